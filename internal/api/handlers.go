@@ -3,39 +3,203 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
 	"net/http"
+	"net/netip"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/alexbotov/rgs/internal/audit"
 	"github.com/alexbotov/rgs/internal/auth"
+	"github.com/alexbotov/rgs/internal/control"
+	"github.com/alexbotov/rgs/internal/database"
 	"github.com/alexbotov/rgs/internal/domain"
+	"github.com/alexbotov/rgs/internal/events"
 	"github.com/alexbotov/rgs/internal/game"
+	"github.com/alexbotov/rgs/internal/limits"
+	"github.com/alexbotov/rgs/internal/realitycheck"
 	"github.com/alexbotov/rgs/internal/rng"
 	"github.com/alexbotov/rgs/internal/wallet"
+	"github.com/alexbotov/rgs/pkg/pateplay"
 	"github.com/gorilla/mux"
 )
 
+// DefaultBalanceUpdateInterval is the minimum time between WebSocket balance updates
+const DefaultBalanceUpdateInterval = 100 * time.Millisecond
+
 // Handler contains all HTTP handlers
 type Handler struct {
-	auth   *auth.Service
-	wallet *wallet.Service
-	game   *game.Engine
-	rng    *rng.Service
+	auth    *auth.Service
+	wallet  *wallet.Service
+	game    *game.Engine
+	rng     *rng.Service
+	limits  *limits.Service
+	control *control.Service
+
+	// realityCheck serves the player's play time and reality checks
+	realityCheck *realitycheck.Service
+
+	// validateToken authenticates a token; WebSocket connections use it
+	// directly since they may authenticate after the upgrade
+	validateToken func(ctx context.Context, token string) (*domain.Session, *domain.Player, error)
+
+	// checkSession and touchSession check and record activity on an open
+	// WebSocket's session without its token
+	checkSession func(ctx context.Context, sessionID string) error
+	touchSession func(ctx context.Context, sessionID string) error
+
+	// events pushes balance changes and limit warnings to open WebSockets
+	events *events.Hub
+
+	// Open WebSocket clients, closed on shutdown
+	clientsMu sync.Mutex
+	clients   map[*WSClient]struct{}
+
+	balanceUpdateInterval time.Duration
+
+	// locale formats the display amounts returned next to numeric ones
+	locale string
+
+	// queryTimeout bounds the work of each WebSocket message; zero uses
+	// database.DefaultQueryTimeout
+	queryTimeout time.Duration
+
+	// trustedProxies are the reverse proxies whose forwarding headers name
+	// the client; requests from anywhere else are attributed to their peer
+	trustedProxies []netip.Prefix
+
+	// accessLog receives one line per request, see LoggingMiddleware
+	accessLog *log.Logger
+
+	// audit records handler panics, see RecoveryMiddleware
+	audit auditLogger
+
+	// Dependencies probed by the health endpoint; nil when not set
+	db       databaseHealth
+	pateplay pinger
+
+	// Rate limiters; nil when disabled, see SetRateLimits
+	ipLimiter          *rateLimiter
+	protectedIPLimiter *rateLimiter
+	playerLimiter      *rateLimiter
 }
 
 // New creates a new API handler
 func New(authSvc *auth.Service, walletSvc *wallet.Service, gameEngine *game.Engine, rngSvc *rng.Service) *Handler {
 	return &Handler{
-		auth:   authSvc,
-		wallet: walletSvc,
-		game:   gameEngine,
-		rng:    rngSvc,
+		auth:                  authSvc,
+		wallet:                walletSvc,
+		game:                  gameEngine,
+		rng:                   rngSvc,
+		validateToken:         authSvc.ValidateToken,
+		checkSession:          authSvc.CheckSession,
+		touchSession:          authSvc.TouchSession,
+		clients:               make(map[*WSClient]struct{}),
+		balanceUpdateInterval: DefaultBalanceUpdateInterval,
+		locale:                domain.DefaultLocale,
+		accessLog:             log.Default(),
+	}
+}
+
+// auditLogger is the part of the audit service the handler uses
+type auditLogger interface {
+	Log(ctx context.Context, eventType string, severity domain.EventSeverity, description string, data interface{}, opts ...audit.EventOption) error
+}
+
+// SetAudit records handler panics in the audit log
+func (h *Handler) SetAudit(auditSvc *audit.Service) {
+	if auditSvc != nil {
+		h.audit = auditSvc
+	}
+}
+
+// SetLimits serves the player's responsible gaming limits and self-exclusion
+func (h *Handler) SetLimits(limitsSvc *limits.Service) {
+	h.limits = limitsSvc
+}
+
+// SetControl serves the operator's gaming management endpoints
+func (h *Handler) SetControl(controlSvc *control.Service) {
+	h.control = controlSvc
+}
+
+// SetRealityCheck serves the player's reality check status and acknowledgement
+func (h *Handler) SetRealityCheck(svc *realitycheck.Service) {
+	h.realityCheck = svc
+}
+
+// databaseHealth is the part of the database the health endpoint checks
+type databaseHealth interface {
+	PingContext(ctx context.Context) error
+	Stats() database.PoolStats
+}
+
+// pinger is an upstream service the health endpoint checks is reachable
+type pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// SetDatabase checks the database on the health endpoint. The server cannot
+// work without it, so the endpoint reports unhealthy when it is down.
+func (h *Handler) SetDatabase(db *database.DB) {
+	if db != nil {
+		h.db = db
+	}
+}
+
+// SetPateplay probes the Pateplay wallet API on the health endpoint. Players
+// of other wallets are unaffected when it is unreachable, so the endpoint
+// reports degraded rather than unhealthy.
+func (h *Handler) SetPateplay(client *pateplay.Client) {
+	if client != nil {
+		h.pateplay = client
 	}
 }
 
+// SetEvents forwards the hub's player events to the player's WebSockets
+func (h *Handler) SetEvents(hub *events.Hub) {
+	h.events = hub
+}
+
+// SetBalanceUpdateInterval sets how often balance updates may be pushed to a
+// WebSocket client during rapid play
+func (h *Handler) SetBalanceUpdateInterval(interval time.Duration) {
+	h.balanceUpdateInterval = interval
+}
+
+// SetLocale sets the locale money is formatted in for display, e.g. "de-DE"
+func (h *Handler) SetLocale(locale string) {
+	if locale != "" {
+		h.locale = locale
+	}
+}
+
+// SetQueryTimeout sets how long the database work of a WebSocket message
+// may take before it is abandoned
+func (h *Handler) SetQueryTimeout(timeout time.Duration) {
+	h.queryTimeout = timeout
+}
+
 // Response helpers
 
+// formatMoney formats amounts for display in the handler's locale. Responses
+// return them under "formatted", keyed like the numeric fields they format,
+// which stay for existing clients.
+func (h *Handler) formatMoney(amounts map[string]domain.Money) map[string]string {
+	formatted := make(map[string]string, len(amounts))
+	for field, m := range amounts {
+		formatted[field] = m.Format(h.locale)
+	}
+	return formatted
+}
+
 type APIResponse struct {
 	Success bool        `json:"success"`
 	Data    interface{} `json:"data,omitempty"`
@@ -68,36 +232,122 @@ func respondError(w http.ResponseWriter, status int, code, message string) {
 	})
 }
 
-// getClientIP extracts client IP from request
-func getClientIP(r *http.Request) string {
-	// Check X-Forwarded-For header
+// SetTrustedProxies sets the reverse proxies, as IP addresses or CIDR
+// ranges, whose X-Forwarded-For and X-Real-IP headers name the client
+func (h *Handler) SetTrustedProxies(proxies []string) error {
+	prefixes := make([]netip.Prefix, 0, len(proxies))
+	for _, proxy := range proxies {
+		if prefix, err := netip.ParsePrefix(proxy); err == nil {
+			prefixes = append(prefixes, prefix.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(proxy)
+		if err != nil {
+			return fmt.Errorf("invalid trusted proxy %q", proxy)
+		}
+		addr = addr.Unmap()
+		prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	h.trustedProxies = prefixes
+	return nil
+}
+
+// clientIP returns the address a request came from. Any client can set
+// forwarding headers, so they are believed only from a trusted proxy: the
+// client is then the nearest X-Forwarded-For address that is not itself a
+// trusted proxy.
+func (h *Handler) clientIP(r *http.Request) string {
+	ip := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(ip); err == nil {
+		ip = host
+	}
+	if !h.trustedProxy(ip) {
+		return ip
+	}
+
 	if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
-		ips := strings.Split(xff, ",")
-		return strings.TrimSpace(ips[0])
+		hops := strings.Split(xff, ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop := strings.TrimSpace(hops[i])
+			if hop != "" && (i == 0 || !h.trustedProxy(hop)) {
+				return hop
+			}
+		}
 	}
-	// Check X-Real-IP header
-	if xrip := r.Header.Get("X-Real-IP"); xrip != "" {
+	if xrip := strings.TrimSpace(r.Header.Get("X-Real-IP")); xrip != "" {
 		return xrip
 	}
-	// Fall back to RemoteAddr
-	ip := r.RemoteAddr
-	if idx := strings.LastIndex(ip, ":"); idx != -1 {
-		ip = ip[:idx]
-	}
 	return ip
 }
 
+// trustedProxy reports whether ip is one of the trusted proxies
+func (h *Handler) trustedProxy(ip string) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range h.trustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
 // === Health & Info ===
 
-// HealthCheck handles GET /health
+// healthCheckTimeout bounds each dependency probe of the health endpoint
+const healthCheckTimeout = 2 * time.Second
+
+// HealthCheck handles GET /health. It responds 503 when a dependency the
+// server cannot work without is down, naming it under "unhealthy".
 func (h *Handler) HealthCheck(w http.ResponseWriter, r *http.Request) {
-	// Check RNG health (GLI-19 §3.3.3)
-	rngHealth, _ := h.rng.HealthCheck()
+	// RNG health from its last self-test (GLI-19 §3.3.3)
+	rngHealth := h.rng.Health()
 
-	respondJSON(w, http.StatusOK, map[string]interface{}{
-		"status":     "healthy",
+	status := "healthy"
+	unhealthy := []string{}
+	body := map[string]interface{}{
 		"rng_status": rngHealth,
-	})
+	}
+
+	if h.db != nil {
+		ctx, cancel := context.WithTimeout(r.Context(), healthCheckTimeout)
+		err := h.db.PingContext(ctx)
+		cancel()
+
+		dbStatus := map[string]interface{}{"healthy": err == nil, "pool": h.db.Stats()}
+		if err != nil {
+			dbStatus["error"] = err.Error()
+			status = "unhealthy"
+			unhealthy = append(unhealthy, "database")
+		}
+		body["database"] = dbStatus
+	}
+
+	if h.pateplay != nil {
+		ctx, cancel := context.WithTimeout(r.Context(), healthCheckTimeout)
+		err := h.pateplay.Ping(ctx)
+		cancel()
+
+		pateplayStatus := map[string]interface{}{"healthy": err == nil}
+		if err != nil {
+			pateplayStatus["error"] = err.Error()
+			if status == "healthy" {
+				status = "degraded"
+			}
+		}
+		body["pateplay"] = pateplayStatus
+	}
+
+	body["status"] = status
+	body["unhealthy"] = unhealthy
+	if status == "unhealthy" {
+		respondJSON(w, http.StatusServiceUnavailable, body)
+		return
+	}
+	respondJSON(w, http.StatusOK, body)
 }
 
 // ServerInfo handles GET /
@@ -111,6 +361,35 @@ func (h *Handler) ServerInfo(w http.ResponseWriter, r *http.Request) {
 
 // === Authentication ===
 
+// Register handles POST /api/v1/auth/register
+func (h *Handler) Register(w http.ResponseWriter, r *http.Request) {
+	var req auth.RegisterRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid request body")
+		return
+	}
+
+	player, err := h.auth.Register(r.Context(), &req, h.clientIP(r))
+	if err != nil {
+		switch {
+		case errors.Is(err, auth.ErrInvalidRegistration):
+			respondError(w, http.StatusBadRequest, "INVALID_REGISTRATION", err.Error())
+		case errors.Is(err, auth.ErrUserExists):
+			respondError(w, http.StatusConflict, "USER_EXISTS", "Username or email already exists")
+		default:
+			respondError(w, http.StatusInternalServerError, "REGISTRATION_FAILED", "Registration failed")
+		}
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, map[string]interface{}{
+		"id":       player.ID,
+		"username": player.Username,
+		"email":    player.Email,
+		"status":   player.Status,
+	})
+}
+
 // Login handles POST /api/v1/auth/login
 func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
 	var req auth.LoginRequest
@@ -119,22 +398,28 @@ func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	result, err := h.auth.Login(r.Context(), &req, getClientIP(r), r.UserAgent())
+	result, err := h.auth.Login(r.Context(), &req, h.clientIP(r), r.UserAgent())
 	if err != nil {
-		switch err {
-		case auth.ErrInvalidCredentials:
+		switch {
+		case errors.Is(err, auth.ErrInvalidCredentials):
 			respondError(w, http.StatusUnauthorized, "INVALID_CREDENTIALS", "Invalid username or password")
-		case auth.ErrAccountLocked:
+		case errors.Is(err, auth.ErrAccountLocked):
 			respondError(w, http.StatusForbidden, "ACCOUNT_LOCKED", "Account is temporarily locked")
-		case auth.ErrAccountNotActive:
+		case errors.Is(err, auth.ErrAccountNotActive):
 			respondError(w, http.StatusForbidden, "ACCOUNT_INACTIVE", "Account is not active")
+		case errors.Is(err, auth.ErrCountryNotAllowed):
+			respondError(w, http.StatusForbidden, "COUNTRY_NOT_ALLOWED", "Play is not permitted from your country")
+		case errors.Is(err, auth.ErrCurrencyNotAllowed):
+			respondError(w, http.StatusForbidden, "CURRENCY_NOT_ALLOWED", "Play is not permitted in your currency")
+		case errors.Is(err, auth.ErrAuthUnavailable):
+			respondError(w, http.StatusServiceUnavailable, "AUTH_UNAVAILABLE", "Login is temporarily unavailable")
 		default:
 			respondError(w, http.StatusInternalServerError, "LOGIN_FAILED", "Login failed")
 		}
 		return
 	}
 
-	respondJSON(w, http.StatusOK, map[string]interface{}{
+	resp := map[string]interface{}{
 		"token":      result.Token,
 		"session_id": result.Session.ID,
 		"player": map[string]interface{}{
@@ -143,27 +428,71 @@ func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
 			"email":    result.Player.Email,
 		},
 		"expires_at": result.Session.ExpiresAt,
-	})
+	}
+	h.addInterruptedGames(r.Context(), resp, result.Player.ID)
+
+	respondJSON(w, http.StatusOK, resp)
 }
 
 // Logout handles POST /api/v1/auth/logout
 func (h *Handler) Logout(w http.ResponseWriter, r *http.Request) {
-	session := r.Context().Value("session").(*domain.Session)
+	session, ok := sessionFromContext(r)
+	if !ok {
+		respondNotAuthenticated(w)
+		return
+	}
 
 	if err := h.auth.Logout(r.Context(), session.ID); err != nil {
 		respondError(w, http.StatusInternalServerError, "LOGOUT_FAILED", "Logout failed")
 		return
 	}
 
+	// Game sessions left open under this login end with it; the logout
+	// itself has succeeded whether or not they could be closed
+	if h.game != nil {
+		if _, err := h.game.EndActiveSessions(r.Context(), session.PlayerID, session.ID); err != nil {
+			log.Printf("Failed to end game sessions of player %s at logout: %v", session.PlayerID, err)
+		}
+	}
+
 	respondJSON(w, http.StatusOK, map[string]string{
 		"message": "Logged out successfully",
 	})
 }
 
+// RefreshSession handles POST /api/v1/auth/refresh
+func (h *Handler) RefreshSession(w http.ResponseWriter, r *http.Request) {
+	session, ok := sessionFromContext(r)
+	if !ok {
+		respondNotAuthenticated(w)
+		return
+	}
+
+	refreshed, token, err := h.auth.RefreshSession(r.Context(), session.Token)
+	if err != nil {
+		respondError(w, http.StatusUnauthorized, "SESSION_EXPIRED", "Session cannot be renewed")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"session_id": refreshed.ID,
+		"token":      token,
+		"expires_at": refreshed.ExpiresAt,
+	})
+}
+
 // GetSession handles GET /api/v1/auth/session
 func (h *Handler) GetSession(w http.ResponseWriter, r *http.Request) {
-	session := r.Context().Value("session").(*domain.Session)
-	player := r.Context().Value("player").(*domain.Player)
+	session, ok := sessionFromContext(r)
+	if !ok {
+		respondNotAuthenticated(w)
+		return
+	}
+	player, ok := playerFromContext(r)
+	if !ok {
+		respondNotAuthenticated(w)
+		return
+	}
 
 	respondJSON(w, http.StatusOK, map[string]interface{}{
 		"session_id": session.ID,
@@ -183,7 +512,11 @@ func (h *Handler) GetSession(w http.ResponseWriter, r *http.Request) {
 
 // GetBalance handles GET /api/v1/wallet/balance
 func (h *Handler) GetBalance(w http.ResponseWriter, r *http.Request) {
-	player := r.Context().Value("player").(*domain.Player)
+	player, ok := playerFromContext(r)
+	if !ok {
+		respondNotAuthenticated(w)
+		return
+	}
 
 	balance, err := h.wallet.GetBalance(r.Context(), player.ID)
 	if err != nil {
@@ -196,12 +529,21 @@ func (h *Handler) GetBalance(w http.ResponseWriter, r *http.Request) {
 		"bonus_balance": balance.BonusBalance.Float64(),
 		"available":     balance.Available.Float64(),
 		"currency":      balance.Currency,
+		"formatted": h.formatMoney(map[string]domain.Money{
+			"real_money":    balance.RealMoney,
+			"bonus_balance": balance.BonusBalance,
+			"available":     balance.Available,
+		}),
 	})
 }
 
 // Deposit handles POST /api/v1/wallet/deposit
 func (h *Handler) Deposit(w http.ResponseWriter, r *http.Request) {
-	player := r.Context().Value("player").(*domain.Player)
+	player, ok := playerFromContext(r)
+	if !ok {
+		respondNotAuthenticated(w)
+		return
+	}
 
 	var req struct {
 		Amount    float64 `json:"amount"`
@@ -217,10 +559,36 @@ func (h *Handler) Deposit(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	amount := domain.NewMoney(req.Amount, "USD")
-	tx, err := h.wallet.Deposit(r.Context(), player.ID, amount, req.Reference)
+	// A retried request carrying the same Idempotency-Key is applied once
+	ctx := wallet.WithIdempotencyKey(r.Context(), r.Header.Get("Idempotency-Key"))
+
+	// Amounts are in the currency of the player's account
+	balance, err := h.wallet.GetBalance(ctx, player.ID)
 	if err != nil {
-		respondError(w, http.StatusInternalServerError, "DEPOSIT_FAILED", err.Error())
+		respondError(w, http.StatusInternalServerError, "BALANCE_ERROR", "Failed to get balance")
+		return
+	}
+
+	amount := domain.NewMoney(req.Amount, balance.Currency)
+	tx, err := h.wallet.Deposit(ctx, player.ID, amount, req.Reference)
+	if err != nil {
+		switch {
+		case errors.Is(err, wallet.ErrDuplicateTransaction):
+			respondError(w, http.StatusConflict, "DUPLICATE_TRANSACTION", "Idempotency key already used for a different amount")
+		case errors.Is(err, wallet.ErrDuplicateReference):
+			respondError(w, http.StatusConflict, "DUPLICATE_REFERENCE", "Reference already used for a different amount")
+		case errors.Is(err, wallet.ErrDepositBelowMinimum):
+			respondError(w, http.StatusBadRequest, "DEPOSIT_BELOW_MINIMUM", "Deposit is below the minimum amount")
+		case errors.Is(err, wallet.ErrDepositAboveMaximum):
+			respondError(w, http.StatusBadRequest, "DEPOSIT_ABOVE_MAXIMUM", "Deposit is above the maximum amount")
+		case errors.Is(err, wallet.ErrDepositLimitExceeded):
+			respondError(w, http.StatusForbidden, "DEPOSIT_LIMIT_EXCEEDED", "Deposit would exceed your deposit limit")
+		case errors.Is(err, wallet.ErrPlayerExcluded):
+			respondError(w, http.StatusForbidden, "PLAYER_EXCLUDED", "Player is self-excluded")
+		default:
+			log.Printf("Deposit failed for player %s: %v", player.ID, err)
+			respondError(w, http.StatusInternalServerError, "DEPOSIT_FAILED", "Deposit failed")
+		}
 		return
 	}
 
@@ -229,12 +597,20 @@ func (h *Handler) Deposit(w http.ResponseWriter, r *http.Request) {
 		"amount":         tx.Amount.Float64(),
 		"balance_after":  tx.BalanceAfter.Float64(),
 		"status":         tx.Status,
+		"formatted": h.formatMoney(map[string]domain.Money{
+			"amount":        tx.Amount,
+			"balance_after": tx.BalanceAfter,
+		}),
 	})
 }
 
 // Withdraw handles POST /api/v1/wallet/withdraw
 func (h *Handler) Withdraw(w http.ResponseWriter, r *http.Request) {
-	player := r.Context().Value("player").(*domain.Player)
+	player, ok := playerFromContext(r)
+	if !ok {
+		respondNotAuthenticated(w)
+		return
+	}
 
 	var req struct {
 		Amount    float64 `json:"amount"`
@@ -250,14 +626,27 @@ func (h *Handler) Withdraw(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	amount := domain.NewMoney(req.Amount, "USD")
-	tx, err := h.wallet.Withdraw(r.Context(), player.ID, amount, req.Reference)
+	ctx := wallet.WithIdempotencyKey(r.Context(), r.Header.Get("Idempotency-Key"))
+
+	balance, err := h.wallet.GetBalance(ctx, player.ID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "BALANCE_ERROR", "Failed to get balance")
+		return
+	}
+
+	amount := domain.NewMoney(req.Amount, balance.Currency)
+	tx, err := h.wallet.Withdraw(ctx, player.ID, amount, req.Reference)
 	if err != nil {
 		switch err {
 		case wallet.ErrInsufficientFunds:
 			respondError(w, http.StatusBadRequest, "INSUFFICIENT_FUNDS", "Insufficient funds")
+		case wallet.ErrDuplicateTransaction:
+			respondError(w, http.StatusConflict, "DUPLICATE_TRANSACTION", "Idempotency key already used for a different amount")
+		case wallet.ErrDuplicateReference:
+			respondError(w, http.StatusConflict, "DUPLICATE_REFERENCE", "Reference already used for a different amount")
 		default:
-			respondError(w, http.StatusInternalServerError, "WITHDRAWAL_FAILED", err.Error())
+			log.Printf("Withdrawal failed for player %s: %v", player.ID, err)
+			respondError(w, http.StatusInternalServerError, "WITHDRAWAL_FAILED", "Withdrawal failed")
 		}
 		return
 	}
@@ -267,12 +656,20 @@ func (h *Handler) Withdraw(w http.ResponseWriter, r *http.Request) {
 		"amount":         tx.Amount.Float64(),
 		"balance_after":  tx.BalanceAfter.Float64(),
 		"status":         tx.Status,
+		"formatted": h.formatMoney(map[string]domain.Money{
+			"amount":        tx.Amount,
+			"balance_after": tx.BalanceAfter,
+		}),
 	})
 }
 
 // GetTransactions handles GET /api/v1/wallet/transactions
 func (h *Handler) GetTransactions(w http.ResponseWriter, r *http.Request) {
-	player := r.Context().Value("player").(*domain.Player)
+	player, ok := playerFromContext(r)
+	if !ok {
+		respondNotAuthenticated(w)
+		return
+	}
 
 	limit := 50
 	if l := r.URL.Query().Get("limit"); l != "" {
@@ -299,6 +696,11 @@ func (h *Handler) GetTransactions(w http.ResponseWriter, r *http.Request) {
 			"status":         tx.Status,
 			"description":    tx.Description,
 			"created_at":     tx.CreatedAt,
+			"formatted": h.formatMoney(map[string]domain.Money{
+				"amount":         tx.Amount,
+				"balance_before": tx.BalanceBefore,
+				"balance_after":  tx.BalanceAfter,
+			}),
 		}
 	}
 
@@ -350,28 +752,54 @@ func (h *Handler) GetGame(w http.ResponseWriter, r *http.Request) {
 
 // StartGameSession handles POST /api/v1/games/{id}/session
 func (h *Handler) StartGameSession(w http.ResponseWriter, r *http.Request) {
-	player := r.Context().Value("player").(*domain.Player)
+	player, ok := playerFromContext(r)
+	if !ok {
+		respondNotAuthenticated(w)
+		return
+	}
 	gameID := mux.Vars(r)["id"]
 
-	session, err := h.game.StartSession(r.Context(), player.ID, gameID)
+	// Logging out ends the game sessions started under the login session
+	ctx := r.Context()
+	if login, ok := sessionFromContext(r); ok {
+		ctx = game.WithLoginSession(ctx, login.ID)
+	}
+
+	session, err := h.game.StartSession(ctx, player.ID, gameID)
 	if err != nil {
 		switch err {
 		case game.ErrGameNotFound:
 			respondError(w, http.StatusNotFound, "GAME_NOT_FOUND", "Game not found")
 		case game.ErrGameDisabled:
 			respondError(w, http.StatusBadRequest, "GAME_DISABLED", "Game is currently disabled")
+		case game.ErrShuttingDown:
+			respondError(w, http.StatusServiceUnavailable, "SHUTTING_DOWN", "Server is shutting down")
+		case game.ErrSessionAlreadyActive:
+			respondError(w, http.StatusConflict, "SESSION_ALREADY_ACTIVE", "An active session for this game already exists")
+		case control.ErrGamingDisabled:
+			respondError(w, http.StatusServiceUnavailable, "GAMING_DISABLED", "Gaming is currently disabled")
+		case control.ErrGameDisabled:
+			respondError(w, http.StatusBadRequest, "GAME_DISABLED", "Game is currently disabled")
+		case control.ErrPlayerDisabled:
+			respondError(w, http.StatusForbidden, "PLAYER_DISABLED", "Player account is disabled")
 		default:
 			respondError(w, http.StatusInternalServerError, "SESSION_ERROR", err.Error())
 		}
 		return
 	}
 
-	respondJSON(w, http.StatusCreated, map[string]interface{}{
+	resp := map[string]interface{}{
 		"session_id":      session.ID,
 		"game_id":         session.GameID,
 		"opening_balance": session.OpeningBalance.Float64(),
 		"started_at":      session.StartedAt,
-	})
+	}
+	if cfg, err := h.game.InitGameConfig(session.GameID, session.OpeningBalance.Currency); err == nil {
+		resp["config"] = cfg
+	}
+	h.addInterruptedGames(r.Context(), resp, player.ID)
+
+	respondJSON(w, http.StatusCreated, resp)
 }
 
 // EndGameSession handles DELETE /api/v1/games/{id}/session
@@ -399,6 +827,53 @@ func (h *Handler) EndGameSession(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// GetGameSession handles GET /api/v1/games/session/{id}, the live stats of
+// one of the player's game sessions
+func (h *Handler) GetGameSession(w http.ResponseWriter, r *http.Request) {
+	player, ok := playerFromContext(r)
+	if !ok {
+		respondNotAuthenticated(w)
+		return
+	}
+
+	session, err := h.game.GetSession(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		if err == game.ErrSessionNotFound {
+			respondError(w, http.StatusNotFound, "SESSION_NOT_FOUND", "Game session not found")
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "SESSION_ERROR", "Failed to get game session")
+		return
+	}
+
+	// Another player's session is reported as missing so its existence is not revealed
+	if session.PlayerID != player.ID {
+		respondError(w, http.StatusNotFound, "SESSION_NOT_FOUND", "Game session not found")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"session_id":       session.ID,
+		"game_id":          session.GameID,
+		"status":           session.Status,
+		"started_at":       session.StartedAt,
+		"ended_at":         session.EndedAt,
+		"last_activity_at": session.LastActivityAt,
+		"games_played":     session.GamesPlayed,
+		"opening_balance":  session.OpeningBalance.Float64(),
+		"current_balance":  session.CurrentBalance.Float64(),
+		"total_wagered":    session.TotalWagered.Float64(),
+		"total_won":        session.TotalWon.Float64(),
+		"currency":         session.OpeningBalance.Currency,
+		"formatted": h.formatMoney(map[string]domain.Money{
+			"opening_balance": session.OpeningBalance,
+			"current_balance": session.CurrentBalance,
+			"total_wagered":   session.TotalWagered,
+			"total_won":       session.TotalWon,
+		}),
+	})
+}
+
 // Play handles POST /api/v1/games/play
 func (h *Handler) Play(w http.ResponseWriter, r *http.Request) {
 	var req game.PlayRequest
@@ -418,24 +893,71 @@ func (h *Handler) Play(w http.ResponseWriter, r *http.Request) {
 			respondError(w, http.StatusBadRequest, "INVALID_WAGER", "Wager amount is invalid")
 		case game.ErrInsufficientBalance:
 			respondError(w, http.StatusBadRequest, "INSUFFICIENT_BALANCE", "Insufficient balance")
+		case game.ErrWagerLimitExceeded:
+			respondError(w, http.StatusForbidden, "WAGER_LIMIT_EXCEEDED", "Wager would exceed your wager limit")
+		case game.ErrLossLimitExceeded:
+			respondError(w, http.StatusForbidden, "LOSS_LIMIT_EXCEEDED", "Loss limit reached")
+		case game.ErrTimeLimitExceeded:
+			respondError(w, http.StatusForbidden, "TIME_LIMIT_EXCEEDED", "Play time limit reached")
+		case game.ErrPlayerExcluded:
+			respondError(w, http.StatusForbidden, "PLAYER_EXCLUDED", "Player is self-excluded")
+		case game.ErrShuttingDown:
+			respondError(w, http.StatusServiceUnavailable, "SHUTTING_DOWN", "Server is shutting down")
+		case control.ErrGamingDisabled:
+			respondError(w, http.StatusServiceUnavailable, "GAMING_DISABLED", "Gaming is currently disabled")
+		case control.ErrGameDisabled:
+			respondError(w, http.StatusBadRequest, "GAME_DISABLED", "Game is currently disabled")
+		case control.ErrPlayerDisabled:
+			respondError(w, http.StatusForbidden, "PLAYER_DISABLED", "Player account is disabled")
+		case realitycheck.ErrAcknowledgementRequired:
+			respondError(w, http.StatusForbidden, "REALITY_CHECK_PENDING", "Acknowledge the reality check to continue playing")
 		default:
 			respondError(w, http.StatusInternalServerError, "GAME_ERROR", err.Error())
 		}
 		return
 	}
 
-	respondJSON(w, http.StatusOK, map[string]interface{}{
+	resp := map[string]interface{}{
 		"cycle_id":     result.CycleID,
 		"outcome":      result.Outcome,
 		"wager_amount": result.WagerAmount.Float64(),
 		"win_amount":   result.WinAmount.Float64(),
 		"balance":      result.Balance.Float64(),
-	})
+		"formatted": h.formatMoney(map[string]domain.Money{
+			"wager_amount": result.WagerAmount,
+			"win_amount":   result.WinAmount,
+			"balance":      result.Balance,
+		}),
+	}
+	if result.Session != nil {
+		resp["session"] = h.sessionTotalsResponse(result.Session)
+	}
+	respondJSON(w, http.StatusOK, resp)
+}
+
+// sessionTotalsResponse renders a session's running totals, so the client can
+// show the player's net position without fetching the session
+func (h *Handler) sessionTotalsResponse(t *game.SessionTotals) map[string]interface{} {
+	net := t.Net()
+	return map[string]interface{}{
+		"total_wagered": t.Wagered.Float64(),
+		"total_won":     t.Won.Float64(),
+		"net":           net.Float64(),
+		"formatted": h.formatMoney(map[string]domain.Money{
+			"total_wagered": t.Wagered,
+			"total_won":     t.Won,
+			"net":           net,
+		}),
+	}
 }
 
 // GetGameHistory handles GET /api/v1/games/history
 func (h *Handler) GetGameHistory(w http.ResponseWriter, r *http.Request) {
-	player := r.Context().Value("player").(*domain.Player)
+	player, ok := playerFromContext(r)
+	if !ok {
+		respondNotAuthenticated(w)
+		return
+	}
 
 	limit := 10
 	if l := r.URL.Query().Get("limit"); l != "" {
@@ -451,18 +973,696 @@ func (h *Handler) GetGameHistory(w http.ResponseWriter, r *http.Request) {
 	}
 
 	historyList := make([]map[string]interface{}, len(history))
-	for i, h := range history {
+	for i, entry := range history {
 		historyList[i] = map[string]interface{}{
-			"cycle_id":       h.CycleID,
-			"game_id":        h.GameID,
-			"played_at":      h.PlayedAt,
-			"wager_amount":   h.WagerAmount.Float64(),
-			"win_amount":     h.WinAmount.Float64(),
-			"balance_before": h.BalanceBefore.Float64(),
-			"balance_after":  h.BalanceAfter.Float64(),
-			"outcome":        h.Outcome,
+			"cycle_id":       entry.CycleID,
+			"game_id":        entry.GameID,
+			"played_at":      entry.PlayedAt,
+			"wager_amount":   entry.WagerAmount.Float64(),
+			"win_amount":     entry.WinAmount.Float64(),
+			"balance_before": entry.BalanceBefore.Float64(),
+			"balance_after":  entry.BalanceAfter.Float64(),
+			"outcome":        entry.Outcome,
+			"formatted": h.formatMoney(map[string]domain.Money{
+				"wager_amount":   entry.WagerAmount,
+				"win_amount":     entry.WinAmount,
+				"balance_before": entry.BalanceBefore,
+				"balance_after":  entry.BalanceAfter,
+			}),
+		}
+		if entry.DecodedOutcome != nil {
+			historyList[i]["decoded_outcome"] = entry.DecodedOutcome
+		}
+		if entry.PateplayTransactionID != "" {
+			historyList[i]["pateplay_round_id"] = entry.PateplayRoundID
+			historyList[i]["pateplay_transaction_id"] = entry.PateplayTransactionID
 		}
 	}
 
 	respondJSON(w, http.StatusOK, historyList)
 }
+
+// GetGameCycle handles GET /api/v1/games/history/{cycle_id}
+// GLI-19 §4.14: Game Recall
+func (h *Handler) GetGameCycle(w http.ResponseWriter, r *http.Request) {
+	player, ok := playerFromContext(r)
+	if !ok {
+		respondNotAuthenticated(w)
+		return
+	}
+
+	cycle, err := h.game.GetCycle(r.Context(), mux.Vars(r)["cycle_id"])
+	if err != nil {
+		if err == game.ErrCycleNotFound {
+			respondError(w, http.StatusNotFound, "CYCLE_NOT_FOUND", "Game cycle not found")
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "HISTORY_ERROR", "Failed to get game cycle")
+		return
+	}
+
+	// Another player's cycle is reported as missing so its existence is not revealed
+	if cycle.PlayerID != player.ID {
+		respondError(w, http.StatusNotFound, "CYCLE_NOT_FOUND", "Game cycle not found")
+		return
+	}
+
+	resp := map[string]interface{}{
+		"cycle_id":       cycle.ID,
+		"session_id":     cycle.SessionID,
+		"game_id":        cycle.GameID,
+		"status":         cycle.Status,
+		"started_at":     cycle.StartedAt,
+		"completed_at":   cycle.CompletedAt,
+		"wager_amount":   cycle.WagerAmount.Float64(),
+		"win_amount":     cycle.WinAmount.Float64(),
+		"balance_before": cycle.BalanceBefore.Float64(),
+		"balance_after":  cycle.BalanceAfter.Float64(),
+		"currency":       cycle.WagerAmount.Currency,
+		"outcome":        cycle.Outcome,
+		"formatted": h.formatMoney(map[string]domain.Money{
+			"wager_amount":   cycle.WagerAmount,
+			"win_amount":     cycle.WinAmount,
+			"balance_before": cycle.BalanceBefore,
+			"balance_after":  cycle.BalanceAfter,
+		}),
+	}
+	if cycle.DecodedOutcome != nil {
+		resp["decoded_outcome"] = cycle.DecodedOutcome
+	}
+	if cycle.PateplayTransactionID != "" {
+		resp["pateplay_round_id"] = cycle.PateplayRoundID
+		resp["pateplay_transaction_id"] = cycle.PateplayTransactionID
+	}
+
+	respondJSON(w, http.StatusOK, resp)
+}
+
+// === Interrupted Games (GLI-19 §4.16) ===
+
+// GetInterruptedGames handles GET /api/v1/games/interrupted
+func (h *Handler) GetInterruptedGames(w http.ResponseWriter, r *http.Request) {
+	player, ok := playerFromContext(r)
+	if !ok {
+		respondNotAuthenticated(w)
+		return
+	}
+
+	interrupted, err := h.game.GetInterruptedGames(r.Context(), player.ID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "INTERRUPTED_ERROR", "Failed to get interrupted games")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, interruptedGamesResponse(interrupted))
+}
+
+// ResumeGame handles POST /api/v1/games/{cycle_id}/resume
+func (h *Handler) ResumeGame(w http.ResponseWriter, r *http.Request) {
+	cycleID, ok := h.ownInterruptedCycle(w, r)
+	if !ok {
+		return
+	}
+
+	result, err := h.game.ResumeGame(r.Context(), cycleID)
+	if err != nil {
+		switch err {
+		case game.ErrCycleNotInterrupted:
+			respondError(w, http.StatusConflict, "CYCLE_NOT_INTERRUPTED", "Game is not interrupted or was already resolved")
+		case game.ErrShuttingDown:
+			respondError(w, http.StatusServiceUnavailable, "SHUTTING_DOWN", "Server is shutting down")
+		default:
+			respondError(w, http.StatusInternalServerError, "RESUME_FAILED", "Failed to resume game")
+		}
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"cycle_id":     result.CycleID,
+		"outcome":      result.Outcome,
+		"wager_amount": result.WagerAmount.Float64(),
+		"win_amount":   result.WinAmount.Float64(),
+		"balance":      result.Balance.Float64(),
+		"formatted": h.formatMoney(map[string]domain.Money{
+			"wager_amount": result.WagerAmount,
+			"win_amount":   result.WinAmount,
+			"balance":      result.Balance,
+		}),
+	})
+}
+
+// VoidGame handles POST /api/v1/games/{cycle_id}/void; the wager is refunded
+func (h *Handler) VoidGame(w http.ResponseWriter, r *http.Request) {
+	cycleID, ok := h.ownInterruptedCycle(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.game.VoidGame(r.Context(), cycleID, "voided by player"); err != nil {
+		switch err {
+		case game.ErrCycleNotInterrupted:
+			respondError(w, http.StatusConflict, "CYCLE_NOT_INTERRUPTED", "Game is not interrupted or was already resolved")
+		default:
+			respondError(w, http.StatusInternalServerError, "VOID_FAILED", "Failed to void game")
+		}
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"cycle_id": cycleID,
+		"status":   domain.CycleStatusVoided,
+	})
+}
+
+// ownInterruptedCycle returns the cycle ID of the request once it is known to
+// belong to the requesting player. Other players' cycles are reported as
+// missing so their existence is not revealed.
+func (h *Handler) ownInterruptedCycle(w http.ResponseWriter, r *http.Request) (string, bool) {
+	player, ok := playerFromContext(r)
+	if !ok {
+		respondNotAuthenticated(w)
+		return "", false
+	}
+
+	cycle, err := h.game.GetCycle(r.Context(), mux.Vars(r)["cycle_id"])
+	if err != nil && err != game.ErrCycleNotFound {
+		respondError(w, http.StatusInternalServerError, "GAME_ERROR", "Failed to get game cycle")
+		return "", false
+	}
+	if err != nil || cycle.PlayerID != player.ID {
+		respondError(w, http.StatusNotFound, "CYCLE_NOT_FOUND", "Game cycle not found")
+		return "", false
+	}
+
+	return cycle.ID, true
+}
+
+// addInterruptedGames adds the player's interrupted games to a response, if
+// there are any, so the client can offer to resume or void them. It is best
+// effort: a failure leaves the response unchanged.
+func (h *Handler) addInterruptedGames(ctx context.Context, resp map[string]interface{}, playerID string) {
+	if h.game == nil {
+		return
+	}
+	interrupted, err := h.game.GetInterruptedGames(ctx, playerID)
+	if err != nil || len(interrupted) == 0 {
+		return
+	}
+	resp["interrupted_games"] = interruptedGamesResponse(interrupted)
+}
+
+func interruptedGamesResponse(interrupted []*domain.InterruptedGame) []map[string]interface{} {
+	list := make([]map[string]interface{}, len(interrupted))
+	for i, ig := range interrupted {
+		list[i] = map[string]interface{}{
+			"cycle_id":       ig.CycleID,
+			"session_id":     ig.SessionID,
+			"game_id":        ig.GameID,
+			"interrupted_at": ig.InterruptedAt,
+			"reason":         ig.Reason,
+			"wager_held":     ig.WagerHeld.Float64(),
+			"can_resume":     ig.CanResume,
+		}
+	}
+	return list
+}
+
+// === Responsible Gaming Limits ===
+
+// GetLimits handles GET /api/v1/limits
+func (h *Handler) GetLimits(w http.ResponseWriter, r *http.Request) {
+	player, ok := playerFromContext(r)
+	if !ok {
+		respondNotAuthenticated(w)
+		return
+	}
+
+	playerLimits, err := h.limits.GetLimits(r.Context(), player.ID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "LIMITS_ERROR", "Failed to get limits")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, limitsResponse(playerLimits, h.limits.Currency()))
+}
+
+// GetResponsibleGamingStatus handles GET /api/v1/limits/status
+// GLI-19 §2.5.5
+func (h *Handler) GetResponsibleGamingStatus(w http.ResponseWriter, r *http.Request) {
+	player, ok := playerFromContext(r)
+	if !ok {
+		respondNotAuthenticated(w)
+		return
+	}
+
+	status, err := h.limits.GetResponsibleGamingStatus(r.Context(), player.ID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "LIMITS_ERROR", "Failed to get responsible gaming status")
+		return
+	}
+
+	resp := map[string]interface{}{
+		"limits": limitsResponse(status.Limits, h.limits.Currency()),
+		"usage": map[string]interface{}{
+			"daily_deposits":   status.DailyDeposits.Float64(),
+			"weekly_deposits":  status.WeeklyDeposits.Float64(),
+			"monthly_deposits": status.MonthlyDeposits.Float64(),
+			"daily_wagers":     status.DailyWagers.Float64(),
+			"weekly_wagers":    status.WeeklyWagers.Float64(),
+			"daily_loss":       status.DailyLoss.Float64(),
+			"weekly_loss":      status.WeeklyLoss.Float64(),
+		},
+		"currency": h.limits.Currency(),
+		"excluded": status.Excluded,
+	}
+	if status.Exclusion != nil {
+		resp["exclusion"] = status.Exclusion
+	}
+
+	respondJSON(w, http.StatusOK, resp)
+}
+
+// SetDepositLimit handles POST /api/v1/limits/deposit
+func (h *Handler) SetDepositLimit(w http.ResponseWriter, r *http.Request) {
+	h.setLimit(w, r, "deposit", func(ctx context.Context, playerID, period string, amount int64) (*domain.PlayerLimits, error) {
+		return h.limits.SetDepositLimit(ctx, &limits.SetDepositLimitRequest{PlayerID: playerID, Period: period, Amount: amount})
+	})
+}
+
+// SetWagerLimit handles POST /api/v1/limits/wager
+func (h *Handler) SetWagerLimit(w http.ResponseWriter, r *http.Request) {
+	h.setLimit(w, r, "wager", func(ctx context.Context, playerID, period string, amount int64) (*domain.PlayerLimits, error) {
+		return h.limits.SetWagerLimit(ctx, &limits.SetWagerLimitRequest{PlayerID: playerID, Period: period, Amount: amount})
+	})
+}
+
+// SetLossLimit handles POST /api/v1/limits/loss
+func (h *Handler) SetLossLimit(w http.ResponseWriter, r *http.Request) {
+	h.setLimit(w, r, "loss", func(ctx context.Context, playerID, period string, amount int64) (*domain.PlayerLimits, error) {
+		return h.limits.SetLossLimit(ctx, &limits.SetLossLimitRequest{PlayerID: playerID, Period: period, Amount: amount})
+	})
+}
+
+// setLimit decodes a {"period", "amount"} request and applies it with set to
+// the period's limit of kind (e.g. deposit). Tightening a limit applies
+// immediately; loosening or removing one (amount 0) is accepted but only takes
+// effect after the cooling-off period.
+// GLI-19 §2.5.5.b
+func (h *Handler) setLimit(w http.ResponseWriter, r *http.Request, kind string, set func(ctx context.Context, playerID, period string, amount int64) (*domain.PlayerLimits, error)) {
+	player, ok := playerFromContext(r)
+	if !ok {
+		respondNotAuthenticated(w)
+		return
+	}
+
+	var req struct {
+		Period string  `json:"period"`
+		Amount float64 `json:"amount"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid request body")
+		return
+	}
+
+	if req.Amount < 0 {
+		respondError(w, http.StatusBadRequest, "INVALID_LIMIT", "Limit must not be negative")
+		return
+	}
+
+	amount := domain.NewMoney(req.Amount, h.limits.Currency())
+	playerLimits, err := set(r.Context(), player.ID, req.Period, amount.Amount)
+	if err != nil {
+		switch {
+		case errors.Is(err, limits.ErrInvalidLimit):
+			respondError(w, http.StatusBadRequest, "INVALID_LIMIT", "Limit value is invalid")
+		case errors.Is(err, limits.ErrInvalidPeriod):
+			respondError(w, http.StatusBadRequest, "INVALID_PERIOD", "Limit period is invalid")
+		case errors.Is(err, limits.ErrLimitAboveCap):
+			respondError(w, http.StatusForbidden, "LIMIT_ABOVE_CAP", "Limit exceeds the cap set by the operator or regulator")
+		case errors.Is(err, limits.ErrCoolingOffPending):
+			respondError(w, http.StatusConflict, "COOLING_OFF_PENDING", "A limit increase is already pending its cooling-off period")
+		default:
+			respondError(w, http.StatusInternalServerError, "LIMITS_ERROR", "Failed to set limit")
+		}
+		return
+	}
+
+	resp := limitsResponse(playerLimits, h.limits.Currency())
+	if pending := playerLimits.PendingFor(req.Period + "_" + kind); pending != nil {
+		resp["status"] = "pending_cooling_off"
+		resp["pending_effective_at"] = pending.EffectiveAt
+		respondJSON(w, http.StatusAccepted, resp)
+		return
+	}
+	resp["status"] = "active"
+	respondJSON(w, http.StatusOK, resp)
+}
+
+// limitsResponse formats a player's limits in major units of currency; unset
+// limits are null. Limits still in their cooling-off period are listed under
+// pending.
+func limitsResponse(l *domain.PlayerLimits, currency string) map[string]interface{} {
+	amount := func(m *domain.Money) interface{} {
+		if m == nil {
+			return nil
+		}
+		return m.Float64()
+	}
+
+	pending := make([]map[string]interface{}, 0, len(l.Pending))
+	for _, p := range l.Pending {
+		var value interface{}
+		switch {
+		case p.Amount == 0:
+		case p.LimitType == "session_duration":
+			value = p.Amount
+		default:
+			value = domain.Money{Amount: p.Amount, Currency: currency}.Float64()
+		}
+		pending = append(pending, map[string]interface{}{
+			"limit_type":   p.LimitType,
+			"amount":       value,
+			"effective_at": p.EffectiveAt,
+		})
+	}
+
+	return map[string]interface{}{
+		"daily_deposit":            amount(l.DailyDeposit),
+		"weekly_deposit":           amount(l.WeeklyDeposit),
+		"monthly_deposit":          amount(l.MonthlyDeposit),
+		"daily_wager":              amount(l.DailyWager),
+		"weekly_wager":             amount(l.WeeklyWager),
+		"daily_loss":               amount(l.DailyLoss),
+		"weekly_loss":              amount(l.WeeklyLoss),
+		"session_duration_minutes": l.SessionDuration,
+		"effective_at":             l.EffectiveAt,
+		"pending":                  pending,
+	}
+}
+
+// SelfExclude handles POST /api/v1/limits/self-exclude
+// GLI-19 §2.5.5.c
+func (h *Handler) SelfExclude(w http.ResponseWriter, r *http.Request) {
+	player, ok := playerFromContext(r)
+	if !ok {
+		respondNotAuthenticated(w)
+		return
+	}
+
+	var req struct {
+		Reason       string `json:"reason"`
+		DurationDays int    `json:"duration_days"`
+		Permanent    bool   `json:"permanent"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid request body")
+		return
+	}
+
+	// Exclusion is hard to undo, so permanence must be asked for explicitly
+	var duration *time.Duration
+	switch {
+	case req.Permanent && req.DurationDays == 0:
+	case !req.Permanent && req.DurationDays > 0:
+		d := time.Duration(req.DurationDays) * 24 * time.Hour
+		duration = &d
+	default:
+		respondError(w, http.StatusBadRequest, "INVALID_DURATION", "Specify either a positive duration_days or permanent")
+		return
+	}
+
+	exclusion, err := h.limits.SelfExclude(r.Context(), player.ID, req.Reason, duration)
+	if errors.Is(err, limits.ErrExclusionTooShort) {
+		respondError(w, http.StatusBadRequest, "INVALID_DURATION", "Self-exclusion is shorter than the minimum exclusion period")
+		return
+	}
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "EXCLUSION_FAILED", "Failed to self-exclude")
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, exclusion)
+}
+
+// RemoveSelfExclusion handles DELETE /api/v1/limits/self-exclude
+func (h *Handler) RemoveSelfExclusion(w http.ResponseWriter, r *http.Request) {
+	player, ok := playerFromContext(r)
+	if !ok {
+		respondNotAuthenticated(w)
+		return
+	}
+
+	exclusion, err := h.limits.RemoveSelfExclusion(r.Context(), player.ID, "player")
+	if err != nil {
+		switch err {
+		case limits.ErrExclusionNotFound:
+			respondError(w, http.StatusNotFound, "EXCLUSION_NOT_FOUND", "No active self-exclusion")
+		case limits.ErrExclusionNotExpired:
+			respondError(w, http.StatusForbidden, "EXCLUSION_NOT_EXPIRED", "Self-exclusion cannot be lifted yet")
+		case limits.ErrExclusionPermanent:
+			respondError(w, http.StatusForbidden, "EXCLUSION_PERMANENT", "Permanent self-exclusion can only be lifted by the operator")
+		default:
+			respondError(w, http.StatusInternalServerError, "EXCLUSION_ERROR", "Failed to remove self-exclusion")
+		}
+		return
+	}
+
+	respondJSON(w, http.StatusOK, exclusion)
+}
+
+// === Reality Checks (GLI-19 §2.5.5) ===
+
+// GetRealityCheck handles GET /api/v1/reality-check
+func (h *Handler) GetRealityCheck(w http.ResponseWriter, r *http.Request) {
+	player, ok := playerFromContext(r)
+	if !ok {
+		respondNotAuthenticated(w)
+		return
+	}
+
+	status, err := h.realityCheck.GetStatus(r.Context(), player.ID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "REALITY_CHECK_ERROR", "Failed to get reality check")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, realityCheckResponse(status))
+}
+
+// AcknowledgeRealityCheck handles POST /api/v1/reality-check/acknowledge
+func (h *Handler) AcknowledgeRealityCheck(w http.ResponseWriter, r *http.Request) {
+	player, ok := playerFromContext(r)
+	if !ok {
+		respondNotAuthenticated(w)
+		return
+	}
+
+	status, err := h.realityCheck.Acknowledge(r.Context(), player.ID)
+	if err != nil {
+		if err == realitycheck.ErrNoPendingCheck {
+			respondError(w, http.StatusConflict, "NO_PENDING_REALITY_CHECK", "No reality check awaiting acknowledgement")
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "REALITY_CHECK_ERROR", "Failed to acknowledge reality check")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, realityCheckResponse(status))
+}
+
+// realityCheckResponse reports play time in whole minutes
+func realityCheckResponse(status *realitycheck.Status) map[string]interface{} {
+	return map[string]interface{}{
+		"played_minutes":     int64(status.PlayedTime / time.Minute),
+		"next_check_minutes": int64(status.NextCheckAt / time.Minute),
+		"pending":            status.Pending,
+		"notified_at":        status.NotifiedAt,
+		"acknowledged_at":    status.AcknowledgedAt,
+	}
+}
+
+// === Admin: Gaming Management (GLI-19 §2.4) ===
+
+// adminRequest is the body of an admin action; every action records who
+// authorized it and, when disabling, why
+type adminRequest struct {
+	Reason       string `json:"reason"`
+	AuthorizedBy string `json:"authorized_by"`
+}
+
+// decodeAdminRequest reads an admin request, responding 400 when it is
+// malformed or missing a required field
+func decodeAdminRequest(w http.ResponseWriter, r *http.Request, requireReason bool) (*adminRequest, bool) {
+	var req adminRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid request body")
+		return nil, false
+	}
+	req.Reason = strings.TrimSpace(req.Reason)
+	req.AuthorizedBy = strings.TrimSpace(req.AuthorizedBy)
+
+	if req.AuthorizedBy == "" {
+		respondError(w, http.StatusBadRequest, "AUTHORIZED_BY_REQUIRED", "authorized_by is required")
+		return nil, false
+	}
+	if requireReason && req.Reason == "" {
+		respondError(w, http.StatusBadRequest, "REASON_REQUIRED", "reason is required")
+		return nil, false
+	}
+	return &req, true
+}
+
+// GetSystemStatus handles GET /api/v1/admin/status
+func (h *Handler) GetSystemStatus(w http.ResponseWriter, r *http.Request) {
+	status, err := h.control.GetSystemStatus(r.Context())
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "STATUS_ERROR", "Failed to get system status")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, status)
+}
+
+// DisableGaming handles POST /api/v1/admin/gaming/disable
+func (h *Handler) DisableGaming(w http.ResponseWriter, r *http.Request) {
+	req, ok := decodeAdminRequest(w, r, true)
+	if !ok {
+		return
+	}
+
+	if err := h.control.DisableAllGaming(r.Context(), req.Reason, req.AuthorizedBy); err != nil {
+		respondError(w, http.StatusInternalServerError, "CONTROL_ERROR", "Failed to disable gaming")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{"gaming_enabled": false})
+}
+
+// EnableGaming handles POST /api/v1/admin/gaming/enable
+func (h *Handler) EnableGaming(w http.ResponseWriter, r *http.Request) {
+	req, ok := decodeAdminRequest(w, r, false)
+	if !ok {
+		return
+	}
+
+	if err := h.control.EnableAllGaming(r.Context(), req.AuthorizedBy); err != nil {
+		respondError(w, http.StatusInternalServerError, "CONTROL_ERROR", "Failed to enable gaming")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{"gaming_enabled": true})
+}
+
+// DisableGame handles POST /api/v1/admin/games/{id}/disable
+func (h *Handler) DisableGame(w http.ResponseWriter, r *http.Request) {
+	gameID := mux.Vars(r)["id"]
+	if _, err := h.game.GetGame(gameID); err != nil {
+		respondError(w, http.StatusNotFound, "GAME_NOT_FOUND", "Game not found")
+		return
+	}
+
+	req, ok := decodeAdminRequest(w, r, true)
+	if !ok {
+		return
+	}
+
+	if err := h.control.DisableGame(r.Context(), gameID, req.Reason, req.AuthorizedBy); err != nil {
+		respondError(w, http.StatusInternalServerError, "CONTROL_ERROR", "Failed to disable game")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{"game_id": gameID, "enabled": false})
+}
+
+// EnableGame handles POST /api/v1/admin/games/{id}/enable
+func (h *Handler) EnableGame(w http.ResponseWriter, r *http.Request) {
+	gameID := mux.Vars(r)["id"]
+	if _, err := h.game.GetGame(gameID); err != nil {
+		respondError(w, http.StatusNotFound, "GAME_NOT_FOUND", "Game not found")
+		return
+	}
+
+	req, ok := decodeAdminRequest(w, r, false)
+	if !ok {
+		return
+	}
+
+	if err := h.control.EnableGame(r.Context(), gameID, req.AuthorizedBy); err != nil {
+		respondError(w, http.StatusInternalServerError, "CONTROL_ERROR", "Failed to enable game")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{"game_id": gameID, "enabled": true})
+}
+
+// DisablePlayer handles POST /api/v1/admin/players/{id}/disable
+func (h *Handler) DisablePlayer(w http.ResponseWriter, r *http.Request) {
+	playerID := mux.Vars(r)["id"]
+
+	req, ok := decodeAdminRequest(w, r, true)
+	if !ok {
+		return
+	}
+
+	if err := h.control.DisablePlayer(r.Context(), playerID, req.Reason, req.AuthorizedBy); err != nil {
+		switch err {
+		case control.ErrPlayerNotFound:
+			respondError(w, http.StatusNotFound, "PLAYER_NOT_FOUND", "Player not found")
+		default:
+			respondError(w, http.StatusInternalServerError, "CONTROL_ERROR", "Failed to disable player")
+		}
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{"player_id": playerID, "status": domain.PlayerStatusSuspended})
+}
+
+// OverrideSelfExclusion handles POST /api/v1/admin/players/{id}/self-exclusion/override
+// GLI-19 §2.5.5.c
+func (h *Handler) OverrideSelfExclusion(w http.ResponseWriter, r *http.Request) {
+	playerID := mux.Vars(r)["id"]
+
+	req, ok := decodeAdminRequest(w, r, true)
+	if !ok {
+		return
+	}
+
+	exclusion, err := h.limits.OverrideSelfExclusion(r.Context(), playerID, req.AuthorizedBy, req.Reason)
+	if err != nil {
+		switch err {
+		case limits.ErrExclusionNotFound:
+			respondError(w, http.StatusNotFound, "EXCLUSION_NOT_FOUND", "No active self-exclusion")
+		case limits.ErrExclusionNotExpired:
+			respondError(w, http.StatusForbidden, "EXCLUSION_NOT_EXPIRED", "Self-exclusion cannot be lifted yet")
+		default:
+			respondError(w, http.StatusInternalServerError, "EXCLUSION_ERROR", "Failed to lift self-exclusion")
+		}
+		return
+	}
+
+	respondJSON(w, http.StatusOK, exclusion)
+}
+
+// EnablePlayer handles POST /api/v1/admin/players/{id}/enable
+func (h *Handler) EnablePlayer(w http.ResponseWriter, r *http.Request) {
+	playerID := mux.Vars(r)["id"]
+
+	req, ok := decodeAdminRequest(w, r, false)
+	if !ok {
+		return
+	}
+
+	if err := h.control.EnablePlayer(r.Context(), playerID, req.AuthorizedBy); err != nil {
+		switch err {
+		case control.ErrPlayerNotFound:
+			respondError(w, http.StatusNotFound, "PLAYER_NOT_FOUND", "Player not found")
+		case control.ErrPlayerSelfExcluded:
+			respondError(w, http.StatusConflict, "PLAYER_SELF_EXCLUDED", "Player has an active self-exclusion")
+		default:
+			respondError(w, http.StatusInternalServerError, "CONTROL_ERROR", "Failed to enable player")
+		}
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{"player_id": playerID, "status": domain.PlayerStatusActive})
+}
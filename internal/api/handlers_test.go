@@ -0,0 +1,261 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/alexbotov/rgs/internal/database"
+	"github.com/alexbotov/rgs/internal/domain"
+)
+
+// authenticatedRequest returns a request carrying the session and player
+// AuthMiddleware would have set
+func authenticatedRequest(method, body string) *http.Request {
+	req := httptest.NewRequest(method, "/", strings.NewReader(body))
+	ctx := context.WithValue(req.Context(), sessionKey, &domain.Session{ID: "session-1", PlayerID: "player-1"})
+	ctx = context.WithValue(ctx, playerKey, &domain.Player{ID: "player-1"})
+	return req.WithContext(ctx)
+}
+
+func TestHandlersRejectUnauthenticatedContext(t *testing.T) {
+	h := newTestAuthHandler()
+
+	handlers := map[string]http.HandlerFunc{
+		"Logout":           h.Logout,
+		"RefreshSession":   h.RefreshSession,
+		"GetSession":       h.GetSession,
+		"GetBalance":       h.GetBalance,
+		"Deposit":          h.Deposit,
+		"Withdraw":         h.Withdraw,
+		"GetTransactions":  h.GetTransactions,
+		"StartGameSession": h.StartGameSession,
+		"GetGameSession":   h.GetGameSession,
+		"GetGameHistory":   h.GetGameHistory,
+		"GetGameCycle":     h.GetGameCycle,
+		"GetInterrupted":   h.GetInterruptedGames,
+		"ResumeGame":       h.ResumeGame,
+		"VoidGame":         h.VoidGame,
+		"GetLimits":        h.GetLimits,
+		"GetRGStatus":      h.GetResponsibleGamingStatus,
+		"SetDepositLimit":  h.SetDepositLimit,
+		"SetWagerLimit":    h.SetWagerLimit,
+		"SetLossLimit":     h.SetLossLimit,
+		"SelfExclude":      h.SelfExclude,
+		"RemoveExclusion":  h.RemoveSelfExclusion,
+	}
+
+	for name, handler := range handlers {
+		t.Run(name, func(t *testing.T) {
+			// Called directly, without AuthMiddleware, so the context is empty
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"amount": 10}`))
+			rec := httptest.NewRecorder()
+			handler(rec, req)
+
+			if rec.Code != http.StatusUnauthorized {
+				t.Fatalf("Expected status 401, got %d", rec.Code)
+			}
+			if code := decodeError(t, rec); code != "NOT_AUTHENTICATED" {
+				t.Errorf("Expected NOT_AUTHENTICATED, got %s", code)
+			}
+		})
+	}
+}
+
+func TestLimitRequestValidation(t *testing.T) {
+	h := newTestAuthHandler()
+
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		body    string
+		code    string
+	}{
+		{"MalformedBody", h.SetDepositLimit, `{"period":`, "INVALID_REQUEST"},
+		{"NegativeAmount", h.SetWagerLimit, `{"period": "daily", "amount": -5}`, "INVALID_LIMIT"},
+		{"ExclusionWithoutDuration", h.SelfExclude, `{"reason": "break"}`, "INVALID_DURATION"},
+		{"ExclusionBothDurationAndPermanent", h.SelfExclude, `{"duration_days": 7, "permanent": true}`, "INVALID_DURATION"},
+		{"ExclusionNegativeDuration", h.SelfExclude, `{"duration_days": -1}`, "INVALID_DURATION"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			tt.handler(rec, authenticatedRequest(http.MethodPost, tt.body))
+
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("Expected status 400, got %d", rec.Code)
+			}
+			if code := decodeError(t, rec); code != tt.code {
+				t.Errorf("Expected %s, got %s", tt.code, code)
+			}
+		})
+	}
+}
+
+func TestAdminRequestValidation(t *testing.T) {
+	h := newTestAuthHandler()
+
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		body    string
+		code    string
+	}{
+		{"MalformedBody", h.DisableGaming, `{`, "INVALID_REQUEST"},
+		{"DisableWithoutAuthorizer", h.DisableGaming, `{"reason": "maintenance"}`, "AUTHORIZED_BY_REQUIRED"},
+		{"DisableWithoutReason", h.DisablePlayer, `{"authorized_by": "ops@example.com"}`, "REASON_REQUIRED"},
+		{"BlankReason", h.DisablePlayer, `{"reason": "  ", "authorized_by": "ops@example.com"}`, "REASON_REQUIRED"},
+		{"EnableWithoutAuthorizer", h.EnablePlayer, `{}`, "AUTHORIZED_BY_REQUIRED"},
+		{"OverrideWithoutReason", h.OverrideSelfExclusion, `{"authorized_by": "ops@example.com"}`, "REASON_REQUIRED"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			tt.handler(rec, authenticatedRequest(http.MethodPost, tt.body))
+
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("Expected status 400, got %d", rec.Code)
+			}
+			if code := decodeError(t, rec); code != tt.code {
+				t.Errorf("Expected %s, got %s", tt.code, code)
+			}
+		})
+	}
+}
+
+// fakePinger is an upstream dependency that fails with err
+type fakePinger struct{ err error }
+
+func (p fakePinger) Ping(ctx context.Context) error { return p.err }
+
+func TestHealthCheckDependencies(t *testing.T) {
+	health := func(h *Handler) (int, map[string]interface{}) {
+		rec := httptest.NewRecorder()
+		h.HealthCheck(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+		var resp struct {
+			Data map[string]interface{} `json:"data"`
+		}
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		return rec.Code, resp.Data
+	}
+
+	t.Run("ClosedDatabase", func(t *testing.T) {
+		sqlDB, err := sql.Open("postgres", "host=localhost dbname=rgs sslmode=disable")
+		if err != nil {
+			t.Fatalf("Failed to open database: %v", err)
+		}
+		sqlDB.Close()
+
+		h := newTestAuthHandler()
+		h.SetDatabase(&database.DB{DB: sqlDB})
+		code, data := health(h)
+
+		if code != http.StatusServiceUnavailable {
+			t.Errorf("Expected status 503, got %d", code)
+		}
+		if data["status"] != "unhealthy" {
+			t.Errorf("Expected unhealthy, got %v", data["status"])
+		}
+		if unhealthy, _ := data["unhealthy"].([]interface{}); len(unhealthy) != 1 || unhealthy[0] != "database" {
+			t.Errorf("Expected database named unhealthy, got %v", data["unhealthy"])
+		}
+		if data["rng_status"] == nil {
+			t.Error("Expected RNG status in the payload")
+		}
+	})
+
+	t.Run("UnreachablePateplay", func(t *testing.T) {
+		h := newTestAuthHandler()
+		h.pateplay = fakePinger{err: errors.New("connection refused")}
+		code, data := health(h)
+
+		if code != http.StatusOK {
+			t.Errorf("Expected status 200, got %d", code)
+		}
+		if data["status"] != "degraded" {
+			t.Errorf("Expected degraded, got %v", data["status"])
+		}
+	})
+
+	t.Run("Healthy", func(t *testing.T) {
+		h := newTestAuthHandler()
+		h.pateplay = fakePinger{}
+		code, data := health(h)
+
+		if code != http.StatusOK || data["status"] != "healthy" {
+			t.Errorf("Expected healthy 200, got %d %v", code, data["status"])
+		}
+	})
+}
+
+func TestClientIP(t *testing.T) {
+	h := &Handler{}
+	if err := h.SetTrustedProxies([]string{"10.0.0.0/8", "192.168.1.1"}); err != nil {
+		t.Fatalf("Failed to set trusted proxies: %v", err)
+	}
+	if err := (&Handler{}).SetTrustedProxies([]string{"proxy"}); err == nil {
+		t.Error("Expected an invalid trusted proxy to be rejected")
+	}
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		xff        string
+		realIP     string
+		want       string
+	}{
+		{"Direct", "203.0.113.7:5000", "", "", "203.0.113.7"},
+		{"SpoofedFromClient", "203.0.113.7:5000", "198.51.100.1", "198.51.100.2", "203.0.113.7"},
+		{"ThroughProxy", "10.1.2.3:5000", "198.51.100.1", "", "198.51.100.1"},
+		{"ClientPrependedHop", "10.1.2.3:5000", "1.2.3.4, 198.51.100.1", "", "198.51.100.1"},
+		{"ProxyChain", "192.168.1.1:5000", "198.51.100.1, 10.9.9.9", "", "198.51.100.1"},
+		{"RealIPThroughProxy", "10.1.2.3:5000", "", "198.51.100.3", "198.51.100.3"},
+		{"IPv6Peer", "[2001:db8::1]:5000", "198.51.100.1", "", "2001:db8::1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			if tt.xff != "" {
+				req.Header.Set("X-Forwarded-For", tt.xff)
+			}
+			if tt.realIP != "" {
+				req.Header.Set("X-Real-IP", tt.realIP)
+			}
+			if got := h.clientIP(req); got != tt.want {
+				t.Errorf("Expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestLimitsResponseCurrency(t *testing.T) {
+	daily := domain.Money{Amount: 5000, Currency: "JPY"}
+	resp := limitsResponse(&domain.PlayerLimits{
+		DailyDeposit: &daily,
+		Pending: []domain.PendingLimit{
+			{LimitType: "daily_deposit", Amount: 10000},
+			{LimitType: "session_duration", Amount: 90},
+		},
+	}, "JPY")
+
+	if got := resp["daily_deposit"]; got != 5000.0 {
+		t.Errorf("Expected daily deposit 5000, got %v", got)
+	}
+	pending := resp["pending"].([]map[string]interface{})
+	if got := pending[0]["amount"]; got != 10000.0 {
+		t.Errorf("Expected pending deposit limit 10000 in yen, got %v", got)
+	}
+	if got := pending[1]["amount"]; got != int64(90) {
+		t.Errorf("Expected pending session duration 90, got %v", got)
+	}
+}
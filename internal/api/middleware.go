@@ -2,13 +2,53 @@
 package api
 
 import (
+	"bufio"
 	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
 	"net/http"
+	"runtime/debug"
 	"strings"
+	"time"
 
+	"github.com/alexbotov/rgs/internal/audit"
 	"github.com/alexbotov/rgs/internal/auth"
+	"github.com/alexbotov/rgs/internal/domain"
+	"github.com/google/uuid"
 )
 
+// RequestIDHeader carries the request ID on every response
+const RequestIDHeader = "X-Request-ID"
+
+// contextKey identifies a value the API stores in a request context
+type contextKey int
+
+const (
+	sessionKey   contextKey = iota // *domain.Session, set by AuthMiddleware
+	playerKey                      // *domain.Player, set by AuthMiddleware
+	accessLogKey                   // *accessLogEntry, set by LoggingMiddleware
+)
+
+// sessionFromContext returns the session of an authenticated request
+func sessionFromContext(r *http.Request) (*domain.Session, bool) {
+	session, ok := r.Context().Value(sessionKey).(*domain.Session)
+	return session, ok && session != nil
+}
+
+// playerFromContext returns the player of an authenticated request
+func playerFromContext(r *http.Request) (*domain.Player, bool) {
+	player, ok := r.Context().Value(playerKey).(*domain.Player)
+	return player, ok && player != nil
+}
+
+// respondNotAuthenticated rejects a request that reached a protected handler
+// without the session and player AuthMiddleware provides
+func respondNotAuthenticated(w http.ResponseWriter) {
+	respondError(w, http.StatusUnauthorized, "NOT_AUTHENTICATED", "Authentication required")
+}
+
 // AuthMiddleware validates JWT tokens and adds session/player to context
 func (h *Handler) AuthMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -29,42 +69,132 @@ func (h *Handler) AuthMiddleware(next http.Handler) http.Handler {
 		token := parts[1]
 
 		// Validate token
-		session, player, err := h.auth.ValidateToken(r.Context(), token)
+		session, player, err := h.validateToken(r.Context(), token)
 		if err != nil {
 			switch err {
 			case auth.ErrSessionExpired:
 				respondError(w, http.StatusUnauthorized, "SESSION_EXPIRED", "Session has expired")
 			case auth.ErrSessionNotFound:
 				respondError(w, http.StatusUnauthorized, "SESSION_NOT_FOUND", "Session not found")
+			case auth.ErrSessionLimit:
+				respondError(w, http.StatusUnauthorized, "SESSION_LIMIT_REACHED", "Session duration limit reached, please log in again")
 			default:
 				respondError(w, http.StatusUnauthorized, "INVALID_TOKEN", "Invalid token")
 			}
 			return
 		}
 
+		if entry, ok := r.Context().Value(accessLogKey).(*accessLogEntry); ok {
+			entry.playerID = player.ID
+		}
+
 		// Add session and player to context
-		ctx := context.WithValue(r.Context(), "session", session)
-		ctx = context.WithValue(ctx, "player", player)
+		ctx := context.WithValue(r.Context(), sessionKey, session)
+		ctx = context.WithValue(ctx, playerKey, player)
 
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
 
-// LoggingMiddleware logs all requests
-func LoggingMiddleware(next http.Handler) http.Handler {
+// AdminMiddleware restricts routes to operator staff; it runs after
+// AuthMiddleware so the player is known
+func (h *Handler) AdminMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Simple request logging
-		// In production, use structured logging
+		player, ok := playerFromContext(r)
+		if !ok {
+			respondNotAuthenticated(w)
+			return
+		}
+		if player.Role != domain.PlayerRoleAdmin {
+			respondError(w, http.StatusForbidden, "FORBIDDEN", "Admin access required")
+			return
+		}
 		next.ServeHTTP(w, r)
 	})
 }
 
+// accessLogEntry collects what inner handlers learn about a request, such as
+// the authenticated player, for the access log line written once it completes
+type accessLogEntry struct {
+	playerID string
+}
+
+// LoggingMiddleware assigns each request an ID, returned in the X-Request-ID
+// header and carried in the context so audit events raised while serving it
+// record the same ID, and writes one access log line per request
+func (h *Handler) LoggingMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		requestID := uuid.New().String()
+		w.Header().Set(RequestIDHeader, requestID)
+
+		entry := &accessLogEntry{}
+		ctx := audit.ContextWithRequestID(r.Context(), requestID)
+		ctx = context.WithValue(ctx, accessLogKey, entry)
+
+		rec := &statusRecorder{ResponseWriter: w}
+		next.ServeHTTP(rec, r.WithContext(ctx))
+
+		playerID := entry.playerID
+		if playerID == "" {
+			playerID = "-"
+		}
+		h.accessLog.Printf("request_id=%s method=%s path=%q status=%d latency=%s player_id=%s ip=%s",
+			requestID, r.Method, r.URL.Path, rec.Status(), time.Since(start), playerID, h.clientIP(r))
+	})
+}
+
+// statusRecorder remembers the status code written through it
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	return r.ResponseWriter.Write(b)
+}
+
+// Status returns the response status, 200 if the handler wrote nothing
+func (r *statusRecorder) Status() int {
+	if r.status == 0 {
+		return http.StatusOK
+	}
+	return r.status
+}
+
+// Hijack lets WebSocket upgrades through the recorder
+func (r *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hj, ok := r.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("response writer does not support hijacking")
+	}
+	if r.status == 0 {
+		r.status = http.StatusSwitchingProtocols
+	}
+	return hj.Hijack()
+}
+
+// Flush passes flushes through to the underlying writer
+func (r *statusRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
 // CORSMiddleware adds CORS headers
 func CORSMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		setCORSHeaders(w, "GET, POST, PUT, DELETE, OPTIONS")
 
 		if r.Method == "OPTIONS" {
 			w.WriteHeader(http.StatusOK)
@@ -75,15 +205,53 @@ func CORSMiddleware(next http.Handler) http.Handler {
 	})
 }
 
-// RecoveryMiddleware recovers from panics
-func RecoveryMiddleware(next http.Handler) http.Handler {
+// setCORSHeaders writes the CORS response headers with the given allowed methods
+func setCORSHeaders(w http.ResponseWriter, methods string) {
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Methods", methods)
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+	w.Header().Set("Access-Control-Expose-Headers", RequestIDHeader)
+}
+
+// RecoveryMiddleware turns a handler panic into a 500 response, logging the
+// stack trace and recording a critical audit event (GLI-19 §2.8.8)
+func (h *Handler) RecoveryMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		defer func() {
-			if err := recover(); err != nil {
-				respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Internal server error")
+			err := recover()
+			if err == nil {
+				return
 			}
+			if err == http.ErrAbortHandler {
+				panic(err)
+			}
+
+			log.Printf("Panic serving %s %s: %v\n%s", r.Method, r.URL.Path, err, debug.Stack())
+			h.auditPanic(r, err)
+			respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Internal server error")
 		}()
 		next.ServeHTTP(w, r)
 	})
 }
 
+// auditPanic records a recovered panic, attributed to the player when the
+// request was authenticated before it failed
+func (h *Handler) auditPanic(r *http.Request, err interface{}) {
+	if h.audit == nil {
+		return
+	}
+
+	opts := []audit.EventOption{audit.WithIP(h.clientIP(r)), audit.WithComponent("api")}
+	if entry, ok := r.Context().Value(accessLogKey).(*accessLogEntry); ok && entry.playerID != "" {
+		opts = append(opts, audit.WithPlayer(entry.playerID))
+	}
+
+	h.audit.Log(r.Context(), audit.EventSystemError, domain.SeverityCritical,
+		"Panic while handling API request",
+		map[string]string{
+			"panic":  fmt.Sprint(err),
+			"method": r.Method,
+			"path":   r.URL.Path,
+		},
+		opts...)
+}
@@ -0,0 +1,294 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/alexbotov/rgs/internal/audit"
+	"github.com/alexbotov/rgs/internal/auth"
+	"github.com/alexbotov/rgs/internal/domain"
+	"github.com/alexbotov/rgs/internal/rng"
+)
+
+// recordingAudit keeps the events logged through it
+type recordingAudit struct {
+	events []*domain.AuditEvent
+}
+
+func (a *recordingAudit) Log(ctx context.Context, eventType string, severity domain.EventSeverity, description string, data interface{}, opts ...audit.EventOption) error {
+	event := &domain.AuditEvent{Type: eventType, Severity: severity, Description: description,
+		RequestID: audit.RequestIDFromContext(ctx)}
+	if data != nil {
+		event.Data, _ = json.Marshal(data)
+	}
+	for _, opt := range opts {
+		opt(event)
+	}
+	a.events = append(a.events, event)
+	return nil
+}
+
+// newTestAuthHandler returns a handler that accepts "valid-token" and
+// "admin-token", the latter for an admin, and treats "expired-token" as an
+// expired session
+func newTestAuthHandler() *Handler {
+	h := New(nil, nil, nil, rng.New())
+	h.validateToken = func(ctx context.Context, token string) (*domain.Session, *domain.Player, error) {
+		switch token {
+		case "valid-token":
+			return &domain.Session{ID: "session-1", PlayerID: "player-1"}, &domain.Player{ID: "player-1"}, nil
+		case "admin-token":
+			return &domain.Session{ID: "session-2", PlayerID: "admin-1"}, &domain.Player{ID: "admin-1", Role: domain.PlayerRoleAdmin}, nil
+		case "expired-token":
+			return nil, nil, auth.ErrSessionExpired
+		}
+		return nil, nil, auth.ErrSessionNotFound
+	}
+	return h
+}
+
+// decodeError returns the error code of an API error response
+func decodeError(t *testing.T, rec *httptest.ResponseRecorder) string {
+	t.Helper()
+	var resp APIResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if resp.Success || resp.Error == nil {
+		t.Fatalf("Expected an error response, got %+v", resp)
+	}
+	return resp.Error.Code
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	h := newTestAuthHandler()
+
+	var gotSession *domain.Session
+	var gotPlayer *domain.Player
+	protected := h.AuthMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotSession, _ = sessionFromContext(r)
+		gotPlayer, _ = playerFromContext(r)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	serve := func(authHeader string) *httptest.ResponseRecorder {
+		gotSession, gotPlayer = nil, nil
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/wallet/balance", nil)
+		if authHeader != "" {
+			req.Header.Set("Authorization", authHeader)
+		}
+		rec := httptest.NewRecorder()
+		protected.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("ValidToken", func(t *testing.T) {
+		rec := serve("Bearer valid-token")
+		if rec.Code != http.StatusNoContent {
+			t.Fatalf("Expected status 204, got %d", rec.Code)
+		}
+		if gotSession == nil || gotSession.ID != "session-1" {
+			t.Errorf("Expected session in context, got %+v", gotSession)
+		}
+		if gotPlayer == nil || gotPlayer.ID != "player-1" {
+			t.Errorf("Expected player in context, got %+v", gotPlayer)
+		}
+	})
+
+	t.Run("MissingToken", func(t *testing.T) {
+		rec := serve("")
+		if rec.Code != http.StatusUnauthorized {
+			t.Fatalf("Expected status 401, got %d", rec.Code)
+		}
+		if code := decodeError(t, rec); code != "NO_TOKEN" {
+			t.Errorf("Expected NO_TOKEN, got %s", code)
+		}
+		if gotPlayer != nil {
+			t.Error("Handler should not run without a token")
+		}
+	})
+
+	t.Run("MalformedHeader", func(t *testing.T) {
+		rec := serve("Token valid-token")
+		if rec.Code != http.StatusUnauthorized {
+			t.Fatalf("Expected status 401, got %d", rec.Code)
+		}
+		if code := decodeError(t, rec); code != "INVALID_TOKEN_FORMAT" {
+			t.Errorf("Expected INVALID_TOKEN_FORMAT, got %s", code)
+		}
+	})
+
+	t.Run("ExpiredToken", func(t *testing.T) {
+		rec := serve("Bearer expired-token")
+		if rec.Code != http.StatusUnauthorized {
+			t.Fatalf("Expected status 401, got %d", rec.Code)
+		}
+		if code := decodeError(t, rec); code != "SESSION_EXPIRED" {
+			t.Errorf("Expected SESSION_EXPIRED, got %s", code)
+		}
+		if gotPlayer != nil {
+			t.Error("Handler should not run with an expired token")
+		}
+	})
+}
+
+func TestRouterAuthentication(t *testing.T) {
+	router := newTestAuthHandler().SetupRouter()
+
+	t.Run("PublicRouteSkipsAuth", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/health", nil)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Errorf("Expected status 200, got %d", rec.Code)
+		}
+	})
+
+	t.Run("ProtectedRouteRequiresAuth", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/wallet/balance", nil)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusUnauthorized {
+			t.Errorf("Expected status 401, got %d", rec.Code)
+		}
+	})
+}
+
+func TestLoggingMiddleware(t *testing.T) {
+	h := newTestAuthHandler()
+	var logs bytes.Buffer
+	h.accessLog = log.New(&logs, "", 0)
+
+	var ctxRequestID string
+	handler := h.LoggingMiddleware(h.AuthMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctxRequestID = audit.RequestIDFromContext(r.Context())
+		w.WriteHeader(http.StatusTeapot)
+	})))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/games/play", nil)
+	req.Header.Set("Authorization", "Bearer valid-token")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	requestID := rec.Header().Get(RequestIDHeader)
+	if requestID == "" {
+		t.Fatal("Expected X-Request-ID header to be set")
+	}
+	if ctxRequestID != requestID {
+		t.Errorf("Expected request ID %q in context for audit events, got %q", requestID, ctxRequestID)
+	}
+
+	line := strings.TrimSpace(logs.String())
+	for _, want := range []string{
+		"request_id=" + requestID,
+		"method=POST",
+		`path="/api/v1/games/play"`,
+		"status=418",
+		"player_id=player-1",
+	} {
+		if !strings.Contains(line, want) {
+			t.Errorf("Expected log line to contain %q, got %q", want, line)
+		}
+	}
+	if !regexp.MustCompile(`latency=\S+s `).MatchString(line) {
+		t.Errorf("Expected log line to contain the latency, got %q", line)
+	}
+
+	t.Run("UnauthenticatedRequest", func(t *testing.T) {
+		logs.Reset()
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/wallet/balance", nil))
+
+		if rec.Header().Get(RequestIDHeader) == "" {
+			t.Error("Expected X-Request-ID header on error responses")
+		}
+		line := logs.String()
+		if !strings.Contains(line, "status=401") || !strings.Contains(line, "player_id=-") {
+			t.Errorf("Expected 401 without player in log line, got %q", line)
+		}
+	})
+}
+
+func TestRecoveryMiddleware(t *testing.T) {
+	h := newTestAuthHandler()
+	h.accessLog = log.New(&bytes.Buffer{}, "", 0)
+	recorder := &recordingAudit{}
+	h.audit = recorder
+
+	handler := h.LoggingMiddleware(h.RecoveryMiddleware(h.AuthMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var session *domain.Session
+		_ = session.ID // nil pointer dereference
+	}))))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/logout", nil)
+	req.Header.Set("Authorization", "Bearer valid-token")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("Expected status 500, got %d", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Expected JSON response, got content type %q", ct)
+	}
+	if code := decodeError(t, rec); code != "INTERNAL_ERROR" {
+		t.Errorf("Expected INTERNAL_ERROR, got %s", code)
+	}
+
+	if len(recorder.events) != 1 {
+		t.Fatalf("Expected 1 audit event, got %d", len(recorder.events))
+	}
+	event := recorder.events[0]
+	if event.Type != audit.EventSystemError || event.Severity != domain.SeverityCritical {
+		t.Errorf("Expected critical system_error event, got %s/%s", event.Type, event.Severity)
+	}
+	if event.PlayerID == nil || *event.PlayerID != "player-1" {
+		t.Errorf("Expected event for player-1, got %v", event.PlayerID)
+	}
+	if event.RequestID == "" || event.RequestID != rec.Header().Get(RequestIDHeader) {
+		t.Errorf("Expected event to carry the request ID, got %q", event.RequestID)
+	}
+	if !strings.Contains(string(event.Data), "nil pointer dereference") {
+		t.Errorf("Expected panic value in event data, got %s", event.Data)
+	}
+}
+
+func TestAdminMiddleware(t *testing.T) {
+	h := newTestAuthHandler()
+	handler := h.AuthMiddleware(h.AdminMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})))
+
+	serve := func(token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/status", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("PlayerIsForbidden", func(t *testing.T) {
+		rec := serve("valid-token")
+		if rec.Code != http.StatusForbidden {
+			t.Fatalf("Expected status 403, got %d", rec.Code)
+		}
+		if code := decodeError(t, rec); code != "FORBIDDEN" {
+			t.Errorf("Expected FORBIDDEN, got %s", code)
+		}
+	})
+
+	t.Run("AdminIsAllowed", func(t *testing.T) {
+		if rec := serve("admin-token"); rec.Code != http.StatusNoContent {
+			t.Errorf("Expected status 204, got %d", rec.Code)
+		}
+	})
+}
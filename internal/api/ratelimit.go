@@ -0,0 +1,156 @@
+// Package api - Rate limiting per client IP and per player
+package api
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// rateLimitSweepInterval is how often buckets that have refilled are dropped
+const rateLimitSweepInterval = time.Minute
+
+// RateLimit is a token bucket: requests are allowed while tokens remain and
+// tokens refill at Rate per second up to Burst. A zero Rate disables it.
+type RateLimit struct {
+	Rate  float64
+	Burst int
+}
+
+// rateLimiter keeps one token bucket per key
+type rateLimiter struct {
+	limit RateLimit
+	now   func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+type tokenBucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// newRateLimiter returns a limiter, or nil when the limit is disabled
+func newRateLimiter(limit RateLimit) *rateLimiter {
+	if limit.Rate <= 0 {
+		return nil
+	}
+	if limit.Burst < 1 {
+		limit.Burst = 1
+	}
+	return &rateLimiter{
+		limit:   limit,
+		now:     time.Now,
+		buckets: make(map[string]*tokenBucket),
+	}
+}
+
+// allow takes a token for key. When none is left it returns false and how
+// long until the next token is available.
+func (l *rateLimiter) allow(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if now.Sub(l.lastSweep) >= rateLimitSweepInterval {
+		l.sweep(now)
+	}
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &tokenBucket{tokens: float64(l.limit.Burst), updated: now}
+		l.buckets[key] = b
+	}
+	b.refill(now, l.limit)
+
+	if b.tokens < 1 {
+		wait := time.Duration((1 - b.tokens) / l.limit.Rate * float64(time.Second))
+		return false, wait
+	}
+	b.tokens--
+	return true, 0
+}
+
+// sweep drops buckets that are full again; they behave like new ones
+func (l *rateLimiter) sweep(now time.Time) {
+	for key, b := range l.buckets {
+		b.refill(now, l.limit)
+		if b.tokens >= float64(l.limit.Burst) {
+			delete(l.buckets, key)
+		}
+	}
+	l.lastSweep = now
+}
+
+func (b *tokenBucket) refill(now time.Time, limit RateLimit) {
+	elapsed := now.Sub(b.updated).Seconds()
+	if elapsed > 0 {
+		b.tokens = math.Min(float64(limit.Burst), b.tokens+elapsed*limit.Rate)
+		b.updated = now
+	}
+}
+
+// SetRateLimits sets the limits for unauthenticated requests, per client IP;
+// for protected requests, per client IP before the token is checked; and for
+// authenticated requests, per player
+func (h *Handler) SetRateLimits(perIP, perProtectedIP, perPlayer RateLimit) {
+	h.ipLimiter = newRateLimiter(perIP)
+	h.protectedIPLimiter = newRateLimiter(perProtectedIP)
+	h.playerLimiter = newRateLimiter(perPlayer)
+}
+
+// IPRateLimitMiddleware limits unauthenticated requests per client IP
+func (h *Handler) IPRateLimitMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !checkRateLimit(w, h.ipLimiter, h.clientIP(r)) {
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// ProtectedIPRateLimitMiddleware limits protected requests per client IP. It
+// runs before AuthMiddleware so requests with bad or missing tokens are
+// limited before any session lookup.
+func (h *Handler) ProtectedIPRateLimitMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !checkRateLimit(w, h.protectedIPLimiter, h.clientIP(r)) {
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// PlayerRateLimitMiddleware limits authenticated requests per player; it runs
+// after AuthMiddleware so the player is known
+func (h *Handler) PlayerRateLimitMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := h.clientIP(r)
+		if player, ok := playerFromContext(r); ok {
+			key = player.ID
+		}
+		if !checkRateLimit(w, h.playerLimiter, key) {
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// checkRateLimit takes a token for key, responding 429 with Retry-After when
+// the limit is exceeded. A nil limiter allows everything.
+func checkRateLimit(w http.ResponseWriter, l *rateLimiter, key string) bool {
+	if l == nil {
+		return true
+	}
+	ok, wait := l.allow(key)
+	if ok {
+		return true
+	}
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+	respondError(w, http.StatusTooManyRequests, "RATE_LIMITED", "Too many requests, please retry later")
+	return false
+}
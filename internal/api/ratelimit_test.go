@@ -0,0 +1,144 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterRefill(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	l := newRateLimiter(RateLimit{Rate: 2, Burst: 3})
+	l.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		if ok, _ := l.allow("key"); !ok {
+			t.Fatalf("Request %d within burst should be allowed", i+1)
+		}
+	}
+	ok, wait := l.allow("key")
+	if ok {
+		t.Fatal("Request beyond burst should be refused")
+	}
+	if wait != 500*time.Millisecond {
+		t.Errorf("Expected to wait 500ms for the next token, got %v", wait)
+	}
+
+	if ok, _ := l.allow("other"); !ok {
+		t.Error("Buckets should be independent per key")
+	}
+
+	now = now.Add(500 * time.Millisecond)
+	if ok, _ := l.allow("key"); !ok {
+		t.Error("Request should be allowed once a token has refilled")
+	}
+	if ok, _ := l.allow("key"); ok {
+		t.Error("Only one token should have refilled")
+	}
+
+	now = now.Add(rateLimitSweepInterval)
+	l.allow("key")
+	if _, ok := l.buckets["other"]; ok {
+		t.Error("Refilled buckets should be swept")
+	}
+
+	if newRateLimiter(RateLimit{}) != nil {
+		t.Error("A zero rate should disable the limiter")
+	}
+}
+
+func TestIPRateLimitMiddleware(t *testing.T) {
+	h := newTestAuthHandler()
+	h.SetRateLimits(RateLimit{Rate: 1, Burst: 2}, RateLimit{}, RateLimit{})
+	handler := h.IPRateLimitMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	serve := func(ip string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/login", nil)
+		req.RemoteAddr = ip + ":1234"
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	for i := 0; i < 2; i++ {
+		if rec := serve("203.0.113.1"); rec.Code != http.StatusNoContent {
+			t.Fatalf("Request %d: expected status 204, got %d", i+1, rec.Code)
+		}
+	}
+
+	rec := serve("203.0.113.1")
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("Expected status 429, got %d", rec.Code)
+	}
+	if got := rec.Header().Get("Retry-After"); got != "1" {
+		t.Errorf("Expected Retry-After 1, got %q", got)
+	}
+	if code := decodeError(t, rec); code != "RATE_LIMITED" {
+		t.Errorf("Expected RATE_LIMITED, got %s", code)
+	}
+
+	if rec := serve("203.0.113.2"); rec.Code != http.StatusNoContent {
+		t.Errorf("Another IP should not be limited, got %d", rec.Code)
+	}
+}
+
+func TestProtectedIPRateLimitBeforeAuth(t *testing.T) {
+	h := newTestAuthHandler()
+	h.SetRateLimits(RateLimit{}, RateLimit{Rate: 1, Burst: 1}, RateLimit{})
+	router := h.SetupRouter()
+
+	serve := func(token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/wallet/balance", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.RemoteAddr = "203.0.113.1:1234"
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := serve("guessed-token"); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("Expected status 401, got %d", rec.Code)
+	}
+
+	// Bad tokens spend the address's budget before they reach the session lookup
+	rec := serve("another-guess")
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("Expected status 429, got %d", rec.Code)
+	}
+	if code := decodeError(t, rec); code != "RATE_LIMITED" {
+		t.Errorf("Expected RATE_LIMITED, got %s", code)
+	}
+}
+
+func TestPlayerRateLimitMiddleware(t *testing.T) {
+	h := newTestAuthHandler()
+	h.SetRateLimits(RateLimit{}, RateLimit{}, RateLimit{Rate: 1, Burst: 1})
+	handler := h.AuthMiddleware(h.PlayerRateLimitMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})))
+
+	serve := func(ip string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/games/play", nil)
+		req.Header.Set("Authorization", "Bearer valid-token")
+		req.RemoteAddr = ip + ":1234"
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := serve("203.0.113.1"); rec.Code != http.StatusNoContent {
+		t.Fatalf("Expected status 204, got %d", rec.Code)
+	}
+
+	// The limit follows the player, not the address
+	rec := serve("203.0.113.2")
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("Expected status 429, got %d", rec.Code)
+	}
+	if rec.Header().Get("Retry-After") == "" {
+		t.Error("Expected Retry-After header")
+	}
+}
@@ -3,6 +3,7 @@ package api
 
 import (
 	"net/http"
+	"strings"
 
 	"github.com/gorilla/mux"
 )
@@ -12,9 +13,9 @@ func (h *Handler) SetupRouter() *mux.Router {
 	r := mux.NewRouter()
 
 	// Apply global middleware
-	r.Use(RecoveryMiddleware)
+	r.Use(h.LoggingMiddleware)
+	r.Use(h.RecoveryMiddleware)
 	r.Use(CORSMiddleware)
-	r.Use(LoggingMiddleware)
 
 	// Public routes
 	r.HandleFunc("/", h.ServerInfo).Methods("GET")
@@ -25,15 +26,20 @@ func (h *Handler) SetupRouter() *mux.Router {
 
 	// Auth routes (public)
 	auth := api.PathPrefix("/auth").Subrouter()
+	auth.Use(h.IPRateLimitMiddleware)
+	auth.HandleFunc("/register", h.Register).Methods("POST")
 	auth.HandleFunc("/login", h.Login).Methods("POST")
 
 	// Protected routes
 	protected := api.PathPrefix("").Subrouter()
+	protected.Use(h.ProtectedIPRateLimitMiddleware)
 	protected.Use(h.AuthMiddleware)
+	protected.Use(h.PlayerRateLimitMiddleware)
 
 	// Auth (protected)
 	protected.HandleFunc("/auth/logout", h.Logout).Methods("POST")
 	protected.HandleFunc("/auth/session", h.GetSession).Methods("GET")
+	protected.HandleFunc("/auth/refresh", h.RefreshSession).Methods("POST")
 
 	// Wallet
 	protected.HandleFunc("/wallet/balance", h.GetBalance).Methods("GET")
@@ -44,17 +50,136 @@ func (h *Handler) SetupRouter() *mux.Router {
 	// Games
 	protected.HandleFunc("/games", h.GetGames).Methods("GET")
 	protected.HandleFunc("/games/history", h.GetGameHistory).Methods("GET")
+	protected.HandleFunc("/games/history/{cycle_id}", h.GetGameCycle).Methods("GET")
 	protected.HandleFunc("/games/play", h.Play).Methods("POST")
+	protected.HandleFunc("/games/session/{id}", h.GetGameSession).Methods("GET")
+	protected.HandleFunc("/games/interrupted", h.GetInterruptedGames).Methods("GET")
 	protected.HandleFunc("/games/{id}", h.GetGame).Methods("GET")
 	protected.HandleFunc("/games/{id}/session", h.StartGameSession).Methods("POST")
 	protected.HandleFunc("/games/{id}/session", h.EndGameSession).Methods("DELETE")
+	protected.HandleFunc("/games/{cycle_id}/resume", h.ResumeGame).Methods("POST")
+	protected.HandleFunc("/games/{cycle_id}/void", h.VoidGame).Methods("POST")
 
-	// WebSocket for real-time games
-	protected.HandleFunc("/ws/game/{session_id}", h.HandleWebSocket).Methods("GET")
+	// Responsible gaming limits (GLI-19 §2.5.5)
+	protected.HandleFunc("/limits", h.GetLimits).Methods("GET")
+	protected.HandleFunc("/limits/status", h.GetResponsibleGamingStatus).Methods("GET")
+	protected.HandleFunc("/limits/deposit", h.SetDepositLimit).Methods("POST")
+	protected.HandleFunc("/limits/wager", h.SetWagerLimit).Methods("POST")
+	protected.HandleFunc("/limits/loss", h.SetLossLimit).Methods("POST")
+	protected.HandleFunc("/limits/self-exclude", h.SelfExclude).Methods("POST")
+	protected.HandleFunc("/limits/self-exclude", h.RemoveSelfExclusion).Methods("DELETE")
+
+	// Reality checks (GLI-19 §2.5.5)
+	protected.HandleFunc("/reality-check", h.GetRealityCheck).Methods("GET")
+	protected.HandleFunc("/reality-check/acknowledge", h.AcknowledgeRealityCheck).Methods("POST")
+
+	// Admin: gaming management (GLI-19 §2.4)
+	admin := protected.PathPrefix("/admin").Subrouter()
+	admin.Use(h.AdminMiddleware)
+	admin.HandleFunc("/status", h.GetSystemStatus).Methods("GET")
+	admin.HandleFunc("/gaming/disable", h.DisableGaming).Methods("POST")
+	admin.HandleFunc("/gaming/enable", h.EnableGaming).Methods("POST")
+	admin.HandleFunc("/games/{id}/disable", h.DisableGame).Methods("POST")
+	admin.HandleFunc("/games/{id}/enable", h.EnableGame).Methods("POST")
+	admin.HandleFunc("/players/{id}/disable", h.DisablePlayer).Methods("POST")
+	admin.HandleFunc("/players/{id}/enable", h.EnablePlayer).Methods("POST")
+	admin.HandleFunc("/players/{id}/self-exclusion/override", h.OverrideSelfExclusion).Methods("POST")
+
+	// WebSocket for real-time games; authenticates itself, see HandleWebSocket
+	api.Handle("/ws/game/{session_id}", h.IPRateLimitMiddleware(http.HandlerFunc(h.HandleWebSocket))).Methods("GET")
+
+	// Known paths requested with an unregistered method get 405/HEAD/OPTIONS handling.
+	// mux loses the method mismatch when routes live in nested subrouters and
+	// reports not found instead, so the not found handler checks as well.
+	r.MethodNotAllowedHandler = methodNotAllowedHandler(r)
+	r.NotFoundHandler = notFoundHandler(r)
 
 	return r
 }
 
+// notFoundHandler returns 404 unless the path is registered under another method
+func notFoundHandler(router *mux.Router) http.Handler {
+	methodNotAllowed := methodNotAllowedHandler(router)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(allowedMethods(router, r)) > 0 {
+			methodNotAllowed.ServeHTTP(w, r)
+			return
+		}
+		NotFoundHandler(w, r)
+	})
+}
+
+// methodNotAllowedHandler handles requests whose path matches a route but whose
+// method does not. HEAD is served by the matching GET route with the body
+// discarded, OPTIONS answers with the permitted methods, and anything else gets
+// a 405 with an Allow header.
+func methodNotAllowedHandler(router *mux.Router) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		allowed := allowedMethods(router, r)
+
+		switch r.Method {
+		case http.MethodHead:
+			if containsMethod(allowed, http.MethodGet) {
+				get := r.Clone(r.Context())
+				get.Method = http.MethodGet
+				router.ServeHTTP(headResponseWriter{w}, get)
+				return
+			}
+		case http.MethodOptions:
+			methods := strings.Join(append(allowed, http.MethodOptions), ", ")
+			setCORSHeaders(w, methods)
+			w.Header().Set("Allow", methods)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		setCORSHeaders(w, strings.Join(append(allowed, http.MethodOptions), ", "))
+		w.Header().Set("Allow", strings.Join(allowed, ", "))
+		respondError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "Method not allowed")
+	})
+}
+
+// allowedMethods returns the methods registered for routes matching the request path
+func allowedMethods(router *mux.Router, r *http.Request) []string {
+	var allowed []string
+	router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		methods, err := route.GetMethods()
+		if err != nil {
+			return nil
+		}
+		for _, method := range methods {
+			if containsMethod(allowed, method) {
+				continue
+			}
+			probe := r.Clone(r.Context())
+			probe.Method = method
+			if route.Match(probe, &mux.RouteMatch{}) {
+				allowed = append(allowed, method)
+			}
+		}
+		return nil
+	})
+	return allowed
+}
+
+func containsMethod(methods []string, method string) bool {
+	for _, m := range methods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+// headResponseWriter drops the response body so GET handlers can serve HEAD
+type headResponseWriter struct {
+	http.ResponseWriter
+}
+
+func (w headResponseWriter) Write(b []byte) (int, error) {
+	return len(b), nil
+}
+
 // NotFoundHandler handles 404 errors
 func NotFoundHandler(w http.ResponseWriter, r *http.Request) {
 	respondError(w, http.StatusNotFound, "NOT_FOUND", "Resource not found")
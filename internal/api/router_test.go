@@ -0,0 +1,79 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alexbotov/rgs/internal/rng"
+)
+
+func TestRouterMethodHandling(t *testing.T) {
+	h := New(nil, nil, nil, rng.New())
+	router := h.SetupRouter()
+
+	t.Run("MethodNotAllowed", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/games", nil)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusMethodNotAllowed {
+			t.Errorf("Expected status 405, got %d", rec.Code)
+		}
+		if allow := rec.Header().Get("Allow"); allow != "GET" {
+			t.Errorf("Expected Allow header GET, got %q", allow)
+		}
+	})
+
+	t.Run("MultipleAllowedMethods", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPut, "/api/v1/games/fortune-slots/session", nil)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusMethodNotAllowed {
+			t.Errorf("Expected status 405, got %d", rec.Code)
+		}
+		if allow := rec.Header().Get("Allow"); allow != "POST, DELETE" {
+			t.Errorf("Expected Allow header POST, DELETE, got %q", allow)
+		}
+	})
+
+	t.Run("HeadOnGetRoute", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodHead, "/health", nil)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Errorf("Expected status 200, got %d", rec.Code)
+		}
+		if rec.Body.Len() != 0 {
+			t.Errorf("Expected empty body, got %d bytes", rec.Body.Len())
+		}
+	})
+
+	t.Run("Options", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodOptions, "/api/v1/auth/login", nil)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusNoContent {
+			t.Errorf("Expected status 204, got %d", rec.Code)
+		}
+		if methods := rec.Header().Get("Access-Control-Allow-Methods"); methods != "POST, OPTIONS" {
+			t.Errorf("Expected Access-Control-Allow-Methods POST, OPTIONS, got %q", methods)
+		}
+		if origin := rec.Header().Get("Access-Control-Allow-Origin"); origin != "*" {
+			t.Errorf("Expected Access-Control-Allow-Origin *, got %q", origin)
+		}
+	})
+
+	t.Run("UnknownPath", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/unknown", nil)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404, got %d", rec.Code)
+		}
+	})
+}
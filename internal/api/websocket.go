@@ -4,17 +4,31 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/alexbotov/rgs/internal/database"
 	"github.com/alexbotov/rgs/internal/domain"
+	"github.com/alexbotov/rgs/internal/events"
 	"github.com/alexbotov/rgs/internal/game"
 	"github.com/gorilla/mux"
 	"github.com/gorilla/websocket"
 )
 
+const (
+	// wsAuthTimeout is how long a client has to send its auth message
+	wsAuthTimeout = 10 * time.Second
+
+	// wsSessionCheckInterval is how often an open socket re-validates its session
+	wsSessionCheckInterval = time.Minute
+)
+
+var errWSAuthRequired = errors.New("first message must be an auth message with a token")
+
 var upgrader = websocket.Upgrader{
 	ReadBufferSize:  1024,
 	WriteBufferSize: 1024,
@@ -36,28 +50,111 @@ type WSClient struct {
 	sessionID string
 	playerID  string
 	mu        sync.Mutex
+	balance   *balanceThrottle
+	done      chan struct{} // Closed when the read pump exits
+	closed    bool          // send is closed; guarded by mu
+
+	// ctx carries the upgrade request's values for the connection's
+	// lifetime and is cancelled when the read pump exits
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// authSessionID is the player's login session, watched while open
+	authSessionID string
 }
 
-// HandleWebSocket handles WebSocket connections for game sessions
-func (h *Handler) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
-	session := r.Context().Value("session").(*domain.Session)
-	player := r.Context().Value("player").(*domain.Player)
-	gameSessionID := mux.Vars(r)["session_id"]
+// balanceThrottle coalesces rapid balance updates so that at most one is sent
+// per interval. The most recent balance always wins, so the client never ends
+// up displaying a stale value once a burst of plays settles.
+type balanceThrottle struct {
+	interval time.Duration
+	flush    func(balance domain.Money)
+	mu       sync.Mutex
+	latest   domain.Money
+	lastSent time.Time
+	timer    *time.Timer
+	stopped  bool
+}
 
-	// Verify game session exists and belongs to player
-	gameSession, err := h.game.GetSession(r.Context(), gameSessionID)
-	if err != nil {
-		http.Error(w, "Game session not found", http.StatusNotFound)
+func newBalanceThrottle(interval time.Duration, flush func(balance domain.Money)) *balanceThrottle {
+	return &balanceThrottle{
+		interval: interval,
+		flush:    flush,
+	}
+}
+
+// update records the latest authoritative balance and sends it immediately if
+// the interval has elapsed, otherwise schedules it for the end of the interval
+func (t *balanceThrottle) update(balance domain.Money) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.stopped {
 		return
 	}
-	if gameSession.PlayerID != player.ID {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+	t.latest = balance
+
+	// A flush is already scheduled and will pick up the latest balance
+	if t.timer != nil {
 		return
 	}
-	if gameSession.Status != domain.GameSessionActive {
-		http.Error(w, "Game session is not active", http.StatusBadRequest)
+
+	wait := t.interval - time.Since(t.lastSent)
+	if wait <= 0 {
+		t.send()
 		return
 	}
+	t.timer = time.AfterFunc(wait, func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		t.timer = nil
+		if !t.stopped {
+			t.send()
+		}
+	})
+}
+
+// send must be called with t.mu held
+func (t *balanceThrottle) send() {
+	t.lastSent = time.Now()
+	t.flush(t.latest)
+}
+
+// stop cancels any pending update; no further updates are sent afterwards
+func (t *balanceThrottle) stop() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.stopped = true
+	if t.timer != nil {
+		t.timer.Stop()
+		t.timer = nil
+	}
+}
+
+// HandleWebSocket handles WebSocket connections for game sessions.
+// Browsers cannot set an Authorization header on a WebSocket, so the token may
+// also be given in the token query parameter, or sent in an auth message as
+// the first message after connecting.
+func (h *Handler) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	gameSessionID := mux.Vars(r)["session_id"]
+
+	var session *domain.Session
+	var player *domain.Player
+	token := wsToken(r)
+	if token != "" {
+		var err error
+		session, player, err = h.validateToken(ctx, token)
+		if err != nil {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if status, message := h.verifyGameSession(ctx, gameSessionID, player.ID); status != 0 {
+			http.Error(w, message, status)
+			return
+		}
+	}
 
 	// Upgrade connection
 	conn, err := upgrader.Upgrade(w, r, nil)
@@ -66,16 +163,166 @@ func (h *Handler) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	client := &WSClient{
-		conn:      conn,
-		send:      make(chan []byte, 256),
-		sessionID: gameSessionID,
-		playerID:  player.ID,
+	if token == "" {
+		session, player, token, err = h.authenticateWS(ctx, conn)
+		if err != nil {
+			closeWS(conn, websocket.ClosePolicyViolation, "authentication failed")
+			return
+		}
+		if status, message := h.verifyGameSession(ctx, gameSessionID, player.ID); status != 0 {
+			closeWS(conn, websocket.ClosePolicyViolation, message)
+			return
+		}
 	}
 
+	// The request context ends when this handler returns, but the
+	// connection outlives it
+	client := h.newWSClient(context.WithoutCancel(ctx), conn, gameSessionID, player.ID)
+	client.authSessionID = session.ID
+	h.clientsMu.Lock()
+	h.clients[client] = struct{}{}
+	h.clientsMu.Unlock()
+
 	// Start goroutines for reading and writing
 	go client.writePump()
-	go h.readPump(client, session.ID)
+	go h.readPump(client)
+	go h.watchSession(client)
+	if h.events != nil {
+		go h.forwardEvents(client, h.events.Subscribe(player.ID))
+	}
+}
+
+// forwardEvents pushes the player's events to the client until it disconnects.
+// Balance updates share the client's throttle with play results.
+func (h *Handler) forwardEvents(c *WSClient, sub *events.Subscription) {
+	defer sub.Close()
+
+	for {
+		select {
+		case <-c.done:
+			return
+		case event := <-sub.C:
+			if event.Type == events.BalanceUpdate {
+				c.balance.update(event.Balance)
+				continue
+			}
+			h.sendMessage(c, string(event.Type), event.Data)
+		}
+	}
+}
+
+// wsToken returns the token presented with the upgrade request, if any
+func wsToken(r *http.Request) string {
+	if parts := strings.SplitN(r.Header.Get("Authorization"), " ", 2); len(parts) == 2 && strings.ToLower(parts[0]) == "bearer" {
+		return parts[1]
+	}
+	return r.URL.Query().Get("token")
+}
+
+// authenticateWS waits for the client's auth message and validates its token
+func (h *Handler) authenticateWS(ctx context.Context, conn *websocket.Conn) (*domain.Session, *domain.Player, string, error) {
+	conn.SetReadDeadline(time.Now().Add(wsAuthTimeout))
+	defer conn.SetReadDeadline(time.Time{})
+
+	var msg WSMessage
+	if err := conn.ReadJSON(&msg); err != nil {
+		return nil, nil, "", err
+	}
+	if msg.Type != "auth" {
+		return nil, nil, "", errWSAuthRequired
+	}
+	var payload struct {
+		Token string `json:"token"`
+	}
+	if err := json.Unmarshal(msg.Payload, &payload); err != nil || payload.Token == "" {
+		return nil, nil, "", errWSAuthRequired
+	}
+
+	session, player, err := h.validateToken(ctx, payload.Token)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	return session, player, payload.Token, nil
+}
+
+// verifyGameSession checks the game session exists, belongs to the player and
+// is active. It returns a zero status when the session can be used.
+func (h *Handler) verifyGameSession(ctx context.Context, gameSessionID, playerID string) (int, string) {
+	gameSession, err := h.game.GetSession(ctx, gameSessionID)
+	if err != nil {
+		return http.StatusNotFound, "Game session not found"
+	}
+	if gameSession.PlayerID != playerID {
+		return http.StatusUnauthorized, "Unauthorized"
+	}
+	if gameSession.Status != domain.GameSessionActive {
+		return http.StatusBadRequest, "Game session is not active"
+	}
+	return 0, ""
+}
+
+// watchSession checks the player's session while the socket is open and
+// closes the socket once the session has expired or been logged out. The
+// check does not count as activity and follows the session across token
+// refreshes.
+func (h *Handler) watchSession(c *WSClient) {
+	ticker := time.NewTicker(wsSessionCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-ticker.C:
+			ctx, cancel := database.BoundedContext(c.ctx, h.queryTimeout)
+			err := h.checkSession(ctx, c.authSessionID)
+			cancel()
+			if err != nil {
+				closeWS(c.conn, websocket.ClosePolicyViolation, "session expired")
+				return
+			}
+		}
+	}
+}
+
+// CloseWebSockets tells every connected client the server is going away and
+// closes the connections; used during shutdown since http.Server.Shutdown does
+// not close hijacked connections
+func (h *Handler) CloseWebSockets() {
+	h.clientsMu.Lock()
+	defer h.clientsMu.Unlock()
+
+	for c := range h.clients {
+		closeWS(c.conn, websocket.CloseGoingAway, "server shutting down")
+	}
+}
+
+// closeWS sends a close frame with the reason and closes the connection
+func closeWS(conn *websocket.Conn, code int, reason string) {
+	conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason),
+		time.Now().Add(time.Second))
+	conn.Close()
+}
+
+// newWSClient creates a client whose balance updates are throttled to the
+// handler's configured interval. Its messages are handled in contexts
+// derived from ctx.
+func (h *Handler) newWSClient(ctx context.Context, conn *websocket.Conn, sessionID, playerID string) *WSClient {
+	client := &WSClient{
+		conn:      conn,
+		send:      make(chan []byte, 256),
+		sessionID: sessionID,
+		playerID:  playerID,
+		done:      make(chan struct{}),
+	}
+	client.ctx, client.cancel = context.WithCancel(ctx)
+	client.balance = newBalanceThrottle(h.balanceUpdateInterval, func(balance domain.Money) {
+		h.sendMessage(client, "balance_update", map[string]interface{}{
+			"balance":  balance.Float64(),
+			"currency": balance.Currency,
+		})
+	})
+	return client
 }
 
 // writePump pumps messages from the send channel to the WebSocket connection
@@ -112,9 +359,19 @@ func (c *WSClient) writePump() {
 }
 
 // readPump pumps messages from the WebSocket connection to the handler
-func (h *Handler) readPump(c *WSClient, authSessionID string) {
+func (h *Handler) readPump(c *WSClient) {
 	defer func() {
+		// Stop pending balance updates before closing the send channel
+		c.balance.stop()
+		h.clientsMu.Lock()
+		delete(h.clients, c)
+		h.clientsMu.Unlock()
+		c.cancel()
+		close(c.done)
+		c.mu.Lock()
+		c.closed = true
 		close(c.send)
+		c.mu.Unlock()
 		c.conn.Close()
 	}()
 
@@ -152,13 +409,16 @@ func (h *Handler) readPump(c *WSClient, authSessionID string) {
 	}
 }
 
-// handleWSMessage processes incoming WebSocket messages
+// handleWSMessage processes incoming WebSocket messages. Each message's
+// database work is bounded by the handler's query timeout, so a stalled
+// database cannot hang the connection's read pump.
 func (h *Handler) handleWSMessage(c *WSClient, msg *WSMessage) {
-	ctx := context.Background()
+	ctx, cancel := database.BoundedContext(c.ctx, h.queryTimeout)
+	defer cancel()
 
 	switch msg.Type {
 	case "spin", "play":
-		h.handlePlayMessage(c, msg)
+		h.handlePlayMessage(ctx, c, msg)
 
 	case "balance":
 		balance, err := h.wallet.GetBalance(ctx, c.playerID)
@@ -206,9 +466,7 @@ func (h *Handler) handleWSMessage(c *WSClient, msg *WSMessage) {
 }
 
 // handlePlayMessage processes play/spin messages
-func (h *Handler) handlePlayMessage(c *WSClient, msg *WSMessage) {
-	ctx := context.Background()
-
+func (h *Handler) handlePlayMessage(ctx context.Context, c *WSClient, msg *WSMessage) {
 	// Parse wager amount
 	var payload struct {
 		WagerAmount int64 `json:"wager_amount"`
@@ -218,6 +476,16 @@ func (h *Handler) handlePlayMessage(c *WSClient, msg *WSMessage) {
 		return
 	}
 
+	// A play is player activity, so it keeps the session alive, but only
+	// while the session is still usable
+	if err := h.checkSession(ctx, c.authSessionID); err != nil {
+		closeWS(c.conn, websocket.ClosePolicyViolation, "session expired")
+		return
+	}
+	if err := h.touchSession(ctx, c.authSessionID); err != nil {
+		log.Printf("Failed to record session activity: %v", err)
+	}
+
 	// Execute play
 	result, err := h.game.Play(ctx, &game.PlayRequest{
 		SessionID:   c.sessionID,
@@ -231,25 +499,58 @@ func (h *Handler) handlePlayMessage(c *WSClient, msg *WSMessage) {
 			h.sendError(c, "INVALID_WAGER", "Invalid wager amount")
 		case game.ErrSessionNotActive:
 			h.sendError(c, "SESSION_NOT_ACTIVE", "Game session is not active")
+		case game.ErrWagerLimitExceeded:
+			h.sendError(c, "WAGER_LIMIT_EXCEEDED", "Wager would exceed your wager limit")
+		case game.ErrLossLimitExceeded:
+			h.sendError(c, "LOSS_LIMIT_EXCEEDED", "Loss limit reached")
+		case game.ErrTimeLimitExceeded:
+			h.sendError(c, "TIME_LIMIT_EXCEEDED", "Play time limit reached")
+		case game.ErrPlayerExcluded:
+			h.sendError(c, "PLAYER_EXCLUDED", "Player is self-excluded")
+		case game.ErrShuttingDown:
+			h.sendError(c, "SHUTTING_DOWN", "Server is shutting down")
 		default:
 			h.sendError(c, "GAME_ERROR", err.Error())
 		}
 		return
 	}
 
-	// Send result
-	h.sendMessage(c, "outcome", map[string]interface{}{
+	h.sendPlayResult(c, result)
+}
+
+// sendPlayResult sends the outcome of a play to the client. Every outcome is
+// delivered for game history; the resulting balance goes only through the
+// client's throttle so rapid wins don't flood the connection.
+func (h *Handler) sendPlayResult(c *WSClient, result *game.PlayResult) {
+	payload := map[string]interface{}{
 		"cycle_id":     result.CycleID,
 		"outcome":      result.Outcome,
 		"wager_amount": result.WagerAmount.Float64(),
 		"win_amount":   result.WinAmount.Float64(),
-		"balance":      result.Balance.Float64(),
 		"is_win":       result.Outcome.IsWin,
-	})
+	}
+	if result.Session != nil {
+		payload["session"] = h.sessionTotalsResponse(result.Session)
+	}
+	// A client too slow to take an outcome is disconnected rather than
+	// silently missing it; it can read the result from its history
+	if !h.queueMessage(c, "outcome", payload) {
+		closeWS(c.conn, websocket.CloseTryAgainLater, "client too slow")
+		return
+	}
+	c.balance.update(result.Balance)
 }
 
-// sendMessage sends a message to the client
+// sendMessage sends a message to the client, dropping it if the client's
+// buffer is full
 func (h *Handler) sendMessage(c *WSClient, msgType string, payload interface{}) {
+	h.queueMessage(c, msgType, payload)
+}
+
+// queueMessage queues a message for the client and reports whether it was
+// queued; it is not when the buffer is full. A closed client counts as
+// queued since there is nobody left to deliver to.
+func (h *Handler) queueMessage(c *WSClient, msgType string, payload interface{}) bool {
 	payloadBytes, _ := json.Marshal(payload)
 	msg := WSMessage{
 		Type:    msgType,
@@ -260,10 +561,14 @@ func (h *Handler) sendMessage(c *WSClient, msgType string, payload interface{})
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
+	if c.closed {
+		return true
+	}
 	select {
 	case c.send <- msgBytes:
+		return true
 	default:
-		// Channel full, drop message
+		return false
 	}
 }
 
@@ -0,0 +1,268 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alexbotov/rgs/internal/auth"
+	"github.com/alexbotov/rgs/internal/domain"
+	"github.com/alexbotov/rgs/internal/events"
+	"github.com/alexbotov/rgs/internal/game"
+	"github.com/gorilla/websocket"
+)
+
+func TestBalanceThrottle(t *testing.T) {
+	h := New(nil, nil, nil, nil)
+	h.SetBalanceUpdateInterval(100 * time.Millisecond)
+
+	t.Run("BurstCoalescesBalanceUpdates", func(t *testing.T) {
+		client := h.newWSClient(context.Background(), nil, "session-1", "player-1")
+		defer client.balance.stop()
+
+		plays := 50
+		for i := 1; i <= plays; i++ {
+			h.sendPlayResult(client, &game.PlayResult{
+				CycleID:     "cycle",
+				Outcome:     &game.SlotOutcome{IsWin: true},
+				WagerAmount: domain.Money{Amount: 100, Currency: "USD"},
+				WinAmount:   domain.Money{Amount: 200, Currency: "USD"},
+				Balance:     domain.Money{Amount: int64(10000 + i*100), Currency: "USD"},
+			})
+		}
+
+		// Wait for the trailing update to flush
+		time.Sleep(250 * time.Millisecond)
+
+		outcomes := 0
+		var balances []float64
+		for len(client.send) > 0 {
+			var msg WSMessage
+			if err := json.Unmarshal(<-client.send, &msg); err != nil {
+				t.Fatalf("Failed to decode message: %v", err)
+			}
+			switch msg.Type {
+			case "outcome":
+				outcomes++
+				var payload map[string]interface{}
+				if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+					t.Fatalf("Failed to decode outcome payload: %v", err)
+				}
+				if _, ok := payload["balance"]; ok {
+					t.Error("Outcome should leave the balance to the throttle")
+				}
+			case "balance_update":
+				var payload struct {
+					Balance float64 `json:"balance"`
+				}
+				if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+					t.Fatalf("Failed to decode balance payload: %v", err)
+				}
+				balances = append(balances, payload.Balance)
+			}
+		}
+
+		if outcomes != plays {
+			t.Errorf("Expected %d outcome messages, got %d", plays, outcomes)
+		}
+		if len(balances) == 0 || len(balances) >= plays {
+			t.Errorf("Expected fewer balance updates than plays, got %d", len(balances))
+		}
+		expected := float64(10000+plays*100) / 100
+		if len(balances) > 0 && balances[len(balances)-1] != expected {
+			t.Errorf("Expected latest balance %.2f, got %.2f", expected, balances[len(balances)-1])
+		}
+	})
+
+	t.Run("NoUpdatesAfterStop", func(t *testing.T) {
+		client := h.newWSClient(context.Background(), nil, "session-2", "player-1")
+
+		client.balance.update(domain.Money{Amount: 100, Currency: "USD"})
+		client.balance.update(domain.Money{Amount: 200, Currency: "USD"})
+		client.balance.stop()
+
+		time.Sleep(150 * time.Millisecond)
+
+		if len(client.send) != 1 {
+			t.Errorf("Expected 1 balance update before stop, got %d", len(client.send))
+		}
+	})
+}
+
+func TestSlowClientDisconnectedOnOutcome(t *testing.T) {
+	h := New(nil, nil, nil, nil)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("Upgrade failed: %v", err)
+			return
+		}
+		// No write pump, so the send buffer fills up
+		client := h.newWSClient(context.Background(), conn, "session-1", "player-1")
+		defer client.balance.stop()
+		for len(client.send) < cap(client.send) {
+			h.sendMessage(client, "ping", nil)
+		}
+		h.sendPlayResult(client, &game.PlayResult{
+			CycleID: "cycle",
+			Outcome: &game.SlotOutcome{},
+			Balance: domain.Money{Amount: 10000, Currency: "USD"},
+		})
+	}))
+	defer server.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer conn.Close()
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, _, err = conn.ReadMessage()
+	if !websocket.IsCloseError(err, websocket.CloseTryAgainLater) {
+		t.Errorf("Expected the connection to be closed, got %v", err)
+	}
+}
+
+func TestWebSocketAuthMessage(t *testing.T) {
+	h := New(nil, nil, nil, nil)
+	h.validateToken = func(ctx context.Context, token string) (*domain.Session, *domain.Player, error) {
+		if token != "valid-token" {
+			return nil, nil, auth.ErrSessionExpired
+		}
+		return &domain.Session{ID: "session-1"}, &domain.Player{ID: "player-1"}, nil
+	}
+
+	type authResult struct {
+		player *domain.Player
+		err    error
+	}
+	results := make(chan authResult, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("Upgrade failed: %v", err)
+			return
+		}
+		defer conn.Close()
+		_, player, _, err := h.authenticateWS(r.Context(), conn)
+		results <- authResult{player, err}
+	}))
+	defer server.Close()
+
+	authenticate := func(t *testing.T, msg string) authResult {
+		t.Helper()
+		conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+		if err != nil {
+			t.Fatalf("Dial failed: %v", err)
+		}
+		defer conn.Close()
+		if err := conn.WriteMessage(websocket.TextMessage, []byte(msg)); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+		select {
+		case result := <-results:
+			return result
+		case <-time.After(2 * time.Second):
+			t.Fatal("Timed out waiting for authentication")
+			return authResult{}
+		}
+	}
+
+	t.Run("ValidToken", func(t *testing.T) {
+		result := authenticate(t, `{"type":"auth","payload":{"token":"valid-token"}}`)
+		if result.err != nil {
+			t.Fatalf("Expected authentication to succeed, got %v", result.err)
+		}
+		if result.player.ID != "player-1" {
+			t.Errorf("Expected player-1, got %s", result.player.ID)
+		}
+	})
+
+	t.Run("InvalidToken", func(t *testing.T) {
+		result := authenticate(t, `{"type":"auth","payload":{"token":"stolen-token"}}`)
+		if result.err != auth.ErrSessionExpired {
+			t.Errorf("Expected ErrSessionExpired, got %v", result.err)
+		}
+	})
+
+	t.Run("FirstMessageNotAuth", func(t *testing.T) {
+		result := authenticate(t, `{"type":"spin","payload":{"wager_amount":100}}`)
+		if result.err != errWSAuthRequired {
+			t.Errorf("Expected errWSAuthRequired, got %v", result.err)
+		}
+	})
+}
+
+func TestWebSocketPushesPlayerEvents(t *testing.T) {
+	hub := events.New()
+	h := New(nil, nil, nil, nil)
+	h.SetEvents(hub)
+	h.SetBalanceUpdateInterval(0)
+
+	subscribed := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("Upgrade failed: %v", err)
+			return
+		}
+		client := h.newWSClient(context.Background(), conn, "session-1", "player-1")
+		sub := hub.Subscribe("player-1")
+		go client.writePump()
+		go h.readPump(client)
+		go h.forwardEvents(client, sub)
+		close(subscribed)
+	}))
+	defer server.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer conn.Close()
+	<-subscribed
+
+	// What the wallet publishes after a deposit
+	hub.Publish(events.Event{
+		Type:     events.BalanceUpdate,
+		PlayerID: "player-1",
+		Balance:  domain.Money{Amount: 12500, Currency: "USD"},
+	})
+	hub.Publish(events.Event{
+		Type:     events.LimitWarning,
+		PlayerID: "player-1",
+		Data:     map[string]interface{}{"limit": "loss", "reached": true},
+	})
+
+	received := map[string]json.RawMessage{}
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	for len(received) < 2 {
+		var msg WSMessage
+		if err := conn.ReadJSON(&msg); err != nil {
+			t.Fatalf("Read failed after %v: %v", received, err)
+		}
+		if msg.Type != "connected" {
+			received[msg.Type] = msg.Payload
+		}
+	}
+
+	var balance struct {
+		Balance  float64 `json:"balance"`
+		Currency string  `json:"currency"`
+	}
+	if err := json.Unmarshal(received["balance_update"], &balance); err != nil {
+		t.Fatalf("Expected balance_update: %v", err)
+	}
+	if balance.Balance != 125.00 || balance.Currency != "USD" {
+		t.Errorf("Expected 125.00 USD, got %.2f %s", balance.Balance, balance.Currency)
+	}
+	if _, ok := received["limit_warning"]; !ok {
+		t.Error("Expected limit_warning")
+	}
+}
@@ -5,8 +5,11 @@ package audit
 import (
 	"context"
 	"database/sql"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/alexbotov/rgs/internal/domain"
@@ -19,6 +22,7 @@ const (
 	EventPlayerLogin         = "player_login"
 	EventPlayerLogout        = "player_logout"
 	EventLoginFailed         = "login_failed"
+	EventLoginBlocked        = "login_blocked"
 	EventSessionExpired      = "session_expired"
 	EventDeposit             = "deposit"
 	EventWithdrawal          = "withdrawal"
@@ -26,11 +30,16 @@ const (
 	EventGameSessionEnd      = "game_session_end"
 	EventGameCycleComplete   = "game_cycle_complete"
 	EventLargeWin            = "large_win"
+	EventJackpotWin          = "jackpot_win"
 	EventLargeWager          = "large_wager"
+	EventLargeTransaction    = "large_transaction"
 	EventBalanceAdjustment   = "balance_adjustment"
 	EventAccountStatusChange = "account_status_change"
+	EventConfigurationChange = "configuration_change"
 	EventSystemError         = "system_error"
 	EventRNGHealthCheck      = "rng_health_check"
+	EventRealityCheck        = "reality_check"
+	EventRealityCheckAck     = "reality_check_acknowledged"
 )
 
 // Service provides audit logging functionality
@@ -43,7 +52,23 @@ func New(db *sql.DB) *Service {
 	return &Service{db: db}
 }
 
-// LogEvent records a significant event
+// requestIDKey is the context key of the API request ID
+type requestIDKey struct{}
+
+// ContextWithRequestID returns a context carrying the ID of the API request
+// being served, so the events it causes can be correlated with the access log
+func ContextWithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestIDFromContext returns the API request ID carried by ctx, if any
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// LogEvent records a significant event, chaining it to the event logged
+// before it so later edits or deletions of the log can be detected
 func (s *Service) LogEvent(ctx context.Context, event *domain.AuditEvent) error {
 	if event.ID == "" {
 		event.ID = uuid.New().String()
@@ -51,16 +76,44 @@ func (s *Service) LogEvent(ctx context.Context, event *domain.AuditEvent) error
 	if event.Timestamp.IsZero() {
 		event.Timestamp = time.Now().UTC()
 	}
+	if event.RequestID == "" {
+		event.RequestID = RequestIDFromContext(ctx)
+	}
 
+	// Hash the timestamp at the precision it is stored with
+	event.Timestamp = event.Timestamp.UTC().Truncate(time.Microsecond)
 	dataJSON, _ := json.Marshal(event.Data)
 
-	_, err := s.db.ExecContext(ctx, `
-		INSERT INTO audit_events (id, type, severity, timestamp, player_id, session_id, description, data, ip_address, component)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Append one event at a time so each links to its predecessor
+	if _, err := tx.ExecContext(ctx, "LOCK TABLE audit_events IN SHARE ROW EXCLUSIVE MODE"); err != nil {
+		return err
+	}
+	var prevHash sql.NullString
+	err = tx.QueryRowContext(ctx, "SELECT hash FROM audit_events ORDER BY seq DESC LIMIT 1").Scan(&prevHash)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+	event.PrevHash = prevHash.String
+	event.Hash = hashEvent(event, dataJSON)
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO audit_events (id, type, severity, timestamp, player_id, session_id, description, data, ip_address, component, request_id, prev_hash, hash)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
 	`, event.ID, event.Type, event.Severity, event.Timestamp, event.PlayerID, event.SessionID,
-		event.Description, string(dataJSON), event.IPAddress, event.Component)
+		event.Description, string(dataJSON), event.IPAddress, event.Component,
+		sql.NullString{String: event.RequestID, Valid: event.RequestID != ""},
+		event.PrevHash, event.Hash)
+	if err != nil {
+		return err
+	}
 
-	return err
+	return tx.Commit()
 }
 
 // Log is a convenience method for logging events
@@ -119,84 +172,199 @@ func WithComponent(component string) EventOption {
 	}
 }
 
-// GetEvents retrieves audit events with optional filtering
+// GetEvents retrieves audit events with optional filtering, newest first
 func (s *Service) GetEvents(ctx context.Context, filter *EventFilter) ([]*domain.AuditEvent, error) {
-	query := `SELECT id, type, severity, timestamp, player_id, session_id, description, data, ip_address, component 
-			  FROM audit_events WHERE 1=1`
-	args := []interface{}{}
-	paramIdx := 1
-
-	if filter != nil {
-		if filter.PlayerID != "" {
-			query += fmt.Sprintf(" AND player_id = $%d", paramIdx)
-			args = append(args, filter.PlayerID)
-			paramIdx++
-		}
-		if filter.Type != "" {
-			query += fmt.Sprintf(" AND type = $%d", paramIdx)
-			args = append(args, filter.Type)
-			paramIdx++
-		}
-		if !filter.From.IsZero() {
-			query += fmt.Sprintf(" AND timestamp >= $%d", paramIdx)
-			args = append(args, filter.From)
-			paramIdx++
-		}
-		if !filter.To.IsZero() {
-			query += fmt.Sprintf(" AND timestamp <= $%d", paramIdx)
-			args = append(args, filter.To)
-			paramIdx++
+	page, err := s.QueryEvents(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	return page.Events, nil
+}
+
+// EventFilter defines criteria for filtering audit events. Zero values leave
+// a criterion unrestricted.
+type EventFilter struct {
+	PlayerID  string
+	SessionID string
+	Type      string
+	Severity  domain.EventSeverity
+	Component string
+	From      time.Time // At or after
+	To        time.Time // At or before
+
+	Limit  int    // Page size, default 100
+	Offset int    // Events to skip; ignored when Cursor is set
+	Cursor string // NextCursor of the previous page
+}
+
+// EventPage is one page of filtered audit events
+type EventPage struct {
+	Events     []*domain.AuditEvent `json:"events"`
+	Total      int                  `json:"total"` // Matching events across all pages
+	Limit      int                  `json:"limit"`
+	Offset     int                  `json:"offset"`
+	NextCursor string               `json:"next_cursor,omitempty"` // Empty on the last page
+}
+
+// ErrInvalidCursor is returned for a cursor not taken from a previous page
+var ErrInvalidCursor = errors.New("invalid audit event cursor")
+
+// QueryEvents retrieves a page of audit events matching the filter, newest
+// first, with the total number of matches. Pages can be walked by offset or,
+// stable against events logged meanwhile, by passing each page's NextCursor.
+// GLI-19 §2.8.8 - Significant events must be available for review
+func (s *Service) QueryEvents(ctx context.Context, filter *EventFilter) (*EventPage, error) {
+	if filter == nil {
+		filter = &EventFilter{}
+	}
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 100
+	}
+	offset := filter.Offset
+	if offset < 0 || filter.Cursor != "" {
+		offset = 0
+	}
+
+	q := filterQuery(filter)
+
+	var cursorTS time.Time
+	var cursorID string
+	if filter.Cursor != "" {
+		var err error
+		if cursorTS, cursorID, err = decodeCursor(filter.Cursor); err != nil {
+			return nil, err
 		}
 	}
 
-	query += " ORDER BY timestamp DESC"
+	page := &EventPage{Limit: limit, Offset: offset, Events: []*domain.AuditEvent{}}
+	err := s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM audit_events WHERE "+q.where, q.args...).Scan(&page.Total)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count audit events: %w", err)
+	}
 
-	if filter != nil && filter.Limit > 0 {
-		query += fmt.Sprintf(" LIMIT $%d", paramIdx)
-		args = append(args, filter.Limit)
-	} else {
-		query += " LIMIT 100"
+	// The cursor continues after the last event of the previous page
+	if cursorID != "" {
+		q.where += " AND (timestamp, id) < (" + q.arg(cursorTS) + ", " + q.arg(cursorID) + ")"
 	}
 
-	rows, err := s.db.QueryContext(ctx, query, args...)
+	query := "SELECT " + eventColumns + " FROM audit_events WHERE " + q.where +
+		" ORDER BY timestamp DESC, id DESC LIMIT " + q.arg(limit) + " OFFSET " + q.arg(offset)
+	rows, err := s.db.QueryContext(ctx, query, q.args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var events []*domain.AuditEvent
 	for rows.Next() {
-		var event domain.AuditEvent
-		var playerID, sessionID sql.NullString
-		var data string
-
-		err := rows.Scan(&event.ID, &event.Type, &event.Severity, &event.Timestamp,
-			&playerID, &sessionID, &event.Description, &data, &event.IPAddress, &event.Component)
+		event, err := scanEvent(rows)
 		if err != nil {
 			return nil, err
 		}
+		page.Events = append(page.Events, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
 
-		if playerID.Valid {
-			event.PlayerID = &playerID.String
-		}
-		if sessionID.Valid {
-			event.SessionID = &sessionID.String
-		}
-		if data != "" {
-			event.Data = json.RawMessage(data)
-		}
+	if n := len(page.Events); n == limit {
+		last := page.Events[n-1]
+		page.NextCursor = encodeCursor(last.Timestamp, last.ID)
+	}
+
+	return page, nil
+}
+
+// eventQuery accumulates the conditions and arguments of an audit query
+type eventQuery struct {
+	where string
+	args  []interface{}
+}
+
+// arg adds a query argument and returns its placeholder
+func (q *eventQuery) arg(v interface{}) string {
+	q.args = append(q.args, v)
+	return fmt.Sprintf("$%d", len(q.args))
+}
+
+// filterQuery returns the conditions selecting the events a filter matches,
+// leaving out its pagination
+func filterQuery(filter *EventFilter) *eventQuery {
+	q := &eventQuery{where: "1=1"}
+	if filter.PlayerID != "" {
+		q.where += " AND player_id = " + q.arg(filter.PlayerID)
+	}
+	if filter.SessionID != "" {
+		q.where += " AND session_id = " + q.arg(filter.SessionID)
+	}
+	if filter.Type != "" {
+		q.where += " AND type = " + q.arg(filter.Type)
+	}
+	if filter.Severity != "" {
+		q.where += " AND severity = " + q.arg(filter.Severity)
+	}
+	if filter.Component != "" {
+		q.where += " AND component = " + q.arg(filter.Component)
+	}
+	if !filter.From.IsZero() {
+		q.where += " AND timestamp >= " + q.arg(filter.From)
+	}
+	if !filter.To.IsZero() {
+		q.where += " AND timestamp <= " + q.arg(filter.To)
+	}
+	return q
+}
+
+// eventColumns are the audit_events columns read by scanEvent
+const eventColumns = `id, type, severity, timestamp, player_id, session_id, description, COALESCE(data::text, ''),
+	COALESCE(ip_address, ''), component, COALESCE(request_id, ''), COALESCE(prev_hash, ''), COALESCE(hash, '')`
+
+// scanEvent reads an audit event selected with eventColumns
+func scanEvent(rows *sql.Rows) (*domain.AuditEvent, error) {
+	var event domain.AuditEvent
+	var playerID, sessionID sql.NullString
+	var data string
 
-		events = append(events, &event)
+	err := rows.Scan(&event.ID, &event.Type, &event.Severity, &event.Timestamp,
+		&playerID, &sessionID, &event.Description, &data, &event.IPAddress, &event.Component,
+		&event.RequestID, &event.PrevHash, &event.Hash)
+	if err != nil {
+		return nil, err
 	}
 
-	return events, nil
+	if playerID.Valid {
+		event.PlayerID = &playerID.String
+	}
+	if sessionID.Valid {
+		event.SessionID = &sessionID.String
+	}
+	if data != "" {
+		event.Data = json.RawMessage(data)
+	}
+	return &event, nil
 }
 
-// EventFilter defines criteria for filtering audit events
-type EventFilter struct {
-	PlayerID string
-	Type     string
-	From     time.Time
-	To       time.Time
-	Limit    int
+// encodeCursor identifies an event's position in timestamp, ID order
+func encodeCursor(ts time.Time, id string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(ts.UTC().Format(time.RFC3339Nano) + "|" + id))
+}
+
+// decodeCursor reverses encodeCursor
+func decodeCursor(cursor string) (time.Time, string, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, "", ErrInvalidCursor
+	}
+	tsPart, id, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return time.Time{}, "", ErrInvalidCursor
+	}
+	ts, err := time.Parse(time.RFC3339Nano, tsPart)
+	if err != nil {
+		return time.Time{}, "", ErrInvalidCursor
+	}
+	if _, err := uuid.Parse(id); err != nil {
+		return time.Time{}, "", ErrInvalidCursor
+	}
+	return ts, id, nil
 }
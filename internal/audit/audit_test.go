@@ -0,0 +1,306 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alexbotov/rgs/internal/database/dbtest"
+	"github.com/alexbotov/rgs/internal/domain"
+	"github.com/google/uuid"
+)
+
+func TestMain(m *testing.M) {
+	dbtest.Main(m)
+}
+
+func setupTestAudit(t *testing.T) (*Service, func()) {
+	t.Helper()
+
+	db := dbtest.Open(t)
+
+	return New(db.DB), func() {}
+}
+
+func TestQueryEvents(t *testing.T) {
+	svc, cleanup := setupTestAudit(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	sessionID := uuid.New().String()
+
+	// Ten events a minute apart: every third is critical, the even ones
+	// belong to the session and come from the wallet
+	for i := 0; i < 10; i++ {
+		event := &domain.AuditEvent{
+			Type:        "test_event",
+			Severity:    domain.SeverityInfo,
+			Timestamp:   base.Add(time.Duration(i) * time.Minute),
+			Description: "test",
+			Component:   "game",
+		}
+		if i%3 == 0 {
+			event.Severity = domain.SeverityCritical
+		}
+		if i%2 == 0 {
+			event.SessionID = &sessionID
+			event.Component = "wallet"
+		}
+		if err := svc.LogEvent(ctx, event); err != nil {
+			t.Fatalf("LogEvent failed: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		filter EventFilter
+		total  int
+	}{
+		{"all", EventFilter{}, 10},
+		{"severity", EventFilter{Severity: domain.SeverityCritical}, 4},
+		{"session", EventFilter{SessionID: sessionID}, 5},
+		{"component", EventFilter{Component: "game"}, 5},
+		{"time window", EventFilter{From: base.Add(2 * time.Minute), To: base.Add(5 * time.Minute)}, 4},
+		{"severity in window", EventFilter{Severity: domain.SeverityCritical, From: base.Add(time.Minute), To: base.Add(6 * time.Minute)}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := svc.QueryEvents(ctx, &tt.filter)
+			if err != nil {
+				t.Fatalf("QueryEvents failed: %v", err)
+			}
+			if page.Total != tt.total || len(page.Events) != tt.total {
+				t.Errorf("Expected %d events, got %d of total %d", tt.total, len(page.Events), page.Total)
+			}
+			for _, e := range page.Events {
+				if tt.filter.Severity != "" && e.Severity != tt.filter.Severity {
+					t.Errorf("Event %s has severity %s", e.ID, e.Severity)
+				}
+				if !tt.filter.From.IsZero() && (e.Timestamp.Before(tt.filter.From) || e.Timestamp.After(tt.filter.To)) {
+					t.Errorf("Event %s at %v is outside the window", e.ID, e.Timestamp)
+				}
+			}
+		})
+	}
+
+	t.Run("offset pages", func(t *testing.T) {
+		page, err := svc.QueryEvents(ctx, &EventFilter{Limit: 4, Offset: 8})
+		if err != nil {
+			t.Fatalf("QueryEvents failed: %v", err)
+		}
+		if page.Total != 10 || len(page.Events) != 2 {
+			t.Fatalf("Expected last 2 of 10 events, got %d of %d", len(page.Events), page.Total)
+		}
+		if !page.Events[1].Timestamp.Equal(base) {
+			t.Errorf("Expected oldest event last, got %v", page.Events[1].Timestamp)
+		}
+		if page.NextCursor != "" {
+			t.Error("Expected no cursor on the last page")
+		}
+	})
+
+	t.Run("cursor pages", func(t *testing.T) {
+		var seen []time.Time
+		filter := &EventFilter{Limit: 3}
+		for pages := 0; ; pages++ {
+			if pages > 4 {
+				t.Fatal("Cursor did not reach the last page")
+			}
+			page, err := svc.QueryEvents(ctx, filter)
+			if err != nil {
+				t.Fatalf("QueryEvents failed: %v", err)
+			}
+			if page.Total != 10 {
+				t.Errorf("Expected total 10 on every page, got %d", page.Total)
+			}
+			for _, e := range page.Events {
+				seen = append(seen, e.Timestamp)
+			}
+			if page.NextCursor == "" {
+				break
+			}
+			filter.Cursor = page.NextCursor
+		}
+
+		// Every event exactly once, newest first
+		if len(seen) != 10 {
+			t.Fatalf("Expected 10 events across pages, got %d", len(seen))
+		}
+		for i := 1; i < len(seen); i++ {
+			if !seen[i].Before(seen[i-1]) {
+				t.Errorf("Event %d at %v is not older than %v", i, seen[i], seen[i-1])
+			}
+		}
+	})
+
+	t.Run("invalid cursor", func(t *testing.T) {
+		_, err := svc.QueryEvents(ctx, &EventFilter{Cursor: "not-a-cursor"})
+		if !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("Expected ErrInvalidCursor, got %v", err)
+		}
+	})
+
+	// GetEvents keeps returning just the events
+	events, err := svc.GetEvents(ctx, &EventFilter{Type: "test_event", Limit: 5})
+	if err != nil {
+		t.Fatalf("GetEvents failed: %v", err)
+	}
+	if len(events) != 5 {
+		t.Errorf("Expected 5 events, got %d", len(events))
+	}
+}
+
+func TestVerifyChain(t *testing.T) {
+	svc, cleanup := setupTestAudit(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	playerID := uuid.New().String()
+	for i := 0; i < 5; i++ {
+		err := svc.Log(ctx, EventDeposit, domain.SeverityInfo, "Deposit",
+			map[string]interface{}{"amount": float64(i) + 0.5, "currency": "USD"},
+			WithPlayer(playerID), WithIP("127.0.0.1"))
+		if err != nil {
+			t.Fatalf("Log failed: %v", err)
+		}
+	}
+
+	events, err := svc.GetEvents(ctx, &EventFilter{PlayerID: playerID})
+	if err != nil {
+		t.Fatalf("GetEvents failed: %v", err)
+	}
+	if len(events) != 5 {
+		t.Fatalf("Expected 5 events, got %d", len(events))
+	}
+	// Newest first: each event links to the one after it in the list
+	for i := 0; i < len(events)-1; i++ {
+		if events[i].PrevHash != events[i+1].Hash {
+			t.Errorf("Event %d is not linked to its predecessor", i)
+		}
+	}
+	if events[4].PrevHash != "" {
+		t.Error("Expected the first event to link to nothing")
+	}
+
+	t.Run("intact", func(t *testing.T) {
+		result, err := svc.VerifyChain(ctx, time.Time{}, time.Time{})
+		if err != nil {
+			t.Fatalf("VerifyChain failed: %v", err)
+		}
+		if !result.Intact || result.Checked != 5 {
+			t.Errorf("Expected intact chain of 5, got %+v", result)
+		}
+
+		// A window anchored on the event before it
+		result, err = svc.VerifyChain(ctx, events[2].Timestamp, events[1].Timestamp)
+		if err != nil {
+			t.Fatalf("VerifyChain failed: %v", err)
+		}
+		if !result.Intact || result.Checked != 2 {
+			t.Errorf("Expected intact window of 2, got %+v", result)
+		}
+	})
+
+	t.Run("mutated row", func(t *testing.T) {
+		_, err := svc.db.ExecContext(ctx, `UPDATE audit_events SET description = 'Withdrawal' WHERE id = $1`, events[2].ID)
+		if err != nil {
+			t.Fatalf("Failed to mutate event: %v", err)
+		}
+		result, err := svc.VerifyChain(ctx, time.Time{}, time.Time{})
+		if err != nil {
+			t.Fatalf("VerifyChain failed: %v", err)
+		}
+		if result.Intact || result.BrokenEventID != events[2].ID {
+			t.Errorf("Expected break at %s, got %+v", events[2].ID, result)
+		}
+	})
+
+	t.Run("deleted row", func(t *testing.T) {
+		_, err := svc.db.ExecContext(ctx, `DELETE FROM audit_events WHERE id = $1 OR id = $2`, events[2].ID, events[3].ID)
+		if err != nil {
+			t.Fatalf("Failed to delete events: %v", err)
+		}
+		result, err := svc.VerifyChain(ctx, time.Time{}, time.Time{})
+		if err != nil {
+			t.Fatalf("VerifyChain failed: %v", err)
+		}
+		if result.Intact || result.BrokenEventID != events[1].ID {
+			t.Errorf("Expected break at %s, got %+v", events[1].ID, result)
+		}
+	})
+}
+
+func TestExport(t *testing.T) {
+	svc, cleanup := setupTestAudit(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	playerID := uuid.New().String()
+	for i := 0; i < 4; i++ {
+		err := svc.Log(ctx, EventDeposit, domain.SeverityInfo, "Deposit, via card",
+			map[string]interface{}{"amount": 10}, WithPlayer(playerID))
+		if err != nil {
+			t.Fatalf("Log failed: %v", err)
+		}
+	}
+	if err := svc.Log(ctx, EventSystemError, domain.SeverityCritical, "Unrelated", nil); err != nil {
+		t.Fatalf("Log failed: %v", err)
+	}
+	filter := &EventFilter{PlayerID: playerID}
+
+	t.Run("csv", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := svc.Export(ctx, filter, &buf, ExportCSV); err != nil {
+			t.Fatalf("Export failed: %v", err)
+		}
+		records, err := csv.NewReader(&buf).ReadAll()
+		if err != nil {
+			t.Fatalf("Export is not valid CSV: %v", err)
+		}
+		if len(records) != 5 {
+			t.Fatalf("Expected header and 4 rows, got %d records", len(records))
+		}
+		if got := strings.Join(records[0], ","); got != strings.Join(exportColumns, ",") {
+			t.Errorf("Unexpected header %q", got)
+		}
+		for _, record := range records[1:] {
+			if record[5] != playerID || record[9] != "Deposit, via card" {
+				t.Errorf("Unexpected row %v", record)
+			}
+			if _, err := time.Parse(time.RFC3339Nano, record[1]); err != nil {
+				t.Errorf("Timestamp %q is not ISO-8601: %v", record[1], err)
+			}
+		}
+	})
+
+	t.Run("json lines", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := svc.Export(ctx, filter, &buf, ExportJSONLines); err != nil {
+			t.Fatalf("Export failed: %v", err)
+		}
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		if len(lines) != 4 {
+			t.Fatalf("Expected 4 lines, got %d", len(lines))
+		}
+		var event domain.AuditEvent
+		if err := json.Unmarshal([]byte(lines[0]), &event); err != nil {
+			t.Fatalf("Line is not a JSON event: %v", err)
+		}
+		if event.Type != EventDeposit {
+			t.Errorf("Expected deposit event, got %s", event.Type)
+		}
+	})
+
+	t.Run("unsupported format", func(t *testing.T) {
+		err := svc.Export(ctx, filter, &bytes.Buffer{}, "xml")
+		if !errors.Is(err, ErrUnsupportedExportFormat) {
+			t.Errorf("Expected ErrUnsupportedExportFormat, got %v", err)
+		}
+	})
+}
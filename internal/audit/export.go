@@ -0,0 +1,102 @@
+// Package audit - Exporting significant events for regulator reporting
+package audit
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// ErrUnsupportedExportFormat is returned for an export format other than
+// ExportCSV or ExportJSONLines
+var ErrUnsupportedExportFormat = errors.New("unsupported audit export format")
+
+// ExportFormat is the file format of an audit event export
+type ExportFormat string
+
+const (
+	ExportCSV       ExportFormat = "csv"
+	ExportJSONLines ExportFormat = "jsonl" // One JSON event per line
+)
+
+// exportColumns is the CSV header; the order is fixed for regulators' tooling
+var exportColumns = []string{
+	"id", "timestamp", "type", "severity", "component", "player_id", "session_id",
+	"ip_address", "request_id", "description", "data", "prev_hash", "hash",
+}
+
+// Export writes every event matching the filter to w, oldest first, streaming
+// rows from the database as they are read. The filter's pagination fields
+// are ignored. Timestamps are ISO-8601 in UTC.
+// GLI-19 §2.8.8 - Significant events must be available to the regulator
+func (s *Service) Export(ctx context.Context, filter *EventFilter, w io.Writer, format ExportFormat) error {
+	if format != ExportCSV && format != ExportJSONLines {
+		return fmt.Errorf("%w: %q", ErrUnsupportedExportFormat, format)
+	}
+	if filter == nil {
+		filter = &EventFilter{}
+	}
+
+	q := filterQuery(filter)
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT "+eventColumns+" FROM audit_events WHERE "+q.where+" ORDER BY timestamp, seq", q.args...)
+	if err != nil {
+		return fmt.Errorf("failed to query audit events: %w", err)
+	}
+	defer rows.Close()
+
+	var csvWriter *csv.Writer
+	var jsonEncoder *json.Encoder
+	if format == ExportCSV {
+		csvWriter = csv.NewWriter(w)
+		if err := csvWriter.Write(exportColumns); err != nil {
+			return err
+		}
+	} else {
+		jsonEncoder = json.NewEncoder(w)
+	}
+
+	for rows.Next() {
+		event, err := scanEvent(rows)
+		if err != nil {
+			return err
+		}
+		event.Timestamp = event.Timestamp.UTC()
+
+		if jsonEncoder != nil {
+			if err := jsonEncoder.Encode(event); err != nil {
+				return err
+			}
+			continue
+		}
+
+		var playerID, sessionID string
+		if event.PlayerID != nil {
+			playerID = *event.PlayerID
+		}
+		if event.SessionID != nil {
+			sessionID = *event.SessionID
+		}
+		err = csvWriter.Write([]string{
+			event.ID, event.Timestamp.Format(time.RFC3339Nano), event.Type, string(event.Severity),
+			event.Component, playerID, sessionID, event.IPAddress, event.RequestID,
+			event.Description, string(event.Data), event.PrevHash, event.Hash,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if csvWriter != nil {
+		csvWriter.Flush()
+		return csvWriter.Error()
+	}
+	return nil
+}
@@ -4,14 +4,19 @@ package auth
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/alexbotov/rgs/internal/audit"
 	"github.com/alexbotov/rgs/internal/config"
 	"github.com/alexbotov/rgs/internal/domain"
+	"github.com/alexbotov/rgs/internal/limits"
 	"github.com/alexbotov/rgs/pkg/pateplay"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
@@ -19,12 +24,17 @@ import (
 )
 
 var (
-	ErrInvalidCredentials = errors.New("invalid credentials")
-	ErrAccountLocked      = errors.New("account temporarily locked")
-	ErrAccountNotActive   = errors.New("account is not active")
-	ErrSessionExpired     = errors.New("session expired")
-	ErrSessionNotFound    = errors.New("session not found")
-	ErrUserExists         = errors.New("username or email already exists")
+	ErrInvalidCredentials  = errors.New("invalid credentials")
+	ErrAccountLocked       = errors.New("account temporarily locked")
+	ErrAccountNotActive    = errors.New("account is not active")
+	ErrSessionExpired      = errors.New("session expired")
+	ErrSessionNotFound     = errors.New("session not found")
+	ErrUserExists          = errors.New("username or email already exists")
+	ErrSessionLimit        = errors.New("session duration limit reached")
+	ErrInvalidRegistration = errors.New("invalid registration")
+	ErrCountryNotAllowed   = errors.New("country not allowed")
+	ErrCurrencyNotAllowed  = errors.New("currency not allowed")
+	ErrAuthUnavailable     = errors.New("authentication service unavailable")
 )
 
 // Service provides authentication functionality
@@ -33,6 +43,7 @@ type Service struct {
 	config   *config.AuthConfig
 	audit    *audit.Service
 	pateplay *pateplay.Client
+	limits   *limits.Service
 }
 
 // New creates a new auth service
@@ -45,6 +56,12 @@ func New(db *sql.DB, cfg *config.AuthConfig, auditSvc *audit.Service, pateplayCl
 	}
 }
 
+// SetLimits enables enforcement of player session duration limits when
+// validating tokens
+func (s *Service) SetLimits(limitsSvc *limits.Service) {
+	s.limits = limitsSvc
+}
+
 // RegisterRequest contains registration data
 type RegisterRequest struct {
 	Username string `json:"username"`
@@ -57,13 +74,13 @@ type RegisterRequest struct {
 func (s *Service) Register(ctx context.Context, req *RegisterRequest, ip string) (*domain.Player, error) {
 	// Validate input
 	if req.Username == "" || req.Email == "" || req.Password == "" {
-		return nil, errors.New("username, email, and password are required")
+		return nil, fmt.Errorf("%w: username, email, and password are required", ErrInvalidRegistration)
 	}
 	if !req.AcceptTC {
-		return nil, errors.New("terms and conditions must be accepted")
+		return nil, fmt.Errorf("%w: terms and conditions must be accepted", ErrInvalidRegistration)
 	}
 	if len(req.Password) < 8 {
-		return nil, errors.New("password must be at least 8 characters")
+		return nil, fmt.Errorf("%w: password must be at least 8 characters", ErrInvalidRegistration)
 	}
 
 	// Check if user exists
@@ -91,6 +108,7 @@ func (s *Service) Register(ctx context.Context, req *RegisterRequest, ip string)
 		Email:            req.Email,
 		PasswordHash:     string(hash),
 		Status:           domain.PlayerStatusActive,
+		Role:             domain.PlayerRolePlayer,
 		RegistrationDate: now,
 		TCAcceptedAt:     now,
 		CreatedAt:        now,
@@ -140,6 +158,15 @@ type LoginResponse struct {
 
 // Login authenticates a player (GLI-19 §2.5.3)
 func (s *Service) Login(ctx context.Context, req *LoginRequest, ip, userAgent string) (*LoginResponse, error) {
+	// Check for lockout before contacting Pateplay (GLI-19 §2.5.3.d)
+	if s.isLockedOut(ctx, ip) {
+		s.audit.Log(ctx, audit.EventLoginFailed, domain.SeverityWarning,
+			"Login rejected: too many failed attempts",
+			map[string]string{"reason": "locked_out"},
+			audit.WithIP(ip))
+		return nil, ErrAccountLocked
+	}
+
 	authResult, err := s.pateplay.Authenticate(ctx, req.AuthToken, pateplay.DeviceTypeDesktop)
 	if err != nil {
 		// authResult may be nil on error, so we can't access its fields
@@ -147,34 +174,49 @@ func (s *Service) Login(ctx context.Context, req *LoginRequest, ip, userAgent st
 			fmt.Sprintf("Pateplay authentication failed: %v", err),
 			map[string]string{"error": err.Error()},
 			audit.WithIP(ip))
+
+		// Only a token Pateplay rejected counts toward the lockout; an
+		// outage must not lock out every player who tries to log in
+		if !authRejected(err) {
+			return nil, fmt.Errorf("%w: %v", ErrAuthUnavailable, err)
+		}
+		s.recordFailedLogin(ctx, tokenFingerprint(req.AuthToken), ip)
 		return nil, ErrInvalidCredentials
 	}
 
+	// Only players of permitted jurisdictions and currencies may play
+	if err := s.verifyAuthResult(authResult); err != nil {
+		s.audit.Log(ctx, audit.EventLoginBlocked, domain.SeverityWarning,
+			fmt.Sprintf("Login blocked: %v", err),
+			map[string]string{
+				"reason":   err.Error(),
+				"country":  authResult.Country,
+				"currency": authResult.Currency,
+			},
+			audit.WithPlayer(authResult.PlayerID), audit.WithIP(ip))
+		return nil, err
+	}
+
 	// Get player
 	var player domain.Player
 	err = s.db.QueryRowContext(ctx, `
-		SELECT id, username, email, password_hash, status, registration_date, last_login_at, tc_accepted_at, created_at, updated_at
+		SELECT id, username, email, password_hash, status, role, registration_date, last_login_at, tc_accepted_at, created_at, updated_at
 		FROM players WHERE id = $1
 	`, authResult.PlayerID).Scan(
 		&player.ID, &player.Username, &player.Email, &player.PasswordHash,
-		&player.Status, &player.RegistrationDate, &player.LastLoginAt,
+		&player.Status, &player.Role, &player.RegistrationDate, &player.LastLoginAt,
 		&player.TCAcceptedAt, &player.CreatedAt, &player.UpdatedAt)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			s.Register(ctx, &RegisterRequest{
-				Username: authResult.PlayerName,
-				Email:    authResult.PlayerName,
-				Password: authResult.PlayerName,
-				AcceptTC: true,
-			}, ip)
-		} else {
+		if !errors.Is(err, sql.ErrNoRows) {
 			return nil, fmt.Errorf("database error: %w", err)
 		}
-	}
 
-	// Check for lockout (GLI-19 §2.5.3.d)
-	if s.isLockedOut(ctx, player.ID, player.LastLoginAt) {
-		return nil, ErrAccountLocked
+		// First login of a Pateplay player: create the local account
+		provisioned, err := s.provisionPlayerFromPateplay(ctx, authResult, ip)
+		if err != nil {
+			return nil, err
+		}
+		player = *provisioned
 	}
 
 	// Check account status
@@ -182,6 +224,11 @@ func (s *Service) Login(ctx context.Context, req *LoginRequest, ip, userAgent st
 		return nil, ErrAccountNotActive
 	}
 
+	// Pateplay is the wallet of record (GLI-19 §2.5.7)
+	if err := s.syncPateplayBalance(ctx, player.ID, authResult); err != nil {
+		return nil, err
+	}
+
 	// Create session
 	session, token, err := s.createSession(ctx, &player, ip, userAgent)
 	if err != nil {
@@ -195,7 +242,7 @@ func (s *Service) Login(ctx context.Context, req *LoginRequest, ip, userAgent st
 	player.LastLoginAt = &now
 
 	// Clear failed login attempts
-	s.db.ExecContext(ctx, "DELETE FROM failed_logins WHERE player_id = $1", player.ID)
+	s.db.ExecContext(ctx, "DELETE FROM failed_logins WHERE ip_address = $1", ip)
 
 	// Audit log
 	s.audit.Log(ctx, audit.EventPlayerLogin, domain.SeverityInfo,
@@ -210,6 +257,204 @@ func (s *Service) Login(ctx context.Context, req *LoginRequest, ip, userAgent st
 	}, nil
 }
 
+// verifyAuthResult checks that a player authenticated by Pateplay is in a
+// country and plays in a currency the operator permits
+func (s *Service) verifyAuthResult(authResult *pateplay.AuthenticateResult) error {
+	if !allowed(s.config.AllowedCountries, authResult.Country) {
+		return fmt.Errorf("%w: %q", ErrCountryNotAllowed, authResult.Country)
+	}
+	if !allowed(s.config.AllowedCurrencies, authResult.Currency) {
+		return fmt.Errorf("%w: %q", ErrCurrencyNotAllowed, authResult.Currency)
+	}
+	return nil
+}
+
+// allowed reports whether value is in the allow-list, ignoring case. An
+// empty allow-list allows any value.
+func allowed(list []string, value string) bool {
+	if len(list) == 0 {
+		return true
+	}
+	for _, item := range list {
+		if strings.EqualFold(item, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// provisionPlayerFromPateplay creates the local account for a player
+// authenticated by Pateplay for the first time. The account keeps the Pateplay
+// player ID, gets a synthetic email and an unusable password (Pateplay players
+// only log in by token), and a balance seeded from the reported balance.
+// GLI-19 §2.5.2 - Player registration
+func (s *Service) provisionPlayerFromPateplay(ctx context.Context, authResult *pateplay.AuthenticateResult, ip string) (*domain.Player, error) {
+	balance, err := domain.ParseMoney(authResult.Balance, authResult.Currency)
+	if err != nil {
+		balance = domain.Money{Currency: authResult.Currency}
+	}
+	if balance.Currency == "" {
+		balance.Currency = "USD"
+	}
+
+	// A random password nobody knows can never be used to log in
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("failed to generate password: %w", err)
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(hex.EncodeToString(secret)), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	username := authResult.PlayerName
+	if username == "" {
+		username = "pateplay-" + authResult.PlayerID
+	}
+
+	now := time.Now().UTC()
+	player := &domain.Player{
+		ID:               authResult.PlayerID,
+		Username:         username,
+		Email:            authResult.PlayerID + "@pateplay.invalid",
+		PasswordHash:     string(hash),
+		Status:           domain.PlayerStatusActive,
+		Role:             domain.PlayerRolePlayer,
+		RegistrationDate: now,
+		TCAcceptedAt:     now,
+		CreatedAt:        now,
+		UpdatedAt:        now,
+	}
+
+	dbTx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer dbTx.Rollback()
+
+	_, err = dbTx.ExecContext(ctx, `
+		INSERT INTO players (id, username, email, password_hash, status, registration_date, tc_accepted_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`, player.ID, player.Username, player.Email, player.PasswordHash, player.Status,
+		player.RegistrationDate, player.TCAcceptedAt, player.CreatedAt, player.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create player: %w", err)
+	}
+
+	// Create initial balance (GLI-19 §2.5.7)
+	_, err = dbTx.ExecContext(ctx, `
+		INSERT INTO balances (player_id, real_money_amount, real_money_currency, bonus_amount, bonus_currency, updated_at)
+		VALUES ($1, $2, $3, 0, $3, $4)
+	`, player.ID, balance.Amount, balance.Currency, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create balance: %w", err)
+	}
+
+	if err := dbTx.Commit(); err != nil {
+		return nil, err
+	}
+
+	s.audit.Log(ctx, audit.EventPlayerRegistered, domain.SeverityInfo,
+		fmt.Sprintf("Player provisioned from Pateplay: %s", player.Username),
+		map[string]string{"player_id": player.ID, "country": authResult.Country},
+		audit.WithPlayer(player.ID), audit.WithIP(ip))
+
+	return player, nil
+}
+
+// syncPateplayBalance reconciles the player's internal balance with the
+// balance Pateplay reported at authentication. A missing balance is seeded and
+// a small difference is corrected with an adjustment transaction; a difference
+// beyond the configured threshold is left in place and flagged for review.
+// GLI-19 §2.5.7 - Pateplay is the wallet of record for operator play
+func (s *Service) syncPateplayBalance(ctx context.Context, playerID string, authResult *pateplay.AuthenticateResult) error {
+	reported, err := domain.ParseMoney(authResult.Balance, authResult.Currency)
+	if err != nil {
+		s.audit.Log(ctx, "balance_discrepancy", domain.SeverityWarning,
+			fmt.Sprintf("Unparseable Pateplay balance %q", authResult.Balance),
+			map[string]string{"balance": authResult.Balance, "error": err.Error()},
+			audit.WithPlayer(playerID))
+		return nil
+	}
+
+	now := time.Now().UTC()
+
+	dbTx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer dbTx.Rollback()
+
+	var stored int64
+	var currency string
+	err = dbTx.QueryRowContext(ctx, `
+		SELECT real_money_amount, real_money_currency FROM balances WHERE player_id = $1 FOR UPDATE
+	`, playerID).Scan(&stored, &currency)
+	if errors.Is(err, sql.ErrNoRows) {
+		_, err = dbTx.ExecContext(ctx, `
+			INSERT INTO balances (player_id, real_money_amount, real_money_currency, bonus_amount, bonus_currency, updated_at)
+			VALUES ($1, $2, $3, 0, $3, $4)
+		`, playerID, reported.Amount, reported.Currency, now)
+		if err != nil {
+			return fmt.Errorf("failed to seed balance: %w", err)
+		}
+		return dbTx.Commit()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get balance: %w", err)
+	}
+
+	internal := domain.Money{Amount: stored, Currency: currency}
+	if internal == reported {
+		return nil
+	}
+
+	diff, err := reported.SubChecked(internal)
+	if err != nil || diff.Amount > s.config.BalanceDiscrepancyThreshold || -diff.Amount > s.config.BalanceDiscrepancyThreshold {
+		s.audit.Log(ctx, "balance_discrepancy", domain.SeverityCritical,
+			fmt.Sprintf("Internal balance %s differs from Pateplay balance %s", internal, reported),
+			map[string]string{
+				"internal": internal.String(),
+				"pateplay": reported.String(),
+			},
+			audit.WithPlayer(playerID))
+		return nil
+	}
+
+	_, err = dbTx.ExecContext(ctx, `
+		UPDATE balances SET real_money_amount = $1, updated_at = $2 WHERE player_id = $3
+	`, reported.Amount, now, playerID)
+	if err != nil {
+		return fmt.Errorf("failed to reconcile balance: %w", err)
+	}
+
+	txID := uuid.New().String()
+	_, err = dbTx.ExecContext(ctx, `
+		INSERT INTO transactions (id, player_id, type, amount, currency, balance_before, balance_after, status, reference, description, created_at, completed_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $11)
+	`, txID, playerID, domain.TxTypeAdjustment, diff.Amount, reported.Currency, internal.Amount, reported.Amount,
+		domain.TxStatusCompleted, "pateplay", "Reconciled with Pateplay balance", now)
+	if err != nil {
+		return fmt.Errorf("failed to record balance adjustment: %w", err)
+	}
+
+	if err := dbTx.Commit(); err != nil {
+		return err
+	}
+
+	s.audit.Log(ctx, audit.EventBalanceAdjustment, domain.SeverityWarning,
+		fmt.Sprintf("Balance reconciled with Pateplay: %s -> %s", internal, reported),
+		map[string]interface{}{
+			"transaction_id": txID,
+			"internal":       internal.String(),
+			"pateplay":       reported.String(),
+			"adjustment":     diff.Amount,
+		},
+		audit.WithPlayer(playerID))
+
+	return nil
+}
+
 // createSession creates a new session with JWT token
 func (s *Service) createSession(ctx context.Context, player *domain.Player, ip, userAgent string) (*domain.Session, string, error) {
 	now := time.Now().UTC()
@@ -224,18 +469,9 @@ func (s *Service) createSession(ctx context.Context, player *domain.Player, ip,
 		Status:         domain.SessionStatusActive,
 	}
 
-	// Generate JWT token
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"session_id": session.ID,
-		"player_id":  player.ID,
-		"username":   player.Username,
-		"exp":        session.ExpiresAt.Unix(),
-		"iat":        now.Unix(),
-	})
-
-	tokenString, err := token.SignedString([]byte(s.config.JWTSecret))
+	tokenString, err := s.signToken(session, player, now)
 	if err != nil {
-		return nil, "", fmt.Errorf("failed to sign token: %w", err)
+		return nil, "", err
 	}
 
 	session.Token = tokenString
@@ -253,6 +489,64 @@ func (s *Service) createSession(ctx context.Context, player *domain.Player, ip,
 	return session, tokenString, nil
 }
 
+// signToken generates the JWT for a session
+func (s *Service) signToken(session *domain.Session, player *domain.Player, issuedAt time.Time) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"session_id": session.ID,
+		"player_id":  player.ID,
+		"username":   player.Username,
+		"exp":        session.ExpiresAt.Unix(),
+		"iat":        issuedAt.Unix(),
+		"jti":        uuid.New().String(), // Distinguishes tokens issued in the same second
+	})
+
+	tokenString, err := token.SignedString([]byte(s.config.JWTSecret))
+	if err != nil {
+		return "", fmt.Errorf("failed to sign token: %w", err)
+	}
+	return tokenString, nil
+}
+
+// RefreshSession renews a still-valid session without a new Pateplay login.
+// The session gets a new token with the expiry extended by the token lifetime;
+// the old token stops working. Logged-out, expired and inactive sessions are
+// refused (GLI-19 §2.5.4).
+func (s *Service) RefreshSession(ctx context.Context, tokenString string) (*domain.Session, string, error) {
+	session, player, err := s.ValidateToken(ctx, tokenString)
+	if err != nil {
+		return nil, "", err
+	}
+
+	now := time.Now().UTC()
+	session.ExpiresAt = now.Add(s.config.TokenExpiry)
+	session.LastActivityAt = now
+
+	newToken, err := s.signToken(session, player, now)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Only the presented token can be exchanged, so a replayed token fails
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE sessions SET token = $1, expires_at = $2, last_activity_at = $3
+		WHERE id = $4 AND token = $5 AND status = $6
+	`, newToken, session.ExpiresAt, now, session.ID, tokenString, domain.SessionStatusActive)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to refresh session: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return nil, "", ErrSessionExpired
+	}
+	session.Token = newToken
+
+	s.audit.Log(ctx, "session_refreshed", domain.SeverityInfo,
+		"Session renewed",
+		map[string]interface{}{"session_id": session.ID, "expires_at": session.ExpiresAt},
+		audit.WithPlayer(session.PlayerID), audit.WithSession(session.ID))
+
+	return session, newToken, nil
+}
+
 // ValidateToken validates a JWT token and returns the session
 func (s *Service) ValidateToken(ctx context.Context, tokenString string) (*domain.Session, *domain.Player, error) {
 	// Parse and validate token
@@ -296,6 +590,11 @@ func (s *Service) ValidateToken(ctx context.Context, tokenString string) (*domai
 		return nil, nil, ErrSessionExpired
 	}
 
+	// A token replaced by RefreshSession is no longer valid
+	if session.Token != tokenString {
+		return nil, nil, ErrSessionExpired
+	}
+
 	// Check expiry
 	if time.Now().After(session.ExpiresAt) {
 		s.db.ExecContext(ctx, "UPDATE sessions SET status = $1 WHERE id = $2",
@@ -310,13 +609,30 @@ func (s *Service) ValidateToken(ctx context.Context, tokenString string) (*domai
 		return nil, nil, ErrSessionExpired
 	}
 
+	// Check the player's session duration limit (GLI-19 §2.5.5)
+	if s.limits != nil {
+		err := s.limits.CheckSessionDuration(ctx, session.PlayerID, session.CreatedAt)
+		if errors.Is(err, limits.ErrSessionDurationExceeded) {
+			s.db.ExecContext(ctx, "UPDATE sessions SET status = $1 WHERE id = $2",
+				domain.SessionStatusRequiresAuth, session.ID)
+			s.audit.Log(ctx, "session_limit_reached", domain.SeverityInfo,
+				"Session duration limit reached",
+				map[string]string{"session_id": session.ID},
+				audit.WithPlayer(session.PlayerID), audit.WithSession(session.ID))
+			return nil, nil, ErrSessionLimit
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
 	// Get player
 	var player domain.Player
 	err = s.db.QueryRowContext(ctx, `
-		SELECT id, username, email, status, registration_date, last_login_at, tc_accepted_at, created_at, updated_at
+		SELECT id, username, email, status, role, registration_date, last_login_at, tc_accepted_at, created_at, updated_at
 		FROM players WHERE id = $1
 	`, session.PlayerID).Scan(
-		&player.ID, &player.Username, &player.Email, &player.Status,
+		&player.ID, &player.Username, &player.Email, &player.Status, &player.Role,
 		&player.RegistrationDate, &player.LastLoginAt, &player.TCAcceptedAt,
 		&player.CreatedAt, &player.UpdatedAt)
 	if err != nil {
@@ -331,6 +647,51 @@ func (s *Service) ValidateToken(ctx context.Context, tokenString string) (*domai
 	return &session, &player, nil
 }
 
+// CheckSession reports whether a session is still usable without counting
+// as activity, so polling it does not keep an idle session alive. It follows
+// the session rather than a token and so keeps working after RefreshSession
+// replaces the token. Errors match ValidateToken.
+func (s *Service) CheckSession(ctx context.Context, sessionID string) error {
+	var session domain.Session
+	err := s.db.QueryRowContext(ctx, `
+		SELECT player_id, created_at, last_activity_at, expires_at, status
+		FROM sessions WHERE id = $1
+	`, sessionID).Scan(&session.PlayerID, &session.CreatedAt, &session.LastActivityAt,
+		&session.ExpiresAt, &session.Status)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrSessionNotFound
+		}
+		return err
+	}
+
+	if session.Status != domain.SessionStatusActive ||
+		time.Now().After(session.ExpiresAt) ||
+		time.Since(session.LastActivityAt) > s.config.SessionTimeout {
+		return ErrSessionExpired
+	}
+
+	if s.limits != nil {
+		err := s.limits.CheckSessionDuration(ctx, session.PlayerID, session.CreatedAt)
+		if errors.Is(err, limits.ErrSessionDurationExceeded) {
+			return ErrSessionLimit
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// TouchSession records player activity on an active session, for actions
+// that do not go through ValidateToken such as plays over a WebSocket
+func (s *Service) TouchSession(ctx context.Context, sessionID string) error {
+	_, err := s.db.ExecContext(ctx, "UPDATE sessions SET last_activity_at = $1 WHERE id = $2 AND status = $3",
+		time.Now().UTC(), sessionID, domain.SessionStatusActive)
+	return err
+}
+
 // Logout terminates a session
 func (s *Service) Logout(ctx context.Context, sessionID string) error {
 	_, err := s.db.ExecContext(ctx, "UPDATE sessions SET status = $1 WHERE id = $2",
@@ -347,14 +708,56 @@ func (s *Service) Logout(ctx context.Context, sessionID string) error {
 	return nil
 }
 
+// GetActiveSessions returns a player's active, unexpired sessions, newest first
+func (s *Service) GetActiveSessions(ctx context.Context, playerID string) ([]*domain.Session, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, player_id, token, ip_address, user_agent, created_at, last_activity_at, expires_at, status
+		FROM sessions WHERE player_id = $1 AND status = $2 AND expires_at > $3
+		ORDER BY created_at DESC
+	`, playerID, domain.SessionStatusActive, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sessions []*domain.Session
+	for rows.Next() {
+		var session domain.Session
+		if err := rows.Scan(&session.ID, &session.PlayerID, &session.Token, &session.IPAddress, &session.UserAgent,
+			&session.CreatedAt, &session.LastActivityAt, &session.ExpiresAt, &session.Status); err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, &session)
+	}
+	return sessions, rows.Err()
+}
+
+// TerminateAllSessions logs out every active session of a player, for example
+// when the account is disabled or compromised (GLI-19 §2.5.3)
+func (s *Service) TerminateAllSessions(ctx context.Context, playerID, reason string) error {
+	result, err := s.db.ExecContext(ctx, "UPDATE sessions SET status = $1 WHERE player_id = $2 AND status = $3",
+		domain.SessionStatusLoggedOut, playerID, domain.SessionStatusActive)
+	if err != nil {
+		return fmt.Errorf("failed to terminate sessions: %w", err)
+	}
+	terminated, _ := result.RowsAffected()
+
+	s.audit.Log(ctx, audit.EventPlayerLogout, domain.SeverityWarning,
+		fmt.Sprintf("All sessions terminated: %s", reason),
+		map[string]interface{}{"reason": reason, "sessions_terminated": terminated},
+		audit.WithPlayer(playerID))
+
+	return nil
+}
+
 // GetPlayer retrieves a player by ID
 func (s *Service) GetPlayer(ctx context.Context, playerID string) (*domain.Player, error) {
 	var player domain.Player
 	err := s.db.QueryRowContext(ctx, `
-		SELECT id, username, email, status, registration_date, last_login_at, tc_accepted_at, created_at, updated_at
+		SELECT id, username, email, status, role, registration_date, last_login_at, tc_accepted_at, created_at, updated_at
 		FROM players WHERE id = $1
 	`, playerID).Scan(
-		&player.ID, &player.Username, &player.Email, &player.Status,
+		&player.ID, &player.Username, &player.Email, &player.Status, &player.Role,
 		&player.RegistrationDate, &player.LastLoginAt, &player.TCAcceptedAt,
 		&player.CreatedAt, &player.UpdatedAt)
 	if err != nil {
@@ -366,23 +769,40 @@ func (s *Service) GetPlayer(ctx context.Context, playerID string) (*domain.Playe
 	return &player, nil
 }
 
-// isLockedOut checks if account is locked due to failed attempts (GLI-19 §2.5.3.d)
-func (s *Service) isLockedOut(ctx context.Context, playerID string, lastLoginAt *time.Time) bool {
-	if lastLoginAt == nil {
+// isLockedOut checks if logins from an address are locked due to failed
+// attempts within the lockout window (GLI-19 §2.5.3.d)
+func (s *Service) isLockedOut(ctx context.Context, ip string) bool {
+	if s.config.MaxFailedAttempts <= 0 {
 		return false
 	}
-	cutoff := lastLoginAt.Add(-s.config.LockoutDuration)
+	cutoff := time.Now().UTC().Add(-s.config.LockoutDuration)
 	var count int
 	s.db.QueryRowContext(ctx,
-		"SELECT COUNT(*) FROM failed_logins WHERE player_id = $1 AND attempted_at > $2 AND attempted_at < $3",
-		playerID, cutoff, time.Now()).Scan(&count)
+		"SELECT COUNT(*) FROM failed_logins WHERE ip_address = $1 AND attempted_at > $2",
+		ip, cutoff).Scan(&count)
 	return count >= s.config.MaxFailedAttempts
 }
 
-// recordFailedLogin records a failed login attempt
+// recordFailedLogin records a failed login attempt. Pateplay tokens do not
+// identify the player on failure, so the attempt is recorded against the token
+// fingerprint and the address it came from.
 func (s *Service) recordFailedLogin(ctx context.Context, username, ip string) {
 	s.db.ExecContext(ctx, `
 		INSERT INTO failed_logins (id, username, ip_address, attempted_at)
 		VALUES ($1, $2, $3, $4)
 	`, uuid.New().String(), username, ip, time.Now().UTC())
 }
+
+// authRejected reports whether a Pateplay authentication error is a definite
+// rejection of the token rather than a failure to check it
+func authRejected(err error) bool {
+	var apiErr *pateplay.APIError
+	return errors.As(err, &apiErr) && apiErr.Code == pateplay.ErrInvalidAuthToken
+}
+
+// tokenFingerprint identifies an auth token in the failed login log without
+// storing the token itself
+func tokenFingerprint(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return "token:" + hex.EncodeToString(sum[:8])
+}
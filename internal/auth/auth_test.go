@@ -3,15 +3,19 @@ package auth
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"net/mail"
 	"testing"
 	"time"
 
 	"github.com/alexbotov/rgs/internal/audit"
 	"github.com/alexbotov/rgs/internal/config"
-	"github.com/alexbotov/rgs/internal/database"
+	"github.com/alexbotov/rgs/internal/database/dbtest"
+	"github.com/alexbotov/rgs/internal/limits"
 	"github.com/alexbotov/rgs/pkg/pateplay"
+	"github.com/google/uuid"
 )
 
 const (
@@ -20,6 +24,10 @@ const (
 	testSiteCode  = "testsite"
 )
 
+func TestMain(m *testing.M) {
+	dbtest.Main(m)
+}
+
 // mockPateplayServer creates a test server that simulates Pateplay API responses
 func mockPateplayServer(t *testing.T, authToken string, result *pateplay.AuthenticateResult, apiErr *pateplay.APIError) *httptest.Server {
 	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -66,19 +74,7 @@ func setupTestAuthWithMock(t *testing.T, validAuthToken string, authResult *pate
 	// Create mock Pateplay server
 	mockServer := mockPateplayServer(t, validAuthToken, authResult, nil)
 
-	// Create PostgreSQL connection
-	db, err := database.New("postgres", "host=localhost dbname=rgs sslmode=disable")
-	if err != nil {
-		t.Fatalf("Failed to create database: %v", err)
-	}
-
-	if err := db.Migrate(); err != nil {
-		t.Logf("Migration note: %v", err)
-	}
-
-	if err := db.CleanData(); err != nil {
-		t.Fatalf("Failed to clean data: %v", err)
-	}
+	db := dbtest.Open(t)
 
 	auditSvc := audit.New(db.DB)
 	cfg := &config.AuthConfig{
@@ -101,8 +97,6 @@ func setupTestAuthWithMock(t *testing.T, validAuthToken string, authResult *pate
 
 	return svc, func() {
 		mockServer.Close()
-		db.CleanData()
-		db.Close()
 	}
 }
 
@@ -251,6 +245,19 @@ func TestLogin(t *testing.T) {
 		}
 	})
 
+	t.Run("SeedsBalanceFromPateplay", func(t *testing.T) {
+		var amount int64
+		var currency string
+		err := svc.db.QueryRowContext(ctx, "SELECT real_money_amount, real_money_currency FROM balances WHERE player_id = $1",
+			authResult.PlayerID).Scan(&amount, &currency)
+		if err != nil {
+			t.Fatalf("Expected balance to be seeded: %v", err)
+		}
+		if amount != 100000 || currency != "USD" {
+			t.Errorf("Expected 100000 USD, got %d %s", amount, currency)
+		}
+	})
+
 	t.Run("InvalidAuthToken", func(t *testing.T) {
 		_, err := svc.Login(ctx, &LoginRequest{
 			AuthToken:  "invalid-auth-token",
@@ -394,6 +401,191 @@ func TestLogout(t *testing.T) {
 	})
 }
 
+func TestRefreshSession(t *testing.T) {
+	authResult := &pateplay.AuthenticateResult{
+		SessionToken: "mock-session-token",
+		PlayerID:     "55555555-5555-5555-5555-555555555555",
+		PlayerName:   "RefreshUser",
+		Currency:     "USD",
+		Country:      "US",
+		Balance:      "1000.00",
+	}
+
+	svc, cleanup := setupTestAuthWithMock(t, "valid-auth-token", authResult)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	loginResult, err := svc.Login(ctx, &LoginRequest{
+		AuthToken:  "valid-auth-token",
+		DeviceType: "desktop",
+	}, "127.0.0.1", "TestAgent")
+	if err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+
+	var refreshedToken string
+
+	t.Run("RenewsActiveSession", func(t *testing.T) {
+		session, token, err := svc.RefreshSession(ctx, loginResult.Token)
+		if err != nil {
+			t.Fatalf("RefreshSession failed: %v", err)
+		}
+		if token == "" || token == loginResult.Token {
+			t.Error("Expected a new token")
+		}
+		if session.ID != loginResult.Session.ID {
+			t.Errorf("Expected same session %s, got %s", loginResult.Session.ID, session.ID)
+		}
+		if session.ExpiresAt.Before(loginResult.Session.ExpiresAt) {
+			t.Error("Expected expiry to be extended")
+		}
+
+		if _, _, err := svc.ValidateToken(ctx, token); err != nil {
+			t.Errorf("New token should be valid: %v", err)
+		}
+		if _, _, err := svc.ValidateToken(ctx, loginResult.Token); err == nil {
+			t.Error("Old token should be invalid after refresh")
+		}
+		refreshedToken = token
+	})
+
+	t.Run("RefusedAfterLogout", func(t *testing.T) {
+		if err := svc.Logout(ctx, loginResult.Session.ID); err != nil {
+			t.Fatalf("Logout failed: %v", err)
+		}
+
+		if _, _, err := svc.RefreshSession(ctx, refreshedToken); err != ErrSessionExpired {
+			t.Errorf("Expected ErrSessionExpired, got %v", err)
+		}
+	})
+}
+
+func TestCheckSession(t *testing.T) {
+	authResult := &pateplay.AuthenticateResult{
+		SessionToken: "mock-session-token",
+		PlayerID:     "56565656-5656-5656-5656-565656565656",
+		PlayerName:   "CheckSessionUser",
+		Currency:     "USD",
+		Country:      "US",
+		Balance:      "1000.00",
+	}
+
+	svc, cleanup := setupTestAuthWithMock(t, "valid-auth-token", authResult)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	loginResult, err := svc.Login(ctx, &LoginRequest{
+		AuthToken:  "valid-auth-token",
+		DeviceType: "desktop",
+	}, "127.0.0.1", "TestAgent")
+	if err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+	sessionID := loginResult.Session.ID
+
+	lastActivity := func() time.Time {
+		var at time.Time
+		if err := svc.db.QueryRowContext(ctx, "SELECT last_activity_at FROM sessions WHERE id = $1",
+			sessionID).Scan(&at); err != nil {
+			t.Fatalf("Failed to read session: %v", err)
+		}
+		return at
+	}
+
+	t.Run("DoesNotTouchActivity", func(t *testing.T) {
+		before := lastActivity()
+		if err := svc.CheckSession(ctx, sessionID); err != nil {
+			t.Fatalf("CheckSession failed: %v", err)
+		}
+		if after := lastActivity(); !after.Equal(before) {
+			t.Errorf("Expected last activity %v to be unchanged, got %v", before, after)
+		}
+	})
+
+	t.Run("FollowsRefresh", func(t *testing.T) {
+		if _, _, err := svc.RefreshSession(ctx, loginResult.Token); err != nil {
+			t.Fatalf("RefreshSession failed: %v", err)
+		}
+		if err := svc.CheckSession(ctx, sessionID); err != nil {
+			t.Errorf("Session should still be usable after refresh: %v", err)
+		}
+	})
+
+	t.Run("IdleSessionExpires", func(t *testing.T) {
+		if _, err := svc.db.ExecContext(ctx, "UPDATE sessions SET last_activity_at = $1 WHERE id = $2",
+			time.Now().UTC().Add(-time.Hour), sessionID); err != nil {
+			t.Fatalf("Failed to age session: %v", err)
+		}
+		if err := svc.CheckSession(ctx, sessionID); err != ErrSessionExpired {
+			t.Errorf("Expected ErrSessionExpired, got %v", err)
+		}
+	})
+
+	t.Run("RefusedAfterLogout", func(t *testing.T) {
+		if err := svc.TouchSession(ctx, sessionID); err != nil {
+			t.Fatalf("TouchSession failed: %v", err)
+		}
+		if err := svc.Logout(ctx, sessionID); err != nil {
+			t.Fatalf("Logout failed: %v", err)
+		}
+		if err := svc.CheckSession(ctx, sessionID); err != ErrSessionExpired {
+			t.Errorf("Expected ErrSessionExpired, got %v", err)
+		}
+	})
+}
+
+func TestTerminateAllSessions(t *testing.T) {
+	authResult := &pateplay.AuthenticateResult{
+		SessionToken: "mock-session-token",
+		PlayerID:     "66666666-6666-6666-6666-666666666666",
+		PlayerName:   "MultiSessionUser",
+		Currency:     "USD",
+		Country:      "US",
+		Balance:      "1000.00",
+	}
+
+	svc, cleanup := setupTestAuthWithMock(t, "valid-auth-token", authResult)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	var tokens []string
+	for i := 0; i < 3; i++ {
+		result, err := svc.Login(ctx, &LoginRequest{
+			AuthToken:  "valid-auth-token",
+			DeviceType: "desktop",
+		}, "127.0.0.1", "TestAgent")
+		if err != nil {
+			t.Fatalf("Login %d failed: %v", i+1, err)
+		}
+		tokens = append(tokens, result.Token)
+	}
+
+	sessions, err := svc.GetActiveSessions(ctx, authResult.PlayerID)
+	if err != nil {
+		t.Fatalf("GetActiveSessions failed: %v", err)
+	}
+	if len(sessions) != 3 {
+		t.Fatalf("Expected 3 active sessions, got %d", len(sessions))
+	}
+
+	if err := svc.TerminateAllSessions(ctx, authResult.PlayerID, "security review"); err != nil {
+		t.Fatalf("TerminateAllSessions failed: %v", err)
+	}
+
+	sessions, _ = svc.GetActiveSessions(ctx, authResult.PlayerID)
+	if len(sessions) != 0 {
+		t.Errorf("Expected no active sessions, got %d", len(sessions))
+	}
+	for i, token := range tokens {
+		if _, _, err := svc.ValidateToken(ctx, token); err == nil {
+			t.Errorf("Token %d should be invalid after termination", i+1)
+		}
+	}
+}
+
 func TestAccountLockout(t *testing.T) {
 	// Create mock with specific player ID (must be valid UUID)
 	authResult := &pateplay.AuthenticateResult{
@@ -449,4 +641,300 @@ func TestAccountLockout(t *testing.T) {
 			t.Error("Expected token")
 		}
 	})
+
+	t.Run("LockedAfterMaxFailedAttempts", func(t *testing.T) {
+		for i := 0; i < svc.config.MaxFailedAttempts; i++ {
+			_, err := svc.Login(ctx, &LoginRequest{
+				AuthToken:  "invalid-auth-token",
+				DeviceType: "desktop",
+			}, "10.0.0.1", "TestAgent")
+			if err != ErrInvalidCredentials {
+				t.Fatalf("Attempt %d: expected ErrInvalidCredentials, got %v", i+1, err)
+			}
+		}
+
+		// Even a valid token is refused while the lockout lasts
+		_, err := svc.Login(ctx, &LoginRequest{
+			AuthToken:  "valid-auth-token",
+			DeviceType: "desktop",
+		}, "10.0.0.1", "TestAgent")
+		if err != ErrAccountLocked {
+			t.Errorf("Expected ErrAccountLocked, got %v", err)
+		}
+
+		// Other addresses are unaffected
+		if _, err := svc.Login(ctx, &LoginRequest{
+			AuthToken:  "valid-auth-token",
+			DeviceType: "desktop",
+		}, "10.0.0.2", "TestAgent"); err != nil {
+			t.Errorf("Expected login from another address to succeed, got %v", err)
+		}
+	})
+}
+
+func TestLoginOutageNotCounted(t *testing.T) {
+	svc, cleanup := setupTestAuth(t)
+	ctx := context.Background()
+
+	// Pateplay is unreachable: no attempt says the token was wrong
+	cleanup()
+	for i := 0; i <= svc.config.MaxFailedAttempts; i++ {
+		_, err := svc.Login(ctx, &LoginRequest{
+			AuthToken:  "valid-auth-token",
+			DeviceType: "desktop",
+		}, "10.0.1.1", "TestAgent")
+		if !errors.Is(err, ErrAuthUnavailable) {
+			t.Fatalf("Attempt %d: expected ErrAuthUnavailable, got %v", i+1, err)
+		}
+	}
+
+	if svc.isLockedOut(ctx, "10.0.1.1") {
+		t.Error("Expected no lockout after Pateplay was unreachable")
+	}
+}
+
+func TestValidateTokenSessionLimit(t *testing.T) {
+	authResult := &pateplay.AuthenticateResult{
+		SessionToken: "mock-session-token",
+		PlayerID:     "33333333-3333-3333-3333-333333333333",
+		PlayerName:   "LimitUser",
+		Currency:     "USD",
+		Country:      "US",
+		Balance:      "1000.00",
+	}
+
+	svc, cleanup := setupTestAuthWithMock(t, "valid-auth-token", authResult)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	_, err := svc.db.ExecContext(ctx, `
+		INSERT INTO players (id, username, email, password_hash, status, registration_date, tc_accepted_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`, authResult.PlayerID, authResult.PlayerName, "limit@example.com", "", "active",
+		time.Now().UTC(), time.Now().UTC(), time.Now().UTC(), time.Now().UTC())
+	if err != nil {
+		t.Fatalf("Failed to insert test player: %v", err)
+	}
+
+	limitsSvc := limits.New(svc.db, svc.audit, "USD")
+	svc.SetLimits(limitsSvc)
+	if _, err := limitsSvc.SetSessionLimit(ctx, &limits.SetSessionLimitRequest{PlayerID: authResult.PlayerID, Minutes: 1}); err != nil {
+		t.Fatalf("Failed to set session limit: %v", err)
+	}
+
+	loginResult, err := svc.Login(ctx, &LoginRequest{
+		AuthToken:  "valid-auth-token",
+		DeviceType: "desktop",
+	}, "127.0.0.1", "TestAgent")
+	if err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+
+	if _, _, err := svc.ValidateToken(ctx, loginResult.Token); err != nil {
+		t.Fatalf("Expected fresh session to validate, got %v", err)
+	}
+
+	// Age the session past the one-minute limit
+	_, err = svc.db.ExecContext(ctx, "UPDATE sessions SET created_at = $1 WHERE id = $2",
+		time.Now().UTC().Add(-2*time.Minute), loginResult.Session.ID)
+	if err != nil {
+		t.Fatalf("Failed to age session: %v", err)
+	}
+
+	if _, _, err := svc.ValidateToken(ctx, loginResult.Token); err != ErrSessionLimit {
+		t.Errorf("Expected ErrSessionLimit, got %v", err)
+	}
+}
+
+func TestLoginBalanceReconciliation(t *testing.T) {
+	authResult := &pateplay.AuthenticateResult{
+		SessionToken: "mock-session-token",
+		PlayerID:     "33333333-3333-3333-3333-333333333333",
+		PlayerName:   "ReconcilePlayer",
+		Currency:     "USD",
+		Country:      "US",
+		Balance:      "1000.00",
+	}
+
+	svc, cleanup := setupTestAuthWithMock(t, "valid-auth-token", authResult)
+	defer cleanup()
+	svc.config.BalanceDiscrepancyThreshold = 5000 // $50
+
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	_, err := svc.db.ExecContext(ctx, `
+		INSERT INTO players (id, username, email, password_hash, status, registration_date, tc_accepted_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`, authResult.PlayerID, authResult.PlayerName, "reconcile@example.com", "", "active", now, now, now, now)
+	if err != nil {
+		t.Fatalf("Failed to insert test player: %v", err)
+	}
+
+	storedBalance := func() int64 {
+		var amount int64
+		svc.db.QueryRowContext(ctx, "SELECT real_money_amount FROM balances WHERE player_id = $1", authResult.PlayerID).Scan(&amount)
+		return amount
+	}
+	login := func() {
+		t.Helper()
+		if _, err := svc.Login(ctx, &LoginRequest{AuthToken: "valid-auth-token"}, "127.0.0.1", "TestAgent"); err != nil {
+			t.Fatalf("Login failed: %v", err)
+		}
+	}
+
+	t.Run("SmallDifferenceReconciled", func(t *testing.T) {
+		_, err := svc.db.ExecContext(ctx, `
+			INSERT INTO balances (player_id, real_money_amount, real_money_currency, bonus_amount, bonus_currency, updated_at)
+			VALUES ($1, 99000, 'USD', 0, 'USD', NOW())
+		`, authResult.PlayerID)
+		if err != nil {
+			t.Fatalf("Failed to create balance: %v", err)
+		}
+
+		login()
+
+		if storedBalance() != 100000 {
+			t.Errorf("Expected balance reconciled to 100000, got %d", storedBalance())
+		}
+
+		var txType string
+		var amount int64
+		err = svc.db.QueryRowContext(ctx, "SELECT type, amount FROM transactions WHERE player_id = $1", authResult.PlayerID).Scan(&txType, &amount)
+		if err != nil || txType != "adjustment" || amount != 1000 {
+			t.Errorf("Expected adjustment of 1000, got %s %d (%v)", txType, amount, err)
+		}
+	})
+
+	t.Run("LargeDifferenceFlagged", func(t *testing.T) {
+		svc.db.ExecContext(ctx, "UPDATE balances SET real_money_amount = 50000 WHERE player_id = $1", authResult.PlayerID)
+
+		login()
+
+		if storedBalance() != 50000 {
+			t.Errorf("Expected balance left at 50000 for review, got %d", storedBalance())
+		}
+
+		var count int
+		svc.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM audit_events WHERE type = 'balance_discrepancy' AND player_id = $1",
+			authResult.PlayerID).Scan(&count)
+		if count != 1 {
+			t.Errorf("Expected 1 balance_discrepancy audit event, got %d", count)
+		}
+	})
+}
+
+func TestLoginProvisionsNewPlayer(t *testing.T) {
+	authResult := &pateplay.AuthenticateResult{
+		SessionToken: "mock-session-token",
+		PlayerID:     "44444444-4444-4444-4444-444444444444",
+		PlayerName:   "FirstTimer",
+		Currency:     "EUR",
+		Country:      "DE",
+		Balance:      "250.50",
+	}
+
+	svc, cleanup := setupTestAuthWithMock(t, "valid-auth-token", authResult)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	result, err := svc.Login(ctx, &LoginRequest{AuthToken: "valid-auth-token"}, "127.0.0.1", "TestAgent")
+	if err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+	if result.Player.ID != authResult.PlayerID {
+		t.Errorf("Expected player ID %s, got %s", authResult.PlayerID, result.Player.ID)
+	}
+
+	var username, email, passwordHash string
+	err = svc.db.QueryRowContext(ctx, "SELECT username, email, password_hash FROM players WHERE id = $1",
+		authResult.PlayerID).Scan(&username, &email, &passwordHash)
+	if err != nil {
+		t.Fatalf("Provisioned player not found: %v", err)
+	}
+	if username != authResult.PlayerName {
+		t.Errorf("Expected username %s, got %s", authResult.PlayerName, username)
+	}
+	if _, err := mail.ParseAddress(email); err != nil {
+		t.Errorf("Expected valid synthetic email, got %s", email)
+	}
+	if passwordHash == "" || passwordHash == authResult.PlayerName {
+		t.Error("Expected an unusable password hash")
+	}
+
+	var amount int64
+	var currency string
+	svc.db.QueryRowContext(ctx, "SELECT real_money_amount, real_money_currency FROM balances WHERE player_id = $1",
+		authResult.PlayerID).Scan(&amount, &currency)
+	if amount != 25050 || currency != "EUR" {
+		t.Errorf("Expected balance 25050 EUR, got %d %s", amount, currency)
+	}
+
+	// A second login reuses the provisioned account
+	if _, err := svc.Login(ctx, &LoginRequest{AuthToken: "valid-auth-token"}, "127.0.0.1", "TestAgent"); err != nil {
+		t.Fatalf("Second login failed: %v", err)
+	}
+	var count int
+	svc.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM players WHERE id = $1", authResult.PlayerID).Scan(&count)
+	if count != 1 {
+		t.Errorf("Expected 1 player row, got %d", count)
+	}
+}
+
+func TestLoginAllowLists(t *testing.T) {
+	tests := []struct {
+		name     string
+		country  string
+		currency string
+		wantErr  error
+	}{
+		{"AllowedPlayer", "mt", "EUR", nil},
+		{"BlockedCountry", "US", "EUR", ErrCountryNotAllowed},
+		{"BlockedCurrency", "MT", "USD", ErrCurrencyNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authResult := &pateplay.AuthenticateResult{
+				SessionToken: "mock-session-token",
+				PlayerID:     uuid.New().String(),
+				PlayerName:   "AllowListPlayer",
+				Currency:     tt.currency,
+				Country:      tt.country,
+				Balance:      "100.00",
+			}
+
+			svc, cleanup := setupTestAuthWithMock(t, "valid-auth-token", authResult)
+			defer cleanup()
+			svc.config.AllowedCountries = []string{"MT", "GB"}
+			svc.config.AllowedCurrencies = []string{"EUR", "GBP"}
+
+			ctx := context.Background()
+			_, err := svc.Login(ctx, &LoginRequest{AuthToken: "valid-auth-token"}, "127.0.0.1", "TestAgent")
+
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Fatalf("Login failed: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Expected %v, got %v", tt.wantErr, err)
+			}
+
+			// Blocked players get no account and are audited
+			var players, events int
+			svc.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM players WHERE id = $1", authResult.PlayerID).Scan(&players)
+			if players != 0 {
+				t.Error("Expected no account for a blocked player")
+			}
+			svc.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM audit_events WHERE type = $1 AND player_id = $2",
+				audit.EventLoginBlocked, authResult.PlayerID).Scan(&events)
+			if events != 1 {
+				t.Errorf("Expected 1 login_blocked event, got %d", events)
+			}
+		})
+	}
 }
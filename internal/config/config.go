@@ -3,28 +3,48 @@ package config
 
 import (
 	"os"
+	"strconv"
+	"strings"
 	"time"
 )
 
 // Config holds all configuration for the RGS
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	Auth     AuthConfig
-	Game     GameConfig
+	Server    ServerConfig
+	Database  DatabaseConfig
+	Auth      AuthConfig
+	Game      GameConfig
+	RateLimit RateLimitConfig
 }
 
 // ServerConfig holds HTTP server configuration
 type ServerConfig struct {
-	Port         string
-	ReadTimeout  time.Duration
-	WriteTimeout time.Duration
+	Port            string
+	ReadTimeout     time.Duration
+	WriteTimeout    time.Duration
+	ShutdownTimeout time.Duration // How long shutdown waits for in-flight game cycles and requests
+
+	HealthCheckPateplay bool // Whether /health probes the Pateplay wallet API
+
+	// Reverse proxies, as IP addresses or CIDR ranges, trusted to name the
+	// client in X-Forwarded-For. Empty attributes requests to their peer.
+	TrustedProxies []string
 }
 
 // DatabaseConfig holds database configuration
 type DatabaseConfig struct {
 	Driver string
 	DSN    string
+
+	// Connection pool; zero leaves open connections and their lifetime
+	// unlimited
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+
+	// QueryTimeout bounds database work done outside an HTTP request, such
+	// as WebSocket messages
+	QueryTimeout time.Duration
 }
 
 // AuthConfig holds authentication configuration
@@ -34,25 +54,84 @@ type AuthConfig struct {
 	SessionTimeout    time.Duration
 	MaxFailedAttempts int
 	LockoutDuration   time.Duration
+
+	// BalanceDiscrepancyThreshold is the largest difference, in minor units,
+	// between the internal and Pateplay balances that is reconciled
+	// automatically at login. Larger differences are flagged for review.
+	BalanceDiscrepancyThreshold int64
+
+	// Countries (ISO 3166-1 alpha-2) and currencies (ISO 4217) Pateplay
+	// players may log in from and play in. Empty allows any.
+	AllowedCountries  []string
+	AllowedCurrencies []string
 }
 
 // GameConfig holds game-related configuration
 type GameConfig struct {
-	DefaultCurrency string
-	MinRTP          float64
+	DefaultCurrency         string
+	Locale                  string // Locale API responses format amounts in for display, e.g. "en-US"
+	MinRTP                  float64
+	BalanceUpdateInterval   time.Duration // Minimum interval between WebSocket balance updates
+	DefinitionsFile         string        // Optional JSON file of game definitions, replaces the built-in games
+	RecordRNGSeeds          bool          // Record a per-cycle RNG seed so cycles can be replayed
+	CertificationMode       bool          // Record every RNG draw of a cycle and its range
+	RNGReseedInterval       time.Duration // How often the RNG re-opens and checks its entropy source; 0 disables
+	RNGMonitorInterval      time.Duration // How often the RNG self-test runs in the background; 0 disables
+	SessionConflictPolicy   string        // "reject" or "end_stale" when a player reopens an active game
+	StaleCycleTimeout       time.Duration // In-progress cycles older than this are marked interrupted
+	StaleCycleSweepInterval time.Duration // How often to look for stale in-progress cycles
+	LargeWinAmount          int64         // Wins of at least this, in minor units, are audited as large wins; 0 disables
+	LargeWinMultiple        float64       // Wins of at least this multiple of the stake are audited as large wins; 0 disables
+	RealityCheckInterval    time.Duration // Play time between reality checks; 0 disables
+	MinExclusionPeriod      time.Duration // Shortest time a self-exclusion runs before it can be lifted
+	ExclusionExpiryInterval time.Duration // How often to lift self-exclusions that have run out
+
+	// Smallest and largest single deposit per currency in the major unit,
+	// e.g. "USD:10,EUR:10"; a currency not listed is unbounded
+	MinDeposit map[string]string
+	MaxDeposit map[string]string
+
+	// Deposits and withdrawals of at least this, in minor units, are audited
+	// as large transactions; 0 disables
+	LargeTransactionAmount int64
+}
+
+// RateLimitConfig holds API rate limits. Each limit is a token bucket that
+// refills at the given rate per second up to its burst; a rate of zero
+// disables the limit.
+type RateLimitConfig struct {
+	IPRate      float64 // Unauthenticated requests (login, register) per client IP
+	IPBurst     int
+	PlayerRate  float64 // Authenticated requests per player
+	PlayerBurst int
+
+	// Protected requests per client IP, checked before the token so bad
+	// tokens are limited too; set above the per-player limit since players
+	// can share an address
+	ProtectedIPRate  float64
+	ProtectedIPBurst int
 }
 
 // Load loads configuration from environment with defaults
 func Load() *Config {
 	return &Config{
 		Server: ServerConfig{
-			Port:         getEnv("RGS_PORT", "8080"),
-			ReadTimeout:  30 * time.Second,
-			WriteTimeout: 30 * time.Second,
+			Port:            getEnv("RGS_PORT", "8080"),
+			ReadTimeout:     30 * time.Second,
+			WriteTimeout:    30 * time.Second,
+			ShutdownTimeout: getEnvDuration("RGS_SHUTDOWN_TIMEOUT", 30*time.Second),
+
+			HealthCheckPateplay: getEnv("RGS_HEALTH_CHECK_PATEPLAY", "false") == "true",
+			TrustedProxies:      getEnvList("RGS_TRUSTED_PROXIES"),
 		},
 		Database: DatabaseConfig{
 			Driver: getEnv("RGS_DB_DRIVER", "postgres"),
 			DSN:    getEnv("RGS_DB_DSN", "host=localhost dbname=rgs sslmode=disable"),
+
+			MaxOpenConns:    getEnvInt("RGS_DB_MAX_OPEN_CONNS", 25),
+			MaxIdleConns:    getEnvInt("RGS_DB_MAX_IDLE_CONNS", 5),
+			ConnMaxLifetime: getEnvDuration("RGS_DB_CONN_MAX_LIFETIME", 30*time.Minute),
+			QueryTimeout:    getEnvDuration("RGS_DB_QUERY_TIMEOUT", 10*time.Second),
 		},
 		Auth: AuthConfig{
 			JWTSecret:         getEnv("RGS_JWT_SECRET", "rgs-dev-secret-change-in-production"),
@@ -60,10 +139,43 @@ func Load() *Config {
 			SessionTimeout:    30 * time.Minute,
 			MaxFailedAttempts: 3,
 			LockoutDuration:   30 * time.Minute,
+
+			BalanceDiscrepancyThreshold: getEnvInt64("RGS_BALANCE_DISCREPANCY_THRESHOLD", 10000),
+			AllowedCountries:            getEnvList("RGS_ALLOWED_COUNTRIES"),
+			AllowedCurrencies:           getEnvList("RGS_ALLOWED_CURRENCIES"),
 		},
 		Game: GameConfig{
-			DefaultCurrency: getEnv("RGS_CURRENCY", "USD"),
-			MinRTP:          0.75, // GLI-19 §4.7.1 - minimum 75%
+			DefaultCurrency:         getEnv("RGS_CURRENCY", "USD"),
+			Locale:                  getEnv("RGS_LOCALE", "en-US"),
+			MinRTP:                  0.75, // GLI-19 §4.7.1 - minimum 75%
+			BalanceUpdateInterval:   getEnvDuration("RGS_BALANCE_UPDATE_INTERVAL", 100*time.Millisecond),
+			DefinitionsFile:         getEnv("RGS_GAMES_FILE", ""),
+			RecordRNGSeeds:          getEnv("RGS_RECORD_RNG_SEEDS", "false") == "true",
+			CertificationMode:       getEnv("RGS_CERTIFICATION_MODE", "false") == "true",
+			RNGReseedInterval:       getEnvDuration("RGS_RNG_RESEED_INTERVAL", time.Hour),
+			RNGMonitorInterval:      getEnvDuration("RGS_RNG_MONITOR_INTERVAL", time.Minute),
+			SessionConflictPolicy:   getEnv("RGS_SESSION_CONFLICT_POLICY", "reject"),
+			StaleCycleTimeout:       getEnvDuration("RGS_STALE_CYCLE_TIMEOUT", 10*time.Minute),
+			StaleCycleSweepInterval: getEnvDuration("RGS_STALE_CYCLE_SWEEP_INTERVAL", time.Minute),
+			LargeWinAmount:          getEnvInt64("RGS_LARGE_WIN_AMOUNT", 10000),
+			LargeWinMultiple:        getEnvFloat("RGS_LARGE_WIN_MULTIPLE", 0),
+			RealityCheckInterval:    getEnvDuration("RGS_REALITY_CHECK_INTERVAL", time.Hour),
+			MinExclusionPeriod:      getEnvDuration("RGS_MIN_EXCLUSION_PERIOD", 24*time.Hour),
+			ExclusionExpiryInterval: getEnvDuration("RGS_EXCLUSION_EXPIRY_INTERVAL", 5*time.Minute),
+
+			MinDeposit: getEnvMap("RGS_MIN_DEPOSIT"),
+			MaxDeposit: getEnvMap("RGS_MAX_DEPOSIT"),
+
+			LargeTransactionAmount: getEnvInt64("RGS_LARGE_TRANSACTION_AMOUNT", 1000000),
+		},
+		RateLimit: RateLimitConfig{
+			IPRate:      getEnvFloat("RGS_RATE_LIMIT_IP_RATE", 1),
+			IPBurst:     getEnvInt("RGS_RATE_LIMIT_IP_BURST", 10),
+			PlayerRate:  getEnvFloat("RGS_RATE_LIMIT_PLAYER_RATE", 10),
+			PlayerBurst: getEnvInt("RGS_RATE_LIMIT_PLAYER_BURST", 30),
+
+			ProtectedIPRate:  getEnvFloat("RGS_RATE_LIMIT_PROTECTED_IP_RATE", 50),
+			ProtectedIPBurst: getEnvInt("RGS_RATE_LIMIT_PROTECTED_IP_BURST", 150),
 		},
 	}
 }
@@ -74,3 +186,68 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// getEnvList returns a comma-separated environment variable as a list,
+// or nil when it is unset
+func getEnvList(key string) []string {
+	var list []string
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// getEnvMap returns a comma-separated list of key:value pairs as a map, or
+// nil when it is unset. Pairs without a colon are ignored.
+func getEnvMap(key string) map[string]string {
+	var m map[string]string
+	for _, item := range getEnvList(key) {
+		k, v, ok := strings.Cut(item, ":")
+		if !ok {
+			continue
+		}
+		if m == nil {
+			m = make(map[string]string)
+		}
+		m[strings.TrimSpace(k)] = strings.TrimSpace(v)
+	}
+	return m
+}
+
+func getEnvInt64(key string, defaultValue int64) int64 {
+	if value := os.Getenv(key); value != "" {
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return n
+		}
+	}
+	return defaultValue
+}
+
+func getEnvInt(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		if n, err := strconv.Atoi(value); err == nil {
+			return n
+		}
+	}
+	return defaultValue
+}
+
+func getEnvFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return f
+		}
+	}
+	return defaultValue
+}
+
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if d, err := time.ParseDuration(value); err == nil {
+			return d
+		}
+	}
+	return defaultValue
+}
@@ -13,10 +13,12 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
 	"github.com/alexbotov/rgs/internal/audit"
+	"github.com/alexbotov/rgs/internal/auth"
 	"github.com/alexbotov/rgs/internal/domain"
 )
 
@@ -24,6 +26,9 @@ var (
 	ErrGamingDisabled = errors.New("gaming is currently disabled")
 	ErrGameDisabled   = errors.New("game is currently disabled")
 	ErrPlayerDisabled = errors.New("player account is disabled")
+
+	ErrPlayerNotFound     = errors.New("player not found")
+	ErrPlayerSelfExcluded = errors.New("cannot enable player with active self-exclusion")
 )
 
 // Service provides gaming system control functionality
@@ -31,20 +36,22 @@ var (
 type Service struct {
 	db    *sql.DB
 	audit *audit.Service
+	auth  *auth.Service
 
-	mu            sync.RWMutex
-	gamingEnabled bool
-	disabledGames map[string]bool
-	disabledAt    *time.Time
-	disabledBy    string
+	mu             sync.RWMutex
+	gamingEnabled  bool
+	disabledGames  map[string]bool
+	disabledAt     *time.Time
+	disabledBy     string
 	disabledReason string
 }
 
 // New creates a new control service
-func New(db *sql.DB, auditSvc *audit.Service) *Service {
+func New(db *sql.DB, auditSvc *audit.Service, authSvc *auth.Service) *Service {
 	return &Service{
 		db:            db,
 		audit:         auditSvc,
+		auth:          authSvc,
 		gamingEnabled: true,
 		disabledGames: make(map[string]bool),
 	}
@@ -173,19 +180,19 @@ func (s *Service) EnableGame(ctx context.Context, gameID, authorizedBy string) e
 func (s *Service) DisablePlayer(ctx context.Context, playerID, reason, authorizedBy string) error {
 	now := time.Now().UTC()
 
-	_, err := s.db.ExecContext(ctx, `
+	result, err := s.db.ExecContext(ctx, `
 		UPDATE players SET status = $1, updated_at = $2 WHERE id = $3
 	`, domain.PlayerStatusSuspended, now, playerID)
 	if err != nil {
 		return fmt.Errorf("failed to disable player: %w", err)
 	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return ErrPlayerNotFound
+	}
 
 	// Terminate active sessions
-	_, err = s.db.ExecContext(ctx, `
-		UPDATE sessions SET status = $1 WHERE player_id = $2 AND status = $3
-	`, domain.SessionStatusExpired, playerID, domain.SessionStatusActive)
-	if err != nil {
-		return fmt.Errorf("failed to terminate sessions: %w", err)
+	if err := s.auth.TerminateAllSessions(ctx, playerID, "account disabled: "+reason); err != nil {
+		return err
 	}
 
 	// Audit log - GLI-19 §2.8.8
@@ -217,15 +224,18 @@ func (s *Service) EnablePlayer(ctx context.Context, playerID, authorizedBy strin
 		return err
 	}
 	if exclusionCount > 0 {
-		return errors.New("cannot enable player with active self-exclusion")
+		return ErrPlayerSelfExcluded
 	}
 
-	_, err = s.db.ExecContext(ctx, `
+	result, err := s.db.ExecContext(ctx, `
 		UPDATE players SET status = $1, updated_at = $2 WHERE id = $3
 	`, domain.PlayerStatusActive, now, playerID)
 	if err != nil {
 		return fmt.Errorf("failed to enable player: %w", err)
 	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return ErrPlayerNotFound
+	}
 
 	s.audit.Log(ctx, audit.EventAccountStatusChange, domain.SeverityInfo,
 		"Player account enabled",
@@ -268,8 +278,15 @@ func (s *Service) GetSystemStatus(ctx context.Context) (*domain.GamingSystemStat
 		return nil, err
 	}
 
+	disabledGames := make([]string, 0, len(s.disabledGames))
+	for gameID := range s.disabledGames {
+		disabledGames = append(disabledGames, gameID)
+	}
+	sort.Strings(disabledGames)
+
 	status := &domain.GamingSystemStatus{
 		GamingEnabled:   s.gamingEnabled,
+		DisabledGames:   disabledGames,
 		DisabledAt:      s.disabledAt,
 		DisabledBy:      s.disabledBy,
 		DisabledReason:  s.disabledReason,
@@ -337,4 +354,3 @@ func (s *Service) LoadState(ctx context.Context) error {
 
 	return nil
 }
-
@@ -5,36 +5,29 @@ import (
 	"testing"
 
 	"github.com/alexbotov/rgs/internal/audit"
-	"github.com/alexbotov/rgs/internal/database"
+	"github.com/alexbotov/rgs/internal/auth"
+	"github.com/alexbotov/rgs/internal/config"
+	"github.com/alexbotov/rgs/internal/database/dbtest"
 	"github.com/alexbotov/rgs/internal/domain"
 	"github.com/google/uuid"
 )
 
+func TestMain(m *testing.M) {
+	dbtest.Main(m)
+}
+
 func setupTestControl(t *testing.T) (*Service, string, func()) {
 	t.Helper()
 
-	// Create PostgreSQL connection
-	db, err := database.New("postgres", "host=localhost dbname=rgs sslmode=disable")
-	if err != nil {
-		t.Fatalf("Failed to create database: %v", err)
-	}
-
-	// Ensure schema exists (idempotent)
-	if err := db.Migrate(); err != nil {
-		t.Logf("Migration note: %v", err)
-	}
-
-	// Clean data for fresh test state
-	if err := db.CleanData(); err != nil {
-		t.Fatalf("Failed to clean data: %v", err)
-	}
+	db := dbtest.Open(t)
 
 	auditSvc := audit.New(db.DB)
-	svc := New(db.DB, auditSvc)
+	authSvc := auth.New(db.DB, &config.AuthConfig{JWTSecret: "test-secret"}, auditSvc, nil)
+	svc := New(db.DB, auditSvc, authSvc)
 
 	// Create a test player
 	playerID := uuid.New().String()
-	_, err = db.DB.Exec(`
+	_, err := db.DB.Exec(`
 		INSERT INTO players (id, username, email, password_hash, status, registration_date, tc_accepted_at, created_at, updated_at)
 		VALUES ($1, 'controluser', 'control@example.com', 'hash', 'active', NOW(), NOW(), NOW(), NOW())
 	`, playerID)
@@ -51,10 +44,7 @@ func setupTestControl(t *testing.T) (*Service, string, func()) {
 		t.Fatalf("Failed to create balance: %v", err)
 	}
 
-	return svc, playerID, func() {
-		db.CleanData()
-		db.Close()
-	}
+	return svc, playerID, func() {}
 }
 
 func TestGamingEnabled(t *testing.T) {
@@ -147,11 +137,29 @@ func TestDisablePlayer(t *testing.T) {
 	ctx := context.Background()
 
 	t.Run("DisablePlayer", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			_, err := svc.db.ExecContext(ctx, `
+				INSERT INTO sessions (id, player_id, token, ip_address, user_agent, created_at, last_activity_at, expires_at, status)
+				VALUES ($1, $2, $3, '127.0.0.1', 'TestAgent', NOW(), NOW(), NOW() + INTERVAL '1 hour', 'active')
+			`, uuid.New().String(), playerID, uuid.New().String())
+			if err != nil {
+				t.Fatalf("Failed to create session: %v", err)
+			}
+		}
+
 		err := svc.DisablePlayer(ctx, playerID, "Suspicious activity", "admin@example.com")
 		if err != nil {
 			t.Fatalf("Failed to disable player: %v", err)
 		}
 
+		sessions, err := svc.auth.GetActiveSessions(ctx, playerID)
+		if err != nil {
+			t.Fatalf("Failed to get sessions: %v", err)
+		}
+		if len(sessions) != 0 {
+			t.Errorf("Expected all sessions terminated, %d still active", len(sessions))
+		}
+
 		// Verify player status is suspended
 		err = svc.CheckAccess(ctx, playerID, "fortune-slots")
 		if err == nil {
@@ -174,6 +182,13 @@ func TestDisablePlayer(t *testing.T) {
 			t.Errorf("Expected no error for enabled player, got: %v", err)
 		}
 	})
+
+	t.Run("UnknownPlayer", func(t *testing.T) {
+		err := svc.DisablePlayer(ctx, uuid.New().String(), "Test", "admin@example.com")
+		if err != ErrPlayerNotFound {
+			t.Errorf("Expected ErrPlayerNotFound, got: %v", err)
+		}
+	})
 }
 
 func TestCheckAccess(t *testing.T) {
@@ -301,8 +316,8 @@ func TestCannotEnableExcludedPlayer(t *testing.T) {
 
 	t.Run("CannotEnableExcludedPlayer", func(t *testing.T) {
 		err := svc.EnablePlayer(ctx, playerID, "admin")
-		if err == nil {
-			t.Error("Expected error when trying to enable excluded player")
+		if err != ErrPlayerSelfExcluded {
+			t.Errorf("Expected ErrPlayerSelfExcluded, got %v", err)
 		}
 	})
 }
@@ -318,7 +333,7 @@ func TestLoadState(t *testing.T) {
 		svc.DisableAllGaming(ctx, "Test", "admin")
 
 		// Create a new service instance (simulating restart)
-		svc2 := New(svc.db, svc.audit)
+		svc2 := New(svc.db, svc.audit, svc.auth)
 
 		// Load state
 		err := svc2.LoadState(ctx)
@@ -0,0 +1,78 @@
+package database_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alexbotov/rgs/internal/database"
+	"github.com/alexbotov/rgs/internal/database/dbtest"
+)
+
+func TestPoolOptions(t *testing.T) {
+	db, err := database.New("postgres", dbtest.DSN(t),
+		database.WithMaxOpenConns(7), database.WithMaxIdleConns(2), database.WithConnMaxLifetime(time.Minute))
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	if got := db.DB.Stats().MaxOpenConnections; got != 7 {
+		t.Errorf("Expected sql.DBStats max open 7, got %d", got)
+	}
+
+	stats := db.Stats()
+	if stats.MaxOpenConnections != 7 {
+		t.Errorf("Expected pool stats max open 7, got %d", stats.MaxOpenConnections)
+	}
+	// New pings the database, leaving one connection open
+	if stats.OpenConnections != 1 || stats.Idle != 1 || stats.InUse != 0 {
+		t.Errorf("Expected one idle connection, got %+v", stats)
+	}
+}
+
+func TestBoundedContext(t *testing.T) {
+	t.Run("DefaultTimeout", func(t *testing.T) {
+		ctx, cancel := database.BoundedContext(context.Background(), 0)
+		defer cancel()
+		deadline, ok := ctx.Deadline()
+		if !ok {
+			t.Fatal("Expected a deadline")
+		}
+		if left := time.Until(deadline); left <= 0 || left > database.DefaultQueryTimeout {
+			t.Errorf("Expected a deadline within %v, got %v", database.DefaultQueryTimeout, left)
+		}
+	})
+
+	t.Run("SoonerParentDeadline", func(t *testing.T) {
+		parent, cancelParent := context.WithTimeout(context.Background(), time.Millisecond)
+		defer cancelParent()
+		ctx, cancel := database.BoundedContext(parent, time.Hour)
+		defer cancel()
+		deadline, _ := ctx.Deadline()
+		if time.Until(deadline) > time.Second {
+			t.Errorf("Expected the parent's deadline to apply, got %v", deadline)
+		}
+	})
+}
+
+func TestBoundedContextSlowQuery(t *testing.T) {
+	db := dbtest.Open(t)
+
+	ctx, cancel := database.BoundedContext(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	// A stalled query is abandoned at the deadline rather than hanging
+	start := time.Now()
+	_, err := db.ExecContext(ctx, "SELECT pg_sleep(10)")
+	elapsed := time.Since(start)
+
+	// The driver reports the cancelled statement in its own words
+	if err == nil || !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		t.Errorf("Expected the query to fail at the deadline, got %v", err)
+	}
+	if elapsed > 5*time.Second {
+		t.Errorf("Expected the query to be abandoned at the deadline, took %v", elapsed)
+	}
+}
@@ -0,0 +1,167 @@
+// Package dbtest opens the database for integration tests.
+//
+// Tests run against the Postgres named by RGS_TEST_DATABASE_URL, or a local
+// rgs database when it is unset. With RGS_TEST_POSTGRES=docker an ephemeral
+// Postgres container is started for the test binary instead, and removed by
+// Main when the tests finish. When no database can be reached the tests that
+// need one are skipped, so `go test ./...` passes on a machine without
+// Postgres; set RGS_TEST_REQUIRE_DB to fail them instead, as CI should.
+package dbtest
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alexbotov/rgs/internal/database"
+)
+
+// DefaultDSN is the database used when RGS_TEST_DATABASE_URL is unset
+const DefaultDSN = "host=localhost dbname=rgs sslmode=disable"
+
+// DefaultImage is the Postgres image started when RGS_TEST_POSTGRES=docker,
+// unless RGS_TEST_POSTGRES_IMAGE names another
+const DefaultImage = "postgres:16-alpine"
+
+// containerStartTimeout bounds how long a new container may take to accept
+// connections
+const containerStartTimeout = 60 * time.Second
+
+var (
+	once      sync.Once
+	dsn       string
+	container string
+	setupErr  error
+)
+
+// Open connects to the test database, applies the migrations and removes
+// all data, so the test starts from an empty schema. The data is removed
+// again and the connection closed when the test ends. Without a reachable
+// database the test is skipped, or fails when RGS_TEST_REQUIRE_DB is set.
+func Open(t testing.TB) *database.DB {
+	t.Helper()
+
+	once.Do(resolve)
+	if setupErr != nil {
+		unavailable(t, setupErr)
+	}
+
+	db, err := database.New("postgres", dsn)
+	if err != nil {
+		unavailable(t, err)
+	}
+
+	// Ensure schema exists (idempotent)
+	if err := db.Migrate(); err != nil {
+		t.Logf("Migration note: %v", err)
+	}
+
+	// Clean data for fresh test state
+	if err := db.CleanData(); err != nil {
+		db.Close()
+		t.Fatalf("Failed to clean data: %v", err)
+	}
+
+	t.Cleanup(func() {
+		db.CleanData()
+		db.Close()
+	})
+	return db
+}
+
+// DSN returns the connection string of the test database, for tests that
+// manage their own connections, such as to a separate schema. Without a
+// reachable database the test is skipped, or fails when RGS_TEST_REQUIRE_DB
+// is set.
+func DSN(t testing.TB) string {
+	t.Helper()
+
+	once.Do(resolve)
+	if setupErr != nil {
+		unavailable(t, setupErr)
+	}
+
+	db, err := database.New("postgres", dsn)
+	if err != nil {
+		unavailable(t, err)
+	}
+	db.Close()
+	return dsn
+}
+
+// Main runs the package's tests and removes the container started for
+// them, if any. Packages using Open call it from TestMain.
+func Main(m *testing.M) {
+	code := m.Run()
+	if container != "" {
+		exec.Command("docker", "rm", "-f", container).Run()
+	}
+	os.Exit(code)
+}
+
+// resolve picks the database the tests run against
+func resolve() {
+	if os.Getenv("RGS_TEST_POSTGRES") == "docker" {
+		dsn, setupErr = startContainer()
+		return
+	}
+	dsn = os.Getenv("RGS_TEST_DATABASE_URL")
+	if dsn == "" {
+		dsn = DefaultDSN
+	}
+}
+
+// startContainer starts a throwaway Postgres on a free local port and waits
+// until it accepts connections
+func startContainer() (string, error) {
+	image := os.Getenv("RGS_TEST_POSTGRES_IMAGE")
+	if image == "" {
+		image = DefaultImage
+	}
+
+	out, err := exec.Command("docker", "run", "-d", "--rm",
+		"-e", "POSTGRES_PASSWORD=rgs", "-e", "POSTGRES_DB=rgs",
+		"-p", "127.0.0.1::5432", image).Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to start postgres container: %w", err)
+	}
+	container = strings.TrimSpace(string(out))
+
+	out, err = exec.Command("docker", "port", container, "5432/tcp").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to find postgres container port: %w", err)
+	}
+	host, port, err := net.SplitHostPort(strings.TrimSpace(strings.SplitN(string(out), "\n", 2)[0]))
+	if err != nil {
+		return "", fmt.Errorf("unexpected postgres container port %q: %w", out, err)
+	}
+	containerDSN := fmt.Sprintf("host=%s port=%s user=postgres password=rgs dbname=rgs sslmode=disable", host, port)
+
+	deadline := time.Now().Add(containerStartTimeout)
+	for {
+		db, err := database.New("postgres", containerDSN)
+		if err == nil {
+			db.Close()
+			return containerDSN, nil
+		}
+		if time.Now().After(deadline) {
+			return "", fmt.Errorf("postgres container not ready after %v: %w", containerStartTimeout, err)
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+}
+
+// unavailable skips the test for lack of a database, or fails it when one
+// is required
+func unavailable(t testing.TB, err error) {
+	t.Helper()
+	if os.Getenv("RGS_TEST_REQUIRE_DB") != "" {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	t.Skipf("No test database available: %v", err)
+}
@@ -0,0 +1,9 @@
+package database
+
+// Migrations and MigrateTo expose the migration list to the external tests
+var Migrations = migrations
+
+// MigrateTo applies the given migrations in place of the built-in list
+func (db *DB) MigrateTo(ms []Migration) error {
+	return db.migrate(ms)
+}
@@ -0,0 +1,379 @@
+// Package database - Versioned schema migrations
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Migration is one numbered step in the evolution of the schema. Once
+// released, a migration is never edited; schema changes go in a new
+// migration with the next version.
+type Migration struct {
+	Version     int
+	Description string
+	SQL         string
+}
+
+// migrations is the schema history, in version order
+var migrations = []Migration{
+	{Version: 1, Description: "Initial schema", SQL: initialSchema},
+	{Version: 2, Description: "Record RNG draws per game cycle", SQL: `
+		ALTER TABLE game_cycles ADD COLUMN IF NOT EXISTS rng_trace JSONB;
+	`},
+	{Version: 3, Description: "Reality checks", SQL: `
+		CREATE TABLE IF NOT EXISTS reality_checks (
+			player_id UUID PRIMARY KEY REFERENCES players(id),
+			played_ms BIGINT NOT NULL DEFAULT 0,
+			next_check_ms BIGINT NOT NULL,
+			last_play_at TIMESTAMP,
+			pending BOOLEAN NOT NULL DEFAULT false,
+			notified_at TIMESTAMP,
+			acknowledged_at TIMESTAMP
+		);
+	`},
+	{Version: 4, Description: "Pateplay transaction references per game cycle", SQL: `
+		ALTER TABLE game_cycles ADD COLUMN IF NOT EXISTS pateplay_session_token TEXT;
+		ALTER TABLE game_cycles ADD COLUMN IF NOT EXISTS pateplay_player_id TEXT;
+		ALTER TABLE game_cycles ADD COLUMN IF NOT EXISTS pateplay_round_id TEXT;
+		ALTER TABLE game_cycles ADD COLUMN IF NOT EXISTS pateplay_transaction_id TEXT;
+	`},
+	{Version: 5, Description: "Index game cycles by Pateplay transaction", SQL: `
+		CREATE INDEX IF NOT EXISTS idx_game_cycles_pateplay_tx ON game_cycles(pateplay_transaction_id)
+			WHERE pateplay_transaction_id IS NOT NULL;
+	`},
+	{Version: 6, Description: "Pending player limits", SQL: `
+		CREATE TABLE IF NOT EXISTS pending_limits (
+			player_id UUID NOT NULL REFERENCES players(id),
+			limit_type VARCHAR(50) NOT NULL,
+			amount BIGINT NOT NULL DEFAULT 0,
+			effective_at TIMESTAMP NOT NULL,
+			created_at TIMESTAMP NOT NULL,
+			PRIMARY KEY (player_id, limit_type)
+		);
+	`},
+	{Version: 7, Description: "Record who changed each limit", SQL: `
+		ALTER TABLE limit_change_history ADD COLUMN IF NOT EXISTS changed_by VARCHAR(255);
+		ALTER TABLE limit_change_history ADD COLUMN IF NOT EXISTS seq BIGSERIAL;
+		CREATE INDEX IF NOT EXISTS idx_limit_change_history_type ON limit_change_history(player_id, limit_type, seq);
+	`},
+	{Version: 8, Description: "Unique deposit and withdrawal references", SQL: `
+		-- References reused before they were unique stay on the earliest
+		-- transaction; later ones are suffixed with their own id
+		UPDATE transactions t SET reference = LEFT(t.reference, 218) || ':' || t.id::text
+		FROM (
+			SELECT id, ROW_NUMBER() OVER (PARTITION BY player_id, type, reference ORDER BY created_at, id) AS n
+			FROM transactions
+			WHERE type IN ('deposit', 'withdrawal') AND reference <> ''
+		) d
+		WHERE t.id = d.id AND d.n > 1;
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_transactions_reference ON transactions(player_id, type, reference)
+			WHERE type IN ('deposit', 'withdrawal') AND reference <> '';
+	`},
+	{Version: 9, Description: "Login session of each game session", SQL: `
+		ALTER TABLE game_sessions ADD COLUMN IF NOT EXISTS login_session_id UUID;
+		CREATE INDEX IF NOT EXISTS idx_game_sessions_login ON game_sessions(login_session_id) WHERE status = 'active';
+	`},
+	{Version: 10, Description: "Bonus part of each transaction", SQL: `
+		ALTER TABLE transactions ADD COLUMN IF NOT EXISTS bonus_amount BIGINT NOT NULL DEFAULT 0;
+	`},
+	{Version: 11, Description: "Time of the last step of each game cycle", SQL: `
+		ALTER TABLE game_cycles ADD COLUMN IF NOT EXISTS last_step_at TIMESTAMP;
+	`},
+}
+
+// Migrate applies all pending migrations in version order
+// Based on GLI-19 §2.8 Information to be Maintained
+func (db *DB) Migrate() error {
+	if err := db.migrate(migrations); err != nil {
+		return fmt.Errorf("failed to run migrations: %w", err)
+	}
+	return nil
+}
+
+// migrate applies the migrations not yet recorded in schema_migrations, each
+// in its own transaction
+func (db *DB) migrate(ms []Migration) error {
+	for i := 1; i < len(ms); i++ {
+		if ms[i].Version <= ms[i-1].Version {
+			return fmt.Errorf("migration %d is out of order", ms[i].Version)
+		}
+	}
+
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INTEGER PRIMARY KEY,
+			description TEXT NOT NULL,
+			applied_at TIMESTAMP NOT NULL
+		)
+	`)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range ms {
+		if err := db.applyMigration(m); err != nil {
+			return fmt.Errorf("migration %d (%s): %w", m.Version, m.Description, err)
+		}
+	}
+	return nil
+}
+
+// applyMigration runs a migration unless it has already been applied. The
+// table lock makes servers starting together apply it only once.
+func (db *DB) applyMigration(m Migration) error {
+	return db.WithTx(context.Background(), func(dbTx *sql.Tx) error {
+		if _, err := dbTx.Exec("LOCK TABLE schema_migrations IN EXCLUSIVE MODE"); err != nil {
+			return err
+		}
+
+		var applied bool
+		err := dbTx.QueryRow("SELECT EXISTS (SELECT 1 FROM schema_migrations WHERE version = $1)", m.Version).Scan(&applied)
+		if err != nil || applied {
+			return err
+		}
+
+		if _, err := dbTx.Exec(m.SQL); err != nil {
+			return err
+		}
+		_, err = dbTx.Exec(`
+			INSERT INTO schema_migrations (version, description, applied_at) VALUES ($1, $2, $3)
+		`, m.Version, m.Description, time.Now().UTC())
+		return err
+	})
+}
+
+// initialSchema is the schema as it stood before versioned migrations. It
+// only creates what is missing, so databases created before versioning take
+// it as a no-op.
+const initialSchema = `
+	-- Players table (GLI-19 §2.5, §2.8.5)
+	CREATE TABLE IF NOT EXISTS players (
+		id UUID PRIMARY KEY,
+		username VARCHAR(255) UNIQUE NOT NULL,
+		email VARCHAR(255) UNIQUE NOT NULL,
+		password_hash VARCHAR(255) NOT NULL,
+		status VARCHAR(50) NOT NULL DEFAULT 'active',
+		registration_date TIMESTAMP NOT NULL,
+		last_login_at TIMESTAMP,
+		tc_accepted_at TIMESTAMP NOT NULL,
+		created_at TIMESTAMP NOT NULL,
+		updated_at TIMESTAMP NOT NULL,
+		role VARCHAR(20) NOT NULL DEFAULT 'player'
+	);
+	ALTER TABLE players ADD COLUMN IF NOT EXISTS role VARCHAR(20) NOT NULL DEFAULT 'player';
+
+	-- Sessions table (GLI-19 §2.5.3)
+	CREATE TABLE IF NOT EXISTS sessions (
+		id UUID PRIMARY KEY,
+		player_id UUID NOT NULL REFERENCES players(id),
+		token TEXT NOT NULL,
+		ip_address VARCHAR(45) NOT NULL,
+		user_agent TEXT,
+		created_at TIMESTAMP NOT NULL,
+		last_activity_at TIMESTAMP NOT NULL,
+		expires_at TIMESTAMP NOT NULL,
+		status VARCHAR(50) NOT NULL DEFAULT 'active'
+	);
+
+	-- Balances table (GLI-19 §2.5.7)
+	CREATE TABLE IF NOT EXISTS balances (
+		player_id UUID PRIMARY KEY REFERENCES players(id),
+		real_money_amount BIGINT NOT NULL DEFAULT 0,
+		real_money_currency VARCHAR(3) NOT NULL DEFAULT 'USD',
+		bonus_amount BIGINT NOT NULL DEFAULT 0,
+		bonus_currency VARCHAR(3) NOT NULL DEFAULT 'USD',
+		wagering_remaining BIGINT NOT NULL DEFAULT 0,
+		updated_at TIMESTAMP NOT NULL
+	);
+	ALTER TABLE balances ADD COLUMN IF NOT EXISTS wagering_remaining BIGINT NOT NULL DEFAULT 0;
+
+	-- Transactions table (GLI-19 §2.5.6, §2.5.7, §2.8.5)
+	CREATE TABLE IF NOT EXISTS transactions (
+		id UUID PRIMARY KEY,
+		player_id UUID NOT NULL REFERENCES players(id),
+		type VARCHAR(50) NOT NULL,
+		amount BIGINT NOT NULL,
+		currency VARCHAR(3) NOT NULL,
+		balance_before BIGINT NOT NULL,
+		balance_after BIGINT NOT NULL,
+		status VARCHAR(50) NOT NULL,
+		reference VARCHAR(255),
+		description TEXT,
+		created_at TIMESTAMP NOT NULL,
+		completed_at TIMESTAMP,
+		idempotency_key VARCHAR(255)
+	);
+	ALTER TABLE transactions ADD COLUMN IF NOT EXISTS idempotency_key VARCHAR(255);
+	CREATE UNIQUE INDEX IF NOT EXISTS idx_transactions_idempotency
+		ON transactions(player_id, type, idempotency_key) WHERE idempotency_key IS NOT NULL;
+
+	-- Game Sessions table (GLI-19 §4.3)
+	CREATE TABLE IF NOT EXISTS game_sessions (
+		id UUID PRIMARY KEY,
+		player_id UUID NOT NULL REFERENCES players(id),
+		game_id VARCHAR(255) NOT NULL,
+		started_at TIMESTAMP NOT NULL,
+		ended_at TIMESTAMP,
+		last_activity_at TIMESTAMP NOT NULL,
+		status VARCHAR(50) NOT NULL DEFAULT 'active',
+		opening_balance BIGINT NOT NULL,
+		current_balance BIGINT NOT NULL,
+		total_wagered BIGINT NOT NULL DEFAULT 0,
+		total_won BIGINT NOT NULL DEFAULT 0,
+		games_played INTEGER NOT NULL DEFAULT 0,
+		currency VARCHAR(3) NOT NULL,
+		feature_state JSONB
+	);
+	ALTER TABLE game_sessions ADD COLUMN IF NOT EXISTS feature_state JSONB;
+	ALTER TABLE game_sessions ADD COLUMN IF NOT EXISTS rtp_variant VARCHAR(50);
+
+	-- Certified RTP variant selected per game (GLI-19 §4.7)
+	CREATE TABLE IF NOT EXISTS game_rtp_variants (
+		game_id VARCHAR(255) PRIMARY KEY,
+		variant VARCHAR(50) NOT NULL,
+		authorized_by VARCHAR(255) NOT NULL,
+		updated_at TIMESTAMP NOT NULL
+	);
+
+	-- Game Cycles table (GLI-19 §4.3.3, §2.8.2)
+	CREATE TABLE IF NOT EXISTS game_cycles (
+		id UUID PRIMARY KEY,
+		session_id UUID NOT NULL REFERENCES game_sessions(id),
+		player_id UUID NOT NULL REFERENCES players(id),
+		game_id VARCHAR(255) NOT NULL,
+		started_at TIMESTAMP NOT NULL,
+		completed_at TIMESTAMP,
+		wager_amount BIGINT NOT NULL,
+		win_amount BIGINT NOT NULL DEFAULT 0,
+		balance_before BIGINT NOT NULL,
+		balance_after BIGINT NOT NULL,
+		outcome JSONB,
+		status VARCHAR(50) NOT NULL DEFAULT 'pending',
+		currency VARCHAR(3) NOT NULL,
+		game_state JSONB
+	);
+	ALTER TABLE game_cycles ADD COLUMN IF NOT EXISTS game_state JSONB;
+	ALTER TABLE game_cycles ADD COLUMN IF NOT EXISTS interrupted_at TIMESTAMP;
+	ALTER TABLE game_cycles ADD COLUMN IF NOT EXISTS interrupt_reason VARCHAR(100);
+	ALTER TABLE game_cycles ADD COLUMN IF NOT EXISTS win_banked BOOLEAN NOT NULL DEFAULT true;
+
+	-- Progressive jackpot pools, one per game and currency
+	CREATE TABLE IF NOT EXISTS jackpot_pools (
+		game_id VARCHAR(255) NOT NULL,
+		currency VARCHAR(3) NOT NULL,
+		amount BIGINT NOT NULL,
+		updated_at TIMESTAMP NOT NULL,
+		PRIMARY KEY (game_id, currency)
+	);
+
+	-- Audit Events table (GLI-19 §2.8.8)
+	CREATE TABLE IF NOT EXISTS audit_events (
+		id UUID PRIMARY KEY,
+		type VARCHAR(100) NOT NULL,
+		severity VARCHAR(20) NOT NULL,
+		timestamp TIMESTAMP NOT NULL,
+		player_id UUID,
+		session_id UUID,
+		description TEXT NOT NULL,
+		data JSONB,
+		ip_address VARCHAR(45),
+		component VARCHAR(100) NOT NULL,
+		request_id VARCHAR(64),
+		seq BIGSERIAL,
+		prev_hash VARCHAR(64),
+		hash VARCHAR(64)
+	);
+	ALTER TABLE audit_events ADD COLUMN IF NOT EXISTS request_id VARCHAR(64);
+	ALTER TABLE audit_events ADD COLUMN IF NOT EXISTS seq BIGSERIAL;
+	ALTER TABLE audit_events ADD COLUMN IF NOT EXISTS prev_hash VARCHAR(64);
+	ALTER TABLE audit_events ADD COLUMN IF NOT EXISTS hash VARCHAR(64);
+
+	-- Failed Login Attempts table (GLI-19 §2.8.8)
+	CREATE TABLE IF NOT EXISTS failed_logins (
+		id UUID PRIMARY KEY,
+		username VARCHAR(255) NOT NULL,
+		ip_address VARCHAR(45) NOT NULL,
+		attempted_at TIMESTAMP NOT NULL
+	);
+
+	-- Player Limits table (GLI-19 §2.5.5)
+	CREATE TABLE IF NOT EXISTS player_limits (
+		id UUID PRIMARY KEY,
+		player_id UUID NOT NULL REFERENCES players(id),
+		daily_deposit BIGINT,
+		weekly_deposit BIGINT,
+		monthly_deposit BIGINT,
+		daily_wager BIGINT,
+		weekly_wager BIGINT,
+		daily_loss BIGINT,
+		weekly_loss BIGINT,
+		session_duration INTEGER,
+		cooling_off_until TIMESTAMP,
+		source VARCHAR(50) NOT NULL DEFAULT 'player',
+		effective_at TIMESTAMP NOT NULL,
+		updated_at TIMESTAMP NOT NULL,
+		UNIQUE(player_id, source)
+	);
+	ALTER TABLE player_limits DROP CONSTRAINT IF EXISTS player_limits_player_id_key;
+	CREATE UNIQUE INDEX IF NOT EXISTS idx_player_limits_player_source ON player_limits(player_id, source);
+
+	-- Limit Change History table (GLI-19 §2.5.5 - regulator audit of limit changes)
+	CREATE TABLE IF NOT EXISTS limit_change_history (
+		id UUID PRIMARY KEY,
+		player_id UUID NOT NULL REFERENCES players(id),
+		limit_type VARCHAR(50) NOT NULL,
+		old_value BIGINT NOT NULL DEFAULT 0,
+		new_value BIGINT NOT NULL DEFAULT 0,
+		source VARCHAR(50) NOT NULL,
+		effective_at TIMESTAMP NOT NULL,
+		changed_at TIMESTAMP NOT NULL
+	);
+
+	-- Self Exclusions table (GLI-19 §2.5.5.c)
+	CREATE TABLE IF NOT EXISTS self_exclusions (
+		id UUID PRIMARY KEY,
+		player_id UUID NOT NULL REFERENCES players(id),
+		reason TEXT NOT NULL,
+		started_at TIMESTAMP NOT NULL,
+		expires_at TIMESTAMP,
+		removed_at TIMESTAMP,
+		removed_by VARCHAR(255),
+		is_active BOOLEAN NOT NULL DEFAULT true,
+		created_at TIMESTAMP NOT NULL
+	);
+
+	-- System State table (GLI-19 §2.4)
+	CREATE TABLE IF NOT EXISTS system_state (
+		key VARCHAR(100) PRIMARY KEY,
+		value TEXT NOT NULL,
+		updated_at TIMESTAMP NOT NULL,
+		updated_by VARCHAR(255)
+	);
+
+	-- Disabled Games table (GLI-19 §2.4)
+	CREATE TABLE IF NOT EXISTS disabled_games (
+		game_id VARCHAR(255) PRIMARY KEY,
+		reason TEXT NOT NULL,
+		disabled_at TIMESTAMP NOT NULL,
+		disabled_by VARCHAR(255) NOT NULL
+	);
+
+	-- Indexes for performance
+	CREATE INDEX IF NOT EXISTS idx_sessions_player ON sessions(player_id);
+	CREATE INDEX IF NOT EXISTS idx_sessions_token ON sessions(token);
+	CREATE INDEX IF NOT EXISTS idx_transactions_player ON transactions(player_id);
+	CREATE INDEX IF NOT EXISTS idx_transactions_created ON transactions(created_at);
+	CREATE INDEX IF NOT EXISTS idx_game_sessions_player ON game_sessions(player_id);
+	CREATE INDEX IF NOT EXISTS idx_game_cycles_session ON game_cycles(session_id);
+	CREATE INDEX IF NOT EXISTS idx_game_cycles_player ON game_cycles(player_id);
+	CREATE INDEX IF NOT EXISTS idx_audit_events_timestamp ON audit_events(timestamp);
+	CREATE INDEX IF NOT EXISTS idx_audit_events_player ON audit_events(player_id);
+	CREATE UNIQUE INDEX IF NOT EXISTS idx_audit_events_seq ON audit_events(seq);
+	CREATE INDEX IF NOT EXISTS idx_player_limits_player ON player_limits(player_id);
+	CREATE INDEX IF NOT EXISTS idx_limit_change_history_player ON limit_change_history(player_id, changed_at);
+	CREATE INDEX IF NOT EXISTS idx_self_exclusions_player ON self_exclusions(player_id);
+	CREATE INDEX IF NOT EXISTS idx_self_exclusions_active ON self_exclusions(is_active);
+`
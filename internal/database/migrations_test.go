@@ -0,0 +1,179 @@
+package database_test
+
+import (
+	"testing"
+
+	"github.com/alexbotov/rgs/internal/database"
+	"github.com/alexbotov/rgs/internal/database/dbtest"
+	"github.com/google/uuid"
+)
+
+// setupEmptySchema connects to a fresh Postgres schema with no tables
+func setupEmptySchema(t *testing.T) *database.DB {
+	t.Helper()
+
+	admin, err := database.New("postgres", dbtest.DSN(t))
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	if _, err := admin.Exec("DROP SCHEMA IF EXISTS migration_test CASCADE; CREATE SCHEMA migration_test"); err != nil {
+		t.Fatalf("Failed to create schema: %v", err)
+	}
+
+	db, err := database.New("postgres", dbtest.DSN(t)+" search_path=migration_test")
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	t.Cleanup(func() {
+		db.Close()
+		admin.Exec("DROP SCHEMA IF EXISTS migration_test CASCADE")
+		admin.Close()
+	})
+	return db
+}
+
+func appliedVersions(t *testing.T, db *database.DB) []int {
+	t.Helper()
+	rows, err := db.Query("SELECT version FROM schema_migrations ORDER BY version")
+	if err != nil {
+		t.Fatalf("Failed to read schema_migrations: %v", err)
+	}
+	defer rows.Close()
+
+	var versions []int
+	for rows.Next() {
+		var v int
+		if err := rows.Scan(&v); err != nil {
+			t.Fatalf("Failed to scan version: %v", err)
+		}
+		versions = append(versions, v)
+	}
+	return versions
+}
+
+func TestMigrate(t *testing.T) {
+	db := setupEmptySchema(t)
+
+	// Empty database: every migration is applied
+	if err := db.Migrate(); err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+	if got := appliedVersions(t, db); len(got) != len(database.Migrations) {
+		t.Fatalf("Expected %d applied migrations, got %v", len(database.Migrations), got)
+	}
+	var n int
+	if err := db.QueryRow("SELECT COUNT(*) FROM players").Scan(&n); err != nil {
+		t.Fatalf("Expected players table: %v", err)
+	}
+
+	// Running again is a no-op
+	if err := db.Migrate(); err != nil {
+		t.Fatalf("Second Migrate failed: %v", err)
+	}
+	if got := appliedVersions(t, db); len(got) != len(database.Migrations) {
+		t.Errorf("Expected %d applied migrations after rerun, got %v", len(database.Migrations), got)
+	}
+
+	// A new migration is applied on top
+	next := database.Migrations[len(database.Migrations)-1].Version + 1
+	withNext := append(append([]database.Migration{}, database.Migrations...), database.Migration{
+		Version:     next,
+		Description: "Add player nickname",
+		SQL:         "ALTER TABLE players ADD COLUMN nickname VARCHAR(50)",
+	})
+	if err := db.MigrateTo(withNext); err != nil {
+		t.Fatalf("migrate failed: %v", err)
+	}
+	if got := appliedVersions(t, db); len(got) != len(withNext) || got[len(got)-1] != next {
+		t.Errorf("Expected version %d applied, got %v", next, got)
+	}
+	if _, err := db.Exec("UPDATE players SET nickname = 'x'"); err != nil {
+		t.Errorf("Expected nickname column: %v", err)
+	}
+
+	// Applied once: rerunning would fail on the existing column
+	if err := db.MigrateTo(withNext); err != nil {
+		t.Errorf("Rerun of applied migration failed: %v", err)
+	}
+}
+
+func TestMigrateRejectsOutOfOrder(t *testing.T) {
+	db := setupEmptySchema(t)
+
+	err := db.MigrateTo([]database.Migration{
+		{Version: 2, Description: "second", SQL: "SELECT 1"},
+		{Version: 1, Description: "first", SQL: "SELECT 1"},
+	})
+	if err == nil {
+		t.Error("Expected out of order migrations to be rejected")
+	}
+}
+
+func TestMigrateDuplicateReferences(t *testing.T) {
+	db := setupEmptySchema(t)
+
+	// Schema as it stood before references were unique
+	var before []database.Migration
+	for _, m := range database.Migrations {
+		if m.Version < 8 {
+			before = append(before, m)
+		}
+	}
+	if err := db.MigrateTo(before); err != nil {
+		t.Fatalf("migrate failed: %v", err)
+	}
+
+	playerID := uuid.New().String()
+	if _, err := db.Exec(`
+		INSERT INTO players (id, username, email, password_hash, status, registration_date, tc_accepted_at, created_at, updated_at)
+		VALUES ($1, 'dupuser', 'dup@example.com', 'hash', 'active', NOW(), NOW(), NOW(), NOW())
+	`, playerID); err != nil {
+		t.Fatalf("Failed to create player: %v", err)
+	}
+	insert := func(txType, reference string, age int) string {
+		id := uuid.New().String()
+		_, err := db.Exec(`
+			INSERT INTO transactions (id, player_id, type, amount, currency, balance_before, balance_after, status, reference, description, created_at)
+			VALUES ($1, $2, $3, 100, 'USD', 0, 100, 'completed', $4, '', NOW() - $5 * INTERVAL '1 minute')
+		`, id, playerID, txType, reference, age)
+		if err != nil {
+			t.Fatalf("Failed to create transaction: %v", err)
+		}
+		return id
+	}
+	first := insert("deposit", "dep-1", 3)
+	second := insert("deposit", "dep-1", 2)
+	third := insert("deposit", "dep-1", 1)
+	withdrawal := insert("withdrawal", "dep-1", 0)
+
+	if err := db.Migrate(); err != nil {
+		t.Fatalf("Migrate failed with duplicate references: %v", err)
+	}
+
+	reference := func(id string) string {
+		var ref string
+		if err := db.QueryRow("SELECT reference FROM transactions WHERE id = $1", id).Scan(&ref); err != nil {
+			t.Fatalf("Failed to read reference: %v", err)
+		}
+		return ref
+	}
+	if ref := reference(first); ref != "dep-1" {
+		t.Errorf("Expected the earliest deposit to keep its reference, got %q", ref)
+	}
+	if ref := reference(withdrawal); ref != "dep-1" {
+		t.Errorf("Expected the withdrawal to keep its reference, got %q", ref)
+	}
+	for _, id := range []string{second, third} {
+		if ref := reference(id); ref != "dep-1:"+id {
+			t.Errorf("Expected the duplicate renumbered to %q, got %q", "dep-1:"+id, ref)
+		}
+	}
+
+	// The index now rejects a reused reference
+	if _, err := db.Exec(`
+		INSERT INTO transactions (id, player_id, type, amount, currency, balance_before, balance_after, status, reference, description, created_at)
+		VALUES ($1, $2, 'deposit', 100, 'USD', 0, 100, 'completed', 'dep-1', '', NOW())
+	`, uuid.New().String(), playerID); err == nil {
+		t.Error("Expected a reused reference to be rejected")
+	}
+}
@@ -0,0 +1,38 @@
+// Package database - Running work in a single database transaction
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// WithTx runs fn in a database transaction, committing if it returns nil and
+// rolling back if it returns an error or panics. Services pass the same
+// transaction to the Tx variants of other services' methods so that work
+// spanning them commits or fails as one.
+func WithTx(ctx context.Context, db *sql.DB, fn func(*sql.Tx) error) error {
+	dbTx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			dbTx.Rollback()
+			panic(p)
+		}
+	}()
+
+	if err := fn(dbTx); err != nil {
+		dbTx.Rollback()
+		return err
+	}
+	return dbTx.Commit()
+}
+
+// WithTx runs fn in a transaction on this connection; see the package-level
+// WithTx
+func (db *DB) WithTx(ctx context.Context, fn func(*sql.Tx) error) error {
+	return WithTx(ctx, db.DB, fn)
+}
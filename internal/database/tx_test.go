@@ -0,0 +1,85 @@
+package database_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/alexbotov/rgs/internal/database/dbtest"
+	"github.com/google/uuid"
+)
+
+func TestMain(m *testing.M) {
+	dbtest.Main(m)
+}
+
+func TestWithTx(t *testing.T) {
+	db := dbtest.Open(t)
+	ctx := context.Background()
+
+	insertPlayer := func(dbTx *sql.Tx, id string) error {
+		_, err := dbTx.ExecContext(ctx, `
+			INSERT INTO players (id, username, email, password_hash, status, registration_date, tc_accepted_at, created_at, updated_at)
+			VALUES ($1, $1, $1, 'hash', 'active', NOW(), NOW(), NOW(), NOW())
+		`, id)
+		return err
+	}
+	exists := func(id string) bool {
+		var n int
+		if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM players WHERE id = $1", id).Scan(&n); err != nil {
+			t.Fatalf("Failed to count players: %v", err)
+		}
+		return n > 0
+	}
+
+	t.Run("commits on success", func(t *testing.T) {
+		id := uuid.New().String()
+		err := db.WithTx(ctx, func(dbTx *sql.Tx) error {
+			return insertPlayer(dbTx, id)
+		})
+		if err != nil {
+			t.Fatalf("WithTx failed: %v", err)
+		}
+		if !exists(id) {
+			t.Error("Expected the insert to be committed")
+		}
+	})
+
+	t.Run("rolls back on error", func(t *testing.T) {
+		id := uuid.New().String()
+		errFailed := errors.New("failed")
+		err := db.WithTx(ctx, func(dbTx *sql.Tx) error {
+			if err := insertPlayer(dbTx, id); err != nil {
+				return err
+			}
+			return errFailed
+		})
+		if !errors.Is(err, errFailed) {
+			t.Fatalf("Expected the returned error, got %v", err)
+		}
+		if exists(id) {
+			t.Error("Expected the insert to be rolled back")
+		}
+	})
+
+	t.Run("rolls back on panic", func(t *testing.T) {
+		id := uuid.New().String()
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Error("Expected the panic to propagate")
+				}
+			}()
+			db.WithTx(ctx, func(dbTx *sql.Tx) error {
+				if err := insertPlayer(dbTx, id); err != nil {
+					return err
+				}
+				panic("failed")
+			})
+		}()
+		if exists(id) {
+			t.Error("Expected the insert to be rolled back")
+		}
+	})
+}
@@ -10,36 +10,273 @@ package domain
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
 	"time"
 )
 
+var (
+	// ErrCurrencyMismatch is returned when combining money in different currencies
+	ErrCurrencyMismatch = errors.New("currency mismatch")
+
+	// ErrInvalidMoney is returned when parsing a malformed amount
+	ErrInvalidMoney = errors.New("invalid money amount")
+)
+
 // Money represents monetary values with precision (GLI-19 §2.5.6)
 type Money struct {
 	Amount   int64  `json:"amount"`   // Amount in smallest unit (cents)
 	Currency string `json:"currency"` // ISO 4217 currency code
 }
 
-// NewMoney creates a new Money value from dollars/major unit
+// currencyExponents lists ISO 4217 currencies whose minor unit is not
+// hundredths. All other currencies use two decimal places.
+var currencyExponents = map[string]int{
+	// Zero-decimal currencies
+	"CLP": 0, "ISK": 0, "JPY": 0, "KRW": 0, "UGX": 0, "VND": 0, "XAF": 0, "XOF": 0,
+	// Three-decimal currencies
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+}
+
+// CurrencyExponent returns the number of decimal places in a currency's minor
+// unit (2 for USD cents, 0 for JPY, 3 for BHD fils)
+func CurrencyExponent(currency string) int {
+	if exp, ok := currencyExponents[currency]; ok {
+		return exp
+	}
+	return 2
+}
+
+// minorUnits returns the number of minor units in one major unit
+func minorUnits(currency string) int64 {
+	units := int64(1)
+	for i := 0; i < CurrencyExponent(currency); i++ {
+		units *= 10
+	}
+	return units
+}
+
+// NewMoney creates a new Money value from the major unit (e.g. dollars),
+// rounding to the nearest minor unit of the currency
 func NewMoney(amount float64, currency string) Money {
 	return Money{
-		Amount:   int64(amount * 100),
+		Amount:   int64(math.Round(amount * float64(minorUnits(currency)))),
 		Currency: currency,
 	}
 }
 
-// Float64 returns the monetary value as a float
+// Float64 returns the monetary value in the major unit as a float
 func (m Money) Float64() float64 {
-	return float64(m.Amount) / 100.0
+	return float64(m.Amount) / float64(minorUnits(m.Currency))
+}
+
+// String formats the value with the currency's number of decimals, e.g.
+// "19.99 USD", "1999 JPY" or "1.250 BHD"
+func (m Money) String() string {
+	if m.Currency == "" {
+		return m.ToAPIString()
+	}
+	return m.ToAPIString() + " " + m.Currency
+}
+
+// ToAPIString formats the value as a decimal string in the major unit with
+// the currency's number of decimals, as exchanged with external wallet APIs
+// (e.g. "100.00")
+func (m Money) ToAPIString() string {
+	units := minorUnits(m.Currency)
+	amount := m.Amount
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	value := fmt.Sprintf("%s%d", sign, amount/units)
+	if exp := CurrencyExponent(m.Currency); exp > 0 {
+		value += fmt.Sprintf(".%0*d", exp, amount%units)
+	}
+	return value
+}
+
+// DefaultLocale is the locale amounts are formatted in for display when none
+// is configured
+const DefaultLocale = "en-US"
+
+// numberFormat is how a locale writes amounts of money
+type numberFormat struct {
+	group       string // Thousands separator; no-break space where a space is used
+	decimal     string // Decimal separator
+	symbolAfter bool   // Currency follows the amount, e.g. "12,50 €"
+}
+
+// localeFormats lists number formats by language. Languages not listed are
+// formatted as English.
+var localeFormats = map[string]numberFormat{
+	"en": {",", ".", false},
+	"ja": {",", ".", false},
+	"de": {".", ",", true},
+	"es": {".", ",", true},
+	"it": {".", ",", true},
+	"nl": {".", ",", true},
+	"pt": {".", ",", true},
+	"fr": {"\u00a0", ",", true},
+	"sv": {"\u00a0", ",", true},
+}
+
+// currencySymbols lists the display symbols of common currencies. Other
+// currencies are shown with their ISO 4217 code.
+var currencySymbols = map[string]string{
+	"USD": "$", "EUR": "€", "GBP": "£", "JPY": "¥", "KRW": "₩", "INR": "₹",
+}
+
+// Format formats the value for display to a player in the given locale
+// (e.g. "en-US", "de_DE"), with the currency's number of decimals, grouped
+// thousands and the currency symbol: "$1,234.56" in en-US, "1.234,56 €" in
+// de-DE and "¥1,235" for JPY.
+func (m Money) Format(locale string) string {
+	lang, _, _ := strings.Cut(strings.ToLower(locale), "-")
+	lang, _, _ = strings.Cut(lang, "_")
+	format, ok := localeFormats[lang]
+	if !ok {
+		format = localeFormats["en"]
+	}
+
+	value := m.ToAPIString()
+	sign := ""
+	if strings.HasPrefix(value, "-") {
+		sign = "-"
+		value = value[1:]
+	}
+	whole, frac, hasPoint := strings.Cut(value, ".")
+
+	var b strings.Builder
+	for i, c := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteString(format.group)
+		}
+		b.WriteRune(c)
+	}
+	if hasPoint {
+		b.WriteString(format.decimal)
+		b.WriteString(frac)
+	}
+	value = b.String()
+
+	symbol, ok := currencySymbols[m.Currency]
+	switch {
+	case m.Currency == "":
+		return sign + value
+	case format.symbolAfter && ok:
+		return sign + value + " " + symbol
+	case format.symbolAfter:
+		return sign + value + " " + m.Currency
+	case ok:
+		return sign + symbol + value
+	default:
+		return sign + m.Currency + " " + value
+	}
+}
+
+// ParseMoney parses a decimal string in the major unit (e.g. "100.00") into
+// Money without going through floating point. Digits beyond the currency's
+// decimal places must be zero.
+func ParseMoney(s, currency string) (Money, error) {
+	invalid := fmt.Errorf("%w: %q", ErrInvalidMoney, s)
+
+	value := s
+	negative := strings.HasPrefix(value, "-")
+	if negative {
+		value = value[1:]
+	}
+
+	whole, frac, hasPoint := strings.Cut(value, ".")
+	if whole == "" || (hasPoint && frac == "") || !isDigits(whole) || !isDigits(frac) {
+		return Money{}, invalid
+	}
+
+	exp := CurrencyExponent(currency)
+	if len(frac) > exp {
+		if strings.Trim(frac[exp:], "0") != "" {
+			return Money{}, invalid
+		}
+		frac = frac[:exp]
+	}
+	frac += strings.Repeat("0", exp-len(frac))
+
+	amount, err := strconv.ParseInt(whole+frac, 10, 64)
+	if err != nil {
+		return Money{}, invalid
+	}
+	if negative {
+		amount = -amount
+	}
+	return Money{Amount: amount, Currency: currency}, nil
 }
 
-// Add adds two money values
+// isDigits reports whether s contains only ASCII digits
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Add adds two money values. It panics if the currencies differ; use
+// AddChecked where the currencies are not known to match.
 func (m Money) Add(other Money) Money {
-	return Money{Amount: m.Amount + other.Amount, Currency: m.Currency}
+	result, err := m.AddChecked(other)
+	if err != nil {
+		panic(err)
+	}
+	return result
 }
 
-// Sub subtracts money value
+// Sub subtracts money value. It panics if the currencies differ; use
+// SubChecked where the currencies are not known to match.
 func (m Money) Sub(other Money) Money {
-	return Money{Amount: m.Amount - other.Amount, Currency: m.Currency}
+	result, err := m.SubChecked(other)
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+// AddChecked adds two money values, returning ErrCurrencyMismatch if the
+// currencies differ
+func (m Money) AddChecked(other Money) (Money, error) {
+	currency, err := m.commonCurrency(other)
+	if err != nil {
+		return Money{}, err
+	}
+	return Money{Amount: m.Amount + other.Amount, Currency: currency}, nil
+}
+
+// SubChecked subtracts money value, returning ErrCurrencyMismatch if the
+// currencies differ
+func (m Money) SubChecked(other Money) (Money, error) {
+	currency, err := m.commonCurrency(other)
+	if err != nil {
+		return Money{}, err
+	}
+	return Money{Amount: m.Amount - other.Amount, Currency: currency}, nil
+}
+
+// commonCurrency returns the currency of the result of combining two values.
+// A zero value with no currency is compatible with any currency.
+func (m Money) commonCurrency(other Money) (string, error) {
+	switch {
+	case m.Currency == "":
+		return other.Currency, nil
+	case other.Currency == "" || other.Currency == m.Currency:
+		return m.Currency, nil
+	default:
+		return "", fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.Currency, other.Currency)
+	}
 }
 
 // PlayerStatus represents the status of a player account (GLI-19 §2.5)
@@ -60,6 +297,7 @@ type Player struct {
 	Email            string       `json:"email" db:"email"`
 	PasswordHash     string       `json:"-" db:"password_hash"`
 	Status           PlayerStatus `json:"status" db:"status"`
+	Role             PlayerRole   `json:"role" db:"role"`
 	RegistrationDate time.Time    `json:"registration_date" db:"registration_date"`
 	LastLoginAt      *time.Time   `json:"last_login_at" db:"last_login_at"`
 	TCAcceptedAt     time.Time    `json:"tc_accepted_at" db:"tc_accepted_at"`
@@ -67,6 +305,14 @@ type Player struct {
 	UpdatedAt        time.Time    `json:"updated_at" db:"updated_at"`
 }
 
+// PlayerRole determines what an account may do through the API
+type PlayerRole string
+
+const (
+	PlayerRolePlayer PlayerRole = "player"
+	PlayerRoleAdmin  PlayerRole = "admin" // Operator staff: may use the admin endpoints
+)
+
 // SessionStatus represents session state (GLI-19 §2.5.3)
 type SessionStatus string
 
@@ -129,6 +375,14 @@ type Transaction struct {
 	Description   string            `json:"description" db:"description"`
 	CreatedAt     time.Time         `json:"created_at" db:"created_at"`
 	CompletedAt   *time.Time        `json:"completed_at" db:"completed_at"`
+
+	// IdempotencyKey identifies a request so a retry returns this transaction
+	// instead of moving money twice. Wagers and wins default to the cycle ID.
+	IdempotencyKey string `json:"idempotency_key,omitempty" db:"idempotency_key"`
+
+	// BonusAmount is the part of Amount taken from or credited to the bonus
+	// balance rather than real money, in minor units
+	BonusAmount int64 `json:"bonus_amount,omitempty" db:"bonus_amount"`
 }
 
 // GameSessionStatus represents game session state (GLI-19 §4.3)
@@ -154,6 +408,23 @@ type GameSession struct {
 	TotalWagered   Money             `json:"total_wagered" db:"total_wagered"`
 	TotalWon       Money             `json:"total_won" db:"total_won"`
 	GamesPlayed    int               `json:"games_played" db:"games_played"`
+	FeatureState   *FeatureState     `json:"feature_state,omitempty" db:"feature_state"`
+	RTPVariant     string            `json:"rtp_variant,omitempty" db:"rtp_variant"` // Fixed when the session starts
+}
+
+// FeatureState is bonus state carried between cycles of a game session,
+// such as free spins awarded by a scatter trigger
+type FeatureState struct {
+	FreeSpinsRemaining int   `json:"free_spins_remaining"`
+	FreeSpinsAwarded   int   `json:"free_spins_awarded"` // Total awarded, including retriggers
+	Multiplier         int   `json:"multiplier"`         // Applied to every free spin win
+	Wager              int64 `json:"wager"`              // Triggering stake in cents, played on each free spin
+	TotalWin           int64 `json:"total_win"`          // Accumulated feature win in cents
+}
+
+// Active returns true if free spins remain to be played
+func (f *FeatureState) Active() bool {
+	return f != nil && f.FreeSpinsRemaining > 0
 }
 
 // GameCycleStatus represents game cycle state (GLI-19 §4.3.3)
@@ -181,6 +452,14 @@ type GameCycle struct {
 	BalanceAfter  Money           `json:"balance_after" db:"balance_after"`
 	Outcome       json.RawMessage `json:"outcome" db:"outcome"`
 	Status        GameCycleStatus `json:"status" db:"status"`
+
+	// Pateplay round and transaction of a cycle settled through Pateplay
+	PateplayRoundID       string `json:"pateplay_round_id,omitempty" db:"pateplay_round_id"`
+	PateplayTransactionID string `json:"pateplay_transaction_id,omitempty" db:"pateplay_transaction_id"`
+
+	// DecodedOutcome is Outcome decoded into the game's typed outcome (a
+	// *game.SlotOutcome for slots), or nil when it cannot be decoded
+	DecodedOutcome interface{} `json:"decoded_outcome,omitempty" db:"-"`
 }
 
 // GameRecall provides game history for display (GLI-19 §4.14)
@@ -192,7 +471,15 @@ type GameRecall struct {
 	WinAmount     Money           `json:"win_amount"`
 	BalanceBefore Money           `json:"balance_before"`
 	BalanceAfter  Money           `json:"balance_after"`
-	Outcome       json.RawMessage `json:"outcome"`
+	Outcome       json.RawMessage `json:"outcome"` // As stored, for outcomes newer than this server
+
+	// Pateplay round and transaction of a cycle settled through Pateplay
+	PateplayRoundID       string `json:"pateplay_round_id,omitempty"`
+	PateplayTransactionID string `json:"pateplay_transaction_id,omitempty"`
+
+	// DecodedOutcome is Outcome decoded into the game's typed outcome (a
+	// *game.SlotOutcome for slots), or nil when it cannot be decoded
+	DecodedOutcome interface{} `json:"decoded_outcome,omitempty"`
 }
 
 // Game represents a game definition
@@ -204,6 +491,7 @@ type Game struct {
 	MinBet         Money   `json:"min_bet"`
 	MaxBet         Money   `json:"max_bet"`
 	Enabled        bool    `json:"enabled"`
+	RTPVariant     string  `json:"rtp_variant,omitempty"` // Certified RTP variant new sessions play
 }
 
 // EventSeverity represents audit event severity
@@ -230,6 +518,9 @@ type AuditEvent struct {
 	Data        json.RawMessage `json:"data,omitempty" db:"data"`
 	IPAddress   string          `json:"ip_address" db:"ip_address"`
 	Component   string          `json:"component" db:"component"`
+	RequestID   string          `json:"request_id,omitempty" db:"request_id"`
+	PrevHash    string          `json:"prev_hash,omitempty" db:"prev_hash"` // Hash of the event logged before it
+	Hash        string          `json:"hash,omitempty" db:"hash"`           // Hash of this event and PrevHash
 }
 
 // Balance represents player balance (GLI-19 §2.5.7)
@@ -240,6 +531,10 @@ type Balance struct {
 	Available    Money     `json:"available"`
 	Currency     string    `json:"currency"`
 	UpdatedAt    time.Time `json:"updated_at"`
+
+	// WageringRemaining is how much must still be wagered before the bonus
+	// balance converts to real money
+	WageringRemaining Money `json:"wagering_remaining"`
 }
 
 // LimitSource indicates who set the limit
@@ -269,8 +564,44 @@ type PlayerLimits struct {
 	SessionDuration *int64      `json:"session_duration_minutes,omitempty" db:"session_duration"` // in minutes
 	CoolingOffUntil *time.Time  `json:"cooling_off_until,omitempty" db:"cooling_off_until"`
 	Source          LimitSource `json:"source" db:"source"`
-	EffectiveAt     time.Time   `json:"effective_at" db:"effective_at"`
+	EffectiveAt     time.Time   `json:"effective_at" db:"effective_at"` // Last change to the values above, which are all in force
 	UpdatedAt       time.Time   `json:"updated_at" db:"updated_at"`
+
+	// Pending are loosened limits still in their cooling-off period. The
+	// values above stay in force until each takes effect.
+	Pending []PendingLimit `json:"pending,omitempty"`
+}
+
+// PendingLimit is a loosened player limit waiting out its cooling-off period
+// GLI-19 §2.5.5.b - Limit increases require waiting period
+type PendingLimit struct {
+	LimitType   string    `json:"limit_type"` // e.g. daily_deposit, session_duration
+	Amount      int64     `json:"amount"`     // cents (minutes for session_duration), 0 = no limit
+	EffectiveAt time.Time `json:"effective_at"`
+}
+
+// PendingFor returns the pending change to a limit type, or nil if none
+func (l *PlayerLimits) PendingFor(limitType string) *PendingLimit {
+	for i := range l.Pending {
+		if l.Pending[i].LimitType == limitType {
+			return &l.Pending[i]
+		}
+	}
+	return nil
+}
+
+// LimitChange records a single change to one of a player's limits
+// GLI-19 §2.5.5 - Limit changes must be recoverable for regulator audit
+type LimitChange struct {
+	ID          string      `json:"id" db:"id"`
+	PlayerID    string      `json:"player_id" db:"player_id"`
+	LimitType   string      `json:"limit_type" db:"limit_type"` // e.g. daily_deposit, session_duration
+	OldValue    int64       `json:"old_value" db:"old_value"`   // cents (minutes for session_duration), 0 = no limit
+	NewValue    int64       `json:"new_value" db:"new_value"`   // cents (minutes for session_duration), 0 = no limit
+	Source      LimitSource `json:"source" db:"source"`
+	EffectiveAt time.Time   `json:"effective_at" db:"effective_at"`
+	ChangedAt   time.Time   `json:"changed_at" db:"changed_at"`
+	ChangedBy   string      `json:"changed_by,omitempty" db:"changed_by"` // Player ID, or who imposed the limit
 }
 
 // SelfExclusion represents a player's self-exclusion record
@@ -312,6 +643,7 @@ type GamingSystemStatus struct {
 	DisabledAt        *time.Time `json:"disabled_at,omitempty"`
 	DisabledBy        string    `json:"disabled_by,omitempty"`
 	DisabledReason    string    `json:"disabled_reason,omitempty"`
+	DisabledGames     []string  `json:"disabled_games"`
 	ActiveSessions    int64     `json:"active_sessions"`
 	LastStateChange   time.Time `json:"last_state_change"`
 }
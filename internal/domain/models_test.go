@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"errors"
 	"testing"
 )
 
@@ -49,6 +50,161 @@ func TestMoney(t *testing.T) {
 			t.Errorf("Expected -200, got %d", result.Amount)
 		}
 	})
+
+	t.Run("CheckedSameCurrency", func(t *testing.T) {
+		sum, err := Money{Amount: 1000, Currency: "EUR"}.AddChecked(Money{Amount: 250, Currency: "EUR"})
+		if err != nil || sum.Amount != 1250 || sum.Currency != "EUR" {
+			t.Errorf("Expected 1250 EUR, got %d %s (%v)", sum.Amount, sum.Currency, err)
+		}
+		diff, err := Money{Amount: 1000, Currency: "EUR"}.SubChecked(Money{Amount: 250, Currency: "EUR"})
+		if err != nil || diff.Amount != 750 || diff.Currency != "EUR" {
+			t.Errorf("Expected 750 EUR, got %d %s (%v)", diff.Amount, diff.Currency, err)
+		}
+	})
+
+	t.Run("CheckedCurrencyMismatch", func(t *testing.T) {
+		if _, err := (Money{Amount: 100, Currency: "USD"}).AddChecked(Money{Amount: 100, Currency: "EUR"}); !errors.Is(err, ErrCurrencyMismatch) {
+			t.Errorf("Expected ErrCurrencyMismatch from AddChecked, got %v", err)
+		}
+		if _, err := (Money{Amount: 100, Currency: "USD"}).SubChecked(Money{Amount: 100, Currency: "EUR"}); !errors.Is(err, ErrCurrencyMismatch) {
+			t.Errorf("Expected ErrCurrencyMismatch from SubChecked, got %v", err)
+		}
+	})
+
+	t.Run("CheckedZeroValue", func(t *testing.T) {
+		sum, err := Money{}.AddChecked(Money{Amount: 500, Currency: "GBP"})
+		if err != nil || sum.Amount != 500 || sum.Currency != "GBP" {
+			t.Errorf("Expected 500 GBP from zero value, got %d %s (%v)", sum.Amount, sum.Currency, err)
+		}
+		diff, err := Money{Amount: 500, Currency: "GBP"}.SubChecked(Money{})
+		if err != nil || diff.Amount != 500 || diff.Currency != "GBP" {
+			t.Errorf("Expected 500 GBP, got %d %s (%v)", diff.Amount, diff.Currency, err)
+		}
+	})
+
+	t.Run("AddCurrencyMismatchPanics", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Error("Expected panic adding EUR to USD")
+			}
+		}()
+		Money{Amount: 100, Currency: "USD"}.Add(Money{Amount: 100, Currency: "EUR"})
+	})
+}
+
+func TestMoneyMinorUnits(t *testing.T) {
+	tests := []struct {
+		amount   float64
+		currency string
+		minor    int64
+		str      string
+	}{
+		{19.99, "USD", 1999, "19.99 USD"},
+		{0.29, "USD", 29, "0.29 USD"},
+		{4.35, "EUR", 435, "4.35 EUR"},
+		{1999, "JPY", 1999, "1999 JPY"},
+		{1999.6, "JPY", 2000, "2000 JPY"},
+		{1.234, "BHD", 1234, "1.234 BHD"},
+		{19.99, "BHD", 19990, "19.990 BHD"},
+		{-5.5, "USD", -550, "-5.50 USD"},
+	}
+
+	for _, tt := range tests {
+		m := NewMoney(tt.amount, tt.currency)
+		if m.Amount != tt.minor {
+			t.Errorf("NewMoney(%v, %s) = %d, expected %d", tt.amount, tt.currency, m.Amount, tt.minor)
+		}
+		if m.String() != tt.str {
+			t.Errorf("String() = %q, expected %q", m.String(), tt.str)
+		}
+	}
+
+	t.Run("Float64UsesExponent", func(t *testing.T) {
+		if f := (Money{Amount: 1999, Currency: "JPY"}).Float64(); f != 1999 {
+			t.Errorf("Expected 1999 JPY, got %f", f)
+		}
+		if f := (Money{Amount: 1234, Currency: "BHD"}).Float64(); f != 1.234 {
+			t.Errorf("Expected 1.234 BHD, got %f", f)
+		}
+	})
+}
+
+func TestParseMoney(t *testing.T) {
+	valid := []struct {
+		in       string
+		currency string
+		minor    int64
+		api      string
+	}{
+		{"0.01", "USD", 1, "0.01"},
+		{"1000.00", "USD", 100000, "1000.00"},
+		{"19.99", "USD", 1999, "19.99"},
+		{"5", "USD", 500, "5.00"},
+		{"5.5", "USD", 550, "5.50"},
+		{"-2.25", "USD", -225, "-2.25"},
+		{"1000.00", "JPY", 1000, "1000"},
+		{"1.234", "BHD", 1234, "1.234"},
+	}
+	for _, tt := range valid {
+		m, err := ParseMoney(tt.in, tt.currency)
+		if err != nil {
+			t.Errorf("ParseMoney(%q, %s) failed: %v", tt.in, tt.currency, err)
+			continue
+		}
+		if m.Amount != tt.minor || m.Currency != tt.currency {
+			t.Errorf("ParseMoney(%q, %s) = %d %s, expected %d", tt.in, tt.currency, m.Amount, m.Currency, tt.minor)
+		}
+		if m.ToAPIString() != tt.api {
+			t.Errorf("ToAPIString() = %q, expected %q", m.ToAPIString(), tt.api)
+		}
+	}
+
+	invalid := []string{"", "abc", "1.2.3", "1,000.00", "1e3", ".50", "10.", "+5.00", " 5.00", "0.001", "99999999999999999999"}
+	for _, in := range invalid {
+		if _, err := ParseMoney(in, "USD"); !errors.Is(err, ErrInvalidMoney) {
+			t.Errorf("ParseMoney(%q) expected ErrInvalidMoney, got %v", in, err)
+		}
+	}
+
+	t.Run("RoundTrip", func(t *testing.T) {
+		for _, amount := range []int64{0, 1, 99, 100, 123456789} {
+			m := Money{Amount: amount, Currency: "EUR"}
+			parsed, err := ParseMoney(m.ToAPIString(), "EUR")
+			if err != nil || parsed != m {
+				t.Errorf("Round trip of %d gave %v (%v)", amount, parsed, err)
+			}
+		}
+	})
+}
+
+func TestMoneyFormat(t *testing.T) {
+	tests := []struct {
+		amount   int64
+		currency string
+		locale   string
+		want     string
+	}{
+		{123456, "USD", "en-US", "$1,234.56"},
+		{5, "USD", "en-US", "$0.05"},
+		{100000000, "USD", "en-US", "$1,000,000.00"},
+		{-1999, "USD", "en-US", "-$19.99"},
+		{123456, "USD", "de-DE", "1.234,56 $"},
+		{1235, "JPY", "en-US", "¥1,235"},
+		{1234567, "JPY", "ja-JP", "¥1,234,567"},
+		{999, "JPY", "en-US", "¥999"},
+		{1235, "JPY", "de_DE", "1.235 ¥"},
+		{123456, "EUR", "fr-FR", "1\u00a0234,56 €"},
+		{1234567, "BHD", "en-US", "BHD 1,234.567"},
+		{123456, "USD", "", "$1,234.56"},
+		{123456, "USD", "xx", "$1,234.56"},
+	}
+
+	for _, tt := range tests {
+		m := Money{Amount: tt.amount, Currency: tt.currency}
+		if got := m.Format(tt.locale); got != tt.want {
+			t.Errorf("Format(%q) of %d %s = %q, expected %q", tt.locale, tt.amount, tt.currency, got, tt.want)
+		}
+	}
 }
 
 func TestPlayerStatus(t *testing.T) {
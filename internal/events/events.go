@@ -0,0 +1,99 @@
+// Package events provides an in-process publish/subscribe hub for player events
+// such as balance changes and limit warnings, so they can be pushed to the
+// player's open WebSocket connections
+package events
+
+import (
+	"sync"
+
+	"github.com/alexbotov/rgs/internal/domain"
+)
+
+// Type identifies an event; it is also the WebSocket message type
+type Type string
+
+const (
+	BalanceUpdate Type = "balance_update"
+	LimitWarning  Type = "limit_warning"
+	RealityCheck  Type = "reality_check"
+)
+
+// subscriberBuffer is how many events a slow subscriber may fall behind by
+// before further events are dropped
+const subscriberBuffer = 64
+
+// Event is something that happened to a player
+type Event struct {
+	Type     Type
+	PlayerID string
+	Balance  domain.Money           // BalanceUpdate: the player's available balance
+	Data     map[string]interface{} // Details sent to the client
+}
+
+// Hub delivers events to the subscribers of each player
+type Hub struct {
+	mu          sync.RWMutex
+	subscribers map[string]map[*Subscription]struct{}
+}
+
+// Subscription receives the events of one player until closed
+type Subscription struct {
+	C <-chan Event
+
+	ch       chan Event
+	hub      *Hub
+	playerID string
+	once     sync.Once
+}
+
+// New creates an empty hub
+func New() *Hub {
+	return &Hub{
+		subscribers: make(map[string]map[*Subscription]struct{}),
+	}
+}
+
+// Subscribe starts receiving events for a player
+func (h *Hub) Subscribe(playerID string) *Subscription {
+	ch := make(chan Event, subscriberBuffer)
+	sub := &Subscription{C: ch, ch: ch, hub: h, playerID: playerID}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.subscribers[playerID] == nil {
+		h.subscribers[playerID] = make(map[*Subscription]struct{})
+	}
+	h.subscribers[playerID][sub] = struct{}{}
+	return sub
+}
+
+// Close stops the subscription and closes its channel
+func (s *Subscription) Close() {
+	s.once.Do(func() {
+		s.hub.mu.Lock()
+		defer s.hub.mu.Unlock()
+		delete(s.hub.subscribers[s.playerID], s)
+		if len(s.hub.subscribers[s.playerID]) == 0 {
+			delete(s.hub.subscribers, s.playerID)
+		}
+		close(s.ch)
+	})
+}
+
+// Publish delivers an event to the player's subscribers without blocking; a
+// subscriber whose buffer is full misses the event. Publishing on a nil hub
+// does nothing, so services work without one.
+func (h *Hub) Publish(event Event) {
+	if h == nil {
+		return
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for sub := range h.subscribers[event.PlayerID] {
+		select {
+		case sub.ch <- event:
+		default:
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/alexbotov/rgs/internal/domain"
+)
+
+func TestHub(t *testing.T) {
+	hub := New()
+
+	t.Run("DeliversToPlayerSubscribers", func(t *testing.T) {
+		first := hub.Subscribe("player-1")
+		defer first.Close()
+		second := hub.Subscribe("player-1")
+		defer second.Close()
+		other := hub.Subscribe("player-2")
+		defer other.Close()
+
+		hub.Publish(Event{Type: BalanceUpdate, PlayerID: "player-1", Balance: domain.Money{Amount: 500, Currency: "USD"}})
+
+		for i, sub := range []*Subscription{first, second} {
+			select {
+			case event := <-sub.C:
+				if event.Balance.Amount != 500 {
+					t.Errorf("Subscriber %d: expected balance 500, got %d", i+1, event.Balance.Amount)
+				}
+			default:
+				t.Errorf("Subscriber %d: expected an event", i+1)
+			}
+		}
+		if len(other.C) != 0 {
+			t.Error("Another player's subscriber should not receive the event")
+		}
+	})
+
+	t.Run("CloseStopsDelivery", func(t *testing.T) {
+		sub := hub.Subscribe("player-3")
+		sub.Close()
+		sub.Close() // Closing twice is harmless
+
+		hub.Publish(Event{Type: LimitWarning, PlayerID: "player-3"})
+
+		if _, ok := <-sub.C; ok {
+			t.Error("Expected closed channel")
+		}
+	})
+
+	t.Run("FullSubscriberDoesNotBlock", func(t *testing.T) {
+		sub := hub.Subscribe("player-4")
+		defer sub.Close()
+
+		for i := 0; i < subscriberBuffer+10; i++ {
+			hub.Publish(Event{Type: BalanceUpdate, PlayerID: "player-4"})
+		}
+		if len(sub.C) != subscriberBuffer {
+			t.Errorf("Expected %d buffered events, got %d", subscriberBuffer, len(sub.C))
+		}
+	})
+
+	t.Run("NilHub", func(t *testing.T) {
+		var nilHub *Hub
+		nilHub.Publish(Event{Type: BalanceUpdate, PlayerID: "player-1"})
+	})
+}
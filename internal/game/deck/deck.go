@@ -0,0 +1,195 @@
+// Package deck provides a standard deck of playing cards for table games,
+// shuffled with the RGS random number generator
+// Compliant with GLI-19 §3.2.1 and §4.5.2
+package deck
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/alexbotov/rgs/internal/rng"
+)
+
+var (
+	ErrNotEnoughCards = errors.New("not enough cards left in the deck")
+	ErrInvalidDeal    = errors.New("deal must be at least one card")
+)
+
+// Suit of a playing card
+type Suit string
+
+const (
+	Clubs    Suit = "C"
+	Diamonds Suit = "D"
+	Hearts   Suit = "H"
+	Spades   Suit = "S"
+)
+
+// Suits in the order a new deck is sorted
+var Suits = []Suit{Clubs, Diamonds, Hearts, Spades}
+
+// Rank of a playing card, from Two up to Ace
+type Rank int
+
+const (
+	Two Rank = iota + 2
+	Three
+	Four
+	Five
+	Six
+	Seven
+	Eight
+	Nine
+	Ten
+	Jack
+	Queen
+	King
+	Ace
+)
+
+// String returns the rank as it is written on a card
+func (r Rank) String() string {
+	switch r {
+	case Ten:
+		return "T"
+	case Jack:
+		return "J"
+	case Queen:
+		return "Q"
+	case King:
+		return "K"
+	case Ace:
+		return "A"
+	}
+	return fmt.Sprintf("%d", int(r))
+}
+
+// Card is a single playing card
+type Card struct {
+	Rank Rank `json:"rank"`
+	Suit Suit `json:"suit"`
+}
+
+// String returns the card in short form, e.g. "AS" or "7H"
+func (c Card) String() string {
+	return c.Rank.String() + string(c.Suit)
+}
+
+// Size is the number of cards in a full deck
+const Size = 52
+
+// Deck is a 52-card deck dealt from the top
+type Deck struct {
+	cards []Card
+}
+
+// NewDeck creates a full, sorted deck
+func NewDeck() *Deck {
+	cards := make([]Card, 0, Size)
+	for _, suit := range Suits {
+		for rank := Two; rank <= Ace; rank++ {
+			cards = append(cards, Card{Rank: rank, Suit: suit})
+		}
+	}
+	return &Deck{cards: cards}
+}
+
+// Shuffle puts the cards left in the deck in a random order with a
+// Fisher-Yates shuffle. Each swap index is drawn with rejection sampling, so
+// every ordering is equally likely.
+// GLI-19 §3.2.1: Shuffling algorithms
+func (d *Deck) Shuffle(src *rng.Service) error {
+	for i := len(d.cards) - 1; i > 0; i-- {
+		j, err := src.GenerateInt(int64(i + 1))
+		if err != nil {
+			return fmt.Errorf("failed to shuffle deck: %w", err)
+		}
+		d.cards[i], d.cards[j] = d.cards[j], d.cards[i]
+	}
+	return nil
+}
+
+// Deal removes n cards from the top of the deck and returns them
+func (d *Deck) Deal(n int) ([]Card, error) {
+	if n < 1 {
+		return nil, ErrInvalidDeal
+	}
+	if n > len(d.cards) {
+		return nil, ErrNotEnoughCards
+	}
+	dealt := append([]Card{}, d.cards[:n]...)
+	d.cards = d.cards[n:]
+	return dealt, nil
+}
+
+// Remaining returns the number of cards left to deal
+func (d *Deck) Remaining() int {
+	return len(d.cards)
+}
+
+// Cards returns the cards left in the deck, top first
+func (d *Deck) Cards() []Card {
+	return append([]Card{}, d.cards...)
+}
+
+// seedSize is the number of bytes in a shuffle seed
+const seedSize = 32
+
+// Commitment is a provably fair shuffle: the hash of the seed is published
+// before play, and the seed is revealed afterwards so the player can check
+// both that it matches the hash and that it produces the dealt order
+// GLI-19 §4.6: Game Fairness
+type Commitment struct {
+	Seed []byte // Kept secret until the round is over
+	Hash string // Hex SHA-256 of Seed, published before play
+}
+
+// NewCommitment draws a shuffle seed from src and commits to it
+func NewCommitment(src *rng.Service) (*Commitment, error) {
+	seed, err := src.GenerateBytes(seedSize)
+	if err != nil {
+		return nil, err
+	}
+	return &Commitment{Seed: seed, Hash: commitHash(seed)}, nil
+}
+
+// Shuffle shuffles d with the committed seed
+func (c *Commitment) Shuffle(d *Deck) error {
+	return d.Shuffle(rng.NewSeeded(c.Seed))
+}
+
+// Reveal returns the seed in hex, for publishing once the round is over
+func (c *Commitment) Reveal() string {
+	return hex.EncodeToString(c.Seed)
+}
+
+// VerifyReveal checks that a revealed hex seed matches the published hash
+func VerifyReveal(hash, revealed string) bool {
+	seed, err := hex.DecodeString(revealed)
+	if err != nil {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(commitHash(seed)), []byte(hash)) == 1
+}
+
+// ShuffleRevealed rebuilds the deck a revealed seed produced, so the dealt
+// order can be checked
+func ShuffleRevealed(revealed string) (*Deck, error) {
+	seed, err := hex.DecodeString(revealed)
+	if err != nil {
+		return nil, fmt.Errorf("invalid revealed seed: %w", err)
+	}
+	d := NewDeck()
+	if err := d.Shuffle(rng.NewSeeded(seed)); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+func commitHash(seed []byte) string {
+	sum := sha256.Sum256(seed)
+	return hex.EncodeToString(sum[:])
+}
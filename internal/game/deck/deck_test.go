@@ -0,0 +1,161 @@
+package deck
+
+import (
+	"testing"
+
+	"github.com/alexbotov/rgs/internal/rng"
+)
+
+func TestNewDeck(t *testing.T) {
+	d := NewDeck()
+	if d.Remaining() != Size {
+		t.Fatalf("Expected %d cards, got %d", Size, d.Remaining())
+	}
+
+	seen := make(map[Card]bool)
+	for _, c := range d.Cards() {
+		if seen[c] {
+			t.Errorf("Duplicate card %s", c)
+		}
+		seen[c] = true
+	}
+	if c := d.Cards()[Size-1]; c.String() != "AS" {
+		t.Errorf("Expected sorted deck to end with AS, got %s", c)
+	}
+}
+
+func TestShuffle(t *testing.T) {
+	s := rng.New()
+
+	t.Run("KeepsAllCards", func(t *testing.T) {
+		d := NewDeck()
+		if err := d.Shuffle(s); err != nil {
+			t.Fatalf("Failed to shuffle: %v", err)
+		}
+		if d.Remaining() != Size {
+			t.Fatalf("Expected %d cards after shuffle, got %d", Size, d.Remaining())
+		}
+
+		seen := make(map[Card]bool)
+		for _, c := range d.Cards() {
+			seen[c] = true
+		}
+		for _, c := range NewDeck().Cards() {
+			if !seen[c] {
+				t.Errorf("Card %s missing after shuffle", c)
+			}
+		}
+	})
+
+	t.Run("ChangesOrder", func(t *testing.T) {
+		// Getting the sorted order back is a 1 in 52! chance
+		d := NewDeck()
+		if err := d.Shuffle(s); err != nil {
+			t.Fatalf("Failed to shuffle: %v", err)
+		}
+		sorted := NewDeck().Cards()
+		same := true
+		for i, c := range d.Cards() {
+			if c != sorted[i] {
+				same = false
+				break
+			}
+		}
+		if same {
+			t.Error("Expected shuffle to change the order")
+		}
+	})
+
+	t.Run("EachCardReachesTop", func(t *testing.T) {
+		counts := make(map[Card]int)
+		for i := 0; i < 5200; i++ {
+			d := NewDeck()
+			if err := d.Shuffle(s); err != nil {
+				t.Fatalf("Failed to shuffle: %v", err)
+			}
+			counts[d.Cards()[0]]++
+		}
+		// Expected 100 each; 30 is far outside any fair variation
+		for _, c := range NewDeck().Cards() {
+			if counts[c] < 30 {
+				t.Errorf("Card %s reached the top %d times in 5200 shuffles", c, counts[c])
+			}
+		}
+	})
+}
+
+func TestDeal(t *testing.T) {
+	d := NewDeck()
+	if err := d.Shuffle(rng.New()); err != nil {
+		t.Fatalf("Failed to shuffle: %v", err)
+	}
+
+	seen := make(map[Card]bool)
+	for d.Remaining() > 0 {
+		hand, err := d.Deal(5)
+		if err == ErrNotEnoughCards {
+			hand, err = d.Deal(d.Remaining())
+		}
+		if err != nil {
+			t.Fatalf("Failed to deal: %v", err)
+		}
+		for _, c := range hand {
+			if seen[c] {
+				t.Errorf("Card %s dealt twice", c)
+			}
+			seen[c] = true
+		}
+	}
+	if len(seen) != Size {
+		t.Errorf("Expected %d cards dealt, got %d", Size, len(seen))
+	}
+
+	if _, err := d.Deal(1); err != ErrNotEnoughCards {
+		t.Errorf("Expected ErrNotEnoughCards from empty deck, got %v", err)
+	}
+	if _, err := NewDeck().Deal(0); err != ErrInvalidDeal {
+		t.Errorf("Expected ErrInvalidDeal, got %v", err)
+	}
+}
+
+func TestCommitment(t *testing.T) {
+	commit, err := NewCommitment(rng.New())
+	if err != nil {
+		t.Fatalf("Failed to commit: %v", err)
+	}
+
+	d := NewDeck()
+	if err := commit.Shuffle(d); err != nil {
+		t.Fatalf("Failed to shuffle: %v", err)
+	}
+	dealt, _ := d.Deal(10)
+
+	t.Run("RevealVerifies", func(t *testing.T) {
+		if !VerifyReveal(commit.Hash, commit.Reveal()) {
+			t.Error("Expected revealed seed to match the commitment")
+		}
+	})
+
+	t.Run("RevealReproducesDeal", func(t *testing.T) {
+		replayed, err := ShuffleRevealed(commit.Reveal())
+		if err != nil {
+			t.Fatalf("Failed to rebuild deck: %v", err)
+		}
+		hand, _ := replayed.Deal(10)
+		for i := range dealt {
+			if hand[i] != dealt[i] {
+				t.Fatalf("Expected revealed seed to deal %v, got %v", dealt, hand)
+			}
+		}
+	})
+
+	t.Run("WrongSeedRejected", func(t *testing.T) {
+		other, _ := NewCommitment(rng.New())
+		if VerifyReveal(commit.Hash, other.Reveal()) {
+			t.Error("Expected a different seed not to match the commitment")
+		}
+		if VerifyReveal(commit.Hash, "not-hex") {
+			t.Error("Expected malformed seed not to match the commitment")
+		}
+	})
+}
@@ -0,0 +1,240 @@
+// Package game - Game definitions and registration
+// Compliant with GLI-19 §4.4, §4.7
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/alexbotov/rgs/internal/domain"
+)
+
+var ErrInvalidDefinition = errors.New("invalid game definition")
+
+// MinTheoreticalRTP is the lowest RTP a game may declare
+// GLI-19 §4.7.1: Minimum 75% RTP
+const MinTheoreticalRTP = 0.75
+
+// GameDefinition describes a game and the math that drives it
+// GLI-19 §4.4.1: Paytable information, §4.5.2: Game Selection Process
+type GameDefinition struct {
+	ID             string           `json:"id"`
+	Name           string           `json:"name"`
+	Type           string           `json:"type"` // slots
+	TheoreticalRTP float64          `json:"theoretical_rtp"`
+	MinBet         int64            `json:"min_bet"`            // In cents
+	MaxBet         int64            `json:"max_bet"`            // In cents
+	Reels          [][]Symbol       `json:"reels"`              // Reel strips, one per reel
+	Paytable       map[string]int64 `json:"paytable"`           // Payout in cents per unit bet, per line
+	Rows           int              `json:"rows,omitempty"`     // Visible rows per reel, defaults to 1
+	Paylines       [][]int          `json:"paylines,omitempty"` // Row index on each reel, per line
+	FreeSpins      *FreeSpinsConfig `json:"free_spins,omitempty"`
+	Jackpot        *JackpotConfig   `json:"jackpot,omitempty"`
+
+	// Alternative certified reel and paytable sets, by name
+	Variants map[string]RTPVariant `json:"variants,omitempty"`
+}
+
+// FreeSpinsConfig describes a scatter-triggered free spins feature
+// GLI-19 §4.4.1: Paytable information - bonus features must be described
+type FreeSpinsConfig struct {
+	Symbol     Symbol `json:"symbol"`     // Scatter symbol, counted anywhere on the grid
+	Trigger    int    `json:"trigger"`    // Scatters needed to award the feature
+	Spins      int    `json:"spins"`      // Free spins awarded per trigger
+	Multiplier int    `json:"multiplier"` // Applied to wins during free spins
+}
+
+// JackpotConfig describes a progressive jackpot fed by every paid wager
+// GLI-19 §4.4.1: Paytable information - jackpot contributions must be described
+type JackpotConfig struct {
+	ContributionRate   float64 `json:"contribution_rate"`   // Fraction of each wager added to the pool
+	TriggerProbability float64 `json:"trigger_probability"` // Chance a paid cycle wins the pool
+	Seed               int64   `json:"seed"`                // Pool after a win, in cents
+}
+
+// rows returns the number of visible rows, defaulting to a single row
+func (d *GameDefinition) rows() int {
+	if d.Rows <= 0 {
+		return 1
+	}
+	return d.Rows
+}
+
+// paylines returns the configured paylines, defaulting to the center row
+// GLI-19 §4.4.1: Paytable information
+func (d *GameDefinition) paylines() [][]int {
+	if len(d.Paylines) > 0 {
+		return d.Paylines
+	}
+	center := make([]int, len(d.Reels))
+	for i := range center {
+		center[i] = d.rows() / 2
+	}
+	return [][]int{center}
+}
+
+// DefaultGameDefinitions returns the built-in games used when no definitions
+// are loaded
+func DefaultGameDefinitions() []GameDefinition {
+	return []GameDefinition{
+		{
+			// Fortune Slots - A simple 3-reel slot game
+			ID:             "fortune-slots",
+			Name:           "Fortune Slots",
+			Type:           "slots",
+			TheoreticalRTP: 0.96,  // 96% RTP
+			MinBet:         10,    // $0.10
+			MaxBet:         10000, // $100.00
+			Reels:          fortuneSlotsReels,
+			Paytable:       fortuneSlotsPaytable,
+		},
+		{
+			// Lucky Sevens - A classic fruit machine
+			ID:             "lucky-sevens",
+			Name:           "Lucky Sevens",
+			Type:           "slots",
+			TheoreticalRTP: 0.94, // 94% RTP
+			MinBet:         25,   // $0.25
+			MaxBet:         5000, // $50.00
+			Reels:          luckySevensReels,
+			Paytable:       luckySevensPaytable,
+		},
+	}
+}
+
+// LoadGameDefinitions reads game definitions from a JSON file
+func LoadGameDefinitions(path string) ([]GameDefinition, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read game definitions: %w", err)
+	}
+
+	var defs []GameDefinition
+	if err := json.Unmarshal(data, &defs); err != nil {
+		return nil, fmt.Errorf("failed to parse game definitions: %w", err)
+	}
+
+	return defs, nil
+}
+
+// LoadGames replaces the registered games with the given definitions.
+// An empty list keeps the currently registered games.
+func (e *Engine) LoadGames(defs []GameDefinition) error {
+	if len(defs) == 0 {
+		return nil
+	}
+
+	// Validate everything before touching the registry
+	for i := range defs {
+		if err := defs[i].Validate(); err != nil {
+			return err
+		}
+	}
+
+	e.games = make(map[string]*domain.Game)
+	e.definitions = make(map[string]*GameDefinition)
+	for _, def := range defs {
+		if err := e.RegisterGame(def.game(e.currency), def); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RegisterGame adds a game with its reels and paytable to the engine,
+// replacing any game with the same ID. Games are registered at startup.
+func (e *Engine) RegisterGame(g *domain.Game, def GameDefinition) error {
+	if g == nil || g.ID == "" || g.ID != def.ID {
+		return fmt.Errorf("%w: game and definition IDs must match", ErrInvalidDefinition)
+	}
+	if err := def.Validate(); err != nil {
+		return err
+	}
+
+	e.games[g.ID] = g
+	e.definitions[g.ID] = &def
+	return nil
+}
+
+// Validate checks that the definition describes a playable game
+func (d *GameDefinition) Validate() error {
+	if d.ID == "" {
+		return fmt.Errorf("%w: missing id", ErrInvalidDefinition)
+	}
+	if d.TheoreticalRTP < MinTheoreticalRTP || d.TheoreticalRTP > 1 {
+		return fmt.Errorf("%w: %s RTP %.4f outside [%.2f, 1]", ErrInvalidDefinition, d.ID, d.TheoreticalRTP, MinTheoreticalRTP)
+	}
+	if d.MinBet <= 0 || d.MaxBet < d.MinBet {
+		return fmt.Errorf("%w: %s bet range %d-%d", ErrInvalidDefinition, d.ID, d.MinBet, d.MaxBet)
+	}
+	if len(d.Reels) < 3 {
+		return fmt.Errorf("%w: %s needs at least 3 reels", ErrInvalidDefinition, d.ID)
+	}
+	for i, reel := range d.Reels {
+		if len(reel) == 0 {
+			return fmt.Errorf("%w: %s reel %d is empty", ErrInvalidDefinition, d.ID, i+1)
+		}
+		if len(reel) < d.rows() {
+			return fmt.Errorf("%w: %s reel %d is shorter than %d rows", ErrInvalidDefinition, d.ID, i+1, d.rows())
+		}
+	}
+	for i, line := range d.Paylines {
+		if len(line) != len(d.Reels) {
+			return fmt.Errorf("%w: %s payline %d must cover %d reels", ErrInvalidDefinition, d.ID, i+1, len(d.Reels))
+		}
+		for _, row := range line {
+			if row < 0 || row >= d.rows() {
+				return fmt.Errorf("%w: %s payline %d row %d out of range", ErrInvalidDefinition, d.ID, i+1, row)
+			}
+		}
+	}
+	if len(d.Paytable) == 0 {
+		return fmt.Errorf("%w: %s has no paytable", ErrInvalidDefinition, d.ID)
+	}
+	if fs := d.FreeSpins; fs != nil {
+		if fs.Symbol == "" || fs.Trigger <= 0 || fs.Spins <= 0 || fs.Multiplier <= 0 {
+			return fmt.Errorf("%w: %s free spins feature is incomplete", ErrInvalidDefinition, d.ID)
+		}
+	}
+	if jp := d.Jackpot; jp != nil {
+		if jp.ContributionRate <= 0 || jp.ContributionRate >= 1 {
+			return fmt.Errorf("%w: %s jackpot contribution rate %.4f outside (0, 1)", ErrInvalidDefinition, d.ID, jp.ContributionRate)
+		}
+		if jp.TriggerProbability <= 0 || jp.TriggerProbability > 1 {
+			return fmt.Errorf("%w: %s jackpot trigger probability %g outside (0, 1]", ErrInvalidDefinition, d.ID, jp.TriggerProbability)
+		}
+		if jp.Seed < 0 {
+			return fmt.Errorf("%w: %s jackpot seed is negative", ErrInvalidDefinition, d.ID)
+		}
+	}
+	for name := range d.Variants {
+		if name == "" || name == DefaultRTPVariant {
+			return fmt.Errorf("%w: %s has a variant named %q", ErrInvalidDefinition, d.ID, name)
+		}
+		v, _ := d.variant(name)
+		if err := v.Validate(); err != nil {
+			return fmt.Errorf("%s variant %s: %w", d.ID, name, err)
+		}
+	}
+	return nil
+}
+
+// game builds the domain game described by the definition
+func (d *GameDefinition) game(currency string) *domain.Game {
+	gameType := d.Type
+	if gameType == "" {
+		gameType = "slots"
+	}
+	return &domain.Game{
+		ID:             d.ID,
+		Name:           d.Name,
+		Type:           gameType,
+		TheoreticalRTP: d.TheoreticalRTP,
+		MinBet:         domain.Money{Amount: d.MinBet, Currency: currency},
+		MaxBet:         domain.Money{Amount: d.MaxBet, Currency: currency},
+		Enabled:        true,
+	}
+}
@@ -0,0 +1,51 @@
+// Package game - Draining in-flight game cycles for shutdown
+package game
+
+import (
+	"context"
+	"errors"
+)
+
+var ErrShuttingDown = errors.New("game server is shutting down")
+
+// beginCycle registers a game cycle that must complete before shutdown. It
+// fails once Drain has been called; the returned function marks the cycle done.
+func (e *Engine) beginCycle() (func(), error) {
+	e.drainMu.Lock()
+	defer e.drainMu.Unlock()
+
+	if e.draining {
+		return nil, ErrShuttingDown
+	}
+	e.inflight.Add(1)
+	return e.inflight.Done, nil
+}
+
+// isDraining reports whether Drain has been called
+func (e *Engine) isDraining() bool {
+	e.drainMu.Lock()
+	defer e.drainMu.Unlock()
+	return e.draining
+}
+
+// Drain stops new sessions and game cycles and waits for cycles already in
+// progress to complete, so a shutdown never cuts a round in half
+// (GLI-19 §4.16). It returns the context's error if the wait times out.
+func (e *Engine) Drain(ctx context.Context) error {
+	e.drainMu.Lock()
+	e.draining = true
+	e.drainMu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		e.inflight.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
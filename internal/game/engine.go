@@ -3,27 +3,56 @@
 package game
 
 import (
+	"bytes"
 	"context"
 	"database/sql"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"log"
+	"sync"
 	"time"
 
 	"github.com/alexbotov/rgs/internal/audit"
+	"github.com/alexbotov/rgs/internal/control"
+	"github.com/alexbotov/rgs/internal/database"
 	"github.com/alexbotov/rgs/internal/domain"
+	"github.com/alexbotov/rgs/internal/events"
+	"github.com/alexbotov/rgs/internal/limits"
+	"github.com/alexbotov/rgs/internal/realitycheck"
 	"github.com/alexbotov/rgs/internal/rng"
 	"github.com/alexbotov/rgs/internal/wallet"
+	"github.com/alexbotov/rgs/pkg/pateplay"
 	"github.com/google/uuid"
 )
 
 var (
-	ErrGameNotFound        = errors.New("game not found")
-	ErrGameDisabled        = errors.New("game is disabled")
-	ErrSessionNotFound     = errors.New("game session not found")
-	ErrSessionNotActive    = errors.New("game session is not active")
-	ErrInsufficientBalance = errors.New("insufficient balance")
-	ErrInvalidWager        = errors.New("invalid wager amount")
+	ErrGameNotFound         = errors.New("game not found")
+	ErrGameDisabled         = errors.New("game is disabled")
+	ErrSessionNotFound      = errors.New("game session not found")
+	ErrSessionNotActive     = errors.New("game session is not active")
+	ErrInsufficientBalance  = errors.New("insufficient balance")
+	ErrInvalidWager         = errors.New("invalid wager amount")
+	ErrCycleNotFound        = errors.New("game cycle not found")
+	ErrCycleNotInterrupted  = errors.New("interrupted game not found or already resolved")
+	ErrNoReplaySeed         = errors.New("game cycle has no recorded RNG seed")
+	ErrNoRNGTrace           = errors.New("game cycle has no recorded RNG trace")
+	ErrReplayMismatch       = errors.New("replayed outcome does not match recorded outcome")
+	ErrWagerLimitExceeded   = errors.New("wager limit exceeded")
+	ErrLossLimitExceeded    = errors.New("loss limit exceeded")
+	ErrTimeLimitExceeded    = errors.New("play time limit reached")
+	ErrPlayerExcluded       = errors.New("player is self-excluded")
+	ErrSessionAlreadyActive = errors.New("player already has an active session for this game")
+)
+
+// SessionConflictPolicy decides what StartSession does when the player already
+// has an active session for the game
+type SessionConflictPolicy string
+
+const (
+	SessionConflictReject   SessionConflictPolicy = "reject"    // Refuse the new session
+	SessionConflictEndStale SessionConflictPolicy = "end_stale" // End the old session and start a new one
 )
 
 // Engine provides game execution functionality
@@ -31,21 +60,72 @@ var (
 type Engine struct {
 	db       *sql.DB
 	rng      *rng.Service
-	wallet   *wallet.Service
+	wallet   wallet.Wallet
+	limits   *limits.Service
 	audit    *audit.Service
 	games    map[string]*domain.Game
 	currency string
+
+	// Reels and paytables per game ID
+	definitions map[string]*GameDefinition
+
+	// recordSeeds derives each outcome from a recorded seed for replay
+	recordSeeds bool
+
+	// certificationMode records every RNG draw of a cycle with the cycle
+	certificationMode bool
+
+	// largeWin decides which wins are audited as large wins
+	largeWin LargeWinThreshold
+
+	// maxGambles is how many times in a row a win may be gambled
+	maxGambles int
+
+	// RTP variant new sessions are played on, per game ID
+	variantMu      sync.RWMutex
+	activeVariants map[string]string
+
+	// sessionConflict handles a second session for the same player and game
+	sessionConflict SessionConflictPolicy
+
+	// control enforces operator shutdowns and player suspensions; optional
+	control *control.Service
+
+	// realityCheck tracks play time and holds play for reality checks; optional
+	realityCheck *realitycheck.Service
+
+	// events receives limit warnings for the player's open connections
+	events *events.Hub
+
+	// pateplay cancels the Pateplay transaction of a voided cycle; optional
+	pateplay *pateplay.Client
+
+	// settlement moves the wager and win of each single-round cycle
+	settlement SettlementBackend
+
+	// Cycles in progress, waited for by Drain
+	drainMu  sync.Mutex
+	draining bool
+	inflight sync.WaitGroup
 }
 
 // New creates a new game engine
-func New(db *sql.DB, rngSvc *rng.Service, walletSvc *wallet.Service, auditSvc *audit.Service, currency string) *Engine {
+func New(db *sql.DB, rngSvc *rng.Service, walletSvc wallet.Wallet, limitsSvc *limits.Service, auditSvc *audit.Service, currency string) *Engine {
 	engine := &Engine{
 		db:       db,
 		rng:      rngSvc,
 		wallet:   walletSvc,
+		limits:   limitsSvc,
 		audit:    auditSvc,
 		games:    make(map[string]*domain.Game),
 		currency: currency,
+
+		definitions:     make(map[string]*GameDefinition),
+		sessionConflict: SessionConflictReject,
+		largeWin:        LargeWinThreshold{Amount: 10000}, // $100+ wins
+		maxGambles:      DefaultMaxGambles,
+		activeVariants:  make(map[string]string),
+		settlement:      NewWalletSettlement(walletSvc),
 	}
 
 	// Register available games
@@ -54,37 +134,100 @@ func New(db *sql.DB, rngSvc *rng.Service, walletSvc *wallet.Service, auditSvc *a
 	return engine
 }
 
-// registerGames registers all available games
+// registerGames registers the built-in games
 func (e *Engine) registerGames() {
-	// Fortune Slots - A simple 3-reel slot game
-	// GLI-19 §4.7.1: Minimum 75% RTP
-	e.games["fortune-slots"] = &domain.Game{
-		ID:             "fortune-slots",
-		Name:           "Fortune Slots",
-		Type:           "slots",
-		TheoreticalRTP: 0.96, // 96% RTP
-		MinBet:         domain.Money{Amount: 10, Currency: e.currency},    // $0.10
-		MaxBet:         domain.Money{Amount: 10000, Currency: e.currency}, // $100.00
-		Enabled:        true,
-	}
-
-	// Lucky Sevens - A classic fruit machine
-	e.games["lucky-sevens"] = &domain.Game{
-		ID:             "lucky-sevens",
-		Name:           "Lucky Sevens",
-		Type:           "slots",
-		TheoreticalRTP: 0.94, // 94% RTP
-		MinBet:         domain.Money{Amount: 25, Currency: e.currency},   // $0.25
-		MaxBet:         domain.Money{Amount: 5000, Currency: e.currency}, // $50.00
-		Enabled:        true,
+	for _, def := range DefaultGameDefinitions() {
+		e.RegisterGame(def.game(e.currency), def)
+	}
+}
+
+// SetRecordSeeds enables recording a per-cycle RNG seed in the outcome so the
+// cycle can later be replayed with ReplayCycle. Seeds are still drawn from
+// the cryptographic RNG.
+func (e *Engine) SetRecordSeeds(enabled bool) {
+	e.recordSeeds = enabled
+}
+
+// SetCertificationMode enables recording, with each played cycle, every RNG
+// draw it made and the range the draw was scaled to, for GetCycleRNGTrace
+// GLI-19 §3.2.3: Scaling must be traceable during certification
+func (e *Engine) SetCertificationMode(enabled bool) {
+	e.certificationMode = enabled
+}
+
+// LargeWinThreshold decides which wins are recorded as significant events
+// (GLI-19 §2.8.8). A win is large when it reaches Amount, in minor units, or
+// Multiple times the stake; a zero field is not checked.
+type LargeWinThreshold struct {
+	Amount   int64
+	Multiple float64
+}
+
+// isLarge reports whether win crosses either threshold
+func (t LargeWinThreshold) isLarge(win, stake domain.Money) bool {
+	if win.Amount <= 0 {
+		return false
+	}
+	if t.Amount > 0 && win.Amount >= t.Amount {
+		return true
+	}
+	return t.Multiple > 0 && stake.Amount > 0 && float64(win.Amount) >= t.Multiple*float64(stake.Amount)
+}
+
+// SetLargeWinThreshold sets which wins are audited as large wins
+func (e *Engine) SetLargeWinThreshold(threshold LargeWinThreshold) {
+	e.largeWin = threshold
+}
+
+// SetSessionConflictPolicy sets how StartSession handles a player who already
+// has an active session for the game
+func (e *Engine) SetSessionConflictPolicy(policy SessionConflictPolicy) {
+	e.sessionConflict = policy
+}
+
+// SetControl makes the engine consult the control service before starting a
+// session and before every game cycle (GLI-19 §2.4)
+func (e *Engine) SetControl(controlSvc *control.Service) {
+	e.control = controlSvc
+}
+
+// SetRealityCheck makes the engine record each cycle's play time and refuse
+// play while a reality check awaits acknowledgement (GLI-19 §2.5.5)
+func (e *Engine) SetRealityCheck(svc *realitycheck.Service) {
+	e.realityCheck = svc
+}
+
+// SetEvents publishes limit warnings to the hub
+func (e *Engine) SetEvents(hub *events.Hub) {
+	e.events = hub
+}
+
+// SetPateplay makes VoidGame cancel the Pateplay transaction of a voided
+// cycle that has one recorded
+func (e *Engine) SetPateplay(client *pateplay.Client) {
+	e.pateplay = client
+}
+
+// SetSettlement sets the backend that settles single-round cycles; cycles
+// are settled on the local wallet by default
+func (e *Engine) SetSettlement(backend SettlementBackend) {
+	e.settlement = backend
+}
+
+// checkAccess rejects play when gaming or the game is disabled by the
+// operator, or the player is suspended or excluded (GLI-19 §2.4)
+func (e *Engine) checkAccess(ctx context.Context, playerID, gameID string) error {
+	if e.control == nil {
+		return nil
 	}
+	return e.control.CheckAccess(ctx, playerID, gameID)
 }
 
 // GetGames returns all available games
 func (e *Engine) GetGames() []*domain.Game {
 	games := make([]*domain.Game, 0, len(e.games))
 	for _, g := range e.games {
-		games = append(games, g)
+		games = append(games, e.withActiveVariant(g))
 	}
 	return games
 }
@@ -95,11 +238,35 @@ func (e *Engine) GetGame(gameID string) (*domain.Game, error) {
 	if !ok {
 		return nil, ErrGameNotFound
 	}
-	return game, nil
+	return e.withActiveVariant(game), nil
+}
+
+// loginSessionCtx is the context key for the player's login session
+type loginSessionCtx struct{}
+
+// WithLoginSession returns a context carrying the ID of the login session a
+// game session is started under, so logging out ends only the game sessions
+// it opened
+func WithLoginSession(ctx context.Context, loginSessionID string) context.Context {
+	return context.WithValue(ctx, loginSessionCtx{}, loginSessionID)
+}
+
+// loginSession returns the login session ID from the context, or nil if none
+// is set
+func loginSession(ctx context.Context) interface{} {
+	if id, ok := ctx.Value(loginSessionCtx{}).(string); ok && id != "" {
+		return id
+	}
+	return nil
 }
 
-// StartSession creates a new game session (GLI-19 §4.3)
+// StartSession creates a new game session (GLI-19 §4.3). A login session set
+// with WithLoginSession is recorded against it.
 func (e *Engine) StartSession(ctx context.Context, playerID, gameID string) (*domain.GameSession, error) {
+	if e.isDraining() {
+		return nil, ErrShuttingDown
+	}
+
 	game, err := e.GetGame(gameID)
 	if err != nil {
 		return nil, err
@@ -107,6 +274,9 @@ func (e *Engine) StartSession(ctx context.Context, playerID, gameID string) (*do
 	if !game.Enabled {
 		return nil, ErrGameDisabled
 	}
+	if err := e.checkAccess(ctx, playerID, gameID); err != nil {
+		return nil, err
+	}
 
 	// Get player balance
 	balance, err := e.wallet.GetBalance(ctx, playerID)
@@ -114,6 +284,54 @@ func (e *Engine) StartSession(ctx context.Context, playerID, gameID string) (*do
 		return nil, err
 	}
 
+	// One active session per player and game (GLI-19 §4.3). The player row lock
+	// serialises concurrent starts for the same player.
+	dbTx, err := e.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer dbTx.Rollback()
+
+	var locked string
+	if err := dbTx.QueryRowContext(ctx, "SELECT id FROM players WHERE id = $1 FOR UPDATE", playerID).Scan(&locked); err != nil {
+		return nil, err
+	}
+
+	rows, err := dbTx.QueryContext(ctx, `
+		SELECT id FROM game_sessions WHERE player_id = $1 AND game_id = $2 AND status = $3
+	`, playerID, gameID, domain.GameSessionActive)
+	if err != nil {
+		return nil, err
+	}
+	var staleIDs []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		staleIDs = append(staleIDs, id)
+	}
+	rows.Close()
+
+	if len(staleIDs) > 0 {
+		if e.sessionConflict != SessionConflictEndStale {
+			return nil, ErrSessionAlreadyActive
+		}
+		now := time.Now().UTC()
+		for _, id := range staleIDs {
+			if _, err := dbTx.ExecContext(ctx, `
+				UPDATE game_sessions SET ended_at = $1, status = $2 WHERE id = $3
+			`, now, domain.GameSessionCompleted, id); err != nil {
+				return nil, err
+			}
+			e.audit.Log(ctx, audit.EventGameSessionEnd, domain.SeverityInfo,
+				"Stale game session ended by new session",
+				map[string]string{"session_id": id, "game_id": gameID},
+				audit.WithPlayer(playerID), audit.WithSession(id))
+		}
+	}
+
 	now := time.Now().UTC()
 	session := &domain.GameSession{
 		ID:             uuid.New().String(),
@@ -124,22 +342,28 @@ func (e *Engine) StartSession(ctx context.Context, playerID, gameID string) (*do
 		Status:         domain.GameSessionActive,
 		OpeningBalance: balance.Available,
 		CurrentBalance: balance.Available,
-		TotalWagered:   domain.Money{Amount: 0, Currency: e.currency},
-		TotalWon:       domain.Money{Amount: 0, Currency: e.currency},
+		TotalWagered:   domain.Money{Amount: 0, Currency: balance.Currency},
+		TotalWon:       domain.Money{Amount: 0, Currency: balance.Currency},
 		GamesPlayed:    0,
+		RTPVariant:     game.RTPVariant,
 	}
 
 	// Store session
-	_, err = e.db.ExecContext(ctx, `
-		INSERT INTO game_sessions (id, player_id, game_id, started_at, last_activity_at, status, opening_balance, current_balance, total_wagered, total_won, games_played, currency)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	_, err = dbTx.ExecContext(ctx, `
+		INSERT INTO game_sessions (id, player_id, game_id, started_at, last_activity_at, status, opening_balance, current_balance, total_wagered, total_won, games_played, currency, rtp_variant, login_session_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
 	`, session.ID, session.PlayerID, session.GameID, session.StartedAt, session.LastActivityAt,
 		session.Status, session.OpeningBalance.Amount, session.CurrentBalance.Amount,
-		session.TotalWagered.Amount, session.TotalWon.Amount, session.GamesPlayed, e.currency)
+		session.TotalWagered.Amount, session.TotalWon.Amount, session.GamesPlayed, balance.Currency, session.RTPVariant,
+		loginSession(ctx))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create game session: %w", err)
 	}
 
+	if err := dbTx.Commit(); err != nil {
+		return nil, err
+	}
+
 	// Audit log
 	e.audit.Log(ctx, audit.EventGameSessionStart, domain.SeverityInfo,
 		fmt.Sprintf("Game session started: %s", game.Name),
@@ -151,19 +375,38 @@ func (e *Engine) StartSession(ctx context.Context, playerID, gameID string) (*do
 
 // GetSession retrieves a game session
 func (e *Engine) GetSession(ctx context.Context, sessionID string) (*domain.GameSession, error) {
+	return scanSession(ctx, e.db, sessionID, "")
+}
+
+// lockSession retrieves a game session and locks its row until dbTx ends, so
+// cycles of the session use its feature state one at a time
+func lockSession(ctx context.Context, dbTx *sql.Tx, sessionID string) (*domain.GameSession, error) {
+	return scanSession(ctx, dbTx, sessionID, " FOR UPDATE")
+}
+
+// rowQueryer is implemented by both *sql.DB and *sql.Tx
+type rowQueryer interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// scanSession reads a game session row, appending suffix (e.g. a locking
+// clause) to the query
+func scanSession(ctx context.Context, q rowQueryer, sessionID, suffix string) (*domain.GameSession, error) {
 	var session domain.GameSession
 	var endedAt sql.NullTime
 	var openingBal, currentBal, wagered, won int64
 	var currency string
+	var featureState sql.NullString
 
-	err := e.db.QueryRowContext(ctx, `
+	err := q.QueryRowContext(ctx, `
 		SELECT id, player_id, game_id, started_at, ended_at, last_activity_at, status, 
-		       opening_balance, current_balance, total_wagered, total_won, games_played, currency
-		FROM game_sessions WHERE id = $1
-	`, sessionID).Scan(
+		       opening_balance, current_balance, total_wagered, total_won, games_played, currency,
+		       feature_state, COALESCE(rtp_variant, '')
+		FROM game_sessions WHERE id = $1`+suffix,
+		sessionID).Scan(
 		&session.ID, &session.PlayerID, &session.GameID, &session.StartedAt, &endedAt,
 		&session.LastActivityAt, &session.Status, &openingBal, &currentBal, &wagered, &won,
-		&session.GamesPlayed, &currency)
+		&session.GamesPlayed, &currency, &featureState, &session.RTPVariant)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, ErrSessionNotFound
@@ -179,6 +422,14 @@ func (e *Engine) GetSession(ctx context.Context, sessionID string) (*domain.Game
 	session.TotalWagered = domain.Money{Amount: wagered, Currency: currency}
 	session.TotalWon = domain.Money{Amount: won, Currency: currency}
 
+	if featureState.Valid {
+		var state domain.FeatureState
+		if err := json.Unmarshal([]byte(featureState.String), &state); err != nil {
+			return nil, fmt.Errorf("failed to parse feature state: %w", err)
+		}
+		session.FeatureState = &state
+	}
+
 	return &session, nil
 }
 
@@ -214,10 +465,119 @@ func (e *Engine) EndSession(ctx context.Context, sessionID string) (*domain.Game
 	return session, nil
 }
 
+// InterruptReasonLogout marks a cycle left in progress when the player logged
+// out
+const InterruptReasonLogout = "logout"
+
+// EndActiveSessions ends the player's active game sessions started under a
+// login session, as when the player logs out of it, and returns how many were
+// ended. Game sessions of the player's other logins are left open. A cycle still in progress
+// is marked interrupted rather than settled, and its session is left
+// interrupted, so the player can resume or void the cycle later
+// (GLI-19 §4.16).
+func (e *Engine) EndActiveSessions(ctx context.Context, playerID, loginSessionID string) (int, error) {
+	type endedSession struct {
+		id, gameID  string
+		gamesPlayed int
+		interrupted []string
+	}
+	var ended []*endedSession
+
+	now := time.Now().UTC()
+	err := database.WithTx(ctx, e.db, func(dbTx *sql.Tx) error {
+		rows, err := dbTx.QueryContext(ctx, `
+			SELECT id, game_id, games_played FROM game_sessions
+			WHERE player_id = $1 AND login_session_id = $2 AND status = $3
+			FOR UPDATE
+		`, playerID, loginSessionID, domain.GameSessionActive)
+		if err != nil {
+			return err
+		}
+		for rows.Next() {
+			s := &endedSession{}
+			if err := rows.Scan(&s.id, &s.gameID, &s.gamesPlayed); err != nil {
+				rows.Close()
+				return err
+			}
+			ended = append(ended, s)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		for _, s := range ended {
+			cycles, err := dbTx.QueryContext(ctx, `
+				UPDATE game_cycles SET status = $1, interrupted_at = $2, interrupt_reason = $3
+				WHERE session_id = $4 AND status = $5
+				RETURNING id
+			`, domain.CycleStatusInterrupted, now, InterruptReasonLogout, s.id, domain.CycleStatusInProgress)
+			if err != nil {
+				return err
+			}
+			for cycles.Next() {
+				var id string
+				if err := cycles.Scan(&id); err != nil {
+					cycles.Close()
+					return err
+				}
+				s.interrupted = append(s.interrupted, id)
+			}
+			cycles.Close()
+			if err := cycles.Err(); err != nil {
+				return err
+			}
+
+			status := domain.GameSessionCompleted
+			if len(s.interrupted) > 0 {
+				status = domain.GameSessionInterrupted
+			}
+			if _, err := dbTx.ExecContext(ctx, `
+				UPDATE game_sessions SET ended_at = $1, status = $2 WHERE id = $3
+			`, now, status, s.id); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to end active sessions: %w", err)
+	}
+
+	for _, s := range ended {
+		for _, cycleID := range s.interrupted {
+			e.audit.Log(ctx, "game_interrupted", domain.SeverityWarning,
+				fmt.Sprintf("Game cycle left in progress at logout marked interrupted: %s", cycleID),
+				map[string]interface{}{
+					"cycle_id": cycleID,
+					"game_id":  s.gameID,
+					"reason":   InterruptReasonLogout,
+				},
+				audit.WithPlayer(playerID), audit.WithSession(s.id), audit.WithComponent("game"))
+		}
+		e.audit.Log(ctx, audit.EventGameSessionEnd, domain.SeverityInfo,
+			fmt.Sprintf("Game session ended at logout: %d games played", s.gamesPlayed),
+			map[string]interface{}{
+				"session_id":   s.id,
+				"game_id":      s.gameID,
+				"games_played": s.gamesPlayed,
+				"interrupted":  len(s.interrupted),
+			},
+			audit.WithPlayer(playerID), audit.WithSession(s.id))
+	}
+
+	return len(ended), nil
+}
+
 // PlayRequest contains the data for playing a game
 type PlayRequest struct {
 	SessionID   string `json:"session_id"`
 	WagerAmount int64  `json:"wager_amount"` // In cents
+
+	// Pateplay is the transaction settling the cycle on Pateplay, recorded
+	// with the cycle for reconciliation and cancellation; nil when the cycle
+	// is settled on the local wallet only
+	Pateplay *PateplayRef `json:"-"`
 }
 
 // PlayResult contains the result of a game cycle
@@ -227,10 +587,44 @@ type PlayResult struct {
 	WagerAmount domain.Money `json:"wager_amount"`
 	WinAmount   domain.Money `json:"win_amount"`
 	Balance     domain.Money `json:"balance"`
+
+	// Free spins: whether this cycle was free, and the feature state after it
+	// (nil once the feature has ended)
+	FreeSpin bool                 `json:"free_spin,omitempty"`
+	Feature  *domain.FeatureState `json:"feature,omitempty"`
+
+	// Round is the state of a multi-step round after this step
+	Round *RoundState `json:"round,omitempty"`
+
+	// Jackpot is the game's progressive jackpot after this cycle; nil for
+	// games without one. WinAmount includes any jackpot payout.
+	Jackpot *JackpotState `json:"jackpot,omitempty"`
+
+	// Session is the session's running totals including this cycle; nil when
+	// the cycle did not complete
+	Session *SessionTotals `json:"session,omitempty"`
+}
+
+// SessionTotals is the money a game session has moved so far
+type SessionTotals struct {
+	Wagered domain.Money `json:"wagered"`
+	Won     domain.Money `json:"won"`
+}
+
+// Net is the player's position over the session, what they won less what
+// they wagered
+func (t SessionTotals) Net() domain.Money {
+	return domain.Money{Amount: t.Won.Amount - t.Wagered.Amount, Currency: t.Wagered.Currency}
 }
 
 // Play executes a game cycle (GLI-19 §4.3.3, §4.5)
 func (e *Engine) Play(ctx context.Context, req *PlayRequest) (*PlayResult, error) {
+	done, err := e.beginCycle()
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
 	// Get session
 	session, err := e.GetSession(ctx, req.SessionID)
 	if err != nil {
@@ -240,7 +634,7 @@ func (e *Engine) Play(ctx context.Context, req *PlayRequest) (*PlayResult, error
 		return nil, ErrSessionNotActive
 	}
 
-	// Get game
+	// Get game, on the RTP variant the session started with
 	game, err := e.GetGame(session.GameID)
 	if err != nil {
 		return nil, err
@@ -248,103 +642,247 @@ func (e *Engine) Play(ctx context.Context, req *PlayRequest) (*PlayResult, error
 	if !game.Enabled {
 		return nil, ErrGameDisabled
 	}
-
-	// Validate wager (GLI-19 §4.3.3.b)
-	wager := domain.Money{Amount: req.WagerAmount, Currency: e.currency}
-	if wager.Amount < game.MinBet.Amount || wager.Amount > game.MaxBet.Amount {
-		return nil, ErrInvalidWager
+	game.RTPVariant = session.RTPVariant
+	if err := e.checkAccess(ctx, session.PlayerID, session.GameID); err != nil {
+		return nil, err
+	}
+	if e.realityCheck != nil {
+		if err := e.realityCheck.Check(ctx, session.PlayerID); err != nil {
+			return nil, err
+		}
 	}
 
-	// Get current balance
-	balance, err := e.wallet.GetBalance(ctx, session.PlayerID)
+	// Validate wager (GLI-19 §4.3.3.b); the session's feature state is read
+	// again under lock once the cycle's transaction starts
+	feature := session.FeatureState
+	freeSpin := feature.Active()
+	currency := session.OpeningBalance.Currency
+	wager, stake, err := cycleStake(game, feature, req.WagerAmount, currency)
 	if err != nil {
 		return nil, err
 	}
-	if balance.Available.Amount < wager.Amount {
-		return nil, ErrInsufficientBalance
+
+	// Responsible gaming checks (GLI-19 §2.5.5)
+	if err := e.checkExcluded(ctx, session.PlayerID); err != nil {
+		return nil, err
 	}
 
+	// The cycle, the money it moves and the session totals commit together or
+	// not at all, so a failure part way through never leaves a wager debited
+	// without a recorded cycle (GLI-19 §4.16)
 	now := time.Now().UTC()
 	cycleID := uuid.New().String()
+	var outcome *SlotOutcome
+	var winAmount domain.Money
+	var jackpot *JackpotState
+	var before, newBalance *domain.Balance
+	var limitWarnings []string
+	var settlement *Settlement // Set once the cycle's money has moved
+	totals := &SessionTotals{
+		Wagered: domain.Money{Currency: currency},
+		Won:     domain.Money{Currency: currency},
+	}
 
-	// Deduct wager (GLI-19 §4.3.3.b)
-	_, err = e.wallet.PlaceWager(ctx, session.PlayerID, wager, session.GameID, cycleID)
-	if err != nil {
-		return nil, err
+	// In certification mode every RNG draw of the cycle is recorded with the
+	// range it was scaled to (GLI-19 §3.2.3)
+	var draws *rng.Recorder
+	if e.certificationMode {
+		draws = rng.NewRecorder()
+		ctx = rng.WithRecorder(ctx, draws)
 	}
 
-	// Generate outcome using RNG (GLI-19 §4.5)
-	outcome, err := e.generateSlotOutcome(game)
-	if err != nil {
-		// Refund on error
-		e.wallet.CreditWin(ctx, session.PlayerID, wager, session.GameID, cycleID)
-		return nil, fmt.Errorf("failed to generate outcome: %w", err)
+	// A Pateplay cycle without its own round and transaction IDs uses the
+	// cycle ID for both
+	pp := req.Pateplay
+	if pp != nil {
+		ref := *pp
+		if ref.RoundID == "" {
+			ref.RoundID = cycleID
+		}
+		if ref.TransactionID == "" {
+			ref.TransactionID = cycleID
+		}
+		pp = &ref
 	}
+	ppToken, ppPlayerID, ppRoundID, ppTransactionID := pp.columns()
 
-	// Calculate win based on outcome
-	winAmount := e.calculateWin(outcome, wager)
+	err = database.WithTx(ctx, e.db, func(dbTx *sql.Tx) error {
+		// Lock the session so a concurrent cycle cannot play the same free
+		// spin, then the balance, for the rest of the cycle
+		locked, err := lockSession(ctx, dbTx, session.ID)
+		if err != nil {
+			return err
+		}
+		if locked.Status != domain.GameSessionActive {
+			return ErrSessionNotActive
+		}
+		feature = locked.FeatureState
+		freeSpin = feature.Active()
+		if wager, stake, err = cycleStake(game, feature, req.WagerAmount, currency); err != nil {
+			return err
+		}
 
-	// Credit win if any (GLI-19 §4.3.3.d)
-	if winAmount.Amount > 0 {
-		_, err = e.wallet.CreditWin(ctx, session.PlayerID, winAmount, session.GameID, cycleID)
+		balance, err := e.wallet.GetBalanceTx(ctx, dbTx, session.PlayerID)
 		if err != nil {
-			return nil, err
+			return err
+		}
+		if balance.Available.Amount < wager.Amount {
+			return ErrInsufficientBalance
+		}
+		if err := e.checkWagerLimits(ctx, dbTx, session.PlayerID, wager); err != nil {
+			return err
+		}
+		before = balance
+
+		// Playing on banks any win from the previous cycle
+		_, err = dbTx.ExecContext(ctx, `
+			UPDATE game_cycles SET win_banked = true WHERE session_id = $1 AND win_banked = false
+		`, session.ID)
+		if err != nil {
+			return err
+		}
+
+		// Record the cycle before any money moves (GLI-19 §2.8.2)
+		_, err = dbTx.ExecContext(ctx, `
+			INSERT INTO game_cycles (id, session_id, player_id, game_id, started_at, wager_amount, win_amount, balance_before, balance_after, status, currency, win_banked,
+				pateplay_session_token, pateplay_player_id, pateplay_round_id, pateplay_transaction_id)
+			VALUES ($1, $2, $3, $4, $5, $6, 0, $7, $7, $8, $9, false, $10, $11, $12, $13)
+		`, cycleID, session.ID, session.PlayerID, session.GameID, now,
+			wager.Amount, balance.Available.Amount, domain.CycleStatusInProgress, currency,
+			ppToken, ppPlayerID, ppRoundID, ppTransactionID)
+		if err != nil {
+			return err
+		}
+
+		// Generate outcome using RNG (GLI-19 §4.5)
+		outcome, err = e.generateSlotOutcome(ctx, game)
+		if err != nil {
+			return fmt.Errorf("failed to generate outcome: %w", err)
+		}
+		if freeSpin {
+			outcome.Multiplier = feature.Multiplier
+		}
+
+		// Calculate win based on outcome
+		winAmount = e.calculateWin(outcome, stake)
+
+		// Carry the feature into the next cycle
+		feature = e.advanceFeature(session.GameID, feature, outcome, stake, winAmount, freeSpin)
+		var featureJSON interface{}
+		if feature != nil {
+			data, _ := json.Marshal(feature)
+			featureJSON = string(data)
+		}
+
+		// Debit the wager and credit any win (GLI-19 §4.3.3.b, §4.3.3.d)
+		settled := &Settlement{
+			CycleID:  cycleID,
+			PlayerID: session.PlayerID,
+			GameID:   session.GameID,
+			Wager:    wager,
+			Win:      winAmount,
+			Pateplay: pp,
+		}
+
+		// Progressive jackpot; a jackpot payout counts toward the cycle's win
+		jackpot, err = e.drawJackpot(ctx, dbTx, session, wager)
+		if err != nil {
+			return err
+		}
+		if jackpot != nil {
+			settled.JackpotContribution = jackpot.Contribution
+			if jackpot.IsJackpotWin {
+				settled.JackpotWin = jackpot.WinAmount
+				if winAmount, err = winAmount.AddChecked(jackpot.WinAmount); err != nil {
+					return err
+				}
+			}
+		}
+
+		if err := e.settlement.Settle(ctx, dbTx, settled); err != nil {
+			return err
+		}
+		settlement = settled
+		limitWarnings = settled.Warnings
+
+		newBalance, err = e.wallet.GetBalanceTx(ctx, dbTx, session.PlayerID)
+		if err != nil {
+			return err
+		}
+
+		// Complete the game cycle (GLI-19 §2.8.2)
+		outcomeJSON, _ := json.Marshal(outcome)
+		var traceJSON interface{}
+		if draws != nil {
+			data, _ := json.Marshal(draws.Draws())
+			traceJSON = string(data)
 		}
+		_, err = dbTx.ExecContext(ctx, `
+			UPDATE game_cycles SET completed_at = $1, win_amount = $2, balance_after = $3, outcome = $4, status = $5, rng_trace = $6
+			WHERE id = $7
+		`, now, winAmount.Amount, newBalance.Available.Amount, string(outcomeJSON), domain.CycleStatusCompleted, traceJSON, cycleID)
+		if err != nil {
+			return err
+		}
+
+		// Update session stats
+		err = dbTx.QueryRowContext(ctx, `
+			UPDATE game_sessions SET 
+				last_activity_at = $1,
+				current_balance = $2,
+				total_wagered = total_wagered + $3,
+				total_won = total_won + $4,
+				games_played = games_played + 1,
+				feature_state = $5
+			WHERE id = $6
+			RETURNING total_wagered, total_won
+		`, now, newBalance.Available.Amount, wager.Amount, winAmount.Amount, featureJSON, session.ID).Scan(&totals.Wagered.Amount, &totals.Won.Amount)
+		if err != nil {
+			return err
+		}
+
+		// The pool is shared by every player of the game, so it is locked
+		// last, once the cycle has settled
+		return e.updateJackpot(ctx, dbTx, session, jackpot)
+	})
+	if err != nil {
+		e.cancelSettlement(ctx, settlement)
+		return nil, e.translatePateplayLimit(ctx, session.PlayerID, wager, err)
 	}
 
-	// Get updated balance
-	newBalance, _ := e.wallet.GetBalance(ctx, session.PlayerID)
-
-	// Store game cycle (GLI-19 §2.8.2)
-	outcomeJSON, _ := json.Marshal(outcome)
-	completedAt := now
-	cycle := &domain.GameCycle{
-		ID:            cycleID,
-		SessionID:     session.ID,
-		PlayerID:      session.PlayerID,
-		GameID:        session.GameID,
-		StartedAt:     now,
-		CompletedAt:   &completedAt,
-		WagerAmount:   wager,
-		WinAmount:     winAmount,
-		BalanceBefore: balance.Available,
-		BalanceAfter:  newBalance.Available,
-		Outcome:       outcomeJSON,
-		Status:        domain.CycleStatusCompleted,
+	// Tell the player's connections about the money the cycle moved
+	if winAmount.Amount > 0 {
+		e.wallet.PublishBalance(newBalance, domain.TxTypeWin)
+	} else if !freeSpin {
+		e.wallet.PublishBalance(newBalance, domain.TxTypeWager)
 	}
 
-	_, err = e.db.ExecContext(ctx, `
-		INSERT INTO game_cycles (id, session_id, player_id, game_id, started_at, completed_at, wager_amount, win_amount, balance_before, balance_after, outcome, status, currency)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
-	`, cycle.ID, cycle.SessionID, cycle.PlayerID, cycle.GameID, cycle.StartedAt, cycle.CompletedAt,
-		cycle.WagerAmount.Amount, cycle.WinAmount.Amount, cycle.BalanceBefore.Amount, cycle.BalanceAfter.Amount,
-		string(outcomeJSON), cycle.Status, e.currency)
-	if err != nil {
-		return nil, err
+	if jackpot != nil && jackpot.IsJackpotWin {
+		e.auditJackpotWin(ctx, session, cycleID, jackpot)
 	}
+	e.convertMetBonus(ctx, session.PlayerID, before, newBalance)
 
-	// Update session stats
-	_, err = e.db.ExecContext(ctx, `
-		UPDATE game_sessions SET 
-			last_activity_at = $1,
-			current_balance = $2,
-			total_wagered = total_wagered + $3,
-			total_won = total_won + $4,
-			games_played = games_played + 1
-		WHERE id = $5
-	`, now, newBalance.Available.Amount, wager.Amount, winAmount.Amount, session.ID)
-	if err != nil {
-		return nil, err
+	for _, code := range limitWarnings {
+		e.publishLimitWarning(session.PlayerID, code)
+	}
+
+	// Count the cycle toward the player's play time; a reality check it
+	// raises holds the next cycle, not this one
+	if e.realityCheck != nil {
+		if _, err := e.realityCheck.RecordPlay(ctx, session.PlayerID, now); err != nil {
+			log.Printf("Failed to record play time for player %s: %v", session.PlayerID, err)
+		}
 	}
 
 	// Audit log for large wins (GLI-19 §2.8.8)
-	if winAmount.Amount >= 10000 { // $100+ wins
+	if e.largeWin.isLarge(winAmount, stake) {
 		e.audit.Log(ctx, audit.EventLargeWin, domain.SeverityInfo,
 			fmt.Sprintf("Large win: %.2f %s", winAmount.Float64(), winAmount.Currency),
 			map[string]interface{}{
 				"cycle_id": cycleID,
 				"win":      winAmount.Float64(),
 				"wager":    wager.Float64(),
+				"stake":    stake.Float64(),
 				"game_id":  session.GameID,
 			},
 			audit.WithPlayer(session.PlayerID), audit.WithSession(session.ID))
@@ -356,9 +894,139 @@ func (e *Engine) Play(ctx context.Context, req *PlayRequest) (*PlayResult, error
 		WagerAmount: wager,
 		WinAmount:   winAmount,
 		Balance:     newBalance.Available,
+		FreeSpin:    freeSpin,
+		Feature:     feature,
+		Jackpot:     jackpot,
+		Session:     totals,
 	}, nil
 }
 
+// cycleStake returns the wager debited for a cycle and the stake its win is
+// paid on. A free spin is played at the triggering stake without a wager;
+// otherwise the wager must be within the game's bet range (GLI-19 §4.3.3.b).
+func cycleStake(game *domain.Game, feature *domain.FeatureState, amount int64, currency string) (wager, stake domain.Money, err error) {
+	if feature.Active() {
+		return domain.Money{Amount: 0, Currency: currency}, domain.Money{Amount: feature.Wager, Currency: currency}, nil
+	}
+	wager = domain.Money{Amount: amount, Currency: currency}
+	if wager.Amount < game.MinBet.Amount || wager.Amount > game.MaxBet.Amount {
+		return wager, wager, ErrInvalidWager
+	}
+	return wager, wager, nil
+}
+
+// cancelSettlement returns the money a settlement moved outside the database
+// when its cycle then failed to commit; nil means nothing was settled
+func (e *Engine) cancelSettlement(ctx context.Context, s *Settlement) {
+	if s == nil {
+		return
+	}
+	if err := e.settlement.Cancel(ctx, s); err != nil {
+		log.Printf("Failed to cancel settlement of cycle %s: %v", s.CycleID, err)
+	}
+}
+
+// checkExcluded rejects play for self-excluded players
+// GLI-19 §2.5.5 - Limits and exclusions must be enforced
+func (e *Engine) checkExcluded(ctx context.Context, playerID string) error {
+	if e.limits == nil {
+		return nil
+	}
+
+	excluded, err := e.limits.IsExcluded(ctx, playerID)
+	if err != nil {
+		return err
+	}
+	if excluded {
+		return ErrPlayerExcluded
+	}
+	return nil
+}
+
+// checkWagerLimits rejects a wager that would exceed the player's wager or
+// loss limits. It runs inside the cycle's transaction once the balance is
+// locked, so concurrent cycles of the player are checked one at a time
+// against the wagers and wins already committed (GLI-19 §2.5.5). The limits
+// are read on the cycle's own transaction, which already holds a connection.
+func (e *Engine) checkWagerLimits(ctx context.Context, dbTx *sql.Tx, playerID string, wager domain.Money) error {
+	if e.limits == nil || wager.Amount <= 0 {
+		return nil
+	}
+	if err := e.limits.CheckWagerLimitTx(ctx, dbTx, playerID, wager); err != nil {
+		if errors.Is(err, limits.ErrWagerLimitExceeded) {
+			e.publishLimitReached(playerID, "wager", wager)
+			return ErrWagerLimitExceeded
+		}
+		return err
+	}
+	if err := e.limits.CheckLossLimitTx(ctx, dbTx, playerID, wager); err != nil {
+		if errors.Is(err, limits.ErrLossLimitExceeded) {
+			e.publishLimitReached(playerID, "loss", wager)
+			return ErrLossLimitExceeded
+		}
+		return err
+	}
+	return nil
+}
+
+// convertMetBonus converts the player's bonus to real money once a cycle's
+// wager has met the wagering requirement. It runs after the cycle has
+// committed, so the conversion and its audit entry do not hold up the cycle.
+func (e *Engine) convertMetBonus(ctx context.Context, playerID string, before, after *domain.Balance) {
+	if before == nil || after == nil || !wallet.WageringMet(before, after) {
+		return
+	}
+	_, err := e.wallet.ConvertBonus(ctx, playerID)
+	if err != nil && !errors.Is(err, wallet.ErrNoBonus) && !errors.Is(err, wallet.ErrWageringIncomplete) {
+		log.Printf("Failed to convert bonus for player %s: %v", playerID, err)
+	}
+}
+
+// publishLimitReached tells the player's open connections that a wager was
+// refused by a responsible gaming limit (GLI-19 §2.5.5)
+func (e *Engine) publishLimitReached(playerID, limit string, wager domain.Money) {
+	e.events.Publish(events.Event{
+		Type:     events.LimitWarning,
+		PlayerID: playerID,
+		Data: map[string]interface{}{
+			"limit":   limit,
+			"reached": true,
+			"wager":   wager.Float64(),
+		},
+	})
+}
+
+// advanceFeature returns the feature state after a cycle: a free spin is
+// consumed, scatters award or retrigger free spins, and the feature ends
+// (nil) once no free spins remain. The caller holds the session row lock from
+// reading feature until the result is saved, so concurrent cycles neither
+// replay a free spin nor lose a retrigger.
+func (e *Engine) advanceFeature(gameID string, feature *domain.FeatureState, outcome *SlotOutcome, stake, win domain.Money, freeSpin bool) *domain.FeatureState {
+	if freeSpin {
+		feature.FreeSpinsRemaining--
+		feature.TotalWin += win.Amount
+	} else {
+		feature = nil
+	}
+
+	if outcome.FreeSpins > 0 {
+		if feature == nil {
+			multiplier := 1
+			if def, ok := e.definitions[gameID]; ok && def.FreeSpins != nil {
+				multiplier = def.FreeSpins.Multiplier
+			}
+			feature = &domain.FeatureState{Multiplier: multiplier, Wager: stake.Amount}
+		}
+		feature.FreeSpinsRemaining += outcome.FreeSpins
+		feature.FreeSpinsAwarded += outcome.FreeSpins
+	}
+
+	if !feature.Active() {
+		return nil
+	}
+	return feature
+}
+
 // GetHistory retrieves game history (GLI-19 §4.14)
 func (e *Engine) GetHistory(ctx context.Context, playerID string, limit int) ([]*domain.GameRecall, error) {
 	if limit <= 0 {
@@ -366,7 +1034,8 @@ func (e *Engine) GetHistory(ctx context.Context, playerID string, limit int) ([]
 	}
 
 	rows, err := e.db.QueryContext(ctx, `
-		SELECT id, game_id, started_at, wager_amount, win_amount, balance_before, balance_after, outcome, currency
+		SELECT id, game_id, started_at, wager_amount, win_amount, balance_before, balance_after, outcome, currency,
+		       pateplay_round_id, pateplay_transaction_id
 		FROM game_cycles WHERE player_id = $1 ORDER BY started_at DESC LIMIT $2
 	`, playerID, limit)
 	if err != nil {
@@ -379,9 +1048,10 @@ func (e *Engine) GetHistory(ctx context.Context, playerID string, limit int) ([]
 		var recall domain.GameRecall
 		var wager, win, balBefore, balAfter int64
 		var outcome, currency string
+		var ppRoundID, ppTransactionID sql.NullString
 
 		err := rows.Scan(&recall.CycleID, &recall.GameID, &recall.PlayedAt,
-			&wager, &win, &balBefore, &balAfter, &outcome, &currency)
+			&wager, &win, &balBefore, &balAfter, &outcome, &currency, &ppRoundID, &ppTransactionID)
 		if err != nil {
 			return nil, err
 		}
@@ -391,6 +1061,11 @@ func (e *Engine) GetHistory(ctx context.Context, playerID string, limit int) ([]
 		recall.BalanceBefore = domain.Money{Amount: balBefore, Currency: currency}
 		recall.BalanceAfter = domain.Money{Amount: balAfter, Currency: currency}
 		recall.Outcome = json.RawMessage(outcome)
+		recall.PateplayRoundID = ppRoundID.String
+		recall.PateplayTransactionID = ppTransactionID.String
+		if decoded := decodeOutcome(recall.Outcome); decoded != nil {
+			recall.DecodedOutcome = decoded
+		}
 
 		history = append(history, &recall)
 	}
@@ -398,12 +1073,159 @@ func (e *Engine) GetHistory(ctx context.Context, playerID string, limit int) ([]
 	return history, nil
 }
 
+// GetCycle retrieves a single game cycle with its complete outcome
+// GLI-19 §4.14: Game Recall - any past round must be retrievable in full
+func (e *Engine) GetCycle(ctx context.Context, cycleID string) (*domain.GameCycle, error) {
+	if _, err := uuid.Parse(cycleID); err != nil {
+		return nil, ErrCycleNotFound
+	}
+
+	var cycle domain.GameCycle
+	var completedAt sql.NullTime
+	var wager, win, balBefore, balAfter int64
+	var outcome, ppRoundID, ppTransactionID sql.NullString
+	var currency string
+
+	err := e.db.QueryRowContext(ctx, `
+		SELECT id, session_id, player_id, game_id, started_at, completed_at,
+		       wager_amount, win_amount, balance_before, balance_after, outcome, status, currency,
+		       pateplay_round_id, pateplay_transaction_id
+		FROM game_cycles WHERE id = $1
+	`, cycleID).Scan(&cycle.ID, &cycle.SessionID, &cycle.PlayerID, &cycle.GameID,
+		&cycle.StartedAt, &completedAt, &wager, &win, &balBefore, &balAfter,
+		&outcome, &cycle.Status, &currency, &ppRoundID, &ppTransactionID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrCycleNotFound
+		}
+		return nil, err
+	}
+
+	if completedAt.Valid {
+		cycle.CompletedAt = &completedAt.Time
+	}
+	cycle.WagerAmount = domain.Money{Amount: wager, Currency: currency}
+	cycle.WinAmount = domain.Money{Amount: win, Currency: currency}
+	cycle.BalanceBefore = domain.Money{Amount: balBefore, Currency: currency}
+	cycle.BalanceAfter = domain.Money{Amount: balAfter, Currency: currency}
+	cycle.PateplayRoundID = ppRoundID.String
+	cycle.PateplayTransactionID = ppTransactionID.String
+	if outcome.Valid {
+		cycle.Outcome = json.RawMessage(outcome.String)
+		if decoded := decodeOutcome(cycle.Outcome); decoded != nil {
+			cycle.DecodedOutcome = decoded
+		}
+	}
+
+	return &cycle, nil
+}
+
+// decodeOutcome decodes a stored outcome for recall. An outcome that does not
+// decode, such as one written by a newer server, yields nil and is recalled
+// in its raw form only.
+func decodeOutcome(raw json.RawMessage) *SlotOutcome {
+	if len(raw) == 0 {
+		return nil
+	}
+	var outcome SlotOutcome
+	if err := json.Unmarshal(raw, &outcome); err != nil {
+		return nil
+	}
+	return &outcome
+}
+
+// GetCycleRNGTrace returns the RNG draws recorded with a cycle played in
+// certification mode, in the order they were made
+// GLI-19 §3.2.3: Scaling must be traceable during certification
+func (e *Engine) GetCycleRNGTrace(ctx context.Context, cycleID string) ([]rng.Draw, error) {
+	if _, err := uuid.Parse(cycleID); err != nil {
+		return nil, ErrCycleNotFound
+	}
+
+	var trace sql.NullString
+	err := e.db.QueryRowContext(ctx, "SELECT rng_trace FROM game_cycles WHERE id = $1", cycleID).Scan(&trace)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrCycleNotFound
+		}
+		return nil, err
+	}
+	if !trace.Valid {
+		return nil, ErrNoRNGTrace
+	}
+
+	var draws []rng.Draw
+	if err := json.Unmarshal([]byte(trace.String), &draws); err != nil {
+		return nil, fmt.Errorf("invalid recorded RNG trace: %w", err)
+	}
+	return draws, nil
+}
+
+// ReplayCycle reconstructs a cycle's outcome from its recorded RNG seed and
+// verifies it matches the persisted outcome
+// GLI-19 §4.14: Game Recall - disputed rounds must be reproducible
+func (e *Engine) ReplayCycle(ctx context.Context, cycleID string) (*SlotOutcome, error) {
+	var gameID, outcomeJSON string
+	err := e.db.QueryRowContext(ctx, `
+		SELECT game_id, outcome FROM game_cycles WHERE id = $1
+	`, cycleID).Scan(&gameID, &outcomeJSON)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrCycleNotFound
+		}
+		return nil, err
+	}
+
+	var recorded SlotOutcome
+	if err := json.Unmarshal([]byte(outcomeJSON), &recorded); err != nil {
+		return nil, fmt.Errorf("failed to parse recorded outcome: %w", err)
+	}
+
+	return e.replayOutcome(gameID, &recorded)
+}
+
+// replayOutcome re-spins a recorded outcome from its seed and compares the result
+func (e *Engine) replayOutcome(gameID string, recorded *SlotOutcome) (*SlotOutcome, error) {
+	if recorded.Seed == "" {
+		return nil, ErrNoReplaySeed
+	}
+	seed, err := hex.DecodeString(recorded.Seed)
+	if err != nil {
+		return nil, fmt.Errorf("invalid recorded seed: %w", err)
+	}
+
+	game, err := e.GetGame(gameID)
+	if err != nil {
+		return nil, err
+	}
+	game.RTPVariant = recorded.RTPVariant
+
+	replayed, err := e.spinReels(context.Background(), game, rng.NewSeeded(seed))
+	if err != nil {
+		return nil, err
+	}
+	replayed.Seed = recorded.Seed
+	// The multiplier comes from feature state, not the RNG
+	replayed.Multiplier = recorded.Multiplier
+	// Gambles are drawn after the spin, from the live RNG
+	replayed.Gamble = recorded.Gamble
+
+	want, _ := json.Marshal(recorded)
+	got, _ := json.Marshal(replayed)
+	if !bytes.Equal(want, got) {
+		return replayed, ErrReplayMismatch
+	}
+
+	return replayed, nil
+}
+
 // GetInterruptedGames retrieves a player's interrupted games
 // GLI-19 §4.16 - Interrupted Games: System must allow recovery of interrupted games
 func (e *Engine) GetInterruptedGames(ctx context.Context, playerID string) ([]*domain.InterruptedGame, error) {
 	rows, err := e.db.QueryContext(ctx, `
-		SELECT gc.id, gc.session_id, gc.player_id, gc.game_id, gc.started_at, 
-		       gc.wager_amount, gc.outcome, gs.currency
+		SELECT gc.id, gc.session_id, gc.player_id, gc.game_id,
+		       COALESCE(gc.interrupted_at, gc.started_at), COALESCE(gc.interrupt_reason, 'connection_lost'),
+		       gc.wager_amount, COALESCE(gc.game_state, gc.outcome), gs.currency
 		FROM game_cycles gc
 		JOIN game_sessions gs ON gc.session_id = gs.id
 		WHERE gc.player_id = $1 AND gc.status = $2
@@ -421,7 +1243,7 @@ func (e *Engine) GetInterruptedGames(ctx context.Context, playerID string) ([]*d
 		var outcome, currency string
 
 		err := rows.Scan(&ig.CycleID, &ig.SessionID, &ig.PlayerID, &ig.GameID,
-			&ig.InterruptedAt, &wager, &outcome, &currency)
+			&ig.InterruptedAt, &ig.Reason, &wager, &outcome, &currency)
 		if err != nil {
 			return nil, err
 		}
@@ -429,7 +1251,6 @@ func (e *Engine) GetInterruptedGames(ctx context.Context, playerID string) ([]*d
 		ig.WagerHeld = domain.Money{Amount: wager, Currency: currency}
 		ig.GameState = json.RawMessage(outcome)
 		ig.CanResume = true
-		ig.Reason = "connection_lost"
 
 		interrupted = append(interrupted, &ig)
 	}
@@ -440,140 +1261,265 @@ func (e *Engine) GetInterruptedGames(ctx context.Context, playerID string) ([]*d
 // ResumeGame continues an interrupted game
 // GLI-19 §4.16 - Interrupted Games: Players must be able to resume interrupted games
 func (e *Engine) ResumeGame(ctx context.Context, cycleID string) (*PlayResult, error) {
-	// Get the interrupted cycle
-	var cycle domain.GameCycle
-	var wager, balBefore int64
-	var outcome, currency string
-
-	err := e.db.QueryRowContext(ctx, `
-		SELECT gc.id, gc.session_id, gc.player_id, gc.game_id, gc.started_at,
-		       gc.wager_amount, gc.balance_before, gc.outcome, gs.currency
-		FROM game_cycles gc
-		JOIN game_sessions gs ON gc.session_id = gs.id
-		WHERE gc.id = $1 AND gc.status = $2
-	`, cycleID, domain.CycleStatusInterrupted).Scan(
-		&cycle.ID, &cycle.SessionID, &cycle.PlayerID, &cycle.GameID,
-		&cycle.StartedAt, &wager, &balBefore, &outcome, &currency)
+	done, err := e.beginCycle()
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return nil, fmt.Errorf("interrupted game not found or already resolved")
+		return nil, err
+	}
+	defer done()
+
+	// Lock the interrupted cycle, so a concurrent void or resume of it fails.
+	// Multi-step rounds continue from their saved state and complete only
+	// once the final step resolves.
+	var round *roundCycle
+	var result *PlayResult
+	var newBalance *domain.Balance
+	err = database.WithTx(ctx, e.db, func(dbTx *sql.Tx) error {
+		var state *RoundState
+		round, state, err = e.loadRound(ctx, dbTx, cycleID, domain.CycleStatusInterrupted)
+		if err != nil {
+			return err
+		}
+		if state != nil {
+			result, newBalance, err = e.advanceRound(ctx, dbTx, round, state, true)
+		} else {
+			result, newBalance, err = e.resumeCycle(ctx, dbTx, round)
+		}
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, ErrCycleNotFound) {
+			return nil, ErrCycleNotInterrupted
 		}
 		return nil, err
 	}
 
-	cycle.WagerAmount = domain.Money{Amount: wager, Currency: currency}
-	cycle.BalanceBefore = domain.Money{Amount: balBefore, Currency: currency}
-	cycle.Outcome = json.RawMessage(outcome)
+	if result.Round != nil {
+		e.roundCommitted(ctx, round, result, newBalance, false)
+	} else if result.WinAmount.Amount > 0 {
+		e.wallet.PublishBalance(newBalance, domain.TxTypeWin)
+	}
+
+	// Audit log
+	e.audit.Log(ctx, "game_resumed", domain.SeverityInfo,
+		fmt.Sprintf("Interrupted game resumed: %s", cycleID),
+		map[string]interface{}{
+			"cycle_id":   cycleID,
+			"game_id":    round.game.ID,
+			"win_amount": result.WinAmount.Float64(),
+		},
+		audit.WithPlayer(round.playerID), audit.WithSession(round.sessionID))
+
+	return result, nil
+}
+
+// resumeCycle completes a locked single-step interrupted cycle from its
+// stored outcome inside dbTx, crediting the win it paid
+func (e *Engine) resumeCycle(ctx context.Context, dbTx *sql.Tx, cycle *roundCycle) (*PlayResult, *domain.Balance, error) {
+	var outcome string
+	err := dbTx.QueryRowContext(ctx, `
+		SELECT outcome FROM game_cycles WHERE id = $1
+	`, cycle.id).Scan(&outcome)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	// Parse existing outcome
 	var slotOutcome SlotOutcome
-	if err := json.Unmarshal(cycle.Outcome, &slotOutcome); err != nil {
-		return nil, fmt.Errorf("failed to parse game state: %w", err)
+	if err := json.Unmarshal([]byte(outcome), &slotOutcome); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse game state: %w", err)
 	}
 
 	// Calculate win based on stored outcome
-	winAmount := e.calculateWin(&slotOutcome, cycle.WagerAmount)
+	winAmount := e.calculateWin(&slotOutcome, cycle.wager)
 
 	// Credit win if any
 	if winAmount.Amount > 0 {
-		_, err = e.wallet.CreditWin(ctx, cycle.PlayerID, winAmount, cycle.GameID, cycle.ID)
-		if err != nil {
-			return nil, err
+		if _, err := e.wallet.CreditWinTx(ctx, dbTx, cycle.playerID, winAmount, cycle.game.ID, cycle.id); err != nil {
+			return nil, nil, err
 		}
 	}
 
 	// Get updated balance
-	newBalance, _ := e.wallet.GetBalance(ctx, cycle.PlayerID)
-
-	now := time.Now().UTC()
+	newBalance, err := e.wallet.GetBalanceTx(ctx, dbTx, cycle.playerID)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	// Update cycle status to completed
-	_, err = e.db.ExecContext(ctx, `
+	_, err = dbTx.ExecContext(ctx, `
 		UPDATE game_cycles SET status = $1, completed_at = $2, win_amount = $3, balance_after = $4
 		WHERE id = $5
-	`, domain.CycleStatusCompleted, now, winAmount.Amount, newBalance.Available.Amount, cycleID)
+	`, domain.CycleStatusCompleted, time.Now().UTC(), winAmount.Amount, newBalance.Available.Amount, cycle.id)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	// Audit log
-	e.audit.Log(ctx, "game_resumed", domain.SeverityInfo,
-		fmt.Sprintf("Interrupted game resumed: %s", cycleID),
-		map[string]interface{}{
-			"cycle_id":   cycleID,
-			"game_id":    cycle.GameID,
-			"win_amount": winAmount.Float64(),
-		},
-		audit.WithPlayer(cycle.PlayerID), audit.WithSession(cycle.SessionID))
-
 	return &PlayResult{
-		CycleID:     cycleID,
+		CycleID:     cycle.id,
 		Outcome:     &slotOutcome,
-		WagerAmount: cycle.WagerAmount,
+		WagerAmount: cycle.wager,
 		WinAmount:   winAmount,
 		Balance:     newBalance.Available,
-	}, nil
+	}, newBalance, nil
 }
 
-// VoidGame cancels an interrupted game and refunds the wager
-// GLI-19 §4.16 - Interrupted Games: Must support voiding with refund
-func (e *Engine) VoidGame(ctx context.Context, cycleID, reason string) error {
-	// Get the interrupted cycle
-	var playerID, gameID, sessionID string
-	var wager int64
-	var currency string
+// PateplayRef identifies the Pateplay transaction behind a game cycle
+type PateplayRef struct {
+	SessionToken  string
+	PlayerID      string
+	RoundID       string // rgsRoundId sent to Pateplay
+	TransactionID string // rgsTransactionId sent to Pateplay
+}
 
-	err := e.db.QueryRowContext(ctx, `
-		SELECT gc.player_id, gc.game_id, gc.session_id, gc.wager_amount, gs.currency
-		FROM game_cycles gc
-		JOIN game_sessions gs ON gc.session_id = gs.id
-		WHERE gc.id = $1 AND gc.status = $2
-	`, cycleID, domain.CycleStatusInterrupted).Scan(&playerID, &gameID, &sessionID, &wager, &currency)
+// columns returns the references as nullable column values, all NULL for a
+// nil ref
+func (r *PateplayRef) columns() (token, playerID, roundID, transactionID sql.NullString) {
+	if r == nil {
+		return
+	}
+	return sql.NullString{String: r.SessionToken, Valid: true},
+		sql.NullString{String: r.PlayerID, Valid: true},
+		sql.NullString{String: r.RoundID, Valid: true},
+		sql.NullString{String: r.TransactionID, Valid: true}
+}
+
+// SetCyclePateplayRef records the Pateplay transaction of a game cycle, so
+// voiding the cycle cancels it on Pateplay too
+func (e *Engine) SetCyclePateplayRef(ctx context.Context, cycleID string, ref PateplayRef) error {
+	res, err := e.db.ExecContext(ctx, `
+		UPDATE game_cycles SET pateplay_session_token = $1, pateplay_player_id = $2,
+			pateplay_round_id = $3, pateplay_transaction_id = $4
+		WHERE id = $5
+	`, ref.SessionToken, ref.PlayerID, ref.RoundID, ref.TransactionID, cycleID)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return fmt.Errorf("interrupted game not found or already resolved")
-		}
 		return err
 	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrCycleNotFound
+	}
+	return nil
+}
 
-	wagerAmount := domain.Money{Amount: wager, Currency: currency}
+// VoidGame cancels an interrupted game and refunds the wager. The cycle is
+// locked for the void, so a concurrent void or resume of it fails with
+// ErrCycleNotInterrupted. A cycle with a recorded Pateplay transaction is
+// cancelled on Pateplay first; the cycle is left interrupted if that fails,
+// so the void can be retried.
+// GLI-19 §4.16 - Interrupted Games: Must support voiding with refund
+func (e *Engine) VoidGame(ctx context.Context, cycleID, reason string) error {
+	var playerID, gameID, sessionID string
+	var wagerAmount domain.Money
+	var newBalance *domain.Balance
 
-	// Refund the wager
-	_, err = e.wallet.CreditWin(ctx, playerID, wagerAmount, gameID, cycleID+"-refund")
-	if err != nil {
-		return fmt.Errorf("failed to refund wager: %w", err)
-	}
+	err := database.WithTx(ctx, e.db, func(dbTx *sql.Tx) error {
+		// Lock the interrupted cycle
+		var wager int64
+		var currency string
+		var ppToken, ppPlayerID, ppRoundID, ppTransactionID sql.NullString
+		err := dbTx.QueryRowContext(ctx, `
+			SELECT gc.player_id, gc.game_id, gc.session_id, gc.wager_amount, gs.currency,
+				gc.pateplay_session_token, gc.pateplay_player_id, gc.pateplay_round_id, gc.pateplay_transaction_id
+			FROM game_cycles gc
+			JOIN game_sessions gs ON gc.session_id = gs.id
+			WHERE gc.id = $1 AND gc.status = $2
+			FOR UPDATE OF gc
+		`, cycleID, domain.CycleStatusInterrupted).Scan(&playerID, &gameID, &sessionID, &wager, &currency,
+			&ppToken, &ppPlayerID, &ppRoundID, &ppTransactionID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrCycleNotInterrupted
+			}
+			return err
+		}
 
-	now := time.Now().UTC()
+		if ppTransactionID.Valid && ppTransactionID.String != "" {
+			ref := PateplayRef{
+				SessionToken:  ppToken.String,
+				PlayerID:      ppPlayerID.String,
+				RoundID:       ppRoundID.String,
+				TransactionID: ppTransactionID.String,
+			}
+			if err := e.cancelPateplay(ctx, cycleID, playerID, ref); err != nil {
+				return err
+			}
+		}
 
-	// Update cycle status to voided
-	_, err = e.db.ExecContext(ctx, `
-		UPDATE game_cycles SET status = $1, completed_at = $2 WHERE id = $3
-	`, domain.CycleStatusVoided, now, cycleID)
+		// Refund the wager
+		wagerAmount = domain.Money{Amount: wager, Currency: currency}
+		if wager > 0 {
+			if _, err := e.wallet.RefundWagerTx(ctx, dbTx, playerID, wagerAmount, gameID, cycleID); err != nil {
+				return fmt.Errorf("failed to refund wager: %w", err)
+			}
+		}
+		if newBalance, err = e.wallet.GetBalanceTx(ctx, dbTx, playerID); err != nil {
+			return err
+		}
+
+		// Update cycle status to voided
+		_, err = dbTx.ExecContext(ctx, `
+			UPDATE game_cycles SET status = $1, completed_at = $2 WHERE id = $3
+		`, domain.CycleStatusVoided, time.Now().UTC(), cycleID)
+		return err
+	})
 	if err != nil {
 		return err
 	}
+	if wagerAmount.Amount > 0 {
+		e.wallet.PublishBalance(newBalance, domain.TxTypeRefund)
+	}
 
 	// Audit log - GLI-19 §2.8.8
 	e.audit.Log(ctx, "game_voided", domain.SeverityWarning,
 		fmt.Sprintf("Game voided and refunded: %s - %s", cycleID, reason),
 		map[string]interface{}{
-			"cycle_id":     cycleID,
-			"game_id":      gameID,
+			"cycle_id":      cycleID,
+			"game_id":       gameID,
 			"refund_amount": wagerAmount.Float64(),
-			"reason":       reason,
+			"reason":        reason,
 		},
 		audit.WithPlayer(playerID), audit.WithSession(sessionID))
 
 	return nil
 }
 
+// cancelPateplay cancels a voided cycle's Pateplay transaction. A transaction
+// Pateplay does not know was never applied, so there is nothing to cancel.
+func (e *Engine) cancelPateplay(ctx context.Context, cycleID, playerID string, ref PateplayRef) error {
+	if e.pateplay == nil {
+		return fmt.Errorf("cycle %s has a Pateplay transaction but no Pateplay client is configured", cycleID)
+	}
+
+	data := map[string]interface{}{
+		"cycle_id":           cycleID,
+		"rgs_round_id":       ref.RoundID,
+		"rgs_transaction_id": ref.TransactionID,
+	}
+
+	_, err := e.pateplay.Cancel(ctx, ref.SessionToken, ref.PlayerID, ref.RoundID, ref.TransactionID)
+	if err != nil {
+		var apiErr *pateplay.APIError
+		if !errors.As(err, &apiErr) || apiErr.Code != pateplay.ErrTransactionNotFound {
+			return fmt.Errorf("failed to cancel Pateplay transaction: %w", err)
+		}
+		e.audit.Log(ctx, "pateplay_cancel_not_found", domain.SeverityInfo,
+			fmt.Sprintf("Pateplay transaction of voided game %s not found, nothing to cancel", cycleID),
+			data, audit.WithPlayer(playerID), audit.WithComponent("game"))
+		return nil
+	}
+
+	e.audit.Log(ctx, "pateplay_cancelled", domain.SeverityInfo,
+		fmt.Sprintf("Pateplay transaction of voided game %s cancelled", cycleID),
+		data, audit.WithPlayer(playerID), audit.WithComponent("game"))
+	return nil
+}
+
 // MarkInterrupted marks a game cycle as interrupted
 // GLI-19 §4.16 - System must detect and handle interruptions
 func (e *Engine) MarkInterrupted(ctx context.Context, cycleID, reason string) error {
 	_, err := e.db.ExecContext(ctx, `
-		UPDATE game_cycles SET status = $1 WHERE id = $2 AND status = $3
-	`, domain.CycleStatusInterrupted, cycleID, domain.CycleStatusInProgress)
+		UPDATE game_cycles SET status = $1, interrupted_at = $2, interrupt_reason = $3
+		WHERE id = $4 AND status = $5
+	`, domain.CycleStatusInterrupted, time.Now().UTC(), reason, cycleID, domain.CycleStatusInProgress)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,255 @@
+// Package game - Gambling a win on a coin flip before banking it
+package game
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/alexbotov/rgs/internal/domain"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidGambleChoice = errors.New("gamble choice must be heads or tails")
+	ErrCycleNotGambleable  = errors.New("cycle has no unbanked win to gamble")
+	ErrGambleLimitReached  = errors.New("maximum consecutive gambles reached")
+	ErrGambleNotCovered    = errors.New("win to gamble is no longer held in the balance")
+)
+
+// DefaultMaxGambles is how many times in a row a win may be gambled
+const DefaultMaxGambles = 5
+
+// GambleChoice is the side of the coin the player picks
+type GambleChoice string
+
+const (
+	GambleHeads GambleChoice = "heads"
+	GambleTails GambleChoice = "tails"
+)
+
+// GambleStep is one coin flip on a cycle's win, recorded in the outcome
+// GLI-19 §4.14: Game Recall - every step of a game must be recallable
+type GambleStep struct {
+	Choice GambleChoice `json:"choice"`
+	Result GambleChoice `json:"result"`
+	Stake  int64        `json:"stake"` // Win risked, in cents
+	Win    int64        `json:"win"`   // Win after the flip: double the stake, or zero
+}
+
+// GambleResult is the outcome of a gamble on a cycle's win
+type GambleResult struct {
+	CycleID          string       `json:"cycle_id"`
+	Step             GambleStep   `json:"step"`
+	Won              bool         `json:"won"`
+	WinAmount        domain.Money `json:"win_amount"` // The cycle's win after the flip
+	Balance          domain.Money `json:"balance"`
+	GamblesRemaining int          `json:"gambles_remaining"` // Zero once the win is banked
+}
+
+// SetMaxGambles sets how many times in a row a win may be gambled
+func (e *Engine) SetMaxGambles(n int) {
+	e.maxGambles = n
+}
+
+// Gamble risks a cycle's whole win on a coin flip. Guessing the flip doubles
+// the win; missing it takes the win back. Only the latest paid cycle of an
+// active session can be gambled, and only until its win is banked by
+// CollectWin, by playing on, or by reaching the gamble limit. A win already
+// spent or withdrawn is refused with ErrGambleNotCovered.
+func (e *Engine) Gamble(ctx context.Context, cycleID string, choice GambleChoice) (*GambleResult, error) {
+	if choice != GambleHeads && choice != GambleTails {
+		return nil, ErrInvalidGambleChoice
+	}
+
+	done, err := e.beginCycle()
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	cycle, err := e.GetCycle(ctx, cycleID)
+	if err != nil {
+		return nil, err
+	}
+	session, err := e.GetSession(ctx, cycle.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	if session.Status != domain.GameSessionActive {
+		return nil, ErrSessionNotActive
+	}
+	if err := e.checkAccess(ctx, cycle.PlayerID, cycle.GameID); err != nil {
+		return nil, err
+	}
+
+	dbTx, err := e.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer dbTx.Rollback()
+
+	// Lock the cycle so concurrent gambles on it run one at a time
+	var status domain.GameCycleStatus
+	var wager, win int64
+	var banked bool
+	var outcomeJSON string
+	var ppToken, ppPlayerID, ppRoundID, ppTransactionID sql.NullString
+	err = dbTx.QueryRowContext(ctx, `
+		SELECT status, wager_amount, win_amount, win_banked, COALESCE(outcome::text, '{}'),
+			pateplay_session_token, pateplay_player_id, pateplay_round_id, pateplay_transaction_id
+		FROM game_cycles WHERE id = $1 FOR UPDATE
+	`, cycleID).Scan(&status, &wager, &win, &banked, &outcomeJSON,
+		&ppToken, &ppPlayerID, &ppRoundID, &ppTransactionID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrCycleNotFound
+		}
+		return nil, err
+	}
+
+	// Free spins (no wager) are part of a feature and are not gambled
+	if status != domain.CycleStatusCompleted || banked || win <= 0 || wager <= 0 {
+		return nil, ErrCycleNotGambleable
+	}
+
+	var outcome SlotOutcome
+	if err := json.Unmarshal([]byte(outcomeJSON), &outcome); err != nil {
+		return nil, fmt.Errorf("failed to parse outcome: %w", err)
+	}
+	if len(outcome.Gamble) >= e.maxGambles {
+		return nil, ErrGambleLimitReached
+	}
+
+	// A win already spent or withdrawn cannot be staked: losing the flip
+	// must take the whole win back
+	balance, err := e.wallet.GetBalanceTx(ctx, dbTx, cycle.PlayerID)
+	if err != nil {
+		return nil, err
+	}
+	if balance.Available.Amount < win {
+		return nil, ErrGambleNotCovered
+	}
+
+	// Flip the coin (GLI-19 §4.5)
+	flip, err := e.rng.GenerateInt(2)
+	if err != nil {
+		return nil, fmt.Errorf("failed to draw gamble: %w", err)
+	}
+	step := GambleStep{Choice: choice, Result: GambleHeads, Stake: win}
+	if flip == 1 {
+		step.Result = GambleTails
+	}
+	won := step.Result == choice
+	if won {
+		step.Win = 2 * win
+	}
+
+	// A won gamble credits the stake again and a lost one takes it back, on
+	// the same backend that settled the cycle
+	currency := cycle.WagerAmount.Currency
+	settled := &Settlement{
+		CycleID:    cycleID,
+		PlayerID:   cycle.PlayerID,
+		GameID:     cycle.GameID,
+		Wager:      domain.Money{Amount: win, Currency: currency},
+		Win:        domain.Money{Amount: 0, Currency: currency},
+		GambleStep: len(outcome.Gamble) + 1,
+	}
+	if won {
+		settled.Wager, settled.Win = settled.Win, settled.Wager
+	}
+	if ppTransactionID.Valid && ppTransactionID.String != "" {
+		settled.Pateplay = &PateplayRef{
+			SessionToken:  ppToken.String,
+			PlayerID:      ppPlayerID.String,
+			RoundID:       ppRoundID.String,
+			TransactionID: ppTransactionID.String,
+		}
+	}
+	if err := e.settlement.Settle(ctx, dbTx, settled); err != nil {
+		// The limit is audited once the step's transaction has ended
+		dbTx.Rollback()
+		return nil, e.translatePateplayLimit(ctx, cycle.PlayerID, settled.Wager, err)
+	}
+	outcome.Gamble = append(outcome.Gamble, step)
+
+	// A lost gamble leaves nothing to gamble; the limit banks what is left
+	remaining := e.maxGambles - len(outcome.Gamble)
+	if !won {
+		remaining = 0
+	}
+
+	newBalance, err := e.finishGamble(ctx, dbTx, cycle, outcome, step.Win-win, remaining == 0)
+	if err != nil {
+		e.cancelSettlement(ctx, settled)
+		return nil, err
+	}
+	if won {
+		e.wallet.PublishBalance(newBalance, domain.TxTypeWin)
+	} else {
+		e.wallet.PublishBalance(newBalance, domain.TxTypeWager)
+	}
+
+	return &GambleResult{
+		CycleID:          cycleID,
+		Step:             step,
+		Won:              won,
+		WinAmount:        domain.Money{Amount: step.Win, Currency: currency},
+		Balance:          newBalance.Available,
+		GamblesRemaining: remaining,
+	}, nil
+}
+
+// finishGamble records a settled gamble step on its cycle and session and
+// commits dbTx, returning the player's balance after the step
+func (e *Engine) finishGamble(ctx context.Context, dbTx *sql.Tx, cycle *domain.GameCycle, outcome SlotOutcome, change int64, banked bool) (*domain.Balance, error) {
+	newBalance, err := e.wallet.GetBalanceTx(ctx, dbTx, cycle.PlayerID)
+	if err != nil {
+		return nil, err
+	}
+
+	win := outcome.Gamble[len(outcome.Gamble)-1].Win
+	data, _ := json.Marshal(outcome)
+	_, err = dbTx.ExecContext(ctx, `
+		UPDATE game_cycles SET win_amount = $1, balance_after = $2, outcome = $3, win_banked = $4
+		WHERE id = $5
+	`, win, newBalance.Available.Amount, string(data), banked, cycle.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = dbTx.ExecContext(ctx, `
+		UPDATE game_sessions SET last_activity_at = $1, current_balance = $2, total_won = total_won + $3
+		WHERE id = $4
+	`, time.Now().UTC(), newBalance.Available.Amount, change, cycle.SessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := dbTx.Commit(); err != nil {
+		return nil, err
+	}
+	return newBalance, nil
+}
+
+// CollectWin banks a cycle's win so it can no longer be gambled
+func (e *Engine) CollectWin(ctx context.Context, cycleID string) error {
+	if _, err := uuid.Parse(cycleID); err != nil {
+		return ErrCycleNotFound
+	}
+
+	result, err := e.db.ExecContext(ctx, `
+		UPDATE game_cycles SET win_banked = true WHERE id = $1
+	`, cycleID)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrCycleNotFound
+	}
+	return nil
+}
@@ -2,47 +2,53 @@ package game
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/alexbotov/rgs/internal/audit"
-	"github.com/alexbotov/rgs/internal/database"
+	"github.com/alexbotov/rgs/internal/auth"
+	"github.com/alexbotov/rgs/internal/config"
+	"github.com/alexbotov/rgs/internal/control"
+	"github.com/alexbotov/rgs/internal/database/dbtest"
 	"github.com/alexbotov/rgs/internal/domain"
+	"github.com/alexbotov/rgs/internal/limits"
 	"github.com/alexbotov/rgs/internal/rng"
 	"github.com/alexbotov/rgs/internal/wallet"
+	"github.com/alexbotov/rgs/pkg/pateplay"
 	"github.com/google/uuid"
 )
 
+func TestMain(m *testing.M) {
+	dbtest.Main(m)
+}
+
 func setupTestEngine(t *testing.T) (*Engine, string, func()) {
 	t.Helper()
 
-	// Create PostgreSQL connection
-	db, err := database.New("postgres", "host=localhost dbname=rgs sslmode=disable")
-	if err != nil {
-		t.Fatalf("Failed to create database: %v", err)
-	}
-
-	// Ensure schema exists (idempotent)
-	if err := db.Migrate(); err != nil {
-		// Ignore if tables already exist
-		t.Logf("Migration note: %v", err)
-	}
-
-	// Clean data for fresh test state
-	if err := db.CleanData(); err != nil {
-		t.Fatalf("Failed to clean data: %v", err)
-	}
+	db := dbtest.Open(t)
 
 	// Create services
 	auditSvc := audit.New(db.DB)
 	rngSvc := rng.New()
 	walletSvc := wallet.New(db.DB, auditSvc, "USD")
+	limitsSvc := limits.New(db.DB, auditSvc, "USD")
 
 	// Create engine
-	engine := New(db.DB, rngSvc, walletSvc, auditSvc, "USD")
+	engine := New(db.DB, rngSvc, walletSvc, limitsSvc, auditSvc, "USD")
 
 	// Create a test player
 	playerID := uuid.New().String()
-	_, err = db.DB.Exec(`
+	_, err := db.DB.Exec(`
 		INSERT INTO players (id, username, email, password_hash, status, registration_date, tc_accepted_at, created_at, updated_at)
 		VALUES ($1, 'testplayer', 'test@example.com', 'hash', 'active', NOW(), NOW(), NOW(), NOW())
 	`, playerID)
@@ -62,10 +68,160 @@ func setupTestEngine(t *testing.T) (*Engine, string, func()) {
 	// Fund the player
 	walletSvc.Deposit(context.Background(), playerID, domain.NewMoney(1000.00, "USD"), "test-funding")
 
-	return engine, playerID, func() {
-		db.CleanData()
-		db.Close()
+	return engine, playerID, func() {}
+}
+
+// fakeWallet is an in-memory wallet.Wallet for engine tests that do not need
+// Postgres; database transactions passed to it are ignored
+type fakeWallet struct {
+	balances map[string]int64
+	ledger   []domain.Transaction
+}
+
+func newFakeWallet() *fakeWallet {
+	return &fakeWallet{balances: make(map[string]int64)}
+}
+
+func (f *fakeWallet) move(playerID string, txType domain.TransactionType, delta int64, amount domain.Money, cycleID string) (*domain.Transaction, error) {
+	if amount.Amount < 0 {
+		return nil, wallet.ErrInvalidAmount
+	}
+	before := f.balances[playerID]
+	if before+delta < 0 {
+		return nil, wallet.ErrInsufficientFunds
+	}
+	f.balances[playerID] = before + delta
+	tx := domain.Transaction{
+		ID:            uuid.New().String(),
+		PlayerID:      playerID,
+		Type:          txType,
+		Amount:        amount,
+		BalanceBefore: domain.Money{Amount: before, Currency: amount.Currency},
+		BalanceAfter:  domain.Money{Amount: before + delta, Currency: amount.Currency},
+		Status:        domain.TxStatusCompleted,
+		Reference:     cycleID,
+		CreatedAt:     time.Now().UTC(),
+	}
+	f.ledger = append(f.ledger, tx)
+	return &tx, nil
+}
+
+func (f *fakeWallet) GetBalance(ctx context.Context, playerID string) (*domain.Balance, error) {
+	amount := domain.Money{Amount: f.balances[playerID], Currency: "USD"}
+	return &domain.Balance{PlayerID: playerID, RealMoney: amount, Available: amount, Currency: "USD"}, nil
+}
+
+func (f *fakeWallet) PlaceWager(ctx context.Context, playerID string, amount domain.Money, gameID, cycleID string) (*domain.Transaction, error) {
+	return f.move(playerID, domain.TxTypeWager, -amount.Amount, amount, cycleID)
+}
+
+func (f *fakeWallet) CreditWin(ctx context.Context, playerID string, amount domain.Money, gameID, cycleID string) (*domain.Transaction, error) {
+	return f.move(playerID, domain.TxTypeWin, amount.Amount, amount, cycleID)
+}
+
+func (f *fakeWallet) RefundWager(ctx context.Context, playerID string, amount domain.Money, gameID, cycleID string) (*domain.Transaction, error) {
+	return f.move(playerID, domain.TxTypeRefund, amount.Amount, amount, cycleID)
+}
+
+func (f *fakeWallet) GetBalanceTx(ctx context.Context, dbTx *sql.Tx, playerID string) (*domain.Balance, error) {
+	return f.GetBalance(ctx, playerID)
+}
+
+func (f *fakeWallet) PlaceWagerTx(ctx context.Context, dbTx *sql.Tx, playerID string, amount domain.Money, gameID, cycleID string) (*domain.Transaction, error) {
+	return f.PlaceWager(ctx, playerID, amount, gameID, cycleID)
+}
+
+func (f *fakeWallet) CreditWinTx(ctx context.Context, dbTx *sql.Tx, playerID string, amount domain.Money, gameID, cycleID string) (*domain.Transaction, error) {
+	return f.CreditWin(ctx, playerID, amount, gameID, cycleID)
+}
+
+func (f *fakeWallet) RefundWagerTx(ctx context.Context, dbTx *sql.Tx, playerID string, amount domain.Money, gameID, cycleID string) (*domain.Transaction, error) {
+	return f.RefundWager(ctx, playerID, amount, gameID, cycleID)
+}
+
+func (f *fakeWallet) GambleTx(ctx context.Context, dbTx *sql.Tx, playerID string, stake domain.Money, won bool, gameID, cycleID string, step int) (*domain.Transaction, error) {
+	if won {
+		return f.move(playerID, domain.TxTypeWin, stake.Amount, stake, cycleID)
+	}
+	return f.move(playerID, domain.TxTypeWager, -stake.Amount, stake, cycleID)
+}
+
+func (f *fakeWallet) CreditJackpotTx(ctx context.Context, dbTx *sql.Tx, playerID string, amount domain.Money, gameID, cycleID string) (*domain.Transaction, error) {
+	return f.move(playerID, domain.TxTypeJackpot, amount.Amount, amount, cycleID)
+}
+
+func (f *fakeWallet) RecordJackpotContributionTx(ctx context.Context, dbTx *sql.Tx, playerID string, amount domain.Money, gameID, cycleID string) (*domain.Transaction, error) {
+	return nil, nil
+}
+
+func (f *fakeWallet) PublishBalance(balance *domain.Balance, txType domain.TransactionType) {}
+
+func (f *fakeWallet) ConvertBonus(ctx context.Context, playerID string) (*domain.Transaction, error) {
+	return nil, wallet.ErrNoBonus
+}
+
+func TestPlayRoundFakeWallet(t *testing.T) {
+	ctx := context.Background()
+	fake := newFakeWallet()
+	playerID := uuid.New().String()
+	fake.balances[playerID] = 100000
+
+	// No database: the round is drawn and settled against the fake wallet
+	engine := New(nil, rng.New(), fake, nil, nil, "USD")
+	game, err := engine.GetGame("fortune-slots")
+	if err != nil {
+		t.Fatalf("Failed to get game: %v", err)
+	}
+
+	wager := domain.Money{Amount: 100, Currency: "USD"}
+	expected := fake.balances[playerID]
+	for i := 0; i < 100; i++ {
+		outcome, err := engine.generateSlotOutcome(ctx, game)
+		if err != nil {
+			t.Fatalf("Failed to generate outcome: %v", err)
+		}
+		win := engine.calculateWin(outcome, wager)
+
+		cycleID := uuid.New().String()
+		err = engine.settlement.Settle(ctx, nil, &Settlement{
+			CycleID:  cycleID,
+			PlayerID: playerID,
+			GameID:   game.ID,
+			Wager:    wager,
+			Win:      win,
+		})
+		if err != nil {
+			t.Fatalf("Failed to settle round: %v", err)
+		}
+		expected += win.Amount - wager.Amount
+	}
+
+	if fake.balances[playerID] != expected {
+		t.Errorf("Expected balance %d after 100 rounds, got %d", expected, fake.balances[playerID])
+	}
+	wagers := 0
+	for _, tx := range fake.ledger {
+		if tx.Type == domain.TxTypeWager {
+			wagers++
+		}
+	}
+	if wagers != 100 {
+		t.Errorf("Expected 100 wagers in the ledger, got %d", wagers)
 	}
+
+	t.Run("InsufficientFunds", func(t *testing.T) {
+		broke := uuid.New().String()
+		err := engine.settlement.Settle(ctx, nil, &Settlement{
+			CycleID:  uuid.New().String(),
+			PlayerID: broke,
+			GameID:   game.ID,
+			Wager:    wager,
+			Win:      domain.Money{Currency: "USD"},
+		})
+		if !errors.Is(err, wallet.ErrInsufficientFunds) {
+			t.Errorf("Expected ErrInsufficientFunds, got %v", err)
+		}
+	})
 }
 
 func TestGetGames(t *testing.T) {
@@ -159,6 +315,110 @@ func TestStartSession(t *testing.T) {
 	})
 }
 
+func TestStartSessionConflict(t *testing.T) {
+	engine, playerID, cleanup := setupTestEngine(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	first, err := engine.StartSession(ctx, playerID, "fortune-slots")
+	if err != nil {
+		t.Fatalf("Failed to start session: %v", err)
+	}
+
+	t.Run("RejectsSecondSession", func(t *testing.T) {
+		_, err := engine.StartSession(ctx, playerID, "fortune-slots")
+		if err != ErrSessionAlreadyActive {
+			t.Errorf("Expected ErrSessionAlreadyActive, got %v", err)
+		}
+	})
+
+	t.Run("OtherGameAllowed", func(t *testing.T) {
+		if _, err := engine.StartSession(ctx, playerID, "bonus-slots"); err != nil {
+			t.Errorf("Expected session for another game, got %v", err)
+		}
+	})
+
+	t.Run("EndsStaleSession", func(t *testing.T) {
+		engine.SetSessionConflictPolicy(SessionConflictEndStale)
+		defer engine.SetSessionConflictPolicy(SessionConflictReject)
+
+		second, err := engine.StartSession(ctx, playerID, "fortune-slots")
+		if err != nil {
+			t.Fatalf("Expected stale session to be replaced, got %v", err)
+		}
+
+		old, err := engine.GetSession(ctx, first.ID)
+		if err != nil {
+			t.Fatalf("Failed to get old session: %v", err)
+		}
+		if old.Status != domain.GameSessionCompleted {
+			t.Errorf("Expected old session completed, got %s", old.Status)
+		}
+		if second.Status != domain.GameSessionActive {
+			t.Errorf("Expected new session active, got %s", second.Status)
+		}
+	})
+}
+
+func TestPlayRespectsControl(t *testing.T) {
+	engine, playerID, cleanup := setupTestEngine(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	authSvc := auth.New(engine.db, &config.AuthConfig{JWTSecret: "test-secret"}, engine.audit, nil)
+	controlSvc := control.New(engine.db, engine.audit, authSvc)
+	engine.SetControl(controlSvc)
+
+	session, err := engine.StartSession(ctx, playerID, "fortune-slots")
+	if err != nil {
+		t.Fatalf("Failed to start session: %v", err)
+	}
+	if _, err := engine.Play(ctx, &PlayRequest{SessionID: session.ID, WagerAmount: 100}); err != nil {
+		t.Fatalf("Play failed: %v", err)
+	}
+
+	t.Run("GamingDisabledMidSession", func(t *testing.T) {
+		controlSvc.DisableAllGaming(ctx, "Maintenance", "admin")
+		defer controlSvc.EnableAllGaming(ctx, "admin")
+
+		_, err := engine.Play(ctx, &PlayRequest{SessionID: session.ID, WagerAmount: 100})
+		if err != control.ErrGamingDisabled {
+			t.Errorf("Expected ErrGamingDisabled, got %v", err)
+		}
+	})
+
+	t.Run("GameDisabledMidSession", func(t *testing.T) {
+		controlSvc.DisableGame(ctx, "fortune-slots", "Maintenance", "admin")
+		defer controlSvc.EnableGame(ctx, "fortune-slots", "admin")
+
+		_, err := engine.Play(ctx, &PlayRequest{SessionID: session.ID, WagerAmount: 100})
+		if err != control.ErrGameDisabled {
+			t.Errorf("Expected ErrGameDisabled, got %v", err)
+		}
+	})
+
+	t.Run("PlayerSuspended", func(t *testing.T) {
+		controlSvc.DisablePlayer(ctx, playerID, "Review", "admin")
+		defer controlSvc.EnablePlayer(ctx, playerID, "admin")
+
+		_, err := engine.Play(ctx, &PlayRequest{SessionID: session.ID, WagerAmount: 100})
+		if err != control.ErrPlayerDisabled {
+			t.Errorf("Expected ErrPlayerDisabled, got %v", err)
+		}
+		if _, err := engine.StartSession(ctx, playerID, "bonus-slots"); err != control.ErrPlayerDisabled {
+			t.Errorf("Expected StartSession to be refused, got %v", err)
+		}
+	})
+
+	t.Run("PlayResumesWhenEnabled", func(t *testing.T) {
+		if _, err := engine.Play(ctx, &PlayRequest{SessionID: session.ID, WagerAmount: 100}); err != nil {
+			t.Errorf("Expected play to resume, got %v", err)
+		}
+	})
+}
+
 func TestPlay(t *testing.T) {
 	engine, playerID, cleanup := setupTestEngine(t)
 	defer cleanup()
@@ -248,341 +508,2818 @@ func TestPlay(t *testing.T) {
 	})
 }
 
-func TestEndSession(t *testing.T) {
+func TestPlaySessionTotals(t *testing.T) {
 	engine, playerID, cleanup := setupTestEngine(t)
 	defer cleanup()
 
 	ctx := context.Background()
+	session, err := engine.StartSession(ctx, playerID, "fortune-slots")
+	if err != nil {
+		t.Fatalf("Failed to start session: %v", err)
+	}
 
-	// Start a session and play some games
-	session, _ := engine.StartSession(ctx, playerID, "fortune-slots")
-	engine.Play(ctx, &PlayRequest{
-		SessionID:   session.ID,
-		WagerAmount: 100,
-	})
-
-	t.Run("SuccessfulEndSession", func(t *testing.T) {
-		endedSession, err := engine.EndSession(ctx, session.ID)
+	var wagered, won int64
+	for i, amount := range []int64{100, 250, 500, 100, 1000} {
+		result, err := engine.Play(ctx, &PlayRequest{SessionID: session.ID, WagerAmount: amount})
 		if err != nil {
-			t.Fatalf("Failed to end session: %v", err)
+			t.Fatalf("Play %d failed: %v", i+1, err)
 		}
+		wagered += result.WagerAmount.Amount
+		won += result.WinAmount.Amount
 
-		if endedSession.GamesPlayed < 1 {
-			t.Error("Expected at least 1 game played")
+		if result.Session == nil {
+			t.Fatalf("Play %d: expected session totals", i+1)
 		}
-		if endedSession.Status != domain.GameSessionCompleted {
-			t.Errorf("Expected completed status, got '%s'", endedSession.Status)
+		if result.Session.Wagered.Amount != wagered || result.Session.Won.Amount != won {
+			t.Errorf("Play %d: expected %d wagered and %d won, got %d and %d",
+				i+1, wagered, won, result.Session.Wagered.Amount, result.Session.Won.Amount)
 		}
-	})
-
-	t.Run("PlayAfterEndedSession", func(t *testing.T) {
-		_, err := engine.Play(ctx, &PlayRequest{
-			SessionID:   session.ID,
-			WagerAmount: 100,
-		})
-
-		if err == nil {
-			t.Error("Expected error for playing on ended session")
+		if net := result.Session.Net(); net.Amount != won-wagered || net.Currency != result.WagerAmount.Currency {
+			t.Errorf("Play %d: expected net %d, got %v", i+1, won-wagered, net)
 		}
-	})
+	}
+
+	stored, err := engine.GetSession(ctx, session.ID)
+	if err != nil {
+		t.Fatalf("Failed to get session: %v", err)
+	}
+	if stored.TotalWagered.Amount != wagered || stored.TotalWon.Amount != won {
+		t.Errorf("Expected stored totals %d/%d, got %d/%d", wagered, won, stored.TotalWagered.Amount, stored.TotalWon.Amount)
+	}
 }
 
-func TestGetHistory(t *testing.T) {
+func TestPlayRollsBackOnFailure(t *testing.T) {
 	engine, playerID, cleanup := setupTestEngine(t)
 	defer cleanup()
 
 	ctx := context.Background()
 
-	// Start session and play games
-	session, _ := engine.StartSession(ctx, playerID, "fortune-slots")
-	for i := 0; i < 5; i++ {
-		engine.Play(ctx, &PlayRequest{
-			SessionID:   session.ID,
-			WagerAmount: 100,
-		})
+	session, err := engine.StartSession(ctx, playerID, "fortune-slots")
+	if err != nil {
+		t.Fatalf("Failed to start session: %v", err)
 	}
+	before, _ := engine.wallet.GetBalance(ctx, playerID)
 
-	t.Run("GetHistory", func(t *testing.T) {
-		history, err := engine.GetHistory(ctx, playerID, 10)
-		if err != nil {
-			t.Fatalf("Failed to get history: %v", err)
-		}
+	// Fail the session stats update, the last step of a play, after the wager
+	// has been debited and the cycle recorded
+	_, err = engine.db.ExecContext(ctx, `
+		CREATE OR REPLACE FUNCTION fail_play_test() RETURNS trigger AS $$
+		BEGIN
+			RAISE EXCEPTION 'injected failure';
+		END;
+		$$ LANGUAGE plpgsql;
+		CREATE TRIGGER fail_play_test BEFORE UPDATE ON game_sessions
+			FOR EACH ROW WHEN (NEW.games_played > OLD.games_played) EXECUTE FUNCTION fail_play_test();
+	`)
+	if err != nil {
+		t.Fatalf("Failed to install failing trigger: %v", err)
+	}
+	defer engine.db.ExecContext(ctx, `
+		DROP TRIGGER IF EXISTS fail_play_test ON game_sessions;
+		DROP FUNCTION IF EXISTS fail_play_test();
+	`)
 
-		if len(history) != 5 {
-			t.Errorf("Expected 5 history entries, got %d", len(history))
-		}
+	if _, err := engine.Play(ctx, &PlayRequest{SessionID: session.ID, WagerAmount: 500}); err == nil {
+		t.Fatal("Expected play to fail")
+	}
 
-		// Check history is ordered (most recent first)
-		for i := 1; i < len(history); i++ {
-			if history[i].PlayedAt.After(history[i-1].PlayedAt) {
-				t.Error("History should be ordered most recent first")
-			}
-		}
-	})
+	after, _ := engine.wallet.GetBalance(ctx, playerID)
+	if after.Available.Amount != before.Available.Amount {
+		t.Errorf("Expected balance %d to be unchanged, got %d", before.Available.Amount, after.Available.Amount)
+	}
 
-	t.Run("LimitHistory", func(t *testing.T) {
-		history, _ := engine.GetHistory(ctx, playerID, 3)
-		if len(history) != 3 {
-			t.Errorf("Expected 3 history entries with limit, got %d", len(history))
-		}
-	})
+	var cycles, wagers int
+	engine.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM game_cycles WHERE session_id = $1", session.ID).Scan(&cycles)
+	if cycles != 0 {
+		t.Errorf("Expected no game cycle, got %d", cycles)
+	}
+	engine.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM transactions WHERE player_id = $1 AND type = $2",
+		playerID, domain.TxTypeWager).Scan(&wagers)
+	if wagers != 0 {
+		t.Errorf("Expected no wager transaction, got %d", wagers)
+	}
 }
 
-// ============================================================================
-// Interrupted Games Tests (GLI-19 §4.16)
-// ============================================================================
-
-func TestGetInterruptedGames(t *testing.T) {
+func TestPlayOutcomeFailure(t *testing.T) {
 	engine, playerID, cleanup := setupTestEngine(t)
 	defer cleanup()
 
 	ctx := context.Background()
 
-	t.Run("NoInterruptedGames", func(t *testing.T) {
-		interrupted, err := engine.GetInterruptedGames(ctx, playerID)
-		if err != nil {
-			t.Fatalf("Failed to get interrupted games: %v", err)
-		}
+	session, err := engine.StartSession(ctx, playerID, "fortune-slots")
+	if err != nil {
+		t.Fatalf("Failed to start session: %v", err)
+	}
+	before, _ := engine.wallet.GetBalance(ctx, playerID)
 
-		if len(interrupted) != 0 {
-			t.Errorf("Expected 0 interrupted games, got %d", len(interrupted))
+	// Without reel strips the RNG cannot produce an outcome
+	delete(engine.definitions, "fortune-slots")
+
+	_, err = engine.Play(ctx, &PlayRequest{SessionID: session.ID, WagerAmount: 500})
+	if !errors.Is(err, ErrGameNotFound) {
+		t.Fatalf("Expected outcome generation to fail, got %v", err)
+	}
+
+	after, _ := engine.wallet.GetBalance(ctx, playerID)
+	if after.Available.Amount != before.Available.Amount {
+		t.Errorf("Expected balance %d to be restored, got %d", before.Available.Amount, after.Available.Amount)
+	}
+
+	// The wager is rolled back rather than paid back as a win
+	txs, _ := engine.wallet.(*wallet.Service).GetTransactions(ctx, playerID, 10)
+	for _, tx := range txs {
+		if tx.Type != domain.TxTypeDeposit {
+			t.Errorf("Expected only the funding deposit in the ledger, got %s of %d", tx.Type, tx.Amount.Amount)
 		}
-	})
+	}
+
+	var cycles int
+	engine.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM game_cycles WHERE session_id = $1", session.ID).Scan(&cycles)
+	if cycles != 0 {
+		t.Errorf("Expected no game cycle, got %d", cycles)
+	}
 }
 
-func TestMarkInterrupted(t *testing.T) {
+func TestLargeWinThreshold(t *testing.T) {
+	money := func(amount int64) domain.Money { return domain.Money{Amount: amount, Currency: "USD"} }
+
+	tests := []struct {
+		name      string
+		threshold LargeWinThreshold
+		win       int64
+		stake     int64
+		large     bool
+	}{
+		{"AtAmount", LargeWinThreshold{Amount: 10000}, 10000, 100, true},
+		{"BelowAmount", LargeWinThreshold{Amount: 10000}, 9999, 100, false},
+		{"AtMultiple", LargeWinThreshold{Multiple: 50}, 5000, 100, true},
+		{"BelowMultiple", LargeWinThreshold{Multiple: 50}, 4999, 100, false},
+		{"EitherCrossed", LargeWinThreshold{Amount: 100000, Multiple: 50}, 5000, 100, true},
+		{"Disabled", LargeWinThreshold{}, 1000000, 100, false},
+		{"NoWin", LargeWinThreshold{Amount: 1, Multiple: 1}, 0, 100, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.threshold.isLarge(money(tt.win), money(tt.stake)); got != tt.large {
+				t.Errorf("Expected large=%v for win %d on stake %d, got %v", tt.large, tt.win, tt.stake, got)
+			}
+		})
+	}
+}
+
+func TestPlayLargeWinAudit(t *testing.T) {
 	engine, playerID, cleanup := setupTestEngine(t)
 	defer cleanup()
 
 	ctx := context.Background()
 
-	// Start session
 	session, err := engine.StartSession(ctx, playerID, "fortune-slots")
 	if err != nil {
 		t.Fatalf("Failed to start session: %v", err)
 	}
 
-	// Create a game cycle in progress (simulate by inserting directly)
-	cycleID := uuid.New().String()
-	_, err = engine.db.ExecContext(ctx, `
-		INSERT INTO game_cycles (id, session_id, player_id, game_id, started_at, wager_amount, win_amount, balance_before, balance_after, outcome, status, currency)
-		VALUES ($1, $2, $3, $4, NOW(), 100, 0, 100000, 99900, '{"reels":["7","7","7"]}', $5, 'USD')
-	`, cycleID, session.ID, playerID, "fortune-slots", domain.CycleStatusInProgress)
-	if err != nil {
-		t.Fatalf("Failed to create in-progress cycle: %v", err)
+	// playUntilWin plays minimum bets until a cycle pays out
+	playUntilWin := func(t *testing.T) {
+		t.Helper()
+		for i := 0; i < 500; i++ {
+			result, err := engine.Play(ctx, &PlayRequest{SessionID: session.ID, WagerAmount: 10})
+			if err != nil {
+				t.Fatalf("Play failed: %v", err)
+			}
+			if result.WinAmount.Amount > 0 {
+				return
+			}
+		}
+		t.Fatal("No win in 500 plays")
 	}
-
-	t.Run("MarkAsInterrupted", func(t *testing.T) {
-		err := engine.MarkInterrupted(ctx, cycleID, "Connection lost")
+	largeWins := func() int {
+		events, err := engine.audit.GetEvents(ctx, &audit.EventFilter{PlayerID: playerID, Type: audit.EventLargeWin})
 		if err != nil {
-			t.Fatalf("Failed to mark as interrupted: %v", err)
+			t.Fatalf("Failed to get audit events: %v", err)
 		}
+		return len(events)
+	}
 
-		// Verify cycle is now interrupted
-		var status domain.GameCycleStatus
-		engine.db.QueryRowContext(ctx, "SELECT status FROM game_cycles WHERE id = $1", cycleID).Scan(&status)
-
-		if status != domain.CycleStatusInterrupted {
-			t.Errorf("Expected status 'interrupted', got '%s'", status)
+	t.Run("HighThreshold", func(t *testing.T) {
+		engine.SetLargeWinThreshold(LargeWinThreshold{Amount: 1 << 40})
+		playUntilWin(t)
+		if n := largeWins(); n != 0 {
+			t.Errorf("Expected no large win events, got %d", n)
 		}
 	})
 
-	t.Run("GetInterruptedGames", func(t *testing.T) {
-		interrupted, err := engine.GetInterruptedGames(ctx, playerID)
-		if err != nil {
-			t.Fatalf("Failed to get interrupted games: %v", err)
+	t.Run("LowThreshold", func(t *testing.T) {
+		engine.SetLargeWinThreshold(LargeWinThreshold{Amount: 1})
+		playUntilWin(t)
+		if n := largeWins(); n != 1 {
+			t.Errorf("Expected 1 large win event, got %d", n)
 		}
+	})
+}
 
-		if len(interrupted) != 1 {
-			t.Errorf("Expected 1 interrupted game, got %d", len(interrupted))
+func TestEndSession(t *testing.T) {
+	engine, playerID, cleanup := setupTestEngine(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	// Start a session and play some games
+	session, _ := engine.StartSession(ctx, playerID, "fortune-slots")
+	engine.Play(ctx, &PlayRequest{
+		SessionID:   session.ID,
+		WagerAmount: 100,
+	})
+
+	t.Run("SuccessfulEndSession", func(t *testing.T) {
+		endedSession, err := engine.EndSession(ctx, session.ID)
+		if err != nil {
+			t.Fatalf("Failed to end session: %v", err)
 		}
 
-		if interrupted[0].CycleID != cycleID {
-			t.Errorf("Expected cycle ID %s, got %s", cycleID, interrupted[0].CycleID)
+		if endedSession.GamesPlayed < 1 {
+			t.Error("Expected at least 1 game played")
+		}
+		if endedSession.Status != domain.GameSessionCompleted {
+			t.Errorf("Expected completed status, got '%s'", endedSession.Status)
+		}
+	})
+
+	t.Run("PlayAfterEndedSession", func(t *testing.T) {
+		_, err := engine.Play(ctx, &PlayRequest{
+			SessionID:   session.ID,
+			WagerAmount: 100,
+		})
+
+		if err == nil {
+			t.Error("Expected error for playing on ended session")
 		}
 	})
 }
 
-func TestVoidGame(t *testing.T) {
+func TestEndActiveSessions(t *testing.T) {
 	engine, playerID, cleanup := setupTestEngine(t)
 	defer cleanup()
 
 	ctx := context.Background()
+	loginID := uuid.New().String()
+	loginCtx := WithLoginSession(ctx, loginID)
 
-	// Start session
-	session, _ := engine.StartSession(ctx, playerID, "fortune-slots")
+	played, err := engine.StartSession(loginCtx, playerID, "fortune-slots")
+	if err != nil {
+		t.Fatalf("Failed to start session: %v", err)
+	}
+	if _, err := engine.Play(ctx, &PlayRequest{SessionID: played.ID, WagerAmount: 100}); err != nil {
+		t.Fatalf("Play failed: %v", err)
+	}
 
-	// Create an interrupted game cycle
+	// The second session has a cycle left in progress
+	dangling, err := engine.StartSession(loginCtx, playerID, "lucky-sevens")
+	if err != nil {
+		t.Fatalf("Failed to start session: %v", err)
+	}
 	cycleID := uuid.New().String()
-	_, err := engine.db.ExecContext(ctx, `
+	_, err = engine.db.ExecContext(ctx, `
 		INSERT INTO game_cycles (id, session_id, player_id, game_id, started_at, wager_amount, win_amount, balance_before, balance_after, outcome, status, currency)
-		VALUES ($1, $2, $3, $4, NOW(), 500, 0, 100000, 99500, '{"reels":["7","BAR","CHERRY"]}', $5, 'USD')
-	`, cycleID, session.ID, playerID, "fortune-slots", domain.CycleStatusInterrupted)
+		VALUES ($1, $2, $3, $4, NOW(), 100, 0, 100000, 99900, '{"reels":["7","7","7"]}', $5, 'USD')
+	`, cycleID, dangling.ID, playerID, "lucky-sevens", domain.CycleStatusInProgress)
 	if err != nil {
-		t.Fatalf("Failed to create interrupted cycle: %v", err)
+		t.Fatalf("Failed to create in-progress cycle: %v", err)
 	}
 
-	// Deduct the wager from balance (simulate what happened before interruption)
+	// A session opened under another login stays active
+	otherID := uuid.New().String()
 	_, err = engine.db.ExecContext(ctx, `
-		UPDATE balances SET real_money_amount = real_money_amount - 500 WHERE player_id = $1
-	`, playerID)
+		INSERT INTO game_sessions (id, player_id, game_id, started_at, last_activity_at, status, opening_balance, current_balance, currency, login_session_id)
+		VALUES ($1, $2, 'fortune-slots', NOW(), NOW(), $3, 100000, 100000, 'USD', $4)
+	`, otherID, playerID, domain.GameSessionActive, uuid.New().String())
 	if err != nil {
-		t.Fatalf("Failed to deduct balance: %v", err)
+		t.Fatalf("Failed to create session of another login: %v", err)
 	}
 
-	t.Run("VoidAndRefund", func(t *testing.T) {
-		// Get balance before void
-		balBefore, _ := engine.wallet.GetBalance(ctx, playerID)
+	ended, err := engine.EndActiveSessions(ctx, playerID, loginID)
+	if err != nil {
+		t.Fatalf("Failed to end active sessions: %v", err)
+	}
+	if ended != 2 {
+		t.Errorf("Expected 2 sessions ended, got %d", ended)
+	}
 
-		err := engine.VoidGame(ctx, cycleID, "Player requested void")
+	for id, want := range map[string]domain.GameSessionStatus{
+		played.ID:   domain.GameSessionCompleted,
+		dangling.ID: domain.GameSessionInterrupted,
+		otherID:     domain.GameSessionActive,
+	} {
+		session, err := engine.GetSession(ctx, id)
 		if err != nil {
-			t.Fatalf("Failed to void game: %v", err)
+			t.Fatalf("Failed to get session: %v", err)
+		}
+		if session.Status != want || (session.EndedAt == nil) != (want == domain.GameSessionActive) {
+			t.Errorf("Expected session %s ended as %s, got %s (ended %v)", id, want, session.Status, session.EndedAt)
 		}
+	}
 
-		// Verify cycle is now voided
-		var status domain.GameCycleStatus
-		engine.db.QueryRowContext(ctx, "SELECT status FROM game_cycles WHERE id = $1", cycleID).Scan(&status)
+	interrupted, err := engine.GetInterruptedGames(ctx, playerID)
+	if err != nil {
+		t.Fatalf("Failed to get interrupted games: %v", err)
+	}
+	if len(interrupted) != 1 || interrupted[0].CycleID != cycleID || interrupted[0].Reason != InterruptReasonLogout {
+		t.Errorf("Expected the in-progress cycle interrupted at logout, got %+v", interrupted)
+	}
 
-		if status != domain.CycleStatusVoided {
-			t.Errorf("Expected status 'voided', got '%s'", status)
+	// Nothing is left to end
+	if ended, err := engine.EndActiveSessions(ctx, playerID, loginID); err != nil || ended != 0 {
+		t.Errorf("Expected no sessions ended on second call, got %d (%v)", ended, err)
+	}
+}
+
+func TestGetHistory(t *testing.T) {
+	engine, playerID, cleanup := setupTestEngine(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	// Start session and play games
+	session, _ := engine.StartSession(ctx, playerID, "fortune-slots")
+	for i := 0; i < 5; i++ {
+		engine.Play(ctx, &PlayRequest{
+			SessionID:   session.ID,
+			WagerAmount: 100,
+		})
+	}
+
+	t.Run("GetHistory", func(t *testing.T) {
+		history, err := engine.GetHistory(ctx, playerID, 10)
+		if err != nil {
+			t.Fatalf("Failed to get history: %v", err)
 		}
 
-		// Verify balance was refunded
-		balAfter, _ := engine.wallet.GetBalance(ctx, playerID)
-		if balAfter.RealMoney.Amount != balBefore.RealMoney.Amount+500 {
-			t.Errorf("Expected balance refund of 500 cents, before: %d, after: %d",
-				balBefore.RealMoney.Amount, balAfter.RealMoney.Amount)
+		if len(history) != 5 {
+			t.Errorf("Expected 5 history entries, got %d", len(history))
 		}
-	})
 
-	t.Run("VoidAlreadyVoided", func(t *testing.T) {
-		err := engine.VoidGame(ctx, cycleID, "Try again")
-		if err == nil {
-			t.Error("Expected error when voiding already voided game")
+		// Check history is ordered (most recent first)
+		for i := 1; i < len(history); i++ {
+			if history[i].PlayedAt.After(history[i-1].PlayedAt) {
+				t.Error("History should be ordered most recent first")
+			}
 		}
 	})
 
-	t.Run("VoidNonexistent", func(t *testing.T) {
-		err := engine.VoidGame(ctx, uuid.New().String(), "Not found")
-		if err == nil {
-			t.Error("Expected error when voiding nonexistent game")
+	t.Run("LimitHistory", func(t *testing.T) {
+		history, _ := engine.GetHistory(ctx, playerID, 3)
+		if len(history) != 3 {
+			t.Errorf("Expected 3 history entries with limit, got %d", len(history))
 		}
 	})
 }
 
-func TestResumeGame(t *testing.T) {
+func TestPlayPateplayRef(t *testing.T) {
 	engine, playerID, cleanup := setupTestEngine(t)
 	defer cleanup()
 
 	ctx := context.Background()
-
-	// Start session
 	session, _ := engine.StartSession(ctx, playerID, "fortune-slots")
 
-	// Create an interrupted game cycle with a winning outcome
-	cycleID := uuid.New().String()
-	_, err := engine.db.ExecContext(ctx, `
-		INSERT INTO game_cycles (id, session_id, player_id, game_id, started_at, wager_amount, win_amount, balance_before, balance_after, outcome, status, currency)
-		VALUES ($1, $2, $3, $4, NOW(), 100, 0, 100000, 99900, '{"reels":["7","7","7"],"win_lines":[{"line":1,"symbols":["7","7","7"],"win":500}]}', $5, 'USD')
-	`, cycleID, session.ID, playerID, "fortune-slots", domain.CycleStatusInterrupted)
+	ref := &PateplayRef{
+		SessionToken:  "pp-session",
+		PlayerID:      "pp-player",
+		RoundID:       "round-1",
+		TransactionID: "tx-1",
+	}
+	settled, err := engine.Play(ctx, &PlayRequest{SessionID: session.ID, WagerAmount: 100, Pateplay: ref})
 	if err != nil {
-		t.Fatalf("Failed to create interrupted cycle: %v", err)
+		t.Fatalf("Failed to play: %v", err)
+	}
+	local, err := engine.Play(ctx, &PlayRequest{SessionID: session.ID, WagerAmount: 100})
+	if err != nil {
+		t.Fatalf("Failed to play: %v", err)
 	}
 
-	t.Run("ResumeInterruptedGame", func(t *testing.T) {
-		result, err := engine.ResumeGame(ctx, cycleID)
+	t.Run("Cycle", func(t *testing.T) {
+		cycle, err := engine.GetCycle(ctx, settled.CycleID)
 		if err != nil {
-			t.Fatalf("Failed to resume game: %v", err)
+			t.Fatalf("Failed to get cycle: %v", err)
 		}
-
-		if result.CycleID != cycleID {
-			t.Errorf("Expected cycle ID %s, got %s", cycleID, result.CycleID)
+		if cycle.PateplayRoundID != ref.RoundID || cycle.PateplayTransactionID != ref.TransactionID {
+			t.Errorf("Expected round %s and transaction %s, got %s and %s",
+				ref.RoundID, ref.TransactionID, cycle.PateplayRoundID, cycle.PateplayTransactionID)
 		}
 
-		if result.Outcome == nil {
-			t.Error("Expected outcome to be present")
+		cycle, err = engine.GetCycle(ctx, local.CycleID)
+		if err != nil {
+			t.Fatalf("Failed to get cycle: %v", err)
 		}
-
-		// Verify cycle is now completed
-		var status domain.GameCycleStatus
-		engine.db.QueryRowContext(ctx, "SELECT status FROM game_cycles WHERE id = $1", cycleID).Scan(&status)
-
-		if status != domain.CycleStatusCompleted {
-			t.Errorf("Expected status 'completed', got '%s'", status)
+		if cycle.PateplayRoundID != "" || cycle.PateplayTransactionID != "" {
+			t.Errorf("Expected no Pateplay references on a local cycle, got %+v", cycle)
 		}
 	})
 
-	t.Run("ResumeAlreadyCompleted", func(t *testing.T) {
-		_, err := engine.ResumeGame(ctx, cycleID)
-		if err == nil {
-			t.Error("Expected error when resuming already completed game")
+	t.Run("History", func(t *testing.T) {
+		history, err := engine.GetHistory(ctx, playerID, 10)
+		if err != nil {
+			t.Fatalf("Failed to get history: %v", err)
+		}
+		found := false
+		for _, recall := range history {
+			if recall.CycleID != settled.CycleID {
+				continue
+			}
+			found = true
+			if recall.PateplayRoundID != ref.RoundID || recall.PateplayTransactionID != ref.TransactionID {
+				t.Errorf("Expected round %s and transaction %s in recall, got %s and %s",
+					ref.RoundID, ref.TransactionID, recall.PateplayRoundID, recall.PateplayTransactionID)
+			}
+		}
+		if !found {
+			t.Fatal("Expected the settled cycle in history")
 		}
 	})
+}
 
-	t.Run("ResumeNonexistent", func(t *testing.T) {
-		_, err := engine.ResumeGame(ctx, uuid.New().String())
-		if err == nil {
-			t.Error("Expected error when resuming nonexistent game")
+func TestDecodeOutcome(t *testing.T) {
+	raw := json.RawMessage(`{"reels":["CHERRY","BAR","7"],"win_lines":[{"line":1,"symbols":["CHERRY","CHERRY"],"count":2,"payout":50}],"multiplier":1,"is_win":true}`)
+	outcome := decodeOutcome(raw)
+	if outcome == nil {
+		t.Fatal("Expected stored outcome to decode")
+	}
+	if len(outcome.Reels) != 3 || outcome.Reels[2] != SymbolSeven {
+		t.Errorf("Expected reels CHERRY, BAR, 7, got %v", outcome.Reels)
+	}
+	if len(outcome.WinLines) != 1 || outcome.WinLines[0].Count != 2 || outcome.WinLines[0].Payout != 50 {
+		t.Errorf("Expected one 2-symbol win line paying 50, got %+v", outcome.WinLines)
+	}
+
+	for _, raw := range []json.RawMessage{nil, json.RawMessage(`[1,2]`), json.RawMessage(`{"reels":5}`)} {
+		if outcome := decodeOutcome(raw); outcome != nil {
+			t.Errorf("Expected %s not to decode, got %+v", raw, outcome)
 		}
-	})
+	}
 }
 
-func TestInterruptedGameFlow(t *testing.T) {
+func TestGetCycle(t *testing.T) {
 	engine, playerID, cleanup := setupTestEngine(t)
 	defer cleanup()
 
 	ctx := context.Background()
 
-	// Start session
 	session, _ := engine.StartSession(ctx, playerID, "fortune-slots")
-
-	// Play a game successfully
-	result, err := engine.Play(ctx, &PlayRequest{
-		SessionID:   session.ID,
-		WagerAmount: 100,
-	})
+	result, err := engine.Play(ctx, &PlayRequest{SessionID: session.ID, WagerAmount: 100})
 	if err != nil {
-		t.Fatalf("Failed to play game: %v", err)
+		t.Fatalf("Failed to play: %v", err)
 	}
 
-	t.Log("Initial play completed successfully")
+	t.Run("GetCycle", func(t *testing.T) {
+		cycle, err := engine.GetCycle(ctx, result.CycleID)
+		if err != nil {
+			t.Fatalf("Failed to get cycle: %v", err)
+		}
 
-	// Simulate creating an interrupted game
-	interruptedCycleID := uuid.New().String()
-	_, err = engine.db.ExecContext(ctx, `
-		INSERT INTO game_cycles (id, session_id, player_id, game_id, started_at, wager_amount, win_amount, balance_before, balance_after, outcome, status, currency)
-		VALUES ($1, $2, $3, $4, NOW(), 200, 0, $5, $6, '{"reels":["CHERRY","BAR","7"]}', $7, 'USD')
-	`, interruptedCycleID, session.ID, playerID, "fortune-slots", 
-		result.Balance.Amount, result.Balance.Amount-200, domain.CycleStatusInterrupted)
-	if err != nil {
-		t.Fatalf("Failed to create interrupted cycle: %v", err)
-	}
+		if cycle.PlayerID != playerID || cycle.SessionID != session.ID {
+			t.Errorf("Cycle belongs to %s/%s, expected %s/%s", cycle.PlayerID, cycle.SessionID, playerID, session.ID)
+		}
+		if cycle.Status != domain.CycleStatusCompleted || cycle.CompletedAt == nil {
+			t.Errorf("Expected completed cycle, got %s", cycle.Status)
+		}
+		if cycle.WagerAmount.Amount != 100 || cycle.WinAmount.Amount != result.WinAmount.Amount {
+			t.Errorf("Expected wager 100 and win %d, got %d and %d",
+				result.WinAmount.Amount, cycle.WagerAmount.Amount, cycle.WinAmount.Amount)
+		}
+		if cycle.BalanceAfter.Amount != result.Balance.Amount {
+			t.Errorf("Expected balance after %d, got %d", result.Balance.Amount, cycle.BalanceAfter.Amount)
+		}
 
-	t.Run("VerifyInterruptedGameExists", func(t *testing.T) {
-		interrupted, err := engine.GetInterruptedGames(ctx, playerID)
+		var outcome SlotOutcome
+		if err := json.Unmarshal(cycle.Outcome, &outcome); err != nil {
+			t.Fatalf("Failed to decode outcome: %v", err)
+		}
+		if len(outcome.Reels) == 0 {
+			t.Error("Expected reels in recalled outcome")
+		}
+	})
+
+	t.Run("DecodedOutcome", func(t *testing.T) {
+		// Play until a win so the recalled outcome has win lines
+		win := result
+		for i := 0; i < 200 && !win.Outcome.IsWin; i++ {
+			if win, err = engine.Play(ctx, &PlayRequest{SessionID: session.ID, WagerAmount: 100}); err != nil {
+				t.Fatalf("Failed to play: %v", err)
+			}
+		}
+		if !win.Outcome.IsWin {
+			t.Skip("No win in 200 spins")
+		}
+
+		cycle, err := engine.GetCycle(ctx, win.CycleID)
 		if err != nil {
-			t.Fatalf("Failed to get interrupted games: %v", err)
+			t.Fatalf("Failed to get cycle: %v", err)
+		}
+		decoded, ok := cycle.DecodedOutcome.(*SlotOutcome)
+		if !ok {
+			t.Fatalf("Expected *SlotOutcome, got %T", cycle.DecodedOutcome)
+		}
+		if !reflect.DeepEqual(decoded.Reels, win.Outcome.Reels) {
+			t.Errorf("Expected reels %v, got %v", win.Outcome.Reels, decoded.Reels)
+		}
+		if !reflect.DeepEqual(decoded.WinLines, win.Outcome.WinLines) {
+			t.Errorf("Expected win lines %+v, got %+v", win.Outcome.WinLines, decoded.WinLines)
+		}
+		if len(cycle.Outcome) == 0 {
+			t.Error("Expected raw outcome alongside the decoded one")
 		}
 
-		if len(interrupted) != 1 {
-			t.Fatalf("Expected 1 interrupted game, got %d", len(interrupted))
+		history, err := engine.GetHistory(ctx, playerID, 1)
+		if err != nil || len(history) != 1 {
+			t.Fatalf("Failed to get history: %v", err)
 		}
+		recalled, ok := history[0].DecodedOutcome.(*SlotOutcome)
+		if !ok || !reflect.DeepEqual(recalled.WinLines, win.Outcome.WinLines) {
+			t.Errorf("Expected history to decode win lines %+v, got %+v", win.Outcome.WinLines, history[0].DecodedOutcome)
+		}
+	})
 
-		if interrupted[0].WagerHeld.Amount != 200 {
-			t.Errorf("Expected wager held 200, got %d", interrupted[0].WagerHeld.Amount)
+	t.Run("UnknownCycle", func(t *testing.T) {
+		if _, err := engine.GetCycle(ctx, uuid.New().String()); err != ErrCycleNotFound {
+			t.Errorf("Expected ErrCycleNotFound, got %v", err)
+		}
+		if _, err := engine.GetCycle(ctx, "not-a-cycle"); err != ErrCycleNotFound {
+			t.Errorf("Expected ErrCycleNotFound for malformed ID, got %v", err)
 		}
 	})
+}
 
-	t.Run("ResolveByVoiding", func(t *testing.T) {
-		err := engine.VoidGame(ctx, interruptedCycleID, "Test resolution")
+// ============================================================================
+// Interrupted Games Tests (GLI-19 §4.16)
+// ============================================================================
+
+func TestGetInterruptedGames(t *testing.T) {
+	engine, playerID, cleanup := setupTestEngine(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	t.Run("NoInterruptedGames", func(t *testing.T) {
+		interrupted, err := engine.GetInterruptedGames(ctx, playerID)
 		if err != nil {
-			t.Fatalf("Failed to void: %v", err)
+			t.Fatalf("Failed to get interrupted games: %v", err)
 		}
 
-		// Verify no more interrupted games
-		interrupted, _ := engine.GetInterruptedGames(ctx, playerID)
 		if len(interrupted) != 0 {
-			t.Errorf("Expected 0 interrupted games after void, got %d", len(interrupted))
+			t.Errorf("Expected 0 interrupted games, got %d", len(interrupted))
+		}
+	})
+}
+
+func TestMarkInterrupted(t *testing.T) {
+	engine, playerID, cleanup := setupTestEngine(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	// Start session
+	session, err := engine.StartSession(ctx, playerID, "fortune-slots")
+	if err != nil {
+		t.Fatalf("Failed to start session: %v", err)
+	}
+
+	// Create a game cycle in progress (simulate by inserting directly)
+	cycleID := uuid.New().String()
+	_, err = engine.db.ExecContext(ctx, `
+		INSERT INTO game_cycles (id, session_id, player_id, game_id, started_at, wager_amount, win_amount, balance_before, balance_after, outcome, status, currency)
+		VALUES ($1, $2, $3, $4, NOW(), 100, 0, 100000, 99900, '{"reels":["7","7","7"]}', $5, 'USD')
+	`, cycleID, session.ID, playerID, "fortune-slots", domain.CycleStatusInProgress)
+	if err != nil {
+		t.Fatalf("Failed to create in-progress cycle: %v", err)
+	}
+
+	t.Run("MarkAsInterrupted", func(t *testing.T) {
+		err := engine.MarkInterrupted(ctx, cycleID, "Connection lost")
+		if err != nil {
+			t.Fatalf("Failed to mark as interrupted: %v", err)
+		}
+
+		// Verify cycle is now interrupted
+		var status domain.GameCycleStatus
+		engine.db.QueryRowContext(ctx, "SELECT status FROM game_cycles WHERE id = $1", cycleID).Scan(&status)
+
+		if status != domain.CycleStatusInterrupted {
+			t.Errorf("Expected status 'interrupted', got '%s'", status)
+		}
+	})
+
+	t.Run("GetInterruptedGames", func(t *testing.T) {
+		interrupted, err := engine.GetInterruptedGames(ctx, playerID)
+		if err != nil {
+			t.Fatalf("Failed to get interrupted games: %v", err)
+		}
+
+		if len(interrupted) != 1 {
+			t.Errorf("Expected 1 interrupted game, got %d", len(interrupted))
+		}
+
+		if interrupted[0].CycleID != cycleID {
+			t.Errorf("Expected cycle ID %s, got %s", cycleID, interrupted[0].CycleID)
+		}
+	})
+}
+
+func TestSweepStaleCycles(t *testing.T) {
+	engine, playerID, cleanup := setupTestEngine(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	session, err := engine.StartSession(ctx, playerID, "fortune-slots")
+	if err != nil {
+		t.Fatalf("Failed to start session: %v", err)
+	}
+
+	// One cycle abandoned an hour ago, one still being played
+	staleID := uuid.New().String()
+	freshID := uuid.New().String()
+	for id, startedAt := range map[string]string{staleID: "NOW() - INTERVAL '1 hour'", freshID: "NOW()"} {
+		_, err = engine.db.ExecContext(ctx, `
+			INSERT INTO game_cycles (id, session_id, player_id, game_id, started_at, wager_amount, win_amount, balance_before, balance_after, outcome, status, currency)
+			VALUES ($1, $2, $3, $4, `+startedAt+`, 100, 0, 100000, 99900, '{"reels":["7","7","7"]}', $5, 'USD')
+		`, id, session.ID, playerID, "fortune-slots", domain.CycleStatusInProgress)
+		if err != nil {
+			t.Fatalf("Failed to create in-progress cycle: %v", err)
+		}
+	}
+
+	// A multi-step round started an hour ago whose last step was just played
+	activeID := uuid.New().String()
+	_, err = engine.db.ExecContext(ctx, `
+		INSERT INTO game_cycles (id, session_id, player_id, game_id, started_at, last_step_at, wager_amount, win_amount, balance_before, balance_after, status, currency)
+		VALUES ($1, $2, $3, $4, NOW() - INTERVAL '1 hour', NOW(), 100, 0, 100000, 99900, $5, 'USD')
+	`, activeID, session.ID, playerID, "fortune-slots", domain.CycleStatusInProgress)
+	if err != nil {
+		t.Fatalf("Failed to create active round: %v", err)
+	}
+
+	swept, err := engine.SweepStaleCycles(ctx, 10*time.Minute)
+	if err != nil {
+		t.Fatalf("Failed to sweep: %v", err)
+	}
+	if swept != 1 {
+		t.Errorf("Expected 1 cycle swept, got %d", swept)
+	}
+
+	interrupted, err := engine.GetInterruptedGames(ctx, playerID)
+	if err != nil {
+		t.Fatalf("Failed to get interrupted games: %v", err)
+	}
+	if len(interrupted) != 1 || interrupted[0].CycleID != staleID {
+		t.Fatalf("Expected only the stale cycle to be interrupted, got %+v", interrupted)
+	}
+	if interrupted[0].Reason != InterruptReasonTimeout {
+		t.Errorf("Expected reason %q, got %q", InterruptReasonTimeout, interrupted[0].Reason)
+	}
+
+	for _, id := range []string{freshID, activeID} {
+		var status domain.GameCycleStatus
+		engine.db.QueryRowContext(ctx, "SELECT status FROM game_cycles WHERE id = $1", id).Scan(&status)
+		if status != domain.CycleStatusInProgress {
+			t.Errorf("Expected cycle %s to stay in progress, got %s", id, status)
+		}
+	}
+
+	// Already interrupted cycles are not swept again
+	if swept, _ := engine.SweepStaleCycles(ctx, 10*time.Minute); swept != 0 {
+		t.Errorf("Expected nothing swept on second pass, got %d", swept)
+	}
+}
+
+func TestVoidGame(t *testing.T) {
+	engine, playerID, cleanup := setupTestEngine(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	// Start session
+	session, _ := engine.StartSession(ctx, playerID, "fortune-slots")
+
+	// Create an interrupted game cycle
+	cycleID := uuid.New().String()
+	_, err := engine.db.ExecContext(ctx, `
+		INSERT INTO game_cycles (id, session_id, player_id, game_id, started_at, wager_amount, win_amount, balance_before, balance_after, outcome, status, currency)
+		VALUES ($1, $2, $3, $4, NOW(), 500, 0, 100000, 99500, '{"reels":["7","BAR","CHERRY"]}', $5, 'USD')
+	`, cycleID, session.ID, playerID, "fortune-slots", domain.CycleStatusInterrupted)
+	if err != nil {
+		t.Fatalf("Failed to create interrupted cycle: %v", err)
+	}
+
+	// Deduct the wager from balance (simulate what happened before interruption)
+	_, err = engine.db.ExecContext(ctx, `
+		UPDATE balances SET real_money_amount = real_money_amount - 500 WHERE player_id = $1
+	`, playerID)
+	if err != nil {
+		t.Fatalf("Failed to deduct balance: %v", err)
+	}
+
+	t.Run("VoidAndRefund", func(t *testing.T) {
+		// Get balance before void
+		balBefore, _ := engine.wallet.GetBalance(ctx, playerID)
+
+		err := engine.VoidGame(ctx, cycleID, "Player requested void")
+		if err != nil {
+			t.Fatalf("Failed to void game: %v", err)
+		}
+
+		// Verify cycle is now voided
+		var status domain.GameCycleStatus
+		engine.db.QueryRowContext(ctx, "SELECT status FROM game_cycles WHERE id = $1", cycleID).Scan(&status)
+
+		if status != domain.CycleStatusVoided {
+			t.Errorf("Expected status 'voided', got '%s'", status)
+		}
+
+		// Verify balance was refunded
+		balAfter, _ := engine.wallet.GetBalance(ctx, playerID)
+		if balAfter.RealMoney.Amount != balBefore.RealMoney.Amount+500 {
+			t.Errorf("Expected balance refund of 500 cents, before: %d, after: %d",
+				balBefore.RealMoney.Amount, balAfter.RealMoney.Amount)
+		}
+
+		// Verify the refund is in the transaction ledger
+		txs, _ := engine.wallet.(*wallet.Service).GetTransactions(ctx, playerID, 1)
+		if len(txs) != 1 || txs[0].Type != domain.TxTypeRefund || txs[0].Reference != cycleID {
+			t.Fatal("Expected refund transaction for the voided cycle")
+		}
+		if txs[0].Amount.Amount != 500 || txs[0].BalanceAfter.Amount != balAfter.RealMoney.Amount {
+			t.Errorf("Unexpected refund transaction: amount %d, balance after %d",
+				txs[0].Amount.Amount, txs[0].BalanceAfter.Amount)
+		}
+	})
+
+	t.Run("VoidAlreadyVoided", func(t *testing.T) {
+		err := engine.VoidGame(ctx, cycleID, "Try again")
+		if err == nil {
+			t.Error("Expected error when voiding already voided game")
+		}
+	})
+
+	t.Run("VoidNonexistent", func(t *testing.T) {
+		err := engine.VoidGame(ctx, uuid.New().String(), "Not found")
+		if err == nil {
+			t.Error("Expected error when voiding nonexistent game")
+		}
+	})
+}
+
+// mockPateplayCancel serves /cancel, recording each request, and answers with
+// apiErr when it is set
+func mockPateplayCancel(t *testing.T, apiErr *pateplay.APIError) (*httptest.Server, *[]pateplay.CancelRequest) {
+	var requests []pateplay.CancelRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/cancel" {
+			t.Errorf("Expected path /cancel, got %s", r.URL.Path)
+			http.Error(w, "Not found", http.StatusNotFound)
+			return
+		}
+
+		var req pateplay.CancelRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Bad request", http.StatusBadRequest)
+			return
+		}
+		requests = append(requests, req)
+
+		w.Header().Set("Content-Type", "application/json")
+		if apiErr != nil {
+			json.NewEncoder(w).Encode(pateplay.Response[pateplay.CancelResult]{Error: apiErr})
+			return
+		}
+		json.NewEncoder(w).Encode(pateplay.Response[pateplay.CancelResult]{
+			Result: &pateplay.CancelResult{TransactionID: "pp-cancel-1"},
+		})
+	}))
+	return server, &requests
+}
+
+func TestVoidGamePateplayCancel(t *testing.T) {
+	engine, playerID, cleanup := setupTestEngine(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	session, _ := engine.StartSession(ctx, playerID, "fortune-slots")
+
+	// interruptedCycle creates an interrupted cycle with a Pateplay transaction
+	interruptedCycle := func(t *testing.T) (string, PateplayRef) {
+		cycleID := uuid.New().String()
+		_, err := engine.db.ExecContext(ctx, `
+			INSERT INTO game_cycles (id, session_id, player_id, game_id, started_at, wager_amount, win_amount, balance_before, balance_after, outcome, status, currency)
+			VALUES ($1, $2, $3, $4, NOW(), 500, 0, 100000, 99500, '{"reels":["7","BAR","CHERRY"]}', $5, 'USD')
+		`, cycleID, session.ID, playerID, "fortune-slots", domain.CycleStatusInterrupted)
+		if err != nil {
+			t.Fatalf("Failed to create interrupted cycle: %v", err)
+		}
+
+		ref := PateplayRef{
+			SessionToken:  "pp-session",
+			PlayerID:      "pp-player",
+			RoundID:       "round-" + cycleID,
+			TransactionID: "tx-" + cycleID,
+		}
+		if err := engine.SetCyclePateplayRef(ctx, cycleID, ref); err != nil {
+			t.Fatalf("Failed to record Pateplay reference: %v", err)
+		}
+		return cycleID, ref
+	}
+
+	cycleStatus := func(cycleID string) domain.GameCycleStatus {
+		var status domain.GameCycleStatus
+		engine.db.QueryRowContext(ctx, "SELECT status FROM game_cycles WHERE id = $1", cycleID).Scan(&status)
+		return status
+	}
+
+	t.Run("CancelCalledOnVoid", func(t *testing.T) {
+		server, requests := mockPateplayCancel(t, nil)
+		defer server.Close()
+		engine.SetPateplay(pateplay.NewClient(&pateplay.ClientConfig{BaseURL: server.URL, SiteCode: "testsite"}))
+
+		cycleID, ref := interruptedCycle(t)
+		if err := engine.VoidGame(ctx, cycleID, "Player requested void"); err != nil {
+			t.Fatalf("Failed to void game: %v", err)
+		}
+
+		if len(*requests) != 1 {
+			t.Fatalf("Expected one cancel request, got %d", len(*requests))
+		}
+		req := (*requests)[0]
+		if req.SessionToken != ref.SessionToken || req.PlayerID != ref.PlayerID ||
+			req.RGSRoundID != ref.RoundID || req.RGSTransactionID != ref.TransactionID {
+			t.Errorf("Expected cancel of %+v, got %+v", ref, req)
+		}
+		if status := cycleStatus(cycleID); status != domain.CycleStatusVoided {
+			t.Errorf("Expected status 'voided', got '%s'", status)
+		}
+	})
+
+	t.Run("TransactionNotFound", func(t *testing.T) {
+		server, requests := mockPateplayCancel(t, &pateplay.APIError{
+			Code:    pateplay.ErrTransactionNotFound,
+			Message: "Transaction not found",
+		})
+		defer server.Close()
+		engine.SetPateplay(pateplay.NewClient(&pateplay.ClientConfig{BaseURL: server.URL, SiteCode: "testsite"}))
+
+		cycleID, _ := interruptedCycle(t)
+		if err := engine.VoidGame(ctx, cycleID, "Player requested void"); err != nil {
+			t.Fatalf("Expected void to succeed when Pateplay has no transaction, got %v", err)
+		}
+		if len(*requests) != 1 {
+			t.Errorf("Expected one cancel request, got %d", len(*requests))
+		}
+		if status := cycleStatus(cycleID); status != domain.CycleStatusVoided {
+			t.Errorf("Expected status 'voided', got '%s'", status)
+		}
+	})
+
+	t.Run("CancelFailureLeavesInterrupted", func(t *testing.T) {
+		server, _ := mockPateplayCancel(t, &pateplay.APIError{
+			Code:    pateplay.ErrUnexpectedError,
+			Message: "Unexpected error",
+		})
+		defer server.Close()
+		engine.SetPateplay(pateplay.NewClient(&pateplay.ClientConfig{BaseURL: server.URL, SiteCode: "testsite"}))
+
+		cycleID, _ := interruptedCycle(t)
+		balBefore, _ := engine.wallet.GetBalance(ctx, playerID)
+		if err := engine.VoidGame(ctx, cycleID, "Player requested void"); err == nil {
+			t.Fatal("Expected void to fail when Pateplay cancel fails")
+		}
+		if status := cycleStatus(cycleID); status != domain.CycleStatusInterrupted {
+			t.Errorf("Expected cycle to stay interrupted, got '%s'", status)
+		}
+		balAfter, _ := engine.wallet.GetBalance(ctx, playerID)
+		if balAfter.RealMoney.Amount != balBefore.RealMoney.Amount {
+			t.Error("Expected no refund when Pateplay cancel fails")
+		}
+	})
+
+	t.Run("UnknownCycle", func(t *testing.T) {
+		if err := engine.SetCyclePateplayRef(ctx, uuid.New().String(), PateplayRef{TransactionID: "tx"}); err != ErrCycleNotFound {
+			t.Errorf("Expected ErrCycleNotFound, got %v", err)
+		}
+	})
+}
+
+// mockPateplaySettle serves /withdraw-and-deposit, recording each request,
+// and answers with apiErr when it is set
+func mockPateplaySettle(t *testing.T, apiErr *pateplay.APIError) (*httptest.Server, *[]pateplay.WithdrawAndDepositRequest) {
+	var requests []pateplay.WithdrawAndDepositRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/withdraw-and-deposit" {
+			t.Errorf("Expected path /withdraw-and-deposit, got %s", r.URL.Path)
+			http.Error(w, "Not found", http.StatusNotFound)
+			return
+		}
+
+		var req pateplay.WithdrawAndDepositRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Bad request", http.StatusBadRequest)
+			return
+		}
+		requests = append(requests, req)
+
+		w.Header().Set("Content-Type", "application/json")
+		if apiErr != nil {
+			json.NewEncoder(w).Encode(pateplay.Response[pateplay.WithdrawAndDepositResult]{Error: apiErr})
+			return
+		}
+		json.NewEncoder(w).Encode(pateplay.Response[pateplay.WithdrawAndDepositResult]{
+			Result: &pateplay.WithdrawAndDepositResult{
+				Balance:               "999.00",
+				WithdrawTransactionID: "pp-withdraw-1",
+				DepositTransactionID:  "pp-deposit-1",
+			},
+		})
+	}))
+	return server, &requests
+}
+
+func TestPateplaySettlement(t *testing.T) {
+	engine, playerID, cleanup := setupTestEngine(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	session, _ := engine.StartSession(ctx, playerID, "fortune-slots")
+	ref := &PateplayRef{SessionToken: "pp-session", PlayerID: "pp-player"}
+
+	useServer := func(server *httptest.Server) {
+		client := pateplay.NewClient(&pateplay.ClientConfig{BaseURL: server.URL, SiteCode: "testsite"})
+		engine.SetSettlement(NewPateplaySettlement(client, engine.wallet, engine.audit))
+	}
+
+	t.Run("WithdrawAndDeposit", func(t *testing.T) {
+		server, requests := mockPateplaySettle(t, nil)
+		defer server.Close()
+		useServer(server)
+
+		result, err := engine.Play(ctx, &PlayRequest{SessionID: session.ID, WagerAmount: 100, Pateplay: ref})
+		if err != nil {
+			t.Fatalf("Failed to play: %v", err)
+		}
+
+		if len(*requests) != 1 {
+			t.Fatalf("Expected one withdraw-and-deposit request, got %d", len(*requests))
+		}
+		req := (*requests)[0]
+		if req.WithdrawAmount != "1.00" || req.DepositAmount != result.WinAmount.ToAPIString() {
+			t.Errorf("Expected withdraw 1.00 and deposit %s, got %s and %s",
+				result.WinAmount.ToAPIString(), req.WithdrawAmount, req.DepositAmount)
+		}
+		if req.WithdrawReason != pateplay.WithdrawReasonRoundStart || req.DepositReason != pateplay.DepositReasonRoundEnd {
+			t.Errorf("Expected round_start and round_end reasons, got %s and %s", req.WithdrawReason, req.DepositReason)
+		}
+		if req.SessionToken != ref.SessionToken || req.PlayerID != ref.PlayerID || req.Currency != "USD" {
+			t.Errorf("Unexpected session, player or currency: %+v", req)
+		}
+		if req.RGSRoundID != result.CycleID || req.RGSWithdrawTransactionID != result.CycleID ||
+			req.RGSDepositTransactionID != result.CycleID+"-deposit" {
+			t.Errorf("Expected round and transaction IDs derived from cycle %s, got %+v", result.CycleID, req)
+		}
+
+		// The cycle stays in the local ledger
+		cycle, err := engine.GetCycle(ctx, result.CycleID)
+		if err != nil {
+			t.Fatalf("Failed to get cycle: %v", err)
+		}
+		if cycle.PateplayRoundID != result.CycleID || cycle.PateplayTransactionID != result.CycleID {
+			t.Errorf("Expected Pateplay references on the cycle, got %+v", cycle)
+		}
+		txs, _ := engine.wallet.(*wallet.Service).GetTransactions(ctx, playerID, 10)
+		wagered := false
+		for _, tx := range txs {
+			if tx.Type == domain.TxTypeWager && tx.Reference == result.CycleID {
+				wagered = true
+			}
+		}
+		if !wagered {
+			t.Error("Expected the wager in the local ledger")
+		}
+	})
+
+	t.Run("FailureRollsBack", func(t *testing.T) {
+		server, _ := mockPateplaySettle(t, &pateplay.APIError{
+			Code:    pateplay.ErrInsufficientBalance,
+			Message: "Insufficient balance",
+		})
+		defer server.Close()
+		useServer(server)
+
+		before, _ := engine.wallet.GetBalance(ctx, playerID)
+		if _, err := engine.Play(ctx, &PlayRequest{SessionID: session.ID, WagerAmount: 100, Pateplay: ref}); err == nil {
+			t.Fatal("Expected play to fail when Pateplay rejects the settlement")
+		}
+		after, _ := engine.wallet.GetBalance(ctx, playerID)
+		if after.Available.Amount != before.Available.Amount {
+			t.Errorf("Expected balance %d to be unchanged, got %d", before.Available.Amount, after.Available.Amount)
+		}
+	})
+
+	t.Run("NoSession", func(t *testing.T) {
+		server, requests := mockPateplaySettle(t, nil)
+		defer server.Close()
+		useServer(server)
+
+		_, err := engine.Play(ctx, &PlayRequest{SessionID: session.ID, WagerAmount: 100})
+		if !errors.Is(err, ErrNoPateplaySession) {
+			t.Errorf("Expected ErrNoPateplaySession, got %v", err)
+		}
+		if len(*requests) != 0 {
+			t.Errorf("Expected no request to Pateplay, got %d", len(*requests))
+		}
+	})
+}
+
+// pateplayCall is a request made to mockPateplayWallet
+type pateplayCall struct {
+	Path string
+	Body map[string]interface{}
+}
+
+// mockPateplayWallet serves the Pateplay wallet endpoints, recording each
+// call, reporting balance, and answering after delay
+func mockPateplayWallet(t *testing.T, balance string, delay time.Duration) (*httptest.Server, *[]pateplayCall) {
+	var calls []pateplayCall
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, "Bad request", http.StatusBadRequest)
+			return
+		}
+		calls = append(calls, pateplayCall{Path: r.URL.Path, Body: body})
+		if r.URL.Path == "/withdraw-and-deposit" {
+			time.Sleep(delay)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/withdraw-and-deposit":
+			json.NewEncoder(w).Encode(pateplay.Response[pateplay.WithdrawAndDepositResult]{
+				Result: &pateplay.WithdrawAndDepositResult{Balance: balance},
+			})
+		case "/deposit":
+			json.NewEncoder(w).Encode(pateplay.Response[pateplay.DepositResult]{
+				Result: &pateplay.DepositResult{Balance: balance},
+			})
+		case "/cancel":
+			json.NewEncoder(w).Encode(pateplay.Response[pateplay.CancelResult]{
+				Result: &pateplay.CancelResult{TransactionID: "pp-cancel"},
+			})
+		default:
+			t.Errorf("Unexpected path %s", r.URL.Path)
+			http.Error(w, "Not found", http.StatusNotFound)
+		}
+	}))
+	return server, &calls
+}
+
+func TestPateplaySettlementJackpotAndCancel(t *testing.T) {
+	ctx := context.Background()
+	fake := newFakeWallet()
+	playerID := uuid.New().String()
+	fake.balances[playerID] = 100000
+
+	// Balance after a 10.00 wager and a 50.10 jackpot
+	server, calls := mockPateplayWallet(t, "1040.10", 0)
+	defer server.Close()
+	client := pateplay.NewClient(&pateplay.ClientConfig{BaseURL: server.URL, SiteCode: "testsite"})
+	backend := NewPateplaySettlement(client, fake, nil)
+
+	settled := &Settlement{
+		CycleID:             "cycle-1",
+		PlayerID:            playerID,
+		GameID:              "jackpot-slots",
+		Wager:               domain.Money{Amount: 1000, Currency: "USD"},
+		Win:                 domain.Money{Amount: 0, Currency: "USD"},
+		JackpotContribution: domain.Money{Amount: 10, Currency: "USD"},
+		JackpotWin:          domain.Money{Amount: 5010, Currency: "USD"},
+		Pateplay:            &PateplayRef{SessionToken: "pp-session", PlayerID: "pp-player", RoundID: "round-1", TransactionID: "tx-1"},
+	}
+	if err := backend.Settle(ctx, nil, settled); err != nil {
+		t.Fatalf("Failed to settle: %v", err)
+	}
+
+	if len(*calls) != 2 || (*calls)[0].Path != "/withdraw-and-deposit" || (*calls)[1].Path != "/deposit" {
+		t.Fatalf("Expected withdraw-and-deposit then deposit, got %+v", *calls)
+	}
+	if got := (*calls)[0].Body["jackpotContribution"]; got != "0.10" {
+		t.Errorf("Expected jackpot contribution 0.10, got %v", got)
+	}
+	deposit := (*calls)[1].Body
+	if deposit["amount"] != "50.10" || deposit["isJackpotWin"] != true || deposit["rgsTransactionId"] != "tx-1-jackpot" {
+		t.Errorf("Expected a 50.10 jackpot deposit as tx-1-jackpot, got %v", deposit)
+	}
+	if fake.balances[playerID] != 104010 {
+		t.Errorf("Expected the local mirror at 104010, got %d", fake.balances[playerID])
+	}
+
+	t.Run("Cancel", func(t *testing.T) {
+		*calls = nil
+		if err := backend.Cancel(ctx, settled); err != nil {
+			t.Fatalf("Failed to cancel: %v", err)
+		}
+		var ids []string
+		for _, call := range *calls {
+			if call.Path != "/cancel" {
+				t.Errorf("Expected only cancels, got %s", call.Path)
+			}
+			ids = append(ids, call.Body["rgsTransactionId"].(string))
+		}
+		if strings.Join(ids, ",") != "tx-1-jackpot,tx-1-deposit,tx-1" {
+			t.Errorf("Expected the deposits cancelled before the withdrawal, got %v", ids)
+		}
+	})
+
+	t.Run("GambleTransactionIDs", func(t *testing.T) {
+		gamble := &Settlement{GambleStep: 2, Pateplay: settled.Pateplay}
+		if id := withdrawTransactionID(gamble); id != "tx-1-gamble-2" {
+			t.Errorf("Expected tx-1-gamble-2, got %s", id)
+		}
+		if id := depositTransactionID(gamble); id != "tx-1-gamble-2-deposit" {
+			t.Errorf("Expected tx-1-gamble-2-deposit, got %s", id)
+		}
+	})
+
+	t.Run("Timeout", func(t *testing.T) {
+		slow, slowCalls := mockPateplayWallet(t, "1030.10", 200*time.Millisecond)
+		defer slow.Close()
+		backend := NewPateplaySettlement(pateplay.NewClient(&pateplay.ClientConfig{BaseURL: slow.URL, SiteCode: "testsite"}), fake, nil)
+		backend.SetTimeout(20 * time.Millisecond)
+
+		err := backend.Settle(ctx, nil, &Settlement{
+			CycleID:  "cycle-2",
+			PlayerID: playerID,
+			GameID:   "jackpot-slots",
+			Wager:    domain.Money{Amount: 1000, Currency: "USD"},
+			Win:      domain.Money{Amount: 0, Currency: "USD"},
+			Pateplay: settled.Pateplay,
+		})
+		if err == nil {
+			t.Error("Expected a slow Pateplay settlement to time out")
+		}
+
+		// The timed-out withdrawal may have been applied, so it is cancelled
+		var cancelled []string
+		for _, call := range *slowCalls {
+			if call.Path == "/cancel" {
+				cancelled = append(cancelled, call.Body["rgsTransactionId"].(string))
+			}
+		}
+		if strings.Join(cancelled, ",") != "tx-1-deposit,tx-1" {
+			t.Errorf("Expected the timed-out settlement cancelled, got %v", cancelled)
+		}
+	})
+}
+
+func TestResumeGame(t *testing.T) {
+	engine, playerID, cleanup := setupTestEngine(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	// Start session
+	session, _ := engine.StartSession(ctx, playerID, "fortune-slots")
+
+	// Create an interrupted game cycle with a winning outcome
+	cycleID := uuid.New().String()
+	_, err := engine.db.ExecContext(ctx, `
+		INSERT INTO game_cycles (id, session_id, player_id, game_id, started_at, wager_amount, win_amount, balance_before, balance_after, outcome, status, currency)
+		VALUES ($1, $2, $3, $4, NOW(), 100, 0, 100000, 99900, '{"reels":["7","7","7"],"win_lines":[{"line":1,"symbols":["7","7","7"],"win":500}]}', $5, 'USD')
+	`, cycleID, session.ID, playerID, "fortune-slots", domain.CycleStatusInterrupted)
+	if err != nil {
+		t.Fatalf("Failed to create interrupted cycle: %v", err)
+	}
+
+	t.Run("ResumeInterruptedGame", func(t *testing.T) {
+		result, err := engine.ResumeGame(ctx, cycleID)
+		if err != nil {
+			t.Fatalf("Failed to resume game: %v", err)
+		}
+
+		if result.CycleID != cycleID {
+			t.Errorf("Expected cycle ID %s, got %s", cycleID, result.CycleID)
+		}
+
+		if result.Outcome == nil {
+			t.Error("Expected outcome to be present")
+		}
+
+		// Verify cycle is now completed
+		var status domain.GameCycleStatus
+		engine.db.QueryRowContext(ctx, "SELECT status FROM game_cycles WHERE id = $1", cycleID).Scan(&status)
+
+		if status != domain.CycleStatusCompleted {
+			t.Errorf("Expected status 'completed', got '%s'", status)
+		}
+	})
+
+	t.Run("ResumeAlreadyCompleted", func(t *testing.T) {
+		_, err := engine.ResumeGame(ctx, cycleID)
+		if err == nil {
+			t.Error("Expected error when resuming already completed game")
+		}
+	})
+
+	t.Run("ResumeNonexistent", func(t *testing.T) {
+		_, err := engine.ResumeGame(ctx, uuid.New().String())
+		if err == nil {
+			t.Error("Expected error when resuming nonexistent game")
+		}
+	})
+
+	t.Run("ConcurrentVoidAndResume", func(t *testing.T) {
+		racedID := uuid.New().String()
+		_, err := engine.db.ExecContext(ctx, `
+			INSERT INTO game_cycles (id, session_id, player_id, game_id, started_at, wager_amount, win_amount, balance_before, balance_after, outcome, status, currency)
+			VALUES ($1, $2, $3, $4, NOW(), 100, 0, 100000, 99900, '{"reels":["7","7","7"],"win_lines":[{"line":1,"symbols":["7","7","7"],"win":500}]}', $5, 'USD')
+		`, racedID, session.ID, playerID, "fortune-slots", domain.CycleStatusInterrupted)
+		if err != nil {
+			t.Fatalf("Failed to create interrupted cycle: %v", err)
+		}
+
+		var wg sync.WaitGroup
+		var voidErr, resumeErr error
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			voidErr = engine.VoidGame(ctx, racedID, "Concurrent void")
+		}()
+		go func() {
+			defer wg.Done()
+			_, resumeErr = engine.ResumeGame(ctx, racedID)
+		}()
+		wg.Wait()
+
+		if (voidErr == nil) == (resumeErr == nil) {
+			t.Fatalf("Expected exactly one of void and resume to succeed, got void=%v resume=%v", voidErr, resumeErr)
+		}
+		failed := voidErr
+		if failed == nil {
+			failed = resumeErr
+		}
+		if !errors.Is(failed, ErrCycleNotInterrupted) {
+			t.Errorf("Expected ErrCycleNotInterrupted from the loser, got %v", failed)
+		}
+
+		var refunds, wins int
+		engine.db.QueryRowContext(ctx, `
+			SELECT COUNT(*) FILTER (WHERE type = $2), COUNT(*) FILTER (WHERE type = $3)
+			FROM transactions WHERE reference = $1
+		`, racedID, domain.TxTypeRefund, domain.TxTypeWin).Scan(&refunds, &wins)
+		if refunds+wins != 1 {
+			t.Errorf("Expected one refund or win for the cycle, got %d refunds and %d wins", refunds, wins)
+		}
+	})
+}
+
+func TestInterruptedGameFlow(t *testing.T) {
+	engine, playerID, cleanup := setupTestEngine(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	// Start session
+	session, _ := engine.StartSession(ctx, playerID, "fortune-slots")
+
+	// Play a game successfully
+	result, err := engine.Play(ctx, &PlayRequest{
+		SessionID:   session.ID,
+		WagerAmount: 100,
+	})
+	if err != nil {
+		t.Fatalf("Failed to play game: %v", err)
+	}
+
+	t.Log("Initial play completed successfully")
+
+	// Simulate creating an interrupted game
+	interruptedCycleID := uuid.New().String()
+	_, err = engine.db.ExecContext(ctx, `
+		INSERT INTO game_cycles (id, session_id, player_id, game_id, started_at, wager_amount, win_amount, balance_before, balance_after, outcome, status, currency)
+		VALUES ($1, $2, $3, $4, NOW(), 200, 0, $5, $6, '{"reels":["CHERRY","BAR","7"]}', $7, 'USD')
+	`, interruptedCycleID, session.ID, playerID, "fortune-slots", 
+		result.Balance.Amount, result.Balance.Amount-200, domain.CycleStatusInterrupted)
+	if err != nil {
+		t.Fatalf("Failed to create interrupted cycle: %v", err)
+	}
+
+	t.Run("VerifyInterruptedGameExists", func(t *testing.T) {
+		interrupted, err := engine.GetInterruptedGames(ctx, playerID)
+		if err != nil {
+			t.Fatalf("Failed to get interrupted games: %v", err)
+		}
+
+		if len(interrupted) != 1 {
+			t.Fatalf("Expected 1 interrupted game, got %d", len(interrupted))
+		}
+
+		if interrupted[0].WagerHeld.Amount != 200 {
+			t.Errorf("Expected wager held 200, got %d", interrupted[0].WagerHeld.Amount)
+		}
+	})
+
+	t.Run("ResolveByVoiding", func(t *testing.T) {
+		err := engine.VoidGame(ctx, interruptedCycleID, "Test resolution")
+		if err != nil {
+			t.Fatalf("Failed to void: %v", err)
+		}
+
+		// Verify no more interrupted games
+		interrupted, _ := engine.GetInterruptedGames(ctx, playerID)
+		if len(interrupted) != 0 {
+			t.Errorf("Expected 0 interrupted games after void, got %d", len(interrupted))
+		}
+	})
+}
+
+func TestLoadGames(t *testing.T) {
+	engine := New(nil, rng.New(), nil, nil, nil, "USD")
+
+	t.Run("DefaultGames", func(t *testing.T) {
+		for _, id := range []string{"fortune-slots", "lucky-sevens"} {
+			if _, err := engine.GetGame(id); err != nil {
+				t.Errorf("Expected default game %s, got error: %v", id, err)
+			}
+		}
+	})
+
+	t.Run("EmptyKeepsDefaults", func(t *testing.T) {
+		if err := engine.LoadGames(nil); err != nil {
+			t.Fatalf("Failed to load empty definitions: %v", err)
+		}
+		if len(engine.GetGames()) != 2 {
+			t.Errorf("Expected 2 default games, got %d", len(engine.GetGames()))
+		}
+	})
+
+	t.Run("InvalidDefinitionRejected", func(t *testing.T) {
+		err := engine.LoadGames([]GameDefinition{{
+			ID:             "low-rtp",
+			TheoreticalRTP: 0.50,
+			MinBet:         10,
+			MaxBet:         100,
+			Reels:          [][]Symbol{{SymbolSeven}, {SymbolSeven}, {SymbolSeven}},
+			Paytable:       map[string]int64{"7-7-7": 100},
+		}})
+		if !errors.Is(err, ErrInvalidDefinition) {
+			t.Errorf("Expected ErrInvalidDefinition, got %v", err)
+		}
+		if _, err := engine.GetGame("fortune-slots"); err != nil {
+			t.Error("Expected defaults to survive a rejected load")
+		}
+	})
+
+	t.Run("CustomGame", func(t *testing.T) {
+		err := engine.LoadGames([]GameDefinition{{
+			ID:             "all-sevens",
+			Name:           "All Sevens",
+			TheoreticalRTP: 0.95,
+			MinBet:         10,
+			MaxBet:         1000,
+			Reels:          [][]Symbol{{SymbolSeven}, {SymbolSeven}, {SymbolSeven}},
+			Paytable:       map[string]int64{"7-7-7": 95},
+		}})
+		if err != nil {
+			t.Fatalf("Failed to load games: %v", err)
+		}
+
+		if _, err := engine.GetGame("fortune-slots"); err != ErrGameNotFound {
+			t.Error("Expected loaded definitions to replace the defaults")
+		}
+
+		game, err := engine.GetGame("all-sevens")
+		if err != nil {
+			t.Fatalf("Failed to get custom game: %v", err)
+		}
+		if game.MinBet.Amount != 10 || game.MinBet.Currency != "USD" {
+			t.Errorf("Expected min bet 10 USD, got %d %s", game.MinBet.Amount, game.MinBet.Currency)
+		}
+
+		outcome, err := engine.generateSlotOutcome(context.Background(), game)
+		if err != nil {
+			t.Fatalf("Failed to generate outcome: %v", err)
+		}
+		if !outcome.IsWin || outcome.WinLines[0].Payout != 95 {
+			t.Errorf("Expected 7-7-7 win paying 95 from custom paytable, got %+v", outcome)
+		}
+	})
+}
+
+func TestGameReelConfigurations(t *testing.T) {
+	engine := New(nil, rng.New(), nil, nil, nil, "USD")
+
+	spins := 10000
+	frequencies := func(gameID string) map[Symbol]int {
+		game, err := engine.GetGame(gameID)
+		if err != nil {
+			t.Fatalf("Failed to get game %s: %v", gameID, err)
+		}
+		counts := make(map[Symbol]int)
+		for i := 0; i < spins; i++ {
+			outcome, err := engine.generateSlotOutcome(context.Background(), game)
+			if err != nil {
+				t.Fatalf("Failed to generate outcome: %v", err)
+			}
+			for _, s := range outcome.Reels {
+				counts[s]++
+			}
+		}
+		return counts
+	}
+
+	fortune := frequencies("fortune-slots")
+	lucky := frequencies("lucky-sevens")
+
+	t.Run("LuckySevensUsesOwnSymbols", func(t *testing.T) {
+		for _, s := range []Symbol{SymbolOrange, SymbolPlum, SymbolGrapes} {
+			if lucky[s] != 0 {
+				t.Errorf("Expected no %s on Lucky Sevens reels, got %d", s, lucky[s])
+			}
+			if fortune[s] == 0 {
+				t.Errorf("Expected %s on Fortune Slots reels", s)
+			}
+		}
+	})
+
+	t.Run("SevensDistributionDiffers", func(t *testing.T) {
+		// Lucky Sevens carries three sevens per reel versus one on Fortune Slots
+		if lucky[SymbolSeven] < 2*fortune[SymbolSeven] {
+			t.Errorf("Expected Lucky Sevens to land far more sevens, got %d vs %d",
+				lucky[SymbolSeven], fortune[SymbolSeven])
+		}
+	})
+
+	t.Run("PaytableSelectedPerGame", func(t *testing.T) {
+		reels := []Symbol{SymbolBell, SymbolBell, SymbolBell}
+		fortuneWins := engine.evaluateWins(fortuneSlotsPaytable, centerLine, [][]Symbol{reels})
+		luckyWins := engine.evaluateWins(luckySevensPaytable, centerLine, [][]Symbol{reels})
+		if len(fortuneWins) != 1 || len(luckyWins) != 1 {
+			t.Fatal("Expected BELL-BELL-BELL to win on both games")
+		}
+		if fortuneWins[0].Payout == luckyWins[0].Payout {
+			t.Errorf("Expected different BELL payouts, both got %d", luckyWins[0].Payout)
+		}
+	})
+}
+
+func TestReplayOutcome(t *testing.T) {
+	engine := New(nil, rng.New(), nil, nil, nil, "USD")
+	engine.SetRecordSeeds(true)
+
+	game, _ := engine.GetGame("fortune-slots")
+
+	t.Run("ReplayMatches", func(t *testing.T) {
+		for i := 0; i < 100; i++ {
+			outcome, err := engine.generateSlotOutcome(context.Background(), game)
+			if err != nil {
+				t.Fatalf("Failed to generate outcome: %v", err)
+			}
+			if outcome.Seed == "" {
+				t.Fatal("Expected seed to be recorded in outcome")
+			}
+
+			replayed, err := engine.replayOutcome(game.ID, outcome)
+			if err != nil {
+				t.Fatalf("Replay failed: %v", err)
+			}
+			for j := range outcome.Reels {
+				if replayed.Reels[j] != outcome.Reels[j] {
+					t.Fatalf("Expected replayed reels %v, got %v", outcome.Reels, replayed.Reels)
+				}
+			}
+		}
+	})
+
+	t.Run("TamperedOutcomeDetected", func(t *testing.T) {
+		outcome, _ := engine.generateSlotOutcome(context.Background(), game)
+		outcome.Reels[0], outcome.Reels[1], outcome.Reels[2] = SymbolSeven, SymbolSeven, SymbolSeven
+		outcome.WinLines = engine.evaluateWins(fortuneSlotsPaytable, centerLine, [][]Symbol{outcome.Reels})
+		outcome.IsWin = true
+
+		if _, err := engine.replayOutcome(game.ID, outcome); err != ErrReplayMismatch {
+			// A genuine 7-7-7 from the seed is possible but vanishingly rare
+			t.Errorf("Expected ErrReplayMismatch, got %v", err)
+		}
+	})
+
+	t.Run("NoSeed", func(t *testing.T) {
+		if _, err := engine.replayOutcome(game.ID, &SlotOutcome{}); err != ErrNoReplaySeed {
+			t.Errorf("Expected ErrNoReplaySeed, got %v", err)
+		}
+	})
+
+	t.Run("DefaultDoesNotRecordSeed", func(t *testing.T) {
+		plain := New(nil, rng.New(), nil, nil, nil, "USD")
+		outcome, err := plain.generateSlotOutcome(context.Background(), game)
+		if err != nil {
+			t.Fatalf("Failed to generate outcome: %v", err)
+		}
+		if outcome.Seed != "" {
+			t.Error("Expected no seed when recording is disabled")
+		}
+	})
+}
+
+func TestReplayCycle(t *testing.T) {
+	engine, playerID, cleanup := setupTestEngine(t)
+	defer cleanup()
+
+	engine.SetRecordSeeds(true)
+	ctx := context.Background()
+
+	session, err := engine.StartSession(ctx, playerID, "fortune-slots")
+	if err != nil {
+		t.Fatalf("Failed to start session: %v", err)
+	}
+
+	result, err := engine.Play(ctx, &PlayRequest{SessionID: session.ID, WagerAmount: 100})
+	if err != nil {
+		t.Fatalf("Failed to play: %v", err)
+	}
+
+	replayed, err := engine.ReplayCycle(ctx, result.CycleID)
+	if err != nil {
+		t.Fatalf("Failed to replay cycle: %v", err)
+	}
+	if replayed.IsWin != result.Outcome.IsWin {
+		t.Errorf("Expected replayed win %v, got %v", result.Outcome.IsWin, replayed.IsWin)
+	}
+
+	if _, err := engine.ReplayCycle(ctx, uuid.New().String()); err != ErrCycleNotFound {
+		t.Errorf("Expected ErrCycleNotFound, got %v", err)
+	}
+}
+
+func TestCycleRNGTrace(t *testing.T) {
+	engine, playerID, cleanup := setupTestEngine(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	session, err := engine.StartSession(ctx, playerID, "fortune-slots")
+	if err != nil {
+		t.Fatalf("Failed to start session: %v", err)
+	}
+
+	t.Run("DrawsReproduceReels", func(t *testing.T) {
+		engine.SetCertificationMode(true)
+		defer engine.SetCertificationMode(false)
+
+		result, err := engine.Play(ctx, &PlayRequest{SessionID: session.ID, WagerAmount: 100})
+		if err != nil {
+			t.Fatalf("Failed to play: %v", err)
+		}
+
+		draws, err := engine.GetCycleRNGTrace(ctx, result.CycleID)
+		if err != nil {
+			t.Fatalf("Failed to get RNG trace: %v", err)
+		}
+
+		def, _ := engine.definition("fortune-slots", DefaultRTPVariant)
+		if len(draws) < len(def.Reels) {
+			t.Fatalf("Expected at least %d draws, got %d", len(def.Reels), len(draws))
+		}
+		for i, reel := range def.Reels {
+			if draws[i].Max != int64(len(reel)) {
+				t.Errorf("Reel %d: expected draw from [0, %d), got max %d", i, len(reel), draws[i].Max)
+			}
+			if got := reel[draws[i].Value]; got != result.Outcome.Reels[i] {
+				t.Errorf("Reel %d: draw %d gives %s, outcome has %s", i, draws[i].Value, got, result.Outcome.Reels[i])
+			}
+		}
+	})
+
+	t.Run("NotRecordedByDefault", func(t *testing.T) {
+		result, err := engine.Play(ctx, &PlayRequest{SessionID: session.ID, WagerAmount: 100})
+		if err != nil {
+			t.Fatalf("Failed to play: %v", err)
+		}
+		if _, err := engine.GetCycleRNGTrace(ctx, result.CycleID); err != ErrNoRNGTrace {
+			t.Errorf("Expected ErrNoRNGTrace, got %v", err)
+		}
+	})
+
+	t.Run("UnknownCycle", func(t *testing.T) {
+		if _, err := engine.GetCycleRNGTrace(ctx, uuid.New().String()); err != ErrCycleNotFound {
+			t.Errorf("Expected ErrCycleNotFound, got %v", err)
+		}
+	})
+}
+
+func TestSimulateRTP(t *testing.T) {
+	engine := New(nil, rng.New(), nil, nil, nil, "USD")
+
+	spins := 2000000
+	if testing.Short() {
+		spins = 200000
+	}
+
+	for _, gameID := range []string{"fortune-slots", "lucky-sevens"} {
+		t.Run(gameID, func(t *testing.T) {
+			report, err := engine.SimulateRTP(gameID, spins)
+			if err != nil {
+				t.Fatalf("SimulateRTP failed: %v", err)
+			}
+
+			if report.TotalWagered != int64(spins)*rtpWagerUnit {
+				t.Errorf("Expected %d wagered, got %d", int64(spins)*rtpWagerUnit, report.TotalWagered)
+			}
+			if report.ConfidenceLow > report.EmpiricalRTP || report.ConfidenceHigh < report.EmpiricalRTP {
+				t.Errorf("Empirical RTP %.4f outside its own interval [%.4f, %.4f]",
+					report.EmpiricalRTP, report.ConfidenceLow, report.ConfidenceHigh)
+			}
+
+			// Allow four standard errors so the test is not flaky
+			tolerance := 4 * report.StdDev / math.Sqrt(float64(spins))
+			if diff := math.Abs(report.EmpiricalRTP - report.TheoreticalRTP); diff > tolerance {
+				t.Errorf("Empirical RTP %.4f differs from theoretical %.4f by more than %.4f",
+					report.EmpiricalRTP, report.TheoreticalRTP, tolerance)
+			}
+		})
+	}
+
+	t.Run("UnknownGame", func(t *testing.T) {
+		if _, err := engine.SimulateRTP("no-such-game", 10); err != ErrGameNotFound {
+			t.Errorf("Expected ErrGameNotFound, got %v", err)
+		}
+	})
+
+	t.Run("InvalidSpinCount", func(t *testing.T) {
+		if _, err := engine.SimulateRTP("fortune-slots", 0); err != ErrInvalidSpinCount {
+			t.Errorf("Expected ErrInvalidSpinCount, got %v", err)
+		}
+	})
+}
+
+// centerLine is the single payline used by one-row games
+var centerLine = [][]int{{0, 0, 0}}
+
+func TestPaylines(t *testing.T) {
+	engine := New(nil, rng.New(), nil, nil, nil, "USD")
+
+	// Three rows, five lines: top, center, bottom and both diagonals
+	paylines := [][]int{
+		{1, 1, 1},
+		{0, 0, 0},
+		{2, 2, 2},
+		{0, 1, 2},
+		{2, 1, 0},
+	}
+
+	t.Run("ScoresEachLine", func(t *testing.T) {
+		grid := [][]Symbol{
+			{SymbolBar, SymbolLemon, SymbolBell},
+			{SymbolBell, SymbolWild, SymbolBell},
+			{SymbolPlum, SymbolOrange, SymbolBar},
+		}
+
+		wins := engine.evaluateWins(fortuneSlotsPaytable, paylines, grid)
+
+		// Center BELL-WILD-BELL and diagonal BAR-WILD-BAR both win via the wild
+		payouts := map[int]int64{}
+		for _, w := range wins {
+			payouts[w.Line] = w.Payout
+		}
+		if len(wins) != 2 {
+			t.Fatalf("Expected 2 winning lines, got %d: %+v", len(wins), wins)
+		}
+		if payouts[1] != fortuneSlotsPaytable["BELL-BELL-BELL"] {
+			t.Errorf("Expected line 1 to pay BELL, got %d", payouts[1])
+		}
+		if payouts[4] != fortuneSlotsPaytable["BAR-BAR-BAR"] {
+			t.Errorf("Expected line 4 to pay BAR, got %d", payouts[4])
+		}
+
+		win := engine.calculateWin(&SlotOutcome{IsWin: true, WinLines: wins}, domain.Money{Amount: 100, Currency: "USD"})
+		expected := fortuneSlotsPaytable["BELL-BELL-BELL"] + fortuneSlotsPaytable["BAR-BAR-BAR"]
+		if win.Amount != expected {
+			t.Errorf("Expected total win %d, got %d", expected, win.Amount)
+		}
+	})
+
+	t.Run("NoWinningLines", func(t *testing.T) {
+		grid := [][]Symbol{
+			{SymbolBar, SymbolLemon, SymbolBell},
+			{SymbolPlum, SymbolBell, SymbolOrange},
+			{SymbolLemon, SymbolOrange, SymbolGrapes},
+		}
+		if wins := engine.evaluateWins(fortuneSlotsPaytable, paylines, grid); len(wins) != 0 {
+			t.Errorf("Expected no wins, got %+v", wins)
+		}
+	})
+
+	t.Run("MultiRowGameSpins", func(t *testing.T) {
+		def := GameDefinition{
+			ID:             "five-line",
+			Name:           "Five Line",
+			TheoreticalRTP: 0.95,
+			MinBet:         10,
+			MaxBet:         1000,
+			Reels:          fortuneSlotsReels,
+			Paytable:       fortuneSlotsPaytable,
+			Rows:           3,
+			Paylines:       paylines,
+		}
+		if err := engine.RegisterGame(def.game("USD"), def); err != nil {
+			t.Fatalf("Failed to register game: %v", err)
+		}
+		game, _ := engine.GetGame("five-line")
+
+		for i := 0; i < 100; i++ {
+			outcome, err := engine.generateSlotOutcome(context.Background(), game)
+			if err != nil {
+				t.Fatalf("Failed to generate outcome: %v", err)
+			}
+			if len(outcome.Grid) != 3 || len(outcome.Grid[0]) != 3 {
+				t.Fatalf("Expected 3x3 grid, got %v", outcome.Grid)
+			}
+			for reel := range outcome.Reels {
+				if outcome.Grid[1][reel] != outcome.Reels[reel] {
+					t.Fatalf("Expected center row to match reels, got %v vs %v", outcome.Grid[1], outcome.Reels)
+				}
+			}
+			for _, w := range outcome.WinLines {
+				if w.Line < 1 || w.Line > len(paylines) {
+					t.Errorf("Unexpected line number %d", w.Line)
+				}
+			}
+		}
+	})
+
+	t.Run("SingleLineByDefault", func(t *testing.T) {
+		game, _ := engine.GetGame("fortune-slots")
+		outcome, err := engine.generateSlotOutcome(context.Background(), game)
+		if err != nil {
+			t.Fatalf("Failed to generate outcome: %v", err)
+		}
+		if outcome.Grid != nil {
+			t.Errorf("Expected no grid for a single-row game, got %v", outcome.Grid)
+		}
+		for _, w := range outcome.WinLines {
+			if w.Line != 1 {
+				t.Errorf("Expected only line 1, got %d", w.Line)
+			}
+		}
+	})
+
+	t.Run("InvalidPaylineRejected", func(t *testing.T) {
+		def := GameDefinition{
+			ID:             "bad-lines",
+			TheoreticalRTP: 0.95,
+			MinBet:         10,
+			MaxBet:         1000,
+			Reels:          fortuneSlotsReels,
+			Paytable:       fortuneSlotsPaytable,
+			Rows:           3,
+			Paylines:       [][]int{{0, 3, 0}},
+		}
+		if err := def.Validate(); !errors.Is(err, ErrInvalidDefinition) {
+			t.Errorf("Expected ErrInvalidDefinition, got %v", err)
+		}
+	})
+}
+
+// bonusDefinition builds a test game whose reels always show reel symbol,
+// with a free spins feature triggered by three scatters
+func bonusDefinition(symbol Symbol) GameDefinition {
+	reel := []Symbol{symbol}
+	return GameDefinition{
+		ID:             "bonus-slots",
+		Name:           "Bonus Slots",
+		TheoreticalRTP: 0.95,
+		MinBet:         10,
+		MaxBet:         10000,
+		Reels:          [][]Symbol{reel, reel, reel},
+		Paytable:       map[string]int64{"CHERRY-CHERRY-CHERRY": 100},
+		FreeSpins: &FreeSpinsConfig{
+			Symbol:     SymbolScatter,
+			Trigger:    3,
+			Spins:      3,
+			Multiplier: 2,
+		},
+	}
+}
+
+func TestFeatureState(t *testing.T) {
+	engine := New(nil, rng.New(), nil, nil, nil, "USD")
+
+	def := bonusDefinition(SymbolScatter)
+	if err := engine.RegisterGame(def.game("USD"), def); err != nil {
+		t.Fatalf("Failed to register game: %v", err)
+	}
+	game, _ := engine.GetGame("bonus-slots")
+	stake := domain.Money{Amount: 100, Currency: "USD"}
+
+	t.Run("ScattersAwardFreeSpins", func(t *testing.T) {
+		outcome, err := engine.generateSlotOutcome(context.Background(), game)
+		if err != nil {
+			t.Fatalf("Failed to generate outcome: %v", err)
+		}
+		if outcome.FreeSpins != 3 {
+			t.Fatalf("Expected 3 free spins awarded, got %d", outcome.FreeSpins)
+		}
+
+		feature := engine.advanceFeature(game.ID, nil, outcome, stake, domain.Money{}, false)
+		if !feature.Active() || feature.FreeSpinsRemaining != 3 || feature.Multiplier != 2 || feature.Wager != 100 {
+			t.Errorf("Unexpected feature state: %+v", feature)
+		}
+	})
+
+	t.Run("FreeSpinsConsumedAndEnd", func(t *testing.T) {
+		feature := &domain.FeatureState{FreeSpinsRemaining: 2, FreeSpinsAwarded: 2, Multiplier: 2, Wager: 100}
+		noScatter := &SlotOutcome{}
+
+		feature = engine.advanceFeature(game.ID, feature, noScatter, stake, domain.Money{Amount: 50}, true)
+		if feature.FreeSpinsRemaining != 1 || feature.TotalWin != 50 {
+			t.Errorf("Unexpected feature state: %+v", feature)
+		}
+		if feature = engine.advanceFeature(game.ID, feature, noScatter, stake, domain.Money{}, true); feature != nil {
+			t.Errorf("Expected feature to end, got %+v", feature)
+		}
+	})
+
+	t.Run("MultiplierAppliedToWin", func(t *testing.T) {
+		outcome := &SlotOutcome{
+			IsWin:      true,
+			Multiplier: 2,
+			WinLines:   []WinLine{{Line: 1, Count: 3, Payout: 100}},
+		}
+		if win := engine.calculateWin(outcome, stake); win.Amount != 200 {
+			t.Errorf("Expected multiplied win 200, got %d", win.Amount)
+		}
+	})
+}
+
+func TestFreeSpins(t *testing.T) {
+	engine, playerID, cleanup := setupTestEngine(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	// Every spin lands three scatters, guaranteeing the trigger
+	trigger := bonusDefinition(SymbolScatter)
+	if err := engine.RegisterGame(trigger.game("USD"), trigger); err != nil {
+		t.Fatalf("Failed to register game: %v", err)
+	}
+
+	session, err := engine.StartSession(ctx, playerID, "bonus-slots")
+	if err != nil {
+		t.Fatalf("Failed to start session: %v", err)
+	}
+
+	result, err := engine.Play(ctx, &PlayRequest{SessionID: session.ID, WagerAmount: 500})
+	if err != nil {
+		t.Fatalf("Play failed: %v", err)
+	}
+	if result.FreeSpin {
+		t.Error("Expected the triggering spin to be paid")
+	}
+	if !result.Feature.Active() || result.Feature.FreeSpinsRemaining != 3 {
+		t.Fatalf("Expected 3 free spins, got %+v", result.Feature)
+	}
+	balance := result.Balance.Amount
+
+	// Swap to cherry-only reels so free spins win without retriggering
+	bonus := bonusDefinition(SymbolCherry)
+	if err := engine.RegisterGame(bonus.game("USD"), bonus); err != nil {
+		t.Fatalf("Failed to register game: %v", err)
+	}
+
+	for i := 2; i >= 0; i-- {
+		result, err = engine.Play(ctx, &PlayRequest{SessionID: session.ID, WagerAmount: 500})
+		if err != nil {
+			t.Fatalf("Free spin failed: %v", err)
+		}
+		if !result.FreeSpin {
+			t.Fatal("Expected a free spin")
+		}
+		if result.WagerAmount.Amount != 0 {
+			t.Errorf("Expected no wager on free spin, got %d", result.WagerAmount.Amount)
+		}
+		// 1x paytable at a $5 stake, doubled by the feature multiplier
+		if result.WinAmount.Amount != 1000 {
+			t.Errorf("Expected win 1000, got %d", result.WinAmount.Amount)
+		}
+		if result.Balance.Amount != balance+1000 {
+			t.Errorf("Expected balance %d, got %d", balance+1000, result.Balance.Amount)
+		}
+		balance = result.Balance.Amount
+
+		if i > 0 && result.Feature.FreeSpinsRemaining != i {
+			t.Errorf("Expected %d free spins remaining, got %+v", i, result.Feature)
+		}
+	}
+
+	if result.Feature != nil {
+		t.Errorf("Expected feature to end, got %+v", result.Feature)
+	}
+	stored, _ := engine.GetSession(ctx, session.ID)
+	if stored.FeatureState != nil {
+		t.Errorf("Expected no stored feature state, got %+v", stored.FeatureState)
+	}
+
+	// The next spin is paid again
+	result, err = engine.Play(ctx, &PlayRequest{SessionID: session.ID, WagerAmount: 500})
+	if err != nil {
+		t.Fatalf("Play failed: %v", err)
+	}
+	if result.FreeSpin || result.WagerAmount.Amount != 500 {
+		t.Errorf("Expected a paid spin after the feature, got %+v", result)
+	}
+}
+
+func TestConcurrentFreeSpins(t *testing.T) {
+	engine, playerID, cleanup := setupTestEngine(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	trigger := bonusDefinition(SymbolScatter)
+	if err := engine.RegisterGame(trigger.game("USD"), trigger); err != nil {
+		t.Fatalf("Failed to register game: %v", err)
+	}
+	session, err := engine.StartSession(ctx, playerID, "bonus-slots")
+	if err != nil {
+		t.Fatalf("Failed to start session: %v", err)
+	}
+	if _, err := engine.Play(ctx, &PlayRequest{SessionID: session.ID, WagerAmount: 500}); err != nil {
+		t.Fatalf("Play failed: %v", err)
+	}
+
+	// Cherry-only reels so free spins do not retrigger
+	bonus := bonusDefinition(SymbolCherry)
+	if err := engine.RegisterGame(bonus.game("USD"), bonus); err != nil {
+		t.Fatalf("Failed to register game: %v", err)
+	}
+
+	// Four cycles race for three free spins; each is played exactly once
+	results := make(chan *PlayResult, 4)
+	for i := 0; i < 4; i++ {
+		go func() {
+			result, err := engine.Play(ctx, &PlayRequest{SessionID: session.ID, WagerAmount: 500})
+			if err != nil {
+				t.Errorf("Play failed: %v", err)
+			}
+			results <- result
+		}()
+	}
+	free, paid := 0, 0
+	for i := 0; i < 4; i++ {
+		result := <-results
+		if result == nil {
+			continue
+		}
+		if result.FreeSpin {
+			free++
+		} else {
+			paid++
+		}
+	}
+	if free != 3 || paid != 1 {
+		t.Errorf("Expected 3 free spins and 1 paid spin, got %d and %d", free, paid)
+	}
+
+	stored, _ := engine.GetSession(ctx, session.ID)
+	if stored.FeatureState != nil {
+		t.Errorf("Expected the feature used up, got %+v", stored.FeatureState)
+	}
+
+	t.Run("Retriggers", func(t *testing.T) {
+		// Scatter reels retrigger three more free spins on every free spin
+		if err := engine.RegisterGame(trigger.game("USD"), trigger); err != nil {
+			t.Fatalf("Failed to register game: %v", err)
+		}
+		if _, err := engine.Play(ctx, &PlayRequest{SessionID: session.ID, WagerAmount: 500}); err != nil {
+			t.Fatalf("Play failed: %v", err)
+		}
+
+		const spins = 4
+		var wg sync.WaitGroup
+		for i := 0; i < spins; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if _, err := engine.Play(ctx, &PlayRequest{SessionID: session.ID, WagerAmount: 500}); err != nil {
+					t.Errorf("Free spin failed: %v", err)
+				}
+			}()
+		}
+		wg.Wait()
+
+		// Every free spin uses one and retriggers three, none lost
+		stored, _ := engine.GetSession(ctx, session.ID)
+		feature := stored.FeatureState
+		if feature == nil || feature.FreeSpinsRemaining != 3+spins*2 || feature.FreeSpinsAwarded != 3+spins*3 {
+			t.Errorf("Expected %d remaining of %d awarded, got %+v", 3+spins*2, 3+spins*3, feature)
+		}
+	})
+}
+
+func TestMultiStepRound(t *testing.T) {
+	engine, playerID, cleanup := setupTestEngine(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	// Cherry-only reels make every step a known 1x win
+	def := bonusDefinition(SymbolCherry)
+	def.FreeSpins = nil
+	if err := engine.RegisterGame(def.game("USD"), def); err != nil {
+		t.Fatalf("Failed to register game: %v", err)
+	}
+	session, err := engine.StartSession(ctx, playerID, "bonus-slots")
+	if err != nil {
+		t.Fatalf("Failed to start session: %v", err)
+	}
+	cycleStatus := func(cycleID string) domain.GameCycleStatus {
+		var status domain.GameCycleStatus
+		engine.db.QueryRowContext(ctx, "SELECT status FROM game_cycles WHERE id = $1", cycleID).Scan(&status)
+		return status
+	}
+
+	t.Run("PlayStepToCompletion", func(t *testing.T) {
+		result, err := engine.StartRound(ctx, &PlayRequest{SessionID: session.ID, WagerAmount: 500}, 2)
+		if err != nil {
+			t.Fatalf("StartRound failed: %v", err)
+		}
+
+		result, err = engine.PlayStep(ctx, result.CycleID)
+		if err != nil {
+			t.Fatalf("PlayStep failed: %v", err)
+		}
+		if result.Round.Pending() {
+			t.Error("Expected round to be complete")
+		}
+		if status := cycleStatus(result.CycleID); status != domain.CycleStatusCompleted {
+			t.Errorf("Expected status completed, got %s", status)
+		}
+
+		if _, err := engine.PlayStep(ctx, result.CycleID); err != ErrCycleNotFound {
+			t.Errorf("Expected ErrCycleNotFound for a completed round, got %v", err)
+		}
+	})
+
+	t.Run("InvalidSteps", func(t *testing.T) {
+		if _, err := engine.StartRound(ctx, &PlayRequest{SessionID: session.ID, WagerAmount: 500}, 0); err != ErrInvalidSteps {
+			t.Errorf("Expected ErrInvalidSteps, got %v", err)
+		}
+	})
+
+	t.Run("ConcurrentSteps", func(t *testing.T) {
+		result, err := engine.StartRound(ctx, &PlayRequest{SessionID: session.ID, WagerAmount: 500}, 2)
+		if err != nil {
+			t.Fatalf("StartRound failed: %v", err)
+		}
+		before, _ := engine.wallet.GetBalance(ctx, playerID)
+
+		// Only one of the two calls plays the final step and credits the win
+		errs := make(chan error, 2)
+		for i := 0; i < 2; i++ {
+			go func() {
+				_, err := engine.PlayStep(ctx, result.CycleID)
+				errs <- err
+			}()
+		}
+		played := 0
+		for i := 0; i < 2; i++ {
+			if err := <-errs; err == nil {
+				played++
+			} else if err != ErrCycleNotFound && err != ErrRoundComplete {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		}
+		if played != 1 {
+			t.Errorf("Expected exactly one step played, got %d", played)
+		}
+
+		after, _ := engine.wallet.GetBalance(ctx, playerID)
+		if after.Available.Amount != before.Available.Amount+1000 {
+			t.Errorf("Expected the win credited once (%d), got %d", before.Available.Amount+1000, after.Available.Amount)
+		}
+	})
+
+	// Interruption also interrupts the session, so this runs last
+	t.Run("InterruptedBetweenSteps", func(t *testing.T) {
+		result, err := engine.StartRound(ctx, &PlayRequest{SessionID: session.ID, WagerAmount: 500}, 2)
+		if err != nil {
+			t.Fatalf("StartRound failed: %v", err)
+		}
+		if result.Round == nil || result.Round.Step != 1 || !result.Round.Pending() {
+			t.Fatalf("Expected round at step 1 of 2, got %+v", result.Round)
+		}
+		if status := cycleStatus(result.CycleID); status != domain.CycleStatusInProgress {
+			t.Errorf("Expected status in_progress after first step, got %s", status)
+		}
+		balanceMidRound := result.Balance.Amount
+
+		if err := engine.MarkInterrupted(ctx, result.CycleID, "connection_lost"); err != nil {
+			t.Fatalf("MarkInterrupted failed: %v", err)
+		}
+
+		interrupted, _ := engine.GetInterruptedGames(ctx, playerID)
+		if len(interrupted) != 1 {
+			t.Fatalf("Expected 1 interrupted game, got %d", len(interrupted))
+		}
+		var saved RoundState
+		if err := json.Unmarshal(interrupted[0].GameState, &saved); err != nil || saved.Step != 1 {
+			t.Errorf("Expected saved state at step 1, got %s", interrupted[0].GameState)
+		}
+
+		resumed, err := engine.ResumeGame(ctx, result.CycleID)
+		if err != nil {
+			t.Fatalf("ResumeGame failed: %v", err)
+		}
+		if resumed.Round.Step != 2 || len(resumed.Round.Outcomes) != 2 {
+			t.Errorf("Expected both steps played, got %+v", resumed.Round)
+		}
+		// Two steps at 1x a $5 stake, credited once the round completes
+		if resumed.WinAmount.Amount != 1000 {
+			t.Errorf("Expected win 1000, got %d", resumed.WinAmount.Amount)
+		}
+		if resumed.Balance.Amount != balanceMidRound+1000 {
+			t.Errorf("Expected balance %d, got %d", balanceMidRound+1000, resumed.Balance.Amount)
+		}
+		if status := cycleStatus(result.CycleID); status != domain.CycleStatusCompleted {
+			t.Errorf("Expected status completed, got %s", status)
+		}
+	})
+}
+
+func TestConcurrentWagerLimit(t *testing.T) {
+	engine, playerID, cleanup := setupTestEngine(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	_, err := engine.limits.SetWagerLimit(ctx, &limits.SetWagerLimitRequest{
+		PlayerID: playerID,
+		Period:   "daily",
+		Amount:   1500, // $15
+	})
+	if err != nil {
+		t.Fatalf("Failed to set wager limit: %v", err)
+	}
+	session, _ := engine.StartSession(ctx, playerID, "fortune-slots")
+
+	// Six spins race for a limit that fits three
+	errs := make(chan error, 6)
+	for i := 0; i < 6; i++ {
+		go func() {
+			_, err := engine.Play(ctx, &PlayRequest{SessionID: session.ID, WagerAmount: 500})
+			errs <- err
+		}()
+	}
+	played := 0
+	for i := 0; i < 6; i++ {
+		if err := <-errs; err == nil {
+			played++
+		} else if err != ErrWagerLimitExceeded {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	}
+	if played != 3 {
+		t.Errorf("Expected 3 spins within the limit, got %d", played)
+	}
+}
+
+func TestPlayWagerLimit(t *testing.T) {
+	engine, playerID, cleanup := setupTestEngine(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	_, err := engine.limits.SetWagerLimit(ctx, &limits.SetWagerLimitRequest{
+		PlayerID: playerID,
+		Period:   "daily",
+		Amount:   1500, // $15
+	})
+	if err != nil {
+		t.Fatalf("Failed to set wager limit: %v", err)
+	}
+
+	session, _ := engine.StartSession(ctx, playerID, "fortune-slots")
+
+	t.Run("PlayUpToLimit", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			if _, err := engine.Play(ctx, &PlayRequest{SessionID: session.ID, WagerAmount: 500}); err != nil {
+				t.Fatalf("Spin %d within limit failed: %v", i+1, err)
+			}
+		}
+	})
+
+	t.Run("NextSpinRejected", func(t *testing.T) {
+		before, _ := engine.wallet.GetBalance(ctx, playerID)
+
+		_, err := engine.Play(ctx, &PlayRequest{SessionID: session.ID, WagerAmount: 500})
+		if err != ErrWagerLimitExceeded {
+			t.Fatalf("Expected ErrWagerLimitExceeded, got %v", err)
+		}
+
+		after, _ := engine.wallet.GetBalance(ctx, playerID)
+		if after.Available.Amount != before.Available.Amount {
+			t.Errorf("Expected no balance change, got %d -> %d", before.Available.Amount, after.Available.Amount)
+		}
+	})
+
+	t.Run("SelfExcludedRejected", func(t *testing.T) {
+		if _, err := engine.limits.SelfExclude(ctx, playerID, "test", nil); err != nil {
+			t.Fatalf("Failed to self-exclude: %v", err)
+		}
+
+		_, err := engine.Play(ctx, &PlayRequest{SessionID: session.ID, WagerAmount: 10})
+		if err != ErrPlayerExcluded {
+			t.Errorf("Expected ErrPlayerExcluded, got %v", err)
+		}
+	})
+}
+
+func TestPlayLossLimit(t *testing.T) {
+	engine, playerID, cleanup := setupTestEngine(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	// Lemon-only reels never win
+	def := bonusDefinition(SymbolLemon)
+	def.FreeSpins = nil
+	if err := engine.RegisterGame(def.game("USD"), def); err != nil {
+		t.Fatalf("Failed to register game: %v", err)
+	}
+
+	_, err := engine.limits.SetLossLimit(ctx, &limits.SetLossLimitRequest{
+		PlayerID: playerID,
+		Period:   "daily",
+		Amount:   1000, // $10
+	})
+	if err != nil {
+		t.Fatalf("Failed to set loss limit: %v", err)
+	}
+
+	session, _ := engine.StartSession(ctx, playerID, "bonus-slots")
+
+	for i := 0; i < 2; i++ {
+		if _, err := engine.Play(ctx, &PlayRequest{SessionID: session.ID, WagerAmount: 500}); err != nil {
+			t.Fatalf("Losing spin %d within limit failed: %v", i+1, err)
+		}
+	}
+
+	loss, _ := engine.limits.GetNetLoss(ctx, playerID, time.Now().Add(-time.Hour), time.Now().Add(time.Minute))
+	if loss.Amount != 1000 {
+		t.Errorf("Expected net loss 1000, got %d", loss.Amount)
+	}
+
+	// At the ceiling, any further wager is blocked
+	if _, err := engine.Play(ctx, &PlayRequest{SessionID: session.ID, WagerAmount: 10}); err != ErrLossLimitExceeded {
+		t.Errorf("Expected ErrLossLimitExceeded, got %v", err)
+	}
+}
+
+func TestConcurrentLossLimit(t *testing.T) {
+	engine, playerID, cleanup := setupTestEngine(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	// Lemon-only reels never win
+	def := bonusDefinition(SymbolLemon)
+	def.FreeSpins = nil
+	if err := engine.RegisterGame(def.game("USD"), def); err != nil {
+		t.Fatalf("Failed to register game: %v", err)
+	}
+	_, err := engine.limits.SetLossLimit(ctx, &limits.SetLossLimitRequest{
+		PlayerID: playerID,
+		Period:   "daily",
+		Amount:   1000, // $10
+	})
+	if err != nil {
+		t.Fatalf("Failed to set loss limit: %v", err)
+	}
+	session, _ := engine.StartSession(ctx, playerID, "bonus-slots")
+
+	// Five losing spins race for a limit that fits two
+	errs := make(chan error, 5)
+	for i := 0; i < 5; i++ {
+		go func() {
+			_, err := engine.Play(ctx, &PlayRequest{SessionID: session.ID, WagerAmount: 500})
+			errs <- err
+		}()
+	}
+	for i := 0; i < 5; i++ {
+		if err := <-errs; err != nil && err != ErrLossLimitExceeded {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	}
+
+	loss, _ := engine.limits.GetNetLoss(ctx, playerID, time.Now().Add(-time.Hour), time.Now().Add(time.Minute))
+	if loss.Amount != 1000 {
+		t.Errorf("Expected net loss held at 1000, got %d", loss.Amount)
+	}
+}
+
+// jackpotDefinition is a game that never pays a line win, so balances move
+// only by wagers and the jackpot
+func jackpotDefinition(probability float64) GameDefinition {
+	reel := []Symbol{SymbolBar}
+	return GameDefinition{
+		ID:             "jackpot-slots",
+		Name:           "Jackpot Slots",
+		TheoreticalRTP: 0.95,
+		MinBet:         10,
+		MaxBet:         10000,
+		Reels:          [][]Symbol{reel, reel, reel},
+		Paytable:       map[string]int64{"CHERRY-CHERRY-CHERRY": 100},
+		Jackpot: &JackpotConfig{
+			ContributionRate:   0.01,
+			TriggerProbability: probability,
+			Seed:               5000,
+		},
+	}
+}
+
+func TestJackpotAccrual(t *testing.T) {
+	engine, playerID, cleanup := setupTestEngine(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	def := jackpotDefinition(1e-15)
+	if err := engine.RegisterGame(def.game("USD"), def); err != nil {
+		t.Fatalf("Failed to register game: %v", err)
+	}
+	session, err := engine.StartSession(ctx, playerID, "jackpot-slots")
+	if err != nil {
+		t.Fatalf("Failed to start session: %v", err)
+	}
+	before, _ := engine.wallet.GetBalance(ctx, playerID)
+
+	const spins = 20
+	var result *PlayResult
+	for i := 0; i < spins; i++ {
+		result, err = engine.Play(ctx, &PlayRequest{SessionID: session.ID, WagerAmount: 1000})
+		if err != nil {
+			t.Fatalf("Play failed: %v", err)
+		}
+		if result.Jackpot == nil || result.Jackpot.Contribution.Amount != 10 {
+			t.Fatalf("Expected a 10 cent contribution, got %+v", result.Jackpot)
+		}
+	}
+
+	// Seed plus 1% of every wager
+	if result.Jackpot.Pool.Amount != 5000+spins*10 {
+		t.Errorf("Expected pool %d, got %d", 5000+spins*10, result.Jackpot.Pool.Amount)
+	}
+	pool, err := engine.GetJackpot(ctx, "jackpot-slots", "USD")
+	if err != nil {
+		t.Fatalf("Failed to get jackpot: %v", err)
+	}
+	if pool.Amount != result.Jackpot.Pool.Amount {
+		t.Errorf("Expected stored pool %d, got %d", result.Jackpot.Pool.Amount, pool.Amount)
+	}
+
+	// Contributions come out of the wager, not on top of it
+	after, _ := engine.wallet.GetBalance(ctx, playerID)
+	if after.Available.Amount != before.Available.Amount-spins*1000 {
+		t.Errorf("Expected balance %d, got %d", before.Available.Amount-spins*1000, after.Available.Amount)
+	}
+
+	page, err := engine.wallet.(*wallet.Service).GetTransactionsFiltered(ctx, playerID, wallet.TransactionFilter{
+		Types: []domain.TransactionType{domain.TxTypeJackpot},
+	})
+	if err != nil {
+		t.Fatalf("Failed to get transactions: %v", err)
+	}
+	if page.Total != spins {
+		t.Errorf("Expected %d jackpot contributions, got %d", spins, page.Total)
+	}
+}
+
+func TestJackpotWin(t *testing.T) {
+	engine, playerID, cleanup := setupTestEngine(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	// Every paid cycle wins the pool
+	def := jackpotDefinition(1)
+	if err := engine.RegisterGame(def.game("USD"), def); err != nil {
+		t.Fatalf("Failed to register game: %v", err)
+	}
+	session, err := engine.StartSession(ctx, playerID, "jackpot-slots")
+	if err != nil {
+		t.Fatalf("Failed to start session: %v", err)
+	}
+	before, _ := engine.wallet.GetBalance(ctx, playerID)
+
+	result, err := engine.Play(ctx, &PlayRequest{SessionID: session.ID, WagerAmount: 1000})
+	if err != nil {
+		t.Fatalf("Play failed: %v", err)
+	}
+
+	jp := result.Jackpot
+	if jp == nil || !jp.IsJackpotWin {
+		t.Fatalf("Expected a jackpot win, got %+v", jp)
+	}
+	if jp.WinAmount.Amount != 5010 {
+		t.Errorf("Expected the seed plus contribution (5010) paid, got %d", jp.WinAmount.Amount)
+	}
+	if jp.Pool.Amount != 5000 {
+		t.Errorf("Expected the pool reset to the seed, got %d", jp.Pool.Amount)
+	}
+	if result.WinAmount.Amount != 5010 {
+		t.Errorf("Expected the cycle win to include the jackpot, got %d", result.WinAmount.Amount)
+	}
+
+	after, _ := engine.wallet.GetBalance(ctx, playerID)
+	if after.Available.Amount != before.Available.Amount-1000+5010 {
+		t.Errorf("Expected balance %d, got %d", before.Available.Amount-1000+5010, after.Available.Amount)
+	}
+
+	events, err := engine.audit.GetEvents(ctx, &audit.EventFilter{PlayerID: playerID, Type: audit.EventJackpotWin})
+	if err != nil {
+		t.Fatalf("Failed to get audit events: %v", err)
+	}
+	if len(events) != 1 {
+		t.Errorf("Expected 1 jackpot win event, got %d", len(events))
+	}
+}
+
+func TestJackpotDefinitionValidation(t *testing.T) {
+	for name, jp := range map[string]JackpotConfig{
+		"NoContribution":    {ContributionRate: 0, TriggerProbability: 0.001},
+		"WholeWager":        {ContributionRate: 1, TriggerProbability: 0.001},
+		"NeverTriggers":     {ContributionRate: 0.01, TriggerProbability: 0},
+		"NegativeSeed":      {ContributionRate: 0.01, TriggerProbability: 0.001, Seed: -1},
+		"ProbabilityAbove1": {ContributionRate: 0.01, TriggerProbability: 1.5},
+	} {
+		t.Run(name, func(t *testing.T) {
+			def := jackpotDefinition(0.001)
+			def.Jackpot = &jp
+			if err := def.Validate(); !errors.Is(err, ErrInvalidDefinition) {
+				t.Errorf("Expected ErrInvalidDefinition, got %v", err)
+			}
+		})
+	}
+}
+
+func TestGamble(t *testing.T) {
+	engine, playerID, cleanup := setupTestEngine(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	// Cherry-only reels win every spin
+	def := bonusDefinition(SymbolCherry)
+	if err := engine.RegisterGame(def.game("USD"), def); err != nil {
+		t.Fatalf("Failed to register game: %v", err)
+	}
+	session, err := engine.StartSession(ctx, playerID, "bonus-slots")
+	if err != nil {
+		t.Fatalf("Failed to start session: %v", err)
+	}
+
+	play := func(t *testing.T) *PlayResult {
+		t.Helper()
+		result, err := engine.Play(ctx, &PlayRequest{SessionID: session.ID, WagerAmount: 100})
+		if err != nil {
+			t.Fatalf("Play failed: %v", err)
+		}
+		return result
+	}
+
+	// The flip is random, so gamble fresh wins until both sides have been seen
+	var seenWin, seenLoss bool
+	for i := 0; i < 100 && !(seenWin && seenLoss); i++ {
+		result := play(t)
+		win := result.WinAmount.Amount
+		if win <= 0 {
+			t.Fatalf("Expected a winning spin, got %d", win)
+		}
+
+		gamble, err := engine.Gamble(ctx, result.CycleID, GambleHeads)
+		if err != nil {
+			t.Fatalf("Gamble failed: %v", err)
+		}
+
+		cycle, _ := engine.GetCycle(ctx, result.CycleID)
+		var outcome SlotOutcome
+		json.Unmarshal(cycle.Outcome, &outcome)
+		if len(outcome.Gamble) != 1 || outcome.Gamble[0] != gamble.Step {
+			t.Errorf("Expected the gamble step recorded in the outcome, got %+v", outcome.Gamble)
+		}
+
+		if gamble.Won {
+			seenWin = true
+			if gamble.WinAmount.Amount != 2*win || cycle.WinAmount.Amount != 2*win {
+				t.Errorf("Expected win doubled to %d, got %d (cycle %d)", 2*win, gamble.WinAmount.Amount, cycle.WinAmount.Amount)
+			}
+			if gamble.Balance.Amount != result.Balance.Amount+win {
+				t.Errorf("Expected balance %d after doubling, got %d", result.Balance.Amount+win, gamble.Balance.Amount)
+			}
+		} else {
+			seenLoss = true
+			if gamble.WinAmount.Amount != 0 || cycle.WinAmount.Amount != 0 {
+				t.Errorf("Expected win zeroed, got %d (cycle %d)", gamble.WinAmount.Amount, cycle.WinAmount.Amount)
+			}
+			if gamble.Balance.Amount != result.Balance.Amount-win {
+				t.Errorf("Expected balance %d after losing, got %d", result.Balance.Amount-win, gamble.Balance.Amount)
+			}
+			// Nothing is left to gamble
+			if _, err := engine.Gamble(ctx, result.CycleID, GambleHeads); err != ErrCycleNotGambleable {
+				t.Errorf("Expected ErrCycleNotGambleable after a loss, got %v", err)
+			}
+		}
+	}
+	if !seenWin || !seenLoss {
+		t.Fatalf("Expected both outcomes in 100 gambles (won: %v, lost: %v)", seenWin, seenLoss)
+	}
+
+	t.Run("BankedWin", func(t *testing.T) {
+		first := play(t)
+		play(t) // Playing on banks the first win
+		if _, err := engine.Gamble(ctx, first.CycleID, GambleTails); err != ErrCycleNotGambleable {
+			t.Errorf("Expected ErrCycleNotGambleable, got %v", err)
+		}
+
+		collected := play(t)
+		if err := engine.CollectWin(ctx, collected.CycleID); err != nil {
+			t.Fatalf("Failed to collect win: %v", err)
+		}
+		if _, err := engine.Gamble(ctx, collected.CycleID, GambleTails); err != ErrCycleNotGambleable {
+			t.Errorf("Expected ErrCycleNotGambleable, got %v", err)
+		}
+	})
+
+	t.Run("WithdrawnWin", func(t *testing.T) {
+		result := play(t)
+
+		// The whole balance, win included, is cashed out before the flip
+		var held int64
+		engine.db.QueryRowContext(ctx, "SELECT real_money_amount FROM balances WHERE player_id = $1", playerID).Scan(&held)
+		if _, err := engine.db.ExecContext(ctx, "UPDATE balances SET real_money_amount = 0 WHERE player_id = $1", playerID); err != nil {
+			t.Fatalf("Failed to empty balance: %v", err)
+		}
+		defer engine.db.ExecContext(ctx, "UPDATE balances SET real_money_amount = $1 WHERE player_id = $2", held, playerID)
+
+		if _, err := engine.Gamble(ctx, result.CycleID, GambleHeads); err != ErrGambleNotCovered {
+			t.Errorf("Expected ErrGambleNotCovered, got %v", err)
+		}
+	})
+
+	t.Run("GambleLimit", func(t *testing.T) {
+		engine.SetMaxGambles(0)
+		defer engine.SetMaxGambles(DefaultMaxGambles)
+
+		if _, err := engine.Gamble(ctx, play(t).CycleID, GambleHeads); err != ErrGambleLimitReached {
+			t.Errorf("Expected ErrGambleLimitReached, got %v", err)
+		}
+	})
+
+	t.Run("InvalidChoice", func(t *testing.T) {
+		if _, err := engine.Gamble(ctx, play(t).CycleID, "edge"); err != ErrInvalidGambleChoice {
+			t.Errorf("Expected ErrInvalidGambleChoice, got %v", err)
+		}
+	})
+
+	t.Run("LosingCycle", func(t *testing.T) {
+		// Bar-only reels never win
+		lose := bonusDefinition(SymbolBar)
+		if err := engine.RegisterGame(lose.game("USD"), lose); err != nil {
+			t.Fatalf("Failed to register game: %v", err)
+		}
+		result := play(t)
+		if result.WinAmount.Amount != 0 {
+			t.Fatalf("Expected a losing spin, got %d", result.WinAmount.Amount)
+		}
+		if _, err := engine.Gamble(ctx, result.CycleID, GambleHeads); err != ErrCycleNotGambleable {
+			t.Errorf("Expected ErrCycleNotGambleable, got %v", err)
+		}
+	})
+}
+
+// variantDefinition is a game that wins every spin, paying 1x on its default
+// variant and 0.5x on its "94" variant
+func variantDefinition() GameDefinition {
+	reel := []Symbol{SymbolCherry}
+	return GameDefinition{
+		ID:             "variant-slots",
+		Name:           "Variant Slots",
+		TheoreticalRTP: 0.96,
+		MinBet:         10,
+		MaxBet:         10000,
+		Reels:          [][]Symbol{reel, reel, reel},
+		Paytable:       map[string]int64{"CHERRY-CHERRY-CHERRY": 100},
+		Variants: map[string]RTPVariant{
+			"94": {
+				TheoreticalRTP: 0.94,
+				Reels:          [][]Symbol{reel, reel, reel},
+				Paytable:       map[string]int64{"CHERRY-CHERRY-CHERRY": 50},
+			},
+		},
+	}
+}
+
+func TestSetGameRTPVariant(t *testing.T) {
+	engine, playerID, cleanup := setupTestEngine(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	def := variantDefinition()
+	if err := engine.RegisterGame(def.game("USD"), def); err != nil {
+		t.Fatalf("Failed to register game: %v", err)
+	}
+
+	// A session opened before the change keeps the default variant
+	before, err := engine.StartSession(ctx, playerID, "variant-slots")
+	if err != nil {
+		t.Fatalf("Failed to start session: %v", err)
+	}
+
+	if err := engine.SetGameRTPVariant(ctx, "variant-slots", "94", "ops@example.com"); err != nil {
+		t.Fatalf("Failed to set RTP variant: %v", err)
+	}
+
+	game, _ := engine.GetGame("variant-slots")
+	if game.RTPVariant != "94" || game.TheoreticalRTP != 0.94 {
+		t.Errorf("Expected variant 94 at 0.94 RTP, got %s at %.2f", game.RTPVariant, game.TheoreticalRTP)
+	}
+
+	result, err := engine.Play(ctx, &PlayRequest{SessionID: before.ID, WagerAmount: 100})
+	if err != nil {
+		t.Fatalf("Play failed: %v", err)
+	}
+	if result.WinAmount.Amount != 100 {
+		t.Errorf("Expected the open session to pay the default variant (100), got %d", result.WinAmount.Amount)
+	}
+
+	engine.EndSession(ctx, before.ID)
+	after, err := engine.StartSession(ctx, playerID, "variant-slots")
+	if err != nil {
+		t.Fatalf("Failed to start session: %v", err)
+	}
+	result, err = engine.Play(ctx, &PlayRequest{SessionID: after.ID, WagerAmount: 100})
+	if err != nil {
+		t.Fatalf("Play failed: %v", err)
+	}
+	if result.WinAmount.Amount != 50 {
+		t.Errorf("Expected a new session to pay variant 94 (50), got %d", result.WinAmount.Amount)
+	}
+	if result.Outcome.RTPVariant != "94" {
+		t.Errorf("Expected variant 94 recorded in the outcome, got %q", result.Outcome.RTPVariant)
+	}
+
+	events, err := engine.audit.GetEvents(ctx, &audit.EventFilter{Type: audit.EventConfigurationChange})
+	if err != nil {
+		t.Fatalf("Failed to get audit events: %v", err)
+	}
+	if len(events) != 1 {
+		t.Fatalf("Expected 1 configuration change event, got %d", len(events))
+	}
+	data, _ := json.Marshal(events[0].Data)
+	if !strings.Contains(string(data), `"authorized_by":"ops@example.com"`) || !strings.Contains(string(data), `"variant":"94"`) {
+		t.Errorf("Expected the variant and authorizer in the event, got %s", data)
+	}
+
+	t.Run("RestoredAfterRestart", func(t *testing.T) {
+		restarted := New(engine.db, rng.New(), engine.wallet, nil, engine.audit, "USD")
+		restarted.RegisterGame(def.game("USD"), def)
+		if err := restarted.LoadRTPVariants(ctx); err != nil {
+			t.Fatalf("Failed to load RTP variants: %v", err)
+		}
+		if game, _ := restarted.GetGame("variant-slots"); game.RTPVariant != "94" {
+			t.Errorf("Expected variant 94 after restart, got %s", game.RTPVariant)
+		}
+	})
+
+	t.Run("Rejected", func(t *testing.T) {
+		if err := engine.SetGameRTPVariant(ctx, "variant-slots", "90", "ops@example.com"); !errors.Is(err, ErrUnknownRTPVariant) {
+			t.Errorf("Expected ErrUnknownRTPVariant, got %v", err)
+		}
+		if err := engine.SetGameRTPVariant(ctx, "no-such-game", "94", "ops@example.com"); err != ErrGameNotFound {
+			t.Errorf("Expected ErrGameNotFound, got %v", err)
+		}
+		if err := engine.SetGameRTPVariant(ctx, "variant-slots", DefaultRTPVariant, ""); err != ErrAuthorizationRequired {
+			t.Errorf("Expected ErrAuthorizationRequired, got %v", err)
+		}
+		if game, _ := engine.GetGame("variant-slots"); game.RTPVariant != "94" {
+			t.Errorf("Expected rejected changes to leave variant 94, got %s", game.RTPVariant)
+		}
+	})
+}
+
+func TestRTPVariantValidation(t *testing.T) {
+	def := variantDefinition()
+	v := def.Variants["94"]
+	v.TheoreticalRTP = 0.5
+	def.Variants["94"] = v
+	if err := def.Validate(); !errors.Is(err, ErrInvalidDefinition) {
+		t.Errorf("Expected ErrInvalidDefinition for a variant below minimum RTP, got %v", err)
+	}
+
+	def = variantDefinition()
+	def.Variants[DefaultRTPVariant] = def.Variants["94"]
+	if err := def.Validate(); !errors.Is(err, ErrInvalidDefinition) {
+		t.Errorf("Expected ErrInvalidDefinition for a variant named %q, got %v", DefaultRTPVariant, err)
+	}
+}
+
+func TestInitGameConfig(t *testing.T) {
+	engine := New(nil, rng.New(), nil, nil, nil, "USD")
+	jackpot := jackpotDefinition(0.001)
+	if err := engine.RegisterGame(jackpot.game("USD"), jackpot); err != nil {
+		t.Fatalf("Failed to register game: %v", err)
+	}
+
+	for _, def := range append(DefaultGameDefinitions(), jackpot) {
+		t.Run(def.ID, func(t *testing.T) {
+			cfg, err := engine.InitGameConfig(def.ID, "EUR")
+			if err != nil {
+				t.Fatalf("InitGameConfig failed: %v", err)
+			}
+
+			if cfg.MinBet != (domain.Money{Amount: def.MinBet, Currency: "EUR"}) ||
+				cfg.MaxBet != (domain.Money{Amount: def.MaxBet, Currency: "EUR"}) {
+				t.Errorf("Expected bets %d-%d EUR, got %v-%v", def.MinBet, def.MaxBet, cfg.MinBet, cfg.MaxBet)
+			}
+			if cfg.TheoreticalRTP != def.TheoreticalRTP {
+				t.Errorf("Expected RTP %v, got %v", def.TheoreticalRTP, cfg.TheoreticalRTP)
+			}
+			if cfg.RTPVariant != DefaultRTPVariant {
+				t.Errorf("Expected the default variant, got %q", cfg.RTPVariant)
+			}
+			if cfg.Reels != len(def.Reels) || cfg.Rows != def.rows() || cfg.Paylines != len(def.paylines()) {
+				t.Errorf("Expected %d reels, %d rows, %d lines, got %d, %d, %d",
+					len(def.Reels), def.rows(), len(def.paylines()), cfg.Reels, cfg.Rows, cfg.Paylines)
+			}
+			if cfg.Currency != "EUR" || cfg.DecimalPlaces != 2 {
+				t.Errorf("Expected EUR with 2 decimals, got %s with %d", cfg.Currency, cfg.DecimalPlaces)
+			}
+
+			has := func(feature string) bool {
+				for _, f := range cfg.Features {
+					if f == feature {
+						return true
+					}
+				}
+				return false
+			}
+			if has(FeatureFreeSpins) != (def.FreeSpins != nil) || has(FeatureJackpot) != (def.Jackpot != nil) {
+				t.Errorf("Features %v do not match the definition", cfg.Features)
+			}
+			if !has(FeatureGamble) {
+				t.Errorf("Expected gamble in features %v", cfg.Features)
+			}
+		})
+	}
+
+	t.Run("ZeroDecimalCurrency", func(t *testing.T) {
+		cfg, err := engine.InitGameConfig("fortune-slots", "JPY")
+		if err != nil {
+			t.Fatalf("InitGameConfig failed: %v", err)
+		}
+		if cfg.DecimalPlaces != 0 {
+			t.Errorf("Expected 0 decimals for JPY, got %d", cfg.DecimalPlaces)
+		}
+	})
+
+	t.Run("UnknownGame", func(t *testing.T) {
+		if _, err := engine.InitGameConfig("no-such-game", "USD"); !errors.Is(err, ErrGameNotFound) {
+			t.Errorf("Expected ErrGameNotFound, got %v", err)
+		}
+	})
+}
+
+func TestTranslatePateplayError(t *testing.T) {
+	tests := []struct {
+		code string
+		want error
+	}{
+		{pateplay.ErrBetLimitReached, ErrWagerLimitExceeded},
+		{pateplay.ErrLossLimitReached, ErrLossLimitExceeded},
+		{pateplay.ErrTimeLimitReached, ErrTimeLimitExceeded},
+	}
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			// As returned through PateplaySettlement
+			err := fmt.Errorf("failed to settle on Pateplay: %w", &pateplay.APIError{Code: tt.code, Message: "limit"})
+			if got := TranslatePateplayError(err); got != tt.want {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+
+	t.Run("OtherErrorsUnchanged", func(t *testing.T) {
+		for _, err := range []error{
+			&pateplay.APIError{Code: pateplay.ErrInsufficientBalance},
+			&pateplay.APIError{Code: pateplay.ErrBetLimit90Percent},
+			errors.New("connection reset"),
+		} {
+			if got := TranslatePateplayError(err); got != err {
+				t.Errorf("Expected %v unchanged, got %v", err, got)
+			}
 		}
 	})
 }
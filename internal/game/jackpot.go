@@ -0,0 +1,144 @@
+// Package game - Progressive jackpots fed by a share of every paid wager
+package game
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/alexbotov/rgs/internal/audit"
+	"github.com/alexbotov/rgs/internal/domain"
+)
+
+// JackpotState is a game's progressive jackpot after a cycle
+type JackpotState struct {
+	Pool         domain.Money `json:"pool"`         // Pool after the cycle, reset to the seed after a win
+	Contribution domain.Money `json:"contribution"` // Share of the wager added to the pool
+	IsJackpotWin bool         `json:"is_jackpot_win"`
+	WinAmount    domain.Money `json:"win_amount"` // Pool paid to the player on a jackpot win
+}
+
+// GetJackpot returns a game's current jackpot pool in the given currency. A
+// pool nobody has contributed to yet stands at the seed.
+func (e *Engine) GetJackpot(ctx context.Context, gameID, currency string) (domain.Money, error) {
+	def, ok := e.definitions[gameID]
+	if !ok {
+		return domain.Money{}, ErrGameNotFound
+	}
+	if def.Jackpot == nil {
+		return domain.Money{}, fmt.Errorf("game %s has no jackpot", gameID)
+	}
+
+	var amount int64
+	err := e.db.QueryRowContext(ctx, `
+		SELECT amount FROM jackpot_pools WHERE game_id = $1 AND currency = $2
+	`, gameID, currency).Scan(&amount)
+	if err == sql.ErrNoRows {
+		amount = def.Jackpot.Seed
+	} else if err != nil {
+		return domain.Money{}, fmt.Errorf("failed to get jackpot: %w", err)
+	}
+	return domain.Money{Amount: amount, Currency: currency}, nil
+}
+
+// drawJackpot draws for the game's jackpot on a paid wager (GLI-19 §4.5).
+// It runs inside the cycle's transaction; the contribution and payout are
+// moved by the cycle's settlement, and the pool by updateJackpot once the
+// cycle has settled. Only a jackpot win locks the pool here, to pay out what
+// it holds; other cycles take the lock last, for the increment alone. Free
+// spins (a zero wager) neither contribute nor draw.
+func (e *Engine) drawJackpot(ctx context.Context, dbTx *sql.Tx, session *domain.GameSession, wager domain.Money) (*JackpotState, error) {
+	def, ok := e.definitions[session.GameID]
+	if !ok || def.Jackpot == nil || wager.Amount <= 0 {
+		return nil, nil
+	}
+	cfg := def.Jackpot
+
+	state := &JackpotState{
+		Contribution: domain.Money{Amount: int64(float64(wager.Amount) * cfg.ContributionRate), Currency: wager.Currency},
+		WinAmount:    domain.Money{Amount: 0, Currency: wager.Currency},
+	}
+
+	r, err := e.rng.GenerateFloatContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to draw jackpot: %w", err)
+	}
+	if r >= cfg.TriggerProbability {
+		return state, nil
+	}
+
+	pool, err := lockJackpotPool(ctx, dbTx, session.GameID, wager.Currency, cfg.Seed)
+	if err != nil {
+		return nil, err
+	}
+	if pool += state.Contribution.Amount; pool > 0 {
+		state.IsJackpotWin = true
+		state.WinAmount.Amount = pool
+	}
+	return state, nil
+}
+
+// updateJackpot adds the cycle's contribution to the game's pool, or resets
+// the pool to the seed after a jackpot win. The cycle calls it last, so the
+// pool stays locked only until the cycle commits.
+func (e *Engine) updateJackpot(ctx context.Context, dbTx *sql.Tx, session *domain.GameSession, state *JackpotState) error {
+	if state == nil {
+		return nil
+	}
+	cfg := e.definitions[session.GameID].Jackpot
+	currency := state.Contribution.Currency
+	now := time.Now().UTC()
+
+	var pool int64
+	var err error
+	if state.IsJackpotWin {
+		pool = cfg.Seed
+		_, err = dbTx.ExecContext(ctx, `
+			UPDATE jackpot_pools SET amount = $1, updated_at = $2 WHERE game_id = $3 AND currency = $4
+		`, pool, now, session.GameID, currency)
+	} else {
+		err = dbTx.QueryRowContext(ctx, `
+			INSERT INTO jackpot_pools (game_id, currency, amount, updated_at)
+			VALUES ($1, $2, $3 + $4, $5)
+			ON CONFLICT (game_id, currency) DO UPDATE SET amount = jackpot_pools.amount + $4, updated_at = $5
+			RETURNING amount
+		`, session.GameID, currency, cfg.Seed, state.Contribution.Amount, now).Scan(&pool)
+	}
+	if err != nil {
+		return err
+	}
+
+	state.Pool = domain.Money{Amount: pool, Currency: currency}
+	return nil
+}
+
+// lockJackpotPool locks a game's pool, creating it at the seed if nobody has
+// contributed yet, and returns its amount
+func lockJackpotPool(ctx context.Context, dbTx *sql.Tx, gameID, currency string, seed int64) (int64, error) {
+	_, err := dbTx.ExecContext(ctx, `
+		INSERT INTO jackpot_pools (game_id, currency, amount, updated_at)
+		VALUES ($1, $2, $3, $4) ON CONFLICT (game_id, currency) DO NOTHING
+	`, gameID, currency, seed, time.Now().UTC())
+	if err != nil {
+		return 0, err
+	}
+	var pool int64
+	err = dbTx.QueryRowContext(ctx, `
+		SELECT amount FROM jackpot_pools WHERE game_id = $1 AND currency = $2 FOR UPDATE
+	`, gameID, currency).Scan(&pool)
+	return pool, err
+}
+
+// auditJackpotWin records a jackpot payout once the cycle has committed
+// GLI-19 §2.8.8 - Significant events
+func (e *Engine) auditJackpotWin(ctx context.Context, session *domain.GameSession, cycleID string, jackpot *JackpotState) {
+	e.audit.Log(ctx, audit.EventJackpotWin, domain.SeverityInfo,
+		fmt.Sprintf("Jackpot win: %.2f %s", jackpot.WinAmount.Float64(), jackpot.WinAmount.Currency),
+		map[string]interface{}{
+			"cycle_id": cycleID,
+			"win":      jackpot.WinAmount.Float64(),
+			"game_id":  session.GameID,
+		},
+		audit.WithPlayer(session.PlayerID), audit.WithSession(session.ID))
+}
@@ -0,0 +1,76 @@
+// Package game - Configuration a game client needs at launch
+package game
+
+import (
+	"github.com/alexbotov/rgs/internal/domain"
+)
+
+// Game features a client may need to show controls for
+const (
+	FeatureFreeSpins = "free_spins"
+	FeatureJackpot   = "jackpot"
+	FeatureGamble    = "gamble"
+)
+
+// LaunchConfig is what a game client needs to render a game and accept bets:
+// the bet limits, how to format amounts, and which features to show
+// GLI-19 §4.4.1: Paytable information must be available to the player
+type LaunchConfig struct {
+	GameID         string       `json:"game_id"`
+	Name           string       `json:"name"`
+	Type           string       `json:"type"`
+	Enabled        bool         `json:"enabled"`
+	RTPVariant     string       `json:"rtp_variant"`
+	TheoreticalRTP float64      `json:"theoretical_rtp"`
+	MinBet         domain.Money `json:"min_bet"`
+	MaxBet         domain.Money `json:"max_bet"`
+
+	Currency      string `json:"currency"`
+	DecimalPlaces int    `json:"decimal_places"` // Of the currency's minor unit
+
+	Reels    int      `json:"reels"`
+	Rows     int      `json:"rows"`
+	Paylines int      `json:"paylines"`
+	Features []string `json:"features"`
+}
+
+// InitGameConfig assembles the launch configuration of a game for a player
+// of the given currency, on the game's active RTP variant
+func (e *Engine) InitGameConfig(gameID, currency string) (*LaunchConfig, error) {
+	game, err := e.GetGame(gameID)
+	if err != nil {
+		return nil, err
+	}
+	def, ok := e.definition(gameID, game.RTPVariant)
+	if !ok {
+		return nil, ErrGameNotFound
+	}
+
+	features := []string{}
+	if def.FreeSpins != nil {
+		features = append(features, FeatureFreeSpins)
+	}
+	if def.Jackpot != nil {
+		features = append(features, FeatureJackpot)
+	}
+	if e.maxGambles > 0 {
+		features = append(features, FeatureGamble)
+	}
+
+	return &LaunchConfig{
+		GameID:         game.ID,
+		Name:           game.Name,
+		Type:           game.Type,
+		Enabled:        game.Enabled,
+		RTPVariant:     game.RTPVariant,
+		TheoreticalRTP: game.TheoreticalRTP,
+		MinBet:         domain.Money{Amount: def.MinBet, Currency: currency},
+		MaxBet:         domain.Money{Amount: def.MaxBet, Currency: currency},
+		Currency:       currency,
+		DecimalPlaces:  domain.CurrencyExponent(currency),
+		Reels:          len(def.Reels),
+		Rows:           def.rows(),
+		Paylines:       len(def.paylines()),
+		Features:       features,
+	}, nil
+}
@@ -0,0 +1,371 @@
+// Package game - Multi-step game rounds
+// Compliant with GLI-19 §4.3.3, §4.16
+package game
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/alexbotov/rgs/internal/audit"
+	"github.com/alexbotov/rgs/internal/database"
+	"github.com/alexbotov/rgs/internal/domain"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidSteps  = errors.New("a round needs at least one step")
+	ErrRoundComplete = errors.New("game round has no pending steps")
+)
+
+// RoundState is the persisted mid-round state of a multi-step game cycle.
+// Each step is a spin at the round stake; wins accumulate and are credited
+// when the final step resolves.
+// GLI-19 §4.16: Interrupted games resume from the point of interruption
+type RoundState struct {
+	Step       int            `json:"step"`        // Steps completed
+	TotalSteps int            `json:"total_steps"` // Steps in the round
+	Stake      int64          `json:"stake"`       // Stake in cents each step is played at
+	Outcomes   []*SlotOutcome `json:"outcomes"`    // Outcome of each completed step
+	WinAmount  int64          `json:"win_amount"`  // Win accumulated so far, not yet credited
+}
+
+// Pending returns true if steps remain to be played
+func (r *RoundState) Pending() bool {
+	return r.Step < r.TotalSteps
+}
+
+// StartRound debits the wager for a multi-step round, records the cycle as
+// in progress and plays the first step (GLI-19 §4.3.3)
+func (e *Engine) StartRound(ctx context.Context, req *PlayRequest, steps int) (*PlayResult, error) {
+	if steps < 1 {
+		return nil, ErrInvalidSteps
+	}
+
+	done, err := e.beginCycle()
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	session, err := e.GetSession(ctx, req.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	if session.Status != domain.GameSessionActive {
+		return nil, ErrSessionNotActive
+	}
+
+	game, err := e.GetGame(session.GameID)
+	if err != nil {
+		return nil, err
+	}
+	if !game.Enabled {
+		return nil, ErrGameDisabled
+	}
+	game.RTPVariant = session.RTPVariant
+
+	// Validate wager (GLI-19 §4.3.3.b)
+	wager := domain.Money{Amount: req.WagerAmount, Currency: session.OpeningBalance.Currency}
+	if wager.Amount < game.MinBet.Amount || wager.Amount > game.MaxBet.Amount {
+		return nil, ErrInvalidWager
+	}
+
+	// Responsible gaming checks (GLI-19 §2.5.5)
+	if err := e.checkExcluded(ctx, session.PlayerID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	cycleID := uuid.New().String()
+	round := &roundCycle{
+		id:        cycleID,
+		sessionID: session.ID,
+		playerID:  session.PlayerID,
+		game:      game,
+		wager:     wager,
+	}
+
+	// The wager and the cycle it pays for commit together (GLI-19 §4.16)
+	var result *PlayResult
+	var before, newBalance *domain.Balance
+	err = database.WithTx(ctx, e.db, func(dbTx *sql.Tx) error {
+		// Lock the balance for the rest of the step
+		balance, err := e.wallet.GetBalanceTx(ctx, dbTx, session.PlayerID)
+		if err != nil {
+			return err
+		}
+		if balance.Available.Amount < wager.Amount {
+			return ErrInsufficientBalance
+		}
+		if err := e.checkWagerLimits(ctx, dbTx, session.PlayerID, wager); err != nil {
+			return err
+		}
+
+		// Deduct wager (GLI-19 §4.3.3.b)
+		if _, err := e.wallet.PlaceWagerTx(ctx, dbTx, session.PlayerID, wager, session.GameID, cycleID); err != nil {
+			return err
+		}
+		before = balance
+		afterWager, err := e.wallet.GetBalanceTx(ctx, dbTx, session.PlayerID)
+		if err != nil {
+			return err
+		}
+
+		state := &RoundState{TotalSteps: steps, Stake: wager.Amount}
+		stateJSON, _ := json.Marshal(state)
+
+		// Store the cycle as in progress until the final step resolves (GLI-19 §2.8.2)
+		_, err = dbTx.ExecContext(ctx, `
+			INSERT INTO game_cycles (id, session_id, player_id, game_id, started_at, wager_amount, win_amount, balance_before, balance_after, status, currency, game_state)
+			VALUES ($1, $2, $3, $4, $5, $6, 0, $7, $8, $9, $10, $11)
+		`, cycleID, session.ID, session.PlayerID, session.GameID, now, wager.Amount,
+			balance.Available.Amount, afterWager.Available.Amount, domain.CycleStatusInProgress, wager.Currency, string(stateJSON))
+		if err != nil {
+			return err
+		}
+
+		result, newBalance, err = e.advanceRound(ctx, dbTx, round, state, false)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	e.roundCommitted(ctx, round, result, newBalance, true)
+	e.convertMetBonus(ctx, session.PlayerID, before, newBalance)
+	return result, nil
+}
+
+// PlayStep plays the next step of an in-progress multi-step round. The win
+// is credited and the cycle completed when the final step resolves.
+func (e *Engine) PlayStep(ctx context.Context, cycleID string) (*PlayResult, error) {
+	done, err := e.beginCycle()
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	// Lock the cycle so concurrent steps of a round run one at a time
+	var cycle *roundCycle
+	var result *PlayResult
+	var newBalance *domain.Balance
+	err = database.WithTx(ctx, e.db, func(dbTx *sql.Tx) error {
+		var state *RoundState
+		cycle, state, err = e.loadRound(ctx, dbTx, cycleID, domain.CycleStatusInProgress)
+		if err != nil {
+			return err
+		}
+		if state == nil || !state.Pending() {
+			return ErrRoundComplete
+		}
+
+		result, newBalance, err = e.advanceRound(ctx, dbTx, cycle, state, false)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	e.roundCommitted(ctx, cycle, result, newBalance, false)
+	return result, nil
+}
+
+// SaveGameState persists the mid-round state of an unresolved cycle so it can
+// be restored after an interruption
+// GLI-19 §4.16: Interrupted Games
+func (e *Engine) SaveGameState(ctx context.Context, cycleID string, state json.RawMessage) error {
+	res, err := e.db.ExecContext(ctx, `
+		UPDATE game_cycles SET game_state = $1, last_step_at = $2 WHERE id = $3 AND status IN ($4, $5)
+	`, string(state), time.Now().UTC(), cycleID, domain.CycleStatusInProgress, domain.CycleStatusInterrupted)
+	if err != nil {
+		return fmt.Errorf("failed to save game state: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrCycleNotFound
+	}
+	return nil
+}
+
+// roundCycle is the cycle data needed to play the steps of a round
+type roundCycle struct {
+	id        string
+	sessionID string
+	playerID  string
+	game      *domain.Game
+	wager     domain.Money
+}
+
+// loadRound reads and locks a cycle with the given status and its round
+// state. The state is nil for single-step cycles.
+func (e *Engine) loadRound(ctx context.Context, dbTx *sql.Tx, cycleID string, status domain.GameCycleStatus) (*roundCycle, *RoundState, error) {
+	var cycle roundCycle
+	var gameID, currency, variant string
+	var wager int64
+	var gameState sql.NullString
+
+	err := dbTx.QueryRowContext(ctx, `
+		SELECT gc.id, gc.session_id, gc.player_id, gc.game_id, gc.wager_amount, gs.currency, gc.game_state,
+		       COALESCE(gs.rtp_variant, '')
+		FROM game_cycles gc
+		JOIN game_sessions gs ON gc.session_id = gs.id
+		WHERE gc.id = $1 AND gc.status = $2
+		FOR UPDATE OF gc
+	`, cycleID, status).Scan(&cycle.id, &cycle.sessionID, &cycle.playerID, &gameID, &wager, &currency, &gameState, &variant)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil, ErrCycleNotFound
+		}
+		return nil, nil, err
+	}
+
+	cycle.game, err = e.GetGame(gameID)
+	if err != nil {
+		return nil, nil, err
+	}
+	cycle.game.RTPVariant = variant
+	cycle.wager = domain.Money{Amount: wager, Currency: currency}
+
+	if !gameState.Valid {
+		return &cycle, nil, nil
+	}
+	var state RoundState
+	if err := json.Unmarshal([]byte(gameState.String), &state); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse game state: %w", err)
+	}
+	return &cycle, &state, nil
+}
+
+// advanceRound plays the next step, or every remaining step when all is set,
+// saving the state and completing the cycle after the last step. It runs
+// inside dbTx and returns the player's balance after the step.
+func (e *Engine) advanceRound(ctx context.Context, dbTx *sql.Tx, cycle *roundCycle, state *RoundState, all bool) (*PlayResult, *domain.Balance, error) {
+	stake := domain.Money{Amount: state.Stake, Currency: cycle.wager.Currency}
+
+	var outcome *SlotOutcome
+	for state.Pending() {
+		// Generate outcome using RNG (GLI-19 §4.5)
+		var err error
+		outcome, err = e.generateSlotOutcome(ctx, cycle.game)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to generate outcome: %w", err)
+		}
+
+		state.Step++
+		state.Outcomes = append(state.Outcomes, outcome)
+		state.WinAmount += e.calculateWin(outcome, stake).Amount
+
+		if !all {
+			break
+		}
+	}
+
+	stateJSON, _ := json.Marshal(state)
+	_, err := dbTx.ExecContext(ctx, `
+		UPDATE game_cycles SET game_state = $1, last_step_at = $2 WHERE id = $3
+	`, string(stateJSON), time.Now().UTC(), cycle.id)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to save game state: %w", err)
+	}
+
+	if state.Pending() {
+		balance, err := e.wallet.GetBalanceTx(ctx, dbTx, cycle.playerID)
+		if err != nil {
+			return nil, nil, err
+		}
+		return &PlayResult{
+			CycleID:     cycle.id,
+			Outcome:     outcome,
+			WagerAmount: cycle.wager,
+			WinAmount:   domain.Money{Amount: state.WinAmount, Currency: cycle.wager.Currency},
+			Balance:     balance.Available,
+			Round:       state,
+		}, balance, nil
+	}
+
+	return e.completeRound(ctx, dbTx, cycle, state)
+}
+
+// completeRound credits the accumulated win and moves the cycle to completed
+// inside dbTx
+func (e *Engine) completeRound(ctx context.Context, dbTx *sql.Tx, cycle *roundCycle, state *RoundState) (*PlayResult, *domain.Balance, error) {
+	winAmount := domain.Money{Amount: state.WinAmount, Currency: cycle.wager.Currency}
+
+	// Credit win if any (GLI-19 §4.3.3.d)
+	if winAmount.Amount > 0 {
+		if _, err := e.wallet.CreditWinTx(ctx, dbTx, cycle.playerID, winAmount, cycle.game.ID, cycle.id); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	newBalance, err := e.wallet.GetBalanceTx(ctx, dbTx, cycle.playerID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var outcome *SlotOutcome
+	if len(state.Outcomes) > 0 {
+		outcome = state.Outcomes[len(state.Outcomes)-1]
+	}
+	outcomeJSON, _ := json.Marshal(outcome)
+	now := time.Now().UTC()
+
+	_, err = dbTx.ExecContext(ctx, `
+		UPDATE game_cycles SET status = $1, completed_at = $2, win_amount = $3, balance_after = $4, outcome = $5
+		WHERE id = $6
+	`, domain.CycleStatusCompleted, now, winAmount.Amount, newBalance.Available.Amount, string(outcomeJSON), cycle.id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Update session stats
+	_, err = dbTx.ExecContext(ctx, `
+		UPDATE game_sessions SET
+			last_activity_at = $1,
+			current_balance = $2,
+			total_wagered = total_wagered + $3,
+			total_won = total_won + $4,
+			games_played = games_played + 1
+		WHERE id = $5
+	`, now, newBalance.Available.Amount, cycle.wager.Amount, winAmount.Amount, cycle.sessionID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &PlayResult{
+		CycleID:     cycle.id,
+		Outcome:     outcome,
+		WagerAmount: cycle.wager,
+		WinAmount:   winAmount,
+		Balance:     newBalance.Available,
+		Round:       state,
+	}, newBalance, nil
+}
+
+// roundCommitted tells the player's connections about the money a round
+// step moved, and audits the round once it has completed. It runs after the
+// step's transaction has committed.
+func (e *Engine) roundCommitted(ctx context.Context, cycle *roundCycle, result *PlayResult, balance *domain.Balance, wagered bool) {
+	completed := !result.Round.Pending()
+	if completed && result.WinAmount.Amount > 0 {
+		e.wallet.PublishBalance(balance, domain.TxTypeWin)
+	} else if wagered {
+		e.wallet.PublishBalance(balance, domain.TxTypeWager)
+	}
+	if !completed {
+		return
+	}
+
+	e.audit.Log(ctx, "game_round_completed", domain.SeverityInfo,
+		fmt.Sprintf("Multi-step round completed: %s", cycle.id),
+		map[string]interface{}{
+			"cycle_id":   cycle.id,
+			"game_id":    cycle.game.ID,
+			"steps":      result.Round.TotalSteps,
+			"win_amount": result.WinAmount.Float64(),
+		},
+		audit.WithPlayer(cycle.playerID), audit.WithSession(cycle.sessionID))
+}
@@ -0,0 +1,91 @@
+// Package game - RTP verification tooling
+// Compliant with GLI-19 §4.7
+package game
+
+import (
+	"context"
+	"errors"
+	"math"
+
+	"github.com/alexbotov/rgs/internal/domain"
+)
+
+var ErrInvalidSpinCount = errors.New("spin count must be positive")
+
+// rtpWagerUnit is the stake used for each simulated spin (one unit bet)
+const rtpWagerUnit = 100
+
+// rtpConfidenceZ is the z-score for the reported 95% confidence interval
+const rtpConfidenceZ = 1.96
+
+// RTPReport summarizes a simulated run of a game
+// GLI-19 §4.7: Game Payout Percentages
+type RTPReport struct {
+	GameID         string  `json:"game_id"`
+	Spins          int     `json:"spins"`
+	TotalWagered   int64   `json:"total_wagered"` // In cents
+	TotalWon       int64   `json:"total_won"`     // In cents
+	EmpiricalRTP   float64 `json:"empirical_rtp"`
+	TheoreticalRTP float64 `json:"theoretical_rtp"`
+	StdDev         float64 `json:"std_dev"`         // Per-spin standard deviation of return
+	ConfidenceLow  float64 `json:"confidence_low"`  // Lower bound of the 95% interval
+	ConfidenceHigh float64 `json:"confidence_high"` // Upper bound of the 95% interval
+}
+
+// Contains reports whether rtp lies inside the report's confidence interval
+func (r *RTPReport) Contains(rtp float64) bool {
+	return rtp >= r.ConfidenceLow && rtp <= r.ConfidenceHigh
+}
+
+// SimulateRTP plays the given number of spins through the game's outcome and
+// win calculation and reports the empirical RTP. No wallet, session or
+// database state is touched, so it is safe to run against a live engine.
+// GLI-19 §4.7.1: Verification of theoretical RTP
+func (e *Engine) SimulateRTP(gameID string, spins int) (RTPReport, error) {
+	if spins <= 0 {
+		return RTPReport{}, ErrInvalidSpinCount
+	}
+
+	game, err := e.GetGame(gameID)
+	if err != nil {
+		return RTPReport{}, err
+	}
+
+	wager := domain.Money{Amount: rtpWagerUnit, Currency: e.currency}
+	report := RTPReport{
+		GameID:         gameID,
+		Spins:          spins,
+		TheoreticalRTP: game.TheoreticalRTP,
+	}
+
+	// Accumulate the per-spin return (win / wager) for the variance
+	var sumSquares float64
+	for i := 0; i < spins; i++ {
+		outcome, err := e.generateSlotOutcome(context.Background(), game)
+		if err != nil {
+			return RTPReport{}, err
+		}
+		win := e.calculateWin(outcome, wager)
+
+		report.TotalWagered += wager.Amount
+		report.TotalWon += win.Amount
+
+		ret := float64(win.Amount) / float64(wager.Amount)
+		sumSquares += ret * ret
+	}
+
+	n := float64(spins)
+	mean := float64(report.TotalWon) / float64(report.TotalWagered)
+	variance := sumSquares/n - mean*mean
+	if variance < 0 {
+		variance = 0
+	}
+
+	report.EmpiricalRTP = mean
+	report.StdDev = math.Sqrt(variance)
+	margin := rtpConfidenceZ * report.StdDev / math.Sqrt(n)
+	report.ConfidenceLow = mean - margin
+	report.ConfidenceHigh = mean + margin
+
+	return report, nil
+}
@@ -0,0 +1,382 @@
+// Package game - Settlement of single-round game cycles
+package game
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/alexbotov/rgs/internal/audit"
+	"github.com/alexbotov/rgs/internal/domain"
+	"github.com/alexbotov/rgs/internal/events"
+	"github.com/alexbotov/rgs/internal/wallet"
+	"github.com/alexbotov/rgs/pkg/pateplay"
+)
+
+var ErrNoPateplaySession = errors.New("cycle has no Pateplay session to settle through")
+
+// Settlement is the money a single-round game cycle, or a gamble on its win,
+// moves
+type Settlement struct {
+	CycleID  string
+	PlayerID string
+	GameID   string
+	Wager    domain.Money // Zero for a free spin
+	Win      domain.Money // Zero for a losing cycle
+
+	// JackpotContribution is the share of the wager added to the game's
+	// progressive pool, and JackpotWin the pool paid out on a jackpot win;
+	// both are zero for games without a jackpot
+	JackpotContribution domain.Money
+	JackpotWin          domain.Money
+
+	// GambleStep is the number of the gamble on the cycle's win this
+	// settlement is for, zero for the cycle itself. A won gamble is settled
+	// as a Win of the stake and a lost one as a Wager of it.
+	GambleStep int
+
+	// Pateplay is the cycle's Pateplay transaction; nil when the cycle is
+	// not played through Pateplay
+	Pateplay *PateplayRef
+
+	// Warnings are set by the backend to the codes of limit warnings it
+	// reported with the settlement, e.g. BET_LIMIT_90_PERCENT
+	Warnings []string
+}
+
+// SettlementBackend debits the wager and credits the win of a game cycle. It
+// runs inside the cycle's database transaction, so a failed settlement rolls
+// the cycle back (GLI-19 §4.16). Money the backend moves outside the
+// transaction is returned by Cancel when the transaction fails to commit
+// after a successful Settle.
+type SettlementBackend interface {
+	Settle(ctx context.Context, dbTx *sql.Tx, s *Settlement) error
+	Cancel(ctx context.Context, s *Settlement) error
+}
+
+// WalletSettlement settles cycles on the local wallet
+type WalletSettlement struct {
+	wallet wallet.Wallet
+}
+
+// NewWalletSettlement creates a settlement backend on the local wallet
+func NewWalletSettlement(walletSvc wallet.Wallet) *WalletSettlement {
+	return &WalletSettlement{wallet: walletSvc}
+}
+
+// Settle records the wager, the win and any jackpot contribution and payout
+// in the wallet ledger.
+// GLI-19 §4.3.3.b and §4.3.3.d
+func (w *WalletSettlement) Settle(ctx context.Context, dbTx *sql.Tx, s *Settlement) error {
+	if s.GambleStep > 0 {
+		won := s.Win.Amount > 0
+		stake := s.Wager
+		if won {
+			stake = s.Win
+		}
+		_, err := w.wallet.GambleTx(ctx, dbTx, s.PlayerID, stake, won, s.GameID, s.CycleID, s.GambleStep)
+		return err
+	}
+
+	if s.Wager.Amount > 0 {
+		if _, err := w.wallet.PlaceWagerTx(ctx, dbTx, s.PlayerID, s.Wager, s.GameID, s.CycleID); err != nil {
+			return err
+		}
+	}
+	if s.Win.Amount > 0 {
+		if _, err := w.wallet.CreditWinTx(ctx, dbTx, s.PlayerID, s.Win, s.GameID, s.CycleID); err != nil {
+			return err
+		}
+	}
+	if s.JackpotContribution.Amount > 0 {
+		if _, err := w.wallet.RecordJackpotContributionTx(ctx, dbTx, s.PlayerID, s.JackpotContribution, s.GameID, s.CycleID); err != nil {
+			return err
+		}
+	}
+	if s.JackpotWin.Amount > 0 {
+		if _, err := w.wallet.CreditJackpotTx(ctx, dbTx, s.PlayerID, s.JackpotWin, s.GameID, s.CycleID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Cancel has nothing to return: the local ledger rolls back with the cycle
+func (w *WalletSettlement) Cancel(ctx context.Context, s *Settlement) error {
+	return nil
+}
+
+// DefaultPateplaySettleTimeout bounds each Pateplay call of a settlement,
+// which is made while the player's balance row is locked
+const DefaultPateplaySettleTimeout = 5 * time.Second
+
+// PateplaySettlement settles cycles on Pateplay, the wallet of record for
+// operator play, with a single WithdrawAndDeposit call per cycle and a
+// Deposit for a jackpot payout. The wager and win are also recorded in the
+// local wallet, which mirrors the Pateplay balance, so the cycle stays in
+// the ledger.
+type PateplaySettlement struct {
+	client  *pateplay.Client
+	local   *WalletSettlement
+	audit   *audit.Service
+	timeout time.Duration
+}
+
+// NewPateplaySettlement creates a settlement backend on Pateplay
+func NewPateplaySettlement(client *pateplay.Client, walletSvc wallet.Wallet, auditSvc *audit.Service) *PateplaySettlement {
+	return &PateplaySettlement{
+		client:  client,
+		local:   NewWalletSettlement(walletSvc),
+		audit:   auditSvc,
+		timeout: DefaultPateplaySettleTimeout,
+	}
+}
+
+// SetTimeout sets how long each Pateplay call of a settlement may take
+func (p *PateplaySettlement) SetTimeout(timeout time.Duration) {
+	p.timeout = timeout
+}
+
+// Settle records the cycle locally, then withdraws the wager and deposits
+// the win on Pateplay, so a local failure stops the cycle before money moves
+// on the wallet of record. A Pateplay call that fails without a definite
+// rejection is cancelled here; the caller cancels the Pateplay transactions
+// if the cycle fails after this returns.
+func (p *PateplaySettlement) Settle(ctx context.Context, dbTx *sql.Tx, s *Settlement) error {
+	ref := s.Pateplay
+	if ref == nil || ref.SessionToken == "" {
+		return ErrNoPateplaySession
+	}
+
+	if err := p.local.Settle(ctx, dbTx, s); err != nil {
+		return err
+	}
+
+	withdrawReason, depositReason := pateplay.WithdrawReasonRoundStart, pateplay.DepositReasonRoundEnd
+	if s.GambleStep > 0 {
+		withdrawReason, depositReason = pateplay.WithdrawReasonRoundContinue, pateplay.DepositReasonRoundContinue
+	}
+
+	callCtx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+	result, err := p.client.WithdrawAndDeposit(callCtx, &pateplay.WithdrawAndDepositRequest{
+		SessionToken:             ref.SessionToken,
+		PlayerID:                 ref.PlayerID,
+		GameName:                 s.GameID,
+		Currency:                 s.Wager.Currency,
+		RGSRoundID:               ref.RoundID,
+		RGSWithdrawTransactionID: withdrawTransactionID(s),
+		RGSDepositTransactionID:  depositTransactionID(s),
+		WithdrawAmount:           s.Wager.ToAPIString(),
+		DepositAmount:            s.Win.ToAPIString(),
+		JackpotContribution:      s.JackpotContribution.ToAPIString(),
+		WithdrawReason:           withdrawReason,
+		DepositReason:            depositReason,
+	})
+	if err != nil {
+		// Only a rejection by Pateplay is known to have moved no money; after
+		// a timeout or transport failure the withdrawal may have been applied
+		var apiErr *pateplay.APIError
+		if !errors.As(err, &apiErr) {
+			p.Cancel(ctx, s)
+		}
+		return fmt.Errorf("failed to settle on Pateplay: %w", err)
+	}
+	s.Warnings = result.Warnings
+	balance := result.Balance
+
+	if s.JackpotWin.Amount > 0 {
+		deposit, err := p.client.Deposit(callCtx, &pateplay.DepositRequest{
+			SessionToken:     ref.SessionToken,
+			PlayerID:         ref.PlayerID,
+			GameName:         s.GameID,
+			Currency:         s.JackpotWin.Currency,
+			RGSRoundID:       ref.RoundID,
+			RGSTransactionID: jackpotTransactionID(s),
+			Amount:           s.JackpotWin.ToAPIString(),
+			IsJackpotWin:     true,
+			Reason:           pateplay.DepositReasonRoundEnd,
+		})
+		if err != nil {
+			p.Cancel(ctx, s)
+			return fmt.Errorf("failed to pay jackpot on Pateplay: %w", err)
+		}
+		balance = deposit.Balance
+	}
+
+	p.checkBalance(ctx, dbTx, s, balance)
+	return nil
+}
+
+// Cancel cancels the Pateplay transactions of a settlement whose cycle
+// failed after it was settled, the deposits before the withdrawal. A
+// transaction Pateplay does not know was never applied.
+func (p *PateplaySettlement) Cancel(ctx context.Context, s *Settlement) error {
+	ref := s.Pateplay
+	if ref == nil {
+		return nil
+	}
+
+	ids := []string{depositTransactionID(s), withdrawTransactionID(s)}
+	if s.JackpotWin.Amount > 0 {
+		ids = append([]string{jackpotTransactionID(s)}, ids...)
+	}
+
+	// The cycle's own context may be what failed
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), p.timeout)
+	defer cancel()
+
+	var errs []error
+	for _, id := range ids {
+		_, err := p.client.Cancel(ctx, ref.SessionToken, ref.PlayerID, ref.RoundID, id)
+		var apiErr *pateplay.APIError
+		if err != nil && (!errors.As(err, &apiErr) || apiErr.Code != pateplay.ErrTransactionNotFound) {
+			errs = append(errs, fmt.Errorf("failed to cancel Pateplay transaction %s: %w", id, err))
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		p.audit.Log(ctx, "pateplay_cancel_failed", domain.SeverityCritical,
+			fmt.Sprintf("Pateplay transactions of failed cycle %s could not be cancelled", s.CycleID),
+			map[string]interface{}{
+				"cycle_id":     s.CycleID,
+				"gamble_step":  s.GambleStep,
+				"rgs_round_id": ref.RoundID,
+				"error":        err.Error(),
+			},
+			audit.WithPlayer(s.PlayerID), audit.WithComponent("game"))
+		return err
+	}
+	return nil
+}
+
+// checkBalance flags a Pateplay balance that no longer matches the local
+// mirror once the cycle is settled (GLI-19 §2.5.7)
+func (p *PateplaySettlement) checkBalance(ctx context.Context, dbTx *sql.Tx, s *Settlement, reported string) {
+	balance, err := p.local.wallet.GetBalanceTx(ctx, dbTx, s.PlayerID)
+	if err != nil {
+		return
+	}
+	want := balance.RealMoney
+
+	got, err := domain.ParseMoney(reported, want.Currency)
+	if err != nil || got != want {
+		p.audit.Log(ctx, "balance_discrepancy", domain.SeverityWarning,
+			fmt.Sprintf("Pateplay balance %q differs from internal balance %s after cycle %s", reported, want, s.CycleID),
+			map[string]string{
+				"cycle_id": s.CycleID,
+				"internal": want.String(),
+				"pateplay": reported,
+			},
+			audit.WithPlayer(s.PlayerID), audit.WithComponent("game"))
+	}
+}
+
+// withdrawTransactionID is the rgsTransactionId of a settlement's wager: the
+// cycle's own, or one derived from it for a gamble step
+func withdrawTransactionID(s *Settlement) string {
+	if s.GambleStep > 0 {
+		return fmt.Sprintf("%s-gamble-%d", s.Pateplay.TransactionID, s.GambleStep)
+	}
+	return s.Pateplay.TransactionID
+}
+
+// depositTransactionID derives the rgsTransactionId of a settlement's win
+// from the wager's, so a retried settlement reuses both
+func depositTransactionID(s *Settlement) string {
+	return withdrawTransactionID(s) + "-deposit"
+}
+
+// jackpotTransactionID derives the rgsTransactionId of a jackpot payout from
+// the cycle's wager
+func jackpotTransactionID(s *Settlement) string {
+	return s.Pateplay.TransactionID + "-jackpot"
+}
+
+// pateplayLimits maps the responsible gaming limits Pateplay enforces on its
+// wallet to the RGS limit errors and the limit names used in limit events
+var pateplayLimits = map[string]struct {
+	err   error
+	limit string
+}{
+	pateplay.ErrBetLimitReached:  {ErrWagerLimitExceeded, "wager"},
+	pateplay.ErrLossLimitReached: {ErrLossLimitExceeded, "loss"},
+	pateplay.ErrTimeLimitReached: {ErrTimeLimitExceeded, "time"},
+}
+
+// TranslatePateplayError returns the RGS limit error for a Pateplay limit
+// error, so players see the same responsible gaming errors whichever wallet
+// enforced the limit. Other errors are returned unchanged.
+func TranslatePateplayError(err error) error {
+	var apiErr *pateplay.APIError
+	if !errors.As(err, &apiErr) {
+		return err
+	}
+	if l, ok := pateplayLimits[apiErr.Code]; ok {
+		return l.err
+	}
+	return err
+}
+
+// translatePateplayLimit translates a settlement error with
+// TranslatePateplayError. A limit Pateplay refused the wager under is audited
+// and pushed to the player like a limit the RGS enforced (GLI-19 §2.5.5).
+// Callers translate once the cycle's transaction has ended, so the audit
+// entry does not wait on a second connection while the first is held.
+func (e *Engine) translatePateplayLimit(ctx context.Context, playerID string, wager domain.Money, err error) error {
+	var apiErr *pateplay.APIError
+	if !errors.As(err, &apiErr) {
+		return err
+	}
+	l, ok := pateplayLimits[apiErr.Code]
+	if !ok {
+		return err
+	}
+
+	data := map[string]interface{}{
+		"code":  apiErr.Code,
+		"limit": l.limit,
+		"wager": wager.Amount,
+	}
+	if info, ok := apiErr.LimitInfo(); ok {
+		data["remaining"] = info.Remaining
+		data["percent_used"] = info.PercentUsed
+		if !info.ResetAt.IsZero() {
+			data["reset_at"] = info.ResetAt
+		}
+	}
+	e.audit.Log(ctx, "pateplay_limit_reached", domain.SeverityWarning,
+		fmt.Sprintf("Pateplay refused wager under its %s limit", l.limit),
+		data, audit.WithPlayer(playerID), audit.WithComponent("game"))
+	e.publishLimitReached(playerID, l.limit, wager)
+
+	return l.err
+}
+
+// pateplayWarnings maps Pateplay's 90% limit warnings to the limit names used
+// in limit events
+var pateplayWarnings = map[string]string{
+	pateplay.ErrBetLimit90Percent:  "wager",
+	pateplay.ErrLossLimit90Percent: "loss",
+	pateplay.ErrTimeLimit90Percent: "time",
+}
+
+// publishLimitWarning tells the player's open connections that a limit is
+// nearly reached, from a warning the settlement backend reported. The cycle
+// itself was settled.
+func (e *Engine) publishLimitWarning(playerID, code string) {
+	limit, ok := pateplayWarnings[code]
+	if !ok {
+		return
+	}
+	e.events.Publish(events.Event{
+		Type:     events.LimitWarning,
+		PlayerID: playerID,
+		Data: map[string]interface{}{
+			"limit":        limit,
+			"reached":      false,
+			"percent_used": 90,
+		},
+	})
+}
@@ -3,9 +3,16 @@
 package game
 
 import (
+	"context"
+	"encoding/hex"
+
 	"github.com/alexbotov/rgs/internal/domain"
+	"github.com/alexbotov/rgs/internal/rng"
 )
 
+// seedSize is the number of seed bytes recorded per cycle for replay
+const seedSize = 32
+
 // Symbol represents a slot reel symbol
 type Symbol string
 
@@ -19,15 +26,21 @@ const (
 	SymbolPlum    Symbol = "PLUM"
 	SymbolGrapes  Symbol = "GRAPES"
 	SymbolWild    Symbol = "WILD"
+	SymbolScatter Symbol = "SCATTER"
 )
 
 // SlotOutcome represents the outcome of a slot spin
 // GLI-19 §4.14: Game Recall
 type SlotOutcome struct {
-	Reels      []Symbol   `json:"reels"`       // Final reel positions
-	WinLines   []WinLine  `json:"win_lines"`   // Winning combinations
-	Multiplier int        `json:"multiplier"`  // Total multiplier
-	IsWin      bool       `json:"is_win"`      // Whether this is a winning spin
+	Reels      []Symbol     `json:"reels"`                // Final reel positions (center row)
+	Grid       [][]Symbol   `json:"grid,omitempty"`       // Visible symbols, rows x reels, for multi-row games
+	WinLines   []WinLine    `json:"win_lines"`            // Winning combinations
+	Multiplier int          `json:"multiplier"`           // Total multiplier
+	IsWin      bool         `json:"is_win"`               // Whether this is a winning spin
+	Seed       string       `json:"seed,omitempty"`       // Hex RNG seed, recorded for replay
+	FreeSpins  int          `json:"free_spins,omitempty"` // Free spins awarded by scatters
+	Gamble     []GambleStep `json:"gamble,omitempty"`     // Gambles taken on the win, in order
+	RTPVariant string       `json:"rtp_variant,omitempty"` // Certified variant the reels came from
 }
 
 // WinLine represents a winning payline
@@ -59,34 +72,83 @@ var fortuneSlotsReels = [][]Symbol{
 // Paytable for Fortune Slots (payout per unit bet in cents)
 // GLI-19 §4.4.1: Paytable information
 var fortuneSlotsPaytable = map[string]int64{
-	"7-7-7":           5000, // Jackpot: 50x bet
-	"WILD-WILD-WILD":  2500, // 25x bet
-	"BAR-BAR-BAR":     1000, // 10x bet
-	"BELL-BELL-BELL":  500,  // 5x bet
-	"GRAPES-GRAPES-GRAPES": 300, // 3x bet
-	"PLUM-PLUM-PLUM":  200,  // 2x bet
-	"ORANGE-ORANGE-ORANGE": 150, // 1.5x bet
-	"LEMON-LEMON-LEMON": 100, // 1x bet
-	"CHERRY-CHERRY-CHERRY": 80, // 0.8x bet
-	"CHERRY-CHERRY-*": 20,   // 0.2x bet (any third symbol)
-	"CHERRY-*-*":      10,   // 0.1x bet (any second and third symbol)
+	"7-7-7":                10000, // Jackpot: 100x bet
+	"WILD-WILD-WILD":       5000,  // 50x bet
+	"BAR-BAR-BAR":          4000,  // 40x bet
+	"BELL-BELL-BELL":       3000,  // 30x bet
+	"GRAPES-GRAPES-GRAPES": 2000,  // 20x bet
+	"PLUM-PLUM-PLUM":       1500,  // 15x bet
+	"ORANGE-ORANGE-ORANGE": 1200,  // 12x bet
+	"LEMON-LEMON-LEMON":    1000,  // 10x bet
+	"CHERRY-CHERRY-CHERRY": 800,   // 8x bet
+	"CHERRY-CHERRY-*":      400,   // 4x bet (any third symbol)
+	"CHERRY-*-*":           250,   // 2.5x bet (any second and third symbol)
+}
+
+// Reel configuration for Lucky Sevens
+// A classic fruit machine with heavier sevens and a smaller symbol set, ~94% RTP
+// GLI-19 §4.5.2, §4.6: Game Selection Process, Game Fairness
+var luckySevensReels = [][]Symbol{
+	// Reel 1
+	{SymbolSeven, SymbolLemon, SymbolBar, SymbolCherry, SymbolBell, SymbolLemon, SymbolSeven, SymbolBar,
+		SymbolLemon, SymbolCherry, SymbolBell, SymbolWild, SymbolLemon, SymbolBar, SymbolCherry, SymbolBell,
+		SymbolSeven, SymbolLemon, SymbolBar, SymbolCherry, SymbolBell, SymbolLemon},
+	// Reel 2
+	{SymbolSeven, SymbolLemon, SymbolBell, SymbolBar, SymbolCherry, SymbolLemon, SymbolBell, SymbolSeven,
+		SymbolLemon, SymbolBar, SymbolBell, SymbolWild, SymbolLemon, SymbolCherry, SymbolBar, SymbolBell,
+		SymbolSeven, SymbolLemon, SymbolBar, SymbolCherry, SymbolBell, SymbolLemon},
+	// Reel 3
+	{SymbolSeven, SymbolBar, SymbolBell, SymbolLemon, SymbolCherry, SymbolBar, SymbolBell, SymbolSeven,
+		SymbolLemon, SymbolBar, SymbolBell, SymbolWild, SymbolLemon, SymbolCherry, SymbolBar, SymbolBell,
+		SymbolSeven, SymbolLemon, SymbolBar, SymbolCherry, SymbolBell, SymbolLemon},
+}
+
+// Paytable for Lucky Sevens (payout per unit bet in cents)
+// GLI-19 §4.4.1: Paytable information
+var luckySevensPaytable = map[string]int64{
+	"7-7-7":                5000, // Jackpot: 50x bet
+	"WILD-WILD-WILD":       2500, // 25x bet
+	"BAR-BAR-BAR":          1500, // 15x bet
+	"BELL-BELL-BELL":       800,  // 8x bet
+	"LEMON-LEMON-LEMON":    500,  // 5x bet
+	"CHERRY-CHERRY-CHERRY": 500,  // 5x bet
+	"CHERRY-CHERRY-*":      250,  // 2.5x bet (any third symbol)
+	"CHERRY-*-*":           100,  // 1x bet (any second and third symbol)
 }
 
 // generateSlotOutcome generates a random slot outcome using the RNG
 // GLI-19 §4.5.2: Game Selection Process - outcomes determined by RNG
 // GLI-19 §4.6.1: Game Fairness - no adaptive behavior
-func (e *Engine) generateSlotOutcome(game *domain.Game) (*SlotOutcome, error) {
-	var reels [][]Symbol
-	
+func (e *Engine) generateSlotOutcome(ctx context.Context, game *domain.Game) (*SlotOutcome, error) {
+	if !e.recordSeeds {
+		return e.spinReels(ctx, game, e.rng)
+	}
+
+	// Draw a fresh seed from the CSPRNG and derive the spin from it so the
+	// cycle can be replayed exactly (GLI-19 §4.14)
+	seed, err := e.rng.GenerateBytes(seedSize)
+	if err != nil {
+		return nil, err
+	}
+	outcome, err := e.spinReels(ctx, game, rng.NewSeeded(seed))
+	if err != nil {
+		return nil, err
+	}
+	outcome.Seed = hex.EncodeToString(seed)
+	return outcome, nil
+}
+
+// spinReels draws reel positions from src and evaluates the result. Draws
+// are recorded to the recorder attached to ctx, if any.
+func (e *Engine) spinReels(ctx context.Context, game *domain.Game, src *rng.Service) (*SlotOutcome, error) {
 	// Select reel configuration based on game
-	switch game.ID {
-	case "fortune-slots":
-		reels = fortuneSlotsReels
-	case "lucky-sevens":
-		reels = fortuneSlotsReels // Using same reels for simplicity
-	default:
-		reels = fortuneSlotsReels
+	def, ok := e.definition(game.ID, game.RTPVariant)
+	if !ok {
+		return nil, ErrGameNotFound
 	}
+	reels := def.Reels
+	rows := def.rows()
+	center := rows / 2
 
 	// Generate random positions for each reel using CSPRNG
 	// GLI-19 §4.5.2.a: Making calls to RNG
@@ -96,56 +158,113 @@ func (e *Engine) generateSlotOutcome(game *domain.Game) (*SlotOutcome, error) {
 		Multiplier: 1,
 		IsWin:      false,
 	}
+	if game.RTPVariant != DefaultRTPVariant {
+		outcome.RTPVariant = game.RTPVariant
+	}
+
+	grid := make([][]Symbol, rows)
+	for r := range grid {
+		grid[r] = make([]Symbol, len(reels))
+	}
 
 	for i, reel := range reels {
 		// Generate random index within reel
-		idx, err := e.rng.GenerateInt(int64(len(reel)))
+		idx, err := src.GenerateIntContext(ctx, int64(len(reel)))
 		if err != nil {
 			return nil, err
 		}
+		// The selected stop lands on the center row, neighbours fill the rest
+		for r := 0; r < rows; r++ {
+			pos := (int(idx) + r - center + len(reel)) % len(reel)
+			grid[r][i] = reel[pos]
+		}
 		outcome.Reels[i] = reel[idx]
 	}
+	if rows > 1 {
+		outcome.Grid = grid
+	}
 
 	// Evaluate winning combinations
 	// GLI-19 §4.5.2.b: Outcomes used as directed by game rules
-	outcome.WinLines = e.evaluateWins(outcome.Reels)
+	outcome.WinLines = e.evaluateWins(def.Paytable, def.paylines(), grid)
 	outcome.IsWin = len(outcome.WinLines) > 0
+	outcome.FreeSpins = e.evaluateScatters(def.FreeSpins, grid)
 
 	return outcome, nil
 }
 
-// evaluateWins checks for winning combinations
+// evaluateScatters returns the free spins awarded by scatters anywhere on the grid
 // GLI-19 §4.4.1: Paytable information
-func (e *Engine) evaluateWins(reels []Symbol) []WinLine {
+func (e *Engine) evaluateScatters(cfg *FreeSpinsConfig, grid [][]Symbol) int {
+	if cfg == nil {
+		return 0
+	}
+
+	count := 0
+	for _, row := range grid {
+		for _, s := range row {
+			if s == cfg.Symbol {
+				count++
+			}
+		}
+	}
+
+	if count < cfg.Trigger {
+		return 0
+	}
+	return cfg.Spins
+}
+
+// evaluateWins scores every payline across the grid against the game's paytable
+// GLI-19 §4.4.1: Paytable information
+func (e *Engine) evaluateWins(paytable map[string]int64, paylines [][]int, grid [][]Symbol) []WinLine {
 	var winLines []WinLine
 
+	for n, payline := range paylines {
+		symbols := make([]Symbol, len(payline))
+		for reel, row := range payline {
+			if row < 0 || row >= len(grid) || reel >= len(grid[row]) {
+				symbols = nil
+				break
+			}
+			symbols[reel] = grid[row][reel]
+		}
+		if symbols == nil {
+			continue
+		}
+		if win, ok := e.evaluateLine(paytable, n+1, symbols); ok {
+			winLines = append(winLines, win)
+		}
+	}
+
+	return winLines
+}
+
+// evaluateLine checks a single payline for a winning combination
+// GLI-19 §4.4.1: Paytable information
+func (e *Engine) evaluateLine(paytable map[string]int64, line int, reels []Symbol) (WinLine, bool) {
 	if len(reels) < 3 {
-		return winLines
+		return WinLine{}, false
 	}
 
 	// Check for three of a kind (with wild substitution)
 	// GLI-19 §4.4.1.m: Wild/substitute symbols
 	s1, s2, s3 := reels[0], reels[1], reels[2]
+	symbols := []Symbol{s1, s2, s3}
 
 	// Check three matching symbols
 	key := string(s1) + "-" + string(s2) + "-" + string(s3)
-	if payout, ok := fortuneSlotsPaytable[key]; ok {
-		winLines = append(winLines, WinLine{
-			Line:    1,
-			Symbols: []Symbol{s1, s2, s3},
-			Count:   3,
-			Payout:  payout,
-		})
-		return winLines
+	if payout, ok := paytable[key]; ok {
+		return WinLine{Line: line, Symbols: symbols, Count: 3, Payout: payout}, true
 	}
 
 	// Check for wild substitution
 	if s1 == s2 && (s3 == SymbolWild || s3 == s1) ||
-	   s2 == s3 && (s1 == SymbolWild || s1 == s2) ||
-	   s1 == s3 && (s2 == SymbolWild || s2 == s1) {
+		s2 == s3 && (s1 == SymbolWild || s1 == s2) ||
+		s1 == s3 && (s2 == SymbolWild || s2 == s1) {
 		// Find the non-wild symbol
 		var baseSymbol Symbol
-		for _, s := range []Symbol{s1, s2, s3} {
+		for _, s := range symbols {
 			if s != SymbolWild {
 				baseSymbol = s
 				break
@@ -153,44 +272,26 @@ func (e *Engine) evaluateWins(reels []Symbol) []WinLine {
 		}
 		if baseSymbol != "" {
 			key = string(baseSymbol) + "-" + string(baseSymbol) + "-" + string(baseSymbol)
-			if payout, ok := fortuneSlotsPaytable[key]; ok {
-				winLines = append(winLines, WinLine{
-					Line:    1,
-					Symbols: []Symbol{s1, s2, s3},
-					Count:   3,
-					Payout:  payout,
-				})
-				return winLines
+			if payout, ok := paytable[key]; ok {
+				return WinLine{Line: line, Symbols: symbols, Count: 3, Payout: payout}, true
 			}
 		}
 	}
 
 	// Check for cherry combinations
 	if s1 == SymbolCherry && s2 == SymbolCherry {
-		if payout, ok := fortuneSlotsPaytable["CHERRY-CHERRY-*"]; ok {
-			winLines = append(winLines, WinLine{
-				Line:    1,
-				Symbols: []Symbol{s1, s2, s3},
-				Count:   2,
-				Payout:  payout,
-			})
-			return winLines
+		if payout, ok := paytable["CHERRY-CHERRY-*"]; ok {
+			return WinLine{Line: line, Symbols: symbols, Count: 2, Payout: payout}, true
 		}
 	}
 
 	if s1 == SymbolCherry {
-		if payout, ok := fortuneSlotsPaytable["CHERRY-*-*"]; ok {
-			winLines = append(winLines, WinLine{
-				Line:    1,
-				Symbols: []Symbol{s1, s2, s3},
-				Count:   1,
-				Payout:  payout,
-			})
-			return winLines
+		if payout, ok := paytable["CHERRY-*-*"]; ok {
+			return WinLine{Line: line, Symbols: symbols, Count: 1, Payout: payout}, true
 		}
 	}
 
-	return winLines
+	return WinLine{}, false
 }
 
 // calculateWin calculates the total win amount
@@ -207,6 +308,11 @@ func (e *Engine) calculateWin(outcome *SlotOutcome, wager domain.Money) domain.M
 		totalPayout += linePayout
 	}
 
+	// Feature multiplier, e.g. during free spins
+	if outcome.Multiplier > 1 {
+		totalPayout *= int64(outcome.Multiplier)
+	}
+
 	return domain.Money{Amount: totalPayout, Currency: wager.Currency}
 }
 
@@ -0,0 +1,98 @@
+// Package game - Detecting game cycles abandoned part way through
+package game
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/alexbotov/rgs/internal/audit"
+	"github.com/alexbotov/rgs/internal/database"
+	"github.com/alexbotov/rgs/internal/domain"
+)
+
+// InterruptReasonTimeout marks a cycle the sweeper found left in progress
+const InterruptReasonTimeout = "timeout"
+
+// SweepStaleCycles marks in-progress cycles that have seen no step for longer
+// than olderThan as interrupted, so a round whose server or client died after
+// the wager was taken can be resumed or voided. A multi-step round still being
+// played is left alone however long ago it started. It returns how many were
+// marked.
+// GLI-19 §4.16 - System must detect and handle interruptions
+func (e *Engine) SweepStaleCycles(ctx context.Context, olderThan time.Duration) (int, error) {
+	now := time.Now().UTC()
+
+	rows, err := e.db.QueryContext(ctx, `
+		UPDATE game_cycles SET status = $1, interrupted_at = $2, interrupt_reason = $3
+		WHERE status = $4 AND COALESCE(last_step_at, started_at) < $5
+		RETURNING id, session_id, player_id, game_id, wager_amount
+	`, domain.CycleStatusInterrupted, now, InterruptReasonTimeout,
+		domain.CycleStatusInProgress, now.Add(-olderThan))
+	if err != nil {
+		return 0, fmt.Errorf("failed to sweep stale cycles: %w", err)
+	}
+
+	type staleCycle struct {
+		id, sessionID, playerID, gameID string
+		wager                           int64
+	}
+	var stale []staleCycle
+	for rows.Next() {
+		var c staleCycle
+		if err := rows.Scan(&c.id, &c.sessionID, &c.playerID, &c.gameID, &c.wager); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		stale = append(stale, c)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, c := range stale {
+		_, err := e.db.ExecContext(ctx, `
+			UPDATE game_sessions SET status = $1 WHERE id = $2 AND status = $3
+		`, domain.GameSessionInterrupted, c.sessionID, domain.GameSessionActive)
+		if err != nil {
+			return len(stale), err
+		}
+
+		e.audit.Log(ctx, "game_interrupted", domain.SeverityWarning,
+			fmt.Sprintf("Game cycle left in progress marked interrupted: %s", c.id),
+			map[string]interface{}{
+				"cycle_id":     c.id,
+				"game_id":      c.gameID,
+				"wager_amount": c.wager,
+				"reason":       InterruptReasonTimeout,
+			},
+			audit.WithPlayer(c.playerID), audit.WithSession(c.sessionID), audit.WithComponent("game"))
+	}
+
+	return len(stale), nil
+}
+
+// RunStaleCycleSweeper calls SweepStaleCycles every interval until ctx is done
+func (e *Engine) RunStaleCycleSweeper(ctx context.Context, interval, olderThan time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// A sweep stalled on the database gives way to the next one
+			sweepCtx, cancel := database.BoundedContext(ctx, interval)
+			n, err := e.SweepStaleCycles(sweepCtx, olderThan)
+			cancel()
+			if err != nil {
+				log.Printf("Stale cycle sweep failed: %v", err)
+			} else if n > 0 {
+				log.Printf("Marked %d stale game cycles as interrupted", n)
+			}
+		}
+	}
+}
@@ -0,0 +1,150 @@
+// Package game - Selecting among certified RTP variants of a game
+package game
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/alexbotov/rgs/internal/audit"
+	"github.com/alexbotov/rgs/internal/domain"
+)
+
+var (
+	ErrUnknownRTPVariant     = errors.New("unknown RTP variant")
+	ErrAuthorizationRequired = errors.New("authorized by is required")
+)
+
+// DefaultRTPVariant names the reels and paytable a game definition declares
+// directly, as opposed to one of its alternative variants
+const DefaultRTPVariant = "default"
+
+// RTPVariant is an alternative certified reel and paytable set for a game,
+// for operators whose jurisdiction or contract calls for a different RTP
+// GLI-19 §4.7: Game Payout Percentages
+type RTPVariant struct {
+	TheoreticalRTP float64          `json:"theoretical_rtp"`
+	Reels          [][]Symbol       `json:"reels"`
+	Paytable       map[string]int64 `json:"paytable"`
+}
+
+// variant returns the definition with the named variant's reels and
+// paytable. The empty name and DefaultRTPVariant select the definition itself.
+func (d *GameDefinition) variant(name string) (*GameDefinition, bool) {
+	if name == "" || name == DefaultRTPVariant {
+		return d, true
+	}
+	v, ok := d.Variants[name]
+	if !ok {
+		return nil, false
+	}
+
+	def := *d
+	def.TheoreticalRTP = v.TheoreticalRTP
+	def.Reels = v.Reels
+	def.Paytable = v.Paytable
+	def.Variants = nil
+	return &def, true
+}
+
+// definition returns a game's definition under the given variant
+func (e *Engine) definition(gameID, variant string) (*GameDefinition, bool) {
+	def, ok := e.definitions[gameID]
+	if !ok {
+		return nil, false
+	}
+	return def.variant(variant)
+}
+
+// activeVariant returns the variant new sessions of a game are played on
+func (e *Engine) activeVariant(gameID string) string {
+	e.variantMu.RLock()
+	defer e.variantMu.RUnlock()
+	if v, ok := e.activeVariants[gameID]; ok {
+		return v
+	}
+	return DefaultRTPVariant
+}
+
+// withActiveVariant returns a copy of a registered game describing its active
+// variant
+func (e *Engine) withActiveVariant(g *domain.Game) *domain.Game {
+	game := *g
+	game.RTPVariant = e.activeVariant(g.ID)
+	if def, ok := e.definition(g.ID, game.RTPVariant); ok {
+		game.TheoreticalRTP = def.TheoreticalRTP
+	}
+	return &game
+}
+
+// SetGameRTPVariant switches a game to another of its certified RTP variants.
+// Sessions already open keep the variant they started on; only new sessions
+// play the new one. The change is recorded as a significant event.
+// GLI-19 §2.8.8 - Changes to game configuration must be logged
+func (e *Engine) SetGameRTPVariant(ctx context.Context, gameID, variant, authorizedBy string) error {
+	def, ok := e.definitions[gameID]
+	if !ok {
+		return ErrGameNotFound
+	}
+	selected, ok := def.variant(variant)
+	if !ok {
+		return fmt.Errorf("%w: %s has no variant %q", ErrUnknownRTPVariant, gameID, variant)
+	}
+	if authorizedBy == "" {
+		return ErrAuthorizationRequired
+	}
+	if variant == "" {
+		variant = DefaultRTPVariant
+	}
+
+	_, err := e.db.ExecContext(ctx, `
+		INSERT INTO game_rtp_variants (game_id, variant, authorized_by, updated_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (game_id) DO UPDATE SET variant = $2, authorized_by = $3, updated_at = $4
+	`, gameID, variant, authorizedBy, time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("failed to save RTP variant: %w", err)
+	}
+
+	previous := e.activeVariant(gameID)
+	e.variantMu.Lock()
+	e.activeVariants[gameID] = variant
+	e.variantMu.Unlock()
+
+	e.audit.Log(ctx, audit.EventConfigurationChange, domain.SeverityWarning,
+		fmt.Sprintf("RTP variant of %s changed from %s to %s", gameID, previous, variant),
+		map[string]interface{}{
+			"game_id":         gameID,
+			"previous":        previous,
+			"variant":         variant,
+			"theoretical_rtp": selected.TheoreticalRTP,
+			"authorized_by":   authorizedBy,
+		},
+		audit.WithComponent("game"))
+
+	return nil
+}
+
+// LoadRTPVariants restores the RTP variants selected before a restart.
+// Selections for games or variants no longer defined are ignored.
+func (e *Engine) LoadRTPVariants(ctx context.Context) error {
+	rows, err := e.db.QueryContext(ctx, `SELECT game_id, variant FROM game_rtp_variants`)
+	if err != nil {
+		return fmt.Errorf("failed to load RTP variants: %w", err)
+	}
+	defer rows.Close()
+
+	e.variantMu.Lock()
+	defer e.variantMu.Unlock()
+	for rows.Next() {
+		var gameID, variant string
+		if err := rows.Scan(&gameID, &variant); err != nil {
+			return err
+		}
+		if _, ok := e.definition(gameID, variant); ok {
+			e.activeVariants[gameID] = variant
+		}
+	}
+	return rows.Err()
+}
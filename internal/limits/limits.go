@@ -13,9 +13,11 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"log"
 	"time"
 
 	"github.com/alexbotov/rgs/internal/audit"
+	"github.com/alexbotov/rgs/internal/database"
 	"github.com/alexbotov/rgs/internal/domain"
 	"github.com/google/uuid"
 )
@@ -25,31 +27,132 @@ var (
 	ErrPlayerExcluded    = errors.New("player is self-excluded")
 	ErrCoolingOffPending = errors.New("limit increase pending cooling-off period")
 	ErrInvalidLimit      = errors.New("invalid limit value")
+	ErrInvalidPeriod     = errors.New("invalid limit period")
+
+	ErrDepositLimitExceeded = errors.New("deposit limit exceeded")
+	ErrWagerLimitExceeded   = errors.New("wager limit exceeded")
+	ErrLossLimitExceeded    = errors.New("loss limit exceeded")
+
+	ErrSessionDurationExceeded = errors.New("session duration limit exceeded")
+
+	ErrLimitAboveCap = errors.New("limit exceeds operator or regulator cap")
+
+	ErrExclusionNotFound   = errors.New("no active self-exclusion")
+	ErrExclusionNotExpired = errors.New("self-exclusion cooling-off period has not elapsed")
+	ErrExclusionPermanent  = errors.New("permanent self-exclusion can only be lifted by operator override")
+	ErrExclusionTooShort   = errors.New("self-exclusion is shorter than the minimum exclusion period")
 )
 
 // CoolingOffPeriod is the required waiting period for limit increases
 // GLI-19 §2.5.5.b - Limit increases require waiting period
 const CoolingOffPeriod = 24 * time.Hour
 
+// MinPermanentExclusionPeriod is how long a permanent self-exclusion must
+// run before an operator can override it
+// GLI-19 §2.5.5.c - Self-exclusion requires a minimum cooling-off before removal
+const MinPermanentExclusionPeriod = 180 * 24 * time.Hour
+
+// DefaultMinExclusionPeriod is how long any self-exclusion must run before it
+// can be removed, unless SetMinExclusionPeriod sets another
+const DefaultMinExclusionPeriod = 24 * time.Hour
+
 // Service provides player limit management
 type Service struct {
 	db       *sql.DB
 	audit    *audit.Service
 	currency string
+
+	minExclusionPeriod time.Duration
 }
 
 // New creates a new limits service
 func New(db *sql.DB, auditSvc *audit.Service, currency string) *Service {
 	return &Service{
-		db:       db,
-		audit:    auditSvc,
-		currency: currency,
+		db:                 db,
+		audit:              auditSvc,
+		currency:           currency,
+		minExclusionPeriod: DefaultMinExclusionPeriod,
 	}
 }
 
-// GetLimits retrieves a player's current limits
+// SetMinExclusionPeriod sets how long a self-exclusion must run, from when
+// it started, before it can be removed
+// GLI-19 §2.5.5.c - Self-exclusion requires a minimum cooling-off before removal
+func (s *Service) SetMinExclusionPeriod(d time.Duration) {
+	s.minExclusionPeriod = d
+}
+
+// Currency returns the currency limit amounts are held in
+func (s *Service) Currency() string {
+	return s.currency
+}
+
+// imposedSources are the limit sources that cap a player's own limits
+var imposedSources = []domain.LimitSource{domain.LimitSourceOperator, domain.LimitSourceRegulator}
+
+// GetLimits retrieves a player's effective limits. Operator and regulator
+// limits cap the player's own, so each limit is the most restrictive value
+// set by any source.
 // GLI-19 §2.5.5 - Player must be able to view their limits
 func (s *Service) GetLimits(ctx context.Context, playerID string) (*domain.PlayerLimits, error) {
+	var limits *domain.PlayerLimits
+	err := database.WithTx(ctx, s.db, func(dbTx *sql.Tx) error {
+		var err error
+		limits, err = s.GetLimitsTx(ctx, dbTx, playerID)
+		return err
+	})
+	return limits, err
+}
+
+// GetLimitsTx retrieves a player's effective limits like GetLimits inside
+// the caller's database transaction
+func (s *Service) GetLimitsTx(ctx context.Context, dbTx *sql.Tx, playerID string) (*domain.PlayerLimits, error) {
+	limits, err := s.getSourceLimitsTx(ctx, dbTx, playerID, domain.LimitSourcePlayer)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, source := range imposedSources {
+		imposed, err := s.getSourceLimitsTx(ctx, dbTx, playerID, source)
+		if err != nil {
+			return nil, err
+		}
+		if imposed.ID != "" {
+			applyCap(limits, imposed)
+		}
+	}
+
+	return limits, nil
+}
+
+// getSourceLimits retrieves the limits set by a single source in a
+// transaction of its own
+func (s *Service) getSourceLimits(ctx context.Context, playerID string, source domain.LimitSource) (*domain.PlayerLimits, error) {
+	var limits *domain.PlayerLimits
+	err := database.WithTx(ctx, s.db, func(dbTx *sql.Tx) error {
+		var err error
+		limits, err = s.getSourceLimitsTx(ctx, dbTx, playerID, source)
+		return err
+	})
+	return limits, err
+}
+
+// getSourceLimitsTx retrieves the limits set by a single source. An empty
+// record (no ID) is returned if the source has set none. For the player's own
+// limits, pending changes whose cooling-off has elapsed are applied first and
+// those still cooling off are returned in Pending.
+func (s *Service) getSourceLimitsTx(ctx context.Context, dbTx *sql.Tx, playerID string, source domain.LimitSource) (*domain.PlayerLimits, error) {
+	var pending []domain.PendingLimit
+	if source == domain.LimitSourcePlayer {
+		if err := applyDueLimits(ctx, dbTx, playerID); err != nil {
+			return nil, err
+		}
+		var err error
+		if pending, err = getPendingLimits(ctx, dbTx, playerID); err != nil {
+			return nil, err
+		}
+	}
+
 	var limits domain.PlayerLimits
 	var dailyDep, weeklyDep, monthlyDep sql.NullInt64
 	var dailyWager, weeklyWager sql.NullInt64
@@ -57,12 +160,12 @@ func (s *Service) GetLimits(ctx context.Context, playerID string) (*domain.Playe
 	var sessionDur sql.NullInt64
 	var coolingOff sql.NullTime
 
-	err := s.db.QueryRowContext(ctx, `
+	err := dbTx.QueryRowContext(ctx, `
 		SELECT id, player_id, daily_deposit, weekly_deposit, monthly_deposit,
 		       daily_wager, weekly_wager, daily_loss, weekly_loss,
 		       session_duration, cooling_off_until, source, effective_at, updated_at
-		FROM player_limits WHERE player_id = $1
-	`, playerID).Scan(
+		FROM player_limits WHERE player_id = $1 AND source = $2
+	`, playerID, source).Scan(
 		&limits.ID, &limits.PlayerID,
 		&dailyDep, &weeklyDep, &monthlyDep,
 		&dailyWager, &weeklyWager,
@@ -74,13 +177,15 @@ func (s *Service) GetLimits(ctx context.Context, playerID string) (*domain.Playe
 			// Return empty limits if none set
 			return &domain.PlayerLimits{
 				PlayerID:    playerID,
-				Source:      domain.LimitSourcePlayer,
+				Source:      source,
 				EffectiveAt: time.Now().UTC(),
 				UpdatedAt:   time.Now().UTC(),
+				Pending:     pending,
 			}, nil
 		}
 		return nil, fmt.Errorf("failed to get limits: %w", err)
 	}
+	limits.Pending = pending
 
 	// Convert nullable values to Money pointers
 	if dailyDep.Valid {
@@ -136,13 +241,14 @@ func (s *Service) SetDepositLimit(ctx context.Context, req *SetDepositLimitReque
 		return nil, ErrInvalidLimit
 	}
 
-	currentLimits, err := s.GetLimits(ctx, req.PlayerID)
+	currentLimits, err := s.getSourceLimits(ctx, req.PlayerID, domain.LimitSourcePlayer)
 	if err != nil {
 		return nil, err
 	}
 
 	now := time.Now().UTC()
 	effectiveAt := now
+	limitType := req.Period + "_deposit"
 
 	// Check if this is an increase (requires cooling-off period)
 	var currentAmount int64
@@ -160,16 +266,21 @@ func (s *Service) SetDepositLimit(ctx context.Context, req *SetDepositLimitReque
 			currentAmount = currentLimits.MonthlyDeposit.Amount
 		}
 	default:
-		return nil, fmt.Errorf("invalid period: %s", req.Period)
+		return nil, fmt.Errorf("%w: %s", ErrInvalidPeriod, req.Period)
+	}
+
+	// Players cannot set a limit looser than an operator or regulator cap
+	if err := s.checkCap(ctx, req.PlayerID, limitType, req.Amount); err != nil {
+		return nil, err
 	}
 
 	// If increasing or removing limit, apply cooling-off period
-	if req.Amount > currentAmount || (req.Amount == 0 && currentAmount > 0) {
+	if loosens(currentAmount, req.Amount) {
 		effectiveAt = now.Add(CoolingOffPeriod)
 	}
 
 	// Upsert limit
-	err = s.upsertLimit(ctx, req.PlayerID, req.Period+"_deposit", req.Amount, effectiveAt)
+	err = s.upsertLimit(ctx, req.PlayerID, domain.LimitSourcePlayer, limitType, currentAmount, req.Amount, effectiveAt, req.PlayerID)
 	if err != nil {
 		return nil, err
 	}
@@ -202,13 +313,14 @@ func (s *Service) SetWagerLimit(ctx context.Context, req *SetWagerLimitRequest)
 		return nil, ErrInvalidLimit
 	}
 
-	currentLimits, err := s.GetLimits(ctx, req.PlayerID)
+	currentLimits, err := s.getSourceLimits(ctx, req.PlayerID, domain.LimitSourcePlayer)
 	if err != nil {
 		return nil, err
 	}
 
 	now := time.Now().UTC()
 	effectiveAt := now
+	limitType := req.Period + "_wager"
 
 	var currentAmount int64
 	switch req.Period {
@@ -221,14 +333,19 @@ func (s *Service) SetWagerLimit(ctx context.Context, req *SetWagerLimitRequest)
 			currentAmount = currentLimits.WeeklyWager.Amount
 		}
 	default:
-		return nil, fmt.Errorf("invalid period: %s", req.Period)
+		return nil, fmt.Errorf("%w: %s", ErrInvalidPeriod, req.Period)
+	}
+
+	// Players cannot set a limit looser than an operator or regulator cap
+	if err := s.checkCap(ctx, req.PlayerID, limitType, req.Amount); err != nil {
+		return nil, err
 	}
 
-	if req.Amount > currentAmount || (req.Amount == 0 && currentAmount > 0) {
+	if loosens(currentAmount, req.Amount) {
 		effectiveAt = now.Add(CoolingOffPeriod)
 	}
 
-	err = s.upsertLimit(ctx, req.PlayerID, req.Period+"_wager", req.Amount, effectiveAt)
+	err = s.upsertLimit(ctx, req.PlayerID, domain.LimitSourcePlayer, limitType, currentAmount, req.Amount, effectiveAt, req.PlayerID)
 	if err != nil {
 		return nil, err
 	}
@@ -255,13 +372,14 @@ func (s *Service) SetLossLimit(ctx context.Context, req *SetLossLimitRequest) (*
 		return nil, ErrInvalidLimit
 	}
 
-	currentLimits, err := s.GetLimits(ctx, req.PlayerID)
+	currentLimits, err := s.getSourceLimits(ctx, req.PlayerID, domain.LimitSourcePlayer)
 	if err != nil {
 		return nil, err
 	}
 
 	now := time.Now().UTC()
 	effectiveAt := now
+	limitType := req.Period + "_loss"
 
 	var currentAmount int64
 	switch req.Period {
@@ -274,14 +392,19 @@ func (s *Service) SetLossLimit(ctx context.Context, req *SetLossLimitRequest) (*
 			currentAmount = currentLimits.WeeklyLoss.Amount
 		}
 	default:
-		return nil, fmt.Errorf("invalid period: %s", req.Period)
+		return nil, fmt.Errorf("%w: %s", ErrInvalidPeriod, req.Period)
 	}
 
-	if req.Amount > currentAmount || (req.Amount == 0 && currentAmount > 0) {
+	// Players cannot set a limit looser than an operator or regulator cap
+	if err := s.checkCap(ctx, req.PlayerID, limitType, req.Amount); err != nil {
+		return nil, err
+	}
+
+	if loosens(currentAmount, req.Amount) {
 		effectiveAt = now.Add(CoolingOffPeriod)
 	}
 
-	err = s.upsertLimit(ctx, req.PlayerID, req.Period+"_loss", req.Amount, effectiveAt)
+	err = s.upsertLimit(ctx, req.PlayerID, domain.LimitSourcePlayer, limitType, currentAmount, req.Amount, effectiveAt, req.PlayerID)
 	if err != nil {
 		return nil, err
 	}
@@ -294,9 +417,155 @@ func (s *Service) SetLossLimit(ctx context.Context, req *SetLossLimitRequest) (*
 	return s.GetLimits(ctx, req.PlayerID)
 }
 
-// SelfExclude excludes a player from gaming
+// SetSessionLimitRequest contains session duration limit update data
+type SetSessionLimitRequest struct {
+	PlayerID string `json:"player_id"`
+	Minutes  int64  `json:"minutes"` // 0 to remove limit
+}
+
+// SetSessionLimit updates a player's session duration limit
+// GLI-19 §2.5.5.a - Session time limits must be supported
+func (s *Service) SetSessionLimit(ctx context.Context, req *SetSessionLimitRequest) (*domain.PlayerLimits, error) {
+	if req.Minutes < 0 {
+		return nil, ErrInvalidLimit
+	}
+
+	currentLimits, err := s.getSourceLimits(ctx, req.PlayerID, domain.LimitSourcePlayer)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	effectiveAt := now
+
+	var currentMinutes int64
+	if currentLimits.SessionDuration != nil {
+		currentMinutes = *currentLimits.SessionDuration
+	}
+
+	if err := s.checkCap(ctx, req.PlayerID, "session_duration", req.Minutes); err != nil {
+		return nil, err
+	}
+
+	if loosens(currentMinutes, req.Minutes) {
+		effectiveAt = now.Add(CoolingOffPeriod)
+	}
+
+	err = s.upsertLimit(ctx, req.PlayerID, domain.LimitSourcePlayer, "session_duration", currentMinutes, req.Minutes, effectiveAt, req.PlayerID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.audit.Log(ctx, "limit_change", domain.SeverityInfo,
+		fmt.Sprintf("Session limit changed: %d minutes", req.Minutes),
+		map[string]interface{}{"minutes": req.Minutes},
+		audit.WithPlayer(req.PlayerID))
+
+	return s.GetLimits(ctx, req.PlayerID)
+}
+
+// SetImposedLimitRequest contains an operator or regulator limit update
+type SetImposedLimitRequest struct {
+	PlayerID  string `json:"player_id"`
+	LimitType string `json:"limit_type"` // e.g. daily_deposit, session_duration
+	Amount    int64  `json:"amount"`     // cents (minutes for session_duration), 0 to remove
+	SetBy     string `json:"set_by"`
+}
+
+// SetOperatorLimit sets a limit imposed by the operator. It caps the
+// player's own limit and takes effect immediately.
+// GLI-19 §2.5.5 - Operators may impose limits on players
+func (s *Service) SetOperatorLimit(ctx context.Context, req *SetImposedLimitRequest) (*domain.PlayerLimits, error) {
+	return s.setImposedLimit(ctx, domain.LimitSourceOperator, req)
+}
+
+// SetRegulatorLimit sets a limit required by the regulator. It caps the
+// player's own limit and takes effect immediately.
+// GLI-19 §2.5.5 - Regulatory limits must be enforced
+func (s *Service) SetRegulatorLimit(ctx context.Context, req *SetImposedLimitRequest) (*domain.PlayerLimits, error) {
+	return s.setImposedLimit(ctx, domain.LimitSourceRegulator, req)
+}
+
+// setImposedLimit stores a limit for a non-player source
+func (s *Service) setImposedLimit(ctx context.Context, source domain.LimitSource, req *SetImposedLimitRequest) (*domain.PlayerLimits, error) {
+	if req.Amount < 0 {
+		return nil, ErrInvalidLimit
+	}
+
+	current, err := s.getSourceLimits(ctx, req.PlayerID, source)
+	if err != nil {
+		return nil, err
+	}
+	currentAmount, ok := limitValue(current, req.LimitType)
+	if !ok {
+		return nil, fmt.Errorf("unknown limit type: %s", req.LimitType)
+	}
+
+	now := time.Now().UTC()
+	err = s.upsertLimit(ctx, req.PlayerID, source, req.LimitType, currentAmount, req.Amount, now, req.SetBy)
+	if err != nil {
+		return nil, err
+	}
+
+	s.audit.Log(ctx, "limit_change", domain.SeverityWarning,
+		fmt.Sprintf("%s limit changed: %s = %d", source, req.LimitType, req.Amount),
+		map[string]interface{}{
+			"source":     source,
+			"limit_type": req.LimitType,
+			"amount":     req.Amount,
+			"set_by":     req.SetBy,
+		},
+		audit.WithPlayer(req.PlayerID))
+
+	return s.GetLimits(ctx, req.PlayerID)
+}
+
+// GetLimitHistory returns a player's changes to limitType, or to every limit
+// when limitType is empty, in the order they were made
+// GLI-19 §2.5.5 - Regulators must be able to review historical limits
+func (s *Service) GetLimitHistory(ctx context.Context, playerID, limitType string) ([]*domain.LimitChange, error) {
+	where := "player_id = $1"
+	args := []interface{}{playerID}
+	if limitType != "" {
+		where += " AND limit_type = $2"
+		args = append(args, limitType)
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, player_id, limit_type, old_value, new_value, source, effective_at, changed_at, changed_by
+		FROM limit_change_history
+		WHERE `+where+`
+		ORDER BY changed_at ASC, seq ASC
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get limit history: %w", err)
+	}
+	defer rows.Close()
+
+	var history []*domain.LimitChange
+	for rows.Next() {
+		var c domain.LimitChange
+		var changedBy sql.NullString
+		if err := rows.Scan(&c.ID, &c.PlayerID, &c.LimitType, &c.OldValue, &c.NewValue,
+			&c.Source, &c.EffectiveAt, &c.ChangedAt, &changedBy); err != nil {
+			return nil, err
+		}
+		c.ChangedBy = changedBy.String
+		history = append(history, &c)
+	}
+
+	return history, rows.Err()
+}
+
+// SelfExclude excludes a player from gaming, permanently when duration is
+// nil. A time-limited exclusion must last at least the minimum exclusion
+// period.
 // GLI-19 §2.5.5.c - Self-exclusion must be supported
 func (s *Service) SelfExclude(ctx context.Context, playerID, reason string, duration *time.Duration) (*domain.SelfExclusion, error) {
+	if duration != nil && *duration < s.minExclusionPeriod {
+		return nil, ErrExclusionTooShort
+	}
+
 	now := time.Now().UTC()
 
 	exclusion := &domain.SelfExclusion{
@@ -344,25 +613,341 @@ func (s *Service) SelfExclude(ctx context.Context, playerID, reason string, dura
 	return exclusion, nil
 }
 
+// RemoveSelfExclusion lifts a player's active time-limited self-exclusion and
+// reactivates the account. It cannot be removed before it expires, nor before
+// the minimum exclusion period has passed. Permanent exclusions are rejected
+// with ErrExclusionPermanent; see OverrideSelfExclusion.
+// GLI-19 §2.5.5.c - Self-exclusion must be supported
+func (s *Service) RemoveSelfExclusion(ctx context.Context, playerID, removedBy string) (*domain.SelfExclusion, error) {
+	return s.removeSelfExclusion(ctx, playerID, removedBy, "")
+}
+
+// OverrideSelfExclusion lifts a player's active self-exclusion, permanent or
+// not, on the authority of an operator. The minimum exclusion period still
+// applies, and a permanent exclusion must have run MinPermanentExclusionPeriod.
+// The override and its reason are audited.
+// GLI-19 §2.5.5.c - Self-exclusion requires a minimum cooling-off before removal
+func (s *Service) OverrideSelfExclusion(ctx context.Context, playerID, authorizedBy, reason string) (*domain.SelfExclusion, error) {
+	if authorizedBy == "" || reason == "" {
+		return nil, errors.New("override requires who authorized it and a reason")
+	}
+	return s.removeSelfExclusion(ctx, playerID, authorizedBy, reason)
+}
+
+// removeSelfExclusion lifts the player's active self-exclusion. A non-empty
+// overrideReason allows a permanent exclusion to be lifted.
+func (s *Service) removeSelfExclusion(ctx context.Context, playerID, removedBy, overrideReason string) (*domain.SelfExclusion, error) {
+	var exclusion domain.SelfExclusion
+	var expiresAt sql.NullTime
+
+	err := s.db.QueryRowContext(ctx, `
+		SELECT id, player_id, reason, started_at, expires_at, is_active, created_at
+		FROM self_exclusions
+		WHERE player_id = $1 AND is_active = true
+		ORDER BY started_at DESC LIMIT 1
+	`, playerID).Scan(&exclusion.ID, &exclusion.PlayerID, &exclusion.Reason,
+		&exclusion.StartedAt, &expiresAt, &exclusion.IsActive, &exclusion.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrExclusionNotFound
+		}
+		return nil, fmt.Errorf("failed to get self-exclusion: %w", err)
+	}
+	if expiresAt.Valid {
+		exclusion.ExpiresAt = &expiresAt.Time
+	}
+
+	// Enforce the cooling-off before the exclusion can be lifted
+	now := time.Now().UTC()
+	override := overrideReason != ""
+	permanent := exclusion.ExpiresAt == nil
+	if now.Before(exclusion.StartedAt.Add(s.minExclusionPeriod)) {
+		return nil, ErrExclusionNotExpired
+	}
+	if !permanent && exclusion.ExpiresAt.After(now) {
+		return nil, ErrExclusionNotExpired
+	}
+	if permanent && !override {
+		return nil, ErrExclusionPermanent
+	}
+	if permanent && now.Before(exclusion.StartedAt.Add(MinPermanentExclusionPeriod)) {
+		return nil, ErrExclusionNotExpired
+	}
+
+	_, err = s.db.ExecContext(ctx, `
+		UPDATE self_exclusions SET is_active = false, removed_at = $1, removed_by = $2 WHERE id = $3
+	`, now, removedBy, exclusion.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to remove self-exclusion: %w", err)
+	}
+	exclusion.IsActive = false
+	exclusion.RemovedAt = &now
+	exclusion.RemovedBy = &removedBy
+
+	// Reactivate the player unless another exclusion is still in force
+	excluded, err := s.IsExcluded(ctx, playerID)
+	if err != nil {
+		return nil, err
+	}
+	if !excluded {
+		_, err = s.db.ExecContext(ctx, `
+			UPDATE players SET status = $1, updated_at = $2 WHERE id = $3 AND status = $4
+		`, domain.PlayerStatusActive, now, playerID, domain.PlayerStatusExcluded)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update player status: %w", err)
+		}
+	}
+
+	// Audit log - GLI-19 §2.8.8 significant event
+	data := map[string]interface{}{
+		"exclusion_id": exclusion.ID,
+		"removed_by":   removedBy,
+		"started_at":   exclusion.StartedAt,
+		"permanent":    permanent,
+	}
+	if override {
+		data["override_reason"] = overrideReason
+		s.audit.Log(ctx, "self_exclusion_override", domain.SeverityCritical,
+			fmt.Sprintf("Self-exclusion lifted by operator override from %s: %s", removedBy, overrideReason),
+			data, audit.WithPlayer(playerID))
+	} else {
+		s.audit.Log(ctx, "self_exclusion_removed", domain.SeverityCritical,
+			fmt.Sprintf("Self-exclusion removed by %s", removedBy),
+			data, audit.WithPlayer(playerID))
+	}
+
+	return &exclusion, nil
+}
+
+// ExclusionExpiredBy is recorded as removed_by on exclusions that ran out
+const ExclusionExpiredBy = "expired"
+
+// ExpireSelfExclusions deactivates time-limited self-exclusions that have run
+// out and reactivates their players, unless another exclusion is still in
+// force. An exclusion that has not yet run the minimum exclusion period is
+// kept, even if it was created with a shorter duration. It returns how many
+// exclusions expired.
+// GLI-19 §2.5.5.c - Self-exclusion must be supported
+func (s *Service) ExpireSelfExclusions(ctx context.Context) (int, error) {
+	now := time.Now().UTC()
+
+	rows, err := s.db.QueryContext(ctx, `
+		UPDATE self_exclusions SET is_active = false, removed_at = $1, removed_by = $2
+		WHERE is_active = true AND expires_at IS NOT NULL AND expires_at <= $1 AND started_at <= $3
+		RETURNING id, player_id, started_at, expires_at
+	`, now, ExclusionExpiredBy, now.Add(-s.minExclusionPeriod))
+	if err != nil {
+		return 0, fmt.Errorf("failed to expire self-exclusions: %w", err)
+	}
+
+	var expired []domain.SelfExclusion
+	for rows.Next() {
+		var e domain.SelfExclusion
+		var expiresAt time.Time
+		if err := rows.Scan(&e.ID, &e.PlayerID, &e.StartedAt, &expiresAt); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		e.ExpiresAt = &expiresAt
+		expired = append(expired, e)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, e := range expired {
+		excluded, err := s.IsExcluded(ctx, e.PlayerID)
+		if err != nil {
+			return len(expired), err
+		}
+		if !excluded {
+			_, err = s.db.ExecContext(ctx, `
+				UPDATE players SET status = $1, updated_at = $2 WHERE id = $3 AND status = $4
+			`, domain.PlayerStatusActive, now, e.PlayerID, domain.PlayerStatusExcluded)
+			if err != nil {
+				return len(expired), fmt.Errorf("failed to update player status: %w", err)
+			}
+		}
+
+		// Audit log - GLI-19 §2.8.8 significant event
+		s.audit.Log(ctx, "self_exclusion_expired", domain.SeverityCritical,
+			fmt.Sprintf("Self-exclusion expired: %s", e.ID),
+			map[string]interface{}{
+				"exclusion_id": e.ID,
+				"started_at":   e.StartedAt,
+				"expires_at":   e.ExpiresAt,
+				"reactivated":  !excluded,
+			},
+			audit.WithPlayer(e.PlayerID), audit.WithComponent("limits"))
+	}
+
+	return len(expired), nil
+}
+
+// RunExclusionExpirer calls ExpireSelfExclusions every interval until ctx is
+// done
+func (s *Service) RunExclusionExpirer(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			sweepCtx, cancel := database.BoundedContext(ctx, interval)
+			n, err := s.ExpireSelfExclusions(sweepCtx)
+			cancel()
+			if err != nil {
+				log.Printf("Self-exclusion expiry failed: %v", err)
+			} else if n > 0 {
+				log.Printf("Expired %d self-exclusions", n)
+			}
+		}
+	}
+}
+
 // IsExcluded checks if a player is currently self-excluded
 // GLI-19 §2.5.5.c - Excluded players cannot access gaming
 func (s *Service) IsExcluded(ctx context.Context, playerID string) (bool, error) {
+	return isExcluded(ctx, s.db, playerID, s.minExclusionPeriod)
+}
+
+// IsExcludedTx checks self-exclusion like IsExcluded inside the caller's
+// database transaction
+func (s *Service) IsExcludedTx(ctx context.Context, dbTx *sql.Tx, playerID string) (bool, error) {
+	return isExcluded(ctx, dbTx, playerID, s.minExclusionPeriod)
+}
+
+// isExcluded checks for an exclusion in force on q. An exclusion is in force
+// until it expires and has run minPeriod.
+func isExcluded(ctx context.Context, q rowQuerier, playerID string, minPeriod time.Duration) (bool, error) {
+	now := time.Now().UTC()
 	var count int
-	err := s.db.QueryRowContext(ctx, `
+	err := q.QueryRowContext(ctx, `
 		SELECT COUNT(*) FROM self_exclusions 
 		WHERE player_id = $1 AND is_active = true 
-		AND (expires_at IS NULL OR expires_at > $2)
-	`, playerID, time.Now().UTC()).Scan(&count)
+		AND (expires_at IS NULL OR expires_at > $2 OR started_at > $3)
+	`, playerID, now, now.Add(-minPeriod)).Scan(&count)
 	if err != nil {
 		return false, err
 	}
 	return count > 0, nil
 }
 
+// ResponsibleGamingStatus is a player's limits alongside their usage in the
+// current limit periods and their exclusion state
+type ResponsibleGamingStatus struct {
+	PlayerID string               `json:"player_id"`
+	Limits   *domain.PlayerLimits `json:"limits"`
+
+	DailyDeposits   domain.Money `json:"daily_deposits"`
+	WeeklyDeposits  domain.Money `json:"weekly_deposits"`
+	MonthlyDeposits domain.Money `json:"monthly_deposits"`
+	DailyWagers     domain.Money `json:"daily_wagers"`
+	WeeklyWagers    domain.Money `json:"weekly_wagers"`
+	DailyLoss       domain.Money `json:"daily_loss"` // Negative for a net win
+	WeeklyLoss      domain.Money `json:"weekly_loss"`
+
+	Excluded  bool                  `json:"excluded"`
+	Exclusion *domain.SelfExclusion `json:"exclusion,omitempty"` // The exclusion in force
+}
+
+// GetResponsibleGamingStatus returns a player's effective limits, their
+// deposits, wagers and net loss over the same rolling periods the limit
+// checks use, and any self-exclusion in force
+// GLI-19 §2.5.5 - Player must be able to view their limits
+func (s *Service) GetResponsibleGamingStatus(ctx context.Context, playerID string) (*ResponsibleGamingStatus, error) {
+	limits, err := s.GetLimits(ctx, playerID)
+	if err != nil {
+		return nil, err
+	}
+	status := &ResponsibleGamingStatus{PlayerID: playerID, Limits: limits}
+
+	now := time.Now().UTC()
+	day := now.Add(-24 * time.Hour)
+	week := now.Add(-7 * 24 * time.Hour)
+	month := now.Add(-30 * 24 * time.Hour)
+
+	deposits := []struct {
+		from time.Time
+		dst  *domain.Money
+	}{{day, &status.DailyDeposits}, {week, &status.WeeklyDeposits}, {month, &status.MonthlyDeposits}}
+	for _, d := range deposits {
+		total, err := getDepositTotal(ctx, s.db, playerID, d.from, now)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get deposit total: %w", err)
+		}
+		*d.dst = domain.Money{Amount: total, Currency: s.currency}
+	}
+
+	wagers := []struct {
+		from time.Time
+		dst  *domain.Money
+	}{{day, &status.DailyWagers}, {week, &status.WeeklyWagers}}
+	for _, w := range wagers {
+		total, err := getWagerTotal(ctx, s.db, playerID, w.from, now)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get wager total: %w", err)
+		}
+		*w.dst = domain.Money{Amount: total, Currency: s.currency}
+	}
+
+	if status.DailyLoss, err = s.GetNetLoss(ctx, playerID, day, now); err != nil {
+		return nil, err
+	}
+	if status.WeeklyLoss, err = s.GetNetLoss(ctx, playerID, week, now); err != nil {
+		return nil, err
+	}
+
+	status.Exclusion, err = s.activeExclusion(ctx, playerID, now)
+	if err != nil {
+		return nil, err
+	}
+	status.Excluded = status.Exclusion != nil
+
+	return status, nil
+}
+
+// activeExclusion returns the self-exclusion in force at now, or nil if the
+// player is not excluded. It matches IsExcluded.
+func (s *Service) activeExclusion(ctx context.Context, playerID string, now time.Time) (*domain.SelfExclusion, error) {
+	var exclusion domain.SelfExclusion
+	var expiresAt sql.NullTime
+
+	err := s.db.QueryRowContext(ctx, `
+		SELECT id, player_id, reason, started_at, expires_at, is_active, created_at
+		FROM self_exclusions
+		WHERE player_id = $1 AND is_active = true
+		AND (expires_at IS NULL OR expires_at > $2 OR started_at > $3)
+		ORDER BY expires_at DESC NULLS FIRST LIMIT 1
+	`, playerID, now, now.Add(-s.minExclusionPeriod)).Scan(&exclusion.ID, &exclusion.PlayerID, &exclusion.Reason,
+		&exclusion.StartedAt, &expiresAt, &exclusion.IsActive, &exclusion.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get self-exclusion: %w", err)
+	}
+	if expiresAt.Valid {
+		exclusion.ExpiresAt = &expiresAt.Time
+	}
+	return &exclusion, nil
+}
+
 // CheckDepositLimit checks if a deposit would exceed limits
 // GLI-19 §2.5.5 - Limits must be enforced
 func (s *Service) CheckDepositLimit(ctx context.Context, playerID string, amount domain.Money) error {
-	limits, err := s.GetLimits(ctx, playerID)
+	return database.WithTx(ctx, s.db, func(dbTx *sql.Tx) error {
+		return s.CheckDepositLimitTx(ctx, dbTx, playerID, amount)
+	})
+}
+
+// CheckDepositLimitTx checks a deposit like CheckDepositLimit inside the
+// caller's database transaction
+func (s *Service) CheckDepositLimitTx(ctx context.Context, dbTx *sql.Tx, playerID string, amount domain.Money) error {
+	limits, err := s.GetLimitsTx(ctx, dbTx, playerID)
 	if err != nil {
 		return err
 	}
@@ -370,33 +955,33 @@ func (s *Service) CheckDepositLimit(ctx context.Context, playerID string, amount
 	now := time.Now().UTC()
 
 	// Get deposits in current periods
-	dailyTotal, err := s.getDepositTotal(ctx, playerID, now.Add(-24*time.Hour), now)
+	dailyTotal, err := getDepositTotal(ctx, dbTx, playerID, now.Add(-24*time.Hour), now)
 	if err != nil {
 		return err
 	}
-	weeklyTotal, err := s.getDepositTotal(ctx, playerID, now.Add(-7*24*time.Hour), now)
+	weeklyTotal, err := getDepositTotal(ctx, dbTx, playerID, now.Add(-7*24*time.Hour), now)
 	if err != nil {
 		return err
 	}
-	monthlyTotal, err := s.getDepositTotal(ctx, playerID, now.Add(-30*24*time.Hour), now)
+	monthlyTotal, err := getDepositTotal(ctx, dbTx, playerID, now.Add(-30*24*time.Hour), now)
 	if err != nil {
 		return err
 	}
 
 	// Check against limits
-	if limits.DailyDeposit != nil && limits.EffectiveAt.Before(now) {
+	if limits.DailyDeposit != nil {
 		if dailyTotal+amount.Amount > limits.DailyDeposit.Amount {
-			return fmt.Errorf("daily deposit limit exceeded")
+			return fmt.Errorf("daily %w", ErrDepositLimitExceeded)
 		}
 	}
-	if limits.WeeklyDeposit != nil && limits.EffectiveAt.Before(now) {
+	if limits.WeeklyDeposit != nil {
 		if weeklyTotal+amount.Amount > limits.WeeklyDeposit.Amount {
-			return fmt.Errorf("weekly deposit limit exceeded")
+			return fmt.Errorf("weekly %w", ErrDepositLimitExceeded)
 		}
 	}
-	if limits.MonthlyDeposit != nil && limits.EffectiveAt.Before(now) {
+	if limits.MonthlyDeposit != nil {
 		if monthlyTotal+amount.Amount > limits.MonthlyDeposit.Amount {
-			return fmt.Errorf("monthly deposit limit exceeded")
+			return fmt.Errorf("monthly %w", ErrDepositLimitExceeded)
 		}
 	}
 
@@ -406,94 +991,398 @@ func (s *Service) CheckDepositLimit(ctx context.Context, playerID string, amount
 // CheckWagerLimit checks if a wager would exceed limits
 // GLI-19 §2.5.5 - Limits must be enforced
 func (s *Service) CheckWagerLimit(ctx context.Context, playerID string, amount domain.Money) error {
-	limits, err := s.GetLimits(ctx, playerID)
+	return database.WithTx(ctx, s.db, func(dbTx *sql.Tx) error {
+		return s.CheckWagerLimitTx(ctx, dbTx, playerID, amount)
+	})
+}
+
+// CheckWagerLimitTx checks a wager like CheckWagerLimit inside the caller's
+// database transaction, so a game cycle holding a connection needs no other
+func (s *Service) CheckWagerLimitTx(ctx context.Context, dbTx *sql.Tx, playerID string, amount domain.Money) error {
+	limits, err := s.GetLimitsTx(ctx, dbTx, playerID)
 	if err != nil {
 		return err
 	}
 
 	now := time.Now().UTC()
 
-	dailyTotal, err := s.getWagerTotal(ctx, playerID, now.Add(-24*time.Hour), now)
+	dailyTotal, err := getWagerTotal(ctx, dbTx, playerID, now.Add(-24*time.Hour), now)
 	if err != nil {
 		return err
 	}
-	weeklyTotal, err := s.getWagerTotal(ctx, playerID, now.Add(-7*24*time.Hour), now)
+	weeklyTotal, err := getWagerTotal(ctx, dbTx, playerID, now.Add(-7*24*time.Hour), now)
 	if err != nil {
 		return err
 	}
 
-	if limits.DailyWager != nil && limits.EffectiveAt.Before(now) {
+	if limits.DailyWager != nil {
 		if dailyTotal+amount.Amount > limits.DailyWager.Amount {
-			return fmt.Errorf("daily wager limit exceeded")
+			return fmt.Errorf("daily %w", ErrWagerLimitExceeded)
 		}
 	}
-	if limits.WeeklyWager != nil && limits.EffectiveAt.Before(now) {
+	if limits.WeeklyWager != nil {
 		if weeklyTotal+amount.Amount > limits.WeeklyWager.Amount {
-			return fmt.Errorf("weekly wager limit exceeded")
+			return fmt.Errorf("weekly %w", ErrWagerLimitExceeded)
 		}
 	}
 
 	return nil
 }
 
-// upsertLimit inserts or updates a specific limit value
-func (s *Service) upsertLimit(ctx context.Context, playerID, limitType string, amount int64, effectiveAt time.Time) error {
+// CheckLossLimit checks if a wager could take the player's net loss past
+// their loss limits. Play is blocked once the player is at or over a limit.
+// GLI-19 §2.5.5 - Limits must be enforced
+func (s *Service) CheckLossLimit(ctx context.Context, playerID string, prospectiveWager domain.Money) error {
+	return database.WithTx(ctx, s.db, func(dbTx *sql.Tx) error {
+		return s.CheckLossLimitTx(ctx, dbTx, playerID, prospectiveWager)
+	})
+}
+
+// CheckLossLimitTx checks a wager like CheckLossLimit inside the caller's
+// database transaction
+func (s *Service) CheckLossLimitTx(ctx context.Context, dbTx *sql.Tx, playerID string, prospectiveWager domain.Money) error {
+	limits, err := s.GetLimitsTx(ctx, dbTx, playerID)
+	if err != nil {
+		return err
+	}
+	if limits.DailyLoss == nil && limits.WeeklyLoss == nil {
+		return nil
+	}
+
 	now := time.Now().UTC()
 
-	// Check if limits record exists
-	var exists bool
-	err := s.db.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM player_limits WHERE player_id = $1)", playerID).Scan(&exists)
+	dailyLoss, err := s.netLoss(ctx, dbTx, playerID, now.Add(-24*time.Hour), now)
+	if err != nil {
+		return err
+	}
+	weeklyLoss, err := s.netLoss(ctx, dbTx, playerID, now.Add(-7*24*time.Hour), now)
 	if err != nil {
 		return err
 	}
 
-	if !exists {
-		// Create new record
-		_, err = s.db.ExecContext(ctx, `
-			INSERT INTO player_limits (id, player_id, source, effective_at, updated_at)
-			VALUES ($1, $2, $3, $4, $5)
-		`, uuid.New().String(), playerID, domain.LimitSourcePlayer, effectiveAt, now)
+	if limits.DailyLoss != nil {
+		if dailyLoss.Amount+prospectiveWager.Amount > limits.DailyLoss.Amount {
+			return fmt.Errorf("daily %w", ErrLossLimitExceeded)
+		}
+	}
+	if limits.WeeklyLoss != nil {
+		if weeklyLoss.Amount+prospectiveWager.Amount > limits.WeeklyLoss.Amount {
+			return fmt.Errorf("weekly %w", ErrLossLimitExceeded)
+		}
+	}
+
+	return nil
+}
+
+// CheckSessionDuration checks whether a login session that started at
+// sessionStart has run past the player's session duration limit
+// GLI-19 §2.5.5 - Session time limits must be enforced
+func (s *Service) CheckSessionDuration(ctx context.Context, playerID string, sessionStart time.Time) error {
+	limits, err := s.GetLimits(ctx, playerID)
+	if err != nil {
+		return err
+	}
+	if limits.SessionDuration == nil || *limits.SessionDuration <= 0 {
+		return nil
+	}
+
+	maxDuration := time.Duration(*limits.SessionDuration) * time.Minute
+	if time.Now().UTC().Sub(sessionStart) > maxDuration {
+		return ErrSessionDurationExceeded
+	}
+
+	return nil
+}
+
+// GetNetLoss returns the player's realized net loss in a time period: wagers
+// minus wins and refunds. A negative amount is a net win.
+// GLI-19 §2.5.5 - Loss limits are measured against net losses
+func (s *Service) GetNetLoss(ctx context.Context, playerID string, from, to time.Time) (domain.Money, error) {
+	return s.netLoss(ctx, s.db, playerID, from, to)
+}
+
+// netLoss computes GetNetLoss on q
+func (s *Service) netLoss(ctx context.Context, q rowQuerier, playerID string, from, to time.Time) (domain.Money, error) {
+	var total sql.NullInt64
+	err := q.QueryRowContext(ctx, `
+		SELECT COALESCE(SUM(CASE
+			WHEN type = 'wager' THEN amount
+			WHEN type IN ('win', 'jackpot', 'refund') THEN -amount
+			ELSE 0 END), 0)
+		FROM transactions
+		WHERE player_id = $1 AND status = 'completed'
+		AND created_at >= $2 AND created_at <= $3
+	`, playerID, from, to).Scan(&total)
+	if err != nil {
+		return domain.Money{}, fmt.Errorf("failed to get net loss: %w", err)
+	}
+	return domain.Money{Amount: total.Int64, Currency: s.currency}, nil
+}
+
+// loosens reports whether changing a limit from current to requested relaxes
+// it. Zero means no limit, so setting a first limit is never a relaxation.
+func loosens(current, requested int64) bool {
+	if current == 0 {
+		return false
+	}
+	return requested == 0 || requested > current
+}
+
+// checkCap rejects a player limit that is looser than a limit imposed by
+// the operator or regulator. Removing a player limit (0) is allowed since
+// the imposed limit still applies.
+func (s *Service) checkCap(ctx context.Context, playerID, limitType string, amount int64) error {
+	if amount == 0 {
+		return nil
+	}
+	for _, source := range imposedSources {
+		imposed, err := s.getSourceLimits(ctx, playerID, source)
 		if err != nil {
 			return err
 		}
+		if limit, _ := limitValue(imposed, limitType); limit > 0 && amount > limit {
+			return fmt.Errorf("%s %s limit is %d: %w", source, limitType, limit, ErrLimitAboveCap)
+		}
 	}
+	return nil
+}
 
-	// Update specific limit column
-	var query string
-	var nullableAmount interface{}
-	if amount == 0 {
-		nullableAmount = nil
-	} else {
-		nullableAmount = amount
+// applyCap lowers each of the limits to the imposed value where that is more
+// restrictive. A capped record reports the imposing source and the earlier
+// effective time, since imposed limits apply immediately.
+func applyCap(limits, imposed *domain.PlayerLimits) {
+	capped := false
+	capMoney := func(current **domain.Money, limit *domain.Money) {
+		if limit != nil && (*current == nil || limit.Amount < (*current).Amount) {
+			*current = limit
+			capped = true
+		}
+	}
+
+	capMoney(&limits.DailyDeposit, imposed.DailyDeposit)
+	capMoney(&limits.WeeklyDeposit, imposed.WeeklyDeposit)
+	capMoney(&limits.MonthlyDeposit, imposed.MonthlyDeposit)
+	capMoney(&limits.DailyWager, imposed.DailyWager)
+	capMoney(&limits.WeeklyWager, imposed.WeeklyWager)
+	capMoney(&limits.DailyLoss, imposed.DailyLoss)
+	capMoney(&limits.WeeklyLoss, imposed.WeeklyLoss)
+	if imposed.SessionDuration != nil && (limits.SessionDuration == nil || *imposed.SessionDuration < *limits.SessionDuration) {
+		limits.SessionDuration = imposed.SessionDuration
+		capped = true
+	}
+
+	if !capped {
+		return
+	}
+	limits.Source = imposed.Source
+	if imposed.EffectiveAt.Before(limits.EffectiveAt) {
+		limits.EffectiveAt = imposed.EffectiveAt
+	}
+}
+
+// limitValue returns the value of a limit type in a record, 0 if unset. The
+// second result is false for an unknown limit type.
+func limitValue(limits *domain.PlayerLimits, limitType string) (int64, bool) {
+	money := func(m *domain.Money) int64 {
+		if m == nil {
+			return 0
+		}
+		return m.Amount
 	}
 
 	switch limitType {
 	case "daily_deposit":
-		query = "UPDATE player_limits SET daily_deposit = $1, effective_at = $2, updated_at = $3 WHERE player_id = $4"
+		return money(limits.DailyDeposit), true
 	case "weekly_deposit":
-		query = "UPDATE player_limits SET weekly_deposit = $1, effective_at = $2, updated_at = $3 WHERE player_id = $4"
+		return money(limits.WeeklyDeposit), true
 	case "monthly_deposit":
-		query = "UPDATE player_limits SET monthly_deposit = $1, effective_at = $2, updated_at = $3 WHERE player_id = $4"
+		return money(limits.MonthlyDeposit), true
 	case "daily_wager":
-		query = "UPDATE player_limits SET daily_wager = $1, effective_at = $2, updated_at = $3 WHERE player_id = $4"
+		return money(limits.DailyWager), true
 	case "weekly_wager":
-		query = "UPDATE player_limits SET weekly_wager = $1, effective_at = $2, updated_at = $3 WHERE player_id = $4"
+		return money(limits.WeeklyWager), true
 	case "daily_loss":
-		query = "UPDATE player_limits SET daily_loss = $1, effective_at = $2, updated_at = $3 WHERE player_id = $4"
+		return money(limits.DailyLoss), true
 	case "weekly_loss":
-		query = "UPDATE player_limits SET weekly_loss = $1, effective_at = $2, updated_at = $3 WHERE player_id = $4"
+		return money(limits.WeeklyLoss), true
+	case "session_duration":
+		if limits.SessionDuration == nil {
+			return 0, true
+		}
+		return *limits.SessionDuration, true
 	default:
+		return 0, false
+	}
+}
+
+// limitColumns maps each limit type to its player_limits column
+var limitColumns = map[string]string{
+	"daily_deposit":    "daily_deposit",
+	"weekly_deposit":   "weekly_deposit",
+	"monthly_deposit":  "monthly_deposit",
+	"daily_wager":      "daily_wager",
+	"weekly_wager":     "weekly_wager",
+	"daily_loss":       "daily_loss",
+	"weekly_loss":      "weekly_loss",
+	"session_duration": "session_duration",
+}
+
+// execer is implemented by both *sql.DB and *sql.Tx
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// rowQuerier is implemented by both *sql.DB and *sql.Tx
+type rowQuerier interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// upsertLimit sets a specific limit value for a source and records the change,
+// made by actor, in the limit history. A change effective in the future is held in
+// pending_limits, leaving the current value in force until then; one that is
+// effective now replaces the value and any change pending for it.
+func (s *Service) upsertLimit(ctx context.Context, playerID string, source domain.LimitSource, limitType string, oldAmount, amount int64, effectiveAt time.Time, actor string) error {
+	if _, ok := limitColumns[limitType]; !ok {
 		return fmt.Errorf("unknown limit type: %s", limitType)
 	}
+	now := time.Now().UTC()
+
+	// Check if limits record exists
+	var exists bool
+	err := s.db.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM player_limits WHERE player_id = $1 AND source = $2)", playerID, source).Scan(&exists)
+	if err != nil {
+		return err
+	}
 
-	_, err = s.db.ExecContext(ctx, query, nullableAmount, effectiveAt, now, playerID)
+	if !exists {
+		// Create new record
+		_, err = s.db.ExecContext(ctx, `
+			INSERT INTO player_limits (id, player_id, source, effective_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5)
+		`, uuid.New().String(), playerID, source, now, now)
+		if err != nil {
+			return err
+		}
+	}
+
+	if effectiveAt.After(now) {
+		_, err = s.db.ExecContext(ctx, `
+			INSERT INTO pending_limits (player_id, limit_type, amount, effective_at, created_at)
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (player_id, limit_type) DO UPDATE
+			SET amount = EXCLUDED.amount, effective_at = EXCLUDED.effective_at, created_at = EXCLUDED.created_at
+		`, playerID, limitType, amount, effectiveAt, now)
+		if err != nil {
+			return fmt.Errorf("failed to record pending limit: %w", err)
+		}
+	} else {
+		err = database.WithTx(ctx, s.db, func(dbTx *sql.Tx) error {
+			if err := setLimitColumn(ctx, dbTx, playerID, source, limitType, amount, effectiveAt); err != nil {
+				return err
+			}
+			if source != domain.LimitSourcePlayer {
+				return nil
+			}
+			_, err := dbTx.ExecContext(ctx, "DELETE FROM pending_limits WHERE player_id = $1 AND limit_type = $2", playerID, limitType)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	// Record the change for regulator audit
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO limit_change_history (id, player_id, limit_type, old_value, new_value, source, effective_at, changed_at, changed_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`, uuid.New().String(), playerID, limitType, oldAmount, amount, source, effectiveAt, now,
+		sql.NullString{String: actor, Valid: actor != ""})
+	if err != nil {
+		return fmt.Errorf("failed to record limit history: %w", err)
+	}
+
+	return nil
+}
+
+// setLimitColumn writes one limit value of a source's record; 0 clears it
+func setLimitColumn(ctx context.Context, db execer, playerID string, source domain.LimitSource, limitType string, amount int64, effectiveAt time.Time) error {
+	column, ok := limitColumns[limitType]
+	if !ok {
+		return fmt.Errorf("unknown limit type: %s", limitType)
+	}
+
+	var nullableAmount interface{}
+	if amount != 0 {
+		nullableAmount = amount
+	}
+
+	_, err := db.ExecContext(ctx,
+		"UPDATE player_limits SET "+column+" = $1, effective_at = $2, updated_at = $3 WHERE player_id = $4 AND source = $5",
+		nullableAmount, effectiveAt, time.Now().UTC(), playerID, source)
 	return err
 }
 
+// applyDueLimits puts into force the player's pending limit changes whose
+// cooling-off has elapsed. Each change is claimed by deleting it, so
+// concurrent callers apply it once.
+// GLI-19 §2.5.5.b - Limit increases require waiting period
+func applyDueLimits(ctx context.Context, dbTx *sql.Tx, playerID string) error {
+	rows, err := dbTx.QueryContext(ctx, `
+		DELETE FROM pending_limits WHERE player_id = $1 AND effective_at <= $2
+		RETURNING limit_type, amount, effective_at
+	`, playerID, time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("failed to apply pending limits: %w", err)
+	}
+	due, err := scanPendingLimits(rows)
+	if err != nil {
+		return fmt.Errorf("failed to apply pending limits: %w", err)
+	}
+
+	for _, p := range due {
+		if err := setLimitColumn(ctx, dbTx, playerID, domain.LimitSourcePlayer, p.LimitType, p.Amount, p.EffectiveAt); err != nil {
+			return fmt.Errorf("failed to apply pending %s limit: %w", p.LimitType, err)
+		}
+	}
+	return nil
+}
+
+// getPendingLimits returns the player's limit changes still cooling off,
+// soonest first
+func getPendingLimits(ctx context.Context, dbTx *sql.Tx, playerID string) ([]domain.PendingLimit, error) {
+	rows, err := dbTx.QueryContext(ctx, `
+		SELECT limit_type, amount, effective_at FROM pending_limits
+		WHERE player_id = $1 ORDER BY effective_at, limit_type
+	`, playerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending limits: %w", err)
+	}
+	pending, err := scanPendingLimits(rows)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending limits: %w", err)
+	}
+	return pending, nil
+}
+
+// scanPendingLimits reads and closes rows of limit_type, amount, effective_at
+func scanPendingLimits(rows *sql.Rows) ([]domain.PendingLimit, error) {
+	defer rows.Close()
+
+	var pending []domain.PendingLimit
+	for rows.Next() {
+		var p domain.PendingLimit
+		if err := rows.Scan(&p.LimitType, &p.Amount, &p.EffectiveAt); err != nil {
+			return nil, err
+		}
+		pending = append(pending, p)
+	}
+	return pending, rows.Err()
+}
+
 // getDepositTotal calculates total deposits in a time period
-func (s *Service) getDepositTotal(ctx context.Context, playerID string, from, to time.Time) (int64, error) {
+func getDepositTotal(ctx context.Context, q rowQuerier, playerID string, from, to time.Time) (int64, error) {
 	var total sql.NullInt64
-	err := s.db.QueryRowContext(ctx, `
+	err := q.QueryRowContext(ctx, `
 		SELECT COALESCE(SUM(amount), 0) FROM transactions 
 		WHERE player_id = $1 AND type = 'deposit' AND status = 'completed'
 		AND created_at >= $2 AND created_at <= $3
@@ -505,9 +1394,9 @@ func (s *Service) getDepositTotal(ctx context.Context, playerID string, from, to
 }
 
 // getWagerTotal calculates total wagers in a time period
-func (s *Service) getWagerTotal(ctx context.Context, playerID string, from, to time.Time) (int64, error) {
+func getWagerTotal(ctx context.Context, q rowQuerier, playerID string, from, to time.Time) (int64, error) {
 	var total sql.NullInt64
-	err := s.db.QueryRowContext(ctx, `
+	err := q.QueryRowContext(ctx, `
 		SELECT COALESCE(SUM(amount), 0) FROM transactions 
 		WHERE player_id = $1 AND type = 'wager' AND status = 'completed'
 		AND created_at >= $2 AND created_at <= $3
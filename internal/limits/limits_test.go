@@ -2,40 +2,33 @@ package limits
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/alexbotov/rgs/internal/audit"
-	"github.com/alexbotov/rgs/internal/database"
+	"github.com/alexbotov/rgs/internal/database/dbtest"
 	"github.com/alexbotov/rgs/internal/domain"
 	"github.com/google/uuid"
 )
 
+func TestMain(m *testing.M) {
+	dbtest.Main(m)
+}
+
 func setupTestLimits(t *testing.T) (*Service, string, func()) {
 	t.Helper()
 
-	// Create PostgreSQL connection
-	db, err := database.New("postgres", "host=localhost dbname=rgs sslmode=disable")
-	if err != nil {
-		t.Fatalf("Failed to create database: %v", err)
-	}
-
-	// Ensure schema exists (idempotent)
-	if err := db.Migrate(); err != nil {
-		t.Logf("Migration note: %v", err)
-	}
-
-	// Clean data for fresh test state
-	if err := db.CleanData(); err != nil {
-		t.Fatalf("Failed to clean data: %v", err)
-	}
+	db := dbtest.Open(t)
 
 	auditSvc := audit.New(db.DB)
 	svc := New(db.DB, auditSvc, "USD")
 
 	// Create a test player
 	playerID := uuid.New().String()
-	_, err = db.DB.Exec(`
+	_, err := db.DB.Exec(`
 		INSERT INTO players (id, username, email, password_hash, status, registration_date, tc_accepted_at, created_at, updated_at)
 		VALUES ($1, 'limitsuser', 'limits@example.com', 'hash', 'active', NOW(), NOW(), NOW(), NOW())
 	`, playerID)
@@ -52,10 +45,7 @@ func setupTestLimits(t *testing.T) (*Service, string, func()) {
 		t.Fatalf("Failed to create balance: %v", err)
 	}
 
-	return svc, playerID, func() {
-		db.CleanData()
-		db.Close()
-	}
+	return svc, playerID, func() {}
 }
 
 func TestGetLimits(t *testing.T) {
@@ -362,6 +352,36 @@ func TestCheckDepositLimit(t *testing.T) {
 	// This is tested more thoroughly in integration tests
 }
 
+func TestDepositLimitsEnforcedIndependently(t *testing.T) {
+	svc, playerID, cleanup := setupTestLimits(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	set := func(period string, amount int64) {
+		t.Helper()
+		_, err := svc.SetDepositLimit(ctx, &SetDepositLimitRequest{PlayerID: playerID, Period: period, Amount: amount})
+		if err != nil {
+			t.Fatalf("Failed to set %s deposit limit: %v", period, err)
+		}
+	}
+
+	// Raising the weekly limit starts a cooling-off period for it alone
+	set("daily", 5000)
+	set("weekly", 20000)
+	set("weekly", 30000)
+
+	err := svc.CheckDepositLimit(ctx, playerID, domain.Money{Amount: 6000, Currency: "USD"})
+	if !errors.Is(err, ErrDepositLimitExceeded) || !strings.HasPrefix(err.Error(), "daily") {
+		t.Errorf("Expected the daily limit to be enforced while the weekly increase cools off, got %v", err)
+	}
+
+	err = svc.CheckDepositLimit(ctx, playerID, domain.Money{Amount: 4000, Currency: "USD"})
+	if err != nil {
+		t.Errorf("Expected deposit within both limits to be allowed, got %v", err)
+	}
+}
+
 func TestCheckWagerLimit(t *testing.T) {
 	svc, playerID, cleanup := setupTestLimits(t)
 	defer cleanup()
@@ -387,6 +407,44 @@ func TestCheckWagerLimit(t *testing.T) {
 	})
 }
 
+// TestCheckLimitsTxSingleConnection checks the Tx variants read on the
+// caller's transaction, so a pool of one connection cannot deadlock
+func TestCheckLimitsTxSingleConnection(t *testing.T) {
+	svc, playerID, cleanup := setupTestLimits(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	if _, err := svc.SetWagerLimit(ctx, &SetWagerLimitRequest{PlayerID: playerID, Period: "daily", Amount: 5000}); err != nil {
+		t.Fatalf("Failed to set wager limit: %v", err)
+	}
+	if _, err := svc.SetLossLimit(ctx, &SetLossLimitRequest{PlayerID: playerID, Period: "daily", Amount: 5000}); err != nil {
+		t.Fatalf("Failed to set loss limit: %v", err)
+	}
+
+	svc.db.SetMaxOpenConns(1)
+	defer svc.db.SetMaxOpenConns(0)
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	dbTx, err := svc.db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatalf("Failed to begin transaction: %v", err)
+	}
+	defer dbTx.Rollback()
+
+	wager := domain.Money{Amount: 1000, Currency: "USD"}
+	if err := svc.CheckWagerLimitTx(ctx, dbTx, playerID, wager); err != nil {
+		t.Errorf("CheckWagerLimitTx: %v", err)
+	}
+	if err := svc.CheckLossLimitTx(ctx, dbTx, playerID, wager); err != nil {
+		t.Errorf("CheckLossLimitTx: %v", err)
+	}
+	if err := svc.CheckWagerLimitTx(ctx, dbTx, playerID, domain.Money{Amount: 6000, Currency: "USD"}); !errors.Is(err, ErrWagerLimitExceeded) {
+		t.Errorf("Expected ErrWagerLimitExceeded, got %v", err)
+	}
+}
+
 func TestLimitDecreaseTakesEffectImmediately(t *testing.T) {
 	svc, playerID, cleanup := setupTestLimits(t)
 	defer cleanup()
@@ -445,11 +503,18 @@ func TestLimitIncreaseRequiresCoolingOff(t *testing.T) {
 		t.Fatalf("Failed to increase limit: %v", err)
 	}
 
-	// Effective at should be in the future (after cooling off period)
+	// The old limit stays in force and the increase waits out the cooling off period
+	if limits.DailyDeposit == nil || limits.DailyDeposit.Amount != 5000 {
+		t.Errorf("Expected active daily deposit limit 5000 during cooling off, got %v", limits.DailyDeposit)
+	}
+	pending := limits.PendingFor("daily_deposit")
+	if pending == nil || pending.Amount != 10000 {
+		t.Fatalf("Expected pending daily deposit limit 10000, got %+v", limits.Pending)
+	}
 	expectedEarliest := time.Now().Add(CoolingOffPeriod - time.Minute)
-	if limits.EffectiveAt.Before(expectedEarliest) {
+	if pending.EffectiveAt.Before(expectedEarliest) {
 		t.Errorf("Limit increase should have cooling off period. Effective at: %v, Expected after: %v",
-			limits.EffectiveAt, expectedEarliest)
+			pending.EffectiveAt, expectedEarliest)
 	}
 }
 
@@ -479,10 +544,757 @@ func TestRemoveLimitRequiresCoolingOff(t *testing.T) {
 		t.Fatalf("Failed to remove limit: %v", err)
 	}
 
-	// Effective at should be in the future
+	// The limit stays in force until the removal takes effect
+	if limits.DailyDeposit == nil || limits.DailyDeposit.Amount != 5000 {
+		t.Errorf("Expected active daily deposit limit 5000 during cooling off, got %v", limits.DailyDeposit)
+	}
+	pending := limits.PendingFor("daily_deposit")
+	if pending == nil || pending.Amount != 0 {
+		t.Fatalf("Expected pending removal of daily deposit limit, got %+v", limits.Pending)
+	}
 	expectedEarliest := time.Now().Add(CoolingOffPeriod - time.Minute)
-	if limits.EffectiveAt.Before(expectedEarliest) {
+	if pending.EffectiveAt.Before(expectedEarliest) {
 		t.Error("Limit removal should have cooling off period")
 	}
 }
 
+func TestLimitEnforcedDuringCoolingOff(t *testing.T) {
+	svc, playerID, cleanup := setupTestLimits(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	setLimit := func(amount int64) *domain.PlayerLimits {
+		t.Helper()
+		limits, err := svc.SetDepositLimit(ctx, &SetDepositLimitRequest{PlayerID: playerID, Period: "daily", Amount: amount})
+		if err != nil {
+			t.Fatalf("Failed to set limit: %v", err)
+		}
+		return limits
+	}
+	// elapseCoolingOff moves pending changes to the past, as if the
+	// cooling-off period had run
+	elapseCoolingOff := func() {
+		t.Helper()
+		_, err := svc.db.ExecContext(ctx, "UPDATE pending_limits SET effective_at = $1 WHERE player_id = $2",
+			time.Now().UTC().Add(-time.Minute), playerID)
+		if err != nil {
+			t.Fatalf("Failed to backdate pending limits: %v", err)
+		}
+	}
+	deposit := domain.Money{Amount: 7000, Currency: "USD"}
+
+	setLimit(5000)
+	setLimit(10000)
+
+	t.Run("OldLimitEnforced", func(t *testing.T) {
+		err := svc.CheckDepositLimit(ctx, playerID, deposit)
+		if !errors.Is(err, ErrDepositLimitExceeded) {
+			t.Errorf("Expected the old limit to be enforced during cooling off, got %v", err)
+		}
+	})
+
+	t.Run("DecreaseReplacesPending", func(t *testing.T) {
+		limits := setLimit(4000)
+		if limits.DailyDeposit == nil || limits.DailyDeposit.Amount != 4000 {
+			t.Errorf("Expected decrease to apply immediately, got %v", limits.DailyDeposit)
+		}
+		if len(limits.Pending) != 0 {
+			t.Errorf("Expected decrease to cancel the pending increase, got %+v", limits.Pending)
+		}
+	})
+
+	t.Run("NewLimitAfterCoolingOff", func(t *testing.T) {
+		setLimit(10000)
+		elapseCoolingOff()
+
+		limits, err := svc.GetLimits(ctx, playerID)
+		if err != nil {
+			t.Fatalf("Failed to get limits: %v", err)
+		}
+		if limits.DailyDeposit == nil || limits.DailyDeposit.Amount != 10000 {
+			t.Errorf("Expected increase in force after cooling off, got %v", limits.DailyDeposit)
+		}
+		if len(limits.Pending) != 0 {
+			t.Errorf("Expected no pending limits, got %+v", limits.Pending)
+		}
+		if err := svc.CheckDepositLimit(ctx, playerID, deposit); err != nil {
+			t.Errorf("Expected deposit within the new limit to be allowed, got %v", err)
+		}
+	})
+
+	t.Run("RemovalAfterCoolingOff", func(t *testing.T) {
+		setLimit(0)
+		if err := svc.CheckDepositLimit(ctx, playerID, domain.Money{Amount: 20000, Currency: "USD"}); !errors.Is(err, ErrDepositLimitExceeded) {
+			t.Errorf("Expected the limit to be enforced until removal takes effect, got %v", err)
+		}
+
+		elapseCoolingOff()
+		limits, err := svc.GetLimits(ctx, playerID)
+		if err != nil {
+			t.Fatalf("Failed to get limits: %v", err)
+		}
+		if limits.DailyDeposit != nil {
+			t.Errorf("Expected no daily deposit limit after removal, got %v", limits.DailyDeposit)
+		}
+	})
+}
+
+
+func TestGetLimitHistory(t *testing.T) {
+	svc, playerID, cleanup := setupTestLimits(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	t.Run("NoHistory", func(t *testing.T) {
+		history, err := svc.GetLimitHistory(ctx, playerID, "")
+		if err != nil {
+			t.Fatalf("Failed to get limit history: %v", err)
+		}
+		if len(history) != 0 {
+			t.Errorf("Expected no history, got %d entries", len(history))
+		}
+	})
+
+	t.Run("SetThenChangeDailyDeposit", func(t *testing.T) {
+		_, err := svc.SetDepositLimit(ctx, &SetDepositLimitRequest{
+			PlayerID: playerID,
+			Period:   "daily",
+			Amount:   5000, // $50
+		})
+		if err != nil {
+			t.Fatalf("Failed to set limit: %v", err)
+		}
+
+		_, err = svc.SetDepositLimit(ctx, &SetDepositLimitRequest{
+			PlayerID: playerID,
+			Period:   "daily",
+			Amount:   10000, // $100 - increase
+		})
+		if err != nil {
+			t.Fatalf("Failed to change limit: %v", err)
+		}
+
+		history, err := svc.GetLimitHistory(ctx, playerID, "")
+		if err != nil {
+			t.Fatalf("Failed to get limit history: %v", err)
+		}
+		if len(history) != 2 {
+			t.Fatalf("Expected 2 history entries, got %d", len(history))
+		}
+
+		first, second := history[0], history[1]
+		if first.LimitType != "daily_deposit" || second.LimitType != "daily_deposit" {
+			t.Errorf("Expected daily_deposit entries, got %s and %s", first.LimitType, second.LimitType)
+		}
+		if first.OldValue != 0 || first.NewValue != 5000 {
+			t.Errorf("Expected first change 0 -> 5000, got %d -> %d", first.OldValue, first.NewValue)
+		}
+		if second.OldValue != 5000 || second.NewValue != 10000 {
+			t.Errorf("Expected second change 5000 -> 10000, got %d -> %d", second.OldValue, second.NewValue)
+		}
+		if second.ChangedAt.Before(first.ChangedAt) {
+			t.Error("Expected history ordered by change time")
+		}
+		if first.Source != domain.LimitSourcePlayer {
+			t.Errorf("Expected source player, got %s", first.Source)
+		}
+
+		// The increase is subject to cooling-off
+		if !second.EffectiveAt.After(second.ChangedAt.Add(CoolingOffPeriod - time.Minute)) {
+			t.Errorf("Expected increase effective after cooling-off, got %v", second.EffectiveAt)
+		}
+	})
+
+	t.Run("SessionLimitRecorded", func(t *testing.T) {
+		_, err := svc.SetSessionLimit(ctx, &SetSessionLimitRequest{
+			PlayerID: playerID,
+			Minutes:  60,
+		})
+		if err != nil {
+			t.Fatalf("Failed to set session limit: %v", err)
+		}
+
+		history, err := svc.GetLimitHistory(ctx, playerID, "")
+		if err != nil {
+			t.Fatalf("Failed to get limit history: %v", err)
+		}
+		last := history[len(history)-1]
+		if last.LimitType != "session_duration" || last.NewValue != 60 {
+			t.Errorf("Expected session_duration -> 60, got %s -> %d", last.LimitType, last.NewValue)
+		}
+	})
+
+	t.Run("FilteredByLimitType", func(t *testing.T) {
+		// A decrease, then an operator cap below it
+		_, err := svc.SetDepositLimit(ctx, &SetDepositLimitRequest{PlayerID: playerID, Period: "daily", Amount: 4000})
+		if err != nil {
+			t.Fatalf("Failed to set limit: %v", err)
+		}
+		_, err = svc.SetOperatorLimit(ctx, &SetImposedLimitRequest{
+			PlayerID:  playerID,
+			LimitType: "daily_deposit",
+			Amount:    3000,
+			SetBy:     "compliance@operator",
+		})
+		if err != nil {
+			t.Fatalf("Failed to set operator limit: %v", err)
+		}
+
+		history, err := svc.GetLimitHistory(ctx, playerID, "daily_deposit")
+		if err != nil {
+			t.Fatalf("Failed to get limit history: %v", err)
+		}
+		want := []struct {
+			old, new  int64
+			source    domain.LimitSource
+			changedBy string
+		}{
+			{0, 5000, domain.LimitSourcePlayer, playerID},
+			{5000, 10000, domain.LimitSourcePlayer, playerID},
+			{5000, 4000, domain.LimitSourcePlayer, playerID},
+			{0, 3000, domain.LimitSourceOperator, "compliance@operator"},
+		}
+		if len(history) != len(want) {
+			t.Fatalf("Expected %d daily_deposit entries, got %d", len(want), len(history))
+		}
+		for i, w := range want {
+			c := history[i]
+			if c.LimitType != "daily_deposit" || c.OldValue != w.old || c.NewValue != w.new ||
+				c.Source != w.source || c.ChangedBy != w.changedBy {
+				t.Errorf("Entry %d: expected %s %d -> %d by %s, got %s %s %d -> %d by %s", i,
+					w.source, w.old, w.new, w.changedBy, c.Source, c.LimitType, c.OldValue, c.NewValue, c.ChangedBy)
+			}
+		}
+	})
+}
+
+func TestFirstLimitTakesEffectImmediately(t *testing.T) {
+	svc, playerID, cleanup := setupTestLimits(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	// Setting a limit where none existed restricts play, so no cooling-off
+	limits, err := svc.SetWagerLimit(ctx, &SetWagerLimitRequest{
+		PlayerID: playerID,
+		Period:   "daily",
+		Amount:   5000,
+	})
+	if err != nil {
+		t.Fatalf("Failed to set wager limit: %v", err)
+	}
+	if limits.EffectiveAt.After(time.Now().Add(time.Second)) {
+		t.Error("A first limit should be effective immediately")
+	}
+
+	err = svc.CheckWagerLimit(ctx, playerID, domain.Money{Amount: 6000, Currency: "USD"})
+	if !errors.Is(err, ErrWagerLimitExceeded) {
+		t.Errorf("Expected ErrWagerLimitExceeded, got %v", err)
+	}
+}
+
+func TestGetNetLoss(t *testing.T) {
+	svc, playerID, cleanup := setupTestLimits(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	addTx := func(txType string, amount int64) {
+		t.Helper()
+		_, err := svc.db.ExecContext(ctx, `
+			INSERT INTO transactions (id, player_id, type, amount, currency, balance_before, balance_after, status, reference, description, created_at, completed_at)
+			VALUES ($1, $2, $3, $4, 'USD', 0, 0, 'completed', $5, 'test', NOW(), NOW())
+		`, uuid.New().String(), playerID, txType, amount, uuid.New().String())
+		if err != nil {
+			t.Fatalf("Failed to insert %s transaction: %v", txType, err)
+		}
+	}
+
+	// Deposits do not count towards losses
+	addTx("deposit", 10000)
+	addTx("wager", 2000)
+	addTx("win", 500)
+	addTx("wager", 1000)
+
+	now := time.Now().UTC()
+	loss, err := svc.GetNetLoss(ctx, playerID, now.Add(-time.Hour), now.Add(time.Minute))
+	if err != nil {
+		t.Fatalf("Failed to get net loss: %v", err)
+	}
+	if loss.Amount != 2500 {
+		t.Errorf("Expected net loss 2500, got %d", loss.Amount)
+	}
+
+	_, err = svc.SetLossLimit(ctx, &SetLossLimitRequest{
+		PlayerID: playerID,
+		Period:   "daily",
+		Amount:   3000,
+	})
+	if err != nil {
+		t.Fatalf("Failed to set loss limit: %v", err)
+	}
+
+	t.Run("WagerWithinLossLimit", func(t *testing.T) {
+		if err := svc.CheckLossLimit(ctx, playerID, domain.Money{Amount: 500, Currency: "USD"}); err != nil {
+			t.Errorf("Expected wager within loss limit to be allowed: %v", err)
+		}
+	})
+
+	t.Run("WagerOverLossLimit", func(t *testing.T) {
+		err := svc.CheckLossLimit(ctx, playerID, domain.Money{Amount: 501, Currency: "USD"})
+		if !errors.Is(err, ErrLossLimitExceeded) {
+			t.Errorf("Expected ErrLossLimitExceeded, got %v", err)
+		}
+	})
+}
+
+func TestCheckSessionDuration(t *testing.T) {
+	svc, playerID, cleanup := setupTestLimits(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	t.Run("NoLimit", func(t *testing.T) {
+		if err := svc.CheckSessionDuration(ctx, playerID, time.Now().Add(-10*time.Hour)); err != nil {
+			t.Errorf("Expected no error without a limit, got %v", err)
+		}
+	})
+
+	_, err := svc.SetSessionLimit(ctx, &SetSessionLimitRequest{PlayerID: playerID, Minutes: 1})
+	if err != nil {
+		t.Fatalf("Failed to set session limit: %v", err)
+	}
+
+	t.Run("WithinLimit", func(t *testing.T) {
+		if err := svc.CheckSessionDuration(ctx, playerID, time.Now().Add(-30*time.Second)); err != nil {
+			t.Errorf("Expected session within limit to be allowed, got %v", err)
+		}
+	})
+
+	t.Run("SessionOlderThanLimit", func(t *testing.T) {
+		err := svc.CheckSessionDuration(ctx, playerID, time.Now().Add(-2*time.Minute))
+		if err != ErrSessionDurationExceeded {
+			t.Errorf("Expected ErrSessionDurationExceeded, got %v", err)
+		}
+	})
+}
+
+func TestRemoveSelfExclusion(t *testing.T) {
+	svc, playerID, cleanup := setupTestLimits(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	playerStatus := func() domain.PlayerStatus {
+		var status domain.PlayerStatus
+		svc.db.QueryRowContext(ctx, "SELECT status FROM players WHERE id = $1", playerID).Scan(&status)
+		return status
+	}
+
+	t.Run("NoActiveExclusion", func(t *testing.T) {
+		if _, err := svc.RemoveSelfExclusion(ctx, playerID, "support"); err != ErrExclusionNotFound {
+			t.Errorf("Expected ErrExclusionNotFound, got %v", err)
+		}
+	})
+
+	duration := 7 * 24 * time.Hour
+	exclusion, err := svc.SelfExclude(ctx, playerID, "Taking a break", &duration)
+	if err != nil {
+		t.Fatalf("Failed to self-exclude: %v", err)
+	}
+
+	t.Run("RejectedBeforeExpiry", func(t *testing.T) {
+		if _, err := svc.RemoveSelfExclusion(ctx, playerID, "support"); err != ErrExclusionNotExpired {
+			t.Errorf("Expected ErrExclusionNotExpired, got %v", err)
+		}
+		if playerStatus() != domain.PlayerStatusExcluded {
+			t.Error("Expected player to remain excluded")
+		}
+	})
+
+	t.Run("RemovedAfterExpiry", func(t *testing.T) {
+		past := time.Now().UTC().Add(-time.Hour)
+		svc.db.ExecContext(ctx, "UPDATE self_exclusions SET started_at = $1, expires_at = $2 WHERE id = $3",
+			past.Add(-duration), past, exclusion.ID)
+
+		removed, err := svc.RemoveSelfExclusion(ctx, playerID, "support")
+		if err != nil {
+			t.Fatalf("Failed to remove self-exclusion: %v", err)
+		}
+		if removed.IsActive || removed.RemovedAt == nil || removed.RemovedBy == nil || *removed.RemovedBy != "support" {
+			t.Errorf("Unexpected removed exclusion: %+v", removed)
+		}
+		if playerStatus() != domain.PlayerStatusActive {
+			t.Errorf("Expected player reactivated, got %s", playerStatus())
+		}
+	})
+
+	t.Run("PermanentRequiresMinimumPeriod", func(t *testing.T) {
+		permanent, err := svc.SelfExclude(ctx, playerID, "Permanent exclusion", nil)
+		if err != nil {
+			t.Fatalf("Failed to self-exclude: %v", err)
+		}
+
+		if _, err := svc.OverrideSelfExclusion(ctx, playerID, "compliance", "Player request reviewed"); err != ErrExclusionNotExpired {
+			t.Errorf("Expected ErrExclusionNotExpired, got %v", err)
+		}
+
+		svc.db.ExecContext(ctx, "UPDATE self_exclusions SET started_at = $1 WHERE id = $2",
+			time.Now().UTC().Add(-MinPermanentExclusionPeriod-time.Hour), permanent.ID)
+
+		if _, err := svc.RemoveSelfExclusion(ctx, playerID, "support"); err != ErrExclusionPermanent {
+			t.Errorf("Expected ErrExclusionPermanent without an override, got %v", err)
+		}
+
+		removed, err := svc.OverrideSelfExclusion(ctx, playerID, "compliance", "Player request reviewed")
+		if err != nil {
+			t.Fatalf("Expected override after minimum period, got %v", err)
+		}
+		if removed.RemovedBy == nil || *removed.RemovedBy != "compliance" {
+			t.Errorf("Expected exclusion removed by compliance, got %+v", removed)
+		}
+		if excluded, _ := svc.IsExcluded(ctx, playerID); excluded {
+			t.Error("Expected player to no longer be excluded")
+		}
+
+		var overrides int
+		svc.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM audit_events WHERE type = 'self_exclusion_override' AND player_id = $1",
+			playerID).Scan(&overrides)
+		if overrides != 1 {
+			t.Errorf("Expected the override to be audited once, got %d", overrides)
+		}
+	})
+}
+
+func TestMinExclusionPeriod(t *testing.T) {
+	svc, playerID, cleanup := setupTestLimits(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	// A one-day exclusion taken before the minimum was raised to three days
+	duration := 24 * time.Hour
+	exclusion, err := svc.SelfExclude(ctx, playerID, "Short break", &duration)
+	if err != nil {
+		t.Fatalf("Failed to self-exclude: %v", err)
+	}
+	svc.SetMinExclusionPeriod(72 * time.Hour)
+	backdate := func(age time.Duration) {
+		t.Helper()
+		started := time.Now().UTC().Add(-age)
+		_, err := svc.db.ExecContext(ctx, "UPDATE self_exclusions SET started_at = $1, expires_at = $2 WHERE id = $3",
+			started, started.Add(duration), exclusion.ID)
+		if err != nil {
+			t.Fatalf("Failed to backdate exclusion: %v", err)
+		}
+	}
+
+	t.Run("ShorterDurationRejected", func(t *testing.T) {
+		if _, err := svc.SelfExclude(ctx, playerID, "Short break", &duration); err != ErrExclusionTooShort {
+			t.Errorf("Expected ErrExclusionTooShort, got %v", err)
+		}
+	})
+
+	t.Run("NotExpiredBeforeMinimumPeriod", func(t *testing.T) {
+		backdate(48 * time.Hour)
+		if n, err := svc.ExpireSelfExclusions(ctx); err != nil || n != 0 {
+			t.Errorf("Expected nothing expired before the minimum period, got %d, %v", n, err)
+		}
+		if excluded, err := svc.IsExcluded(ctx, playerID); err != nil || !excluded {
+			t.Errorf("Expected the player to stay excluded, got %v, %v", excluded, err)
+		}
+	})
+
+	t.Run("RejectedBeforeMinimumPeriod", func(t *testing.T) {
+		backdate(48 * time.Hour)
+		if _, err := svc.RemoveSelfExclusion(ctx, playerID, "player"); err != ErrExclusionNotExpired {
+			t.Errorf("Expected ErrExclusionNotExpired before the minimum period, got %v", err)
+		}
+		if _, err := svc.OverrideSelfExclusion(ctx, playerID, "compliance", "Reviewed"); err != ErrExclusionNotExpired {
+			t.Errorf("Expected an override to respect the minimum period, got %v", err)
+		}
+	})
+
+	t.Run("RemovedAfterMinimumPeriod", func(t *testing.T) {
+		backdate(73 * time.Hour)
+		if _, err := svc.RemoveSelfExclusion(ctx, playerID, "player"); err != nil {
+			t.Errorf("Expected removal after the minimum period, got %v", err)
+		}
+	})
+}
+
+func TestOperatorLimitOverridesPlayerLimit(t *testing.T) {
+	svc, playerID, cleanup := setupTestLimits(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	// Player sets a loose daily wager limit
+	_, err := svc.SetWagerLimit(ctx, &SetWagerLimitRequest{
+		PlayerID: playerID,
+		Period:   "daily",
+		Amount:   50000, // $500
+	})
+	if err != nil {
+		t.Fatalf("Failed to set player wager limit: %v", err)
+	}
+
+	// Operator caps it lower
+	limits, err := svc.SetOperatorLimit(ctx, &SetImposedLimitRequest{
+		PlayerID:  playerID,
+		LimitType: "daily_wager",
+		Amount:    2000, // $20
+		SetBy:     "compliance",
+	})
+	if err != nil {
+		t.Fatalf("Failed to set operator limit: %v", err)
+	}
+
+	t.Run("MostRestrictiveApplies", func(t *testing.T) {
+		if limits.DailyWager == nil || limits.DailyWager.Amount != 2000 {
+			t.Errorf("Expected effective daily wager 2000, got %v", limits.DailyWager)
+		}
+		if limits.Source != domain.LimitSourceOperator {
+			t.Errorf("Expected operator source, got %s", limits.Source)
+		}
+	})
+
+	t.Run("EnforcedInChecks", func(t *testing.T) {
+		err := svc.CheckWagerLimit(ctx, playerID, domain.Money{Amount: 3000, Currency: "USD"})
+		if !errors.Is(err, ErrWagerLimitExceeded) {
+			t.Errorf("Expected ErrWagerLimitExceeded, got %v", err)
+		}
+		if err := svc.CheckWagerLimit(ctx, playerID, domain.Money{Amount: 1000, Currency: "USD"}); err != nil {
+			t.Errorf("Expected wager within operator limit to be allowed: %v", err)
+		}
+	})
+
+	t.Run("PlayerCannotExceedCap", func(t *testing.T) {
+		_, err := svc.SetWagerLimit(ctx, &SetWagerLimitRequest{
+			PlayerID: playerID,
+			Period:   "daily",
+			Amount:   10000,
+		})
+		if !errors.Is(err, ErrLimitAboveCap) {
+			t.Errorf("Expected ErrLimitAboveCap, got %v", err)
+		}
+
+		// Tightening below the cap is allowed
+		limits, err := svc.SetWagerLimit(ctx, &SetWagerLimitRequest{
+			PlayerID: playerID,
+			Period:   "daily",
+			Amount:   1500,
+		})
+		if err != nil {
+			t.Fatalf("Failed to tighten player limit: %v", err)
+		}
+		if limits.DailyWager.Amount != 1500 || limits.Source != domain.LimitSourcePlayer {
+			t.Errorf("Expected player limit 1500 to apply, got %d from %s", limits.DailyWager.Amount, limits.Source)
+		}
+	})
+}
+
+func TestRegulatorLimitWithoutPlayerLimit(t *testing.T) {
+	svc, playerID, cleanup := setupTestLimits(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	_, err := svc.SetRegulatorLimit(ctx, &SetImposedLimitRequest{
+		PlayerID:  playerID,
+		LimitType: "daily_deposit",
+		Amount:    10000, // $100
+		SetBy:     "regulator",
+	})
+	if err != nil {
+		t.Fatalf("Failed to set regulator limit: %v", err)
+	}
+
+	if err := svc.CheckDepositLimit(ctx, playerID, domain.Money{Amount: 20000, Currency: "USD"}); err == nil {
+		t.Error("Expected deposit above regulator limit to be rejected")
+	}
+
+	_, err = svc.SetRegulatorLimit(ctx, &SetImposedLimitRequest{
+		PlayerID:  playerID,
+		LimitType: "hourly_deposit",
+		Amount:    100,
+	})
+	if err == nil {
+		t.Error("Expected unknown limit type to be rejected")
+	}
+}
+
+func TestGetResponsibleGamingStatus(t *testing.T) {
+	svc, playerID, cleanup := setupTestLimits(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	addTx := func(txType string, amount int64, age time.Duration) {
+		t.Helper()
+		at := time.Now().UTC().Add(-age)
+		_, err := svc.db.ExecContext(ctx, `
+			INSERT INTO transactions (id, player_id, type, amount, currency, balance_before, balance_after, status, reference, description, created_at, completed_at)
+			VALUES ($1, $2, $3, $4, 'USD', 0, 0, 'completed', $5, 'test', $6, $6)
+		`, uuid.New().String(), playerID, txType, amount, uuid.New().String(), at)
+		if err != nil {
+			t.Fatalf("Failed to insert %s transaction: %v", txType, err)
+		}
+	}
+
+	t.Run("NoActivity", func(t *testing.T) {
+		status, err := svc.GetResponsibleGamingStatus(ctx, playerID)
+		if err != nil {
+			t.Fatalf("Failed to get status: %v", err)
+		}
+		if status.Excluded || status.Exclusion != nil {
+			t.Error("Expected player not to be excluded")
+		}
+		if status.DailyDeposits.Amount != 0 || status.WeeklyLoss.Amount != 0 {
+			t.Errorf("Expected no usage, got %+v", status)
+		}
+		if status.Limits.DailyDeposit != nil {
+			t.Error("Expected no daily deposit limit")
+		}
+	})
+
+	_, err := svc.SetDepositLimit(ctx, &SetDepositLimitRequest{PlayerID: playerID, Period: "daily", Amount: 50000})
+	if err != nil {
+		t.Fatalf("Failed to set deposit limit: %v", err)
+	}
+	_, err = svc.SetLossLimit(ctx, &SetLossLimitRequest{PlayerID: playerID, Period: "weekly", Amount: 20000})
+	if err != nil {
+		t.Fatalf("Failed to set loss limit: %v", err)
+	}
+
+	// Today, three days ago and twenty days ago
+	addTx("deposit", 10000, time.Hour)
+	addTx("wager", 2000, time.Hour)
+	addTx("win", 500, time.Hour)
+	addTx("deposit", 5000, 3*24*time.Hour)
+	addTx("wager", 3000, 3*24*time.Hour)
+	addTx("deposit", 7000, 20*24*time.Hour)
+	addTx("wager", 4000, 20*24*time.Hour)
+
+	t.Run("Usage", func(t *testing.T) {
+		status, err := svc.GetResponsibleGamingStatus(ctx, playerID)
+		if err != nil {
+			t.Fatalf("Failed to get status: %v", err)
+		}
+
+		if status.Limits.DailyDeposit == nil || status.Limits.DailyDeposit.Amount != 50000 {
+			t.Errorf("Expected daily deposit limit 50000, got %v", status.Limits.DailyDeposit)
+		}
+		if status.Limits.WeeklyLoss == nil || status.Limits.WeeklyLoss.Amount != 20000 {
+			t.Errorf("Expected weekly loss limit 20000, got %v", status.Limits.WeeklyLoss)
+		}
+
+		checks := []struct {
+			name string
+			got  domain.Money
+			want int64
+		}{
+			{"daily deposits", status.DailyDeposits, 10000},
+			{"weekly deposits", status.WeeklyDeposits, 15000},
+			{"monthly deposits", status.MonthlyDeposits, 22000},
+			{"daily wagers", status.DailyWagers, 2000},
+			{"weekly wagers", status.WeeklyWagers, 5000},
+			{"daily loss", status.DailyLoss, 1500},
+			{"weekly loss", status.WeeklyLoss, 4500},
+		}
+		for _, c := range checks {
+			if c.got.Amount != c.want || c.got.Currency != "USD" {
+				t.Errorf("Expected %s %d USD, got %s", c.name, c.want, c.got)
+			}
+		}
+	})
+
+	t.Run("Excluded", func(t *testing.T) {
+		duration := 7 * 24 * time.Hour
+		exclusion, err := svc.SelfExclude(ctx, playerID, "taking a break", &duration)
+		if err != nil {
+			t.Fatalf("Failed to self-exclude: %v", err)
+		}
+
+		status, err := svc.GetResponsibleGamingStatus(ctx, playerID)
+		if err != nil {
+			t.Fatalf("Failed to get status: %v", err)
+		}
+		if !status.Excluded || status.Exclusion == nil {
+			t.Fatal("Expected player to be excluded")
+		}
+		if status.Exclusion.ID != exclusion.ID || status.Exclusion.ExpiresAt == nil {
+			t.Errorf("Expected exclusion %s with an expiry, got %+v", exclusion.ID, status.Exclusion)
+		}
+	})
+}
+
+func TestExpireSelfExclusions(t *testing.T) {
+	svc, playerID, cleanup := setupTestLimits(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	playerStatus := func(id string) domain.PlayerStatus {
+		var status domain.PlayerStatus
+		svc.db.QueryRowContext(ctx, "SELECT status FROM players WHERE id = $1", id).Scan(&status)
+		return status
+	}
+
+	// An exclusion that ran out an hour ago
+	duration := 7 * 24 * time.Hour
+	expired, err := svc.SelfExclude(ctx, playerID, "Taking a break", &duration)
+	if err != nil {
+		t.Fatalf("Failed to self-exclude: %v", err)
+	}
+	past := time.Now().UTC().Add(-time.Hour)
+	svc.db.ExecContext(ctx, "UPDATE self_exclusions SET started_at = $1, expires_at = $2 WHERE id = $3",
+		past.Add(-duration), past, expired.ID)
+
+	// A second player whose exclusion is still running
+	otherID := uuid.New().String()
+	_, err = svc.db.ExecContext(ctx, `
+		INSERT INTO players (id, username, email, password_hash, status, registration_date, tc_accepted_at, created_at, updated_at)
+		VALUES ($1, 'stillexcluded', 'still@example.com', 'hash', 'active', NOW(), NOW(), NOW(), NOW())
+	`, otherID)
+	if err != nil {
+		t.Fatalf("Failed to create player: %v", err)
+	}
+	if _, err := svc.SelfExclude(ctx, otherID, "Taking a break", &duration); err != nil {
+		t.Fatalf("Failed to self-exclude: %v", err)
+	}
+
+	n, err := svc.ExpireSelfExclusions(ctx)
+	if err != nil {
+		t.Fatalf("Failed to expire self-exclusions: %v", err)
+	}
+	if n != 1 {
+		t.Errorf("Expected 1 expired exclusion, got %d", n)
+	}
+
+	var active bool
+	var removedBy sql.NullString
+	svc.db.QueryRowContext(ctx, "SELECT is_active, removed_by FROM self_exclusions WHERE id = $1", expired.ID).Scan(&active, &removedBy)
+	if active || removedBy.String != ExclusionExpiredBy {
+		t.Errorf("Expected exclusion deactivated by %s, got active=%v removed_by=%q", ExclusionExpiredBy, active, removedBy.String)
+	}
+	if status := playerStatus(playerID); status != domain.PlayerStatusActive {
+		t.Errorf("Expected player reactivated, got %s", status)
+	}
+	if status := playerStatus(otherID); status != domain.PlayerStatusExcluded {
+		t.Errorf("Expected player with a running exclusion to stay excluded, got %s", status)
+	}
+
+	var audited int
+	svc.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM audit_events WHERE type = 'self_exclusion_expired' AND player_id = $1",
+		playerID).Scan(&audited)
+	if audited != 1 {
+		t.Errorf("Expected the expiry to be audited once, got %d", audited)
+	}
+
+	if n, err := svc.ExpireSelfExclusions(ctx); err != nil || n != 0 {
+		t.Errorf("Expected nothing left to expire, got %d, %v", n, err)
+	}
+}
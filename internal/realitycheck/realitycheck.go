@@ -0,0 +1,229 @@
+// Package realitycheck reminds players how long they have been playing
+// Compliant with GLI-19 §2.5.5: Limitations and Exclusions
+//
+// Key Requirements:
+//   - Active play time is tracked per player across game sessions
+//   - A reality check is raised each time play crosses the configured interval
+//   - Play is held until the player acknowledges the reality check
+//   - Checks and acknowledgements are logged
+package realitycheck
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/alexbotov/rgs/internal/audit"
+	"github.com/alexbotov/rgs/internal/database"
+	"github.com/alexbotov/rgs/internal/domain"
+	"github.com/alexbotov/rgs/internal/events"
+)
+
+var (
+	ErrAcknowledgementRequired = errors.New("reality check must be acknowledged before play continues")
+	ErrNoPendingCheck          = errors.New("no reality check awaiting acknowledgement")
+)
+
+// MaxPlayGap is the longest time between two game cycles that counts as
+// continuous play. A longer pause adds only this much, so a player who walks
+// away is not reminded of time they did not spend playing.
+const MaxPlayGap = 5 * time.Minute
+
+// Status is a player's play time and reality check state
+type Status struct {
+	PlayerID       string        `json:"player_id"`
+	PlayedTime     time.Duration `json:"played_time"`
+	NextCheckAt    time.Duration `json:"next_check_at"` // Play time at which the next check is raised
+	Pending        bool          `json:"pending"`       // A check awaits acknowledgement
+	LastPlayAt     *time.Time    `json:"last_play_at,omitempty"`
+	NotifiedAt     *time.Time    `json:"notified_at,omitempty"`
+	AcknowledgedAt *time.Time    `json:"acknowledged_at,omitempty"`
+}
+
+// Service tracks play time and raises reality checks
+type Service struct {
+	db       *sql.DB
+	audit    *audit.Service
+	events   *events.Hub
+	interval time.Duration
+}
+
+// New creates a reality check service raising a check every interval of
+// active play; a zero interval only tracks play time
+func New(db *sql.DB, auditSvc *audit.Service, hub *events.Hub, interval time.Duration) *Service {
+	return &Service{
+		db:       db,
+		audit:    auditSvc,
+		events:   hub,
+		interval: interval,
+	}
+}
+
+// Check returns ErrAcknowledgementRequired while a reality check awaits the
+// player's acknowledgement
+func (s *Service) Check(ctx context.Context, playerID string) error {
+	var pending bool
+	err := s.db.QueryRowContext(ctx, "SELECT pending FROM reality_checks WHERE player_id = $1", playerID).Scan(&pending)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
+	if pending {
+		return ErrAcknowledgementRequired
+	}
+	return nil
+}
+
+// RecordPlay adds the time since the player's previous game cycle, up to
+// MaxPlayGap, to their play time, and raises a reality check when that
+// crosses the next interval. A raised check is audited and pushed to the
+// player's connections.
+func (s *Service) RecordPlay(ctx context.Context, playerID string, at time.Time) (*Status, error) {
+	at = at.UTC()
+	var status *Status
+	var raised bool
+	err := database.WithTx(ctx, s.db, func(dbTx *sql.Tx) error {
+		_, err := dbTx.ExecContext(ctx, `
+			INSERT INTO reality_checks (player_id, next_check_ms) VALUES ($1, $2)
+			ON CONFLICT (player_id) DO NOTHING
+		`, playerID, s.interval.Milliseconds())
+		if err != nil {
+			return err
+		}
+
+		status, err = scanStatus(ctx, dbTx, playerID, " FOR UPDATE")
+		if err != nil {
+			return err
+		}
+
+		if status.LastPlayAt != nil && at.After(*status.LastPlayAt) {
+			gap := at.Sub(*status.LastPlayAt)
+			if gap > MaxPlayGap {
+				gap = MaxPlayGap
+			}
+			status.PlayedTime += gap
+		}
+		status.LastPlayAt = &at
+
+		// A check is raised once per crossing; play that overshoots several
+		// intervals while one is pending raises a single check
+		if s.interval > 0 && !status.Pending && status.PlayedTime >= status.NextCheckAt {
+			raised = true
+			status.Pending = true
+			status.NotifiedAt = &at
+			for status.NextCheckAt <= status.PlayedTime {
+				status.NextCheckAt += s.interval
+			}
+		}
+
+		_, err = dbTx.ExecContext(ctx, `
+			UPDATE reality_checks SET played_ms = $1, next_check_ms = $2, last_play_at = $3,
+				pending = $4, notified_at = $5
+			WHERE player_id = $6
+		`, status.PlayedTime.Milliseconds(), status.NextCheckAt.Milliseconds(), at,
+			status.Pending, status.NotifiedAt, playerID)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to record play time: %w", err)
+	}
+
+	if raised {
+		s.notify(ctx, status)
+	}
+	return status, nil
+}
+
+// notify audits a raised reality check and pushes it to the player
+func (s *Service) notify(ctx context.Context, status *Status) {
+	minutes := int64(status.PlayedTime / time.Minute)
+	s.audit.Log(ctx, audit.EventRealityCheck, domain.SeverityInfo,
+		fmt.Sprintf("Reality check after %d minutes of play", minutes),
+		map[string]interface{}{"played_minutes": minutes},
+		audit.WithPlayer(status.PlayerID), audit.WithComponent("realitycheck"))
+
+	s.events.Publish(events.Event{
+		Type:     events.RealityCheck,
+		PlayerID: status.PlayerID,
+		Data: map[string]interface{}{
+			"played_minutes":           minutes,
+			"acknowledgement_required": true,
+		},
+	})
+}
+
+// Acknowledge records that the player has seen the pending reality check, so
+// play can continue
+func (s *Service) Acknowledge(ctx context.Context, playerID string) (*Status, error) {
+	now := time.Now().UTC()
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE reality_checks SET pending = false, acknowledged_at = $1
+		WHERE player_id = $2 AND pending = true
+	`, now, playerID)
+	if err != nil {
+		return nil, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return nil, ErrNoPendingCheck
+	}
+
+	status, err := s.GetStatus(ctx, playerID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.audit.Log(ctx, audit.EventRealityCheckAck, domain.SeverityInfo,
+		"Reality check acknowledged",
+		map[string]interface{}{"played_minutes": int64(status.PlayedTime / time.Minute)},
+		audit.WithPlayer(playerID), audit.WithComponent("realitycheck"))
+
+	return status, nil
+}
+
+// GetStatus returns a player's play time and reality check state. A player
+// who has not played yet has no play time and the first check one interval
+// away.
+func (s *Service) GetStatus(ctx context.Context, playerID string) (*Status, error) {
+	status, err := scanStatus(ctx, s.db, playerID, "")
+	if errors.Is(err, sql.ErrNoRows) {
+		return &Status{PlayerID: playerID, NextCheckAt: s.interval}, nil
+	}
+	return status, err
+}
+
+// rowQueryer is implemented by both *sql.DB and *sql.Tx
+type rowQueryer interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// scanStatus reads a reality check row, appending suffix (e.g. a locking
+// clause) to the query
+func scanStatus(ctx context.Context, q rowQueryer, playerID, suffix string) (*Status, error) {
+	status := &Status{PlayerID: playerID}
+	var played, next int64
+	var lastPlayAt, notifiedAt, acknowledgedAt sql.NullTime
+	err := q.QueryRowContext(ctx, `
+		SELECT played_ms, next_check_ms, pending, last_play_at, notified_at, acknowledged_at
+		FROM reality_checks WHERE player_id = $1`+suffix,
+		playerID).Scan(&played, &next, &status.Pending, &lastPlayAt, &notifiedAt, &acknowledgedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	status.PlayedTime = time.Duration(played) * time.Millisecond
+	status.NextCheckAt = time.Duration(next) * time.Millisecond
+	if lastPlayAt.Valid {
+		status.LastPlayAt = &lastPlayAt.Time
+	}
+	if notifiedAt.Valid {
+		status.NotifiedAt = &notifiedAt.Time
+	}
+	if acknowledgedAt.Valid {
+		status.AcknowledgedAt = &acknowledgedAt.Time
+	}
+	return status, nil
+}
@@ -0,0 +1,143 @@
+package realitycheck
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alexbotov/rgs/internal/audit"
+	"github.com/alexbotov/rgs/internal/database/dbtest"
+	"github.com/alexbotov/rgs/internal/events"
+	"github.com/google/uuid"
+)
+
+func TestMain(m *testing.M) {
+	dbtest.Main(m)
+}
+
+func setupTestRealityCheck(t *testing.T, interval time.Duration) (*Service, *events.Hub, string) {
+	t.Helper()
+
+	db := dbtest.Open(t)
+	hub := events.New()
+	svc := New(db.DB, audit.New(db.DB), hub, interval)
+
+	// Create a test player
+	playerID := uuid.New().String()
+	_, err := db.DB.Exec(`
+		INSERT INTO players (id, username, email, password_hash, status, registration_date, tc_accepted_at, created_at, updated_at)
+		VALUES ($1, 'realityuser', 'reality@example.com', 'hash', 'active', NOW(), NOW(), NOW(), NOW())
+	`, playerID)
+	if err != nil {
+		t.Fatalf("Failed to create test player: %v", err)
+	}
+
+	return svc, hub, playerID
+}
+
+func TestRealityCheck(t *testing.T) {
+	svc, hub, playerID := setupTestRealityCheck(t, time.Hour)
+
+	ctx := context.Background()
+	sub := hub.Subscribe(playerID)
+	defer sub.Close()
+
+	// Play a cycle every 4 minutes, up to 56 minutes of play
+	start := time.Now().UTC().Add(-24 * time.Hour)
+	for i := 0; i <= 14; i++ {
+		status, err := svc.RecordPlay(ctx, playerID, start.Add(time.Duration(i)*4*time.Minute))
+		if err != nil {
+			t.Fatalf("Failed to record play: %v", err)
+		}
+		if status.Pending {
+			t.Fatalf("Reality check raised early, after %v of play", status.PlayedTime)
+		}
+		if err := svc.Check(ctx, playerID); err != nil {
+			t.Fatalf("Expected play to be allowed before the threshold, got %v", err)
+		}
+	}
+
+	t.Run("FiresAtThreshold", func(t *testing.T) {
+		status, err := svc.RecordPlay(ctx, playerID, start.Add(60*time.Minute))
+		if err != nil {
+			t.Fatalf("Failed to record play: %v", err)
+		}
+		if !status.Pending || status.PlayedTime != time.Hour {
+			t.Fatalf("Expected a pending check after 1h of play, got pending=%v played=%v", status.Pending, status.PlayedTime)
+		}
+		if status.NextCheckAt != 2*time.Hour {
+			t.Errorf("Expected next check at 2h, got %v", status.NextCheckAt)
+		}
+
+		select {
+		case event := <-sub.C:
+			if event.Type != events.RealityCheck || event.Data["played_minutes"] != int64(60) {
+				t.Errorf("Expected reality check push after 60 minutes, got %+v", event)
+			}
+		default:
+			t.Error("Expected reality check to be pushed to the player")
+		}
+	})
+
+	t.Run("HoldsPlayUntilAcknowledged", func(t *testing.T) {
+		if err := svc.Check(ctx, playerID); err != ErrAcknowledgementRequired {
+			t.Fatalf("Expected ErrAcknowledgementRequired, got %v", err)
+		}
+
+		status, err := svc.Acknowledge(ctx, playerID)
+		if err != nil {
+			t.Fatalf("Failed to acknowledge: %v", err)
+		}
+		if status.Pending || status.AcknowledgedAt == nil {
+			t.Errorf("Expected acknowledged check, got %+v", status)
+		}
+		if err := svc.Check(ctx, playerID); err != nil {
+			t.Errorf("Expected play to continue after acknowledgement, got %v", err)
+		}
+		if _, err := svc.Acknowledge(ctx, playerID); err != ErrNoPendingCheck {
+			t.Errorf("Expected ErrNoPendingCheck, got %v", err)
+		}
+	})
+
+	t.Run("PausesNotCounted", func(t *testing.T) {
+		// Two hours away adds only MaxPlayGap
+		status, err := svc.RecordPlay(ctx, playerID, start.Add(3*time.Hour))
+		if err != nil {
+			t.Fatalf("Failed to record play: %v", err)
+		}
+		if status.PlayedTime != time.Hour+MaxPlayGap {
+			t.Errorf("Expected %v of play, got %v", time.Hour+MaxPlayGap, status.PlayedTime)
+		}
+		if status.Pending {
+			t.Error("Expected no check before the next interval")
+		}
+	})
+
+	t.Run("Persisted", func(t *testing.T) {
+		status, err := svc.GetStatus(ctx, playerID)
+		if err != nil {
+			t.Fatalf("Failed to get status: %v", err)
+		}
+		if status.PlayedTime != time.Hour+MaxPlayGap || status.AcknowledgedAt == nil || status.NotifiedAt == nil {
+			t.Errorf("Expected persisted play time and check times, got %+v", status)
+		}
+	})
+}
+
+func TestRealityCheckNewPlayer(t *testing.T) {
+	svc, _, _ := setupTestRealityCheck(t, time.Hour)
+
+	ctx := context.Background()
+	playerID := uuid.New().String()
+
+	if err := svc.Check(ctx, playerID); err != nil {
+		t.Errorf("Expected a player who has not played to be allowed, got %v", err)
+	}
+	status, err := svc.GetStatus(ctx, playerID)
+	if err != nil {
+		t.Fatalf("Failed to get status: %v", err)
+	}
+	if status.PlayedTime != 0 || status.NextCheckAt != time.Hour {
+		t.Errorf("Expected no play and the first check at 1h, got %+v", status)
+	}
+}
@@ -3,15 +3,21 @@
 package rng
 
 import (
+	"context"
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"io"
+	"log"
 	"math"
 	"sync"
 	"time"
 )
 
+var ErrNotReseedable = errors.New("seeded RNG cannot be reseeded")
+
 // Service provides cryptographically strong random number generation
 // GLI-19 §3.2: General RNG Requirements
 // GLI-19 §3.3: RNG Strength and Monitoring
@@ -19,32 +25,111 @@ type Service struct {
 	entropy io.Reader
 	mu      sync.Mutex
 
+	// source opens the entropy source on reseed; nil for seeded replay
+	source func() io.Reader
+
 	// Statistics for monitoring
 	lastHealthCheck time.Time
 	samplesGenerated int64
+	lastReseed       time.Time
+	failedDraws      int64 // Entropy reads that failed, ever
+	failedAtCheck    int64 // failedDraws at the last health check
+	lastHealth       *HealthResult
 }
 
 // New creates a new RNG service using crypto/rand
 func New() *Service {
+	return newWithSource(func() io.Reader { return rand.Reader })
+}
+
+// newWithSource creates an RNG service reading entropy from source
+func newWithSource(source func() io.Reader) *Service {
+	now := time.Now()
 	return &Service{
-		entropy:         rand.Reader,
+		entropy:         source(),
+		source:          source,
+		lastHealthCheck: now,
+		lastReseed:      now,
+	}
+}
+
+// NewSeeded creates an RNG service that produces a deterministic stream from
+// seed. It exists to replay recorded game cycles for certification and
+// dispute resolution and must never drive live outcomes.
+// GLI-19 §4.14: Game Recall
+func NewSeeded(seed []byte) *Service {
+	return &Service{
+		entropy:         newSeededReader(seed),
 		lastHealthCheck: time.Now(),
 	}
 }
 
+// seededReader expands a seed into a byte stream using SHA-256 in counter mode
+type seededReader struct {
+	seed    []byte
+	counter uint64
+	block   []byte
+}
+
+func newSeededReader(seed []byte) *seededReader {
+	return &seededReader{seed: append([]byte(nil), seed...)}
+}
+
+func (r *seededReader) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		if len(r.block) == 0 {
+			var ctr [8]byte
+			binary.BigEndian.PutUint64(ctr[:], r.counter)
+			r.counter++
+			h := sha256.New()
+			h.Write(r.seed)
+			h.Write(ctr[:])
+			r.block = h.Sum(nil)
+		}
+		c := copy(p[n:], r.block)
+		r.block = r.block[c:]
+		n += c
+	}
+	return n, nil
+}
+
 // GenerateBytes returns n cryptographically random bytes
 // GLI-19 §3.3.1: RNG Strength for Outcome Determination
 func (s *Service) GenerateBytes(n int) ([]byte, error) {
+	buf := make([]byte, n)
+	if err := s.GenerateBytesInto(buf); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+// GenerateBytesInto fills buf with cryptographically random bytes without
+// allocating
+// GLI-19 §3.3.1: RNG Strength for Outcome Determination
+func (s *Service) GenerateBytesInto(buf []byte) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	buf := make([]byte, n)
 	if _, err := io.ReadFull(s.entropy, buf); err != nil {
-		return nil, fmt.Errorf("failed to generate random bytes: %w", err)
+		s.failedDraws++
+		return fmt.Errorf("failed to generate random bytes: %w", err)
 	}
 
 	s.samplesGenerated++
-	return buf, nil
+	return nil
+}
+
+// GenerateUint64 returns a uniformly distributed random 64-bit value. All 64
+// bits are random, so reducing it to a range still needs rejection sampling
+// to avoid modulo bias; use GenerateInt for values in [0, max).
+// GLI-19 §3.2.3: Distribution
+func (s *Service) GenerateUint64() (uint64, error) {
+	var buf [8]byte
+	if err := s.GenerateBytesInto(buf[:]); err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint64(buf[:]), nil
 }
 
 // GenerateInt returns a random integer in range [0, max)
@@ -64,6 +149,7 @@ func (s *Service) GenerateInt(max int64) (int64, error) {
 	for {
 		buf := make([]byte, 8)
 		if _, err := io.ReadFull(s.entropy, buf); err != nil {
+			s.failedDraws++
 			return 0, fmt.Errorf("failed to generate random int: %w", err)
 		}
 
@@ -156,11 +242,55 @@ func (s *Service) SelectWeighted(weights []float64) (int, error) {
 	return len(weights) - 1, nil
 }
 
-// HealthCheck verifies RNG is functioning correctly
+// Reseed re-opens the entropy source and checks it can be read. crypto/rand
+// is continuously reseeded by the operating system, so for it this replaces
+// a reader that has started failing and confirms the source still works.
+// GLI-19 §3.3.2: Seeding and Re-seeding
+func (s *Service) Reseed() error {
+	if s.source == nil {
+		return ErrNotReseedable
+	}
+
+	entropy := s.source()
+	var probe [32]byte
+	_, err := io.ReadFull(entropy, probe[:])
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err != nil {
+		s.failedDraws++
+		return fmt.Errorf("failed to reseed: %w", err)
+	}
+	s.entropy = entropy
+	s.lastReseed = time.Now()
+	return nil
+}
+
+// RunReseeder reseeds the RNG every interval until ctx is cancelled
+func (s *Service) RunReseeder(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.Reseed(); err != nil {
+				log.Printf("RNG reseed failed: %v", err)
+			}
+		}
+	}
+}
+
+// HealthCheck verifies RNG is functioning correctly. Entropy reads that
+// failed since the previous check mark the RNG unhealthy even when the
+// check's own samples succeed, so an intermittently failing source is caught.
 // GLI-19 §3.3.3: Dynamic Output Monitoring
 func (s *Service) HealthCheck() (*HealthResult, error) {
 	s.mu.Lock()
 	s.lastHealthCheck = time.Now()
+	failedBefore := s.failedDraws - s.failedAtCheck
 	s.mu.Unlock()
 
 	// Generate test samples
@@ -170,11 +300,13 @@ func (s *Service) HealthCheck() (*HealthResult, error) {
 	for i := 0; i < sampleSize; i++ {
 		n, err := s.GenerateInt(100)
 		if err != nil {
-			return &HealthResult{
+			result := &HealthResult{
 				Healthy:   false,
 				Timestamp: time.Now(),
 				Error:     err.Error(),
-			}, err
+			}
+			s.recordCheck(result)
+			return result, err
 		}
 		samples[i] = n
 	}
@@ -182,13 +314,78 @@ func (s *Service) HealthCheck() (*HealthResult, error) {
 	// Run basic chi-square test
 	chiSquare, passed := s.chiSquareTest(samples, 100)
 
-	return &HealthResult{
-		Healthy:          passed,
-		Timestamp:        time.Now(),
-		SamplesGenerated: s.samplesGenerated,
-		ChiSquare:        chiSquare,
-		ChiSquarePassed:  passed,
-	}, nil
+	// Independence of successive values (GLI-19 §3.2.2)
+	correlation, correlationPassed := serialCorrelationTest(samples)
+	runsZ, runsPassed := runsTest(samples, 100)
+
+	result := &HealthResult{
+		Healthy:                 passed && correlationPassed && runsPassed && failedBefore == 0,
+		Timestamp:               time.Now(),
+		ChiSquare:               chiSquare,
+		ChiSquarePassed:         passed,
+		SerialCorrelation:       correlation,
+		SerialCorrelationPassed: correlationPassed,
+		RunsZ:                   runsZ,
+		RunsTestPassed:          runsPassed,
+	}
+	if failedBefore > 0 {
+		result.Error = fmt.Sprintf("%d entropy reads failed since the last health check", failedBefore)
+	}
+	s.recordCheck(result)
+	return result, nil
+}
+
+// recordCheck fills in the monitoring counters of a health result and starts
+// counting failures afresh for the next check
+func (s *Service) recordCheck(result *HealthResult) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result.SamplesGenerated = s.samplesGenerated
+	result.FailedDraws = s.failedDraws
+	result.LastReseed = s.lastReseed
+	s.failedAtCheck = s.failedDraws
+	s.lastHealth = result
+}
+
+// LastHealth returns the result of the most recent health check, whether run
+// on demand or by the monitor, or nil before the first
+func (s *Service) LastHealth() *HealthResult {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.lastHealth == nil {
+		return nil
+	}
+	result := *s.lastHealth
+	return &result
+}
+
+// Health returns the most recent health check result without re-running the
+// self-test, running one only if none has run yet
+func (s *Service) Health() *HealthResult {
+	if result := s.LastHealth(); result != nil {
+		return result
+	}
+	result, _ := s.HealthCheck()
+	return result
+}
+
+// RunMonitor runs the health self-test every interval until ctx is
+// cancelled, so a failing RNG is noticed between on-demand checks
+// GLI-19 §3.3.3: Dynamic Output Monitoring
+func (s *Service) RunMonitor(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if result, err := s.HealthCheck(); err != nil || !result.Healthy {
+				log.Printf("RNG self-test failed: %s", result.Error)
+			}
+		}
+	}
 }
 
 // chiSquareTest performs a basic chi-square test for uniformity
@@ -221,6 +418,71 @@ func (s *Service) chiSquareTest(samples []int64, bins int) (float64, bool) {
 	return chiSquare, chiSquare < criticalValue
 }
 
+// independenceZ is the critical z-score of the independence tests. At 99.9%
+// confidence they add little to the chance of a false alarm from chi-square.
+const independenceZ = 3.29
+
+// serialCorrelationTest computes the lag-1 serial correlation of samples.
+// For independent values it is near 0 with a standard error of 1/sqrt(n).
+// GLI-19 §3.2.2: Statistical Analysis
+func serialCorrelationTest(samples []int64) (float64, bool) {
+	if len(samples) < 3 {
+		return 0, false
+	}
+
+	var sumXY, sumX, sumY, sumX2, sumY2 float64
+	n := float64(len(samples) - 1)
+	for i := 0; i < len(samples)-1; i++ {
+		x, y := float64(samples[i]), float64(samples[i+1])
+		sumXY += x * y
+		sumX += x
+		sumY += y
+		sumX2 += x * x
+		sumY2 += y * y
+	}
+
+	denominator := math.Sqrt(n*sumX2-sumX*sumX) * math.Sqrt(n*sumY2-sumY*sumY)
+	if denominator == 0 {
+		return 0, false // A constant stream has no meaningful correlation
+	}
+	correlation := (n*sumXY - sumX*sumY) / denominator
+
+	return correlation, math.Abs(correlation) < independenceZ/math.Sqrt(n)
+}
+
+// runsTest performs a Wald-Wolfowitz runs test of samples drawn from
+// [0, max), counting runs above and below the midpoint, and returns the
+// z-score of the number of runs
+// GLI-19 §3.2.2: Statistical Analysis
+func runsTest(samples []int64, max int64) (float64, bool) {
+	var above, below float64
+	runs := 0.0
+	for i, sample := range samples {
+		high := sample >= max/2
+		if high {
+			above++
+		} else {
+			below++
+		}
+		if i == 0 || high != (samples[i-1] >= max/2) {
+			runs++
+		}
+	}
+	if above == 0 || below == 0 {
+		return 0, false
+	}
+
+	n := above + below
+	expected := 2*above*below/n + 1
+	variance := (expected - 1) * (expected - 2) / (n - 1)
+	if variance <= 0 {
+		return 0, false
+	}
+	z := (runs - expected) / math.Sqrt(variance)
+
+	return z, math.Abs(z) < independenceZ
+}
+
 // HealthResult contains RNG health check results
 type HealthResult struct {
 	Healthy          bool      `json:"healthy"`
@@ -228,6 +490,14 @@ type HealthResult struct {
 	SamplesGenerated int64     `json:"samples_generated"`
 	ChiSquare        float64   `json:"chi_square"`
 	ChiSquarePassed  bool      `json:"chi_square_passed"`
-	Error            string    `json:"error,omitempty"`
+
+	SerialCorrelation       float64 `json:"serial_correlation"` // Lag-1, of the check's samples
+	SerialCorrelationPassed bool    `json:"serial_correlation_passed"`
+	RunsZ                   float64 `json:"runs_z"` // z-score of runs above and below the midpoint
+	RunsTestPassed          bool    `json:"runs_test_passed"`
+
+	FailedDraws int64     `json:"failed_draws"` // Entropy reads that failed since startup
+	LastReseed  time.Time `json:"last_reseed"`
+	Error       string    `json:"error,omitempty"`
 }
 
@@ -1,8 +1,13 @@
 package rng
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
 	"math"
 	"testing"
+	"time"
 )
 
 func TestGenerateBytes(t *testing.T) {
@@ -43,6 +48,94 @@ func TestGenerateBytes(t *testing.T) {
 	})
 }
 
+// failingReader is an entropy source that always fails
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) {
+	return 0, errors.New("entropy unavailable")
+}
+
+func TestGenerateBytesInto(t *testing.T) {
+	s := New()
+
+	t.Run("FillsBuffer", func(t *testing.T) {
+		for _, size := range []int{0, 1, 8, 64, 256} {
+			buf := make([]byte, size)
+			if err := s.GenerateBytesInto(buf); err != nil {
+				t.Fatalf("Failed to fill %d bytes: %v", size, err)
+			}
+			if len(buf) != size {
+				t.Errorf("Expected buffer to stay %d bytes, got %d", size, len(buf))
+			}
+		}
+
+		// 32 zero bytes after a fill is vanishingly unlikely
+		buf := make([]byte, 32)
+		if err := s.GenerateBytesInto(buf); err != nil {
+			t.Fatalf("Failed to fill buffer: %v", err)
+		}
+		if bytes.Equal(buf, make([]byte, 32)) {
+			t.Error("Expected buffer to be filled with random bytes")
+		}
+	})
+
+	t.Run("ReportsEntropyFailure", func(t *testing.T) {
+		broken := &Service{entropy: failingReader{}}
+		if err := broken.GenerateBytesInto(make([]byte, 8)); err == nil {
+			t.Error("Expected error when entropy fails")
+		}
+		if _, err := broken.GenerateBytes(8); err == nil {
+			t.Error("Expected GenerateBytes to report entropy failure")
+		}
+	})
+}
+
+func TestGenerateUint64(t *testing.T) {
+	t.Run("GeneratesUniqueValues", func(t *testing.T) {
+		s := New()
+		seen := make(map[uint64]bool)
+		for i := 0; i < 1000; i++ {
+			n, err := s.GenerateUint64()
+			if err != nil {
+				t.Fatalf("Failed to generate uint64: %v", err)
+			}
+			if seen[n] {
+				t.Error("Duplicate 64-bit value generated - extremely unlikely, possible RNG issue")
+			}
+			seen[n] = true
+		}
+	})
+
+	t.Run("ReportsEntropyFailure", func(t *testing.T) {
+		broken := &Service{entropy: failingReader{}}
+		if _, err := broken.GenerateUint64(); err == nil {
+			t.Error("Expected error when entropy fails")
+		}
+	})
+
+	t.Run("HighAndLowBytesUniform", func(t *testing.T) {
+		// A seeded stream keeps the check deterministic
+		s := NewSeeded([]byte("uint64"))
+		high := make([]int64, 25600)
+		low := make([]int64, 25600)
+		for i := range high {
+			n, err := s.GenerateUint64()
+			if err != nil {
+				t.Fatalf("Failed to generate uint64: %v", err)
+			}
+			high[i] = int64(n >> 56)
+			low[i] = int64(n & 0xff)
+		}
+
+		if chiSquare, passed := s.chiSquareTest(high, 256); !passed {
+			t.Errorf("High byte failed chi-square test: %.2f", chiSquare)
+		}
+		if chiSquare, passed := s.chiSquareTest(low, 256); !passed {
+			t.Errorf("Low byte failed chi-square test: %.2f", chiSquare)
+		}
+	})
+}
+
 func TestGenerateInt(t *testing.T) {
 	s := New()
 
@@ -315,6 +408,226 @@ func TestHealthCheck(t *testing.T) {
 	if result.ChiSquare < 20 || result.ChiSquare > 200 {
 		t.Logf("Warning: Chi-square value %f is unusual (expected 50-150 range)", result.ChiSquare)
 	}
+
+	if !result.SerialCorrelationPassed {
+		t.Errorf("Serial correlation test failed with value %f", result.SerialCorrelation)
+	}
+	if result.SerialCorrelation == 0 {
+		t.Error("Expected serial correlation to be computed")
+	}
+	if !result.RunsTestPassed {
+		t.Errorf("Runs test failed with z-score %f", result.RunsZ)
+	}
+}
+
+func TestIndependenceTests(t *testing.T) {
+	t.Run("PassForIndependentData", func(t *testing.T) {
+		s := NewSeeded([]byte("independence"))
+		samples := make([]int64, 1000)
+		for i := range samples {
+			samples[i], _ = s.GenerateInt(100)
+		}
+		if r, passed := serialCorrelationTest(samples); !passed {
+			t.Errorf("Serial correlation test failed for independent data: %f", r)
+		}
+		if z, passed := runsTest(samples, 100); !passed {
+			t.Errorf("Runs test failed for independent data: %f", z)
+		}
+	})
+
+	t.Run("FailForTrendingData", func(t *testing.T) {
+		// A slowly rising sequence is uniform overall but strongly correlated
+		samples := make([]int64, 1000)
+		for i := range samples {
+			samples[i] = int64(i / 10)
+		}
+		if r, passed := serialCorrelationTest(samples); passed {
+			t.Errorf("Serial correlation test should fail for trending data: %f", r)
+		}
+		if z, passed := runsTest(samples, 100); passed {
+			t.Errorf("Runs test should fail with only two runs: %f", z)
+		}
+	})
+
+	t.Run("FailForAlternatingData", func(t *testing.T) {
+		samples := make([]int64, 1000)
+		for i := range samples {
+			samples[i] = int64(i%2) * 99
+		}
+		if r, passed := serialCorrelationTest(samples); passed {
+			t.Errorf("Serial correlation test should fail for alternating data: %f", r)
+		}
+		if z, passed := runsTest(samples, 100); passed {
+			t.Errorf("Runs test should fail with a run per sample: %f", z)
+		}
+	})
+
+	t.Run("FailForConstantData", func(t *testing.T) {
+		samples := make([]int64, 1000)
+		if _, passed := serialCorrelationTest(samples); passed {
+			t.Error("Serial correlation test should fail for constant data")
+		}
+		if _, passed := runsTest(samples, 100); passed {
+			t.Error("Runs test should fail for constant data")
+		}
+	})
+}
+
+// switchableReader reads from crypto/rand until told to fail
+type switchableReader struct {
+	fail bool
+}
+
+func (r *switchableReader) Read(p []byte) (int, error) {
+	if r.fail {
+		return 0, errors.New("entropy unavailable")
+	}
+	return New().entropy.Read(p)
+}
+
+func TestHealthCheckEntropyFailure(t *testing.T) {
+	t.Run("FailingSourceUnhealthy", func(t *testing.T) {
+		s := newWithSource(func() io.Reader { return failingReader{} })
+
+		result, err := s.HealthCheck()
+		if err == nil {
+			t.Error("Expected health check error with a failing entropy source")
+		}
+		if result.Healthy {
+			t.Error("Expected RNG to be reported unhealthy")
+		}
+		if result.FailedDraws == 0 {
+			t.Error("Expected failed draws to be counted")
+		}
+	})
+
+	t.Run("EarlierFailuresReported", func(t *testing.T) {
+		reader := &switchableReader{}
+		s := newWithSource(func() io.Reader { return reader })
+
+		reader.fail = true
+		if _, err := s.GenerateInt(10); err == nil {
+			t.Fatal("Expected draw to fail")
+		}
+		reader.fail = false
+
+		// The check's own samples succeed, but the earlier failure is flagged
+		result, err := s.HealthCheck()
+		if err != nil {
+			t.Fatalf("Health check error: %v", err)
+		}
+		if result.Healthy || result.FailedDraws != 1 {
+			t.Errorf("Expected unhealthy with 1 failed draw, got healthy=%v failed=%d", result.Healthy, result.FailedDraws)
+		}
+
+		// Failures are reported once; the next check starts afresh
+		if result, _ := s.HealthCheck(); !result.Healthy && result.ChiSquarePassed {
+			t.Errorf("Expected healthy once no further draws fail: %s", result.Error)
+		}
+	})
+}
+
+func TestReseed(t *testing.T) {
+	t.Run("ReplacesFailingReader", func(t *testing.T) {
+		reader := &switchableReader{fail: true}
+		s := newWithSource(func() io.Reader { return reader })
+		before := s.lastReseed
+
+		if err := s.Reseed(); err == nil {
+			t.Error("Expected reseed to fail while the source fails")
+		}
+		if s.failedDraws != 1 {
+			t.Errorf("Expected failed reseed to be counted, got %d", s.failedDraws)
+		}
+
+		reader.fail = false
+		if err := s.Reseed(); err != nil {
+			t.Fatalf("Failed to reseed: %v", err)
+		}
+		if !s.lastReseed.After(before) {
+			t.Error("Expected last reseed time to advance")
+		}
+		if _, err := s.GenerateInt(10); err != nil {
+			t.Errorf("Expected draws to work after reseed, got %v", err)
+		}
+	})
+
+	t.Run("SeededNotReseedable", func(t *testing.T) {
+		if err := NewSeeded([]byte("replay")).Reseed(); err != ErrNotReseedable {
+			t.Errorf("Expected ErrNotReseedable, got %v", err)
+		}
+	})
+}
+
+// waitForHealth polls until the service has a health result
+func waitForHealth(t *testing.T, s *Service) *HealthResult {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if result := s.LastHealth(); result != nil {
+			return result
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatal("Monitor did not run a health check")
+	return nil
+}
+
+func TestMonitor(t *testing.T) {
+	t.Run("CachesResult", func(t *testing.T) {
+		s := New()
+		if s.LastHealth() != nil {
+			t.Fatal("Expected no health result before the first check")
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		go s.RunMonitor(ctx, 10*time.Millisecond)
+		result := waitForHealth(t, s)
+		cancel()
+
+		if result.SamplesGenerated == 0 || result.ChiSquare == 0 {
+			t.Errorf("Expected a completed self-test, got %+v", result)
+		}
+
+		// Reading the cached result does not draw any samples
+		time.Sleep(20 * time.Millisecond)
+		s.mu.Lock()
+		samples := s.samplesGenerated
+		s.mu.Unlock()
+		cached := s.Health()
+		s.mu.Lock()
+		after := s.samplesGenerated
+		s.mu.Unlock()
+		if after != samples {
+			t.Error("Expected Health to return the cached result without re-testing")
+		}
+		if cached.Timestamp.IsZero() {
+			t.Error("Expected cached result to carry its timestamp")
+		}
+	})
+
+	t.Run("FlagsFailingSource", func(t *testing.T) {
+		reader := &switchableReader{fail: true}
+		s := newWithSource(func() io.Reader { return reader })
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go s.RunMonitor(ctx, 10*time.Millisecond)
+
+		if result := waitForHealth(t, s); result.Healthy {
+			t.Error("Expected monitor to flag the failing source as unhealthy")
+		}
+		if s.Health().Healthy {
+			t.Error("Expected Health to report the cached unhealthy result")
+		}
+	})
+
+	t.Run("HealthRunsFirstCheck", func(t *testing.T) {
+		s := New()
+		if result := s.Health(); result == nil || result.SamplesGenerated == 0 {
+			t.Errorf("Expected Health to run a check when none has run, got %+v", result)
+		}
+	})
 }
 
 func TestChiSquareTest(t *testing.T) {
@@ -451,3 +764,106 @@ func TestStatisticalQuality(t *testing.T) {
 	})
 }
 
+
+func TestNewSeeded(t *testing.T) {
+	t.Run("SameSeedSameStream", func(t *testing.T) {
+		a := NewSeeded([]byte("certification-seed"))
+		b := NewSeeded([]byte("certification-seed"))
+
+		for i := 0; i < 1000; i++ {
+			x, err := a.GenerateInt(1000)
+			if err != nil {
+				t.Fatalf("Failed to generate int: %v", err)
+			}
+			y, err := b.GenerateInt(1000)
+			if err != nil {
+				t.Fatalf("Failed to generate int: %v", err)
+			}
+			if x != y {
+				t.Fatalf("Expected identical streams, diverged at sample %d: %d vs %d", i, x, y)
+			}
+		}
+	})
+
+	t.Run("DifferentSeedsDiffer", func(t *testing.T) {
+		a := NewSeeded([]byte("seed-a"))
+		b := NewSeeded([]byte("seed-b"))
+
+		bytesA, _ := a.GenerateBytes(32)
+		bytesB, _ := b.GenerateBytes(32)
+		if string(bytesA) == string(bytesB) {
+			t.Error("Expected different seeds to produce different streams")
+		}
+	})
+
+	t.Run("ShuffleIsDeterministic", func(t *testing.T) {
+		a := []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}
+		b := []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}
+
+		if err := NewSeeded([]byte("deck")).Shuffle(a); err != nil {
+			t.Fatalf("Failed to shuffle: %v", err)
+		}
+		if err := NewSeeded([]byte("deck")).Shuffle(b); err != nil {
+			t.Fatalf("Failed to shuffle: %v", err)
+		}
+		for i := range a {
+			if a[i] != b[i] {
+				t.Fatalf("Expected identical shuffles, got %v and %v", a, b)
+			}
+		}
+	})
+
+	t.Run("PassesChiSquare", func(t *testing.T) {
+		s := NewSeeded([]byte("uniformity"))
+		samples := make([]int64, 10000)
+		for i := range samples {
+			samples[i], _ = s.GenerateInt(100)
+		}
+		if chiSquare, passed := s.chiSquareTest(samples, 100); !passed {
+			t.Errorf("Seeded stream failed chi-square test: %.2f", chiSquare)
+		}
+	})
+}
+
+func TestRecorder(t *testing.T) {
+	s := New()
+
+	t.Run("RecordsDrawsInOrder", func(t *testing.T) {
+		rec := NewRecorder()
+		ctx := WithRecorder(context.Background(), rec)
+
+		var values []int64
+		for _, max := range []int64{10, 20, 30} {
+			n, err := s.GenerateIntContext(ctx, max)
+			if err != nil {
+				t.Fatalf("Failed to generate: %v", err)
+			}
+			values = append(values, n)
+		}
+		if _, err := s.GenerateFloatContext(ctx); err != nil {
+			t.Fatalf("Failed to generate float: %v", err)
+		}
+
+		draws := rec.Draws()
+		if len(draws) != 4 {
+			t.Fatalf("Expected 4 draws, got %d", len(draws))
+		}
+		for i, max := range []int64{10, 20, 30} {
+			if draws[i].Max != max || draws[i].Value != values[i] {
+				t.Errorf("Draw %d: expected {%d %d}, got %+v", i, max, values[i], draws[i])
+			}
+		}
+		if draws[3].Max != 1<<53 {
+			t.Errorf("Expected float draw over 2^53, got %d", draws[3].Max)
+		}
+	})
+
+	t.Run("NoRecorder", func(t *testing.T) {
+		if RecorderFromContext(context.Background()) != nil {
+			t.Error("Expected no recorder on a plain context")
+		}
+		if _, err := s.GenerateIntContext(context.Background(), 10); err != nil {
+			t.Errorf("Expected draw without a recorder, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,74 @@
+// Package rng - Recording of draws for certification
+package rng
+
+import (
+	"context"
+	"sync"
+)
+
+// Draw is one call to the RNG: the range requested and the value returned
+// GLI-19 §3.2.3: scaling of RNG output must be traceable
+type Draw struct {
+	Max   int64 `json:"max"`   // Values were drawn from [0, Max)
+	Value int64 `json:"value"` // Value returned
+}
+
+// Recorder collects the draws made through a context it is attached to
+type Recorder struct {
+	mu    sync.Mutex
+	draws []Draw
+}
+
+// NewRecorder creates an empty draw recorder
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+// Draws returns the draws recorded so far, in the order they were made
+func (r *Recorder) Draws() []Draw {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Draw{}, r.draws...)
+}
+
+func (r *Recorder) record(max, value int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.draws = append(r.draws, Draw{Max: max, Value: value})
+}
+
+type recorderKey struct{}
+
+// WithRecorder returns a context whose draws are recorded to rec
+func WithRecorder(ctx context.Context, rec *Recorder) context.Context {
+	return context.WithValue(ctx, recorderKey{}, rec)
+}
+
+// RecorderFromContext returns the recorder attached to ctx, or nil
+func RecorderFromContext(ctx context.Context) *Recorder {
+	rec, _ := ctx.Value(recorderKey{}).(*Recorder)
+	return rec
+}
+
+// GenerateIntContext is GenerateInt, recording the draw to the recorder
+// attached to ctx, if any
+func (s *Service) GenerateIntContext(ctx context.Context, max int64) (int64, error) {
+	n, err := s.GenerateInt(max)
+	if err != nil {
+		return 0, err
+	}
+	if rec := RecorderFromContext(ctx); rec != nil {
+		rec.record(max, n)
+	}
+	return n, nil
+}
+
+// GenerateFloatContext is GenerateFloat, recording the underlying integer
+// draw to the recorder attached to ctx, if any
+func (s *Service) GenerateFloatContext(ctx context.Context) (float64, error) {
+	n, err := s.GenerateIntContext(ctx, 1<<53)
+	if err != nil {
+		return 0, err
+	}
+	return float64(n) / float64(1<<53), nil
+}
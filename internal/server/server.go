@@ -0,0 +1,80 @@
+// Package server runs the RGS HTTP server and shuts it down gracefully, so a
+// deploy never interrupts a game cycle half way through
+// GLI-19 §4.16: Interrupted Games
+package server
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+
+	"github.com/alexbotov/rgs/internal/api"
+	"github.com/alexbotov/rgs/internal/audit"
+	"github.com/alexbotov/rgs/internal/config"
+	"github.com/alexbotov/rgs/internal/domain"
+	"github.com/alexbotov/rgs/internal/game"
+)
+
+// Server serves the API and coordinates shutdown with the game engine
+type Server struct {
+	http    *http.Server
+	handler *api.Handler
+	engine  *game.Engine
+	audit   *audit.Service
+}
+
+// New creates a server for the handler's routes
+func New(cfg *config.ServerConfig, handler *api.Handler, engine *game.Engine, auditSvc *audit.Service) *Server {
+	return &Server{
+		http: &http.Server{
+			Addr:         ":" + cfg.Port,
+			Handler:      handler.SetupRouter(),
+			ReadTimeout:  cfg.ReadTimeout,
+			WriteTimeout: cfg.WriteTimeout,
+		},
+		handler: handler,
+		engine:  engine,
+		audit:   auditSvc,
+	}
+}
+
+// ListenAndServe serves on the configured port until Shutdown
+func (s *Server) ListenAndServe() error {
+	return ignoreClosed(s.http.ListenAndServe())
+}
+
+// Serve serves on an existing listener until Shutdown
+func (s *Server) Serve(l net.Listener) error {
+	return ignoreClosed(s.http.Serve(l))
+}
+
+// Shutdown stops the server in order: new game cycles are refused and the
+// ones in progress are allowed to complete, WebSocket clients are told the
+// server is going away, then in-flight HTTP requests are drained. Work still
+// running when ctx expires is abandoned and left to interrupted game recovery.
+func (s *Server) Shutdown(ctx context.Context) error {
+	drainErr := s.engine.Drain(ctx)
+	if drainErr != nil {
+		log.Printf("Game cycles still in progress at shutdown: %v", drainErr)
+		s.audit.Log(context.Background(), audit.EventSystemError, domain.SeverityCritical,
+			"Shutdown timed out waiting for game cycles",
+			map[string]string{"error": drainErr.Error()},
+			audit.WithComponent("server"))
+	}
+
+	s.handler.CloseWebSockets()
+
+	if err := s.http.Shutdown(ctx); err != nil {
+		return err
+	}
+	return drainErr
+}
+
+func ignoreClosed(err error) error {
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
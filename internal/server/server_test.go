@@ -0,0 +1,56 @@
+package server
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/alexbotov/rgs/internal/api"
+	"github.com/alexbotov/rgs/internal/config"
+	"github.com/alexbotov/rgs/internal/game"
+	"github.com/alexbotov/rgs/internal/rng"
+)
+
+func TestShutdown(t *testing.T) {
+	engine := game.New(nil, rng.New(), nil, nil, nil, "USD")
+	handler := api.New(nil, nil, engine, nil)
+	srv := New(&config.ServerConfig{ReadTimeout: time.Second, WriteTimeout: time.Second}, handler, engine, nil)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	served := make(chan error, 1)
+	go func() { served <- srv.Serve(listener) }()
+
+	resp, err := http.Get("http://" + listener.Addr().String() + "/")
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", resp.StatusCode)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+
+	select {
+	case err := <-served:
+		if err != nil {
+			t.Errorf("Expected Serve to return nil after shutdown, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Serve did not return after shutdown")
+	}
+
+	// The engine refuses new game cycles once drained
+	if _, err := engine.Play(context.Background(), &game.PlayRequest{SessionID: "any", WagerAmount: 100}); err != game.ErrShuttingDown {
+		t.Errorf("Expected ErrShuttingDown, got %v", err)
+	}
+}
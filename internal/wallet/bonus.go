@@ -0,0 +1,222 @@
+// Package wallet - Bonus balance wagering and conversion
+// Compliant with GLI-19 §2.5.6: Financial Transactions, §2.5.7: Transaction Log
+package wallet
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/alexbotov/rgs/internal/audit"
+	"github.com/alexbotov/rgs/internal/domain"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrNoBonus             = errors.New("no bonus balance")
+	ErrWageringIncomplete  = errors.New("bonus wagering requirement not met")
+	ErrInvalidWageringTerm = errors.New("invalid wagering requirement")
+)
+
+// BonusPolicy decides which balance a wager is taken from first
+type BonusPolicy string
+
+const (
+	BonusPolicyBonusFirst BonusPolicy = "bonus_first" // Spend bonus funds before real money
+	BonusPolicyRealFirst  BonusPolicy = "real_first"  // Spend real money before bonus funds
+)
+
+// SetBonusPolicy sets the order in which game wagers spend balances
+func (s *Service) SetBonusPolicy(policy BonusPolicy) {
+	s.bonusPolicy = policy
+}
+
+// GrantBonus credits bonus funds to a player. The bonus converts to real money
+// once the player has wagered multiplier times the bonus amount.
+func (s *Service) GrantBonus(ctx context.Context, playerID string, amount domain.Money, multiplier int, reference string) (*domain.Transaction, error) {
+	if amount.Amount <= 0 {
+		return nil, ErrInvalidAmount
+	}
+	if multiplier < 0 {
+		return nil, ErrInvalidWageringTerm
+	}
+
+	dbTx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer dbTx.Rollback()
+
+	balance, err := lockBalance(ctx, dbTx, playerID)
+	if err != nil {
+		return nil, err
+	}
+	if amount.Currency != balance.Currency {
+		return nil, ErrCurrencyMismatch
+	}
+
+	now := time.Now().UTC()
+	newBonus, err := balance.BonusBalance.AddChecked(amount)
+	if err != nil {
+		return nil, err
+	}
+	// A requirement left over from a bonus already spent does not carry over
+	wagering := amount.Amount * int64(multiplier)
+	if balance.BonusBalance.Amount > 0 {
+		wagering += balance.WageringRemaining.Amount
+	}
+
+	tx := &domain.Transaction{
+		ID:            uuid.New().String(),
+		PlayerID:      playerID,
+		Type:          domain.TxTypeBonus,
+		Amount:        amount,
+		BalanceBefore: balance.BonusBalance,
+		BalanceAfter:  newBonus,
+		Status:        domain.TxStatusCompleted,
+		Reference:     reference,
+		Description:   fmt.Sprintf("Bonus granted (wager %dx)", multiplier),
+		CreatedAt:     now,
+		CompletedAt:   &now,
+	}
+
+	_, err = dbTx.ExecContext(ctx, `
+		UPDATE balances SET bonus_amount = $1, wagering_remaining = $2, updated_at = $3 WHERE player_id = $4
+	`, newBonus.Amount, wagering, now, playerID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := insertTransaction(ctx, dbTx, tx); err != nil {
+		return nil, err
+	}
+
+	if err := dbTx.Commit(); err != nil {
+		return nil, err
+	}
+	s.publishBalance(playerID, balance.RealMoney.Amount+newBonus.Amount, amount.Currency, tx.Type)
+
+	s.audit.Log(ctx, "bonus_granted", domain.SeverityInfo,
+		fmt.Sprintf("Bonus of %.2f %s granted", amount.Float64(), amount.Currency),
+		map[string]interface{}{
+			"transaction_id":     tx.ID,
+			"amount":             amount.Float64(),
+			"multiplier":         multiplier,
+			"wagering_remaining": wagering,
+		},
+		audit.WithPlayer(playerID))
+
+	return tx, nil
+}
+
+// WageringMet reports whether a wager that took the balance from before to
+// after met the bonus wagering requirement, leaving a bonus to convert
+func WageringMet(before, after *domain.Balance) bool {
+	return before.WageringRemaining.Amount > 0 && after.WageringRemaining.Amount == 0 && after.BonusBalance.Amount > 0
+}
+
+// ConvertBonus moves the player's bonus balance to real money once the
+// wagering requirement has been met
+func (s *Service) ConvertBonus(ctx context.Context, playerID string) (*domain.Transaction, error) {
+	dbTx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer dbTx.Rollback()
+
+	balance, err := lockBalance(ctx, dbTx, playerID)
+	if err != nil {
+		return nil, err
+	}
+	if balance.BonusBalance.Amount <= 0 {
+		return nil, ErrNoBonus
+	}
+	if balance.WageringRemaining.Amount > 0 {
+		return nil, ErrWageringIncomplete
+	}
+
+	now := time.Now().UTC()
+	amount := domain.Money{Amount: balance.BonusBalance.Amount, Currency: balance.RealMoney.Currency}
+	newReal, err := balance.RealMoney.AddChecked(amount)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := &domain.Transaction{
+		ID:            uuid.New().String(),
+		PlayerID:      playerID,
+		Type:          domain.TxTypeBonus,
+		Amount:        amount,
+		BalanceBefore: balance.RealMoney,
+		BalanceAfter:  newReal,
+		Status:        domain.TxStatusCompleted,
+		Description:   "Bonus converted to real money",
+		CreatedAt:     now,
+		CompletedAt:   &now,
+	}
+
+	_, err = dbTx.ExecContext(ctx, `
+		UPDATE balances SET real_money_amount = $1, bonus_amount = 0, wagering_remaining = 0, updated_at = $2 WHERE player_id = $3
+	`, newReal.Amount, now, playerID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := insertTransaction(ctx, dbTx, tx); err != nil {
+		return nil, err
+	}
+
+	if err := dbTx.Commit(); err != nil {
+		return nil, err
+	}
+	s.publishBalance(playerID, newReal.Amount, amount.Currency, tx.Type)
+
+	s.audit.Log(ctx, "bonus_converted", domain.SeverityInfo,
+		fmt.Sprintf("Bonus of %.2f %s converted to real money", amount.Float64(), amount.Currency),
+		map[string]interface{}{
+			"transaction_id": tx.ID,
+			"amount":         amount.Float64(),
+		},
+		audit.WithPlayer(playerID))
+
+	return tx, nil
+}
+
+// cycleBonusShare returns the part of amount that belongs to the bonus
+// balance, in the proportion the cycle's transactions of txType drew on it
+func cycleBonusShare(ctx context.Context, dbTx *sql.Tx, playerID, cycleID string, txType domain.TransactionType, amount int64) (int64, error) {
+	if cycleID == "" {
+		return 0, nil
+	}
+
+	var total, bonus int64
+	err := dbTx.QueryRowContext(ctx, `
+		SELECT COALESCE(SUM(amount), 0), COALESCE(SUM(bonus_amount), 0) FROM transactions
+		WHERE player_id = $1 AND type = $2 AND reference = $3 AND status = 'completed'
+	`, playerID, txType, cycleID).Scan(&total, &bonus)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get bonus share: %w", err)
+	}
+	if total <= 0 || bonus <= 0 {
+		return 0, nil
+	}
+	if bonus >= total {
+		return amount, nil
+	}
+	share := new(big.Int).Mul(big.NewInt(amount), big.NewInt(bonus))
+	return share.Quo(share, big.NewInt(total)).Int64(), nil
+}
+
+// splitWager returns the bonus and real money parts of a wager under the
+// policy. The caller has checked the available balance covers the wager.
+func splitWager(policy BonusPolicy, balance *domain.Balance, amount int64) (bonusPart, realPart int64) {
+	if policy == BonusPolicyRealFirst {
+		realPart = min(balance.RealMoney.Amount, amount)
+		return amount - realPart, realPart
+	}
+	bonusPart = min(balance.BonusBalance.Amount, amount)
+	return bonusPart, amount - bonusPart
+}
@@ -7,10 +7,14 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/alexbotov/rgs/internal/audit"
+	"github.com/alexbotov/rgs/internal/database"
 	"github.com/alexbotov/rgs/internal/domain"
+	"github.com/alexbotov/rgs/internal/events"
+	"github.com/alexbotov/rgs/internal/limits"
 	"github.com/google/uuid"
 )
 
@@ -18,34 +22,233 @@ var (
 	ErrInsufficientFunds = errors.New("insufficient funds")
 	ErrInvalidAmount     = errors.New("invalid amount")
 	ErrPlayerNotFound    = errors.New("player not found")
+
+	ErrDuplicateTransaction = errors.New("idempotency key reused for a different transaction")
+	ErrDuplicateReference   = errors.New("reference reused for a different transaction")
+	ErrTransactionNotFound  = errors.New("transaction not found")
+	ErrCurrencyMismatch     = domain.ErrCurrencyMismatch
+
+	ErrDepositBelowMinimum = errors.New("deposit below minimum amount")
+	ErrDepositAboveMaximum = errors.New("deposit above maximum amount")
+
+	ErrDepositLimitExceeded = limits.ErrDepositLimitExceeded
+	ErrPlayerExcluded       = limits.ErrPlayerExcluded
 )
 
 // Service provides wallet functionality
 type Service struct {
-	db       *sql.DB
-	audit    *audit.Service
-	currency string
+	db          *sql.DB
+	audit       *audit.Service
+	currency    string
+	bonusPolicy BonusPolicy
+	events      *events.Hub
+
+	// limits enforces deposit limits and self-exclusion on deposits; optional
+	limits *limits.Service
+
+	// depositBounds are the deposit amounts accepted per currency; a currency
+	// not listed is unbounded
+	depositBounds map[string]DepositBounds
+
+	// largeTransaction is the deposit or withdrawal amount, in minor units,
+	// audited as a large transaction; zero disables
+	largeTransaction int64
+}
+
+// DepositBounds are the smallest and largest single deposit accepted in a
+// currency, in minor units. Zero leaves that side unbounded.
+type DepositBounds struct {
+	Min int64
+	Max int64
+}
+
+// Wallet is the wallet a game engine plays against. *Service implements it
+// on the database; other implementations let the engine settle elsewhere or
+// run against an in-memory fake in tests.
+type Wallet interface {
+	GetBalance(ctx context.Context, playerID string) (*domain.Balance, error)
+	PlaceWager(ctx context.Context, playerID string, amount domain.Money, gameID, cycleID string) (*domain.Transaction, error)
+	CreditWin(ctx context.Context, playerID string, amount domain.Money, gameID, cycleID string) (*domain.Transaction, error)
+	RefundWager(ctx context.Context, playerID string, amount domain.Money, gameID, cycleID string) (*domain.Transaction, error)
+
+	// Variants run inside the caller's database transaction, so the money a
+	// game cycle moves commits together with the cycle
+	GetBalanceTx(ctx context.Context, dbTx *sql.Tx, playerID string) (*domain.Balance, error)
+	PlaceWagerTx(ctx context.Context, dbTx *sql.Tx, playerID string, amount domain.Money, gameID, cycleID string) (*domain.Transaction, error)
+	CreditWinTx(ctx context.Context, dbTx *sql.Tx, playerID string, amount domain.Money, gameID, cycleID string) (*domain.Transaction, error)
+	RefundWagerTx(ctx context.Context, dbTx *sql.Tx, playerID string, amount domain.Money, gameID, cycleID string) (*domain.Transaction, error)
+	GambleTx(ctx context.Context, dbTx *sql.Tx, playerID string, stake domain.Money, won bool, gameID, cycleID string, step int) (*domain.Transaction, error)
+	CreditJackpotTx(ctx context.Context, dbTx *sql.Tx, playerID string, amount domain.Money, gameID, cycleID string) (*domain.Transaction, error)
+	RecordJackpotContributionTx(ctx context.Context, dbTx *sql.Tx, playerID string, amount domain.Money, gameID, cycleID string) (*domain.Transaction, error)
+
+	// PublishBalance notifies the player once a transaction has committed
+	PublishBalance(balance *domain.Balance, txType domain.TransactionType)
+
+	// ConvertBonus moves the bonus to real money once its wagering
+	// requirement is met; the engine calls it after a cycle that met it
+	ConvertBonus(ctx context.Context, playerID string) (*domain.Transaction, error)
 }
 
 // New creates a new wallet service
 func New(db *sql.DB, auditSvc *audit.Service, currency string) *Service {
 	return &Service{
-		db:       db,
-		audit:    auditSvc,
-		currency: currency,
+		db:          db,
+		audit:       auditSvc,
+		currency:    currency,
+		bonusPolicy: BonusPolicyBonusFirst,
+	}
+}
+
+// SetEvents publishes a balance update to the hub after every transaction
+func (s *Service) SetEvents(hub *events.Hub) {
+	s.events = hub
+}
+
+// SetLimits makes Deposit refuse deposits from self-excluded players and
+// deposits over the player's deposit limits (GLI-19 §2.5.5)
+func (s *Service) SetLimits(limitsSvc *limits.Service) {
+	s.limits = limitsSvc
+}
+
+// SetDepositBounds sets the smallest and largest single deposit Deposit
+// accepts in each currency, so a mistyped amount is refused rather than
+// credited
+func (s *Service) SetDepositBounds(bounds map[string]DepositBounds) {
+	s.depositBounds = bounds
+}
+
+// ParseDepositBounds builds deposit bounds from minimum and maximum amounts
+// per currency in the major unit (e.g. "USD" -> "10.00")
+func ParseDepositBounds(min, max map[string]string) (map[string]DepositBounds, error) {
+	bounds := make(map[string]DepositBounds)
+	for currency, value := range min {
+		amount, err := domain.ParseMoney(value, currency)
+		if err != nil {
+			return nil, fmt.Errorf("minimum deposit in %s: %w", currency, err)
+		}
+		b := bounds[currency]
+		b.Min = amount.Amount
+		bounds[currency] = b
+	}
+	for currency, value := range max {
+		amount, err := domain.ParseMoney(value, currency)
+		if err != nil {
+			return nil, fmt.Errorf("maximum deposit in %s: %w", currency, err)
+		}
+		b := bounds[currency]
+		b.Max = amount.Amount
+		bounds[currency] = b
+	}
+	for currency, b := range bounds {
+		if b.Max > 0 && b.Min > b.Max {
+			return nil, fmt.Errorf("minimum deposit in %s is above the maximum", currency)
+		}
 	}
+	return bounds, nil
+}
+
+// SetLargeTransactionThreshold sets the deposit or withdrawal amount, in minor
+// units, at or above which the transaction is audited as a large transaction
+// for anti-money-laundering review; zero disables
+func (s *Service) SetLargeTransactionThreshold(amount int64) {
+	s.largeTransaction = amount
+}
+
+// auditLargeTransaction records a committed deposit or withdrawal at or above
+// the large transaction threshold as a warning, on top of its routine audit
+// event (GLI-19 §2.8.8)
+func (s *Service) auditLargeTransaction(ctx context.Context, tx *domain.Transaction) {
+	if s.largeTransaction <= 0 || tx.Amount.Amount < s.largeTransaction {
+		return
+	}
+	s.audit.Log(ctx, audit.EventLargeTransaction, domain.SeverityWarning,
+		fmt.Sprintf("Large %s of %s", tx.Type, tx.Amount),
+		map[string]interface{}{
+			"transaction_id": tx.ID,
+			"type":           tx.Type,
+			"amount":         tx.Amount.Float64(),
+			"currency":       tx.Amount.Currency,
+			"threshold":      s.largeTransaction,
+		},
+		audit.WithPlayer(tx.PlayerID), audit.WithComponent("wallet"))
+}
+
+// checkDepositBounds refuses a deposit outside the bounds of its currency. An
+// attempt over the maximum is audited, as it is more likely a mistake or
+// abuse than a genuine deposit.
+func (s *Service) checkDepositBounds(ctx context.Context, playerID string, amount domain.Money) error {
+	b, ok := s.depositBounds[amount.Currency]
+	if !ok {
+		return nil
+	}
+	if b.Min > 0 && amount.Amount < b.Min {
+		return ErrDepositBelowMinimum
+	}
+	if b.Max > 0 && amount.Amount > b.Max {
+		maximum := domain.Money{Amount: b.Max, Currency: amount.Currency}
+		s.audit.Log(ctx, "deposit_above_maximum", domain.SeverityWarning,
+			fmt.Sprintf("Deposit of %s refused above the maximum of %s", amount, maximum),
+			map[string]interface{}{
+				"amount":   amount.Amount,
+				"maximum":  b.Max,
+				"currency": amount.Currency,
+			},
+			audit.WithPlayer(playerID), audit.WithComponent("wallet"))
+		return ErrDepositAboveMaximum
+	}
+	return nil
+}
+
+// PublishBalance notifies the player's subscribers of their balance once a
+// transaction run with PlaceWagerTx or CreditWinTx has committed
+func (s *Service) PublishBalance(balance *domain.Balance, txType domain.TransactionType) {
+	s.publishBalance(balance.PlayerID, balance.Available.Amount, balance.Currency, txType)
+}
+
+// publishBalance notifies the player's subscribers of their available balance
+// after a committed transaction
+func (s *Service) publishBalance(playerID string, available int64, currency string, txType domain.TransactionType) {
+	s.events.Publish(events.Event{
+		Type:     events.BalanceUpdate,
+		PlayerID: playerID,
+		Balance:  domain.Money{Amount: available, Currency: currency},
+		Data:     map[string]interface{}{"transaction_type": txType},
+	})
 }
 
 // GetBalance retrieves the current balance for a player (GLI-19 §2.5.7)
 func (s *Service) GetBalance(ctx context.Context, playerID string) (*domain.Balance, error) {
-	var realAmount, bonusAmount int64
+	return scanBalance(ctx, s.db, playerID, "")
+}
+
+// GetBalanceTx reads a player's balance inside the caller's database
+// transaction and locks the row until it commits or rolls back
+func (s *Service) GetBalanceTx(ctx context.Context, dbTx *sql.Tx, playerID string) (*domain.Balance, error) {
+	return lockBalance(ctx, dbTx, playerID)
+}
+
+// lockBalance reads a player's balance inside a database transaction and locks
+// the row until it commits, so concurrent operations serialize on the player
+func lockBalance(ctx context.Context, dbTx *sql.Tx, playerID string) (*domain.Balance, error) {
+	return scanBalance(ctx, dbTx, playerID, " FOR UPDATE")
+}
+
+// rowQueryer is implemented by both *sql.DB and *sql.Tx
+type rowQueryer interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// scanBalance reads a balance row, appending suffix (e.g. a locking clause)
+// to the query
+func scanBalance(ctx context.Context, q rowQueryer, playerID, suffix string) (*domain.Balance, error) {
+	var realAmount, bonusAmount, wageringRemaining int64
 	var realCurrency, bonusCurrency string
 	var updatedAt time.Time
 
-	err := s.db.QueryRowContext(ctx, `
-		SELECT real_money_amount, real_money_currency, bonus_amount, bonus_currency, updated_at
-		FROM balances WHERE player_id = $1
-	`, playerID).Scan(&realAmount, &realCurrency, &bonusAmount, &bonusCurrency, &updatedAt)
+	err := q.QueryRowContext(ctx, `
+		SELECT real_money_amount, real_money_currency, bonus_amount, bonus_currency, wagering_remaining, updated_at
+		FROM balances WHERE player_id = $1`+suffix,
+		playerID).Scan(&realAmount, &realCurrency, &bonusAmount, &bonusCurrency, &wageringRemaining, &updatedAt)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, ErrPlayerNotFound
@@ -55,31 +258,154 @@ func (s *Service) GetBalance(ctx context.Context, playerID string) (*domain.Bala
 
 	realMoney := domain.Money{Amount: realAmount, Currency: realCurrency}
 	bonus := domain.Money{Amount: bonusAmount, Currency: bonusCurrency}
+	available, err := realMoney.AddChecked(bonus)
+	if err != nil {
+		return nil, fmt.Errorf("bonus balance: %w", err)
+	}
 
 	return &domain.Balance{
 		PlayerID:     playerID,
 		RealMoney:    realMoney,
 		BonusBalance: bonus,
-		Available:    realMoney.Add(bonus),
+		Available:    available,
 		Currency:     realCurrency,
 		UpdatedAt:    updatedAt,
+
+		WageringRemaining: domain.Money{Amount: wageringRemaining, Currency: bonusCurrency},
 	}, nil
 }
 
-// Deposit adds funds to a player's account (GLI-19 §2.5.6)
+// insertTransaction records a ledger entry inside a database transaction
+func insertTransaction(ctx context.Context, dbTx *sql.Tx, tx *domain.Transaction) error {
+	var key interface{}
+	if tx.IdempotencyKey != "" {
+		key = tx.IdempotencyKey
+	}
+
+	_, err := dbTx.ExecContext(ctx, `
+		INSERT INTO transactions (id, player_id, type, amount, currency, balance_before, balance_after, status, reference, description, created_at, completed_at, idempotency_key, bonus_amount)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+	`, tx.ID, tx.PlayerID, tx.Type, tx.Amount.Amount, tx.Amount.Currency,
+		tx.BalanceBefore.Amount, tx.BalanceAfter.Amount, tx.Status, tx.Reference, tx.Description, tx.CreatedAt, tx.CompletedAt, key, tx.BonusAmount)
+	return err
+}
+
+// idempotencyKeyCtx is the context key for a client-supplied idempotency key
+type idempotencyKeyCtx struct{}
+
+// WithIdempotencyKey returns a context carrying an idempotency key for the
+// next wallet operation. Retrying the operation with the same key returns the
+// original transaction.
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKeyCtx{}, key)
+}
+
+// idempotencyKey returns the key from the context, or fallback if none is set
+func idempotencyKey(ctx context.Context, fallback string) string {
+	if key, ok := ctx.Value(idempotencyKeyCtx{}).(string); ok && key != "" {
+		return key
+	}
+	return fallback
+}
+
+// findPrior returns the transaction already recorded under an idempotency
+// key, or nil if there is none. A prior transaction for a different amount is
+// a conflicting request, not a retry.
+func findPrior(ctx context.Context, dbTx *sql.Tx, playerID string, txType domain.TransactionType, key string, amount domain.Money) (*domain.Transaction, error) {
+	prior, err := findPriorBy(ctx, dbTx, "idempotency_key", playerID, txType, key, amount)
+	if errors.Is(err, errPriorConflict) {
+		return nil, ErrDuplicateTransaction
+	}
+	return prior, err
+}
+
+// findByReference returns the deposit or withdrawal already recorded under a
+// reference, or nil if there is none, so a request retried with the same
+// reference is applied once. A prior transaction for a different amount is
+// a conflicting request, not a retry.
+func findByReference(ctx context.Context, dbTx *sql.Tx, playerID string, txType domain.TransactionType, reference string, amount domain.Money) (*domain.Transaction, error) {
+	prior, err := findPriorBy(ctx, dbTx, "reference", playerID, txType, reference, amount)
+	if errors.Is(err, errPriorConflict) {
+		return nil, ErrDuplicateReference
+	}
+	return prior, err
+}
+
+// errPriorConflict is returned by findPriorBy for a prior transaction of a
+// different amount
+var errPriorConflict = errors.New("prior transaction for a different amount")
+
+// findPriorBy returns the player's transaction of a type whose column holds
+// value, or nil if value is empty or there is none
+func findPriorBy(ctx context.Context, dbTx *sql.Tx, column, playerID string, txType domain.TransactionType, value string, amount domain.Money) (*domain.Transaction, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	row := dbTx.QueryRowContext(ctx, `
+		SELECT `+transactionColumns+`
+		FROM transactions WHERE player_id = $1 AND type = $2 AND `+column+` = $3
+	`, playerID, txType, value)
+	prior, err := scanTransaction(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if prior.Amount.Amount != amount.Amount {
+		return nil, errPriorConflict
+	}
+	return prior, nil
+}
+
+// Deposit adds funds to a player's account (GLI-19 §2.5.6). Deposits outside
+// the bounds set for their currency are refused, and with limits set, so are
+// deposits over the player's deposit limits or from a self-excluded player.
 func (s *Service) Deposit(ctx context.Context, playerID string, amount domain.Money, reference string) (*domain.Transaction, error) {
 	if amount.Amount <= 0 {
 		return nil, ErrInvalidAmount
 	}
+	if err := s.checkDepositBounds(ctx, playerID, amount); err != nil {
+		return nil, err
+	}
 
-	// Get current balance
-	balance, err := s.GetBalance(ctx, playerID)
+	// Lock the balance row for the rest of the transaction
+	dbTx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
 		return nil, err
 	}
+	defer dbTx.Rollback()
+
+	balance, err := lockBalance(ctx, dbTx, playerID)
+	if err != nil {
+		return nil, err
+	}
+	if amount.Currency != balance.Currency {
+		return nil, ErrCurrencyMismatch
+	}
+
+	// A retry of an already-applied request returns the original transaction
+	key := idempotencyKey(ctx, "")
+	if prior, err := findPrior(ctx, dbTx, playerID, domain.TxTypeDeposit, key, amount); prior != nil || err != nil {
+		return prior, err
+	}
+	if prior, err := findByReference(ctx, dbTx, playerID, domain.TxTypeDeposit, reference, amount); prior != nil || err != nil {
+		return prior, err
+	}
+
+	// Checked with the balance locked, so concurrent deposits cannot both fit
+	// under a limit only one of them fits under
+	if err := s.checkDepositLimits(ctx, dbTx, playerID, amount); err != nil {
+		return nil, err
+	}
 
 	now := time.Now().UTC()
-	newBalance := balance.RealMoney.Add(amount)
+	newBalance, err := balance.RealMoney.AddChecked(amount)
+	if err != nil {
+		return nil, err
+	}
 
 	// Create transaction record
 	tx := &domain.Transaction{
@@ -94,15 +420,11 @@ func (s *Service) Deposit(ctx context.Context, playerID string, amount domain.Mo
 		Description:   "Deposit",
 		CreatedAt:     now,
 		CompletedAt:   &now,
-	}
 
-	// Update balance and record transaction atomically
-	dbTx, err := s.db.BeginTx(ctx, nil)
-	if err != nil {
-		return nil, err
+		IdempotencyKey: key,
 	}
-	defer dbTx.Rollback()
 
+	// Update balance and record transaction atomically
 	_, err = dbTx.ExecContext(ctx, `
 		UPDATE balances SET real_money_amount = $1, updated_at = $2 WHERE player_id = $3
 	`, newBalance.Amount, now, playerID)
@@ -110,18 +432,14 @@ func (s *Service) Deposit(ctx context.Context, playerID string, amount domain.Mo
 		return nil, err
 	}
 
-	_, err = dbTx.ExecContext(ctx, `
-		INSERT INTO transactions (id, player_id, type, amount, currency, balance_before, balance_after, status, reference, description, created_at, completed_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
-	`, tx.ID, tx.PlayerID, tx.Type, tx.Amount.Amount, tx.Amount.Currency,
-		tx.BalanceBefore.Amount, tx.BalanceAfter.Amount, tx.Status, tx.Reference, tx.Description, tx.CreatedAt, tx.CompletedAt)
-	if err != nil {
+	if err := insertTransaction(ctx, dbTx, tx); err != nil {
 		return nil, err
 	}
 
 	if err := dbTx.Commit(); err != nil {
 		return nil, err
 	}
+	s.publishBalance(playerID, newBalance.Amount+balance.BonusBalance.Amount, newBalance.Currency, tx.Type)
 
 	// Audit log
 	s.audit.Log(ctx, audit.EventDeposit, domain.SeverityInfo,
@@ -132,21 +450,59 @@ func (s *Service) Deposit(ctx context.Context, playerID string, amount domain.Mo
 			"currency":       amount.Currency,
 		},
 		audit.WithPlayer(playerID))
+	s.auditLargeTransaction(ctx, tx)
 
 	return tx, nil
 }
 
+// checkDepositLimits refuses deposits from self-excluded players and
+// deposits that would take the player over a deposit limit
+// GLI-19 §2.5.5 - Limits must be enforced
+func (s *Service) checkDepositLimits(ctx context.Context, dbTx *sql.Tx, playerID string, amount domain.Money) error {
+	if s.limits == nil {
+		return nil
+	}
+
+	excluded, err := s.limits.IsExcludedTx(ctx, dbTx, playerID)
+	if err != nil {
+		return err
+	}
+	if excluded {
+		return ErrPlayerExcluded
+	}
+
+	return s.limits.CheckDepositLimitTx(ctx, dbTx, playerID, amount)
+}
+
 // Withdraw removes funds from a player's account (GLI-19 §2.5.6)
 func (s *Service) Withdraw(ctx context.Context, playerID string, amount domain.Money, reference string) (*domain.Transaction, error) {
 	if amount.Amount <= 0 {
 		return nil, ErrInvalidAmount
 	}
 
-	// Get current balance
-	balance, err := s.GetBalance(ctx, playerID)
+	// Lock the balance row for the rest of the transaction
+	dbTx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
 		return nil, err
 	}
+	defer dbTx.Rollback()
+
+	balance, err := lockBalance(ctx, dbTx, playerID)
+	if err != nil {
+		return nil, err
+	}
+	if amount.Currency != balance.Currency {
+		return nil, ErrCurrencyMismatch
+	}
+
+	// A retry of an already-applied request returns the original transaction
+	key := idempotencyKey(ctx, "")
+	if prior, err := findPrior(ctx, dbTx, playerID, domain.TxTypeWithdrawal, key, amount); prior != nil || err != nil {
+		return prior, err
+	}
+	if prior, err := findByReference(ctx, dbTx, playerID, domain.TxTypeWithdrawal, reference, amount); prior != nil || err != nil {
+		return prior, err
+	}
 
 	// Check sufficient funds (GLI-19 §2.5.6 - no negative balance)
 	if balance.RealMoney.Amount < amount.Amount {
@@ -154,7 +510,10 @@ func (s *Service) Withdraw(ctx context.Context, playerID string, amount domain.M
 	}
 
 	now := time.Now().UTC()
-	newBalance := balance.RealMoney.Sub(amount)
+	newBalance, err := balance.RealMoney.SubChecked(amount)
+	if err != nil {
+		return nil, err
+	}
 
 	// Create transaction record
 	tx := &domain.Transaction{
@@ -169,15 +528,11 @@ func (s *Service) Withdraw(ctx context.Context, playerID string, amount domain.M
 		Description:   "Withdrawal",
 		CreatedAt:     now,
 		CompletedAt:   &now,
-	}
 
-	// Update balance and record transaction atomically
-	dbTx, err := s.db.BeginTx(ctx, nil)
-	if err != nil {
-		return nil, err
+		IdempotencyKey: key,
 	}
-	defer dbTx.Rollback()
 
+	// Update balance and record transaction atomically
 	_, err = dbTx.ExecContext(ctx, `
 		UPDATE balances SET real_money_amount = $1, updated_at = $2 WHERE player_id = $3
 	`, newBalance.Amount, now, playerID)
@@ -185,18 +540,14 @@ func (s *Service) Withdraw(ctx context.Context, playerID string, amount domain.M
 		return nil, err
 	}
 
-	_, err = dbTx.ExecContext(ctx, `
-		INSERT INTO transactions (id, player_id, type, amount, currency, balance_before, balance_after, status, reference, description, created_at, completed_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
-	`, tx.ID, tx.PlayerID, tx.Type, tx.Amount.Amount, tx.Amount.Currency,
-		tx.BalanceBefore.Amount, tx.BalanceAfter.Amount, tx.Status, tx.Reference, tx.Description, tx.CreatedAt, tx.CompletedAt)
-	if err != nil {
+	if err := insertTransaction(ctx, dbTx, tx); err != nil {
 		return nil, err
 	}
 
 	if err := dbTx.Commit(); err != nil {
 		return nil, err
 	}
+	s.publishBalance(playerID, newBalance.Amount+balance.BonusBalance.Amount, newBalance.Currency, tx.Type)
 
 	// Audit log
 	s.audit.Log(ctx, audit.EventWithdrawal, domain.SeverityInfo,
@@ -207,29 +558,150 @@ func (s *Service) Withdraw(ctx context.Context, playerID string, amount domain.M
 			"currency":       amount.Currency,
 		},
 		audit.WithPlayer(playerID))
+	s.auditLargeTransaction(ctx, tx)
 
 	return tx, nil
 }
 
-// PlaceWager deducts wager amount for a game (GLI-19 §4.3.3)
+// PlaceWager deducts wager amount for a game, from the bonus and real money
+// balances in the order set by the bonus policy. The wager counts toward the
+// bonus wagering requirement, and the bonus converts to real money as soon as
+// the requirement is met (GLI-19 §4.3.3).
 func (s *Service) PlaceWager(ctx context.Context, playerID string, amount domain.Money, gameID, cycleID string) (*domain.Transaction, error) {
 	if amount.Amount <= 0 {
 		return nil, ErrInvalidAmount
 	}
 
-	// Get current balance
-	balance, err := s.GetBalance(ctx, playerID)
+	var tx *domain.Transaction
+	var before, after *domain.Balance
+	err := database.WithTx(ctx, s.db, func(dbTx *sql.Tx) (err error) {
+		if before, err = lockBalance(ctx, dbTx, playerID); err != nil {
+			return err
+		}
+		tx, after, err = placeWager(ctx, dbTx, s.bonusPolicy, playerID, amount, cycleID,
+			idempotencyKey(ctx, cycleID), fmt.Sprintf("Wager on %s", gameID))
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
 
+	// A retried request moved no money and has nothing to publish
+	if after == nil {
+		return tx, nil
+	}
+	s.PublishBalance(after, tx.Type)
+
+	if WageringMet(before, after) {
+		if _, err := s.ConvertBonus(ctx, playerID); err != nil {
+			return nil, err
+		}
+	}
+	return tx, nil
+}
+
+// PlaceWagerTx deducts a wager like PlaceWager inside the caller's database
+// transaction, so it commits or rolls back with the rest of the game cycle. The caller publishes
+// the balance once the transaction has committed, and converts the bonus if
+// the wager met its requirement.
+func (s *Service) PlaceWagerTx(ctx context.Context, dbTx *sql.Tx, playerID string, amount domain.Money, gameID, cycleID string) (*domain.Transaction, error) {
+	if amount.Amount <= 0 {
+		return nil, ErrInvalidAmount
+	}
+	tx, _, err := placeWager(ctx, dbTx, s.bonusPolicy, playerID, amount, cycleID,
+		idempotencyKey(ctx, cycleID), fmt.Sprintf("Wager on %s", gameID))
+	return tx, err
+}
+
+// GambleTx settles one gamble step on a cycle's win inside the caller's
+// database transaction: a won gamble credits the stake again, doubling the
+// win, and a lost one takes the whole stake back, failing with
+// ErrInsufficientFunds if it is no longer held. Each step is recorded under
+// its own idempotency key. The stake goes to and comes from the balances the
+// win was credited to, and does not count toward the wagering requirement.
+func (s *Service) GambleTx(ctx context.Context, dbTx *sql.Tx, playerID string, stake domain.Money, won bool, gameID, cycleID string, step int) (*domain.Transaction, error) {
+	if stake.Amount <= 0 {
+		return nil, ErrInvalidAmount
+	}
+
+	key := fmt.Sprintf("%s:gamble:%d", cycleID, step)
+	if won {
+		tx, _, err := creditWin(ctx, dbTx, playerID, stake, cycleID, key, fmt.Sprintf("Gamble win on %s", gameID), domain.TxTypeWin)
+		return tx, err
+	}
+	tx, _, err := debitWager(ctx, dbTx, playerID, stake, cycleID, key, fmt.Sprintf("Gamble loss on %s", gameID),
+		func(balance *domain.Balance) (int64, int64, error) {
+			share, err := cycleBonusShare(ctx, dbTx, playerID, cycleID, domain.TxTypeWin, stake.Amount)
+			if err != nil {
+				return 0, 0, err
+			}
+			// Bonus funds spent since the win was credited leave real money
+			// to cover the rest, and the other way round
+			bonusPart := min(share, balance.BonusBalance.Amount)
+			if short := stake.Amount - bonusPart - balance.RealMoney.Amount; short > 0 {
+				bonusPart += short
+			}
+			return bonusPart, balance.WageringRemaining.Amount, nil
+		})
+	return tx, err
+}
+
+// placeWager deducts a game wager within dbTx, splitting it between the real
+// money and bonus balances under the policy. The full wager counts toward the
+// bonus wagering requirement. It returns the balance after the wager, or a
+// nil balance when the wager was already applied under the same idempotency
+// key.
+func placeWager(ctx context.Context, dbTx *sql.Tx, policy BonusPolicy, playerID string, amount domain.Money, cycleID, key, description string) (*domain.Transaction, *domain.Balance, error) {
+	return debitWager(ctx, dbTx, playerID, amount, cycleID, key, description,
+		func(balance *domain.Balance) (int64, int64, error) {
+			bonusPart, _ := splitWager(policy, balance, amount.Amount)
+
+			// The requirement is dropped once the bonus is spent and no
+			// win on it can come back to it
+			wagering := balance.WageringRemaining.Amount - amount.Amount
+			if wagering < 0 || balance.BonusBalance.Amount == 0 {
+				wagering = 0
+			}
+			return bonusPart, wagering, nil
+		})
+}
+
+// debitWager deducts a wager within dbTx. split returns the part taken from
+// the bonus balance and the wagering requirement left after the wager.
+func debitWager(ctx context.Context, dbTx *sql.Tx, playerID string, amount domain.Money, cycleID, key, description string,
+	split func(balance *domain.Balance) (bonusPart, wagering int64, err error)) (*domain.Transaction, *domain.Balance, error) {
+	balance, err := lockBalance(ctx, dbTx, playerID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if amount.Currency != balance.Currency {
+		return nil, nil, ErrCurrencyMismatch
+	}
+
+	// A retry of an already-applied request returns the original transaction
+	if prior, err := findPrior(ctx, dbTx, playerID, domain.TxTypeWager, key, amount); prior != nil || err != nil {
+		return prior, nil, err
+	}
+
 	// Check sufficient funds
 	if balance.Available.Amount < amount.Amount {
-		return nil, ErrInsufficientFunds
+		return nil, nil, ErrInsufficientFunds
+	}
+
+	bonusPart, wagering, err := split(balance)
+	if err != nil {
+		return nil, nil, err
+	}
+	if bonusPart > 0 {
+		description = fmt.Sprintf("%s (bonus %d)", description, bonusPart)
 	}
 
 	now := time.Now().UTC()
-	newBalance := balance.RealMoney.Sub(amount)
+	newReal, err := balance.RealMoney.SubChecked(domain.Money{Amount: amount.Amount - bonusPart, Currency: amount.Currency})
+	if err != nil {
+		return nil, nil, err
+	}
+	newBonus := balance.BonusBalance.Amount - bonusPart
 
 	// Create transaction record
 	tx := &domain.Transaction{
@@ -238,61 +710,168 @@ func (s *Service) PlaceWager(ctx context.Context, playerID string, amount domain
 		Type:          domain.TxTypeWager,
 		Amount:        amount,
 		BalanceBefore: balance.RealMoney,
-		BalanceAfter:  newBalance,
+		BalanceAfter:  newReal,
 		Status:        domain.TxStatusCompleted,
 		Reference:     cycleID,
-		Description:   fmt.Sprintf("Wager on %s", gameID),
+		Description:   description,
 		CreatedAt:     now,
 		CompletedAt:   &now,
-	}
 
-	// Update balance and record transaction
-	dbTx, err := s.db.BeginTx(ctx, nil)
-	if err != nil {
-		return nil, err
+		IdempotencyKey: key,
+		BonusAmount:    bonusPart,
 	}
-	defer dbTx.Rollback()
 
+	// Update balance and record transaction atomically
 	_, err = dbTx.ExecContext(ctx, `
-		UPDATE balances SET real_money_amount = $1, updated_at = $2 WHERE player_id = $3
-	`, newBalance.Amount, now, playerID)
+		UPDATE balances SET real_money_amount = $1, bonus_amount = $2, wagering_remaining = $3, updated_at = $4 WHERE player_id = $5
+	`, newReal.Amount, newBonus, wagering, now, playerID)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	_, err = dbTx.ExecContext(ctx, `
-		INSERT INTO transactions (id, player_id, type, amount, currency, balance_before, balance_after, status, reference, description, created_at, completed_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
-	`, tx.ID, tx.PlayerID, tx.Type, tx.Amount.Amount, tx.Amount.Currency,
-		tx.BalanceBefore.Amount, tx.BalanceAfter.Amount, tx.Status, tx.Reference, tx.Description, tx.CreatedAt, tx.CompletedAt)
-	if err != nil {
-		return nil, err
+	if err := insertTransaction(ctx, dbTx, tx); err != nil {
+		return nil, nil, err
 	}
 
-	if err := dbTx.Commit(); err != nil {
+	after := withBalances(balance, newReal, newBonus, now)
+	after.WageringRemaining.Amount = wagering
+	return tx, after, nil
+}
+
+// CreditWin adds winnings to a player's balance (GLI-19 §4.3.3)
+func (s *Service) CreditWin(ctx context.Context, playerID string, amount domain.Money, gameID, cycleID string) (*domain.Transaction, error) {
+	if amount.Amount < 0 {
+		return nil, ErrInvalidAmount
+	}
+	if amount.Amount == 0 {
+		return nil, nil // No win to credit
+	}
+
+	var tx *domain.Transaction
+	var after *domain.Balance
+	err := database.WithTx(ctx, s.db, func(dbTx *sql.Tx) (err error) {
+		tx, after, err = creditWin(ctx, dbTx, playerID, amount, cycleID,
+			idempotencyKey(ctx, cycleID), fmt.Sprintf("Win on %s", gameID), domain.TxTypeWager)
+		return err
+	})
+	if err != nil {
 		return nil, err
 	}
 
+	// A retried request moved no money and has nothing to publish
+	if after != nil {
+		s.PublishBalance(after, tx.Type)
+	}
 	return tx, nil
 }
 
-// CreditWin adds winnings to a player's balance (GLI-19 §4.3.3)
-func (s *Service) CreditWin(ctx context.Context, playerID string, amount domain.Money, gameID, cycleID string) (*domain.Transaction, error) {
+// CreditWinTx credits winnings inside the caller's database transaction, so
+// it commits or rolls back with the rest of the game cycle. The caller
+// publishes the balance once the transaction has committed.
+func (s *Service) CreditWinTx(ctx context.Context, dbTx *sql.Tx, playerID string, amount domain.Money, gameID, cycleID string) (*domain.Transaction, error) {
 	if amount.Amount < 0 {
 		return nil, ErrInvalidAmount
 	}
 	if amount.Amount == 0 {
 		return nil, nil // No win to credit
 	}
+	tx, _, err := creditWin(ctx, dbTx, playerID, amount, cycleID,
+		idempotencyKey(ctx, cycleID), fmt.Sprintf("Win on %s", gameID), domain.TxTypeWager)
+	return tx, err
+}
+
+// CreditJackpotTx pays a progressive jackpot to the player inside the
+// caller's database transaction. The payout is a win of its own, separate
+// from any line win in the same cycle.
+func (s *Service) CreditJackpotTx(ctx context.Context, dbTx *sql.Tx, playerID string, amount domain.Money, gameID, cycleID string) (*domain.Transaction, error) {
+	if amount.Amount <= 0 {
+		return nil, ErrInvalidAmount
+	}
+	tx, _, err := creditWin(ctx, dbTx, playerID, amount, cycleID,
+		cycleID+":jackpot", fmt.Sprintf("Jackpot win on %s", gameID), domain.TxTypeWager)
+	return tx, err
+}
 
-	// Get current balance
-	balance, err := s.GetBalance(ctx, playerID)
+// RecordJackpotContributionTx records the part of a wager that went to a
+// progressive jackpot pool. The wager has already been debited, so the entry
+// leaves the balance unchanged.
+func (s *Service) RecordJackpotContributionTx(ctx context.Context, dbTx *sql.Tx, playerID string, amount domain.Money, gameID, cycleID string) (*domain.Transaction, error) {
+	if amount.Amount <= 0 {
+		return nil, ErrInvalidAmount
+	}
+
+	balance, err := lockBalance(ctx, dbTx, playerID)
 	if err != nil {
 		return nil, err
 	}
+	if amount.Currency != balance.Currency {
+		return nil, ErrCurrencyMismatch
+	}
+
+	if prior, err := findPrior(ctx, dbTx, playerID, domain.TxTypeJackpot, cycleID, amount); prior != nil || err != nil {
+		return prior, err
+	}
+
+	now := time.Now().UTC()
+	tx := &domain.Transaction{
+		ID:            uuid.New().String(),
+		PlayerID:      playerID,
+		Type:          domain.TxTypeJackpot,
+		Amount:        amount,
+		BalanceBefore: balance.RealMoney,
+		BalanceAfter:  balance.RealMoney,
+		Status:        domain.TxStatusCompleted,
+		Reference:     cycleID,
+		Description:   fmt.Sprintf("Jackpot contribution on %s", gameID),
+		CreatedAt:     now,
+		CompletedAt:   &now,
+
+		IdempotencyKey: cycleID,
+	}
+	if err := insertTransaction(ctx, dbTx, tx); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// creditWin credits winnings within dbTx. While a wagering requirement is
+// outstanding, the part of the win staked from the bonus balance, in the
+// proportion the cycle's transactions of stakeType drew on it, is credited
+// back to the bonus. It returns the balance after the win, or a nil balance
+// when the win was already credited under the same idempotency key.
+func creditWin(ctx context.Context, dbTx *sql.Tx, playerID string, amount domain.Money, cycleID, key, description string, stakeType domain.TransactionType) (*domain.Transaction, *domain.Balance, error) {
+	balance, err := lockBalance(ctx, dbTx, playerID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if amount.Currency != balance.Currency {
+		return nil, nil, ErrCurrencyMismatch
+	}
+
+	// A retry of an already-applied request returns the original transaction
+	if prior, err := findPrior(ctx, dbTx, playerID, domain.TxTypeWin, key, amount); prior != nil || err != nil {
+		return prior, nil, err
+	}
+
+	var bonusPart int64
+	if balance.WageringRemaining.Amount > 0 {
+		if bonusPart, err = cycleBonusShare(ctx, dbTx, playerID, cycleID, stakeType, amount.Amount); err != nil {
+			return nil, nil, err
+		}
+	}
+	if bonusPart > 0 {
+		description = fmt.Sprintf("%s (bonus %d)", description, bonusPart)
+	}
 
 	now := time.Now().UTC()
-	newBalance := balance.RealMoney.Add(amount)
+	newBalance, err := balance.RealMoney.AddChecked(domain.Money{Amount: amount.Amount - bonusPart, Currency: amount.Currency})
+	if err != nil {
+		return nil, nil, err
+	}
+	newBonus, err := balance.BonusBalance.AddChecked(domain.Money{Amount: bonusPart, Currency: balance.BonusBalance.Currency})
+	if err != nil {
+		return nil, nil, err
+	}
 
 	// Create transaction record
 	tx := &domain.Transaction{
@@ -304,39 +883,146 @@ func (s *Service) CreditWin(ctx context.Context, playerID string, amount domain.
 		BalanceAfter:  newBalance,
 		Status:        domain.TxStatusCompleted,
 		Reference:     cycleID,
-		Description:   fmt.Sprintf("Win on %s", gameID),
+		Description:   description,
 		CreatedAt:     now,
 		CompletedAt:   &now,
+
+		IdempotencyKey: key,
+		BonusAmount:    bonusPart,
 	}
 
-	// Update balance and record transaction
-	dbTx, err := s.db.BeginTx(ctx, nil)
+	// Update balance and record transaction atomically
+	_, err = dbTx.ExecContext(ctx, `
+		UPDATE balances SET real_money_amount = $1, bonus_amount = $2, updated_at = $3 WHERE player_id = $4
+	`, newBalance.Amount, newBonus.Amount, now, playerID)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	defer dbTx.Rollback()
 
-	_, err = dbTx.ExecContext(ctx, `
-		UPDATE balances SET real_money_amount = $1, updated_at = $2 WHERE player_id = $3
-	`, newBalance.Amount, now, playerID)
+	if err := insertTransaction(ctx, dbTx, tx); err != nil {
+		return nil, nil, err
+	}
+
+	return tx, withBalances(balance, newBalance, newBonus.Amount, now), nil
+}
+
+// withRealMoney returns a copy of balance with its real money replaced
+func withRealMoney(balance *domain.Balance, realMoney domain.Money, updatedAt time.Time) *domain.Balance {
+	return withBalances(balance, realMoney, balance.BonusBalance.Amount, updatedAt)
+}
+
+// withBalances returns a copy of balance with its real money and bonus
+// replaced
+func withBalances(balance *domain.Balance, realMoney domain.Money, bonus int64, updatedAt time.Time) *domain.Balance {
+	after := *balance
+	after.RealMoney = realMoney
+	after.BonusBalance.Amount = bonus
+	after.Available = domain.Money{Amount: realMoney.Amount + bonus, Currency: realMoney.Currency}
+	after.UpdatedAt = updatedAt
+	return &after
+}
+
+// RefundWager returns a wager to the balances it was taken from for a voided
+// or interrupted game, recording it in the ledger as a refund
+// GLI-19 §4.16 - Voided games must refund the wager
+func (s *Service) RefundWager(ctx context.Context, playerID string, amount domain.Money, gameID, cycleID string) (*domain.Transaction, error) {
+	if amount.Amount <= 0 {
+		return nil, ErrInvalidAmount
+	}
+
+	var tx *domain.Transaction
+	var after *domain.Balance
+	err := database.WithTx(ctx, s.db, func(dbTx *sql.Tx) (err error) {
+		tx, after, err = refundWager(ctx, dbTx, playerID, amount, gameID, cycleID)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
 
+	// A retried request moved no money and has nothing to publish
+	if after != nil {
+		s.PublishBalance(after, tx.Type)
+	}
+	return tx, nil
+}
+
+// RefundWagerTx refunds a wager like RefundWager inside the caller's database
+// transaction, so the refund commits together with the voided cycle. The
+// caller publishes the balance once the transaction has committed.
+func (s *Service) RefundWagerTx(ctx context.Context, dbTx *sql.Tx, playerID string, amount domain.Money, gameID, cycleID string) (*domain.Transaction, error) {
+	if amount.Amount <= 0 {
+		return nil, ErrInvalidAmount
+	}
+	tx, _, err := refundWager(ctx, dbTx, playerID, amount, gameID, cycleID)
+	return tx, err
+}
+
+// refundWager credits a refund within dbTx, returning to the bonus balance
+// the part of the cycle's wagers taken from it. It returns the balance after
+// the refund, or a nil balance when the refund was already applied under the
+// same idempotency key.
+func refundWager(ctx context.Context, dbTx *sql.Tx, playerID string, amount domain.Money, gameID, cycleID string) (*domain.Transaction, *domain.Balance, error) {
+	balance, err := lockBalance(ctx, dbTx, playerID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if amount.Currency != balance.Currency {
+		return nil, nil, ErrCurrencyMismatch
+	}
+
+	// A retry of an already-applied request returns the original transaction
+	key := idempotencyKey(ctx, cycleID)
+	if prior, err := findPrior(ctx, dbTx, playerID, domain.TxTypeRefund, key, amount); prior != nil || err != nil {
+		return prior, nil, err
+	}
+
+	bonusPart, err := cycleBonusShare(ctx, dbTx, playerID, cycleID, domain.TxTypeWager, amount.Amount)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now().UTC()
+	newBalance, err := balance.RealMoney.AddChecked(domain.Money{Amount: amount.Amount - bonusPart, Currency: amount.Currency})
+	if err != nil {
+		return nil, nil, err
+	}
+	newBonus, err := balance.BonusBalance.AddChecked(domain.Money{Amount: bonusPart, Currency: balance.BonusBalance.Currency})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Create transaction record
+	tx := &domain.Transaction{
+		ID:            uuid.New().String(),
+		PlayerID:      playerID,
+		Type:          domain.TxTypeRefund,
+		Amount:        amount,
+		BalanceBefore: balance.RealMoney,
+		BalanceAfter:  newBalance,
+		Status:        domain.TxStatusCompleted,
+		Reference:     cycleID,
+		Description:   fmt.Sprintf("Refund of wager on %s", gameID),
+		CreatedAt:     now,
+		CompletedAt:   &now,
+
+		IdempotencyKey: key,
+		BonusAmount:    bonusPart,
+	}
+
+	// Update balance and record transaction atomically
 	_, err = dbTx.ExecContext(ctx, `
-		INSERT INTO transactions (id, player_id, type, amount, currency, balance_before, balance_after, status, reference, description, created_at, completed_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
-	`, tx.ID, tx.PlayerID, tx.Type, tx.Amount.Amount, tx.Amount.Currency,
-		tx.BalanceBefore.Amount, tx.BalanceAfter.Amount, tx.Status, tx.Reference, tx.Description, tx.CreatedAt, tx.CompletedAt)
+		UPDATE balances SET real_money_amount = $1, bonus_amount = $2, updated_at = $3 WHERE player_id = $4
+	`, newBalance.Amount, newBonus.Amount, now, playerID)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	if err := dbTx.Commit(); err != nil {
-		return nil, err
+	if err := insertTransaction(ctx, dbTx, tx); err != nil {
+		return nil, nil, err
 	}
 
-	return tx, nil
+	return tx, withBalances(balance, newBalance, newBonus.Amount, now), nil
 }
 
 // GetTransactions retrieves transaction history for a player (GLI-19 §2.5.7)
@@ -346,7 +1032,7 @@ func (s *Service) GetTransactions(ctx context.Context, playerID string, limit in
 	}
 
 	rows, err := s.db.QueryContext(ctx, `
-		SELECT id, player_id, type, amount, currency, balance_before, balance_after, status, reference, description, created_at, completed_at
+		SELECT `+transactionColumns+`
 		FROM transactions WHERE player_id = $1 ORDER BY created_at DESC LIMIT $2
 	`, playerID, limit)
 	if err != nil {
@@ -356,29 +1042,151 @@ func (s *Service) GetTransactions(ctx context.Context, playerID string, limit in
 
 	var transactions []*domain.Transaction
 	for rows.Next() {
-		var tx domain.Transaction
-		var amount, balBefore, balAfter int64
-		var currency, reference, description string
-		var completedAt sql.NullTime
-
-		err := rows.Scan(&tx.ID, &tx.PlayerID, &tx.Type, &amount, &currency,
-			&balBefore, &balAfter, &tx.Status, &reference, &description,
-			&tx.CreatedAt, &completedAt)
+		tx, err := scanTransaction(rows)
 		if err != nil {
 			return nil, err
 		}
+		transactions = append(transactions, tx)
+	}
 
-		tx.Amount = domain.Money{Amount: amount, Currency: currency}
-		tx.BalanceBefore = domain.Money{Amount: balBefore, Currency: currency}
-		tx.BalanceAfter = domain.Money{Amount: balAfter, Currency: currency}
-		tx.Reference = reference
-		tx.Description = description
-		if completedAt.Valid {
-			tx.CompletedAt = &completedAt.Time
+	return transactions, nil
+}
+
+// TransactionFilter selects a page of a player's transactions. Zero values
+// leave a criterion unrestricted.
+type TransactionFilter struct {
+	Types  []domain.TransactionType // Match any of these types
+	Status domain.TransactionStatus // Match this status
+	From   time.Time                // Created at or after
+	To     time.Time                // Created before
+	Limit  int                      // Page size, default 50
+	Offset int                      // Transactions to skip
+}
+
+// TransactionPage is one page of filtered transactions
+type TransactionPage struct {
+	Transactions []*domain.Transaction `json:"transactions"`
+	Total        int                   `json:"total"` // Matching transactions across all pages
+	Limit        int                   `json:"limit"`
+	Offset       int                   `json:"offset"`
+}
+
+// GetTransactionsFiltered retrieves a page of a player's transactions matching
+// the filter, newest first, with the total number of matches
+// GLI-19 §2.5.7 - Transaction log must be available for statements
+func (s *Service) GetTransactionsFiltered(ctx context.Context, playerID string, filter TransactionFilter) (*TransactionPage, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = 50
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+
+	where := "player_id = $1"
+	args := []interface{}{playerID}
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if len(filter.Types) > 0 {
+		placeholders := make([]string, len(filter.Types))
+		for i, t := range filter.Types {
+			placeholders[i] = arg(t)
 		}
+		where += " AND type IN (" + strings.Join(placeholders, ", ") + ")"
+	}
+	if filter.Status != "" {
+		where += " AND status = " + arg(filter.Status)
+	}
+	if !filter.From.IsZero() {
+		where += " AND created_at >= " + arg(filter.From)
+	}
+	if !filter.To.IsZero() {
+		where += " AND created_at < " + arg(filter.To)
+	}
 
-		transactions = append(transactions, &tx)
+	page := &TransactionPage{Limit: filter.Limit, Offset: filter.Offset}
+	err := s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM transactions WHERE "+where, args...).Scan(&page.Total)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count transactions: %w", err)
 	}
 
-	return transactions, nil
+	query := "SELECT " + transactionColumns + " FROM transactions WHERE " + where +
+		" ORDER BY created_at DESC, id DESC LIMIT " + arg(filter.Limit) + " OFFSET " + arg(filter.Offset)
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transactions: %w", err)
+	}
+	defer rows.Close()
+
+	page.Transactions = []*domain.Transaction{}
+	for rows.Next() {
+		tx, err := scanTransaction(rows)
+		if err != nil {
+			return nil, err
+		}
+		page.Transactions = append(page.Transactions, tx)
+	}
+
+	return page, rows.Err()
+}
+
+// GetTransactionByReference returns the player's transaction of a type
+// recorded under a reference, such as a payment provider's deposit ID, or
+// ErrTransactionNotFound. Deposits and withdrawals are unique per reference,
+// so callers can look one up before retrying it.
+func (s *Service) GetTransactionByReference(ctx context.Context, playerID string, txType domain.TransactionType, reference string) (*domain.Transaction, error) {
+	if reference == "" {
+		return nil, ErrTransactionNotFound
+	}
+
+	row := s.db.QueryRowContext(ctx, `
+		SELECT `+transactionColumns+`
+		FROM transactions WHERE player_id = $1 AND type = $2 AND reference = $3
+		ORDER BY created_at DESC
+		LIMIT 1
+	`, playerID, txType, reference)
+	tx, err := scanTransaction(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrTransactionNotFound
+	}
+	return tx, err
+}
+
+// transactionColumns are the columns read by scanTransaction
+const transactionColumns = `id, player_id, type, amount, currency, balance_before, balance_after, status,
+		reference, description, created_at, completed_at, idempotency_key, bonus_amount`
+
+// scanner is implemented by *sql.Row and *sql.Rows
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanTransaction reads a transaction selected with transactionColumns
+func scanTransaction(row scanner) (*domain.Transaction, error) {
+	var tx domain.Transaction
+	var amount, balBefore, balAfter int64
+	var currency, reference, description string
+	var completedAt sql.NullTime
+	var key sql.NullString
+
+	err := row.Scan(&tx.ID, &tx.PlayerID, &tx.Type, &amount, &currency,
+		&balBefore, &balAfter, &tx.Status, &reference, &description,
+		&tx.CreatedAt, &completedAt, &key, &tx.BonusAmount)
+	if err != nil {
+		return nil, err
+	}
+
+	tx.Amount = domain.Money{Amount: amount, Currency: currency}
+	tx.BalanceBefore = domain.Money{Amount: balBefore, Currency: currency}
+	tx.BalanceAfter = domain.Money{Amount: balAfter, Currency: currency}
+	tx.Reference = reference
+	tx.Description = description
+	tx.IdempotencyKey = key.String
+	if completedAt.Valid {
+		tx.CompletedAt = &completedAt.Time
+	}
+
+	return &tx, nil
 }
@@ -2,40 +2,37 @@ package wallet
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/alexbotov/rgs/internal/audit"
 	"github.com/alexbotov/rgs/internal/database"
+	"github.com/alexbotov/rgs/internal/database/dbtest"
 	"github.com/alexbotov/rgs/internal/domain"
+	"github.com/alexbotov/rgs/internal/events"
+	"github.com/alexbotov/rgs/internal/limits"
 	"github.com/google/uuid"
 )
 
+func TestMain(m *testing.M) {
+	dbtest.Main(m)
+}
+
 func setupTestWallet(t *testing.T) (*Service, string, func()) {
 	t.Helper()
 
-	// Create PostgreSQL connection
-	db, err := database.New("postgres", "host=localhost dbname=rgs sslmode=disable")
-	if err != nil {
-		t.Fatalf("Failed to create database: %v", err)
-	}
-
-	// Ensure schema exists (idempotent)
-	if err := db.Migrate(); err != nil {
-		// Ignore if tables already exist
-		t.Logf("Migration note: %v", err)
-	}
-
-	// Clean data for fresh test state
-	if err := db.CleanData(); err != nil {
-		t.Fatalf("Failed to clean data: %v", err)
-	}
+	db := dbtest.Open(t)
 
 	auditSvc := audit.New(db.DB)
 	svc := New(db.DB, auditSvc, "USD")
 
 	// Create a test player
 	playerID := uuid.New().String()
-	_, err = db.DB.Exec(`
+	_, err := db.DB.Exec(`
 		INSERT INTO players (id, username, email, password_hash, status, registration_date, tc_accepted_at, created_at, updated_at)
 		VALUES ($1, 'testplayer', 'test@example.com', 'hash', 'active', NOW(), NOW(), NOW(), NOW())
 	`, playerID)
@@ -52,10 +49,7 @@ func setupTestWallet(t *testing.T) (*Service, string, func()) {
 		t.Fatalf("Failed to create balance: %v", err)
 	}
 
-	return svc, playerID, func() {
-		db.CleanData()
-		db.Close()
-	}
+	return svc, playerID, func() {}
 }
 
 func TestGetBalance(t *testing.T) {
@@ -130,6 +124,194 @@ func TestDeposit(t *testing.T) {
 	})
 }
 
+func TestDepositPublishesBalance(t *testing.T) {
+	svc, playerID, cleanup := setupTestWallet(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	hub := events.New()
+	svc.SetEvents(hub)
+	sub := hub.Subscribe(playerID)
+	defer sub.Close()
+
+	if _, err := svc.Deposit(ctx, playerID, domain.NewMoney(25.00, "USD"), "push"); err != nil {
+		t.Fatalf("Deposit failed: %v", err)
+	}
+
+	select {
+	case event := <-sub.C:
+		if event.Type != events.BalanceUpdate {
+			t.Errorf("Expected balance_update, got %s", event.Type)
+		}
+		balance, _ := svc.GetBalance(ctx, playerID)
+		if event.Balance != balance.Available {
+			t.Errorf("Expected pushed balance %v, got %v", balance.Available, event.Balance)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected a balance update after deposit")
+	}
+}
+
+func TestDepositLimits(t *testing.T) {
+	svc, playerID, cleanup := setupTestWallet(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	limitsSvc := limits.New(svc.db, svc.audit, "USD")
+	svc.SetLimits(limitsSvc)
+
+	_, err := limitsSvc.SetDepositLimit(ctx, &limits.SetDepositLimitRequest{
+		PlayerID: playerID,
+		Period:   "daily",
+		Amount:   10000, // $100
+	})
+	if err != nil {
+		t.Fatalf("Failed to set deposit limit: %v", err)
+	}
+
+	t.Run("DepositUpToLimit", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			if _, err := svc.Deposit(ctx, playerID, domain.Money{Amount: 5000, Currency: "USD"}, fmt.Sprintf("up-to-limit-%d", i)); err != nil {
+				t.Fatalf("Expected deposit %d within the limit, got %v", i+1, err)
+			}
+		}
+	})
+
+	t.Run("OverLimitRejected", func(t *testing.T) {
+		_, err := svc.Deposit(ctx, playerID, domain.Money{Amount: 1, Currency: "USD"}, "over-limit")
+		if !errors.Is(err, ErrDepositLimitExceeded) {
+			t.Fatalf("Expected ErrDepositLimitExceeded, got %v", err)
+		}
+
+		balance, _ := svc.GetBalance(ctx, playerID)
+		if balance.RealMoney.Amount != 10000 {
+			t.Errorf("Expected balance to stay at 10000, got %d", balance.RealMoney.Amount)
+		}
+	})
+
+	t.Run("SelfExcludedRejected", func(t *testing.T) {
+		if _, err := limitsSvc.SelfExclude(ctx, playerID, "test", nil); err != nil {
+			t.Fatalf("Failed to self-exclude: %v", err)
+		}
+		_, err := svc.Deposit(ctx, playerID, domain.Money{Amount: 1, Currency: "USD"}, "excluded")
+		if !errors.Is(err, ErrPlayerExcluded) {
+			t.Errorf("Expected ErrPlayerExcluded, got %v", err)
+		}
+	})
+}
+
+func TestDepositBounds(t *testing.T) {
+	svc, playerID, cleanup := setupTestWallet(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	svc.SetDepositBounds(map[string]DepositBounds{"USD": {Min: 1000, Max: 1000000}})
+
+	t.Run("BelowMinimum", func(t *testing.T) {
+		if _, err := svc.Deposit(ctx, playerID, domain.NewMoney(9.99, "USD"), "below-min"); err != ErrDepositBelowMinimum {
+			t.Errorf("Expected ErrDepositBelowMinimum, got %v", err)
+		}
+	})
+
+	t.Run("AboveMaximum", func(t *testing.T) {
+		if _, err := svc.Deposit(ctx, playerID, domain.NewMoney(1000000.00, "USD"), "above-max"); err != ErrDepositAboveMaximum {
+			t.Errorf("Expected ErrDepositAboveMaximum, got %v", err)
+		}
+
+		var audited int
+		svc.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM audit_events WHERE type = 'deposit_above_maximum' AND player_id = $1 AND severity = $2",
+			playerID, domain.SeverityWarning).Scan(&audited)
+		if audited != 1 {
+			t.Errorf("Expected the refused deposit audited as a warning, got %d events", audited)
+		}
+	})
+
+	t.Run("InRange", func(t *testing.T) {
+		for i, amount := range []float64{10.00, 10000.00} {
+			if _, err := svc.Deposit(ctx, playerID, domain.NewMoney(amount, "USD"), fmt.Sprintf("in-range-%d", i)); err != nil {
+				t.Errorf("Expected deposit of %.2f to succeed, got %v", amount, err)
+			}
+		}
+
+		balance, _ := svc.GetBalance(ctx, playerID)
+		if balance.RealMoney.Amount != 1001000 {
+			t.Errorf("Expected only in-range deposits credited, got balance %d", balance.RealMoney.Amount)
+		}
+	})
+}
+
+func TestLargeTransactionAudit(t *testing.T) {
+	svc, playerID, cleanup := setupTestWallet(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	svc.SetLargeTransactionThreshold(1000000)
+
+	largeEvents := func() int {
+		var n int
+		svc.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM audit_events WHERE type = $1 AND player_id = $2 AND severity = $3",
+			audit.EventLargeTransaction, playerID, domain.SeverityWarning).Scan(&n)
+		return n
+	}
+
+	if _, err := svc.Deposit(ctx, playerID, domain.NewMoney(9999.99, "USD"), "small-deposit"); err != nil {
+		t.Fatalf("Failed to deposit: %v", err)
+	}
+	if n := largeEvents(); n != 0 {
+		t.Errorf("Expected no large transaction event below the threshold, got %d", n)
+	}
+
+	if _, err := svc.Deposit(ctx, playerID, domain.NewMoney(10000.00, "USD"), "large-deposit"); err != nil {
+		t.Fatalf("Failed to deposit: %v", err)
+	}
+	if n := largeEvents(); n != 1 {
+		t.Errorf("Expected a large transaction event for a deposit at the threshold, got %d", n)
+	}
+
+	if _, err := svc.Withdraw(ctx, playerID, domain.NewMoney(15000.00, "USD"), "large-withdrawal"); err != nil {
+		t.Fatalf("Failed to withdraw: %v", err)
+	}
+	if n := largeEvents(); n != 2 {
+		t.Errorf("Expected a large transaction event for the withdrawal, got %d", n)
+	}
+
+	// The routine events are still recorded
+	var deposits int
+	svc.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM audit_events WHERE type = $1 AND player_id = $2",
+		audit.EventDeposit, playerID).Scan(&deposits)
+	if deposits != 2 {
+		t.Errorf("Expected 2 deposit events, got %d", deposits)
+	}
+}
+
+func TestParseDepositBounds(t *testing.T) {
+	bounds, err := ParseDepositBounds(
+		map[string]string{"USD": "10", "JPY": "1000"},
+		map[string]string{"USD": "10000.00", "EUR": "5000"},
+	)
+	if err != nil {
+		t.Fatalf("Failed to parse bounds: %v", err)
+	}
+	want := map[string]DepositBounds{
+		"USD": {Min: 1000, Max: 1000000},
+		"JPY": {Min: 1000},
+		"EUR": {Max: 500000},
+	}
+	for currency, b := range want {
+		if bounds[currency] != b {
+			t.Errorf("Expected %s bounds %+v, got %+v", currency, b, bounds[currency])
+		}
+	}
+
+	if _, err := ParseDepositBounds(map[string]string{"USD": "ten"}, nil); !errors.Is(err, domain.ErrInvalidMoney) {
+		t.Errorf("Expected ErrInvalidMoney for a malformed amount, got %v", err)
+	}
+	if _, err := ParseDepositBounds(map[string]string{"USD": "100"}, map[string]string{"USD": "50"}); err == nil {
+		t.Error("Expected an error for a minimum above the maximum")
+	}
+}
+
 func TestWithdraw(t *testing.T) {
 	svc, playerID, cleanup := setupTestWallet(t)
 	defer cleanup()
@@ -196,6 +378,29 @@ func TestPlaceWager(t *testing.T) {
 			t.Error("Expected insufficient funds error")
 		}
 	})
+
+	t.Run("SpendsBonusAfterRealMoney", func(t *testing.T) {
+		if _, err := svc.GrantBonus(ctx, playerID, domain.NewMoney(20.00, "USD"), 1, "welcome"); err != nil {
+			t.Fatalf("Grant failed: %v", err)
+		}
+
+		result, err := svc.PlaceWager(ctx, playerID, domain.NewMoney(100.00, "USD"), "game-1", "cycle-3")
+		if err != nil {
+			t.Fatalf("Wager failed: %v", err)
+		}
+		if result.BalanceAfter.Amount != 0 {
+			t.Errorf("Expected real money spent, got %f", result.BalanceAfter.Float64())
+		}
+
+		balance, _ := svc.GetBalance(ctx, playerID)
+		if balance.RealMoney.Amount != 0 || balance.BonusBalance.Float64() != 10.00 {
+			t.Errorf("Expected real 0.00 and bonus 10.00, got %f / %f",
+				balance.RealMoney.Float64(), balance.BonusBalance.Float64())
+		}
+		if balance.WageringRemaining.Amount != 0 {
+			t.Errorf("Expected the requirement met, got %f", balance.WageringRemaining.Float64())
+		}
+	})
 }
 
 func TestCreditWin(t *testing.T) {
@@ -267,7 +472,7 @@ func TestSequentialWithdrawals(t *testing.T) {
 	t.Run("MultipleWithdrawals", func(t *testing.T) {
 		// Make 5 sequential withdrawals of $100
 		for i := 0; i < 5; i++ {
-			_, err := svc.Withdraw(ctx, playerID, domain.NewMoney(100.00, "USD"), "withdraw")
+			_, err := svc.Withdraw(ctx, playerID, domain.NewMoney(100.00, "USD"), fmt.Sprintf("withdraw-%d", i))
 			if err != nil {
 				t.Fatalf("Withdrawal %d failed: %v", i+1, err)
 			}
@@ -289,3 +494,540 @@ func TestSequentialWithdrawals(t *testing.T) {
 		}
 	})
 }
+
+func TestBonusWagering(t *testing.T) {
+	svc, playerID, cleanup := setupTestWallet(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	svc.Deposit(ctx, playerID, domain.NewMoney(50.00, "USD"), "initial")
+
+	t.Run("GrantBonus", func(t *testing.T) {
+		tx, err := svc.GrantBonus(ctx, playerID, domain.NewMoney(20.00, "USD"), 2, "welcome")
+		if err != nil {
+			t.Fatalf("Grant failed: %v", err)
+		}
+		if tx.Type != domain.TxTypeBonus {
+			t.Errorf("Expected bonus transaction, got %s", tx.Type)
+		}
+
+		balance, _ := svc.GetBalance(ctx, playerID)
+		if balance.BonusBalance.Float64() != 20.00 || balance.WageringRemaining.Float64() != 40.00 {
+			t.Errorf("Expected bonus 20.00 with 40.00 to wager, got %f / %f",
+				balance.BonusBalance.Float64(), balance.WageringRemaining.Float64())
+		}
+		if balance.Available.Float64() != 70.00 {
+			t.Errorf("Expected available 70.00, got %f", balance.Available.Float64())
+		}
+	})
+
+	t.Run("ConvertBeforeRequirementMet", func(t *testing.T) {
+		if _, err := svc.ConvertBonus(ctx, playerID); err != ErrWageringIncomplete {
+			t.Errorf("Expected ErrWageringIncomplete, got %v", err)
+		}
+	})
+
+	t.Run("WagerSpendsBonusFirst", func(t *testing.T) {
+		_, err := svc.PlaceWager(ctx, playerID, domain.NewMoney(5.00, "USD"), "game-1", "cycle-1")
+		if err != nil {
+			t.Fatalf("Wager failed: %v", err)
+		}
+
+		balance, _ := svc.GetBalance(ctx, playerID)
+		if balance.BonusBalance.Float64() != 15.00 || balance.RealMoney.Float64() != 50.00 {
+			t.Errorf("Expected bonus 15.00 and real 50.00, got %f / %f",
+				balance.BonusBalance.Float64(), balance.RealMoney.Float64())
+		}
+		if balance.WageringRemaining.Float64() != 35.00 {
+			t.Errorf("Expected 35.00 left to wager, got %f", balance.WageringRemaining.Float64())
+		}
+	})
+
+	t.Run("RealFirstPolicy", func(t *testing.T) {
+		svc.SetBonusPolicy(BonusPolicyRealFirst)
+		defer svc.SetBonusPolicy(BonusPolicyBonusFirst)
+
+		_, err := svc.PlaceWager(ctx, playerID, domain.NewMoney(30.00, "USD"), "game-1", "cycle-2")
+		if err != nil {
+			t.Fatalf("Wager failed: %v", err)
+		}
+
+		balance, _ := svc.GetBalance(ctx, playerID)
+		if balance.BonusBalance.Float64() != 15.00 || balance.RealMoney.Float64() != 20.00 {
+			t.Errorf("Expected bonus 15.00 and real 20.00, got %f / %f",
+				balance.BonusBalance.Float64(), balance.RealMoney.Float64())
+		}
+	})
+
+	t.Run("ConversionWhenRequirementMet", func(t *testing.T) {
+		// 5.00 of the 35.00 requirement remains; this wager completes it
+		_, err := svc.PlaceWager(ctx, playerID, domain.NewMoney(5.00, "USD"), "game-1", "cycle-3")
+		if err != nil {
+			t.Fatalf("Wager failed: %v", err)
+		}
+
+		balance, _ := svc.GetBalance(ctx, playerID)
+		if balance.BonusBalance.Amount != 0 || balance.WageringRemaining.Amount != 0 {
+			t.Errorf("Expected bonus converted, got bonus %f / wagering %f",
+				balance.BonusBalance.Float64(), balance.WageringRemaining.Float64())
+		}
+		// 20.00 real + (15.00 - 5.00) bonus converted
+		if balance.RealMoney.Float64() != 30.00 {
+			t.Errorf("Expected real 30.00 after conversion, got %f", balance.RealMoney.Float64())
+		}
+
+		txs, _ := svc.GetTransactions(ctx, playerID, 1)
+		if len(txs) != 1 || txs[0].Type != domain.TxTypeBonus {
+			t.Error("Expected conversion recorded as a bonus transaction")
+		}
+	})
+}
+
+func TestBonusWinsAndGambles(t *testing.T) {
+	svc, playerID, cleanup := setupTestWallet(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	svc.Deposit(ctx, playerID, domain.NewMoney(50.00, "USD"), "initial")
+	if _, err := svc.GrantBonus(ctx, playerID, domain.NewMoney(20.00, "USD"), 2, "welcome"); err != nil {
+		t.Fatalf("Grant failed: %v", err)
+	}
+
+	expect := func(t *testing.T, real, bonus, wagering float64) {
+		t.Helper()
+		balance, err := svc.GetBalance(ctx, playerID)
+		if err != nil {
+			t.Fatalf("Failed to get balance: %v", err)
+		}
+		if balance.RealMoney.Float64() != real || balance.BonusBalance.Float64() != bonus || balance.WageringRemaining.Float64() != wagering {
+			t.Errorf("Expected real %.2f, bonus %.2f, wagering %.2f; got %f / %f / %f", real, bonus, wagering,
+				balance.RealMoney.Float64(), balance.BonusBalance.Float64(), balance.WageringRemaining.Float64())
+		}
+	}
+	gamble := func(t *testing.T, stake float64, won bool, step int) {
+		t.Helper()
+		err := database.WithTx(ctx, svc.db, func(dbTx *sql.Tx) error {
+			_, err := svc.GambleTx(ctx, dbTx, playerID, domain.NewMoney(stake, "USD"), won, "game-1", "cycle-1", step)
+			return err
+		})
+		if err != nil {
+			t.Fatalf("Gamble failed: %v", err)
+		}
+	}
+
+	t.Run("WinOnBonusStakeStaysBonus", func(t *testing.T) {
+		if _, err := svc.PlaceWager(ctx, playerID, domain.NewMoney(10.00, "USD"), "game-1", "cycle-1"); err != nil {
+			t.Fatalf("Wager failed: %v", err)
+		}
+		tx, err := svc.CreditWin(ctx, playerID, domain.NewMoney(20.00, "USD"), "game-1", "cycle-1")
+		if err != nil {
+			t.Fatalf("Win failed: %v", err)
+		}
+		if tx.BonusAmount != 2000 {
+			t.Errorf("Expected the whole win credited to bonus, got %d", tx.BonusAmount)
+		}
+		expect(t, 50.00, 30.00, 30.00)
+	})
+
+	t.Run("GambleNotCountedTowardWagering", func(t *testing.T) {
+		gamble(t, 20.00, true, 1)
+		expect(t, 50.00, 50.00, 30.00)
+
+		gamble(t, 40.00, false, 2)
+		expect(t, 50.00, 10.00, 30.00)
+	})
+
+	t.Run("RefundReturnsBonusStake", func(t *testing.T) {
+		if _, err := svc.PlaceWager(ctx, playerID, domain.NewMoney(10.00, "USD"), "game-1", "cycle-2"); err != nil {
+			t.Fatalf("Wager failed: %v", err)
+		}
+		if _, err := svc.RefundWager(ctx, playerID, domain.NewMoney(10.00, "USD"), "game-1", "cycle-2"); err != nil {
+			t.Fatalf("Refund failed: %v", err)
+		}
+		expect(t, 50.00, 10.00, 20.00)
+	})
+}
+
+func TestConcurrentWagersAndWins(t *testing.T) {
+	svc, playerID, cleanup := setupTestWallet(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	svc.Deposit(ctx, playerID, domain.NewMoney(100.00, "USD"), "initial")
+
+	const rounds = 50
+	var wg sync.WaitGroup
+	errs := make(chan error, 2*rounds)
+
+	for i := 0; i < rounds; i++ {
+		cycleID := fmt.Sprintf("cycle-%d", i)
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if _, err := svc.PlaceWager(ctx, playerID, domain.NewMoney(1.00, "USD"), "game-1", cycleID); err != nil {
+				errs <- err
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			if _, err := svc.CreditWin(ctx, playerID, domain.NewMoney(0.50, "USD"), "game-1", cycleID); err != nil {
+				errs <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Errorf("Concurrent operation failed: %v", err)
+	}
+
+	// 100.00 - 50 * 1.00 + 50 * 0.50 = 75.00
+	balance, err := svc.GetBalance(ctx, playerID)
+	if err != nil {
+		t.Fatalf("Failed to get balance: %v", err)
+	}
+	if balance.RealMoney.Amount != 7500 {
+		t.Errorf("Expected final balance 7500, got %d", balance.RealMoney.Amount)
+	}
+}
+
+func TestIdempotentWager(t *testing.T) {
+	svc, playerID, cleanup := setupTestWallet(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	svc.Deposit(ctx, playerID, domain.NewMoney(100.00, "USD"), "initial")
+
+	t.Run("RetriedWagerMovesMoneyOnce", func(t *testing.T) {
+		first, err := svc.PlaceWager(ctx, playerID, domain.NewMoney(10.00, "USD"), "game-1", "cycle-1")
+		if err != nil {
+			t.Fatalf("Wager failed: %v", err)
+		}
+		retry, err := svc.PlaceWager(ctx, playerID, domain.NewMoney(10.00, "USD"), "game-1", "cycle-1")
+		if err != nil {
+			t.Fatalf("Retried wager failed: %v", err)
+		}
+
+		if retry.ID != first.ID {
+			t.Errorf("Expected retry to return transaction %s, got %s", first.ID, retry.ID)
+		}
+
+		balance, _ := svc.GetBalance(ctx, playerID)
+		if balance.RealMoney.Float64() != 90.00 {
+			t.Errorf("Expected balance 90.00, got %f", balance.RealMoney.Float64())
+		}
+	})
+
+	t.Run("ConflictingAmount", func(t *testing.T) {
+		_, err := svc.PlaceWager(ctx, playerID, domain.NewMoney(20.00, "USD"), "game-1", "cycle-1")
+		if err != ErrDuplicateTransaction {
+			t.Errorf("Expected ErrDuplicateTransaction, got %v", err)
+		}
+	})
+
+	t.Run("DepositWithKey", func(t *testing.T) {
+		keyed := WithIdempotencyKey(ctx, "deposit-key-1")
+		svc.Deposit(keyed, playerID, domain.NewMoney(5.00, "USD"), "retry")
+		svc.Deposit(keyed, playerID, domain.NewMoney(5.00, "USD"), "retry")
+
+		balance, _ := svc.GetBalance(ctx, playerID)
+		if balance.RealMoney.Float64() != 95.00 {
+			t.Errorf("Expected balance 95.00, got %f", balance.RealMoney.Float64())
+		}
+	})
+}
+
+func TestDepositReference(t *testing.T) {
+	svc, playerID, cleanup := setupTestWallet(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	first, err := svc.Deposit(ctx, playerID, domain.NewMoney(20.00, "USD"), "psp-1001")
+	if err != nil {
+		t.Fatalf("Failed to deposit: %v", err)
+	}
+
+	t.Run("DuplicateReturnsExisting", func(t *testing.T) {
+		retried, err := svc.Deposit(ctx, playerID, domain.NewMoney(20.00, "USD"), "psp-1001")
+		if err != nil {
+			t.Fatalf("Expected retried deposit to succeed, got %v", err)
+		}
+		if retried.ID != first.ID {
+			t.Errorf("Expected the original transaction %s, got %s", first.ID, retried.ID)
+		}
+
+		balance, _ := svc.GetBalance(ctx, playerID)
+		if balance.RealMoney.Amount != 2000 {
+			t.Errorf("Expected the deposit applied once, got balance %d", balance.RealMoney.Amount)
+		}
+	})
+
+	t.Run("ConflictingAmount", func(t *testing.T) {
+		if _, err := svc.Deposit(ctx, playerID, domain.NewMoney(30.00, "USD"), "psp-1001"); err != ErrDuplicateReference {
+			t.Errorf("Expected ErrDuplicateReference, got %v", err)
+		}
+	})
+
+	t.Run("ScopedByType", func(t *testing.T) {
+		withdrawal, err := svc.Withdraw(ctx, playerID, domain.NewMoney(5.00, "USD"), "psp-1001")
+		if err != nil {
+			t.Fatalf("Expected a withdrawal with the deposit's reference to succeed, got %v", err)
+		}
+		if retried, err := svc.Withdraw(ctx, playerID, domain.NewMoney(5.00, "USD"), "psp-1001"); err != nil || retried.ID != withdrawal.ID {
+			t.Errorf("Expected the original withdrawal, got %v (%v)", retried, err)
+		}
+
+		balance, _ := svc.GetBalance(ctx, playerID)
+		if balance.RealMoney.Amount != 1500 {
+			t.Errorf("Expected balance 1500, got %d", balance.RealMoney.Amount)
+		}
+	})
+
+	t.Run("EmptyReferenceNotDeduplicated", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			if _, err := svc.Deposit(ctx, playerID, domain.NewMoney(1.00, "USD"), ""); err != nil {
+				t.Fatalf("Deposit %d failed: %v", i+1, err)
+			}
+		}
+		balance, _ := svc.GetBalance(ctx, playerID)
+		if balance.RealMoney.Amount != 1700 {
+			t.Errorf("Expected both deposits applied, got balance %d", balance.RealMoney.Amount)
+		}
+	})
+
+	t.Run("Lookup", func(t *testing.T) {
+		found, err := svc.GetTransactionByReference(ctx, playerID, domain.TxTypeDeposit, "psp-1001")
+		if err != nil {
+			t.Fatalf("Failed to look up deposit: %v", err)
+		}
+		if found.ID != first.ID || found.Amount.Amount != 2000 || found.Reference != "psp-1001" {
+			t.Errorf("Expected the original deposit, got %+v", found)
+		}
+
+		if _, err := svc.GetTransactionByReference(ctx, playerID, domain.TxTypeDeposit, "psp-unknown"); err != ErrTransactionNotFound {
+			t.Errorf("Expected ErrTransactionNotFound, got %v", err)
+		}
+		if _, err := svc.GetTransactionByReference(ctx, uuid.New().String(), domain.TxTypeDeposit, "psp-1001"); err != ErrTransactionNotFound {
+			t.Errorf("Expected another player's lookup to find nothing, got %v", err)
+		}
+	})
+}
+
+func TestRefundWager(t *testing.T) {
+	svc, playerID, cleanup := setupTestWallet(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	svc.Deposit(ctx, playerID, domain.NewMoney(100.00, "USD"), "initial")
+	svc.PlaceWager(ctx, playerID, domain.NewMoney(10.00, "USD"), "game-1", "cycle-1")
+
+	t.Run("RefundRecorded", func(t *testing.T) {
+		tx, err := svc.RefundWager(ctx, playerID, domain.NewMoney(10.00, "USD"), "game-1", "cycle-1")
+		if err != nil {
+			t.Fatalf("Refund failed: %v", err)
+		}
+
+		if tx.Type != domain.TxTypeRefund {
+			t.Errorf("Expected refund transaction, got %s", tx.Type)
+		}
+		if tx.BalanceBefore.Float64() != 90.00 || tx.BalanceAfter.Float64() != 100.00 {
+			t.Errorf("Expected balance 90.00 -> 100.00, got %f -> %f",
+				tx.BalanceBefore.Float64(), tx.BalanceAfter.Float64())
+		}
+
+		txs, _ := svc.GetTransactions(ctx, playerID, 1)
+		if len(txs) != 1 || txs[0].ID != tx.ID {
+			t.Error("Expected refund in transaction history")
+		}
+	})
+
+	t.Run("RefundedOnce", func(t *testing.T) {
+		svc.RefundWager(ctx, playerID, domain.NewMoney(10.00, "USD"), "game-1", "cycle-1")
+
+		balance, _ := svc.GetBalance(ctx, playerID)
+		if balance.RealMoney.Float64() != 100.00 {
+			t.Errorf("Expected balance 100.00, got %f", balance.RealMoney.Float64())
+		}
+	})
+}
+
+func TestGambleLossNotCovered(t *testing.T) {
+	svc, playerID, cleanup := setupTestWallet(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	// Part of the win has been withdrawn before the flip is lost
+	svc.Deposit(ctx, playerID, domain.NewMoney(50.00, "USD"), "initial")
+	svc.Withdraw(ctx, playerID, domain.NewMoney(30.00, "USD"), "cashout")
+
+	var tx *domain.Transaction
+	err := database.WithTx(ctx, svc.db, func(dbTx *sql.Tx) (err error) {
+		tx, err = svc.GambleTx(ctx, dbTx, playerID, domain.NewMoney(50.00, "USD"), false, "game-1", "cycle-1", 1)
+		return err
+	})
+	if err != ErrInsufficientFunds || tx != nil {
+		t.Errorf("Expected ErrInsufficientFunds for a loss not held, got %v (%v)", tx, err)
+	}
+
+	balance, _ := svc.GetBalance(ctx, playerID)
+	if balance.RealMoney.Float64() != 20.00 {
+		t.Errorf("Expected balance 20.00 untouched, got %f", balance.RealMoney.Float64())
+	}
+}
+
+func TestGetTransactionsFiltered(t *testing.T) {
+	svc, playerID, cleanup := setupTestWallet(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	svc.Deposit(ctx, playerID, domain.NewMoney(100.00, "USD"), "deposit-1")
+	for i := 0; i < 5; i++ {
+		svc.PlaceWager(ctx, playerID, domain.NewMoney(1.00, "USD"), "game-1", fmt.Sprintf("cycle-%d", i))
+	}
+	svc.CreditWin(ctx, playerID, domain.NewMoney(3.00, "USD"), "game-1", "cycle-0")
+	svc.Withdraw(ctx, playerID, domain.NewMoney(10.00, "USD"), "withdraw-1")
+
+	t.Run("TypeFilter", func(t *testing.T) {
+		page, err := svc.GetTransactionsFiltered(ctx, playerID, TransactionFilter{
+			Types: []domain.TransactionType{domain.TxTypeWager},
+		})
+		if err != nil {
+			t.Fatalf("Failed to get transactions: %v", err)
+		}
+		if page.Total != 5 || len(page.Transactions) != 5 {
+			t.Errorf("Expected 5 wagers, got total %d, page %d", page.Total, len(page.Transactions))
+		}
+		for _, tx := range page.Transactions {
+			if tx.Type != domain.TxTypeWager {
+				t.Errorf("Expected only wagers, got %s", tx.Type)
+			}
+		}
+	})
+
+	t.Run("MultipleTypes", func(t *testing.T) {
+		page, _ := svc.GetTransactionsFiltered(ctx, playerID, TransactionFilter{
+			Types: []domain.TransactionType{domain.TxTypeDeposit, domain.TxTypeWithdrawal},
+		})
+		if page.Total != 2 {
+			t.Errorf("Expected 2 deposits and withdrawals, got %d", page.Total)
+		}
+	})
+
+	// Spread the wagers over five consecutive days, oldest first
+	base := time.Now().UTC().Truncate(time.Hour).Add(-10 * 24 * time.Hour)
+	for i := 0; i < 5; i++ {
+		svc.db.ExecContext(ctx, `UPDATE transactions SET created_at = $1 WHERE player_id = $2 AND type = 'wager' AND reference = $3`,
+			base.Add(time.Duration(i)*24*time.Hour), playerID, fmt.Sprintf("cycle-%d", i))
+	}
+
+	t.Run("DateRangePaging", func(t *testing.T) {
+		// Days 1-3 inclusive, two per page
+		filter := TransactionFilter{
+			Types:  []domain.TransactionType{domain.TxTypeWager},
+			From:   base.Add(24 * time.Hour),
+			To:     base.Add(4 * 24 * time.Hour),
+			Limit:  2,
+			Offset: 0,
+		}
+
+		first, err := svc.GetTransactionsFiltered(ctx, playerID, filter)
+		if err != nil {
+			t.Fatalf("Failed to get first page: %v", err)
+		}
+		if first.Total != 3 || len(first.Transactions) != 2 {
+			t.Fatalf("Expected total 3 with 2 on the first page, got %d / %d", first.Total, len(first.Transactions))
+		}
+		if first.Transactions[0].Reference != "cycle-3" || first.Transactions[1].Reference != "cycle-2" {
+			t.Errorf("Expected newest first, got %s, %s", first.Transactions[0].Reference, first.Transactions[1].Reference)
+		}
+
+		filter.Offset = 2
+		second, _ := svc.GetTransactionsFiltered(ctx, playerID, filter)
+		if len(second.Transactions) != 1 || second.Transactions[0].Reference != "cycle-1" {
+			t.Errorf("Expected cycle-1 alone on the second page, got %d transactions", len(second.Transactions))
+		}
+	})
+
+	t.Run("ExistingMethodUnchanged", func(t *testing.T) {
+		txs, err := svc.GetTransactions(ctx, playerID, 50)
+		if err != nil || len(txs) != 8 {
+			t.Errorf("Expected all 8 transactions, got %d (%v)", len(txs), err)
+		}
+	})
+}
+
+func TestMultiCurrencyAccounts(t *testing.T) {
+	svc, usdPlayer, cleanup := setupTestWallet(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	// Create a second player with a EUR account
+	eurPlayer := uuid.New().String()
+	_, err := svc.db.Exec(`
+		INSERT INTO players (id, username, email, password_hash, status, registration_date, tc_accepted_at, created_at, updated_at)
+		VALUES ($1, 'eurplayer', 'eur@example.com', 'hash', 'active', NOW(), NOW(), NOW(), NOW())
+	`, eurPlayer)
+	if err != nil {
+		t.Fatalf("Failed to create EUR player: %v", err)
+	}
+	_, err = svc.db.Exec(`
+		INSERT INTO balances (player_id, real_money_amount, real_money_currency, bonus_amount, bonus_currency, updated_at)
+		VALUES ($1, 0, 'EUR', 0, 'EUR', NOW())
+	`, eurPlayer)
+	if err != nil {
+		t.Fatalf("Failed to create EUR balance: %v", err)
+	}
+
+	t.Run("BalanceInAccountCurrency", func(t *testing.T) {
+		balance, err := svc.GetBalance(ctx, eurPlayer)
+		if err != nil {
+			t.Fatalf("Failed to get balance: %v", err)
+		}
+		if balance.Currency != "EUR" || balance.Available.Currency != "EUR" {
+			t.Errorf("Expected EUR balance, got %s", balance.Currency)
+		}
+	})
+
+	t.Run("MatchingCurrency", func(t *testing.T) {
+		if _, err := svc.Deposit(ctx, eurPlayer, domain.NewMoney(50.00, "EUR"), "eur-deposit"); err != nil {
+			t.Fatalf("EUR deposit failed: %v", err)
+		}
+		if _, err := svc.Deposit(ctx, usdPlayer, domain.NewMoney(50.00, "USD"), "usd-deposit"); err != nil {
+			t.Fatalf("USD deposit failed: %v", err)
+		}
+		tx, err := svc.PlaceWager(ctx, eurPlayer, domain.NewMoney(5.00, "EUR"), "game-1", "cycle-1")
+		if err != nil {
+			t.Fatalf("EUR wager failed: %v", err)
+		}
+		if tx.BalanceAfter.Currency != "EUR" || tx.BalanceAfter.Float64() != 45.00 {
+			t.Errorf("Expected 45.00 EUR, got %f %s", tx.BalanceAfter.Float64(), tx.BalanceAfter.Currency)
+		}
+	})
+
+	t.Run("MismatchedCurrencyRejected", func(t *testing.T) {
+		if _, err := svc.Deposit(ctx, eurPlayer, domain.NewMoney(10.00, "USD"), "wrong"); err != ErrCurrencyMismatch {
+			t.Errorf("Expected ErrCurrencyMismatch on deposit, got %v", err)
+		}
+		if _, err := svc.Withdraw(ctx, usdPlayer, domain.NewMoney(10.00, "EUR"), "wrong"); err != ErrCurrencyMismatch {
+			t.Errorf("Expected ErrCurrencyMismatch on withdrawal, got %v", err)
+		}
+		if _, err := svc.PlaceWager(ctx, eurPlayer, domain.NewMoney(1.00, "USD"), "game-1", "cycle-2"); err != ErrCurrencyMismatch {
+			t.Errorf("Expected ErrCurrencyMismatch on wager, got %v", err)
+		}
+
+		balance, _ := svc.GetBalance(ctx, eurPlayer)
+		if balance.RealMoney.Float64() != 45.00 {
+			t.Errorf("Expected EUR balance unchanged at 45.00, got %f", balance.RealMoney.Float64())
+		}
+	})
+}
@@ -9,19 +9,22 @@ import (
 	"context"
 	"fmt"
 	"log"
-	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
-	"time"
 
 	"github.com/alexbotov/rgs/internal/api"
 	"github.com/alexbotov/rgs/internal/audit"
 	"github.com/alexbotov/rgs/internal/auth"
 	"github.com/alexbotov/rgs/internal/config"
+	"github.com/alexbotov/rgs/internal/control"
 	"github.com/alexbotov/rgs/internal/database"
+	"github.com/alexbotov/rgs/internal/events"
 	"github.com/alexbotov/rgs/internal/game"
+	"github.com/alexbotov/rgs/internal/limits"
+	"github.com/alexbotov/rgs/internal/realitycheck"
 	"github.com/alexbotov/rgs/internal/rng"
+	"github.com/alexbotov/rgs/internal/server"
 	"github.com/alexbotov/rgs/internal/wallet"
 	"github.com/alexbotov/rgs/pkg/pateplay"
 )
@@ -35,7 +38,10 @@ func main() {
 	log.Printf("Configuration loaded (port: %s, db: %s)", cfg.Server.Port, cfg.Database.DSN)
 
 	// Initialize database
-	db, err := database.New(cfg.Database.Driver, cfg.Database.DSN)
+	db, err := database.New(cfg.Database.Driver, cfg.Database.DSN,
+		database.WithMaxOpenConns(cfg.Database.MaxOpenConns),
+		database.WithMaxIdleConns(cfg.Database.MaxIdleConns),
+		database.WithConnMaxLifetime(cfg.Database.ConnMaxLifetime))
 	if err != nil {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
@@ -60,6 +66,17 @@ func main() {
 	}
 	log.Printf("✓ RNG service initialized (Chi-Square: %.2f, Passed: %v)", rngHealth.ChiSquare, rngHealth.ChiSquarePassed)
 
+	// Periodically re-open the entropy source (GLI-19 §3.3.2) and self-test
+	// the output (GLI-19 §3.3.3)
+	rngCtx, stopRNG := context.WithCancel(context.Background())
+	defer stopRNG()
+	if cfg.Game.RNGReseedInterval > 0 {
+		go rngSvc.RunReseeder(rngCtx, cfg.Game.RNGReseedInterval)
+	}
+	if cfg.Game.RNGMonitorInterval > 0 {
+		go rngSvc.RunMonitor(rngCtx, cfg.Game.RNGMonitorInterval)
+	}
+
 	pateplayClient := pateplay.NewClient(&pateplay.ClientConfig{
 		BaseURL:   "https://api.pateplay.com",
 		APIKey:    "test-api-key",
@@ -69,24 +86,94 @@ func main() {
 	authSvc := auth.New(db.DB, &cfg.Auth, auditSvc, pateplayClient)
 	log.Println("✓ Auth service initialized")
 
+	// Player events pushed to open WebSockets
+	eventHub := events.New()
+
 	walletSvc := wallet.New(db.DB, auditSvc, cfg.Game.DefaultCurrency)
+	walletSvc.SetEvents(eventHub)
+	depositBounds, err := wallet.ParseDepositBounds(cfg.Game.MinDeposit, cfg.Game.MaxDeposit)
+	if err != nil {
+		log.Fatalf("Invalid deposit bounds: %v", err)
+	}
+	walletSvc.SetDepositBounds(depositBounds)
+	walletSvc.SetLargeTransactionThreshold(cfg.Game.LargeTransactionAmount)
 	log.Println("✓ Wallet service initialized")
 
-	gameEngine := game.New(db.DB, rngSvc, walletSvc, auditSvc, cfg.Game.DefaultCurrency)
+	limitsSvc := limits.New(db.DB, auditSvc, cfg.Game.DefaultCurrency)
+	limitsSvc.SetMinExclusionPeriod(cfg.Game.MinExclusionPeriod)
+	authSvc.SetLimits(limitsSvc)
+	walletSvc.SetLimits(limitsSvc)
+	log.Println("✓ Limits service initialized")
+
+	// Reality checks after each interval of play (GLI-19 §2.5.5)
+	realityCheckSvc := realitycheck.New(db.DB, auditSvc, eventHub, cfg.Game.RealityCheckInterval)
+
+	gameEngine := game.New(db.DB, rngSvc, walletSvc, limitsSvc, auditSvc, cfg.Game.DefaultCurrency)
+	if cfg.Game.DefinitionsFile != "" {
+		defs, err := game.LoadGameDefinitions(cfg.Game.DefinitionsFile)
+		if err != nil {
+			log.Fatalf("Failed to load game definitions: %v", err)
+		}
+		if err := gameEngine.LoadGames(defs); err != nil {
+			log.Fatalf("Failed to register games: %v", err)
+		}
+	}
+	if err := gameEngine.LoadRTPVariants(context.Background()); err != nil {
+		log.Fatalf("Failed to load RTP variants: %v", err)
+	}
+	gameEngine.SetRecordSeeds(cfg.Game.RecordRNGSeeds)
+	gameEngine.SetCertificationMode(cfg.Game.CertificationMode)
+	gameEngine.SetSessionConflictPolicy(game.SessionConflictPolicy(cfg.Game.SessionConflictPolicy))
+	gameEngine.SetLargeWinThreshold(game.LargeWinThreshold{
+		Amount:   cfg.Game.LargeWinAmount,
+		Multiple: cfg.Game.LargeWinMultiple,
+	})
+
+	// Operator controls (GLI-19 §2.4)
+	controlSvc := control.New(db.DB, auditSvc, authSvc)
+	if err := controlSvc.LoadState(context.Background()); err != nil {
+		log.Fatalf("Failed to load control state: %v", err)
+	}
+	gameEngine.SetControl(controlSvc)
+	gameEngine.SetRealityCheck(realityCheckSvc)
+	gameEngine.SetEvents(eventHub)
+	gameEngine.SetPateplay(pateplayClient)
 	log.Printf("✓ Game engine initialized (%d games available)", len(gameEngine.GetGames()))
 
+	// Mark cycles left in progress as interrupted (GLI-19 §4.16)
+	sweepCtx, stopSweep := context.WithCancel(context.Background())
+	defer stopSweep()
+	go gameEngine.RunStaleCycleSweeper(sweepCtx, cfg.Game.StaleCycleSweepInterval, cfg.Game.StaleCycleTimeout)
+
+	// Lift self-exclusions that have run out (GLI-19 §2.5.5.c)
+	go limitsSvc.RunExclusionExpirer(sweepCtx, cfg.Game.ExclusionExpiryInterval)
+
 	// Initialize API handlers
 	handler := api.New(authSvc, walletSvc, gameEngine, rngSvc)
-	router := handler.SetupRouter()
-	log.Println("✓ API routes configured")
+	handler.SetBalanceUpdateInterval(cfg.Game.BalanceUpdateInterval)
+	handler.SetLocale(cfg.Game.Locale)
+	handler.SetQueryTimeout(cfg.Database.QueryTimeout)
+	handler.SetEvents(eventHub)
+	handler.SetAudit(auditSvc)
+	handler.SetLimits(limitsSvc)
+	handler.SetControl(controlSvc)
+	handler.SetRealityCheck(realityCheckSvc)
+	handler.SetDatabase(db)
+	if err := handler.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		log.Fatalf("Failed to configure trusted proxies: %v", err)
+	}
+	if cfg.Server.HealthCheckPateplay {
+		handler.SetPateplay(pateplayClient)
+	}
+	handler.SetRateLimits(
+		api.RateLimit{Rate: cfg.RateLimit.IPRate, Burst: cfg.RateLimit.IPBurst},
+		api.RateLimit{Rate: cfg.RateLimit.ProtectedIPRate, Burst: cfg.RateLimit.ProtectedIPBurst},
+		api.RateLimit{Rate: cfg.RateLimit.PlayerRate, Burst: cfg.RateLimit.PlayerBurst},
+	)
 
 	// Create HTTP server
-	server := &http.Server{
-		Addr:         ":" + cfg.Server.Port,
-		Handler:      router,
-		ReadTimeout:  cfg.Server.ReadTimeout,
-		WriteTimeout: cfg.Server.WriteTimeout,
-	}
+	srv := server.New(&cfg.Server, handler, gameEngine, auditSvc)
+	log.Println("✓ API routes configured")
 
 	// Start server in goroutine
 	go func() {
@@ -95,7 +182,7 @@ func main() {
 		printEndpoints(cfg.Server.Port)
 		log.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
 
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		if err := srv.ListenAndServe(); err != nil {
 			log.Fatalf("Server error: %v", err)
 		}
 	}()
@@ -114,14 +201,18 @@ func main() {
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 	log.Println("\nShutdown signal received...")
+	stopSweep()
+	stopRNG()
 
-	// Graceful shutdown with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	// Graceful shutdown: finish game cycles in progress, close WebSockets,
+	// then drain HTTP requests
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
 	defer cancel()
 
-	if err := server.Shutdown(ctx); err != nil {
+	if err := srv.Shutdown(ctx); err != nil {
 		log.Printf("Server forced to shutdown: %v", err)
 	}
+	pateplayClient.Close()
 
 	// Log shutdown event
 	auditSvc.Log(context.Background(), "system_shutdown", "info",
@@ -173,6 +264,23 @@ func printEndpoints(port string) {
 	log.Printf("    POST http://localhost:%s/api/v1/games/{id}/session  Start session", port)
 	log.Printf("    POST http://localhost:%s/api/v1/games/play          Play game", port)
 	log.Printf("    GET  http://localhost:%s/api/v1/games/history       Game history", port)
+	log.Printf("    GET  http://localhost:%s/api/v1/games/history/{id}  Game recall", port)
+	log.Printf("    GET  http://localhost:%s/api/v1/games/interrupted   Interrupted games", port)
+	log.Printf("    POST http://localhost:%s/api/v1/games/{id}/resume   Resume interrupted game", port)
+	log.Printf("    POST http://localhost:%s/api/v1/games/{id}/void     Void interrupted game", port)
+	log.Println("")
+	log.Println("  Limits:")
+	log.Printf("    GET  http://localhost:%s/api/v1/limits              Current limits", port)
+	log.Printf("    POST http://localhost:%s/api/v1/limits/deposit      Set deposit limit", port)
+	log.Printf("    POST http://localhost:%s/api/v1/limits/wager        Set wager limit", port)
+	log.Printf("    POST http://localhost:%s/api/v1/limits/loss         Set loss limit", port)
+	log.Printf("    POST http://localhost:%s/api/v1/limits/self-exclude Self-exclude", port)
+	log.Println("")
+	log.Println("  Admin:")
+	log.Printf("    GET  http://localhost:%s/api/v1/admin/status              System status", port)
+	log.Printf("    POST http://localhost:%s/api/v1/admin/gaming/disable      Disable all gaming", port)
+	log.Printf("    POST http://localhost:%s/api/v1/admin/games/{id}/disable  Disable a game", port)
+	log.Printf("    POST http://localhost:%s/api/v1/admin/players/{id}/disable Disable a player", port)
 	log.Println("")
 	log.Println("  WebSocket:")
 	log.Printf("    WS   ws://localhost:%s/api/v1/ws/game/{session_id}  Real-time game", port)
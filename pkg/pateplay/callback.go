@@ -0,0 +1,63 @@
+package pateplay
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// HeaderHMAC carries the hex HMAC-SHA256 of the request body, keyed with the
+// API secret, on requests in both directions
+const HeaderHMAC = "x-api-hmac"
+
+// maxCallbackBody bounds the body VerifyingMiddleware reads to check its
+// signature
+const maxCallbackBody = 1 << 20
+
+// VerifyHMAC reports whether headerValue is the hex HMAC-SHA256 of body keyed
+// with secret. Hex digits may be in either case.
+func VerifyHMAC(secret, body []byte, headerValue string) bool {
+	h := hmac.New(sha256.New, secret)
+	h.Write(body)
+	return hmacEqual(strings.ToLower(headerValue), hex.EncodeToString(h.Sum(nil)))
+}
+
+// hmacEqual compares two signatures in constant time. Comparing with == or !=
+// returns as soon as a byte differs, which lets an attacker time requests to
+// guess a valid signature byte by byte; every signature comparison must use
+// this instead. Inputs of different lengths are unequal.
+func hmacEqual(a, b string) bool {
+	return hmac.Equal([]byte(a), []byte(b))
+}
+
+// VerifyingMiddleware rejects callbacks from Pateplay whose x-api-hmac header
+// is missing or does not sign the body with secret. Verified requests reach
+// next with the body intact.
+func VerifyingMiddleware(secret []byte) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, err := io.ReadAll(io.LimitReader(r.Body, maxCallbackBody+1))
+			r.Body.Close()
+			if err != nil {
+				http.Error(w, "Bad request", http.StatusBadRequest)
+				return
+			}
+			if len(body) > maxCallbackBody {
+				http.Error(w, "Request body too large", http.StatusRequestEntityTooLarge)
+				return
+			}
+
+			if !VerifyHMAC(secret, body, r.Header.Get(HeaderHMAC)) {
+				http.Error(w, "Invalid signature", http.StatusUnauthorized)
+				return
+			}
+
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -7,10 +7,21 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"math/rand"
+	"net"
 	"net/http"
+	"strings"
 	"time"
+
+	"github.com/alexbotov/rgs/internal/domain"
+)
+
+var (
+	ErrInvalidAmount    = errors.New("invalid amount")
+	ErrCurrencyMismatch = errors.New("balance currency mismatch")
 )
 
 // Client is a Pateplay RGS Wallet API client
@@ -28,11 +39,31 @@ func NewClient(config *ClientConfig) *Client {
 	return &Client{
 		config: config,
 		httpClient: &http.Client{
-			Timeout: config.Timeout,
+			Timeout:   config.Timeout,
+			Transport: newTransport(config),
 		},
 	}
 }
 
+// newTransport builds the HTTP transport for the configured connection reuse
+func newTransport(config *ClientConfig) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if config.MaxIdleConns > 0 {
+		transport.MaxIdleConns = config.MaxIdleConns
+	}
+	if config.MaxIdleConnsPerHost > 0 {
+		transport.MaxIdleConnsPerHost = config.MaxIdleConnsPerHost
+	}
+	if config.IdleConnTimeout > 0 {
+		transport.IdleConnTimeout = config.IdleConnTimeout
+	}
+	if config.KeepAlive > 0 {
+		dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: config.KeepAlive}
+		transport.DialContext = dialer.DialContext
+	}
+	return transport
+}
+
 // NewClientWithHTTPClient creates a new Pateplay API client with a custom HTTP client
 func NewClientWithHTTPClient(config *ClientConfig, httpClient *http.Client) *Client {
 	return &Client{
@@ -41,6 +72,13 @@ func NewClientWithHTTPClient(config *ClientConfig, httpClient *http.Client) *Cli
 	}
 }
 
+// Close closes the client's idle connections. Requests made afterwards open
+// new ones, so closing a client still in use is safe.
+func (c *Client) Close() error {
+	c.httpClient.CloseIdleConnections()
+	return nil
+}
+
 // computeHMAC computes the HMAC-SHA256 signature for the request body
 func (c *Client) computeHMAC(body []byte) string {
 	h := hmac.New(sha256.New, []byte(c.config.APISecret))
@@ -48,7 +86,19 @@ func (c *Client) computeHMAC(body []byte) string {
 	return hex.EncodeToString(h.Sum(nil))
 }
 
-// doRequest performs an HTTP request with HMAC signing
+// defaultRetryBackoff is used when ClientConfig.RetryBackoff is not set
+const defaultRetryBackoff = 100 * time.Millisecond
+
+// idempotentEndpoints are safe to resend after any transient failure. Other
+// endpoints move money and are only retried when the request never left.
+var idempotentEndpoints = map[string]bool{
+	"/authenticate":  true,
+	"/balance":       true,
+	"/reality-check": true,
+}
+
+// doRequest performs an HTTP request with HMAC signing, retrying transient
+// failures with exponential backoff
 func (c *Client) doRequest(ctx context.Context, endpoint string, reqBody interface{}, result interface{}) error {
 	// Marshal request body
 	bodyBytes, err := json.Marshal(reqBody)
@@ -56,54 +106,147 @@ func (c *Client) doRequest(ctx context.Context, endpoint string, reqBody interfa
 		return fmt.Errorf("failed to marshal request: %w", err)
 	}
 
+	if timeout := c.config.OperationTimeouts[endpoint]; timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	idempotent := idempotentEndpoints[endpoint]
+	attempts := 1
+	if c.config.RetryCount > 0 {
+		attempts += c.config.RetryCount
+	}
+
+	var lastErr error
+	for attempt := 0; attempt < attempts; attempt++ {
+		if attempt > 0 {
+			if err := c.waitBackoff(ctx, attempt); err != nil {
+				return err
+			}
+		}
+
+		status, respBody, err := c.sendRequest(ctx, endpoint, bodyBytes)
+		if err != nil {
+			lastErr = err
+			// Never retry once the caller's context is done
+			if ctx.Err() != nil {
+				return err
+			}
+			if idempotent || isConnectError(err) {
+				continue
+			}
+			return err
+		}
+
+		// Server errors are retried for idempotent operations only
+		if status >= http.StatusInternalServerError && idempotent && attempt < attempts-1 {
+			lastErr = fmt.Errorf("server error: status %d", status)
+			continue
+		}
+
+		// Parse response
+		if err := json.Unmarshal(respBody, result); err != nil {
+			return fmt.Errorf("failed to parse response: %w", err)
+		}
+
+		return nil
+	}
+
+	return fmt.Errorf("request failed after %d attempts: %w", attempts, lastErr)
+}
+
+// sendRequest sends a single signed request and returns the status and body
+func (c *Client) sendRequest(ctx context.Context, endpoint string, bodyBytes []byte) (int, []byte, error) {
 	// Create request
 	url := c.config.BaseURL + endpoint
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(bodyBytes))
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		return 0, nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	// Set headers
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("x-api-key", c.config.APIKey)
-	req.Header.Set("x-api-hmac", c.computeHMAC(bodyBytes))
+	req.Header.Set(HeaderHMAC, c.computeHMAC(bodyBytes))
 
-	// Execute request with retry
-	var resp *http.Response
-	var lastErr error
-	retryCount := c.config.RetryCount
-	if retryCount == 0 {
-		retryCount = 1
+	if c.config.RequestInterceptor != nil {
+		c.config.RequestInterceptor(ctx, endpoint, bodyBytes)
 	}
 
-	for i := 0; i < retryCount; i++ {
-		resp, err = c.httpClient.Do(req)
-		if err != nil {
-			lastErr = err
-			continue
-		}
-		break
+	start := time.Now()
+	status, respBody, err := c.execute(req)
+
+	if c.config.ResponseInterceptor != nil {
+		c.config.ResponseInterceptor(ctx, endpoint, status, respBody, time.Since(start))
 	}
 
-	if resp == nil {
-		return fmt.Errorf("request failed after %d retries: %w", retryCount, lastErr)
+	return status, respBody, err
+}
+
+// execute sends the request and reads the full response body
+func (c *Client) execute(req *http.Request) (int, []byte, error) {
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, nil, err
 	}
 	defer resp.Body.Close()
 
 	// Read response body
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return fmt.Errorf("failed to read response: %w", err)
+		return resp.StatusCode, nil, fmt.Errorf("failed to read response: %w", err)
 	}
 
-	// Parse response
-	if err := json.Unmarshal(respBody, result); err != nil {
-		return fmt.Errorf("failed to parse response: %w", err)
+	return resp.StatusCode, respBody, nil
+}
+
+// waitBackoff sleeps for the exponential backoff of the given retry attempt,
+// with jitter, or until the context is done
+func (c *Client) waitBackoff(ctx context.Context, attempt int) error {
+	base := c.config.RetryBackoff
+	if base <= 0 {
+		base = defaultRetryBackoff
+	}
+	delay := base << (attempt - 1)
+	// Jitter in [delay/2, delay) to avoid synchronized retries
+	delay = delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
 	}
+}
 
+// Ping checks that the Pateplay API can be reached. Any HTTP response short
+// of a server error counts as reachable; the API is not authenticated against.
+func (c *Client) Ping(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.config.BaseURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	status, _, err := c.execute(req)
+	if err != nil {
+		return err
+	}
+	if status >= http.StatusInternalServerError {
+		return fmt.Errorf("server error: status %d", status)
+	}
 	return nil
 }
 
+// isConnectError reports whether err happened while establishing the
+// connection, meaning the request was never sent
+func isConnectError(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
 // Authenticate creates a new session token from a one-time auth token
 // This is called when a player opens a Pateplay game
 func (c *Client) Authenticate(ctx context.Context, authToken string, deviceType DeviceType) (*AuthenticateResult, error) {
@@ -145,6 +288,40 @@ func (c *Client) GetBalance(ctx context.Context, sessionToken, playerID string)
 	return resp.Result, nil
 }
 
+// GetBalanceDetailed retrieves the player's current balance parsed into minor
+// units of its currency, with the currency when the operator reports one.
+// The amount is parsed in currency when the operator does not report one.
+func (c *Client) GetBalanceDetailed(ctx context.Context, sessionToken, playerID, currency string) (*DetailedBalance, error) {
+	result, err := c.GetBalance(ctx, sessionToken, playerID)
+	if err != nil {
+		return nil, err
+	}
+
+	if result.Currency != "" {
+		currency = result.Currency
+	}
+	amount, err := domain.ParseMoney(result.Balance, strings.ToUpper(currency))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidAmount, result.Balance)
+	}
+	return &DetailedBalance{Balance: result.Balance, Currency: result.Currency, Amount: amount.Amount}, nil
+}
+
+// ReconcileBalance compares the player's Pateplay balance with the balance
+// held internally, both in minor units of currency, and returns how far the
+// Pateplay balance is above (positive) or below (negative) the internal one.
+// A balance reported in another currency cannot be compared and is an error.
+func (c *Client) ReconcileBalance(ctx context.Context, sessionToken, playerID string, internalAmount int64, currency string) (int64, error) {
+	balance, err := c.GetBalanceDetailed(ctx, sessionToken, playerID, currency)
+	if err != nil {
+		return 0, err
+	}
+	if balance.Currency != "" && !strings.EqualFold(balance.Currency, currency) {
+		return 0, fmt.Errorf("%w: pateplay reports %s, expected %s", ErrCurrencyMismatch, balance.Currency, currency)
+	}
+	return balance.Amount - internalAmount, nil
+}
+
 // InitGame starts a new game session
 // Returns a potentially updated session token for this game session
 func (c *Client) InitGame(ctx context.Context, sessionToken, playerID, gameName string) (*InitGameResult, error) {
@@ -167,7 +344,43 @@ func (c *Client) InitGame(ctx context.Context, sessionToken, playerID, gameName
 	return resp.Result, nil
 }
 
-// Withdraw deducts money from the player's balance (for placing bets)
+// AuthenticateAndInit authenticates a player from a one-time auth token and
+// initializes the game for them, the common path when a game is launched.
+// It stops at the first failing call.
+func (c *Client) AuthenticateAndInit(ctx context.Context, authToken string, deviceType DeviceType, gameName string) (*LaunchResult, error) {
+	auth, err := c.Authenticate(ctx, authToken, deviceType)
+	if err != nil {
+		return nil, err
+	}
+	game, err := c.InitGame(ctx, auth.SessionToken, auth.PlayerID, gameName)
+	if err != nil {
+		return nil, err
+	}
+
+	// InitGame may replace the session token and has the later balance
+	result := &LaunchResult{
+		SessionToken: auth.SessionToken,
+		PlayerID:     auth.PlayerID,
+		PlayerName:   auth.PlayerName,
+		Country:      auth.Country,
+		Currency:     auth.Currency,
+		Balance:      auth.Balance,
+		Game:         game,
+	}
+	if game.SessionToken != "" {
+		result.SessionToken = game.SessionToken
+	}
+	if game.Balance != "" {
+		result.Balance = game.Balance
+	}
+	if game.Currency != "" {
+		result.Currency = game.Currency
+	}
+	return result, nil
+}
+
+// Withdraw deducts money from the player's balance (for placing bets). A 90%
+// limit warning returned with the result is reported in its Warnings.
 func (c *Client) Withdraw(ctx context.Context, req *WithdrawRequest) (*WithdrawResult, error) {
 	// Ensure site code is set
 	req.SiteCode = c.config.SiteCode
@@ -178,12 +391,46 @@ func (c *Client) Withdraw(ctx context.Context, req *WithdrawRequest) (*WithdrawR
 	}
 
 	if resp.Error != nil {
-		return nil, resp.Error
+		if !resp.Error.IsWarning() || resp.Result == nil {
+			return nil, resp.Error
+		}
+		resp.Result.Warnings = append(resp.Result.Warnings, resp.Error.Code)
 	}
 
 	return resp.Result, nil
 }
 
+// WithdrawIdempotent performs a withdraw that can be safely resent, e.g. when
+// an RGS retries a round after a crash. If req.ReplayOnDuplicate is set and
+// Pateplay reports the transaction already exists, the existing transaction ID
+// is returned together with the player's current balance instead of an error.
+func (c *Client) WithdrawIdempotent(ctx context.Context, req *WithdrawRequest) (*WithdrawResult, error) {
+	result, err := c.Withdraw(ctx, req)
+	if err == nil || !req.ReplayOnDuplicate {
+		return result, err
+	}
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Code != ErrTransactionAlreadyExists {
+		return nil, err
+	}
+
+	transactionID, _ := apiErr.Data["transactionId"].(string)
+	if transactionID == "" {
+		return nil, err
+	}
+
+	balance, balErr := c.GetBalance(ctx, req.SessionToken, req.PlayerID)
+	if balErr != nil {
+		return nil, fmt.Errorf("failed to get balance for replayed withdraw: %w", balErr)
+	}
+
+	return &WithdrawResult{
+		TransactionID: transactionID,
+		Balance:       balance.Balance,
+	}, nil
+}
+
 // Deposit adds money to the player's balance (for wins)
 func (c *Client) Deposit(ctx context.Context, req *DepositRequest) (*DepositResult, error) {
 	// Ensure site code is set
@@ -195,7 +442,10 @@ func (c *Client) Deposit(ctx context.Context, req *DepositRequest) (*DepositResu
 	}
 
 	if resp.Error != nil {
-		return nil, resp.Error
+		if !resp.Error.IsWarning() || resp.Result == nil {
+			return nil, resp.Error
+		}
+		resp.Result.Warnings = append(resp.Result.Warnings, resp.Error.Code)
 	}
 
 	return resp.Result, nil
@@ -213,7 +463,10 @@ func (c *Client) WithdrawAndDeposit(ctx context.Context, req *WithdrawAndDeposit
 	}
 
 	if resp.Error != nil {
-		return nil, resp.Error
+		if !resp.Error.IsWarning() || resp.Result == nil {
+			return nil, resp.Error
+		}
+		resp.Result.Warnings = append(resp.Result.Warnings, resp.Error.Code)
 	}
 
 	return resp.Result, nil
@@ -241,6 +494,46 @@ func (c *Client) Cancel(ctx context.Context, sessionToken, playerID, rgsRoundID,
 	return resp.Result, nil
 }
 
+// RealityCheck retrieves the reality-check state for the player's session:
+// elapsed play time, net position and whether the player must acknowledge it
+func (c *Client) RealityCheck(ctx context.Context, sessionToken, playerID string) (*RealityCheckResult, error) {
+	req := &RealityCheckRequest{
+		SessionToken: sessionToken,
+		SiteCode:     c.config.SiteCode,
+		PlayerID:     playerID,
+	}
+
+	var resp Response[RealityCheckResult]
+	if err := c.doRequest(ctx, "/reality-check", req, &resp); err != nil {
+		return nil, err
+	}
+
+	if resp.Error != nil {
+		return nil, resp.Error
+	}
+
+	return resp.Result, nil
+}
+
+// AcknowledgeRealityCheck records that the player has acknowledged the reality check
+func (c *Client) AcknowledgeRealityCheck(ctx context.Context, sessionToken string) (*AcknowledgeRealityCheckResult, error) {
+	req := &AcknowledgeRealityCheckRequest{
+		SessionToken: sessionToken,
+		SiteCode:     c.config.SiteCode,
+	}
+
+	var resp Response[AcknowledgeRealityCheckResult]
+	if err := c.doRequest(ctx, "/reality-check/acknowledge", req, &resp); err != nil {
+		return nil, err
+	}
+
+	if resp.Error != nil {
+		return nil, resp.Error
+	}
+
+	return resp.Result, nil
+}
+
 // CreateAuthToken creates a test player auth token (DEBUG ONLY - disabled in production)
 func (c *Client) CreateAuthToken(ctx context.Context, req *CreateAuthTokenRequest) (*CreateAuthTokenResult, error) {
 	// Ensure site code is set
@@ -6,9 +6,12 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -58,7 +61,7 @@ func mockServer(t *testing.T, expectedPath string, validateBody func(body []byte
 		// Validate HMAC
 		expectedHMAC := computeTestHMAC(body)
 		actualHMAC := r.Header.Get("x-api-hmac")
-		if actualHMAC != expectedHMAC {
+		if !hmacEqual(actualHMAC, expectedHMAC) {
 			t.Errorf("HMAC mismatch: expected %s, got %s", expectedHMAC, actualHMAC)
 		}
 
@@ -351,6 +354,93 @@ func TestWithdraw_InsufficientBalance(t *testing.T) {
 	}
 }
 
+func TestWithdraw_LimitWarning(t *testing.T) {
+	expectedResponse := Response[WithdrawResult]{
+		Result: &WithdrawResult{
+			TransactionID: "tx-withdraw-123",
+			Balance:       "90.00",
+		},
+		Error: &APIError{
+			Code:    ErrBetLimit90Percent,
+			Message: "90% of bet limit used.",
+		},
+	}
+
+	server := mockServer(t, "/withdraw", nil, expectedResponse)
+	defer server.Close()
+
+	client := newTestClient(server.URL)
+	result, err := client.Withdraw(context.Background(), &WithdrawRequest{
+		SessionToken:     "session-123",
+		PlayerID:         "player-456",
+		Currency:         "USD",
+		RGSRoundID:       "round-1",
+		RGSTransactionID: "tx-1",
+		GameName:         "fortune-slots",
+		Amount:           "10.00",
+		Reason:           WithdrawReasonRoundStart,
+	})
+
+	if err != nil {
+		t.Fatalf("Expected withdraw to succeed with a warning, got %v", err)
+	}
+	if result.TransactionID != "tx-withdraw-123" || result.Balance != "90.00" {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+	if len(result.Warnings) != 1 || result.Warnings[0] != ErrBetLimit90Percent {
+		t.Errorf("Expected warning %s, got %v", ErrBetLimit90Percent, result.Warnings)
+	}
+}
+
+func TestWithdrawAndDeposit_LimitWarning(t *testing.T) {
+	t.Run("WithResult", func(t *testing.T) {
+		server := mockServer(t, "/withdraw-and-deposit", nil, Response[WithdrawAndDepositResult]{
+			Result: &WithdrawAndDepositResult{Balance: "95.00"},
+			Error:  &APIError{Code: ErrLossLimit90Percent, Message: "90% of loss limit used."},
+		})
+		defer server.Close()
+
+		result, err := newTestClient(server.URL).WithdrawAndDeposit(context.Background(), &WithdrawAndDepositRequest{})
+		if err != nil {
+			t.Fatalf("Expected success with a warning, got %v", err)
+		}
+		if len(result.Warnings) != 1 || result.Warnings[0] != ErrLossLimit90Percent {
+			t.Errorf("Expected warning %s, got %v", ErrLossLimit90Percent, result.Warnings)
+		}
+	})
+
+	t.Run("WithoutResult", func(t *testing.T) {
+		// A warning with nothing to return is still reported as an error
+		server := mockServer(t, "/withdraw-and-deposit", nil, Response[WithdrawAndDepositResult]{
+			Error: &APIError{Code: ErrTimeLimit90Percent, Message: "90% of time limit used."},
+		})
+		defer server.Close()
+
+		_, err := newTestClient(server.URL).WithdrawAndDeposit(context.Background(), &WithdrawAndDepositRequest{})
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) || apiErr.Code != ErrTimeLimit90Percent {
+			t.Errorf("Expected %s error, got %v", ErrTimeLimit90Percent, err)
+		}
+	})
+}
+
+func TestAPIError_IsWarning(t *testing.T) {
+	for code, want := range map[string]bool{
+		ErrBetLimit90Percent:   true,
+		ErrLossLimit90Percent:  true,
+		ErrTimeLimit90Percent:  true,
+		ErrBetLimitReached:     false,
+		ErrInsufficientBalance: false,
+	} {
+		if got := (&APIError{Code: code}).IsWarning(); got != want {
+			t.Errorf("IsWarning(%s) = %v, want %v", code, got, want)
+		}
+	}
+	if (*APIError)(nil).IsWarning() {
+		t.Error("Expected nil error not to be a warning")
+	}
+}
+
 func TestWithdraw_TransactionAlreadyExists(t *testing.T) {
 	expectedResponse := Response[WithdrawResult]{
 		Error: &APIError{
@@ -395,6 +485,109 @@ func TestWithdraw_TransactionAlreadyExists(t *testing.T) {
 	}
 }
 
+// routingServer serves a fixed response per endpoint path
+func routingServer(responses map[string]interface{}) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		response, ok := responses[r.URL.Path]
+		if !ok {
+			http.Error(w, "Not found", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	}))
+}
+
+func TestWithdrawIdempotent_Duplicate(t *testing.T) {
+	server := routingServer(map[string]interface{}{
+		"/withdraw": Response[WithdrawResult]{
+			Error: &APIError{
+				Code:    ErrTransactionAlreadyExists,
+				Message: "Transaction already exists.",
+				Data: map[string]interface{}{
+					"transactionId": "existing-tx-123",
+				},
+			},
+		},
+		"/balance": Response[BalanceResult]{
+			Result: &BalanceResult{Balance: "900.00"},
+		},
+	})
+	defer server.Close()
+
+	client := newTestClient(server.URL)
+
+	t.Run("ReplayEnabled", func(t *testing.T) {
+		result, err := client.WithdrawIdempotent(context.Background(), &WithdrawRequest{
+			SessionToken:      "session-123",
+			PlayerID:          "player-456",
+			RGSTransactionID:  "existing-tx",
+			Amount:            "100.00",
+			ReplayOnDuplicate: true,
+		})
+
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if result.TransactionID != "existing-tx-123" {
+			t.Errorf("Expected transactionId 'existing-tx-123', got '%s'", result.TransactionID)
+		}
+		if result.Balance != "900.00" {
+			t.Errorf("Expected balance '900.00', got '%s'", result.Balance)
+		}
+	})
+
+	t.Run("ReplayDisabled", func(t *testing.T) {
+		_, err := client.WithdrawIdempotent(context.Background(), &WithdrawRequest{
+			SessionToken:     "session-123",
+			PlayerID:         "player-456",
+			RGSTransactionID: "existing-tx",
+			Amount:           "100.00",
+		})
+
+		apiErr, ok := err.(*APIError)
+		if !ok {
+			t.Fatalf("Expected APIError, got %T", err)
+		}
+		if apiErr.Code != ErrTransactionAlreadyExists {
+			t.Errorf("Expected error code '%s', got '%s'", ErrTransactionAlreadyExists, apiErr.Code)
+		}
+	})
+}
+
+func TestWithdrawIdempotent_Fresh(t *testing.T) {
+	expectedResponse := Response[WithdrawResult]{
+		Result: &WithdrawResult{
+			TransactionID: "new-tx-123",
+			Balance:       "900.00",
+		},
+	}
+
+	server := mockServer(t, "/withdraw", func(body []byte) error {
+		if strings.Contains(string(body), "ReplayOnDuplicate") {
+			t.Error("Expected replay flag not to be sent")
+		}
+		return nil
+	}, expectedResponse)
+	defer server.Close()
+
+	client := newTestClient(server.URL)
+	result, err := client.WithdrawIdempotent(context.Background(), &WithdrawRequest{
+		SessionToken:      "session-123",
+		PlayerID:          "player-456",
+		RGSTransactionID:  "new-tx",
+		Amount:            "100.00",
+		ReplayOnDuplicate: true,
+	})
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.TransactionID != "new-tx-123" {
+		t.Errorf("Expected transactionId 'new-tx-123', got '%s'", result.TransactionID)
+	}
+}
+
 func TestDeposit_Success(t *testing.T) {
 	expectedResponse := Response[DepositResult]{
 		Result: &DepositResult{
@@ -611,6 +804,105 @@ func TestCancel_TransactionNotFound(t *testing.T) {
 	}
 }
 
+func TestRealityCheck_Success(t *testing.T) {
+	expectedResponse := Response[RealityCheckResult]{
+		Result: &RealityCheckResult{
+			ElapsedMinutes: 60,
+			NetPosition:    "-25.50",
+			RequiresAck:    true,
+		},
+	}
+
+	server := mockServer(t, "/reality-check", func(body []byte) error {
+		var req RealityCheckRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			return err
+		}
+		if req.SessionToken != "session-123" {
+			t.Errorf("Expected sessionToken 'session-123', got '%s'", req.SessionToken)
+		}
+		if req.PlayerID != "player-456" {
+			t.Errorf("Expected playerId 'player-456', got '%s'", req.PlayerID)
+		}
+		if req.SiteCode != testSiteCode {
+			t.Errorf("Expected siteCode '%s', got '%s'", testSiteCode, req.SiteCode)
+		}
+		return nil
+	}, expectedResponse)
+	defer server.Close()
+
+	client := newTestClient(server.URL)
+	result, err := client.RealityCheck(context.Background(), "session-123", "player-456")
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if result.ElapsedMinutes != 60 {
+		t.Errorf("Expected elapsedMinutes 60, got %d", result.ElapsedMinutes)
+	}
+	if result.NetPosition != "-25.50" {
+		t.Errorf("Expected netPosition '-25.50', got '%s'", result.NetPosition)
+	}
+	if !result.RequiresAck {
+		t.Error("Expected requiresAck to be true")
+	}
+}
+
+func TestRealityCheck_InvalidSession(t *testing.T) {
+	expectedResponse := Response[RealityCheckResult]{
+		Error: &APIError{
+			Code:    ErrInvalidSessionToken,
+			Message: "Session token is invalid.",
+		},
+	}
+
+	server := mockServer(t, "/reality-check", nil, expectedResponse)
+	defer server.Close()
+
+	client := newTestClient(server.URL)
+	_, err := client.RealityCheck(context.Background(), "bad-session", "player-456")
+
+	apiErr, ok := err.(*APIError)
+	if !ok {
+		t.Fatalf("Expected APIError, got %T", err)
+	}
+	if apiErr.Code != ErrInvalidSessionToken {
+		t.Errorf("Expected error code '%s', got '%s'", ErrInvalidSessionToken, apiErr.Code)
+	}
+}
+
+func TestAcknowledgeRealityCheck_Success(t *testing.T) {
+	expectedResponse := Response[AcknowledgeRealityCheckResult]{
+		Result: &AcknowledgeRealityCheckResult{
+			Acknowledged: true,
+		},
+	}
+
+	server := mockServer(t, "/reality-check/acknowledge", func(body []byte) error {
+		var req AcknowledgeRealityCheckRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			return err
+		}
+		if req.SessionToken != "session-123" {
+			t.Errorf("Expected sessionToken 'session-123', got '%s'", req.SessionToken)
+		}
+		return nil
+	}, expectedResponse)
+	defer server.Close()
+
+	client := newTestClient(server.URL)
+	result, err := client.AcknowledgeRealityCheck(context.Background(), "session-123")
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !result.Acknowledged {
+		t.Error("Expected acknowledged to be true")
+	}
+}
+
 func TestCreateAuthToken_NewPlayer(t *testing.T) {
 	expectedResponse := Response[CreateAuthTokenResult]{
 		Result: &CreateAuthTokenResult{
@@ -750,6 +1042,163 @@ func TestClient_ContextCancellation(t *testing.T) {
 	}
 }
 
+// flakyServer fails the first n requests with a 503 and then returns response
+func flakyServer(failures int, response interface{}) (*httptest.Server, *int32) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if int(atomic.AddInt32(&calls, 1)) <= failures {
+			http.Error(w, "Service unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	}))
+	return server, &calls
+}
+
+func newRetryTestClient(baseURL string, retries int) *Client {
+	return NewClient(&ClientConfig{
+		BaseURL:      baseURL,
+		APIKey:       testAPIKey,
+		APISecret:    testAPISecret,
+		SiteCode:     testSiteCode,
+		Timeout:      5 * time.Second,
+		RetryCount:   retries,
+		RetryBackoff: time.Millisecond,
+	})
+}
+
+func TestClient_RetryIdempotent(t *testing.T) {
+	server, calls := flakyServer(2, Response[BalanceResult]{
+		Result: &BalanceResult{Balance: "100.00"},
+	})
+	defer server.Close()
+
+	client := newRetryTestClient(server.URL, 3)
+	result, err := client.GetBalance(context.Background(), "session-123", "player-456")
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Balance != "100.00" {
+		t.Errorf("Expected balance '100.00', got '%s'", result.Balance)
+	}
+	if got := atomic.LoadInt32(calls); got != 3 {
+		t.Errorf("Expected 3 attempts, got %d", got)
+	}
+}
+
+func TestClient_RetryExhausted(t *testing.T) {
+	server, calls := flakyServer(10, Response[BalanceResult]{
+		Result: &BalanceResult{Balance: "100.00"},
+	})
+	defer server.Close()
+
+	client := newRetryTestClient(server.URL, 2)
+	_, err := client.GetBalance(context.Background(), "session-123", "player-456")
+
+	if err == nil {
+		t.Fatal("Expected error after retries exhausted, got nil")
+	}
+	if got := atomic.LoadInt32(calls); got != 3 {
+		t.Errorf("Expected 3 attempts, got %d", got)
+	}
+}
+
+func TestClient_NoRetryNonIdempotent(t *testing.T) {
+	server, calls := flakyServer(1, Response[WithdrawResult]{
+		Result: &WithdrawResult{TransactionID: "tx-1", Balance: "90.00"},
+	})
+	defer server.Close()
+
+	client := newRetryTestClient(server.URL, 3)
+	_, err := client.Withdraw(context.Background(), &WithdrawRequest{
+		RGSTransactionID: "rgs-tx-1",
+		Amount:           "10.00",
+	})
+
+	if err == nil {
+		t.Fatal("Expected error for failed withdraw, got nil")
+	}
+	if got := atomic.LoadInt32(calls); got != 1 {
+		t.Errorf("Expected withdraw not to be retried, got %d attempts", got)
+	}
+}
+
+func TestClient_RetryConnectError(t *testing.T) {
+	// Grab a free port and close it so connections are refused
+	listener := httptest.NewServer(http.NotFoundHandler())
+	url := listener.URL
+	listener.Close()
+
+	client := newRetryTestClient(url, 2)
+	_, err := client.Withdraw(context.Background(), &WithdrawRequest{})
+
+	if err == nil {
+		t.Fatal("Expected error for refused connection, got nil")
+	}
+	if !strings.Contains(err.Error(), "after 3 attempts") {
+		t.Errorf("Expected withdraw to be retried on connection failure, got: %v", err)
+	}
+}
+
+func TestClient_Interceptors(t *testing.T) {
+	expectedResponse := Response[BalanceResult]{
+		Result: &BalanceResult{Balance: "100.00"},
+	}
+
+	server := mockServer(t, "/balance", nil, expectedResponse)
+	defer server.Close()
+
+	var requestEndpoint, responseEndpoint string
+	var requestBody, responseBody []byte
+	var responseStatus int
+	var elapsed time.Duration
+
+	client := NewClient(&ClientConfig{
+		BaseURL:    server.URL,
+		APIKey:     testAPIKey,
+		APISecret:  testAPISecret,
+		SiteCode:   testSiteCode,
+		Timeout:    5 * time.Second,
+		RetryCount: 1,
+		RequestInterceptor: func(ctx context.Context, endpoint string, body []byte) {
+			requestEndpoint = endpoint
+			requestBody = body
+		},
+		ResponseInterceptor: func(ctx context.Context, endpoint string, status int, body []byte, d time.Duration) {
+			responseEndpoint = endpoint
+			responseStatus = status
+			responseBody = body
+			elapsed = d
+		},
+	})
+
+	_, err := client.GetBalance(context.Background(), "session-123", "player-456")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if requestEndpoint != "/balance" {
+		t.Errorf("Expected request interceptor endpoint '/balance', got '%s'", requestEndpoint)
+	}
+	if !strings.Contains(string(requestBody), `"playerId":"player-456"`) {
+		t.Errorf("Expected request body to contain playerId, got %s", requestBody)
+	}
+	if responseEndpoint != "/balance" {
+		t.Errorf("Expected response interceptor endpoint '/balance', got '%s'", responseEndpoint)
+	}
+	if responseStatus != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", responseStatus)
+	}
+	if !strings.Contains(string(responseBody), "100.00") {
+		t.Errorf("Expected response body to contain balance, got %s", responseBody)
+	}
+	if elapsed <= 0 {
+		t.Errorf("Expected positive elapsed time, got %v", elapsed)
+	}
+}
+
 func TestAPIError_Error(t *testing.T) {
 	apiErr := &APIError{
 		Code:    ErrInsufficientBalance,
@@ -761,6 +1210,95 @@ func TestAPIError_Error(t *testing.T) {
 	}
 }
 
+func TestAPIError_LimitInfo(t *testing.T) {
+	t.Run("LossLimitReached", func(t *testing.T) {
+		expectedResponse := Response[WithdrawResult]{
+			Error: &APIError{
+				Code:    ErrLossLimitReached,
+				Message: "Loss limit reached.",
+				Data: map[string]interface{}{
+					"limitType":   "loss",
+					"remaining":   "0.00",
+					"resetAt":     "2024-01-02T00:00:00Z",
+					"percentUsed": 100,
+				},
+			},
+		}
+
+		server := mockServer(t, "/withdraw", nil, expectedResponse)
+		defer server.Close()
+
+		client := newTestClient(server.URL)
+		_, err := client.Withdraw(context.Background(), &WithdrawRequest{})
+
+		apiErr, ok := err.(*APIError)
+		if !ok {
+			t.Fatalf("Expected APIError, got %T", err)
+		}
+
+		info, ok := apiErr.LimitInfo()
+		if !ok {
+			t.Fatal("Expected limit info for loss limit error")
+		}
+		if info.LimitType != "loss" {
+			t.Errorf("Expected limitType 'loss', got '%s'", info.LimitType)
+		}
+		if info.Remaining != "0.00" {
+			t.Errorf("Expected remaining '0.00', got '%s'", info.Remaining)
+		}
+		if !info.ResetAt.Equal(time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)) {
+			t.Errorf("Expected resetAt 2024-01-02T00:00:00Z, got %v", info.ResetAt)
+		}
+		if info.PercentUsed != 100 {
+			t.Errorf("Expected percentUsed 100, got %f", info.PercentUsed)
+		}
+	})
+
+	t.Run("NinetyPercentWithoutData", func(t *testing.T) {
+		apiErr := &APIError{Code: ErrBetLimit90Percent, Message: "Bet limit 90% used."}
+
+		info, ok := apiErr.LimitInfo()
+		if !ok {
+			t.Fatal("Expected limit info for bet limit warning")
+		}
+		if info.LimitType != "bet" {
+			t.Errorf("Expected limitType 'bet', got '%s'", info.LimitType)
+		}
+		if info.PercentUsed != 90 {
+			t.Errorf("Expected percentUsed 90, got %f", info.PercentUsed)
+		}
+		if !info.ResetAt.IsZero() {
+			t.Errorf("Expected zero resetAt, got %v", info.ResetAt)
+		}
+	})
+
+	t.Run("MalformedData", func(t *testing.T) {
+		apiErr := &APIError{
+			Code: ErrTimeLimitReached,
+			Data: map[string]interface{}{
+				"remaining": true,
+				"resetAt":   "not-a-time",
+			},
+		}
+
+		info, ok := apiErr.LimitInfo()
+		if !ok {
+			t.Fatal("Expected limit info for time limit error")
+		}
+		if info.LimitType != "time" || info.Remaining != "" || !info.ResetAt.IsZero() {
+			t.Errorf("Expected defaults for malformed data, got %+v", info)
+		}
+	})
+
+	t.Run("NotLimitError", func(t *testing.T) {
+		apiErr := &APIError{Code: ErrInsufficientBalance}
+
+		if _, ok := apiErr.LimitInfo(); ok {
+			t.Error("Expected no limit info for non-limit error")
+		}
+	})
+}
+
 func TestDefaultConfig(t *testing.T) {
 	config := DefaultConfig()
 
@@ -770,6 +1308,9 @@ func TestDefaultConfig(t *testing.T) {
 	if config.RetryCount != 3 {
 		t.Errorf("Expected default retry count 3, got %d", config.RetryCount)
 	}
+	if config.RetryBackoff != 100*time.Millisecond {
+		t.Errorf("Expected default retry backoff 100ms, got %v", config.RetryBackoff)
+	}
 }
 
 func TestNewClientWithHTTPClient(t *testing.T) {
@@ -790,3 +1331,384 @@ func TestNewClientWithHTTPClient(t *testing.T) {
 		t.Error("Expected custom HTTP client to be used")
 	}
 }
+
+func TestClientTransport(t *testing.T) {
+	t.Run("Configured", func(t *testing.T) {
+		client := NewClient(&ClientConfig{
+			BaseURL:             "http://localhost:8080",
+			MaxIdleConns:        200,
+			MaxIdleConnsPerHost: 50,
+			IdleConnTimeout:     45 * time.Second,
+			KeepAlive:           15 * time.Second,
+		})
+
+		transport, ok := client.httpClient.Transport.(*http.Transport)
+		if !ok {
+			t.Fatalf("Expected an *http.Transport, got %T", client.httpClient.Transport)
+		}
+		if transport.MaxIdleConns != 200 || transport.MaxIdleConnsPerHost != 50 {
+			t.Errorf("Expected 200 idle connections, 50 per host, got %d and %d",
+				transport.MaxIdleConns, transport.MaxIdleConnsPerHost)
+		}
+		if transport.IdleConnTimeout != 45*time.Second {
+			t.Errorf("Expected idle timeout 45s, got %v", transport.IdleConnTimeout)
+		}
+		if transport.DialContext == nil {
+			t.Error("Expected a dialer with the configured keep-alive")
+		}
+		if transport == http.DefaultTransport {
+			t.Error("Expected a transport of the client's own, not the shared default")
+		}
+	})
+
+	t.Run("Defaults", func(t *testing.T) {
+		client := NewClient(&ClientConfig{BaseURL: "http://localhost:8080"})
+		transport := client.httpClient.Transport.(*http.Transport)
+		def := http.DefaultTransport.(*http.Transport)
+		if transport.MaxIdleConns != def.MaxIdleConns || transport.IdleConnTimeout != def.IdleConnTimeout {
+			t.Errorf("Expected Go's defaults, got %d idle connections and %v timeout",
+				transport.MaxIdleConns, transport.IdleConnTimeout)
+		}
+	})
+
+	t.Run("ReusedAfterClose", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "Not found", http.StatusNotFound)
+		}))
+		defer server.Close()
+
+		client := NewClient(&ClientConfig{BaseURL: server.URL})
+		if err := client.Ping(context.Background()); err != nil {
+			t.Fatalf("Expected reachable, got %v", err)
+		}
+		if err := client.Close(); err != nil {
+			t.Errorf("Expected Close to succeed, got %v", err)
+		}
+		if err := client.Ping(context.Background()); err != nil {
+			t.Errorf("Expected the client to reconnect after Close, got %v", err)
+		}
+	})
+
+	t.Run("CloseCustomClient", func(t *testing.T) {
+		for _, custom := range []*http.Client{{}, {Transport: &http.Transport{}}} {
+			client := NewClientWithHTTPClient(&ClientConfig{BaseURL: "http://localhost:8080"}, custom)
+			if err := client.Close(); err != nil {
+				t.Errorf("Expected Close to succeed, got %v", err)
+			}
+		}
+	})
+}
+
+func TestPing(t *testing.T) {
+	t.Run("Reachable", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "Not found", http.StatusNotFound)
+		}))
+		defer server.Close()
+
+		client := NewClient(&ClientConfig{BaseURL: server.URL})
+		if err := client.Ping(context.Background()); err != nil {
+			t.Errorf("Expected reachable, got %v", err)
+		}
+	})
+
+	t.Run("ServerError", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "Unavailable", http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		client := NewClient(&ClientConfig{BaseURL: server.URL})
+		if err := client.Ping(context.Background()); err == nil {
+			t.Error("Expected an error for a server error")
+		}
+	})
+
+	t.Run("Unreachable", func(t *testing.T) {
+		server := httptest.NewServer(http.NotFoundHandler())
+		server.Close()
+
+		client := NewClient(&ClientConfig{BaseURL: server.URL})
+		if err := client.Ping(context.Background()); err == nil {
+			t.Error("Expected an error for an unreachable server")
+		}
+	})
+}
+
+func TestReconcileBalance(t *testing.T) {
+	tests := []struct {
+		name     string
+		balance  string
+		currency string
+		internal int64
+		ours     string
+		wantDiff int64
+		wantErr  error
+	}{
+		{"Matching", "5000.50", "EUR", 500050, "EUR", 0, nil},
+		{"MatchingWithoutCurrency", "5000.5", "", 500050, "EUR", 0, nil},
+		{"PateplayHigher", "5000.50", "EUR", 500000, "EUR", 50, nil},
+		{"PateplayLower", "10", "eur", 1500, "EUR", -500, nil},
+		{"OtherCurrency", "5000.50", "USD", 500050, "EUR", 0, ErrCurrencyMismatch},
+		{"InvalidAmount", "50.005", "EUR", 5000, "EUR", 0, ErrInvalidAmount},
+		{"ThreeDecimals", "12.345", "KWD", 12000, "KWD", 345, nil},
+		{"NoDecimalsWithoutCurrency", "5000", "", 4000, "JPY", 1000, nil},
+		{"NoDecimalsInvalid", "5000.5", "JPY", 5000, "JPY", 0, ErrInvalidAmount},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := mockServer(t, "/balance", nil, Response[BalanceResult]{
+				Result: &BalanceResult{Balance: tt.balance, Currency: tt.currency},
+			})
+			defer server.Close()
+
+			client := newTestClient(server.URL)
+			diff, err := client.ReconcileBalance(context.Background(), "session-123", "player-456", tt.internal, tt.ours)
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Expected %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if diff != tt.wantDiff {
+				t.Errorf("Expected difference %d, got %d", tt.wantDiff, diff)
+			}
+		})
+	}
+}
+
+func TestClient_OperationTimeout(t *testing.T) {
+	// Every response takes 300ms, or until the client gives up
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(300 * time.Millisecond):
+		case <-r.Context().Done():
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/balance":
+			json.NewEncoder(w).Encode(Response[BalanceResult]{Result: &BalanceResult{Balance: "100.00"}})
+		default:
+			json.NewEncoder(w).Encode(Response[AuthenticateResult]{Result: &AuthenticateResult{SessionToken: "session-123"}})
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(&ClientConfig{
+		BaseURL:           server.URL,
+		APIKey:            testAPIKey,
+		APISecret:         testAPISecret,
+		SiteCode:          testSiteCode,
+		Timeout:           5 * time.Second,
+		RetryCount:        3,
+		RetryBackoff:      time.Millisecond,
+		OperationTimeouts: map[string]time.Duration{"/balance": 50 * time.Millisecond},
+	})
+
+	// The balance timeout fires long before the client timeout, retries included
+	start := time.Now()
+	_, err := client.GetBalance(context.Background(), "session-123", "player-456")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected deadline exceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 250*time.Millisecond {
+		t.Errorf("Expected the operation timeout to fire after 50ms, took %v", elapsed)
+	}
+
+	// Operations without an override keep the client timeout
+	result, err := client.Authenticate(context.Background(), "token", DeviceTypeDesktop)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.SessionToken != "session-123" {
+		t.Errorf("Expected session token, got %q", result.SessionToken)
+	}
+}
+
+func TestAuthenticateAndInit(t *testing.T) {
+	var calls []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.URL.Path)
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.URL.Path {
+		case "/authenticate":
+			json.NewEncoder(w).Encode(Response[AuthenticateResult]{Result: &AuthenticateResult{
+				SessionToken: "auth-session",
+				PlayerID:     "player-456",
+				PlayerName:   "Player",
+				Currency:     "EUR",
+				Country:      "MT",
+				Balance:      "100.00",
+			}})
+		case "/init-game":
+			var req InitGameRequest
+			json.Unmarshal(body, &req)
+			if req.SessionToken != "auth-session" || req.PlayerID != "player-456" || req.GameName != "fortune" {
+				t.Errorf("InitGame not called with the authenticated session: %+v", req)
+			}
+			json.NewEncoder(w).Encode(Response[InitGameResult]{Result: &InitGameResult{
+				SessionToken: "game-session",
+				Balance:      "99.50",
+				MinBet:       "0.10",
+				MaxBet:       "100.00",
+			}})
+		}
+	}))
+	defer server.Close()
+
+	client := newTestClient(server.URL)
+	result, err := client.AuthenticateAndInit(context.Background(), "auth-token", DeviceTypeMobile, "fortune")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if strings.Join(calls, ",") != "/authenticate,/init-game" {
+		t.Errorf("Expected authenticate then init-game, got %v", calls)
+	}
+	if result.SessionToken != "game-session" || result.Balance != "99.50" {
+		t.Errorf("Expected InitGame's session and balance, got %s and %s", result.SessionToken, result.Balance)
+	}
+	if result.PlayerID != "player-456" || result.Currency != "EUR" || result.Country != "MT" {
+		t.Errorf("Expected the authenticated player, got %+v", result)
+	}
+	if result.Game == nil || result.Game.MinBet != "0.10" || result.Game.MaxBet != "100.00" {
+		t.Errorf("Expected the launch configuration, got %+v", result.Game)
+	}
+}
+
+func TestAuthenticateAndInit_AuthFails(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(Response[AuthenticateResult]{
+			Error: &APIError{Code: ErrInvalidAuthToken, Message: "Invalid auth token."},
+		})
+	}))
+	defer server.Close()
+
+	client := newTestClient(server.URL)
+	_, err := client.AuthenticateAndInit(context.Background(), "bad-token", DeviceTypeDesktop, "fortune")
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Code != ErrInvalidAuthToken {
+		t.Fatalf("Expected INVALID_AUTH_TOKEN, got %v", err)
+	}
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Errorf("Expected InitGame to be skipped, got %d calls", got)
+	}
+}
+
+func TestVerifyHMAC(t *testing.T) {
+	body := []byte(`{"sessionToken":"abc","amount":"10.00"}`)
+	signature := computeTestHMAC(body)
+
+	tests := []struct {
+		name   string
+		secret string
+		body   []byte
+		header string
+		want   bool
+	}{
+		{"Valid", testAPISecret, body, signature, true},
+		{"UppercaseHex", testAPISecret, body, strings.ToUpper(signature), true},
+		{"WrongSecret", "other-secret", body, signature, false},
+		{"TamperedBody", testAPISecret, []byte(`{"sessionToken":"abc","amount":"99.00"}`), signature, false},
+		{"Missing", testAPISecret, body, "", false},
+		{"NotHex", testAPISecret, body, "not-a-signature", false},
+		{"Truncated", testAPISecret, body, signature[:32], false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := VerifyHMAC([]byte(tt.secret), tt.body, tt.header); got != tt.want {
+				t.Errorf("VerifyHMAC() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyingMiddleware(t *testing.T) {
+	var received []byte
+	handler := VerifyingMiddleware([]byte(testAPISecret))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	body := `{"playerId":"player-1","amount":"5.00"}`
+	send := func(signature string, set bool) *httptest.ResponseRecorder {
+		received = nil
+		req := httptest.NewRequest(http.MethodPost, "/callback", strings.NewReader(body))
+		if set {
+			req.Header.Set(HeaderHMAC, signature)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("Valid", func(t *testing.T) {
+		rec := send(computeTestHMAC([]byte(body)), true)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Expected 200, got %d", rec.Code)
+		}
+		if string(received) != body {
+			t.Errorf("Expected the handler to read the original body, got %q", received)
+		}
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		rec := send(computeTestHMAC([]byte(`{"amount":"500.00"}`)), true)
+		if rec.Code != http.StatusUnauthorized {
+			t.Errorf("Expected 401, got %d", rec.Code)
+		}
+		if received != nil {
+			t.Error("Expected the handler not to be called")
+		}
+	})
+
+	t.Run("Missing", func(t *testing.T) {
+		rec := send("", false)
+		if rec.Code != http.StatusUnauthorized {
+			t.Errorf("Expected 401, got %d", rec.Code)
+		}
+		if received != nil {
+			t.Error("Expected the handler not to be called")
+		}
+	})
+}
+
+func TestHMACEqual(t *testing.T) {
+	signature := computeTestHMAC([]byte(`{"amount":"1.00"}`))
+	other := computeTestHMAC([]byte(`{"amount":"2.00"}`))
+
+	tests := []struct {
+		name string
+		a, b string
+		want bool
+	}{
+		{"Equal", signature, signature, true},
+		{"Different", signature, other, false},
+		{"LastByteDiffers", signature, signature[:len(signature)-1] + "x", false},
+		{"Shorter", signature, signature[:10], false},
+		{"Longer", signature, signature + "00", false},
+		{"Empty", signature, "", false},
+		{"BothEmpty", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hmacEqual(tt.a, tt.b); got != tt.want {
+				t.Errorf("hmacEqual() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
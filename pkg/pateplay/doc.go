@@ -10,6 +10,10 @@
 //   - API Key: Sent in the x-api-key header
 //   - HMAC Signature: SHA256 hash of the request body, sent in x-api-hmac header
 //
+// Callbacks from Pateplay are checked with VerifyHMAC or VerifyingMiddleware.
+// Signatures are always compared in constant time, since an early-exit
+// comparison would let an attacker recover a valid signature by timing.
+//
 // # Basic Usage
 //
 //	client := pateplay.NewClient(&pateplay.ClientConfig{
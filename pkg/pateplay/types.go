@@ -2,7 +2,11 @@
 // Based on the Pateplay games API specification
 package pateplay
 
-import "time"
+import (
+	"context"
+	"strconv"
+	"time"
+)
 
 // Error codes returned by the Pateplay API
 const (
@@ -57,6 +61,84 @@ func (e *APIError) Error() string {
 	return e.Message
 }
 
+// LimitInfo describes the responsible gaming limit behind a limit error
+type LimitInfo struct {
+	LimitType   string    // bet, loss or time
+	Remaining   string    // Amount (or minutes for time limits) left before the limit is reached
+	ResetAt     time.Time // When the limit period resets, zero if not provided
+	PercentUsed float64   // Percentage of the limit used
+}
+
+// limitErrors maps limit error codes to their limit type and default usage
+var limitErrors = map[string]struct {
+	limitType   string
+	percentUsed float64
+}{
+	ErrBetLimitReached:    {"bet", 100},
+	ErrBetLimit90Percent:  {"bet", 90},
+	ErrLossLimitReached:   {"loss", 100},
+	ErrLossLimit90Percent: {"loss", 90},
+	ErrTimeLimitReached:   {"time", 100},
+	ErrTimeLimit90Percent: {"time", 90},
+}
+
+// warningCodes are limit errors that warn the player a limit is near rather
+// than refuse the operation; Pateplay returns them alongside the result
+var warningCodes = map[string]bool{
+	ErrBetLimit90Percent:  true,
+	ErrLossLimit90Percent: true,
+	ErrTimeLimit90Percent: true,
+}
+
+// IsWarning reports whether the error is a 90% limit warning, which does not
+// fail the operation it was returned with
+func (e *APIError) IsWarning() bool {
+	return e != nil && warningCodes[e.Code]
+}
+
+// LimitInfo parses the limit context carried in Data for BET/LOSS/TIME limit
+// errors. It returns false if the error is not a limit error. Missing or
+// malformed keys in Data are left at their defaults.
+func (e *APIError) LimitInfo() (*LimitInfo, bool) {
+	if e == nil {
+		return nil, false
+	}
+	def, ok := limitErrors[e.Code]
+	if !ok {
+		return nil, false
+	}
+
+	info := &LimitInfo{
+		LimitType:   def.limitType,
+		PercentUsed: def.percentUsed,
+	}
+
+	if v, ok := e.Data["limitType"].(string); ok && v != "" {
+		info.LimitType = v
+	}
+	switch v := e.Data["remaining"].(type) {
+	case string:
+		info.Remaining = v
+	case float64:
+		info.Remaining = strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	if v, ok := e.Data["resetAt"].(string); ok {
+		if t, err := time.Parse(time.RFC3339, v); err == nil {
+			info.ResetAt = t
+		}
+	}
+	switch v := e.Data["percentUsed"].(type) {
+	case float64:
+		info.PercentUsed = v
+	case string:
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			info.PercentUsed = f
+		}
+	}
+
+	return info, true
+}
+
 // Response wraps the API response with either result or error
 type Response[T any] struct {
 	Result *T        `json:"result,omitempty"`
@@ -89,7 +171,15 @@ type BalanceRequest struct {
 
 // BalanceResult is the result of a balance query
 type BalanceResult struct {
-	Balance string `json:"balance"`
+	Balance  string `json:"balance"`
+	Currency string `json:"currency,omitempty"` // Not returned by every operator
+}
+
+// DetailedBalance is a balance query result with the amount parsed
+type DetailedBalance struct {
+	Balance  string // As reported, in the major unit
+	Currency string // Empty when the operator does not report it
+	Amount   int64  // Balance in minor units of the currency
 }
 
 // InitGameRequest is the request body for /init-game
@@ -104,6 +194,25 @@ type InitGameRequest struct {
 type InitGameResult struct {
 	SessionToken string `json:"sessionToken"`
 	Balance      string `json:"balance"`
+
+	// Launch configuration, when the operator returns it
+	Currency   string   `json:"currency,omitempty"`
+	MinBet     string   `json:"minBet,omitempty"`
+	MaxBet     string   `json:"maxBet,omitempty"`
+	DefaultBet string   `json:"defaultBet,omitempty"`
+	Features   []string `json:"features,omitempty"`
+}
+
+// LaunchResult is the merged result of authenticating a player and
+// initializing a game for them
+type LaunchResult struct {
+	SessionToken string          `json:"sessionToken"` // Game session token, from InitGame
+	PlayerID     string          `json:"playerId"`
+	PlayerName   string          `json:"playerName"`
+	Country      string          `json:"country"`
+	Currency     string          `json:"currency"`
+	Balance      string          `json:"balance"` // As of InitGame
+	Game         *InitGameResult `json:"game"`    // Launch configuration
 }
 
 // WithdrawRequest is the request body for /withdraw
@@ -118,12 +227,20 @@ type WithdrawRequest struct {
 	Amount              string         `json:"amount"`
 	JackpotContribution string         `json:"jackpotContribution"`
 	Reason              WithdrawReason `json:"reason"`
+
+	// ReplayOnDuplicate makes WithdrawIdempotent return the existing
+	// transaction instead of a TRANSACTION_ALREADY_EXISTS error. Not sent.
+	ReplayOnDuplicate bool `json:"-"`
 }
 
 // WithdrawResult is the result of a withdraw operation
 type WithdrawResult struct {
 	TransactionID string `json:"transactionId"`
 	Balance       string `json:"balance"`
+
+	// Warnings are the codes of limit warnings returned with the result,
+	// e.g. BET_LIMIT_90_PERCENT. Not part of the result body.
+	Warnings []string `json:"-"`
 }
 
 // DepositRequest is the request body for /deposit
@@ -144,6 +261,9 @@ type DepositRequest struct {
 type DepositResult struct {
 	TransactionID string `json:"transactionId"`
 	Balance       string `json:"balance"`
+
+	// Warnings are the codes of limit warnings returned with the result
+	Warnings []string `json:"-"`
 }
 
 // WithdrawAndDepositRequest is the request body for /withdraw-and-deposit
@@ -168,6 +288,9 @@ type WithdrawAndDepositResult struct {
 	Balance              string `json:"balance"`
 	WithdrawTransactionID string `json:"withdrawTransactionId"`
 	DepositTransactionID  string `json:"depositTransactionId"`
+
+	// Warnings are the codes of limit warnings returned with the result
+	Warnings []string `json:"-"`
 }
 
 // CancelRequest is the request body for /cancel
@@ -184,6 +307,31 @@ type CancelResult struct {
 	TransactionID string `json:"transactionId"`
 }
 
+// RealityCheckRequest is the request body for /reality-check
+type RealityCheckRequest struct {
+	SessionToken string `json:"sessionToken"`
+	SiteCode     string `json:"siteCode"`
+	PlayerID     string `json:"playerId"`
+}
+
+// RealityCheckResult is the current reality-check state of a session
+type RealityCheckResult struct {
+	ElapsedMinutes int    `json:"elapsedMinutes"`
+	NetPosition    string `json:"netPosition"` // Net win/loss for the session, e.g. "-25.50"
+	RequiresAck    bool   `json:"requiresAck"`
+}
+
+// AcknowledgeRealityCheckRequest is the request body for /reality-check/acknowledge
+type AcknowledgeRealityCheckRequest struct {
+	SessionToken string `json:"sessionToken"`
+	SiteCode     string `json:"siteCode"`
+}
+
+// AcknowledgeRealityCheckResult is the result of acknowledging a reality check
+type AcknowledgeRealityCheckResult struct {
+	Acknowledged bool `json:"acknowledged"`
+}
+
 // CreateAuthTokenRequest is the request body for /auth-token (debug only)
 type CreateAuthTokenRequest struct {
 	SiteCode   string `json:"siteCode"`
@@ -206,19 +354,40 @@ type CreateAuthTokenResult struct {
 
 // ClientConfig holds the configuration for the Pateplay client
 type ClientConfig struct {
-	BaseURL    string
-	APIKey     string
-	APISecret  string
-	SiteCode   string
-	Timeout    time.Duration
-	RetryCount int
+	BaseURL      string
+	APIKey       string
+	APISecret    string
+	SiteCode     string
+	Timeout      time.Duration
+	RetryCount   int           // Retries after the first attempt for transient failures
+	RetryBackoff time.Duration // Base delay, doubled on each retry
+
+	// Connection reuse by the HTTP client NewClient builds; zero keeps Go's
+	// defaults. A client passed to NewClientWithHTTPClient is used as is.
+	MaxIdleConns        int           // Idle connections kept in total
+	MaxIdleConnsPerHost int           // Idle connections kept to the Pateplay host
+	IdleConnTimeout     time.Duration // How long an idle connection is kept open
+	KeepAlive           time.Duration // TCP keep-alive probe interval
+
+	// OperationTimeouts bounds whole operations, retries included, by
+	// endpoint (e.g. "/authenticate"), on top of the caller's context.
+	// Timeout still bounds each attempt.
+	OperationTimeouts map[string]time.Duration
+
+	// RequestInterceptor, if set, is called with the signed body before every
+	// request attempt is sent
+	RequestInterceptor func(ctx context.Context, endpoint string, body []byte)
+	// ResponseInterceptor, if set, is called after every request attempt with
+	// the HTTP status (0 on transport failure), response body and latency
+	ResponseInterceptor func(ctx context.Context, endpoint string, status int, body []byte, elapsed time.Duration)
 }
 
 // DefaultConfig returns a default client configuration
 func DefaultConfig() *ClientConfig {
 	return &ClientConfig{
-		Timeout:    30 * time.Second,
-		RetryCount: 3,
+		Timeout:      30 * time.Second,
+		RetryCount:   3,
+		RetryBackoff: 100 * time.Millisecond,
 	}
 }
 
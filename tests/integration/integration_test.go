@@ -7,6 +7,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"sync"
@@ -19,15 +20,21 @@ import (
 	"github.com/alexbotov/rgs/internal/config"
 	"github.com/alexbotov/rgs/internal/control"
 	"github.com/alexbotov/rgs/internal/database"
+	"github.com/alexbotov/rgs/internal/database/dbtest"
 	"github.com/alexbotov/rgs/internal/domain"
 	"github.com/alexbotov/rgs/internal/game"
 	"github.com/alexbotov/rgs/internal/limits"
 	"github.com/alexbotov/rgs/internal/rng"
+	"github.com/alexbotov/rgs/internal/server"
 	"github.com/alexbotov/rgs/internal/wallet"
 	"github.com/alexbotov/rgs/pkg/pateplay"
 	"github.com/google/uuid"
 )
 
+func TestMain(m *testing.M) {
+	dbtest.Main(m)
+}
+
 // mockPateplayServer creates a mock Pateplay API server for integration tests
 type mockPateplayServer struct {
 	server      *httptest.Server
@@ -139,19 +146,8 @@ func NewTestServer(t *testing.T) *TestServer {
 		},
 	}
 
-	// Initialize database
-	db, err := database.New(cfg.Database.Driver, cfg.Database.DSN)
-	if err != nil {
-		t.Fatalf("Failed to create database: %v", err)
-	}
-
-	// Reset and migrate for clean state
-	if err := db.Reset(); err != nil {
-		t.Fatalf("Failed to reset database: %v", err)
-	}
-	if err := db.Migrate(); err != nil {
-		t.Fatalf("Failed to migrate database: %v", err)
-	}
+	// Initialize database, migrated and empty
+	db := dbtest.Open(t)
 
 	// Initialize mock Pateplay server
 	mockPateplay := newMockPateplayServer()
@@ -167,12 +163,16 @@ func NewTestServer(t *testing.T) *TestServer {
 	rngSvc := rng.New()
 	authSvc := auth.New(db.DB, &cfg.Auth, auditSvc, pateplayClient)
 	walletSvc := wallet.New(db.DB, auditSvc, cfg.Game.DefaultCurrency)
-	gameEngine := game.New(db.DB, rngSvc, walletSvc, auditSvc, cfg.Game.DefaultCurrency)
 	limitsSvc := limits.New(db.DB, auditSvc, cfg.Game.DefaultCurrency)
-	controlSvc := control.New(db.DB, auditSvc)
+	gameEngine := game.New(db.DB, rngSvc, walletSvc, limitsSvc, auditSvc, cfg.Game.DefaultCurrency)
+	controlSvc := control.New(db.DB, auditSvc, authSvc)
+	gameEngine.SetControl(controlSvc)
 
 	// Initialize API handler
 	handler := api.New(authSvc, walletSvc, gameEngine, rngSvc)
+	handler.SetAudit(auditSvc)
+	handler.SetLimits(limitsSvc)
+	handler.SetControl(controlSvc)
 	router := handler.SetupRouter()
 
 	// Create test server
@@ -195,8 +195,6 @@ func NewTestServer(t *testing.T) *TestServer {
 		teardown: func() {
 			server.Close()
 			mockPateplay.close()
-			db.Reset() // Clean up after tests
-			db.Close()
 		},
 	}
 }
@@ -468,6 +466,56 @@ func TestSessionManagement(t *testing.T) {
 	})
 }
 
+func TestLogoutEndsGameSessions(t *testing.T) {
+	ts := NewTestServer(t)
+	defer ts.Close()
+
+	player := ts.createTestUser(t, "logoutsessions", "logoutsessions@example.com", "password123")
+	login := func(device string) string {
+		resp := ts.doRequest(t, "POST", "/api/v1/auth/login", map[string]interface{}{
+			"auth_token":  ts.getAuthToken(player.ID),
+			"device_type": device,
+		}, "")
+		return extractField(t, parseResponse(t, resp).Data, "token")
+	}
+	startSession := func(gameID, token string) string {
+		resp := ts.doRequest(t, "POST", "/api/v1/games/"+gameID+"/session", nil, token)
+		if resp.StatusCode != http.StatusCreated {
+			t.Fatalf("Expected status 201 starting %s, got %d", gameID, resp.StatusCode)
+		}
+		return extractField(t, parseResponse(t, resp).Data, "session_id")
+	}
+
+	// The same player is logged in twice, playing a game on each login
+	token := login("desktop")
+	otherToken := login("mobile")
+	ended := startSession("fortune-slots", token)
+	kept := startSession("lucky-sevens", otherToken)
+
+	resp := ts.doRequest(t, "POST", "/api/v1/auth/logout", nil, token)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+	}
+
+	session, err := ts.Game.GetSession(context.Background(), ended)
+	if err != nil {
+		t.Fatalf("Failed to get session: %v", err)
+	}
+	if session.Status != domain.GameSessionCompleted || session.EndedAt == nil {
+		t.Errorf("Expected session %s ended at logout, got %s", ended, session.Status)
+	}
+
+	// The other login's game session is left open
+	session, err = ts.Game.GetSession(context.Background(), kept)
+	if err != nil {
+		t.Fatalf("Failed to get session: %v", err)
+	}
+	if session.Status != domain.GameSessionActive || session.EndedAt != nil {
+		t.Errorf("Expected session %s of the other login still active, got %s", kept, session.Status)
+	}
+}
+
 // ============================================================================
 // Wallet Tests
 // ============================================================================
@@ -741,6 +789,203 @@ func TestGameOperations(t *testing.T) {
 	})
 }
 
+func TestGameRecall(t *testing.T) {
+	ts := NewTestServer(t)
+	defer ts.Close()
+
+	login := func(player *domain.Player) string {
+		resp := ts.doRequest(t, "POST", "/api/v1/auth/login", map[string]interface{}{
+			"auth_token":  ts.getAuthToken(player.ID),
+			"device_type": "desktop",
+		}, "")
+		return extractField(t, parseResponse(t, resp).Data, "token")
+	}
+
+	player := ts.createTestUser(t, "recalltest", "recall@example.com", "password123")
+	token := login(player)
+	other := ts.createTestUser(t, "recallother", "recallother@example.com", "password123")
+	otherToken := login(other)
+
+	ts.doRequest(t, "POST", "/api/v1/wallet/deposit", map[string]interface{}{
+		"amount":    100.00,
+		"reference": "recall-deposit",
+	}, token).Body.Close()
+
+	resp := ts.doRequest(t, "POST", "/api/v1/games/fortune-slots/session", nil, token)
+	gameSessionID := extractField(t, parseResponse(t, resp).Data, "session_id")
+
+	resp = ts.doRequest(t, "POST", "/api/v1/games/play", map[string]interface{}{
+		"session_id":   gameSessionID,
+		"wager_amount": 100,
+	}, token)
+	played := parseResponse(t, resp)
+	cycleID := extractField(t, played.Data, "cycle_id")
+	if cycleID == "" {
+		t.Fatal("Expected a cycle to recall")
+	}
+
+	t.Run("RecallOwnCycle", func(t *testing.T) {
+		resp := ts.doRequest(t, "GET", "/api/v1/games/history/"+cycleID, nil, token)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+		}
+		apiResp := parseResponse(t, resp)
+
+		var cycle struct {
+			CycleID       string                 `json:"cycle_id"`
+			SessionID     string                 `json:"session_id"`
+			GameID        string                 `json:"game_id"`
+			Status        string                 `json:"status"`
+			CompletedAt   *time.Time             `json:"completed_at"`
+			WagerAmount   float64                `json:"wager_amount"`
+			WinAmount     float64                `json:"win_amount"`
+			BalanceBefore float64                `json:"balance_before"`
+			BalanceAfter  float64                `json:"balance_after"`
+			Outcome       map[string]interface{} `json:"outcome"`
+		}
+		if err := json.Unmarshal(apiResp.Data, &cycle); err != nil {
+			t.Fatalf("Failed to decode cycle: %v", err)
+		}
+
+		if cycle.CycleID != cycleID || cycle.SessionID != gameSessionID || cycle.GameID != "fortune-slots" {
+			t.Errorf("Unexpected cycle identity: %+v", cycle)
+		}
+		if cycle.Status != string(domain.CycleStatusCompleted) || cycle.CompletedAt == nil {
+			t.Errorf("Expected a completed cycle, got status %s", cycle.Status)
+		}
+		if cycle.WagerAmount != 1 || cycle.BalanceBefore != 100 {
+			t.Errorf("Expected wager 1 from balance 100, got %v from %v", cycle.WagerAmount, cycle.BalanceBefore)
+		}
+		if cycle.BalanceAfter != cycle.BalanceBefore-cycle.WagerAmount+cycle.WinAmount {
+			t.Errorf("Balances do not reconcile: %+v", cycle)
+		}
+		if _, ok := cycle.Outcome["reels"]; !ok {
+			t.Errorf("Expected full outcome detail, got %v", cycle.Outcome)
+		}
+	})
+
+	t.Run("OtherPlayersCycle", func(t *testing.T) {
+		resp := ts.doRequest(t, "GET", "/api/v1/games/history/"+cycleID, nil, otherToken)
+		if resp.StatusCode != http.StatusNotFound {
+			t.Fatalf("Expected status 404, got %d", resp.StatusCode)
+		}
+		apiResp := parseResponse(t, resp)
+		if apiResp.Error == nil || apiResp.Error.Code != "CYCLE_NOT_FOUND" {
+			t.Errorf("Expected CYCLE_NOT_FOUND, got %+v", apiResp.Error)
+		}
+	})
+
+	t.Run("UnknownCycle", func(t *testing.T) {
+		resp := ts.doRequest(t, "GET", "/api/v1/games/history/not-a-cycle", nil, token)
+		if resp.StatusCode != http.StatusNotFound {
+			t.Fatalf("Expected status 404, got %d", resp.StatusCode)
+		}
+		resp.Body.Close()
+	})
+}
+
+func TestGetGameSession(t *testing.T) {
+	ts := NewTestServer(t)
+	defer ts.Close()
+
+	login := func(player *domain.Player) string {
+		resp := ts.doRequest(t, "POST", "/api/v1/auth/login", map[string]interface{}{
+			"auth_token":  ts.getAuthToken(player.ID),
+			"device_type": "desktop",
+		}, "")
+		return extractField(t, parseResponse(t, resp).Data, "token")
+	}
+
+	player := ts.createTestUser(t, "sessionstats", "sessionstats@example.com", "password123")
+	token := login(player)
+	other := ts.createTestUser(t, "sessionother", "sessionother@example.com", "password123")
+	otherToken := login(other)
+
+	ts.doRequest(t, "POST", "/api/v1/wallet/deposit", map[string]interface{}{
+		"amount":    100.00,
+		"reference": "session-stats-deposit",
+	}, token).Body.Close()
+
+	resp := ts.doRequest(t, "POST", "/api/v1/games/fortune-slots/session", nil, token)
+	gameSessionID := extractField(t, parseResponse(t, resp).Data, "session_id")
+
+	var won int64 // In cents
+	for i := 0; i < 3; i++ {
+		resp := ts.doRequest(t, "POST", "/api/v1/games/play", map[string]interface{}{
+			"session_id":   gameSessionID,
+			"wager_amount": 100,
+		}, token)
+		var played struct {
+			WinAmount float64 `json:"win_amount"`
+		}
+		if err := json.Unmarshal(parseResponse(t, resp).Data, &played); err != nil {
+			t.Fatalf("Failed to decode play %d: %v", i+1, err)
+		}
+		won += domain.NewMoney(played.WinAmount, "USD").Amount
+	}
+
+	t.Run("OwnSession", func(t *testing.T) {
+		resp := ts.doRequest(t, "GET", "/api/v1/games/session/"+gameSessionID, nil, token)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+		}
+
+		var session struct {
+			SessionID      string  `json:"session_id"`
+			GameID         string  `json:"game_id"`
+			Status         string  `json:"status"`
+			GamesPlayed    int     `json:"games_played"`
+			OpeningBalance float64 `json:"opening_balance"`
+			CurrentBalance float64 `json:"current_balance"`
+			TotalWagered   float64 `json:"total_wagered"`
+			TotalWon       float64 `json:"total_won"`
+		}
+		if err := json.Unmarshal(parseResponse(t, resp).Data, &session); err != nil {
+			t.Fatalf("Failed to decode session: %v", err)
+		}
+
+		if session.SessionID != gameSessionID || session.GameID != "fortune-slots" {
+			t.Errorf("Unexpected session identity: %+v", session)
+		}
+		if session.Status != string(domain.GameSessionActive) || session.GamesPlayed != 3 {
+			t.Errorf("Expected an active session with 3 games, got %+v", session)
+		}
+		if session.OpeningBalance != 100 || session.TotalWagered != 3 || domain.NewMoney(session.TotalWon, "USD").Amount != won {
+			t.Errorf("Expected opening 100, wagered 3 and won %d cents, got %+v", won, session)
+		}
+		if domain.NewMoney(session.CurrentBalance, "USD").Amount != 10000-300+won {
+			t.Errorf("Balances do not reconcile: %+v", session)
+		}
+	})
+
+	t.Run("OtherPlayersSession", func(t *testing.T) {
+		resp := ts.doRequest(t, "GET", "/api/v1/games/session/"+gameSessionID, nil, otherToken)
+		if resp.StatusCode != http.StatusNotFound {
+			t.Fatalf("Expected status 404, got %d", resp.StatusCode)
+		}
+		apiResp := parseResponse(t, resp)
+		if apiResp.Error == nil || apiResp.Error.Code != "SESSION_NOT_FOUND" {
+			t.Errorf("Expected SESSION_NOT_FOUND, got %+v", apiResp.Error)
+		}
+	})
+
+	t.Run("UnknownSession", func(t *testing.T) {
+		resp := ts.doRequest(t, "GET", "/api/v1/games/session/not-a-session", nil, token)
+		if resp.StatusCode != http.StatusNotFound {
+			t.Fatalf("Expected status 404, got %d", resp.StatusCode)
+		}
+		resp.Body.Close()
+	})
+
+	t.Run("Unauthenticated", func(t *testing.T) {
+		resp := ts.doRequest(t, "GET", "/api/v1/games/session/"+gameSessionID, nil, "")
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Fatalf("Expected status 401, got %d", resp.StatusCode)
+		}
+		resp.Body.Close()
+	})
+}
+
 // ============================================================================
 // RNG Tests
 // ============================================================================
@@ -1104,6 +1349,137 @@ func TestSelfExclusionIntegration(t *testing.T) {
 	})
 }
 
+func TestLimitsEndpoints(t *testing.T) {
+	ts := NewTestServer(t)
+	defer ts.Close()
+
+	player := ts.createTestUser(t, "limits_api", "limits_api@example.com", "password123")
+
+	loginResp := ts.doRequest(t, "POST", "/api/v1/auth/login", map[string]interface{}{
+		"auth_token":  ts.getAuthToken(player.ID),
+		"device_type": "desktop",
+	}, "")
+	loginData := parseResponse(t, loginResp)
+	token := extractField(t, loginData.Data, "token")
+
+	t.Run("NoLimitsInitially", func(t *testing.T) {
+		resp := ts.doRequest(t, "GET", "/api/v1/limits", nil, token)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+		}
+		apiResp := parseResponse(t, resp)
+		if v := extractField(t, apiResp.Data, "daily_deposit"); v != "<nil>" {
+			t.Errorf("Expected no daily deposit limit, got %s", v)
+		}
+	})
+
+	t.Run("SetDepositLimit", func(t *testing.T) {
+		resp := ts.doRequest(t, "POST", "/api/v1/limits/deposit", map[string]interface{}{
+			"period": "daily",
+			"amount": 100.00,
+		}, token)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+		}
+		apiResp := parseResponse(t, resp)
+		if v := extractField(t, apiResp.Data, "daily_deposit"); v != "100" {
+			t.Errorf("Expected daily deposit limit 100, got %s", v)
+		}
+		if v := extractField(t, apiResp.Data, "status"); v != "active" {
+			t.Errorf("Expected decrease to apply immediately, got %s", v)
+		}
+	})
+
+	t.Run("IncreaseIsPendingCoolingOff", func(t *testing.T) {
+		resp := ts.doRequest(t, "POST", "/api/v1/limits/deposit", map[string]interface{}{
+			"period": "daily",
+			"amount": 500.00,
+		}, token)
+		if resp.StatusCode != http.StatusAccepted {
+			t.Fatalf("Expected status 202, got %d", resp.StatusCode)
+		}
+		apiResp := parseResponse(t, resp)
+		if v := extractField(t, apiResp.Data, "status"); v != "pending_cooling_off" {
+			t.Errorf("Expected pending_cooling_off, got %s", v)
+		}
+	})
+
+	t.Run("SetWagerAndLossLimits", func(t *testing.T) {
+		resp := ts.doRequest(t, "POST", "/api/v1/limits/wager", map[string]interface{}{
+			"period": "weekly",
+			"amount": 50.00,
+		}, token)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected status 200 for wager limit, got %d", resp.StatusCode)
+		}
+		resp.Body.Close()
+
+		resp = ts.doRequest(t, "POST", "/api/v1/limits/loss", map[string]interface{}{
+			"period": "daily",
+			"amount": 25.00,
+		}, token)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected status 200 for loss limit, got %d", resp.StatusCode)
+		}
+		apiResp := parseResponse(t, resp)
+		if v := extractField(t, apiResp.Data, "weekly_wager"); v != "50" {
+			t.Errorf("Expected weekly wager limit 50, got %s", v)
+		}
+		if v := extractField(t, apiResp.Data, "daily_loss"); v != "25" {
+			t.Errorf("Expected daily loss limit 25, got %s", v)
+		}
+	})
+
+	t.Run("InvalidPeriod", func(t *testing.T) {
+		resp := ts.doRequest(t, "POST", "/api/v1/limits/loss", map[string]interface{}{
+			"period": "monthly",
+			"amount": 25.00,
+		}, token)
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Fatalf("Expected status 400, got %d", resp.StatusCode)
+		}
+		apiResp := parseResponse(t, resp)
+		if apiResp.Error == nil || apiResp.Error.Code != "INVALID_PERIOD" {
+			t.Errorf("Expected INVALID_PERIOD, got %+v", apiResp.Error)
+		}
+	})
+
+	t.Run("RemoveWithoutExclusion", func(t *testing.T) {
+		resp := ts.doRequest(t, "DELETE", "/api/v1/limits/self-exclude", nil, token)
+		if resp.StatusCode != http.StatusNotFound {
+			t.Fatalf("Expected status 404, got %d", resp.StatusCode)
+		}
+		resp.Body.Close()
+	})
+
+	t.Run("SelfExclude", func(t *testing.T) {
+		resp := ts.doRequest(t, "POST", "/api/v1/limits/self-exclude", map[string]interface{}{
+			"reason":        "Taking a break",
+			"duration_days": 7,
+		}, token)
+		if resp.StatusCode != http.StatusCreated {
+			t.Fatalf("Expected status 201, got %d", resp.StatusCode)
+		}
+		resp.Body.Close()
+
+		excluded, err := ts.Limits.IsExcluded(context.Background(), player.ID)
+		if err != nil || !excluded {
+			t.Errorf("Expected player to be excluded, got %v (err %v)", excluded, err)
+		}
+	})
+
+	t.Run("ExclusionCannotBeLiftedEarly", func(t *testing.T) {
+		resp := ts.doRequest(t, "DELETE", "/api/v1/limits/self-exclude", nil, token)
+		if resp.StatusCode != http.StatusForbidden {
+			t.Fatalf("Expected status 403, got %d", resp.StatusCode)
+		}
+		apiResp := parseResponse(t, resp)
+		if apiResp.Error == nil || apiResp.Error.Code != "EXCLUSION_NOT_EXPIRED" {
+			t.Errorf("Expected EXCLUSION_NOT_EXPIRED, got %+v", apiResp.Error)
+		}
+	})
+}
+
 // ============================================================================
 // Gaming Control Tests (GLI-19 §2.4)
 // ============================================================================
@@ -1198,6 +1574,128 @@ func TestGamingControlIntegration(t *testing.T) {
 	})
 }
 
+func TestAdminEndpoints(t *testing.T) {
+	ts := NewTestServer(t)
+	defer ts.Close()
+
+	ctx := context.Background()
+
+	login := func(player *domain.Player) string {
+		resp := ts.doRequest(t, "POST", "/api/v1/auth/login", map[string]interface{}{
+			"auth_token":  ts.getAuthToken(player.ID),
+			"device_type": "desktop",
+		}, "")
+		return extractField(t, parseResponse(t, resp).Data, "token")
+	}
+
+	admin := ts.createTestUser(t, "operator", "operator@example.com", "password123")
+	if _, err := ts.DB.ExecContext(ctx, "UPDATE players SET role = $1 WHERE id = $2", domain.PlayerRoleAdmin, admin.ID); err != nil {
+		t.Fatalf("Failed to grant admin role: %v", err)
+	}
+	adminToken := login(admin)
+
+	player := ts.createTestUser(t, "admin_target", "admin_target@example.com", "password123")
+	playerToken := login(player)
+
+	t.Run("PlayerCannotUseAdminEndpoints", func(t *testing.T) {
+		resp := ts.doRequest(t, "GET", "/api/v1/admin/status", nil, playerToken)
+		if resp.StatusCode != http.StatusForbidden {
+			t.Fatalf("Expected status 403, got %d", resp.StatusCode)
+		}
+		resp.Body.Close()
+	})
+
+	t.Run("DisableAndEnableGaming", func(t *testing.T) {
+		resp := ts.doRequest(t, "POST", "/api/v1/admin/gaming/disable", map[string]interface{}{
+			"reason":        "Emergency maintenance",
+			"authorized_by": "ops@example.com",
+		}, adminToken)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+		}
+		resp.Body.Close()
+
+		resp = ts.doRequest(t, "GET", "/api/v1/admin/status", nil, adminToken)
+		apiResp := parseResponse(t, resp)
+		if v := extractField(t, apiResp.Data, "gaming_enabled"); v != "false" {
+			t.Errorf("Expected gaming disabled, got %s", v)
+		}
+		if v := extractField(t, apiResp.Data, "disabled_by"); v != "ops@example.com" {
+			t.Errorf("Expected disabled_by ops@example.com, got %s", v)
+		}
+
+		resp = ts.doRequest(t, "POST", "/api/v1/admin/gaming/enable", map[string]interface{}{
+			"authorized_by": "ops@example.com",
+		}, adminToken)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+		}
+		resp.Body.Close()
+		if !ts.Control.IsGamingEnabled() {
+			t.Error("Expected gaming to be enabled again")
+		}
+	})
+
+	t.Run("DisableGame", func(t *testing.T) {
+		resp := ts.doRequest(t, "POST", "/api/v1/admin/games/fortune-slots/disable", map[string]interface{}{
+			"reason":        "Paytable review",
+			"authorized_by": "ops@example.com",
+		}, adminToken)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+		}
+		resp.Body.Close()
+		if ts.Control.IsGameEnabled("fortune-slots") {
+			t.Error("Expected game to be disabled")
+		}
+
+		resp = ts.doRequest(t, "POST", "/api/v1/admin/games/no-such-game/disable", map[string]interface{}{
+			"reason":        "Paytable review",
+			"authorized_by": "ops@example.com",
+		}, adminToken)
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("Expected status 404 for unknown game, got %d", resp.StatusCode)
+		}
+		resp.Body.Close()
+	})
+
+	t.Run("DisablePlayer", func(t *testing.T) {
+		resp := ts.doRequest(t, "POST", "/api/v1/admin/players/"+player.ID+"/disable", map[string]interface{}{
+			"reason":        "Suspicious activity",
+			"authorized_by": "ops@example.com",
+		}, adminToken)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+		}
+		resp.Body.Close()
+
+		// The player's sessions were terminated
+		resp = ts.doRequest(t, "GET", "/api/v1/wallet/balance", nil, playerToken)
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("Expected status 401 for disabled player, got %d", resp.StatusCode)
+		}
+		resp.Body.Close()
+	})
+
+	t.Run("CannotEnableSelfExcludedPlayer", func(t *testing.T) {
+		duration := 7 * 24 * time.Hour
+		if _, err := ts.Limits.SelfExclude(ctx, player.ID, "Taking a break", &duration); err != nil {
+			t.Fatalf("Failed to self-exclude: %v", err)
+		}
+
+		resp := ts.doRequest(t, "POST", "/api/v1/admin/players/"+player.ID+"/enable", map[string]interface{}{
+			"authorized_by": "ops@example.com",
+		}, adminToken)
+		if resp.StatusCode != http.StatusConflict {
+			t.Fatalf("Expected status 409, got %d", resp.StatusCode)
+		}
+		apiResp := parseResponse(t, resp)
+		if apiResp.Error == nil || apiResp.Error.Code != "PLAYER_SELF_EXCLUDED" {
+			t.Errorf("Expected PLAYER_SELF_EXCLUDED, got %+v", apiResp.Error)
+		}
+	})
+}
+
 // ============================================================================
 // Interrupted Games Tests (GLI-19 §4.16)
 // ============================================================================
@@ -1284,6 +1782,128 @@ func TestInterruptedGamesIntegration(t *testing.T) {
 	})
 }
 
+func TestInterruptedGamesEndpoints(t *testing.T) {
+	ts := NewTestServer(t)
+	defer ts.Close()
+
+	ctx := context.Background()
+
+	player := ts.createTestUser(t, "interrupted_api", "interrupted_api@example.com", "password123")
+	ts.Wallet.Deposit(ctx, player.ID, domain.NewMoney(500.00, "USD"), "initial-deposit")
+	other := ts.createTestUser(t, "interrupted_other", "interrupted_other@example.com", "password123")
+
+	session, err := ts.Game.StartSession(ctx, player.ID, "fortune-slots")
+	if err != nil {
+		t.Fatalf("Failed to start session: %v", err)
+	}
+
+	// Two rounds cut off after the wager was taken
+	insertInterrupted := func() string {
+		cycleID := uuid.New().String()
+		_, err := ts.DB.DB.ExecContext(ctx, `
+			INSERT INTO game_cycles (id, session_id, player_id, game_id, started_at, wager_amount, win_amount, balance_before, balance_after, outcome, status, currency)
+			VALUES ($1, $2, $3, $4, NOW(), 200, 0, 50000, 49800, '{"reels":["7","BAR","CHERRY"]}', $5, 'USD')
+		`, cycleID, session.ID, player.ID, "fortune-slots", domain.CycleStatusInterrupted)
+		if err != nil {
+			t.Fatalf("Failed to create interrupted cycle: %v", err)
+		}
+		return cycleID
+	}
+	resumeID := insertInterrupted()
+	voidID := insertInterrupted()
+
+	login := func(p *domain.Player) *APIResponse {
+		resp := ts.doRequest(t, "POST", "/api/v1/auth/login", map[string]interface{}{
+			"auth_token":  ts.getAuthToken(p.ID),
+			"device_type": "desktop",
+		}, "")
+		return parseResponse(t, resp)
+	}
+
+	loginResp := login(player)
+	token := extractField(t, loginResp.Data, "token")
+	otherToken := extractField(t, login(other).Data, "token")
+
+	t.Run("LoginReportsInterruptedGames", func(t *testing.T) {
+		var data struct {
+			InterruptedGames []map[string]interface{} `json:"interrupted_games"`
+		}
+		json.Unmarshal(loginResp.Data, &data)
+		if len(data.InterruptedGames) != 2 {
+			t.Errorf("Expected 2 interrupted games at login, got %d", len(data.InterruptedGames))
+		}
+	})
+
+	t.Run("ListInterruptedGames", func(t *testing.T) {
+		resp := ts.doRequest(t, "GET", "/api/v1/games/interrupted", nil, token)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+		}
+		var list []map[string]interface{}
+		json.Unmarshal(parseResponse(t, resp).Data, &list)
+		if len(list) != 2 {
+			t.Errorf("Expected 2 interrupted games, got %d", len(list))
+		}
+	})
+
+	t.Run("OtherPlayerCannotResolve", func(t *testing.T) {
+		for _, action := range []string{"resume", "void"} {
+			resp := ts.doRequest(t, "POST", "/api/v1/games/"+resumeID+"/"+action, nil, otherToken)
+			if resp.StatusCode != http.StatusNotFound {
+				t.Errorf("%s: expected status 404, got %d", action, resp.StatusCode)
+			}
+			resp.Body.Close()
+		}
+	})
+
+	t.Run("Resume", func(t *testing.T) {
+		resp := ts.doRequest(t, "POST", "/api/v1/games/"+resumeID+"/resume", nil, token)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+		}
+		apiResp := parseResponse(t, resp)
+		if v := extractField(t, apiResp.Data, "cycle_id"); v != resumeID {
+			t.Errorf("Expected cycle %s, got %s", resumeID, v)
+		}
+
+		cycle, err := ts.Game.GetCycle(ctx, resumeID)
+		if err != nil {
+			t.Fatalf("Failed to get cycle: %v", err)
+		}
+		if cycle.Status != domain.CycleStatusCompleted {
+			t.Errorf("Expected resumed cycle completed, got %s", cycle.Status)
+		}
+
+		// A resolved cycle cannot be resumed twice
+		resp = ts.doRequest(t, "POST", "/api/v1/games/"+resumeID+"/resume", nil, token)
+		if resp.StatusCode != http.StatusConflict {
+			t.Errorf("Expected status 409 on second resume, got %d", resp.StatusCode)
+		}
+		resp.Body.Close()
+	})
+
+	t.Run("Void", func(t *testing.T) {
+		before, _ := ts.Wallet.GetBalance(ctx, player.ID)
+
+		resp := ts.doRequest(t, "POST", "/api/v1/games/"+voidID+"/void", nil, token)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+		}
+		resp.Body.Close()
+
+		after, _ := ts.Wallet.GetBalance(ctx, player.ID)
+		if after.Available.Amount != before.Available.Amount+200 {
+			t.Errorf("Expected wager of 200 refunded, balance went from %d to %d",
+				before.Available.Amount, after.Available.Amount)
+		}
+
+		remaining, _ := ts.Game.GetInterruptedGames(ctx, player.ID)
+		if len(remaining) != 0 {
+			t.Errorf("Expected no interrupted games left, got %d", len(remaining))
+		}
+	})
+}
+
 // ============================================================================
 // Responsible Gaming Flow Test (GLI-19 §2.5.5)
 // ============================================================================
@@ -1358,8 +1978,11 @@ func TestResponsibleGamingFlow(t *testing.T) {
 		Period:   "daily",
 		Amount:   50000, // $500 - increase
 	})
-	if newLimits.EffectiveAt.Before(time.Now().Add(23 * time.Hour)) {
+	pending := newLimits.PendingFor("daily_deposit")
+	if pending == nil || pending.EffectiveAt.Before(time.Now().Add(23*time.Hour)) {
 		t.Error("Limit increase should have 24-hour cooling off")
+	} else if newLimits.DailyDeposit.Amount != 25000 {
+		t.Error("Previous limit should stay in force during cooling off")
 	} else {
 		t.Log("  ✓ Limit increase has cooling off period")
 	}
@@ -1411,3 +2034,102 @@ func TestAuditLogging(t *testing.T) {
 		t.Errorf("Expected event type 'test_event', got '%s'", events[0].Type)
 	}
 }
+
+// ============================================================================
+// Graceful Shutdown Tests (GLI-19 §4.16)
+// ============================================================================
+
+func TestGracefulShutdown(t *testing.T) {
+	ts := NewTestServer(t)
+	defer ts.Close()
+
+	ctx := context.Background()
+
+	player := ts.createTestUser(t, "shutdown_player", "shutdown@example.com", "password123")
+	loginData := parseResponse(t, ts.doRequest(t, "POST", "/api/v1/auth/login", map[string]interface{}{
+		"auth_token":  ts.getAuthToken(player.ID),
+		"device_type": "desktop",
+	}, ""))
+	if !loginData.Success {
+		t.Fatalf("Login failed: %v", loginData.Error)
+	}
+	token := extractField(t, loginData.Data, "token")
+
+	if _, err := ts.Wallet.Deposit(ctx, player.ID, domain.NewMoney(500.00, "USD"), "shutdown-test"); err != nil {
+		t.Fatalf("Deposit failed: %v", err)
+	}
+	session, err := ts.Game.StartSession(ctx, player.ID, "fortune-slots")
+	if err != nil {
+		t.Fatalf("Failed to start session: %v", err)
+	}
+
+	// Serve the same services through the production server
+	srv := server.New(&config.ServerConfig{ReadTimeout: 5 * time.Second, WriteTimeout: 5 * time.Second},
+		ts.Handler, ts.Game, ts.Audit)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	go srv.Serve(listener)
+	playURL := "http://" + listener.Addr().String() + "/api/v1/games/play"
+
+	// Start a burst of plays and shut down while they are in flight
+	const plays = 20
+	statuses := make(chan int, plays)
+	var wg sync.WaitGroup
+	for i := 0; i < plays; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			body, _ := json.Marshal(map[string]interface{}{"session_id": session.ID, "wager_amount": 100})
+			req, _ := http.NewRequest("POST", playURL, bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", "Bearer "+token)
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				return // Refused once the listener closed
+			}
+			resp.Body.Close()
+			statuses <- resp.StatusCode
+		}()
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	shutdownCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+	wg.Wait()
+	close(statuses)
+
+	completedPlays := 0
+	for status := range statuses {
+		switch status {
+		case http.StatusOK:
+			completedPlays++
+		case http.StatusServiceUnavailable:
+			// Refused while draining
+		default:
+			t.Errorf("Unexpected play status %d", status)
+		}
+	}
+
+	// Every accepted play ran to completion; none was cut off mid-cycle
+	var completed, unfinished int
+	ts.DB.DB.QueryRow("SELECT COUNT(*) FROM game_cycles WHERE player_id = $1 AND status = $2",
+		player.ID, domain.CycleStatusCompleted).Scan(&completed)
+	ts.DB.DB.QueryRow("SELECT COUNT(*) FROM game_cycles WHERE player_id = $1 AND status IN ($2, $3)",
+		player.ID, domain.CycleStatusPending, domain.CycleStatusInProgress).Scan(&unfinished)
+	if completed != completedPlays {
+		t.Errorf("Expected %d completed cycles, got %d", completedPlays, completed)
+	}
+	if unfinished != 0 {
+		t.Errorf("Expected no unfinished cycles, got %d", unfinished)
+	}
+
+	// New play is refused after shutdown
+	if _, err := ts.Game.Play(ctx, &game.PlayRequest{SessionID: session.ID, WagerAmount: 100}); err != game.ErrShuttingDown {
+		t.Errorf("Expected ErrShuttingDown, got %v", err)
+	}
+}